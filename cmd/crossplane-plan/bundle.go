@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/v2/pkg/logging"
+	"github.com/millstonehq/crossplane-plan/pkg/config"
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// runRecord implements "crossplane-plan record": it diffs XRs against the cluster like a plan
+// does and writes everything the diff read to a bundle that "crossplane-plan replay" runs offline
+// Returns the process exit code
+func runRecord(args []string) int {
+	fs := flag.NewFlagSet("record", flag.ContinueOnError)
+	xrFile := fs.String("xr-file", "", "YAML file with the XRs to diff, e.g. from kubectl get -o yaml")
+	name := fs.String("name", "", "Production name to diff a single XR as (default: its own name)")
+	kubeconfigPath := fs.String("kubeconfig", "", "Path to kubeconfig file (default: $KUBECONFIG or ~/.kube/config)")
+	configFile := fs.String("config", "", "crossplane-plan config file to diff with; it is stored in the bundle")
+	out := fs.String("out", "plan-bundle.json", "File to write the bundle to")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *xrFile == "" {
+		fmt.Fprintln(os.Stderr, "--xr-file is required")
+		return 2
+	}
+
+	xrs, err := differ.ReadResources(*xrFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if *name != "" {
+		if len(xrs) != 1 {
+			fmt.Fprintf(os.Stderr, "--name needs exactly one XR, %s has %d\n", *xrFile, len(xrs))
+			return 2
+		}
+		xrs[0].SetName(*name)
+	}
+
+	var configData []byte
+	if *configFile != "" {
+		if configData, err = os.ReadFile(*configFile); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read config file: %v\n", err)
+			return 1
+		}
+	}
+	cfg, err := config.ParseConfig(configData)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = *kubeconfigPath
+	restConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load kubeconfig: %v\n", err)
+		return 1
+	}
+
+	logger := logging.NewNopLogger()
+	recorder := differ.NewRecorder(string(configData))
+	calculator := differ.NewCalculator(restConfig, logger)
+	calculator.SetRecorder(recorder)
+	configureCalculator(calculator, cfg, logger)
+
+	diffErr := writeDiffs(context.Background(), os.Stdout, calculator, xrs)
+
+	file, err := os.Create(*out)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create bundle: %v\n", err)
+		return 1
+	}
+	defer file.Close()
+	if err := recorder.Bundle().Write(file); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write bundle: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Wrote plan bundle to %s\n", *out)
+
+	if diffErr != nil {
+		// The bundle is still useful: it reproduces the failure
+		fmt.Fprintln(os.Stderr, diffErr)
+		return 1
+	}
+	return 0
+}
+
+// runReplay implements "crossplane-plan replay BUNDLE": it diffs a bundle's XRs offline against
+// the recorded cluster state and function outputs
+// With --check, the diffs must match the recorded ones
+// Returns the process exit code
+func runReplay(args []string) int {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	configFile := fs.String("config", "", "crossplane-plan config file to diff with (default: the bundle's)")
+	check := fs.Bool("check", false, "Fail if a diff differs from the recorded one")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: crossplane-plan replay [--config FILE] [--check] BUNDLE")
+		return 2
+	}
+
+	bundle, err := differ.LoadBundle(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	configData := []byte(bundle.Config)
+	if *configFile != "" {
+		if configData, err = os.ReadFile(*configFile); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read config file: %v\n", err)
+			return 1
+		}
+	}
+	cfg, err := config.ParseConfig(configData)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	logger := logging.NewNopLogger()
+	calculator := differ.NewReplayCalculator(bundle, logger)
+	configureCalculator(calculator, cfg, logger)
+
+	xrs := bundle.Resources()
+	if !*check {
+		if err := writeDiffs(context.Background(), os.Stdout, calculator, xrs); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	}
+
+	mismatches := 0
+	for _, xr := range xrs {
+		result, err := calculator.CalculateDiff(context.Background(), xr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s/%s: %v\n", xr.GetKind(), xr.GetName(), err)
+			mismatches++
+			continue
+		}
+		if recorded := bundle.Diffs[xr.GetName()]; result.RawDiff != recorded {
+			fmt.Printf("%s/%s: diff differs from the recording\n--- recorded\n%s\n+++ replayed\n%s\n", xr.GetKind(), xr.GetName(), recorded, result.RawDiff)
+			mismatches++
+		}
+	}
+	if mismatches > 0 {
+		fmt.Fprintf(os.Stderr, "%d of %d diffs differ from the recording\n", mismatches, len(xrs))
+		return 1
+	}
+	fmt.Printf("All %d diffs match the recording\n", len(xrs))
+	return 0
+}
+
+// writeDiffs diffs each XR and prints its raw diff under a "Kind/name" header
+// Every XR is diffed even if some fail; the failures are returned together
+func writeDiffs(ctx context.Context, w io.Writer, calculator *differ.Calculator, xrs []*unstructured.Unstructured) error {
+	var errs []error
+	for _, xr := range xrs {
+		result, err := calculator.CalculateDiff(ctx, xr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s/%s: %w", xr.GetKind(), xr.GetName(), err))
+			continue
+		}
+		fmt.Fprintf(w, "### %s/%s\n", xr.GetKind(), xr.GetName())
+		if !result.HasChanges {
+			fmt.Fprintln(w, "No changes")
+			continue
+		}
+		fmt.Fprintln(w, strings.TrimSuffix(result.RawDiff, "\n"))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/millstonehq/crossplane-plan/pkg/admin"
+)
+
+// runCleanup implements "crossplane-plan cleanup": it deletes or marks stale the comments of
+// open PRs that no longer have a preview, through a replica's admin API
+// Returns the process exit code
+func runCleanup(args []string) int {
+	fs := flag.NewFlagSet("cleanup", flag.ContinueOnError)
+	// Cleanup scans every open PR, so it takes longer than a status query
+	opts := addAdminFlags(fs, 5*time.Minute)
+	action := fs.String("action", admin.CleanupStale, "What to do with orphaned comments: stale (prefix a notice) or delete")
+	dryRun := fs.Bool("dry-run", true, "Only list orphaned comments; pass --dry-run=false to apply the action")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *action != admin.CleanupStale && *action != admin.CleanupDelete {
+		fmt.Fprintf(os.Stderr, "--action must be %s or %s\n", admin.CleanupStale, admin.CleanupDelete)
+		return 2
+	}
+
+	client, token, err := opts.client()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	result, err := admin.Cleanup(context.Background(), client, opts.url, token, *action, *dryRun)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := admin.WriteCleanup(os.Stdout, result); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/millstonehq/crossplane-plan/pkg/admin"
+)
+
+// runCompare implements "crossplane-plan compare": it prints what differs between the preview
+// environments of two PRs, given as PR numbers or branches of open PRs
+// Returns the process exit code
+func runCompare(args []string) int {
+	fs := flag.NewFlagSet("compare", flag.ContinueOnError)
+	opts := addAdminFlags(fs, time.Minute)
+	pr := fs.String("pr", "", "PR number or branch of the PR to compare")
+	against := fs.String("against", "", "PR number or branch of the PR to compare against")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *pr == "" || *against == "" {
+		fmt.Fprintln(os.Stderr, "--pr and --against are required")
+		return 2
+	}
+
+	client, token, err := opts.client()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	result, err := admin.Compare(context.Background(), client, opts.url, token, *pr, *against)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := admin.WriteCompare(os.Stdout, result); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -2,78 +2,299 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
+	"github.com/millstonehq/crossplane-plan/pkg/admin"
+	"github.com/millstonehq/crossplane-plan/pkg/api"
 	"github.com/millstonehq/crossplane-plan/pkg/argocd"
+	"github.com/millstonehq/crossplane-plan/pkg/cliflags"
 	"github.com/millstonehq/crossplane-plan/pkg/config"
-	"github.com/millstonehq/crossplane-plan/pkg/detector"
 	"github.com/millstonehq/crossplane-plan/pkg/differ"
 	"github.com/millstonehq/crossplane-plan/pkg/formatter"
+	"github.com/millstonehq/crossplane-plan/pkg/httpserver"
+	"github.com/millstonehq/crossplane-plan/pkg/metrics"
+	"github.com/millstonehq/crossplane-plan/pkg/planner"
+	"github.com/millstonehq/crossplane-plan/pkg/readonly"
+	"github.com/millstonehq/crossplane-plan/pkg/severity"
+	"github.com/millstonehq/crossplane-plan/pkg/store"
+	"github.com/millstonehq/crossplane-plan/pkg/telemetry"
+	"github.com/millstonehq/crossplane-plan/pkg/transport"
+	"github.com/millstonehq/crossplane-plan/pkg/vcs"
 	"github.com/millstonehq/crossplane-plan/pkg/vcs/github"
+	"github.com/millstonehq/crossplane-plan/pkg/version"
 	"github.com/millstonehq/crossplane-plan/pkg/watcher"
+	"github.com/millstonehq/crossplane-plan/pkg/webhook"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"github.com/crossplane/crossplane-runtime/v2/pkg/logging"
+	"github.com/go-logr/logr"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	uzap "go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 var (
 	kubeconfig              string
+	impersonateUser         string
+	impersonateGroups       string
 	detectionStrategy       string
 	namePattern             string
+	celExpression           string
+	labelKey                string
+	annotationKey           string
 	githubRepo              string
+	allowedTargetRepos      string
+	planConfigName          string
+	planConfigNamespace     string
 	githubToken             string
+	githubTokenFile         string
+	githubAPIURL            string
 	githubCredentials       string
 	githubAppID             string
 	githubInstallID         string
 	githubAppKeyPath        string
 	dryRun                  bool
 	reconciliationInterval  int
+	fullSweepInterval       int
+	shutdownGracePeriod     time.Duration
+	vcsFailureThreshold     int
+	vcsCircuitCooldown      time.Duration
+	commentLastUpdated      bool
+	commentTiming           bool
+	convergenceEstimate     bool
+	componentComments       bool
+	commentFormat           string
+	placement               string
+	commitStatus            bool
+	commitStatusFailOn      string
+	checkAnnotations        bool
+	failureComments         bool
+	placeholderAfter        time.Duration
+	logVerbosity            int
+	logSampleInterval       time.Duration
+	previewRemovedAction    string
+	diffConcurrency         int
+	maxBatchXRs             int
+	maxDiffs                int
+	onlyChangedSources      bool
+	draftPRs                string
+	stateGCInterval         time.Duration
+	stateTTL                time.Duration
+	dashboardIssue          int
+	runOnce                 bool
+	onlyPR                  int
+	summaryFile             string
+	httpsProxy              string
+	caBundlePath            string
+	rbacPreflight           string
+	githubPreflight         string
+	flagsFromFile           string
+	configDump              bool
+	metricsAddr             string
+	adminAddr               string
+	adminPprof              bool
+	adminTokenFile          string
+	apiAddr                 string
+	apiTokenFile            string
+	webhookAddr             string
+	webhookSecretFile       string
+	httpTLSCertFile         string
+	httpTLSKeyFile          string
+	httpClientCAFile        string
+	httpAuthTokenFile       string
+	stripStatsInterval      int
+	debugDiffInput          bool
+	pipelineCaptureDir      string
 	configPath              string
 	noStripDefaults         bool
 	argocdEnabled           bool
 	argocdNamespace         string
 	argocdPRPrefix          string
 	argocdPRSuffix          string
+	argocdServer            string
+	argocdToken             string
+	argocdCAFile            string
+	argocdTrackingMethod    string
+	argocdCacheResync       time.Duration
+	stateStore              string
+	stateNamespace          string
+	stateName               string
+	stateRedisAddr          string
+	stateRedisPassword      string
+	stateRedisDB            int
+	stateSQLitePath         string
+	crossplaneNamespace     string
+	footerVersion           bool
+	updateCheck             bool
+	telemetryEnabled        bool
+	telemetryEndpoint       string
+	telemetryInterval       time.Duration
 )
 
+// sensitiveFlags hold credentials and are redacted by --config-dump
+var sensitiveFlags = []string{"github-token", "github-credentials", "argocd-token", "state-redis-password"}
+
 func init() {
 	flag.StringVar(&kubeconfig, "kubeconfig", "", "Path to kubeconfig file (optional, uses in-cluster config if not specified)")
-	flag.StringVar(&detectionStrategy, "detection-strategy", "name", "PR detection strategy: name, label, or annotation")
+	flag.StringVar(&impersonateUser, "as", "", "User or service account (system:serviceaccount:<namespace>:<name>) to impersonate for reading and diffing resources; leader election and state keep the pod's identity")
+	flag.StringVar(&impersonateGroups, "as-group", "", "Comma-separated groups to impersonate along with --as")
+	flag.StringVar(&detectionStrategy, "detection-strategy", "name", "PR detection strategy: name, label, annotation, or cel")
 	flag.StringVar(&namePattern, "name-pattern", "pr-{number}-*", "Name pattern for PR detection (when strategy=name)")
+	flag.StringVar(&labelKey, "label-key", "millstone.tech/pr-number", "Label key holding the PR number (when strategy=label)")
+	flag.StringVar(&annotationKey, "annotation-key", "millstone.tech/preview-pr", "Annotation key holding the PR number (when strategy=annotation)")
+	flag.StringVar(&celExpression, "cel-expression", "", "CEL expression over the XR ('object') returning the PR number or {'pr': N, 'baseName': '...'} (when strategy=cel)")
 	flag.StringVar(&githubRepo, "github-repo", "", "GitHub repository (format: owner/repo)")
+	flag.StringVar(&allowedTargetRepos, "allowed-target-repos", "", "Comma-separated repositories (owner/repo, globs like owner/*) XRs may target via the millstone.tech/target-repo annotation")
+	flag.StringVar(&planConfigName, "plan-config", "", "Name of a PlanConfig resource to load and hot-reload configuration from (replaces --config)")
+	flag.StringVar(&planConfigNamespace, "plan-config-namespace", os.Getenv("POD_NAMESPACE"), "Namespace of the PlanConfig resource (defaults to POD_NAMESPACE)")
 	flag.StringVar(&githubToken, "github-token", os.Getenv("GITHUB_TOKEN"), "GitHub API token (can also use GITHUB_TOKEN env var)")
+	flag.StringVar(&githubTokenFile, "github-token-file", os.Getenv("GITHUB_TOKEN_FILE"), "File holding a pre-minted GitHub installation access token, re-read when it changes (e.g. refreshed by a sidecar); can also use GITHUB_TOKEN_FILE env var")
+	flag.StringVar(&githubAPIURL, "github-api-url", os.Getenv("GITHUB_API_URL"), "GitHub Enterprise Server API URL, e.g. https://github.example.com/api/v3/ (defaults to github.com; can also use GITHUB_API_URL env var)")
 	flag.StringVar(&githubCredentials, "github-credentials", os.Getenv("GITHUB_CREDENTIALS"), "GitHub credentials in crossplane-provider-github format (base64-encoded JSON)")
 	flag.StringVar(&githubAppID, "github-app-id", os.Getenv("GITHUB_APP_ID"), "GitHub App ID (can also use GITHUB_APP_ID env var)")
 	flag.StringVar(&githubInstallID, "github-installation-id", os.Getenv("GITHUB_INSTALLATION_ID"), "GitHub Installation ID (can also use GITHUB_INSTALLATION_ID env var)")
 	flag.StringVar(&githubAppKeyPath, "github-app-key-path", os.Getenv("GITHUB_APP_PRIVATE_KEY_PATH"), "Path to GitHub App private key file (can also use GITHUB_APP_PRIVATE_KEY_PATH env var)")
 	flag.BoolVar(&dryRun, "dry-run", false, "Dry run mode - calculate diffs but don't post to GitHub")
 	flag.IntVar(&reconciliationInterval, "reconciliation-interval", 5, "Periodic reconciliation interval in minutes (0 to disable)")
+	flag.IntVar(&fullSweepInterval, "full-reconciliation-interval", 60, "Interval in minutes at which periodic reconciliation replans every PR instead of only changed PRs (0 to disable)")
+	flag.IntVar(&logVerbosity, "log-verbosity", 1, "Log verbosity: 0 for Info, 1 for debug messages, 2 for every per-resource message of hot paths, unsampled")
+	flag.DurationVar(&logSampleInterval, "log-sample-interval", time.Minute, "Log each per-resource message of hot paths (XR events, XRs of a batch, detected deletions) at most once per interval, with a count of the suppressed ones (0 to log all)")
+	flag.DurationVar(&shutdownGracePeriod, "shutdown-grace-period", 30*time.Second, "How long in-flight PR processing may finish on shutdown or leadership loss before it is abandoned")
+	flag.IntVar(&vcsFailureThreshold, "vcs-failure-threshold", 5, "Consecutive GitHub failures before comment posting is paused (0 to disable the circuit breaker)")
+	flag.DurationVar(&vcsCircuitCooldown, "vcs-circuit-cooldown", time.Minute, "How long comment posting stays paused before GitHub is probed again")
+	flag.BoolVar(&commentLastUpdated, "comment-last-updated", false, "Append a \"last updated\" line to PR comments (ignored when deciding whether a comment changed)")
+	flag.BoolVar(&commentTiming, "comment-timing", false, "Append a timing breakdown (discovery, diff, ArgoCD) to PR comments (ignored when deciding whether a comment changed)")
+	flag.BoolVar(&convergenceEstimate, "convergence-estimate", true, "Estimate in PR comments how long the changed resources take to become Ready after merge, from the past time-to-Ready of their kinds")
+	flag.StringVar(&commentFormat, "comment-format", "auto", "How comments are formatted: markdown, plain (no collapsible sections, long diffs cut), or auto (plain if the VCS can't render collapsible sections)")
+	flag.BoolVar(&componentComments, "component-comments", false, "Post a separate PR comment per component, grouping XRs by their millstone.tech/component label (needs --placement=comment)")
+	flag.StringVar(&placement, "placement", watcher.PlacementComment, "Where plans are published: comment (the PR comment), split (summary and severity in a check run, full diff in the comment), or check (a check run only; needs GitHub App credentials)")
+	flag.BoolVar(&commitStatus, "commit-status", false, "Publish a commit status (context crossplane-plan/plan) summarizing each plan on the PR's head commit, e.g. for branch protection")
+	flag.StringVar(&commitStatusFailOn, "commit-status-fail-on", "error", "Severity from which the commit status fails: change, destructive, or error")
+	flag.BoolVar(&failureComments, "failure-comments", true, "Comment on PRs whose whole plan fails (e.g. XRDs can't be discovered) with the error's category and a failure ID to find it in the logs")
+	flag.BoolVar(&checkAnnotations, "check-annotations", false, "Annotate the changed manifests declaring each resource in check runs, with the resource's outcome (needs --placement=split or check)")
+	flag.DurationVar(&placeholderAfter, "placeholder-after", 30*time.Second, "Post a \"computing preview\" comment for plans still running after this long, then edit it with the results (0 to disable)")
+	flag.StringVar(&previewRemovedAction, "preview-removed-action", "stale", "What to do with the comment of a PR whose preview resources were all deleted: stale (mark it stale), delete, or none")
+	flag.StringVar(&draftPRs, "draft-prs", watcher.DraftPlan, "What to do with draft PRs: plan, skip (plan them once ready for review), or no-fail (plan them, but don't count their plans toward the --once exit code)")
+	flag.IntVar(&diffConcurrency, "diff-concurrency", 1, "Number of diff engines, i.e. how many XR diffs may run concurrently")
+	flag.IntVar(&maxBatchXRs, "max-batch-xrs", 0, "Maximum XRs planned per PR and repository; a PR with more is planned partially and its comment says so (0 for no limit)")
+	flag.IntVar(&maxDiffs, "max-diffs", 0, "Maximum diffs whose text is kept per plan; later diffs only show their summary (0 for no limit)")
+	flag.BoolVar(&onlyChangedSources, "only-changed-sources", false, "Only plan the XRs whose source files the PR changes, by source mappings or the paths of the PR's ArgoCD Application; the comment lists the others")
+	flag.IntVar(&dashboardIssue, "dashboard-issue", 0, "Issue of the default repository on which a comment summarizes the plans of all open PRs, updated after plans (0 to disable)")
+	flag.BoolVar(&runOnce, "once", false, "Plan every PR with preview XRs once, post the comments and exit with 0 (no changes), 2 (changes), 3 (deletions) or 1 (error), e.g. in a CI workflow")
+	flag.IntVar(&onlyPR, "pr", 0, "Only plan this PR (with --once)")
+	flag.StringVar(&summaryFile, "summary-file", "", "Write a JSON summary of the plans (counts, severity, errors, exit code) to this file (with --once)")
+	flag.StringVar(&httpsProxy, "https-proxy", "", "Proxy URL for GitHub requests (defaults to the HTTPS_PROXY/NO_PROXY environment variables)")
+	flag.StringVar(&caBundlePath, "ca-bundle", "", "PEM file of additional CAs trusted for GitHub requests (e.g., a corporate proxy CA)")
+	flag.StringVar(&rbacPreflight, "rbac-preflight", "warn", "Check the service account's RBAC at startup: warn (log missing permissions), enforce (exit on missing permissions), or off")
+	flag.StringVar(&githubPreflight, "github-preflight", "enforce", "Check at startup that the GitHub credentials can read the repository and comment on PRs: enforce (exit on failure), warn (log failures), or off")
+	flag.StringVar(&flagsFromFile, "flags-from-file", "", "YAML file of flag values (flag name: value); command-line flags and CROSSPLANE_PLAN_* environment variables take precedence")
+	flag.BoolVar(&configDump, "config-dump", false, "Print the effective flag values as a flags file (secrets redacted) and exit")
 	flag.StringVar(&configPath, "config", "/etc/crossplane-plan/config.yaml", "Path to config file for field stripping rules")
+	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "Address to serve Prometheus metrics on (empty to disable)")
+	flag.StringVar(&adminAddr, "admin-addr", "", "Address to serve the admin API on, e.g. :8081 (empty to disable); used by \"crossplane-plan status\"")
+	flag.BoolVar(&adminPprof, "admin-pprof", false, "Serve Go runtime profiles (pprof) under /debug/pprof/ on the admin API")
+	flag.StringVar(&adminTokenFile, "admin-token-file", "", "File holding the bearer token required by the admin API (defaults to --http-auth-token-file; one of them is required)")
+	flag.StringVar(&apiAddr, "api-addr", "", "Address to serve the read-only plan API on, e.g. :8082 (empty to disable); serves GET /plans/{owner}/{repo}/{pr}")
+	flag.StringVar(&apiTokenFile, "api-token-file", "", "File holding the bearer token required by the plan API (defaults to --http-auth-token-file; one of them is required)")
+	flag.StringVar(&webhookAddr, "webhook-addr", "", "Address to receive GitHub webhooks on, e.g. :8083 (empty to disable); a \"/crossplane-plan\" PR comment replans the PR")
+	flag.StringVar(&webhookSecretFile, "webhook-secret-file", "", "File holding the secret GitHub signs webhook deliveries with (required with --webhook-addr)")
+	flag.StringVar(&httpTLSCertFile, "http-tls-cert-file", "", "TLS certificate for the HTTP endpoints (metrics, admin API); enables HTTPS together with --http-tls-key-file")
+	flag.StringVar(&httpTLSKeyFile, "http-tls-key-file", "", "TLS private key for the HTTP endpoints")
+	flag.StringVar(&httpClientCAFile, "http-client-ca-file", "", "CA bundle for verifying client certificates; requires clients of the HTTP endpoints to use mTLS")
+	flag.StringVar(&httpAuthTokenFile, "http-auth-token-file", "", "File holding a bearer token required by the HTTP endpoints")
+	flag.IntVar(&stripStatsInterval, "strip-stats-interval", 0, "Interval in minutes for logging strip rule hit counts (0 to disable)")
+	flag.BoolVar(&debugDiffInput, "debug-diff-input", false, "Attach the sanitized XR used as diff input to every comment and log it (per XR: millstone.tech/plan-debug annotation)")
+	flag.StringVar(&pipelineCaptureDir, "pipeline-capture-dir", "", "Directory to write function pipeline captures to (requested per XR with the millstone.tech/plan-debug-pipeline annotation); logged if unset")
 	flag.BoolVar(&noStripDefaults, "no-strip-defaults", false, "Disable default field stripping rules")
 	flag.BoolVar(&argocdEnabled, "argocd-enabled", true, "Enable ArgoCD integration for enhanced deletion detection")
 	flag.StringVar(&argocdNamespace, "argocd-namespace", "argocd", "ArgoCD namespace")
 	flag.StringVar(&argocdPRPrefix, "argocd-pr-prefix", "pr-", "ArgoCD PR app name prefix (e.g., 'pr-' for 'pr-123-myapp')")
 	flag.StringVar(&argocdPRSuffix, "argocd-pr-suffix", "", "ArgoCD PR app name suffix (optional)")
+	flag.StringVar(&argocdTrackingMethod, "argocd-tracking-method", "label", "ArgoCD resource tracking method (application.resourceTrackingMethod): label, annotation, or annotation+label")
+	flag.DurationVar(&argocdCacheResync, "argocd-cache-resync", 10*time.Minute, "Resync period of the in-memory cache of ArgoCD Applications, which serves Application reads instead of the API server (0 to disable)")
+	flag.StringVar(&argocdServer, "argocd-server", "", "ArgoCD API server URL (e.g., https://argocd-server.argocd.svc); enables manifest-level diffs of the apps' target revisions")
+	flag.StringVar(&argocdToken, "argocd-token", os.Getenv("ARGOCD_AUTH_TOKEN"), "ArgoCD API token (can also use ARGOCD_AUTH_TOKEN env var)")
+	flag.StringVar(&argocdCAFile, "argocd-ca-file", "", "PEM file of additional CAs trusted for the ArgoCD API server")
+	flag.StringVar(&stateStore, "state-store", store.BackendMemory, "Where comment hashes, plan history and retry state are kept: memory, configmap, crd, redis, or sqlite")
+	flag.StringVar(&stateNamespace, "state-namespace", os.Getenv("POD_NAMESPACE"), "Namespace of the state ConfigMap or PlanState resources (defaults to POD_NAMESPACE)")
+	flag.StringVar(&stateName, "state-name", store.DefaultName, "Name of the state ConfigMap, or prefix of PlanState resource names")
+	flag.StringVar(&stateRedisAddr, "state-redis-addr", "", "Redis address (host:port) when --state-store=redis")
+	flag.StringVar(&stateRedisPassword, "state-redis-password", os.Getenv("STATE_REDIS_PASSWORD"), "Redis password (can also use STATE_REDIS_PASSWORD env var)")
+	flag.IntVar(&stateRedisDB, "state-redis-db", 0, "Redis database when --state-store=redis")
+	flag.DurationVar(&stateGCInterval, "state-gc-interval", time.Hour, "How often the state of PRs without preview XRs is garbage-collected: closed PRs, and PRs whose last plan is older than --state-ttl (0 to disable)")
+	flag.DurationVar(&stateTTL, "state-ttl", 7*24*time.Hour, "Age of the last plan after which the state of a PR without preview XRs is dropped, even if the PR is still open (0 to only drop the state of closed PRs)")
+	flag.StringVar(&stateSQLitePath, "state-sqlite-path", "/var/lib/crossplane-plan/state.db", "SQLite database file when --state-store=sqlite (put it on a PersistentVolume)")
+	flag.StringVar(&crossplaneNamespace, "crossplane-namespace", "crossplane-system", "Namespace of the Crossplane deployment, whose version is checked against the versions plans are rendered for (empty to skip the check)")
+	flag.BoolVar(&footerVersion, "footer-version", false, "Show the running crossplane-plan version in the footer of comments")
+	flag.BoolVar(&updateCheck, "update-check", false, "Check GitHub releases daily and hint at a newer crossplane-plan release in the comment footer (needs --footer-version)")
+	flag.BoolVar(&telemetryEnabled, "telemetry", false, "Opt in to sending anonymous usage reports (plan counts, diff engines used, version) to --telemetry-endpoint; off unless set")
+	flag.StringVar(&telemetryEndpoint, "telemetry-endpoint", "", "URL usage reports are POSTed to when --telemetry is set")
+	flag.DurationVar(&telemetryInterval, "telemetry-interval", 24*time.Hour, "How often usage reports are sent when --telemetry is set")
 }
 
 func main() {
+	// Subcommands
+	if len(os.Args) > 1 && os.Args[1] == "status" {
+		os.Exit(runStatus(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "cleanup" {
+		os.Exit(runCleanup(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "preview" {
+		os.Exit(runPreview(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "compare" {
+		os.Exit(runCompare(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "whoowns" {
+		os.Exit(runWhoOwns(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "record" {
+		os.Exit(runRecord(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		os.Exit(runReplay(os.Args[2:]))
+	}
+
 	flag.Parse()
 
 	// Set up logging
-	zapLogger := zap.New(zap.UseDevMode(true))
+	logLevel := uzap.NewAtomicLevelAt(zapcore.DebugLevel)
+	zapLogger := zap.New(zap.UseDevMode(true), zap.Level(logLevel))
 	logrLogger := zapLogger.WithName("crossplane-plan")
 	logger := logging.NewLogrLogger(logrLogger)
 
+	// Layer CROSSPLANE_PLAN_* environment variables and the flags file under the command line
+	if err := cliflags.Apply(flag.CommandLine, flagsFromFile, cliflags.EnvPrefix); err != nil {
+		logrLogger.Error(err, "invalid flag configuration")
+		os.Exit(1)
+	}
+	// zap levels below Info are negated logr verbosities
+	logLevel.SetLevel(zapcore.Level(-logVerbosity))
+
+	if configDump {
+		if err := cliflags.Dump(flag.CommandLine, os.Stdout, []string{"config-dump", "flags-from-file"}, sensitiveFlags); err != nil {
+			logrLogger.Error(err, "failed to dump configuration")
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	logger.Info("Starting crossplane-plan",
 		"detectionStrategy", detectionStrategy,
 		"namePattern", namePattern,
+		"labelKey", labelKey,
+		"annotationKey", annotationKey,
 		"githubRepo", githubRepo,
 		"dryRun", dryRun,
 	)
@@ -84,9 +305,19 @@ func main() {
 		os.Exit(1)
 	}
 
+	if (onlyPR != 0 || summaryFile != "") && !runOnce {
+		logrLogger.Error(fmt.Errorf("--pr and --summary-file require --once"), "invalid flag combination")
+		os.Exit(1)
+	}
+
+	if telemetryEnabled && (telemetryEndpoint == "" || telemetryInterval <= 0) {
+		logrLogger.Error(fmt.Errorf("--telemetry requires --telemetry-endpoint and a positive --telemetry-interval"), "invalid flag combination")
+		os.Exit(1)
+	}
+
 	// Validate authentication config (unless dry-run)
 	if !dryRun {
-		hasToken := githubToken != ""
+		hasToken := githubToken != "" || githubTokenFile != ""
 		hasCredentials := githubCredentials != ""
 		hasAppCreds := githubAppID != "" && githubInstallID != "" && githubAppKeyPath != ""
 
@@ -94,7 +325,7 @@ func main() {
 			logrLogger.Error(
 				fmt.Errorf("authentication required"),
 				"missing authentication",
-				"hint", "provide GITHUB_TOKEN, GITHUB_CREDENTIALS, or GitHub App credentials (GITHUB_APP_ID, GITHUB_INSTALLATION_ID, GITHUB_APP_PRIVATE_KEY_PATH)",
+				"hint", "provide GITHUB_TOKEN, GITHUB_TOKEN_FILE, GITHUB_CREDENTIALS, or GitHub App credentials (GITHUB_APP_ID, GITHUB_INSTALLATION_ID, GITHUB_APP_PRIVATE_KEY_PATH)",
 			)
 			os.Exit(1)
 		}
@@ -107,6 +338,16 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Read-only builds guard every client, so only leader election leases are written
+	if readonly.Enforced {
+		if stateStore == store.BackendConfigMap || stateStore == store.BackendCRD {
+			logrLogger.Error(fmt.Errorf("state store %q writes to the cluster", stateStore), "read-only build requires --state-store=memory, redis, or sqlite")
+			os.Exit(1)
+		}
+		cfg = readonly.Wrap(cfg)
+		logger.Info("Read-only guard enforced for all Kubernetes clients")
+	}
+
 	// Create Kubernetes clientset
 	clientset, err := kubernetes.NewForConfig(cfg)
 	if err != nil {
@@ -114,51 +355,73 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Read and diff resources as the plan-only identity, if one is configured
+	if impersonateGroups != "" && impersonateUser == "" {
+		logrLogger.Error(fmt.Errorf("--as-group requires --as"), "invalid flag combination")
+		os.Exit(1)
+	}
+	// Planning never needs to modify the cluster: reject anything but reads and dry runs
+	planCfg := readonly.Wrap(impersonatedConfig(cfg, impersonateUser, impersonateGroups))
+	if impersonateUser != "" {
+		logger.Info("Impersonating plan-only identity", "user", impersonateUser, "groups", planCfg.Impersonate.Groups)
+	}
+
 	// Load config file
 	appConfig, err := config.LoadConfig(configPath)
 	if err != nil {
 		logrLogger.Error(err, "failed to load config")
 		os.Exit(1)
 	}
+	for _, warning := range appConfig.Warnings() {
+		logger.Info("Ignoring config rule", "problem", warning)
+	}
 
 	// Set CLI-only fields (not in config file)
 	appConfig.DetectionStrategy = detectionStrategy
 	appConfig.NamePattern = namePattern
+	appConfig.CELExpression = celExpression
+	appConfig.LabelKey = labelKey
+	appConfig.AnnotationKey = annotationKey
 	appConfig.GitHubRepo = githubRepo
 	appConfig.DryRun = dryRun
+	if appConfig.AllowedTargetRepos, err = config.ParseRepoPatterns(allowedTargetRepos); err != nil {
+		logrLogger.Error(err, "invalid --allowed-target-repos")
+		os.Exit(1)
+	}
 
 	// Create PR detector
-	prDetector, err := createDetector(appConfig)
+	prDetector, err := planner.NewDetector(appConfig)
 	if err != nil {
 		logrLogger.Error(err, "failed to create PR detector")
 		os.Exit(1)
 	}
 
 	// Create differ
-	diffCalculator := differ.NewCalculator(cfg, logger)
+	diffCalculator := differ.NewCalculator(planCfg, logger, differ.WithPoolSize(diffConcurrency))
 
 	// Override stripDefaults if CLI flag is set
 	if noStripDefaults {
 		appConfig.Diff.StripDefaults = false
 	}
 
-	// Create and configure sanitizer
-	stripRules := appConfig.GetAllStripRules()
-	if len(stripRules) > 0 {
-		sanitizer := differ.NewSanitizer(stripRules)
-		diffCalculator.SetSanitizer(sanitizer)
-		logger.Info("Field stripping enabled", "ruleCount", len(stripRules))
-	} else {
-		logger.Info("Field stripping disabled")
-	}
+	// Configure sanitizer, normalizer and drift comparison
+	configureCalculator(diffCalculator, appConfig, logger)
 
 	// Create formatter
 	diffFormatter := formatter.NewGitHubFormatter()
 
+	if updateCheck && !footerVersion {
+		logrLogger.Error(fmt.Errorf("--update-check requires --footer-version"), "invalid flag combination")
+		os.Exit(1)
+	}
+	if footerVersion {
+		diffFormatter.SetVersion(version.Current())
+	}
+
 	// Create VCS client (if not dry-run)
-	var vcsClient *github.Client
+	var vcsClient vcs.Client
 	if !dryRun {
-		vcsClient, err = createGitHubClient()
+		githubClient, err := createGitHubClient()
 		if err != nil {
 			logrLogger.Error(err, "failed to create GitHub client")
 			os.Exit(1)
@@ -167,12 +430,26 @@ func main() {
 			"authMethod", getAuthMethod(),
 			"repo", githubRepo,
 		)
+		githubClient.SetShowLastUpdated(commentLastUpdated)
+		if vcsFailureThreshold > 0 {
+			githubClient.SetCircuitBreaker(github.NewCircuitBreaker(vcsFailureThreshold, vcsCircuitCooldown, logrLogger))
+		}
+		vcsClient = githubClient
+	}
+	switch commentFormat {
+	case "auto":
+		diffFormatter.SetPlainText(vcsClient != nil && !vcsClient.Capabilities().CollapsibleSections)
+	case "markdown", "plain":
+		diffFormatter.SetPlainText(commentFormat == "plain")
+	default:
+		logrLogger.Error(fmt.Errorf("unknown format %q, expected auto, markdown, or plain", commentFormat), "invalid --comment-format")
+		os.Exit(1)
 	}
 
 	// Create ArgoCD client (if enabled)
 	var argocdClient *argocd.Client
 	if argocdEnabled {
-		dynamicClient, err := dynamic.NewForConfig(cfg)
+		dynamicClient, err := dynamic.NewForConfig(planCfg)
 		if err != nil {
 			logrLogger.Error(err, "failed to create dynamic client for ArgoCD")
 			os.Exit(1)
@@ -184,38 +461,335 @@ func main() {
 			argocdPRSuffix,
 			logrLogger,
 		)
+		trackingMethod, err := argocd.ParseTrackingMethod(argocdTrackingMethod)
+		if err != nil {
+			logrLogger.Error(err, "invalid --argocd-tracking-method")
+			os.Exit(1)
+		}
+		argocdClient.SetTrackingMethod(trackingMethod)
 		logger.Info("ArgoCD client created",
 			"namespace", argocdNamespace,
 			"prPrefix", argocdPRPrefix,
+			"trackingMethod", trackingMethod,
 		)
+
+		if argocdServer != "" {
+			tr, err := transport.New(transport.Options{CABundlePath: argocdCAFile})
+			if err != nil {
+				logrLogger.Error(err, "failed to configure ArgoCD API transport")
+				os.Exit(1)
+			}
+			argocdClient.SetManifestClient(argocd.NewManifestClient(
+				argocdServer,
+				argocdToken,
+				&http.Client{Transport: tr, Timeout: time.Minute},
+			))
+			logger.Info("ArgoCD manifest diffs enabled", "server", argocdServer)
+		}
 	} else {
 		logger.Info("ArgoCD integration disabled")
 	}
 
 	// Create and start watcher
-	xrWatcher := watcher.NewXRWatcher(
-		clientset,
+	xrWatcher, err := watcher.NewXRWatcher(
+		planCfg,
 		prDetector,
 		diffCalculator,
 		diffFormatter,
-		vcsClient,
-		argocdClient,
-		logrLogger,
-		reconciliationInterval,
+		watcher.WithClientset(clientset),
+		watcher.WithVCS(vcsClient),
+		watcher.WithArgoCD(argocdClient),
+		watcher.WithLogger(logrLogger),
+		watcher.WithReconcileInterval(reconciliationInterval),
 	)
+	if err != nil {
+		logrLogger.Error(err, "failed to create XR watcher")
+		os.Exit(1)
+	}
+	xrWatcher.SetAllowedTargetRepos(appConfig.AllowedTargetRepos)
+	xrWatcher.SetConfig(appConfig)
+	xrWatcher.SetDebugDiffInput(debugDiffInput)
+	xrWatcher.SetPipelineCaptureDir(pipelineCaptureDir)
+	xrWatcher.SetFullReconciliationInterval(fullSweepInterval)
+	xrWatcher.SetShutdownGracePeriod(shutdownGracePeriod)
+	xrWatcher.SetCommentTiming(commentTiming)
+	xrWatcher.SetConvergenceEstimate(convergenceEstimate)
+	xrWatcher.SetPlaceholderDelay(placeholderAfter)
+	xrWatcher.SetLogSampling(logSampleInterval)
+	xrWatcher.SetLimits(maxBatchXRs, maxDiffs)
+	xrWatcher.SetOnlyChangedSources(onlyChangedSources)
+	xrWatcher.SetDashboardIssue(dashboardIssue)
+	switch previewRemovedAction {
+	case admin.CleanupStale, admin.CleanupDelete:
+		xrWatcher.SetPreviewRemovedAction(previewRemovedAction)
+	case "none":
+	default:
+		logrLogger.Error(fmt.Errorf("unknown action %q, expected stale, delete, or none", previewRemovedAction), "invalid --preview-removed-action")
+		os.Exit(1)
+	}
+	switch draftPRs {
+	case watcher.DraftPlan, watcher.DraftSkip, watcher.DraftNoFail:
+		xrWatcher.SetDraftPRs(draftPRs)
+	default:
+		logrLogger.Error(fmt.Errorf("unknown mode %q, expected plan, skip, or no-fail", draftPRs), "invalid --draft-prs")
+		os.Exit(1)
+	}
+	switch placement {
+	case watcher.PlacementComment, watcher.PlacementSplit, watcher.PlacementCheck:
+		xrWatcher.SetPlacement(placement)
+	default:
+		logrLogger.Error(fmt.Errorf("unknown placement %q, expected comment, split, or check", placement), "invalid --placement")
+		os.Exit(1)
+	}
+	if componentComments && placement != watcher.PlacementComment {
+		logrLogger.Error(fmt.Errorf("component comments need --placement=comment, got %q", placement), "invalid --component-comments")
+		os.Exit(1)
+	}
+	xrWatcher.SetComponentComments(componentComments)
+	if checkAnnotations && placement == watcher.PlacementComment {
+		logrLogger.Error(fmt.Errorf("check annotations need --placement=split or check, got %q", placement), "invalid --check-annotations")
+		os.Exit(1)
+	}
+	xrWatcher.SetCheckAnnotations(checkAnnotations)
+	xrWatcher.SetFailureComments(failureComments)
+	if commitStatus {
+		failOn, err := severity.Parse(commitStatusFailOn)
+		if err != nil || failOn == severity.Info {
+			logrLogger.Error(fmt.Errorf("unknown severity %q, expected change, destructive, or error", commitStatusFailOn), "invalid --commit-status-fail-on")
+			os.Exit(1)
+		}
+		xrWatcher.SetCommitStatus(failOn)
+	}
+	if len(appConfig.Repos) > 0 {
+		logger.Info("Per-repository profiles enabled", "repoCount", len(appConfig.Repos))
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// A single pass reads each Application about once, so only long-running watchers cache them
+	if argocdClient != nil && argocdCacheResync > 0 && !runOnce {
+		argocdClient.StartCache(ctx, argocdCacheResync)
+	}
+
+	// Hint at newer releases in comment footers
+	if updateCheck {
+		checkForUpdates(ctx, diffFormatter, logrLogger)
+	}
+
+	// Keep plan state in the configured backend, so it survives restarts and leader failover
+	stateDynamicClient, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		logrLogger.Error(err, "failed to create dynamic client for the state store")
+		os.Exit(1)
+	}
+	stateBackend, err := store.New(ctx, store.Options{
+		Backend:       stateStore,
+		Namespace:     stateNamespace,
+		Name:          stateName,
+		Clientset:     clientset,
+		DynamicClient: stateDynamicClient,
+		RedisAddr:     stateRedisAddr,
+		RedisPassword: stateRedisPassword,
+		RedisDB:       stateRedisDB,
+		SQLitePath:    stateSQLitePath,
+	})
+	if err != nil {
+		logrLogger.Error(err, "failed to create state store", "backend", stateStore)
+		os.Exit(1)
+	}
+	defer stateBackend.Close()
+	xrWatcher.SetStateStore(stateBackend)
+	xrWatcher.SetStateGC(stateGCInterval, stateTTL)
+	logger.Info("State store configured", "backend", stateStore)
+
+	// Report missing RBAC up front instead of failing piecemeal during reconciliation
+	if err := runRBACPreflight(ctx, xrWatcher, rbacPreflight, logrLogger); err != nil {
+		logrLogger.Error(err, "RBAC preflight failed")
+		os.Exit(1)
+	}
+
+	// Plans of compositions may be subtly wrong on Crossplane versions the diff library isn't built for
+	if crossplaneNamespace != "" {
+		checkCrossplaneVersion(ctx, clientset, crossplaneNamespace, diffFormatter, logrLogger)
+	}
+
+	// Catch unusable GitHub credentials now instead of on the first comment
+	if vcsClient != nil {
+		if err := runGitHubPreflight(ctx, vcsClient, githubPreflight, logrLogger); err != nil {
+			logrLogger.Error(err, "GitHub preflight failed")
+			os.Exit(1)
+		}
+	}
+
+	// Hot-reload configuration from a PlanConfig resource (replaces the config file)
+	if planConfigName != "" {
+		dynamicClient, err := dynamic.NewForConfig(cfg)
+		if err != nil {
+			logrLogger.Error(err, "failed to create dynamic client for PlanConfig")
+			os.Exit(1)
+		}
+
+		applyConfig := func(newConfig *config.Config) error {
+			if noStripDefaults {
+				newConfig.Diff.StripDefaults = false
+			}
+			newDetector, err := planner.NewDetector(newConfig)
+			if err != nil {
+				return fmt.Errorf("invalid detection settings: %w", err)
+			}
+
+			configureCalculator(diffCalculator, newConfig, logger)
+			xrWatcher.SetDetector(newDetector)
+			xrWatcher.SetConfig(newConfig)
+			xrWatcher.SetAllowedTargetRepos(newConfig.AllowedTargetRepos)
+			return nil
+		}
+
+		planConfigWatcher := watcher.NewPlanConfigWatcher(dynamicClient, planConfigNamespace, planConfigName, appConfig, applyConfig, logrLogger)
+		if err := planConfigWatcher.Load(ctx); err != nil {
+			// Keep running on flag/file config; the watch applies the PlanConfig once it is valid
+			logrLogger.Error(err, "failed to load PlanConfig, using flag and file configuration")
+		}
+		if !runOnce {
+			go planConfigWatcher.Run(ctx)
+		}
+	}
+
+	// Usage reports are strictly opt-in
+	var reporter *telemetry.Reporter
+	if telemetryEnabled {
+		reporter = telemetry.NewReporter(telemetryEndpoint, telemetryInterval, logrLogger)
+		logger.Info("Anonymous usage reports enabled", "endpoint", telemetryEndpoint, "interval", telemetryInterval)
+	}
+
+	// Plan once and exit with the outcome instead of running the controller
+	if runOnce {
+		code := planOnce(ctx, xrWatcher, logrLogger)
+		if reporter != nil {
+			if err := reporter.Send(ctx); err != nil {
+				logger.Info("Failed to send usage report", "error", err.Error())
+			}
+		}
+		stateBackend.Close()
+		cancel()
+		os.Exit(code)
+	}
+
 	// Handle shutdown gracefully
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 	go func() {
 		<-sigCh
 		logger.Info("Received shutdown signal")
+		// Finish in-flight PRs while still holding the lease
+		xrWatcher.Drain()
 		cancel()
 	}()
 
+	if reporter != nil {
+		go reporter.Run(ctx)
+	}
+
+	// Serve metrics
+	if metricsAddr != "" {
+		metricsServer, err := newMetricsServer(metricsAddr, logrLogger)
+		if err != nil {
+			logrLogger.Error(err, "invalid metrics server configuration")
+			os.Exit(1)
+		}
+		go func() {
+			if err := metricsServer.Run(ctx); err != nil {
+				logrLogger.Error(err, "metrics server failed")
+			}
+		}()
+	}
+
+	// Serve the admin API (work queue status)
+	if adminAddr != "" {
+		// Comment cleanup needs GitHub access
+		var cleaner admin.CommentCleaner
+		if vcsClient != nil {
+			cleaner = xrWatcher
+		}
+		adminHandler := admin.NewHandler(xrWatcher, cleaner, xrWatcher, xrWatcher, xrWatcher)
+		if adminPprof {
+			adminHandler = admin.WithProfiling(adminHandler)
+		}
+		// The admin API deletes comments and serves plans and profiles, so a token is mandatory
+		opts := httpServerOptions(adminAddr)
+		if adminTokenFile != "" {
+			opts.AuthTokenFile = adminTokenFile
+		}
+		if opts.AuthTokenFile == "" {
+			logrLogger.Error(fmt.Errorf("--admin-token-file or --http-auth-token-file is required"), "invalid admin server configuration")
+			os.Exit(1)
+		}
+		adminServer, err := httpserver.New("admin", adminHandler, opts, logrLogger)
+		if err != nil {
+			logrLogger.Error(err, "invalid admin server configuration")
+			os.Exit(1)
+		}
+		go func() {
+			if err := adminServer.Run(ctx); err != nil {
+				logrLogger.Error(err, "admin server failed")
+			}
+		}()
+	}
+
+	if apiAddr != "" {
+		// Plans are readable by anyone who can reach the endpoint, so a token is mandatory
+		opts := httpServerOptions(apiAddr)
+		if apiTokenFile != "" {
+			opts.AuthTokenFile = apiTokenFile
+		}
+		if opts.AuthTokenFile == "" {
+			logrLogger.Error(fmt.Errorf("--api-token-file or --http-auth-token-file is required"), "invalid plan API configuration")
+			os.Exit(1)
+		}
+		apiServer, err := httpserver.New("api", api.NewHandler(xrWatcher), opts, logrLogger)
+		if err != nil {
+			logrLogger.Error(err, "invalid plan API server configuration")
+			os.Exit(1)
+		}
+		go func() {
+			if err := apiServer.Run(ctx); err != nil {
+				logrLogger.Error(err, "plan API server failed")
+			}
+		}()
+	}
+
+	if webhookAddr != "" {
+		if webhookSecretFile == "" {
+			logrLogger.Error(fmt.Errorf("--webhook-secret-file is required"), "invalid webhook configuration")
+			os.Exit(1)
+		}
+		secret, err := webhook.ReadSecret(webhookSecretFile)
+		if err != nil {
+			logrLogger.Error(err, "invalid webhook configuration")
+			os.Exit(1)
+		}
+		// GitHub can't present bearer tokens or client certificates; deliveries are authenticated by their signature
+		opts := httpServerOptions(webhookAddr)
+		opts.AuthTokenFile = ""
+		opts.ClientCAFile = ""
+		webhookServer, err := httpserver.New("webhook", webhook.NewHandler(secret, xrWatcher, logrLogger), opts, logrLogger)
+		if err != nil {
+			logrLogger.Error(err, "invalid webhook server configuration")
+			os.Exit(1)
+		}
+		go func() {
+			if err := webhookServer.Run(ctx); err != nil {
+				logrLogger.Error(err, "webhook server failed")
+			}
+		}()
+	}
+
+	// Periodically log which strip rules fire so dead rules can be pruned
+	if stripStatsInterval > 0 {
+		go logStripRuleStats(ctx, time.Duration(stripStatsInterval)*time.Minute, logrLogger)
+	}
+
 	// Start watching
 	if err := xrWatcher.Start(ctx); err != nil {
 		logrLogger.Error(err, "watcher failed")
@@ -225,6 +799,189 @@ func main() {
 	logger.Info("Shutting down gracefully")
 }
 
+// Exit codes of --once
+const (
+	exitNoChanges = 0
+	exitError     = 1
+	exitChanges   = 2
+	exitDeletions = 3
+)
+
+// onceSummary is the machine-readable summary of --once written to --summary-file
+type onceSummary struct {
+	watcher.Outcome
+	ExitCode int `json:"exitCode"`
+}
+
+// planOnce runs a single reconciliation pass and returns the exit code reflecting its outcome
+func planOnce(ctx context.Context, xrWatcher *watcher.XRWatcher, logger logr.Logger) int {
+	outcome, err := xrWatcher.RunOnce(ctx, onlyPR)
+	code := onceExitCode(outcome, err)
+	if err != nil {
+		logger.Error(err, "plan failed")
+	} else {
+		logger.Info("Plan complete", "prCount", outcome.PRs, "changed", outcome.Changed, "deletions", outcome.Deletions, "severity", outcome.Severity)
+	}
+
+	if summaryFile != "" {
+		data, err := json.MarshalIndent(onceSummary{Outcome: outcome, ExitCode: code}, "", "  ")
+		if err == nil {
+			err = os.WriteFile(summaryFile, append(data, '\n'), 0644)
+		}
+		if err != nil {
+			logger.Error(err, "failed to write summary", "path", summaryFile)
+			return exitError
+		}
+	}
+	return code
+}
+
+// onceExitCode maps the outcome of --once to its exit code
+func onceExitCode(outcome watcher.Outcome, err error) int {
+	switch {
+	case err != nil, outcome.Failed > 0:
+		return exitError
+	case outcome.Deletions > 0:
+		return exitDeletions
+	case outcome.Changed > 0:
+		return exitChanges
+	default:
+		return exitNoChanges
+	}
+}
+
+// checkCrossplaneVersion warns in the logs and in the footer of every comment when the cluster runs a
+// Crossplane version outside the range plans are rendered for
+func checkCrossplaneVersion(ctx context.Context, clientset kubernetes.Interface, namespace string, f *formatter.GitHubFormatter, logger logr.Logger) {
+	version, err := differ.DetectCrossplaneVersion(ctx, clientset, namespace)
+	if err != nil {
+		logger.Info("Could not detect the Crossplane version, skipping the version skew check", "error", err.Error())
+		return
+	}
+	if warning := differ.CrossplaneVersionSkew(version); warning != "" {
+		logger.Error(fmt.Errorf("crossplane %s is not supported", version), "Crossplane version skew: plans may be inaccurate",
+			"supportedFrom", differ.MinCrossplaneVersion, "supportedBefore", differ.MaxCrossplaneVersion)
+		f.SetFooterWarning(warning)
+		return
+	}
+	logger.Info("Crossplane version supported", "version", version)
+}
+
+// checkForUpdates keeps the newer-release hint of the comment footer current: once for a single
+// pass, daily in the background otherwise
+func checkForUpdates(ctx context.Context, f *formatter.GitHubFormatter, logger logr.Logger) {
+	tr, err := transport.New(transport.Options{ProxyURL: httpsProxy, CABundlePath: caBundlePath})
+	if err != nil {
+		logger.Error(err, "failed to configure the update check transport, skipping update checks")
+		return
+	}
+	checker := version.NewChecker(version.Current(), tr, logger)
+	if !runOnce {
+		go checker.Run(ctx, f.SetLatestVersion)
+		return
+	}
+
+	latest, err := checker.Newer(ctx)
+	if err != nil {
+		logger.Info("Update check failed", "error", err.Error())
+		return
+	}
+	f.SetLatestVersion(latest)
+}
+
+// runRBACPreflight checks the service account's permissions according to mode
+// Only enforce mode turns missing permissions into an error
+func runRBACPreflight(ctx context.Context, xrWatcher *watcher.XRWatcher, mode string, logger logr.Logger) error {
+	switch mode {
+	case "off":
+		return nil
+	case "warn", "enforce":
+	default:
+		return fmt.Errorf("invalid --rbac-preflight mode %q (must be warn, enforce, or off)", mode)
+	}
+
+	report, err := xrWatcher.Preflight(ctx)
+	if err != nil {
+		if mode == "enforce" {
+			return err
+		}
+		logger.Error(err, "RBAC preflight could not run")
+		return nil
+	}
+
+	if report.OK() {
+		logger.Info("RBAC preflight passed", "checks", report.Checked)
+		return nil
+	}
+	if mode == "enforce" {
+		return fmt.Errorf("service account is missing permissions\n%s", report)
+	}
+	logger.Error(nil, "Service account is missing permissions, affected features will fail\n"+report.String())
+	return nil
+}
+
+// githubPreflightTimeout bounds the GitHub permissions probe
+const githubPreflightTimeout = 30 * time.Second
+
+// runGitHubPreflight probes the GitHub credentials according to mode
+// Only enforce mode turns a failed probe into an error
+func runGitHubPreflight(ctx context.Context, vcsClient vcs.Client, mode string, logger logr.Logger) error {
+	switch mode {
+	case "off":
+		return nil
+	case "warn", "enforce":
+	default:
+		return fmt.Errorf("invalid --github-preflight mode %q (must be warn, enforce, or off)", mode)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, githubPreflightTimeout)
+	defer cancel()
+	if err := vcsClient.Probe(ctx); err != nil {
+		if mode == "enforce" {
+			return err
+		}
+		logger.Error(err, "GitHub preflight failed, posting comments will fail")
+		return nil
+	}
+	logger.Info("GitHub preflight passed", "repo", vcsClient.Repository())
+	return nil
+}
+
+// newMetricsServer creates the server for Prometheus metrics on /metrics
+func newMetricsServer(addr string, logger logr.Logger) (*httpserver.Server, error) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	return httpserver.New("metrics", mux, httpServerOptions(addr), logger)
+}
+
+// httpServerOptions applies the shared TLS and auth flags to an HTTP endpoint
+func httpServerOptions(addr string) httpserver.Options {
+	return httpserver.Options{
+		Addr:          addr,
+		TLSCertFile:   httpTLSCertFile,
+		TLSKeyFile:    httpTLSKeyFile,
+		ClientCAFile:  httpClientCAFile,
+		AuthTokenFile: httpAuthTokenFile,
+	}
+}
+
+// logStripRuleStats logs strip rule hit counts every interval until ctx is cancelled
+func logStripRuleStats(ctx context.Context, interval time.Duration, logger logr.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			for _, rule := range metrics.StripRuleHitCounts() {
+				logger.Info("Strip rule usage", "path", rule.Path, "reason", rule.Reason, "hits", rule.Hits)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
 func buildKubeConfig() (*rest.Config, error) {
 	if kubeconfig != "" {
 		return clientcmd.BuildConfigFromFlags("", kubeconfig)
@@ -232,31 +989,67 @@ func buildKubeConfig() (*rest.Config, error) {
 	return rest.InClusterConfig()
 }
 
-func createDetector(cfg *config.Config) (detector.Detector, error) {
-	switch cfg.DetectionStrategy {
-	case "name":
-		return detector.NewNameDetector(cfg.NamePattern), nil
-	case "label":
-		return detector.NewLabelDetector(), nil
-	case "annotation":
-		return detector.NewAnnotationDetector(), nil
-	default:
-		return nil, fmt.Errorf("unknown detection strategy: %s", cfg.DetectionStrategy)
+// impersonatedConfig returns a copy of cfg that impersonates user and the comma-separated groups
+// Returns cfg itself if user is empty
+func impersonatedConfig(cfg *rest.Config, user, groups string) *rest.Config {
+	if user == "" {
+		return cfg
+	}
+	impersonated := rest.CopyConfig(cfg)
+	impersonated.Impersonate = rest.ImpersonationConfig{UserName: user}
+	if groups != "" {
+		impersonated.Impersonate.Groups = strings.Split(groups, ",")
+	}
+	return impersonated
+}
+
+// configureCalculator applies the diff settings of cfg to the calculator
+func configureCalculator(diffCalculator *differ.Calculator, cfg *config.Config, logger logging.Logger) {
+	// Sanitizer, normalizer, drift comparison and per-kind engines
+	diffCalculator.Configure(cfg)
+
+	if stripRules := cfg.GetAllStripRules(); len(stripRules) > 0 {
+		logger.Info("Field stripping enabled", "ruleCount", len(stripRules))
+	} else {
+		logger.Info("Field stripping disabled")
+	}
+	if len(cfg.Diff.Normalize) > 0 {
+		logger.Info("Field normalization enabled", "ruleCount", len(cfg.Diff.Normalize))
+	}
+	if len(cfg.Diff.Engines) > 0 {
+		logger.Info("Per-kind diff engines configured", "ruleCount", len(cfg.Diff.Engines))
 	}
 }
 
 func createGitHubClient() (*github.Client, error) {
+	// Route requests through the configured proxy and trust the private CA
+	tr, err := transport.New(transport.Options{
+		ProxyURL:     httpsProxy,
+		CABundlePath: caBundlePath,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure GitHub transport: %w", err)
+	}
+
 	// Build client config
 	config := &github.ClientConfig{
 		Repository: githubRepo,
+		BaseURL:    githubAPIURL,
+		Transport:  tr,
 	}
 
-	// Priority: token > credentials > direct GitHub App
+	// Priority: token > token file > credentials > direct GitHub App
 	if githubToken != "" {
 		config.Token = githubToken
 		return github.NewClientFromConfig(config)
 	}
 
+	// Pre-minted installation access token (private key kept outside the cluster)
+	if githubTokenFile != "" {
+		config.TokenFile = githubTokenFile
+		return github.NewClientFromConfig(config)
+	}
+
 	// Crossplane provider credentials format (used in production)
 	if githubCredentials != "" {
 		config.Credentials = githubCredentials
@@ -285,6 +1078,9 @@ func getAuthMethod() string {
 	if githubToken != "" {
 		return "token"
 	}
+	if githubTokenFile != "" {
+		return "token-file"
+	}
 	if githubCredentials != "" {
 		return "crossplane-credentials"
 	}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/millstonehq/crossplane-plan/pkg/admin"
+)
+
+// runPreview implements "crossplane-plan preview": it runs a PR's plan on a replica and prints
+// the comments it would post, without posting them
+// Returns the process exit code
+func runPreview(args []string) int {
+	fs := flag.NewFlagSet("preview", flag.ContinueOnError)
+	// A preview runs the full plan, diffs included
+	opts := addAdminFlags(fs, 5*time.Minute)
+	prNumber := fs.Int("pr", 0, "Number of the PR to preview")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *prNumber <= 0 {
+		fmt.Fprintln(os.Stderr, "--pr is required")
+		return 2
+	}
+
+	client, token, err := opts.client()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	result, err := admin.Preview(context.Background(), client, opts.url, token, *prNumber)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := admin.WritePreview(os.Stdout, result); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/millstonehq/crossplane-plan/pkg/admin"
+	"github.com/millstonehq/crossplane-plan/pkg/transport"
+)
+
+// adminOptions are the connection flags of the subcommands talking to the admin API
+type adminOptions struct {
+	url       string
+	tokenFile string
+	caFile    string
+	timeout   time.Duration
+}
+
+// addAdminFlags registers the admin API connection flags
+func addAdminFlags(fs *flag.FlagSet, timeout time.Duration) *adminOptions {
+	opts := &adminOptions{}
+	fs.StringVar(&opts.url, "admin-url", "http://localhost:8081", "Base URL of the replica's admin API (e.g., via kubectl port-forward)")
+	fs.StringVar(&opts.tokenFile, "token-file", "", "File holding the bearer token required by the admin API")
+	fs.StringVar(&opts.caFile, "ca-file", "", "PEM file of CAs trusted for an HTTPS admin API")
+	fs.DurationVar(&opts.timeout, "timeout", timeout, "Request timeout")
+	return opts
+}
+
+// client returns the HTTP client and bearer token for the admin API
+func (o *adminOptions) client() (*http.Client, string, error) {
+	client := &http.Client{Timeout: o.timeout}
+	if o.caFile != "" {
+		tlsConfig, err := transport.ClientTLSConfig(o.caFile)
+		if err != nil {
+			return nil, "", err
+		}
+		client.Transport = &http.Transport{TLSClientConfig: tlsConfig}
+	}
+
+	var token string
+	if o.tokenFile != "" {
+		data, err := os.ReadFile(o.tokenFile)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read token: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	return client, token, nil
+}
+
+// runStatus implements "crossplane-plan status": it prints a replica's work queue
+// Returns the process exit code
+func runStatus(args []string) int {
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	opts := addAdminFlags(fs, 10*time.Second)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	client, token, err := opts.client()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	status, err := admin.FetchStatus(context.Background(), client, opts.url, token)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := admin.WriteStatus(os.Stdout, status, time.Now()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
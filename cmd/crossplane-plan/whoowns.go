@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/millstonehq/crossplane-plan/pkg/admin"
+)
+
+// runWhoOwns implements "crossplane-plan whoowns <kind>/<name>": it prints the PR a resource
+// belongs to, its base name and the PR's last plan, through a replica's admin API
+// Returns the process exit code
+func runWhoOwns(args []string) int {
+	fs := flag.NewFlagSet("whoowns", flag.ContinueOnError)
+	opts := addAdminFlags(fs, time.Minute)
+	namespace := fs.String("namespace", "", "Namespace of the resource (required if the name exists in several namespaces)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: crossplane-plan whoowns [flags] <kind>/<name>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	client, token, err := opts.client()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	result, err := admin.WhoOwns(context.Background(), client, opts.url, token, fs.Arg(0), *namespace)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := admin.WriteOwner(os.Stdout, result, time.Now()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,98 @@
+// engine-compare diffs XRs with several diff engines on the same inputs and reports where they
+// disagree, to build confidence in an engine before making it the default for a kind
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/v2/pkg/logging"
+	"github.com/millstonehq/crossplane-plan/pkg/config"
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+// run compares the engines and returns the process exit code: 1 if any XR's diffs disagree
+func run(args []string) int {
+	fs := flag.NewFlagSet("engine-compare", flag.ContinueOnError)
+	xrFile := fs.String("xr-file", "", "YAML file with the XRs to diff, e.g. from kubectl get -o yaml")
+	kubeconfigPath := fs.String("kubeconfig", "", "Path to kubeconfig file (default: $KUBECONFIG or ~/.kube/config)")
+	configFile := fs.String("config", "", "crossplane-plan config file whose strip rules and normalization apply")
+	engines := fs.String("engines", differ.EngineCrossplaneDiff+","+differ.EngineDryRun, "Comma-separated engines to compare; the first is the reference")
+	showDiffs := fs.Bool("show-diffs", false, "Print every engine's full diff")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	engineNames := strings.Split(*engines, ",")
+	if *xrFile == "" || len(engineNames) < 2 {
+		fmt.Fprintln(os.Stderr, "--xr-file and at least two --engines are required")
+		return 2
+	}
+
+	xrs, err := differ.ReadResources(*xrFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	cfg, err := config.LoadConfig(*configFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = *kubeconfigPath
+	restConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load kubeconfig: %v\n", err)
+		return 1
+	}
+
+	calculator := differ.NewCalculator(restConfig, logging.NewNopLogger())
+	if rules := cfg.GetAllStripRules(); len(rules) > 0 {
+		calculator.SetSanitizer(differ.NewSanitizer(rules))
+	}
+	if len(cfg.Diff.Normalize) > 0 {
+		calculator.SetNormalizer(differ.NewNormalizer(cfg.Diff.Normalize))
+	}
+	calculator.SetPlugins(cfg.Diff.Plugins)
+
+	disagreements := 0
+	for _, xr := range xrs {
+		comparison, err := calculator.CompareEngines(context.Background(), xr, engineNames)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s/%s: %v\n", xr.GetKind(), xr.GetName(), err)
+			return 1
+		}
+
+		if len(comparison.Discrepancies) == 0 {
+			fmt.Printf("%s: engines agree\n", comparison.Resource)
+		} else {
+			disagreements++
+			fmt.Printf("%s: %d discrepancies\n", comparison.Resource, len(comparison.Discrepancies))
+			for _, discrepancy := range comparison.Discrepancies {
+				fmt.Printf("  %s\n", discrepancy)
+			}
+		}
+		if *showDiffs {
+			for _, result := range comparison.Results {
+				if result.Err == nil {
+					fmt.Printf("----- %s -----\n%s\n", result.Engine, strings.TrimSuffix(result.Diff, "\n"))
+				}
+			}
+		}
+	}
+
+	fmt.Printf("\n%d of %d XRs with discrepancies\n", disagreements, len(xrs))
+	if disagreements > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,131 @@
+// loadtest synthesizes N PR XRs across M XR kinds in an envtest API server (or the cluster of
+// --kubeconfig) and plans every PR through the watcher, reporting plan latency and memory as JSON
+// With --baseline, it fails when the run regressed against a previous report
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/crossplane/crossplane-runtime/v2/pkg/logging"
+	"github.com/millstonehq/crossplane-plan/pkg/config"
+	"github.com/millstonehq/crossplane-plan/pkg/detector"
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+	"github.com/millstonehq/crossplane-plan/pkg/formatter"
+	"github.com/millstonehq/crossplane-plan/pkg/loadtest"
+	"github.com/millstonehq/crossplane-plan/pkg/watcher"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+// run performs the load test and returns the process exit code
+func run(args []string) int {
+	fs := flag.NewFlagSet("loadtest", flag.ContinueOnError)
+	opts := loadtest.Options{}
+	fs.IntVar(&opts.PRs, "prs", 50, "Number of PRs (N)")
+	fs.IntVar(&opts.GVRs, "gvrs", 5, "Number of XR kinds the PR XRs are spread across (M)")
+	fs.IntVar(&opts.XRsPerPR, "xrs-per-pr", 3, "Number of XRs per PR")
+	kubeconfigPath := fs.String("kubeconfig", "", "Run against this cluster instead of envtest (needs KUBEBUILDER_ASSETS)")
+	diffConcurrency := fs.Int("diff-concurrency", 1, "Number of diff engines")
+	out := fs.String("out", "", "File to write the JSON report to (default: stdout)")
+	baseline := fs.String("baseline", "", "Report of a previous run to compare against")
+	tolerance := fs.Float64("tolerance", 0.2, "Allowed growth of each metric over the baseline (0.2 = 20%)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if opts.PRs < 1 || opts.GVRs < 1 || opts.XRsPerPR < 1 {
+		fmt.Fprintln(os.Stderr, "--prs, --gvrs and --xrs-per-pr must be positive")
+		return 2
+	}
+
+	var cfg *rest.Config
+	var err error
+	if *kubeconfigPath != "" {
+		cfg, err = clientcmd.BuildConfigFromFlags("", *kubeconfigPath)
+	} else {
+		env := &envtest.Environment{}
+		if cfg, err = env.Start(); err == nil {
+			defer func() { _ = env.Stop() }()
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to start API server: %v\n", err)
+		return 1
+	}
+
+	report, err := runLoadTest(context.Background(), cfg, opts, *diffConcurrency)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	output := os.Stdout
+	if *out != "" {
+		if output, err = os.Create(*out); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to create report: %v\n", err)
+			return 1
+		}
+		defer output.Close()
+	}
+	if err := report.Write(output); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write report: %v\n", err)
+		return 1
+	}
+
+	if *baseline == "" {
+		return 0
+	}
+	previous, err := loadtest.LoadReport(*baseline)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	regressions := loadtest.Regressions(previous, report, *tolerance)
+	for _, regression := range regressions {
+		fmt.Fprintln(os.Stderr, "regression:", regression)
+	}
+	if len(regressions) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runLoadTest sets up the load test's resources and plans every PR in dry-run mode
+// The synthesized kinds have no compositions, so they are diffed with the dry-run engine
+func runLoadTest(ctx context.Context, cfg *rest.Config, opts loadtest.Options, diffConcurrency int) (*loadtest.Report, error) {
+	dynamicClient, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+	if err := loadtest.Setup(ctx, dynamicClient, opts); err != nil {
+		return nil, fmt.Errorf("failed to set up load test: %w", err)
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes clientset: %w", err)
+	}
+
+	calculator := differ.NewCalculator(cfg, logging.NewNopLogger(),
+		differ.WithPoolSize(diffConcurrency),
+		differ.WithEngineRules([]config.EngineRule{{APIGroup: loadtest.Group, Engines: []string{differ.EngineDryRun}}}),
+	)
+
+	xrWatcher, err := watcher.NewXRWatcher(cfg, detector.NewNameDetector(loadtest.NamePattern),
+		calculator, formatter.NewGitHubFormatter(), watcher.WithClientset(clientset))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create XR watcher: %w", err)
+	}
+	xrWatcher.SetConfig(config.DefaultConfig())
+
+	return loadtest.Run(ctx, xrWatcher, opts), nil
+}
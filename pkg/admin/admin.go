@@ -0,0 +1,313 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/millstonehq/crossplane-plan/pkg/store"
+	"github.com/millstonehq/crossplane-plan/pkg/workqueue"
+)
+
+// StatusPath serves the replica's leadership and work queue
+const StatusPath = "/status"
+
+// CleanupPath deletes or marks stale the comments of PRs without previews (POST)
+const CleanupPath = "/cleanup"
+
+// PreviewPath runs a PR's plan and returns the comments it would post, without posting them (GET ?pr=N)
+const PreviewPath = "/preview"
+
+// ComparePath compares the preview environments of two PRs (GET ?pr=REF&against=REF)
+// A ref is a PR number or the branch of an open PR
+const ComparePath = "/compare"
+
+// WhoOwnsPath reports the PR a resource belongs to (GET ?resource=Kind/name[&namespace=NS])
+const WhoOwnsPath = "/whoowns"
+
+// ErrResourceNotFound is returned by an OwnerFinder for resources that don't exist
+var ErrResourceNotFound = errors.New("resource not found")
+
+// Preview comparison statuses
+const (
+	CompareOnlyInPR      = "only-in-pr"
+	CompareOnlyInAgainst = "only-in-against"
+	CompareChanged       = "changed"
+)
+
+// Cleanup actions
+const (
+	CleanupDelete = "delete"
+	CleanupStale  = "stale"
+)
+
+// StatusSource provides the state reported by the admin API
+type StatusSource interface {
+	IsLeader() bool
+	QueueSnapshot() []workqueue.WorkItem
+}
+
+// CommentCleaner finds and cleans up orphaned PR comments
+// With dryRun, comments are only reported
+type CommentCleaner interface {
+	CleanupComments(ctx context.Context, action string, dryRun bool) ([]CleanedComment, error)
+}
+
+// CleanedComment is an orphaned PR comment found by a cleanup
+type CleanedComment struct {
+	PRNumber int    `json:"prNumber"`
+	Reason   string `json:"reason"`
+	// Done is false for dry runs and comments that were already marked stale
+	Done bool `json:"done"`
+}
+
+// CleanupResult is the admin API's response to a cleanup
+type CleanupResult struct {
+	Action   string           `json:"action"`
+	DryRun   bool             `json:"dryRun"`
+	Comments []CleanedComment `json:"comments"`
+}
+
+// CommentPreviewer runs a PR's plan without posting or recording anything
+type CommentPreviewer interface {
+	PreviewPR(ctx context.Context, prNumber int) ([]PreviewComment, error)
+}
+
+// PreviewComment is a comment a PR's plan would post
+type PreviewComment struct {
+	Repository string `json:"repository"`
+	// Body is the full comment body, including the identifier and footer
+	Body string `json:"body"`
+	// Held is true when a freeze window would hold the comment back
+	Held bool `json:"held,omitempty"`
+}
+
+// PreviewResult is the admin API's response to a preview
+type PreviewResult struct {
+	PRNumber int              `json:"prNumber"`
+	Comments []PreviewComment `json:"comments"`
+}
+
+// PreviewComparer compares the preview environments of two PRs, given as PR numbers or branches
+type PreviewComparer interface {
+	ComparePreviews(ctx context.Context, pr, against string) (*CompareResult, error)
+}
+
+// ComparedField is a spec field whose value differs between two previews
+type ComparedField struct {
+	Path string `json:"path"`
+	// PR and Against are the JSON values of the field, empty when unset
+	PR      string `json:"pr,omitempty"`
+	Against string `json:"against,omitempty"`
+}
+
+// ComparedResource is an XR that differs between two previews, by its production "Kind/name"
+type ComparedResource struct {
+	Resource string          `json:"resource"`
+	Status   string          `json:"status"`
+	Fields   []ComparedField `json:"fields,omitempty"`
+}
+
+// CompareResult is the admin API's response to a comparison
+type CompareResult struct {
+	PRNumber        int                `json:"prNumber"`
+	AgainstPRNumber int                `json:"againstPrNumber"`
+	Resources       []ComparedResource `json:"resources"`
+}
+
+// OwnerFinder runs the PR detectors over an XR, given by kind (or plural resource name) and name
+// namespace is optional; without it the XR must be unique across namespaces
+type OwnerFinder interface {
+	WhoOwns(ctx context.Context, kind, name, namespace string) (*OwnerResult, error)
+}
+
+// OwnerResult is the admin API's response to a reverse lookup
+type OwnerResult struct {
+	// Resource is the XR as "Kind/name"
+	Resource   string `json:"resource"`
+	APIVersion string `json:"apiVersion"`
+	Namespace  string `json:"namespace,omitempty"`
+
+	// PRNumber is 0 for production resources
+	PRNumber int    `json:"prNumber"`
+	BaseName string `json:"baseName"`
+
+	// Repository is the repository the XR's plan is posted to
+	Repository string `json:"repository,omitempty"`
+	// RepositoryError is why the XR's target repository is rejected
+	RepositoryError string `json:"repositoryError,omitempty"`
+	// Ignored is true for XRs excluded from plans
+	Ignored bool `json:"ignored,omitempty"`
+
+	// LastPlan is the PR's most recent plan, if it was planned
+	LastPlan *store.PlanRecord `json:"lastPlan,omitempty"`
+	// Queue is the PR's work queue entry, if it is queued, being planned or failed
+	Queue *QueueItem `json:"queue,omitempty"`
+}
+
+// Status is the admin API's view of a replica
+type Status struct {
+	Leader bool        `json:"leader"`
+	Queue  []QueueItem `json:"queue"`
+}
+
+// QueueItem is a PR in the work queue
+type QueueItem struct {
+	// Repository is empty for PRs of the default repository
+	Repository               string     `json:"repository,omitempty"`
+	PRNumber                 int        `json:"prNumber"`
+	State                    string     `json:"state"`
+	EnqueuedAt               *time.Time `json:"enqueuedAt,omitempty"`
+	DebounceRemainingSeconds float64    `json:"debounceRemainingSeconds,omitempty"`
+	StartedAt                *time.Time `json:"startedAt,omitempty"`
+	Failures                 int        `json:"failures"`
+	LastError                string     `json:"lastError,omitempty"`
+}
+
+// NewHandler returns the admin API handler
+// cleaner, previewer, comparer and owners are optional; without them, their requests are rejected
+func NewHandler(source StatusSource, cleaner CommentCleaner, previewer CommentPreviewer, comparer PreviewComparer, owners OwnerFinder) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+StatusPath, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, statusOf(source))
+	})
+	mux.HandleFunc("POST "+CleanupPath, func(w http.ResponseWriter, r *http.Request) {
+		if cleaner == nil {
+			http.Error(w, "comment cleanup is not available (no GitHub client)", http.StatusNotImplemented)
+			return
+		}
+
+		action := r.URL.Query().Get("action")
+		if action != CleanupDelete && action != CleanupStale {
+			http.Error(w, fmt.Sprintf("action must be %q or %q", CleanupDelete, CleanupStale), http.StatusBadRequest)
+			return
+		}
+		dryRun := r.URL.Query().Get("dryRun") == "true"
+
+		comments, err := cleaner.CleanupComments(r.Context(), action, dryRun)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if comments == nil {
+			comments = []CleanedComment{}
+		}
+		writeJSON(w, CleanupResult{Action: action, DryRun: dryRun, Comments: comments})
+	})
+	mux.HandleFunc("GET "+PreviewPath, func(w http.ResponseWriter, r *http.Request) {
+		if previewer == nil {
+			http.Error(w, "comment preview is not available", http.StatusNotImplemented)
+			return
+		}
+
+		prNumber, err := strconv.Atoi(r.URL.Query().Get("pr"))
+		if err != nil || prNumber <= 0 {
+			http.Error(w, "pr must be a positive PR number", http.StatusBadRequest)
+			return
+		}
+
+		comments, err := previewer.PreviewPR(r.Context(), prNumber)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if comments == nil {
+			comments = []PreviewComment{}
+		}
+		writeJSON(w, PreviewResult{PRNumber: prNumber, Comments: comments})
+	})
+	mux.HandleFunc("GET "+ComparePath, func(w http.ResponseWriter, r *http.Request) {
+		if comparer == nil {
+			http.Error(w, "preview comparison is not available", http.StatusNotImplemented)
+			return
+		}
+
+		pr, against := r.URL.Query().Get("pr"), r.URL.Query().Get("against")
+		if pr == "" || against == "" {
+			http.Error(w, "pr and against must be PR numbers or branches", http.StatusBadRequest)
+			return
+		}
+
+		result, err := comparer.ComparePreviews(r.Context(), pr, against)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if result.Resources == nil {
+			result.Resources = []ComparedResource{}
+		}
+		writeJSON(w, result)
+	})
+	mux.HandleFunc("GET "+WhoOwnsPath, func(w http.ResponseWriter, r *http.Request) {
+		if owners == nil {
+			http.Error(w, "reverse lookup is not available", http.StatusNotImplemented)
+			return
+		}
+
+		kind, name, ok := strings.Cut(r.URL.Query().Get("resource"), "/")
+		if !ok || kind == "" || name == "" {
+			http.Error(w, "resource must be Kind/name", http.StatusBadRequest)
+			return
+		}
+
+		result, err := owners.WhoOwns(r.Context(), kind, name, r.URL.Query().Get("namespace"))
+		if errors.Is(err, ErrResourceNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, result)
+	})
+	return mux
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// statusOf converts the source's state to the API representation
+func statusOf(source StatusSource) Status {
+	snapshot := source.QueueSnapshot()
+	status := Status{
+		Leader: source.IsLeader(),
+		Queue:  make([]QueueItem, 0, len(snapshot)),
+	}
+	for _, item := range snapshot {
+		status.Queue = append(status.Queue, NewQueueItem(item))
+	}
+	return status
+}
+
+// NewQueueItem converts a work queue item to the API representation
+func NewQueueItem(item workqueue.WorkItem) QueueItem {
+	return QueueItem{
+		Repository:               item.Repo,
+		PRNumber:                 item.PRNumber,
+		State:                    item.State,
+		EnqueuedAt:               timeOrNil(item.EnqueuedAt),
+		DebounceRemainingSeconds: item.DebounceRemaining.Seconds(),
+		StartedAt:                timeOrNil(item.StartedAt),
+		Failures:                 item.Failures,
+		LastError:                item.LastError,
+	}
+}
+
+// timeOrNil returns nil for the zero time so it is omitted from JSON
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,402 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/millstonehq/crossplane-plan/pkg/store"
+	"github.com/millstonehq/crossplane-plan/pkg/workqueue"
+)
+
+type fakeSource struct {
+	leader bool
+	items  []workqueue.WorkItem
+}
+
+func (f *fakeSource) IsLeader() bool                      { return f.leader }
+func (f *fakeSource) QueueSnapshot() []workqueue.WorkItem { return f.items }
+
+func TestStatusRoundTrip(t *testing.T) {
+	enqueued := time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC)
+	source := &fakeSource{
+		leader: true,
+		items: []workqueue.WorkItem{
+			{PRNumber: 12, State: workqueue.StatePending, EnqueuedAt: enqueued, DebounceRemaining: 3500 * time.Millisecond},
+			{PRNumber: 40, State: workqueue.StateProcessing, EnqueuedAt: enqueued, StartedAt: enqueued.Add(5 * time.Second)},
+			{PRNumber: 77, State: workqueue.StateFailed, Failures: 2, LastError: "diff failed"},
+			{Repo: "acme/network", PRNumber: 12, State: workqueue.StateFailed, Failures: 1, LastError: "forbidden"},
+		},
+	}
+
+	server := httptest.NewServer(NewHandler(source, nil, nil, nil, nil))
+	defer server.Close()
+
+	status, err := FetchStatus(context.Background(), server.Client(), server.URL+"/", "")
+	if err != nil {
+		t.Fatalf("FetchStatus() error = %v", err)
+	}
+	if !status.Leader || len(status.Queue) != 4 {
+		t.Fatalf("FetchStatus() = %+v, want leader with 4 items", status)
+	}
+	if status.Queue[2].StartedAt != nil || status.Queue[2].EnqueuedAt != nil {
+		t.Errorf("failed item should omit unset times: %+v", status.Queue[2])
+	}
+
+	var buf bytes.Buffer
+	if err := WriteStatus(&buf, status, enqueued.Add(10*time.Second)); err != nil {
+		t.Fatalf("WriteStatus() error = %v", err)
+	}
+	for _, want := range []string{
+		"PR               STATE",
+		"#12              pending     10s ago  3.5s",
+		"#40              processing  10s ago  -         5s ago",
+		"#77              failed      -        -         -        2         diff failed",
+		"acme/network#12  failed      -        -         -        1         forbidden",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("WriteStatus() missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestWriteStatus_NotLeader(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteStatus(&buf, &Status{}, time.Now()); err != nil {
+		t.Fatalf("WriteStatus() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "not the leader") || !strings.Contains(buf.String(), "Work queue is empty") {
+		t.Errorf("WriteStatus() = %q, want not-leader hint and empty queue", buf.String())
+	}
+}
+
+func TestFetchStatus_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("not json"))
+	}))
+	defer server.Close()
+
+	if _, err := FetchStatus(context.Background(), server.Client(), server.URL, ""); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("FetchStatus() error = %v, want 401", err)
+	}
+	if _, err := FetchStatus(context.Background(), server.Client(), server.URL, "secret"); err == nil || !strings.Contains(err.Error(), "decode") {
+		t.Errorf("FetchStatus() error = %v, want decode error", err)
+	}
+}
+
+type fakeCleaner struct {
+	action string
+	dryRun bool
+}
+
+func (f *fakeCleaner) CleanupComments(ctx context.Context, action string, dryRun bool) ([]CleanedComment, error) {
+	f.action, f.dryRun = action, dryRun
+	return []CleanedComment{
+		{PRNumber: 3, Reason: "no preview XRs", Done: !dryRun},
+		{PRNumber: 9, Reason: "no preview XRs"},
+	}, nil
+}
+
+func TestCleanupRoundTrip(t *testing.T) {
+	cleaner := &fakeCleaner{}
+	server := httptest.NewServer(NewHandler(&fakeSource{}, cleaner, nil, nil, nil))
+	defer server.Close()
+
+	result, err := Cleanup(context.Background(), server.Client(), server.URL, "", CleanupStale, false)
+	if err != nil {
+		t.Fatalf("Cleanup() error = %v", err)
+	}
+	if cleaner.action != CleanupStale || cleaner.dryRun {
+		t.Errorf("cleaner called with %q dryRun=%v, want stale without dry run", cleaner.action, cleaner.dryRun)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCleanup(&buf, result); err != nil {
+		t.Fatalf("WriteCleanup() error = %v", err)
+	}
+	for _, want := range []string{"#3  no preview XRs  marked stale", "#9  no preview XRs  unchanged"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("WriteCleanup() missing %q:\n%s", want, buf.String())
+		}
+	}
+
+	result, err = Cleanup(context.Background(), server.Client(), server.URL, "", CleanupDelete, true)
+	if err != nil {
+		t.Fatalf("Cleanup() error = %v", err)
+	}
+	buf.Reset()
+	if err := WriteCleanup(&buf, result); err != nil {
+		t.Fatalf("WriteCleanup() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "would be deleted") || !strings.Contains(buf.String(), "Dry run") {
+		t.Errorf("WriteCleanup() = %q, want dry run output", buf.String())
+	}
+}
+
+func TestCleanup_Rejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		cleaner CommentCleaner
+		action  string
+		want    string
+	}{
+		{name: "no cleaner", action: CleanupDelete, want: "501"},
+		{name: "unknown action", cleaner: &fakeCleaner{}, action: "archive", want: "400"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(NewHandler(&fakeSource{}, tt.cleaner, nil, nil, nil))
+			defer server.Close()
+
+			_, err := Cleanup(context.Background(), server.Client(), server.URL, "", tt.action, true)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Cleanup() error = %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
+
+type fakePreviewer struct {
+	prNumber int
+}
+
+func (f *fakePreviewer) PreviewPR(ctx context.Context, prNumber int) ([]PreviewComment, error) {
+	f.prNumber = prNumber
+	return []PreviewComment{
+		{Repository: "org/infra", Body: "<!-- crossplane-plan -->\n\n## Plan\n"},
+		{Repository: "org/apps", Body: "## Apps", Held: true},
+	}, nil
+}
+
+func TestPreviewRoundTrip(t *testing.T) {
+	previewer := &fakePreviewer{}
+	server := httptest.NewServer(NewHandler(&fakeSource{}, nil, previewer, nil, nil))
+	defer server.Close()
+
+	result, err := Preview(context.Background(), server.Client(), server.URL, "", 42)
+	if err != nil {
+		t.Fatalf("Preview() error = %v", err)
+	}
+	if previewer.prNumber != 42 || result.PRNumber != 42 {
+		t.Errorf("previewed PR %d (result %d), want 42", previewer.prNumber, result.PRNumber)
+	}
+
+	var buf bytes.Buffer
+	if err := WritePreview(&buf, result); err != nil {
+		t.Fatalf("WritePreview() error = %v", err)
+	}
+	want := "===== org/infra =====\n<!-- crossplane-plan -->\n\n## Plan\n\n===== org/apps (held back by a freeze window) =====\n## Apps\n"
+	if buf.String() != want {
+		t.Errorf("WritePreview() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestPreview_Rejected(t *testing.T) {
+	tests := []struct {
+		name      string
+		previewer CommentPreviewer
+		prNumber  int
+		want      string
+	}{
+		{name: "no previewer", prNumber: 1, want: "501"},
+		{name: "invalid PR", previewer: &fakePreviewer{}, prNumber: 0, want: "400"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(NewHandler(&fakeSource{}, nil, tt.previewer, nil, nil))
+			defer server.Close()
+
+			_, err := Preview(context.Background(), server.Client(), server.URL, "", tt.prNumber)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Preview() error = %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
+
+type fakeComparer struct {
+	pr, against string
+}
+
+func (f *fakeComparer) ComparePreviews(ctx context.Context, pr, against string) (*CompareResult, error) {
+	f.pr, f.against = pr, against
+	return &CompareResult{PRNumber: 123, AgainstPRNumber: 456, Resources: []ComparedResource{
+		{Resource: "XBucket/logs", Status: CompareOnlyInPR},
+		{Resource: "XCache/sessions", Status: CompareOnlyInAgainst},
+		{Resource: "XNetwork/vpc", Status: CompareChanged, Fields: []ComparedField{
+			{Path: "spec.parameters.cidr", PR: `"10.1.0.0/16"`, Against: `"10.0.0.0/16"`},
+			{Path: "spec.parameters.ipv6", Against: "true"},
+		}},
+	}}, nil
+}
+
+func TestCompareRoundTrip(t *testing.T) {
+	comparer := &fakeComparer{}
+	server := httptest.NewServer(NewHandler(&fakeSource{}, nil, nil, comparer, nil))
+	defer server.Close()
+
+	result, err := Compare(context.Background(), server.Client(), server.URL, "", "123", "feature/network")
+	if err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+	if comparer.pr != "123" || comparer.against != "feature/network" {
+		t.Errorf("compared %q against %q, want 123 against feature/network", comparer.pr, comparer.against)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCompare(&buf, result); err != nil {
+		t.Fatalf("WriteCompare() error = %v", err)
+	}
+	want := `PR #123 compared to PR #456:
++ XBucket/logs (only in PR #123)
+- XCache/sessions (only in PR #456)
+~ XNetwork/vpc
+    spec.parameters.cidr: "10.1.0.0/16" -> "10.0.0.0/16"
+    spec.parameters.ipv6: (unset) -> true
+`
+	if buf.String() != want {
+		t.Errorf("WriteCompare() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestCompare_Rejected(t *testing.T) {
+	tests := []struct {
+		name     string
+		comparer PreviewComparer
+		against  string
+		want     string
+	}{
+		{name: "no comparer", against: "456", want: "501"},
+		{name: "missing ref", comparer: &fakeComparer{}, against: "", want: "400"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(NewHandler(&fakeSource{}, nil, nil, tt.comparer, nil))
+			defer server.Close()
+
+			_, err := Compare(context.Background(), server.Client(), server.URL, "", "123", tt.against)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Compare() error = %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
+
+type fakeOwnerFinder struct {
+	kind, name, namespace string
+}
+
+func (f *fakeOwnerFinder) WhoOwns(ctx context.Context, kind, name, namespace string) (*OwnerResult, error) {
+	f.kind, f.name, f.namespace = kind, name, namespace
+	if name == "missing" {
+		return nil, fmt.Errorf("%s/%s: %w", kind, name, ErrResourceNotFound)
+	}
+	planned := time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC)
+	return &OwnerResult{
+		Resource:   "XNetwork/pr-42-vpc",
+		APIVersion: "platform.example.com/v1alpha1",
+		Namespace:  "previews",
+		PRNumber:   42,
+		BaseName:   "vpc",
+		Repository: "acme/network",
+		LastPlan:   &store.PlanRecord{Time: planned, Resources: 3, Changed: 1, Failed: 1},
+		Queue:      &QueueItem{PRNumber: 42, State: workqueue.StateFailed, Failures: 2, LastError: "diff failed"},
+	}, nil
+}
+
+func TestWhoOwnsRoundTrip(t *testing.T) {
+	owners := &fakeOwnerFinder{}
+	server := httptest.NewServer(NewHandler(&fakeSource{}, nil, nil, nil, owners))
+	defer server.Close()
+
+	result, err := WhoOwns(context.Background(), server.Client(), server.URL, "", "XNetwork/pr-42-vpc", "previews")
+	if err != nil {
+		t.Fatalf("WhoOwns() error = %v", err)
+	}
+	if owners.kind != "XNetwork" || owners.name != "pr-42-vpc" || owners.namespace != "previews" {
+		t.Errorf("looked up %s/%s in %q, want XNetwork/pr-42-vpc in previews", owners.kind, owners.name, owners.namespace)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteOwner(&buf, result, result.LastPlan.Time.Add(time.Minute)); err != nil {
+		t.Fatalf("WriteOwner() error = %v", err)
+	}
+	want := `Resource:    previews/XNetwork/pr-42-vpc (platform.example.com/v1alpha1)
+PR:          #42
+Base name:   vpc
+Repository:  acme/network
+Last plan:   1m0s ago, 3 resources, 1 changed, 1 failed, comment not updated
+Queue:       failed (2 failures, last error: diff failed)
+`
+	if buf.String() != want {
+		t.Errorf("WriteOwner() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteOwner_Production(t *testing.T) {
+	var buf bytes.Buffer
+	result := &OwnerResult{Resource: "XNetwork/vpc", APIVersion: "platform.example.com/v1alpha1", BaseName: "vpc"}
+	if err := WriteOwner(&buf, result, time.Now()); err != nil {
+		t.Fatalf("WriteOwner() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "none (production resource)") || strings.Contains(buf.String(), "Last plan") {
+		t.Errorf("WriteOwner() = %q, want a production resource without plan", buf.String())
+	}
+}
+
+func TestWhoOwns_Rejected(t *testing.T) {
+	tests := []struct {
+		name     string
+		owners   OwnerFinder
+		resource string
+		want     string
+	}{
+		{name: "no finder", resource: "XNetwork/vpc", want: "501"},
+		{name: "no kind", owners: &fakeOwnerFinder{}, resource: "vpc", want: "400"},
+		{name: "not found", owners: &fakeOwnerFinder{}, resource: "XNetwork/missing", want: "404"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(NewHandler(&fakeSource{}, nil, nil, nil, tt.owners))
+			defer server.Close()
+
+			_, err := WhoOwns(context.Background(), server.Client(), server.URL, "", tt.resource, "")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("WhoOwns() error = %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithProfiling(t *testing.T) {
+	server := httptest.NewServer(WithProfiling(NewHandler(&fakeSource{leader: true}, nil, nil, nil, nil)))
+	defer server.Close()
+
+	for path, want := range map[string]int{
+		ProfilingPath + "heap?debug=1": http.StatusOK,
+		StatusPath:                     http.StatusOK,
+		"/unknown":                     http.StatusNotFound,
+	} {
+		resp, err := server.Client().Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s error = %v", path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("GET %s status = %d, want %d", path, resp.StatusCode, want)
+		}
+	}
+}
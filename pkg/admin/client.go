@@ -0,0 +1,283 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/millstonehq/crossplane-plan/pkg/store"
+)
+
+// FetchStatus queries a replica's admin API
+// token is sent as a bearer token when set
+func FetchStatus(ctx context.Context, client *http.Client, baseURL, token string) (*Status, error) {
+	var status Status
+	if err := call(ctx, client, http.MethodGet, strings.TrimSuffix(baseURL, "/")+StatusPath, token, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// Cleanup asks a replica to delete or mark stale the comments of PRs without previews
+func Cleanup(ctx context.Context, client *http.Client, baseURL, token, action string, dryRun bool) (*CleanupResult, error) {
+	query := url.Values{"action": {action}, "dryRun": {strconv.FormatBool(dryRun)}}
+	var result CleanupResult
+	if err := call(ctx, client, http.MethodPost, strings.TrimSuffix(baseURL, "/")+CleanupPath+"?"+query.Encode(), token, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Preview asks a replica for the comments a PR's plan would post
+func Preview(ctx context.Context, client *http.Client, baseURL, token string, prNumber int) (*PreviewResult, error) {
+	query := url.Values{"pr": {strconv.Itoa(prNumber)}}
+	var result PreviewResult
+	if err := call(ctx, client, http.MethodGet, strings.TrimSuffix(baseURL, "/")+PreviewPath+"?"+query.Encode(), token, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Compare asks a replica what differs between the preview environments of two PRs
+// pr and against are PR numbers or branches of open PRs
+func Compare(ctx context.Context, client *http.Client, baseURL, token, pr, against string) (*CompareResult, error) {
+	query := url.Values{"pr": {pr}, "against": {against}}
+	var result CompareResult
+	if err := call(ctx, client, http.MethodGet, strings.TrimSuffix(baseURL, "/")+ComparePath+"?"+query.Encode(), token, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// WhoOwns asks a replica which PR a resource belongs to
+// resource is "Kind/name"; namespace is optional
+func WhoOwns(ctx context.Context, client *http.Client, baseURL, token, resource, namespace string) (*OwnerResult, error) {
+	query := url.Values{"resource": {resource}}
+	if namespace != "" {
+		query.Set("namespace", namespace)
+	}
+	var result OwnerResult
+	if err := call(ctx, client, http.MethodGet, strings.TrimSuffix(baseURL, "/")+WhoOwnsPath+"?"+query.Encode(), token, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// call sends an admin API request and decodes its JSON response into out
+func call(ctx context.Context, client *http.Client, method, endpoint, token string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("invalid admin URL: %w", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to query admin API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("admin API returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode admin API response: %w", err)
+	}
+	return nil
+}
+
+// WriteCleanup prints a cleanup's comments for operators
+func WriteCleanup(w io.Writer, result *CleanupResult) error {
+	if len(result.Comments) == 0 {
+		_, err := fmt.Fprintln(w, "No orphaned comments found")
+		return err
+	}
+
+	verb := map[string]string{CleanupDelete: "deleted", CleanupStale: "marked stale"}[result.Action]
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PR\tREASON\tRESULT")
+	for _, comment := range result.Comments {
+		status := verb
+		switch {
+		case result.DryRun:
+			status = "would be " + verb
+		case !comment.Done:
+			status = "unchanged"
+		}
+		fmt.Fprintf(tw, "#%d\t%s\t%s\n", comment.PRNumber, comment.Reason, status)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if result.DryRun {
+		_, err := fmt.Fprintln(w, "Dry run: nothing was changed. Pass --dry-run=false to apply.")
+		return err
+	}
+	return nil
+}
+
+// WritePreview prints the comments of a preview exactly as they would be posted
+// Comments for several repositories, or held back by a freeze, are preceded by a header line
+func WritePreview(w io.Writer, result *PreviewResult) error {
+	if len(result.Comments) == 0 {
+		_, err := fmt.Fprintf(w, "PR #%d has nothing to comment\n", result.PRNumber)
+		return err
+	}
+
+	for i, comment := range result.Comments {
+		if len(result.Comments) > 1 || comment.Held {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			header := "===== " + comment.Repository
+			if comment.Held {
+				header += " (held back by a freeze window)"
+			}
+			fmt.Fprintln(w, header+" =====")
+		}
+		if _, err := fmt.Fprintln(w, strings.TrimSuffix(comment.Body, "\n")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteCompare prints what differs between two previews, one resource per line followed by its fields
+func WriteCompare(w io.Writer, result *CompareResult) error {
+	if len(result.Resources) == 0 {
+		_, err := fmt.Fprintf(w, "The previews of PR #%d and PR #%d don't differ\n", result.PRNumber, result.AgainstPRNumber)
+		return err
+	}
+
+	fmt.Fprintf(w, "PR #%d compared to PR #%d:\n", result.PRNumber, result.AgainstPRNumber)
+	for _, resource := range result.Resources {
+		switch resource.Status {
+		case CompareOnlyInPR:
+			fmt.Fprintf(w, "+ %s (only in PR #%d)\n", resource.Resource, result.PRNumber)
+		case CompareOnlyInAgainst:
+			fmt.Fprintf(w, "- %s (only in PR #%d)\n", resource.Resource, result.AgainstPRNumber)
+		default:
+			fmt.Fprintf(w, "~ %s\n", resource.Resource)
+		}
+		for _, field := range resource.Fields {
+			if _, err := fmt.Fprintf(w, "    %s: %s -> %s\n", field.Path, unsetOr(field.PR), unsetOr(field.Against)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// unsetOr returns a compared value, or "(unset)" when the field isn't set
+func unsetOr(value string) string {
+	if value == "" {
+		return "(unset)"
+	}
+	return value
+}
+
+// WriteOwner prints a reverse lookup for operators, one setting per line
+func WriteOwner(w io.Writer, result *OwnerResult, now time.Time) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	resource := result.Resource
+	if result.Namespace != "" {
+		resource = result.Namespace + "/" + resource
+	}
+	fmt.Fprintf(tw, "Resource:\t%s (%s)\n", resource, result.APIVersion)
+	if result.PRNumber == 0 {
+		fmt.Fprintln(tw, "PR:\tnone (production resource)")
+	} else {
+		fmt.Fprintf(tw, "PR:\t#%d\n", result.PRNumber)
+	}
+	fmt.Fprintf(tw, "Base name:\t%s\n", result.BaseName)
+	if result.PRNumber != 0 {
+		repository := result.Repository
+		if result.RepositoryError != "" {
+			repository = "rejected: " + result.RepositoryError
+		}
+		fmt.Fprintf(tw, "Repository:\t%s\n", repository)
+	}
+	if result.Ignored {
+		fmt.Fprintln(tw, "Ignored:\tyes (excluded from plans)")
+	}
+	if result.PRNumber != 0 {
+		fmt.Fprintf(tw, "Last plan:\t%s\n", lastPlan(result.LastPlan, now))
+		if result.Queue != nil {
+			queue := result.Queue.State
+			if result.Queue.LastError != "" {
+				queue += fmt.Sprintf(" (%d failures, last error: %s)", result.Queue.Failures, result.Queue.LastError)
+			}
+			fmt.Fprintf(tw, "Queue:\t%s\n", queue)
+		}
+	}
+	return tw.Flush()
+}
+
+// lastPlan summarizes a PR's last plan
+func lastPlan(record *store.PlanRecord, now time.Time) string {
+	if record == nil {
+		return "never planned"
+	}
+	summary := fmt.Sprintf("%s ago, %d resources, %d changed", now.Sub(record.Time).Round(time.Second), record.Resources, record.Changed)
+	if record.Failed > 0 {
+		summary += fmt.Sprintf(", %d failed", record.Failed)
+	}
+	if !record.Posted {
+		summary += ", comment not updated"
+	}
+	return summary
+}
+
+// WriteStatus prints a status as a table for operators
+func WriteStatus(w io.Writer, status *Status, now time.Time) error {
+	if !status.Leader {
+		fmt.Fprintln(w, "This replica is not the leader; only the leader processes PRs. Query the leader pod for its queue.")
+	}
+	if len(status.Queue) == 0 {
+		_, err := fmt.Fprintln(w, "Work queue is empty")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PR\tSTATE\tQUEUED\tDEBOUNCE\tRUNNING\tFAILURES\tLAST ERROR")
+	for _, item := range status.Queue {
+		fmt.Fprintf(tw, "%s#%d\t%s\t%s\t%s\t%s\t%d\t%s\n",
+			item.Repository,
+			item.PRNumber,
+			item.State,
+			sinceOrDash(item.EnqueuedAt, now),
+			debounceOrDash(item),
+			sinceOrDash(item.StartedAt, now),
+			item.Failures,
+			item.LastError,
+		)
+	}
+	return tw.Flush()
+}
+
+// sinceOrDash formats the time elapsed since t, or "-" when unset
+func sinceOrDash(t *time.Time, now time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return now.Sub(*t).Round(time.Second).String() + " ago"
+}
+
+// debounceOrDash formats the remaining debounce of a pending PR
+func debounceOrDash(item QueueItem) string {
+	if item.DebounceRemainingSeconds <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1fs", item.DebounceRemainingSeconds)
+}
@@ -0,0 +1,22 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/pprof"
+)
+
+// ProfilingPath serves Go runtime profiles (heap, goroutines, CPU, ...) in the pprof format
+const ProfilingPath = "/debug/pprof/"
+
+// WithProfiling serves pprof profiles under ProfilingPath in addition to handler
+// e.g. go tool pprof http://localhost:8081/debug/pprof/heap
+func WithProfiling(handler http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(ProfilingPath, pprof.Index)
+	mux.HandleFunc(ProfilingPath+"cmdline", pprof.Cmdline)
+	mux.HandleFunc(ProfilingPath+"profile", pprof.Profile)
+	mux.HandleFunc(ProfilingPath+"symbol", pprof.Symbol)
+	mux.HandleFunc(ProfilingPath+"trace", pprof.Trace)
+	mux.Handle("/", handler)
+	return mux
+}
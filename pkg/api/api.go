@@ -0,0 +1,222 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/millstonehq/crossplane-plan/pkg/argocd"
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+	"github.com/millstonehq/crossplane-plan/pkg/severity"
+	"github.com/millstonehq/crossplane-plan/pkg/store"
+)
+
+// PlansPath serves the latest plan and plan history of a PR: GET /plans/{owner}/{repo}/{pr}
+const PlansPath = "/plans/"
+
+// ArgoCD change actions
+const (
+	ActionAdded    = "added"
+	ActionModified = "modified"
+	ActionDeleted  = "deleted"
+	ActionOrphaned = "orphaned" // not managed by any Application, so left unchanged
+)
+
+// PlanSource provides the plans served by the API
+type PlanSource interface {
+	// LatestPlan returns the latest plan of a PR, or nil if it has none
+	LatestPlan(ctx context.Context, repo string, prNumber int) (*Plan, error)
+
+	// PlanHistory returns the recent plans of a PR, oldest first
+	PlanHistory(ctx context.Context, repo string, prNumber int) ([]store.PlanRecord, error)
+}
+
+// PlanResponse is the API's view of a PR's plans
+type PlanResponse struct {
+	Repository string             `json:"repository"`
+	PRNumber   int                `json:"prNumber"`
+	Latest     *Plan              `json:"latest"`
+	History    []store.PlanRecord `json:"history"`
+}
+
+// Plan is the structured result of planning a PR
+type Plan struct {
+	Time      time.Time  `json:"time"`
+	Resources []Resource `json:"resources"`
+	// ArgoCD lists the changes of the PR's ArgoCD applications (additions and modifications
+	// of non-XR resources; deletions also appear in Resources)
+	ArgoCD []AppChange `json:"argocd,omitempty"`
+}
+
+// Resource is the diff of one XR (or deleted resource) against production
+type Resource struct {
+	ID         string `json:"id"`
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	Name       string `json:"name,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+	HasChanges bool   `json:"hasChanges"`
+	Summary    string `json:"summary"`
+	Diff       string `json:"diff,omitempty"`
+	// Action is create, update, delete, no-op or error
+	Action differ.Action `json:"action"`
+	// Severity is info, change, destructive or error
+	Severity severity.Level `json:"severity,omitempty"`
+	// Error is why the resource could not be planned
+	Error string `json:"error,omitempty"`
+	// ProductionDrift predates the PR (three-way Git comparisons only)
+	ProductionDrift string `json:"productionDrift,omitempty"`
+	Links           []Link `json:"links,omitempty"`
+	// Sources are the changed files of the PR that likely declare the resource
+	Sources []Link `json:"sources,omitempty"`
+	// Ordering lists the changed sync-wave and hook annotations
+	Ordering []OrderingChange `json:"ordering,omitempty"`
+}
+
+// OrderingChange is a changed annotation controlling apply ordering or hooks
+type OrderingChange struct {
+	Annotation string `json:"annotation"`
+	Before     string `json:"before,omitempty"`
+	After      string `json:"after,omitempty"`
+}
+
+// Link is a deep link for triaging a resource
+type Link struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// AppChange is a resource added, modified or deleted by the PR's ArgoCD applications
+type AppChange struct {
+	Action     string `json:"action"`
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace,omitempty"`
+	Diff       string `json:"diff,omitempty"`
+}
+
+// NewPlan converts the items of a plan to its API representation
+// appDiff is optional
+func NewPlan(items []differ.PlanItem, appDiff *argocd.AppDiff) *Plan {
+	plan := &Plan{Time: time.Now(), Resources: make([]Resource, 0, len(items))}
+	for _, item := range items {
+		result := item.DiffResult
+		resource := Resource{
+			ID:              item.Name,
+			Action:          item.Action,
+			Kind:            item.GroupKind.Kind,
+			HasChanges:      result.HasChanges,
+			Summary:         result.Summary,
+			Diff:            result.RawDiff,
+			Severity:        result.Severity,
+			Error:           result.Error,
+			ProductionDrift: result.ProductionDrift,
+		}
+		for _, link := range result.Links {
+			resource.Links = append(resource.Links, Link{Name: link.Name, URL: link.URL})
+		}
+		for _, source := range result.Sources {
+			resource.Sources = append(resource.Sources, Link{Name: source.Name, URL: source.URL})
+		}
+		for _, change := range result.OrderingChanges {
+			resource.Ordering = append(resource.Ordering, OrderingChange(change))
+		}
+		if result.XR != nil {
+			resource.APIVersion = result.XR.GetAPIVersion()
+			resource.Kind = result.XR.GetKind()
+			resource.Name = result.XR.GetName()
+			resource.Namespace = result.XR.GetNamespace()
+		}
+		plan.Resources = append(plan.Resources, resource)
+	}
+	sort.Slice(plan.Resources, func(i, j int) bool {
+		return plan.Resources[i].ID < plan.Resources[j].ID
+	})
+
+	if appDiff != nil {
+		for _, change := range appDiff.Additions {
+			plan.ArgoCD = append(plan.ArgoCD, appChange(ActionAdded, change))
+		}
+		for _, change := range appDiff.Modifications {
+			plan.ArgoCD = append(plan.ArgoCD, appChange(ActionModified, change))
+		}
+		for _, deletion := range appDiff.Deletions {
+			plan.ArgoCD = append(plan.ArgoCD, AppChange{
+				Action:     ActionDeleted,
+				APIVersion: deletion.GVK.GroupVersion().String(),
+				Kind:       deletion.GVK.Kind,
+				Name:       deletion.Name,
+				Namespace:  deletion.Namespace,
+				Diff:       deletion.RawDiff,
+			})
+		}
+		for _, orphan := range appDiff.Orphaned {
+			plan.ArgoCD = append(plan.ArgoCD, AppChange{
+				Action:     ActionOrphaned,
+				APIVersion: orphan.GVK.GroupVersion().String(),
+				Kind:       orphan.GVK.Kind,
+				Name:       orphan.Name,
+				Namespace:  orphan.Namespace,
+			})
+		}
+	}
+	return plan
+}
+
+// appChange converts an ArgoCD resource change
+func appChange(action string, change argocd.ResourceChange) AppChange {
+	return AppChange{
+		Action:     action,
+		APIVersion: change.GVK.GroupVersion().String(),
+		Kind:       change.GVK.Kind,
+		Name:       change.Name,
+		Namespace:  change.Namespace,
+		Diff:       change.RawDiff,
+	}
+}
+
+// NewHandler returns the read-only plan API handler
+func NewHandler(source PlanSource) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+PlansPath+"{owner}/{repo}/{pr}", func(w http.ResponseWriter, r *http.Request) {
+		repo := r.PathValue("owner") + "/" + r.PathValue("repo")
+		prNumber, err := strconv.Atoi(r.PathValue("pr"))
+		if err != nil || prNumber <= 0 {
+			http.Error(w, "PR number must be a positive integer", http.StatusBadRequest)
+			return
+		}
+
+		latest, err := source.LatestPlan(r.Context(), repo, prNumber)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		history, err := source.PlanHistory(r.Context(), repo, prNumber)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if latest == nil && len(history) == 0 {
+			http.Error(w, "no plans for this PR", http.StatusNotFound)
+			return
+		}
+		if history == nil {
+			history = []store.PlanRecord{}
+		}
+
+		writeJSON(w, PlanResponse{Repository: repo, PRNumber: prNumber, Latest: latest, History: history})
+	})
+	return mux
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
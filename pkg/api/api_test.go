@@ -0,0 +1,102 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/millstonehq/crossplane-plan/pkg/argocd"
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+	"github.com/millstonehq/crossplane-plan/pkg/severity"
+	"github.com/millstonehq/crossplane-plan/pkg/store"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type fakeSource struct {
+	plans   map[string]*Plan
+	history map[string][]store.PlanRecord
+}
+
+func (f *fakeSource) LatestPlan(ctx context.Context, repo string, prNumber int) (*Plan, error) {
+	return f.plans[repo], nil
+}
+
+func (f *fakeSource) PlanHistory(ctx context.Context, repo string, prNumber int) ([]store.PlanRecord, error) {
+	return f.history[repo], nil
+}
+
+func TestNewPlan(t *testing.T) {
+	xr := &unstructured.Unstructured{}
+	xr.SetAPIVersion("example.org/v1alpha1")
+	xr.SetKind("XDatabase")
+	xr.SetName("db")
+
+	items := []differ.PlanItem{
+		differ.NewPlanItem("pr-1-db", &differ.DiffResult{XR: xr, HasChanges: true, Summary: "1 resource modified", RawDiff: "+ size: large", Severity: severity.Change}),
+	}
+	items = differ.AddDeletion(items, schema.GroupVersionKind{Group: "s3.aws.upbound.io", Version: "v1beta1", Kind: "Bucket"}, "", "old-state", &differ.DiffResult{HasChanges: true, Summary: "will be deleted"})
+	plan := NewPlan(items, &argocd.AppDiff{
+		Additions: []argocd.ResourceChange{{GVK: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, Name: "settings"}},
+		Orphaned:  []argocd.ResourceOrphan{{GVK: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, Name: "leftover"}},
+	})
+
+	if len(plan.Resources) != 2 || plan.Resources[0].ID != "Bucket.s3.aws.upbound.io/old-state" {
+		t.Fatalf("Resources = %+v, want 2 sorted by ID", plan.Resources)
+	}
+	if deleted := plan.Resources[0]; deleted.Action != differ.ActionDelete || deleted.Kind != "Bucket" {
+		t.Errorf("Resources[0] = %+v", deleted)
+	}
+	db := plan.Resources[1]
+	if db.Action != differ.ActionUpdate || db.Kind != "XDatabase" || db.Name != "db" || db.APIVersion != "example.org/v1alpha1" || db.Diff != "+ size: large" || db.Severity != severity.Change {
+		t.Errorf("Resources[1] = %+v", db)
+	}
+	if len(plan.ArgoCD) != 2 || plan.ArgoCD[0].Action != ActionAdded || plan.ArgoCD[0].APIVersion != "v1" || plan.ArgoCD[1].Action != ActionOrphaned {
+		t.Errorf("ArgoCD = %+v", plan.ArgoCD)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	source := &fakeSource{
+		plans:   map[string]*Plan{"owner/repo": {Resources: []Resource{{ID: "pr-1-db", HasChanges: true}}}},
+		history: map[string][]store.PlanRecord{"owner/repo": {{Resources: 1, Changed: 1, Posted: true}}},
+	}
+	server := httptest.NewServer(NewHandler(source))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		path string
+		want int
+	}{
+		{name: "known PR", path: "/plans/owner/repo/1", want: http.StatusOK},
+		{name: "unknown PR", path: "/plans/owner/other/1", want: http.StatusNotFound},
+		{name: "invalid PR number", path: "/plans/owner/repo/abc", want: http.StatusBadRequest},
+		{name: "missing PR number", path: "/plans/owner/repo", want: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := server.Client().Get(server.URL + tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Fatalf("GET %s = %d, want %d", tt.path, resp.StatusCode, tt.want)
+			}
+			if tt.want != http.StatusOK {
+				return
+			}
+
+			var got PlanResponse
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Repository != "owner/repo" || got.PRNumber != 1 || got.Latest == nil || len(got.History) != 1 {
+				t.Errorf("response = %+v", got)
+			}
+		})
+	}
+}
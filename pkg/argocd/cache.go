@@ -0,0 +1,76 @@
+package argocd
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+// appCache serves Application reads from a shared informer
+type appCache struct {
+	lister cache.GenericLister
+	synced atomic.Bool // reads go to the API server until the informer has synced
+}
+
+// StartCache backs Application reads with a shared informer on the ArgoCD namespace,
+// resynced every resync, instead of reading Applications from the API server on every plan
+// The informer stops with ctx; reads go to the API server until it has synced
+func (c *Client) StartCache(ctx context.Context, resync time.Duration) {
+	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(c.dynamicClient, resync, c.namespace, nil)
+	informer := factory.ForResource(ApplicationGVR)
+	apps := &appCache{lister: informer.Lister()}
+	c.cache = apps
+
+	factory.Start(ctx.Done())
+	go func() {
+		if !cache.WaitForCacheSync(ctx.Done(), informer.Informer().HasSynced) {
+			return
+		}
+		apps.synced.Store(true)
+		c.logger.Info("ArgoCD Application cache synced", "namespace", c.namespace, "resync", resync)
+	}()
+}
+
+// cachedApps returns the Application cache, or nil if it isn't enabled or hasn't synced
+func (c *Client) cachedApps() *appCache {
+	if c.cache == nil || !c.cache.synced.Load() {
+		return nil
+	}
+	return c.cache
+}
+
+// listApplications returns the Applications in the ArgoCD namespace, in allowed projects
+func (c *Client) listApplications(ctx context.Context) ([]*unstructured.Unstructured, error) {
+	if apps := c.cachedApps(); apps != nil {
+		objs, err := apps.lister.ByNamespace(c.namespace).List(labels.Everything())
+		if err != nil {
+			return nil, fmt.Errorf("failed to list cached applications: %w", err)
+		}
+		list := make([]*unstructured.Unstructured, 0, len(objs))
+		for _, obj := range objs {
+			if app, ok := obj.(*unstructured.Unstructured); ok && c.projectAllowed(appProject(app)) {
+				list = append(list, app)
+			}
+		}
+		return list, nil
+	}
+
+	apps, err := c.dynamicClient.Resource(ApplicationGVR).Namespace(c.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list applications: %w", err)
+	}
+	list := make([]*unstructured.Unstructured, 0, len(apps.Items))
+	for i := range apps.Items {
+		if c.projectAllowed(appProject(&apps.Items[i])) {
+			list = append(list, &apps.Items[i])
+		}
+	}
+	return list, nil
+}
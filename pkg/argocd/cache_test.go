@@ -0,0 +1,63 @@
+package argocd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestStartCache(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dynamicClient := fake.NewSimpleDynamicClient(runtime.NewScheme(),
+		newApp("pr-123-myapp", nil),
+		newApp("myapp", nil),
+	)
+	client := NewClient(dynamicClient, "argocd", "pr-", "", logr.Discard())
+	client.StartCache(ctx, time.Minute)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for client.cachedApps() == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("cache did not sync")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	apps, err := client.FindPRApplications(ctx, 123)
+	if err != nil || len(apps) != 1 || apps[0] != "pr-123-myapp" {
+		t.Errorf("FindPRApplications() = %v, %v, want [pr-123-myapp]", apps, err)
+	}
+	app, err := client.getApplication(ctx, "myapp")
+	if err != nil || app.GetName() != "myapp" {
+		t.Fatalf("getApplication() = %v, %v, want myapp", app, err)
+	}
+	app.SetLabels(map[string]string{"mutated": "true"})
+	if cached, _ := client.getApplication(ctx, "myapp"); cached.GetLabels()["mutated"] != "" {
+		t.Error("getApplication() returned the informer's object")
+	}
+	if _, err := client.getApplication(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("getApplication() of a missing app error = %v, want ErrNotFound", err)
+	}
+
+	// Reads follow the watch
+	if err := dynamicClient.Resource(ApplicationGVR).Namespace("argocd").Delete(ctx, "pr-123-myapp", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	for {
+		if apps, _ := client.FindPRApplications(ctx, 123); len(apps) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("cache did not observe the deletion")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -2,11 +2,16 @@ package argocd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/go-logr/logr"
+	"github.com/millstonehq/crossplane-plan/pkg/errclass"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -15,9 +20,16 @@ import (
 
 var (
 	// ErrNotFound indicates ArgoCD Application or ArgoCD itself is not available
-	ErrNotFound = fmt.Errorf("argocd application not found")
+	ErrNotFound = errclass.Wrap(errclass.ErrNotFound, errors.New("argocd application not found"))
 )
 
+// ApplicationGVR is the resource of ArgoCD Applications
+var ApplicationGVR = schema.GroupVersionResource{
+	Group:    "argoproj.io",
+	Version:  "v1alpha1",
+	Resource: "applications",
+}
+
 // Client handles interactions with ArgoCD Applications
 type Client struct {
 	dynamicClient dynamic.Interface
@@ -25,6 +37,11 @@ type Client struct {
 	logger        logr.Logger
 	prPrefix      string // e.g., "pr-"
 	prSuffix      string // e.g., "" (not commonly used)
+	manifests     *ManifestClient
+	tracking      TrackingMethod
+	cache         *appCache // nil unless StartCache was called
+	mu            sync.RWMutex
+	projects      []string // allowed AppProjects (empty for all)
 }
 
 // AppDiff represents the difference between two ArgoCD Applications
@@ -32,6 +49,7 @@ type AppDiff struct {
 	Additions     []ResourceChange
 	Modifications []ResourceChange
 	Deletions     []ResourceDeletion
+	Orphaned      []ResourceOrphan // orphaned resources of the production app (needs the API server)
 	RawDiff       string
 }
 
@@ -51,6 +69,14 @@ type ResourceDeletion struct {
 	RawDiff   string
 }
 
+// ResourceOrphan is a resource in an Application's namespace that no Application manages
+// ArgoCD leaves it alone, so syncing the PR doesn't change it
+type ResourceOrphan struct {
+	GVK       schema.GroupVersionKind
+	Name      string
+	Namespace string
+}
+
 // NewClient creates a new ArgoCD client
 func NewClient(dynamicClient dynamic.Interface, namespace, prPrefix, prSuffix string, logger logr.Logger) *Client {
 	return &Client{
@@ -62,6 +88,11 @@ func NewClient(dynamicClient dynamic.Interface, namespace, prPrefix, prSuffix st
 	}
 }
 
+// Namespace returns the namespace ArgoCD Applications are read from
+func (c *Client) Namespace() string {
+	return c.namespace
+}
+
 // GetProductionAppName strips PR prefix/suffix to get production app name
 // Example: "pr-123-myapp" with prefix "pr-" → "myapp"
 func (c *Client) GetProductionAppName(prAppName string) string {
@@ -83,6 +114,43 @@ func (c *Client) GetProductionAppName(prAppName string) string {
 	return result
 }
 
+// PRNumber extracts the PR number from a PR app name, or returns 0 for other apps
+// Example: "pr-123-myapp" with prefix "pr-" → 123
+func (c *Client) PRNumber(appName string) int {
+	var patterns []*regexp.Regexp
+	if c.prPrefix != "" {
+		patterns = append(patterns, regexp.MustCompile(fmt.Sprintf(`^%s(\d+)[-_]`, regexp.QuoteMeta(c.prPrefix))))
+	}
+	if c.prSuffix != "" {
+		patterns = append(patterns, regexp.MustCompile(fmt.Sprintf(`%s[-_](\d+)$`, regexp.QuoteMeta(c.prSuffix))))
+	}
+
+	for _, pattern := range patterns {
+		if match := pattern.FindStringSubmatch(appName); match != nil {
+			if number, err := strconv.Atoi(match[1]); err == nil {
+				return number
+			}
+		}
+	}
+	return 0
+}
+
+// FindPRApplications returns the names of the PR apps deployed for a PR
+func (c *Client) FindPRApplications(ctx context.Context, prNumber int) ([]string, error) {
+	apps, err := c.listApplications(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, app := range apps {
+		if c.PRNumber(app.GetName()) == prNumber {
+			names = append(names, app.GetName())
+		}
+	}
+	return names, nil
+}
+
 // GetAppDiff compares two ArgoCD Applications and returns the diff
 func (c *Client) GetAppDiff(ctx context.Context, prAppName, prodAppName string) (*AppDiff, error) {
 	// Get both applications
@@ -91,8 +159,11 @@ func (c *Client) GetAppDiff(ctx context.Context, prAppName, prodAppName string)
 		return nil, fmt.Errorf("failed to get PR application %s: %w", prAppName, err)
 	}
 
-	prodApp, err := c.getApplication(ctx, prodAppName)
-	if err != nil {
+	var prodApp *unstructured.Unstructured
+	if prodAppName != "" {
+		prodApp, err = c.getApplication(ctx, prodAppName)
+	}
+	if prodApp == nil {
 		// Production app might not exist (new app scenario)
 		c.logger.Info("Production application not found, treating as new deployment", "app", prodAppName)
 		prResources := c.extractResourcesFromApp(prApp, "pr")
@@ -112,32 +183,67 @@ func (c *Client) GetAppDiff(ctx context.Context, prAppName, prodAppName string)
 		}, nil
 	}
 
+	// Compare rendered manifests at both apps' target revisions when the API server is available
+	if c.manifests != nil {
+		diff, err := c.manifestDiff(ctx, prApp, prodApp)
+		if err == nil {
+			c.addOrphans(ctx, diff, prodApp)
+			return diff, nil
+		}
+		c.logger.Error(err, "manifest diff failed, comparing resource lists",
+			"prApp", prAppName, "prodApp", prodAppName)
+	}
+
 	// Extract resources from both apps
 	prResources := c.extractResourcesFromApp(prApp, "pr")
 	prodResources := c.extractResourcesFromApp(prodApp, "prod")
 
 	// Compare and build diff
 	diff := c.compareResources(prResources, prodResources)
+	c.addOrphans(ctx, diff, prodApp)
 
 	return diff, nil
 }
 
 // getApplication retrieves an ArgoCD Application by name
 func (c *Client) getApplication(ctx context.Context, name string) (*unstructured.Unstructured, error) {
-	gvr := schema.GroupVersionResource{
-		Group:    "argoproj.io",
-		Version:  "v1alpha1",
-		Resource: "applications",
+	if apps := c.cachedApps(); apps != nil {
+		obj, err := apps.lister.ByNamespace(c.namespace).Get(name)
+		if err != nil {
+			return nil, classifyAPIError(err, fmt.Errorf("%w: %s", ErrNotFound, err))
+		}
+		if app, ok := obj.(*unstructured.Unstructured); ok {
+			// Cached objects are shared with the informer
+			return c.allowedApp(app.DeepCopy())
+		}
 	}
 
-	app, err := c.dynamicClient.Resource(gvr).Namespace(c.namespace).Get(ctx, name, metav1.GetOptions{})
+	app, err := c.dynamicClient.Resource(ApplicationGVR).Namespace(c.namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		return nil, fmt.Errorf("%w: %s", ErrNotFound, err)
+		// Access and throttling errors keep their own class for retry decisions
+		return nil, classifyAPIError(err, fmt.Errorf("%w: %s", ErrNotFound, err))
 	}
 
+	return c.allowedApp(app)
+}
+
+// allowedApp returns app, or ErrNotFound if its project isn't allowed (see SetProjects)
+func (c *Client) allowedApp(app *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	if project := appProject(app); !c.projectAllowed(project) {
+		return nil, fmt.Errorf("%w: application %s is in project %s, which is not allowed", ErrNotFound, app.GetName(), project)
+	}
 	return app, nil
 }
 
+// classifyAPIError marks wrapped with the class of a Kubernetes API error's status code
+func classifyAPIError(err, wrapped error) error {
+	var status apierrors.APIStatus
+	if errors.As(err, &status) {
+		return errclass.WrapStatus(int(status.Status().Code), wrapped)
+	}
+	return wrapped
+}
+
 // extractResourcesFromApp extracts managed resources from an ArgoCD Application
 func (c *Client) extractResourcesFromApp(app *unstructured.Unstructured, context string) map[string]*ResourceInfo {
 	resources := make(map[string]*ResourceInfo)
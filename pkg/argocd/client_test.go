@@ -64,6 +64,48 @@ func TestGetProductionAppName(t *testing.T) {
 	}
 }
 
+func TestPRNumber(t *testing.T) {
+	tests := []struct {
+		name     string
+		appName  string
+		prPrefix string
+		prSuffix string
+		want     int
+	}{
+		{name: "prefix pattern", appName: "pr-123-myapp", prPrefix: "pr-", want: 123},
+		{name: "suffix pattern", appName: "myapp-pr-7", prSuffix: "-pr", want: 7},
+		{name: "production app", appName: "myapp", prPrefix: "pr-", want: 0},
+		{name: "prefix without number", appName: "pr-myapp", prPrefix: "pr-", want: 0},
+		{name: "no patterns", appName: "pr-123-myapp", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &Client{prPrefix: tt.prPrefix, prSuffix: tt.prSuffix}
+			if got := client.PRNumber(tt.appName); got != tt.want {
+				t.Errorf("PRNumber() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindPRApplications(t *testing.T) {
+	client := NewClient(fake.NewSimpleDynamicClient(runtime.NewScheme(),
+		newApp("pr-123-myapp", nil),
+		newApp("pr-123-other", nil),
+		newApp("pr-1234-myapp", nil),
+		newApp("myapp", nil),
+	), "argocd", "pr-", "", logr.Discard())
+
+	apps, err := client.FindPRApplications(context.Background(), 123)
+	if err != nil {
+		t.Fatalf("FindPRApplications() error = %v", err)
+	}
+	if len(apps) != 2 || apps[0] != "pr-123-myapp" || apps[1] != "pr-123-other" {
+		t.Errorf("FindPRApplications() = %v, want [pr-123-myapp pr-123-other]", apps)
+	}
+}
+
 func TestGetAppDiff(t *testing.T) {
 	scheme := runtime.NewScheme()
 	
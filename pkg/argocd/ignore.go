@@ -0,0 +1,124 @@
+package argocd
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// simpleJQPath matches jq path expressions that only select fields, e.g. ".spec.replicas"
+var simpleJQPath = regexp.MustCompile(`^(\.[A-Za-z_][A-Za-z0-9_-]*)+$`)
+
+// ignoreRule is an entry of an Application's spec.ignoreDifferences
+// An empty group is the core group; "*" matches any group or kind, and an empty name or namespace any resource
+type ignoreRule struct {
+	group, kind, name, namespace string
+	jsonPointers                 []string
+	jqPathExpressions            []string
+}
+
+// ignoreDifferences returns the rules of an Application's spec.ignoreDifferences
+func ignoreDifferences(app *unstructured.Unstructured) []ignoreRule {
+	entries, _, _ := unstructured.NestedSlice(app.Object, "spec", "ignoreDifferences")
+	rules := make([]ignoreRule, 0, len(entries))
+	for _, entry := range entries {
+		fields, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		rule := ignoreRule{
+			group:     getStringField(fields, "group"),
+			kind:      getStringField(fields, "kind"),
+			name:      getStringField(fields, "name"),
+			namespace: getStringField(fields, "namespace"),
+		}
+		rule.jsonPointers, _, _ = unstructured.NestedStringSlice(fields, "jsonPointers")
+		rule.jqPathExpressions, _, _ = unstructured.NestedStringSlice(fields, "jqPathExpressions")
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// matches reports whether the rule applies to obj
+func (r ignoreRule) matches(obj *unstructured.Unstructured) bool {
+	gvk := obj.GroupVersionKind()
+	return (r.group == "*" || r.group == gvk.Group) &&
+		(r.kind == "*" || r.kind == gvk.Kind) &&
+		(r.name == "" || r.name == obj.GetName()) &&
+		(r.namespace == "" || r.namespace == obj.GetNamespace())
+}
+
+// pointers returns the rule's paths as JSON pointers
+// jq expressions that do more than select fields can't be evaluated here and are returned as unsupported
+func (r ignoreRule) pointers() (pointers, unsupported []string) {
+	pointers = append(pointers, r.jsonPointers...)
+	for _, expr := range r.jqPathExpressions {
+		if !simpleJQPath.MatchString(expr) {
+			unsupported = append(unsupported, expr)
+			continue
+		}
+		pointers = append(pointers, strings.ReplaceAll(expr, ".", "/"))
+	}
+	return pointers, unsupported
+}
+
+// applyIgnoreDifferences returns a copy of obj without the fields ignored by rules,
+// and the jq expressions that couldn't be applied
+func applyIgnoreDifferences(obj *unstructured.Unstructured, rules []ignoreRule) (*unstructured.Unstructured, []string) {
+	var unsupported []string
+	clean := obj
+	for _, rule := range rules {
+		if !rule.matches(obj) {
+			continue
+		}
+		pointers, skipped := rule.pointers()
+		unsupported = append(unsupported, skipped...)
+		for _, pointer := range pointers {
+			if clean == obj {
+				clean = obj.DeepCopy()
+			}
+			removePointer(clean.Object, pointer)
+		}
+	}
+	return clean, unsupported
+}
+
+// removePointer removes the value at a JSON pointer (RFC 6901), if there is one
+func removePointer(doc map[string]interface{}, pointer string) {
+	if pointer == "" || pointer[0] != '/' {
+		return
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(tokens[i])
+	}
+
+	var parent interface{} = doc
+	for _, token := range tokens[:len(tokens)-1] {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			parent = node[token]
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(node) {
+				return
+			}
+			parent = node[index]
+		default:
+			return
+		}
+	}
+
+	last := tokens[len(tokens)-1]
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		delete(node, last)
+	case []interface{}:
+		// Blank the element rather than shifting later ones onto its index
+		if index, err := strconv.Atoi(last); err == nil && index >= 0 && index < len(node) {
+			node[index] = nil
+		}
+	}
+}
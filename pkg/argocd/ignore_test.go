@@ -0,0 +1,80 @@
+package argocd
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestApplyIgnoreDifferences(t *testing.T) {
+	deployment := func() *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":        "api",
+				"namespace":   "default",
+				"annotations": map[string]interface{}{"example.com/hash": "abc", "keep": "yes"},
+			},
+			"spec": map[string]interface{}{
+				"replicas":   int64(3),
+				"containers": []interface{}{map[string]interface{}{"image": "api:1"}},
+			},
+		}}
+	}
+
+	tests := []struct {
+		name            string
+		app             map[string]interface{}
+		wantSpec        map[string]interface{}
+		wantAnnotations map[string]string
+		wantUnsupported []string
+	}{
+		{
+			name: "json pointers",
+			app: map[string]interface{}{"ignoreDifferences": []interface{}{
+				map[string]interface{}{"group": "apps", "kind": "Deployment", "jsonPointers": []interface{}{"/spec/replicas", "/metadata/annotations/example.com~1hash"}},
+			}},
+			wantSpec:        map[string]interface{}{"containers": []interface{}{map[string]interface{}{"image": "api:1"}}},
+			wantAnnotations: map[string]string{"keep": "yes"},
+		},
+		{
+			name: "jq paths",
+			app: map[string]interface{}{"ignoreDifferences": []interface{}{
+				map[string]interface{}{"group": "*", "kind": "*", "jqPathExpressions": []interface{}{".spec.replicas", ".spec.containers[] | select(.name == \"x\")"}},
+			}},
+			wantSpec:        map[string]interface{}{"containers": []interface{}{map[string]interface{}{"image": "api:1"}}},
+			wantAnnotations: map[string]string{"example.com/hash": "abc", "keep": "yes"},
+			wantUnsupported: []string{".spec.containers[] | select(.name == \"x\")"},
+		},
+		{
+			name: "other resource",
+			app: map[string]interface{}{"ignoreDifferences": []interface{}{
+				map[string]interface{}{"group": "apps", "kind": "Deployment", "name": "worker", "jsonPointers": []interface{}{"/spec/replicas"}},
+				map[string]interface{}{"kind": "Deployment", "jsonPointers": []interface{}{"/spec/replicas"}}, // core group
+			}},
+			wantSpec:        deployment().Object["spec"].(map[string]interface{}),
+			wantAnnotations: map[string]string{"example.com/hash": "abc", "keep": "yes"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := deployment()
+			got, unsupported := applyIgnoreDifferences(obj, ignoreDifferences(newApp("myapp", tt.app)))
+			if !reflect.DeepEqual(got.Object["spec"], tt.wantSpec) {
+				t.Errorf("spec = %v, want %v", got.Object["spec"], tt.wantSpec)
+			}
+			if !reflect.DeepEqual(got.GetAnnotations(), tt.wantAnnotations) {
+				t.Errorf("annotations = %v, want %v", got.GetAnnotations(), tt.wantAnnotations)
+			}
+			if !reflect.DeepEqual(unsupported, tt.wantUnsupported) {
+				t.Errorf("unsupported = %v, want %v", unsupported, tt.wantUnsupported)
+			}
+			if !reflect.DeepEqual(obj, deployment()) {
+				t.Error("applyIgnoreDifferences() modified its input")
+			}
+		})
+	}
+}
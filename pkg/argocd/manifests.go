@@ -0,0 +1,327 @@
+package argocd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/crossplane-contrib/crossplane-diff/cmd/diff/renderer"
+	"github.com/millstonehq/crossplane-plan/pkg/errclass"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+// trackingMetadata is added by ArgoCD per Application and differs between the PR and
+// production apps, so it is dropped before comparing manifests
+var trackingMetadata = []string{
+	"argocd.argoproj.io/instance",
+	"argocd.argoproj.io/tracking-id",
+}
+
+// ManifestClient fetches rendered manifests from the ArgoCD API server
+// The API server renders them through the repo-server for a given revision
+type ManifestClient struct {
+	serverURL  string
+	token      string
+	httpClient *http.Client
+}
+
+// NewManifestClient creates a client for the ArgoCD API server at serverURL
+// token is an ArgoCD API token with get access to the Applications
+func NewManifestClient(serverURL, token string, httpClient *http.Client) *ManifestClient {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &ManifestClient{
+		serverURL:  strings.TrimSuffix(serverURL, "/"),
+		token:      token,
+		httpClient: httpClient,
+	}
+}
+
+// manifestResponse is the API server's ManifestResponse (only the fields used)
+type manifestResponse struct {
+	Manifests []string `json:"manifests"`
+	Revision  string   `json:"revision"`
+}
+
+// resourceTree is the API server's ApplicationTree (only the fields used)
+type resourceTree struct {
+	OrphanedNodes []struct {
+		Group     string `json:"group"`
+		Version   string `json:"version"`
+		Kind      string `json:"kind"`
+		Namespace string `json:"namespace"`
+		Name      string `json:"name"`
+	} `json:"orphanedNodes"`
+}
+
+// Manifests returns an Application's manifests rendered at revision
+func (m *ManifestClient) Manifests(ctx context.Context, appName, appNamespace, revision string) ([]*unstructured.Unstructured, error) {
+	query := url.Values{}
+	if revision != "" {
+		query.Set("revision", revision)
+	}
+	var manifests manifestResponse
+	if err := m.getJSON(ctx, appName, appNamespace, "manifests", query, &manifests); err != nil {
+		return nil, err
+	}
+
+	objects := make([]*unstructured.Unstructured, 0, len(manifests.Manifests))
+	for i, manifest := range manifests.Manifests {
+		obj := &unstructured.Unstructured{}
+		if err := yaml.Unmarshal([]byte(manifest), &obj.Object); err != nil {
+			return nil, fmt.Errorf("failed to parse manifest %d of %s: %w", i, appName, err)
+		}
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
+
+// OrphanedResources returns the resources ArgoCD reports as orphaned in an Application's namespace
+// ArgoCD only reports them when orphaned resource monitoring is enabled on the app's AppProject
+func (m *ManifestClient) OrphanedResources(ctx context.Context, appName, appNamespace string) ([]ResourceOrphan, error) {
+	var tree resourceTree
+	if err := m.getJSON(ctx, appName, appNamespace, "resource-tree", url.Values{}, &tree); err != nil {
+		return nil, err
+	}
+
+	orphans := make([]ResourceOrphan, 0, len(tree.OrphanedNodes))
+	for _, node := range tree.OrphanedNodes {
+		orphans = append(orphans, ResourceOrphan{
+			GVK:       schema.GroupVersionKind{Group: node.Group, Version: node.Version, Kind: node.Kind},
+			Name:      node.Name,
+			Namespace: node.Namespace,
+		})
+	}
+	return orphans, nil
+}
+
+// getJSON decodes an Application's API resource, e.g. "manifests", into out
+func (m *ManifestClient) getJSON(ctx context.Context, appName, appNamespace, resource string, query url.Values, out interface{}) error {
+	if appNamespace != "" {
+		query.Set("appNamespace", appNamespace)
+	}
+	endpoint := fmt.Sprintf("%s/api/v1/applications/%s/%s?%s", m.serverURL, url.PathEscape(appName), resource, query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build %s request: %w", resource, err)
+	}
+	if m.token != "" {
+		req.Header.Set("Authorization", "Bearer "+m.token)
+	}
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s for %s: %w", resource, appName, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errclass.WrapStatus(resp.StatusCode,
+			fmt.Errorf("ArgoCD API returned %s for %s %s: %s", resp.Status, appName, resource, strings.TrimSpace(string(body))))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s for %s: %w", resource, appName, err)
+	}
+	return nil
+}
+
+// addOrphans adds the production app's orphaned resources to a diff, if the API server is available
+// Failures are logged, since orphans only inform the plan
+func (c *Client) addOrphans(ctx context.Context, diff *AppDiff, prodApp *unstructured.Unstructured) {
+	if c.manifests == nil {
+		return
+	}
+	orphans, err := c.manifests.OrphanedResources(ctx, prodApp.GetName(), prodApp.GetNamespace())
+	if err != nil {
+		c.logger.Error(err, "failed to read orphaned resources", "app", prodApp.GetName())
+		return
+	}
+	diff.Orphaned = orphans
+}
+
+// SetManifestClient enables manifest-level diffs through the ArgoCD API server
+// Without it, Applications are compared by their resource lists only
+func (c *Client) SetManifestClient(manifests *ManifestClient) {
+	c.manifests = manifests
+}
+
+// targetRevision returns the revision an Application deploys
+// Multi-source Applications are not supported, since their manifests can't be fetched for a single revision
+func targetRevision(app *unstructured.Unstructured) (string, error) {
+	if _, found, _ := unstructured.NestedSlice(app.Object, "spec", "sources"); found {
+		return "", fmt.Errorf("application %s has multiple sources", app.GetName())
+	}
+	revision, _, _ := unstructured.NestedString(app.Object, "spec", "source", "targetRevision")
+	if revision == "" {
+		revision = "HEAD"
+	}
+	return revision, nil
+}
+
+// manifestDiff compares the rendered manifests of two Applications at their target revisions
+// Fields the production app's spec.ignoreDifferences ignores are left out, as ArgoCD does
+func (c *Client) manifestDiff(ctx context.Context, prApp, prodApp *unstructured.Unstructured) (*AppDiff, error) {
+	prManifests, err := c.appManifests(ctx, prApp)
+	if err != nil {
+		return nil, err
+	}
+	prodManifests, err := c.appManifests(ctx, prodApp)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := ignoreDifferences(prodApp)
+	unsupported := make(map[string]bool)
+	for _, manifests := range []map[string]*unstructured.Unstructured{prManifests, prodManifests} {
+		for key, obj := range manifests {
+			var skipped []string
+			manifests[key], skipped = applyIgnoreDifferences(obj, rules)
+			for _, expr := range skipped {
+				unsupported[expr] = true
+			}
+		}
+	}
+	for expr := range unsupported {
+		c.logger.Info("Ignoring unsupported jqPathExpression of ignoreDifferences", "app", prodApp.GetName(), "expression", expr)
+	}
+
+	return compareManifests(prManifests, prodManifests)
+}
+
+// appManifests fetches an Application's manifests at its target revision, keyed by resource
+func (c *Client) appManifests(ctx context.Context, app *unstructured.Unstructured) (map[string]*unstructured.Unstructured, error) {
+	revision, err := targetRevision(app)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := c.manifests.Manifests(ctx, app.GetName(), app.GetNamespace(), revision)
+	if err != nil {
+		return nil, err
+	}
+
+	manifests := make(map[string]*unstructured.Unstructured, len(objects))
+	for _, obj := range objects {
+		manifests[manifestKey(obj)] = obj
+	}
+	return manifests, nil
+}
+
+// manifestKey identifies a resource independent of its API version
+func manifestKey(obj *unstructured.Unstructured) string {
+	gvk := obj.GroupVersionKind()
+	return fmt.Sprintf("%s/%s/%s/%s", gvk.Group, gvk.Kind, obj.GetNamespace(), obj.GetName())
+}
+
+// compareManifests builds a diff from the PR's and production's rendered manifests
+// Only resources whose content differs are reported as modified
+func compareManifests(prManifests, prodManifests map[string]*unstructured.Unstructured) (*AppDiff, error) {
+	diff := &AppDiff{
+		Additions:     []ResourceChange{},
+		Modifications: []ResourceChange{},
+		Deletions:     []ResourceDeletion{},
+	}
+
+	keys := make([]string, 0, len(prManifests)+len(prodManifests))
+	for key := range prManifests {
+		keys = append(keys, key)
+	}
+	for key := range prodManifests {
+		if _, ok := prManifests[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var raw []string
+	for _, key := range keys {
+		pr, prodObj := prManifests[key], prodManifests[key]
+
+		var current, desired string
+		var err error
+		if prodObj != nil {
+			if current, err = manifestYAML(prodObj); err != nil {
+				return nil, err
+			}
+		}
+		if pr != nil {
+			if desired, err = manifestYAML(pr); err != nil {
+				return nil, err
+			}
+		}
+		if current == desired {
+			continue
+		}
+
+		obj := pr
+		if obj == nil {
+			obj = prodObj
+		}
+		resourceDiff := manifestHeader(obj) + "\n" + lineDiff(current, desired)
+		raw = append(raw, resourceDiff)
+
+		switch {
+		case prodObj == nil:
+			diff.Additions = append(diff.Additions, ResourceChange{
+				GVK: obj.GroupVersionKind(), Name: obj.GetName(), Namespace: obj.GetNamespace(), RawDiff: resourceDiff,
+			})
+		case pr == nil:
+			diff.Deletions = append(diff.Deletions, ResourceDeletion{
+				GVK: obj.GroupVersionKind(), Name: obj.GetName(), Namespace: obj.GetNamespace(), RawDiff: resourceDiff,
+			})
+		default:
+			diff.Modifications = append(diff.Modifications, ResourceChange{
+				GVK: obj.GroupVersionKind(), Name: obj.GetName(), Namespace: obj.GetNamespace(), RawDiff: resourceDiff,
+			})
+		}
+	}
+
+	diff.RawDiff = strings.Join(raw, "\n")
+	return diff, nil
+}
+
+// manifestYAML renders a manifest for diffing, without ArgoCD's per-app tracking metadata
+func manifestYAML(obj *unstructured.Unstructured) (string, error) {
+	clean := obj.DeepCopy()
+	labels, annotations := clean.GetLabels(), clean.GetAnnotations()
+	for _, key := range trackingMetadata {
+		delete(labels, key)
+		delete(annotations, key)
+	}
+	clean.SetLabels(labels)
+	clean.SetAnnotations(annotations)
+
+	out, err := yaml.Marshal(clean.Object)
+	if err != nil {
+		return "", fmt.Errorf("failed to render manifest %s: %w", obj.GetName(), err)
+	}
+	return string(out), nil
+}
+
+// manifestHeader labels a resource's section of the raw diff
+func manifestHeader(obj *unstructured.Unstructured) string {
+	id := obj.GetKind() + "/" + obj.GetName()
+	if obj.GetNamespace() != "" {
+		id += " (" + obj.GetNamespace() + ")"
+	}
+	return "=== " + id + " ==="
+}
+
+// lineDiff renders a compact line diff without colors
+func lineDiff(current, desired string) string {
+	opts := renderer.DefaultDiffOptions()
+	opts.UseColors = false
+	opts.Compact = true
+	return strings.TrimRight(renderer.FormatDiff(renderer.GetLineDiff(current, desired), opts), "\n")
+}
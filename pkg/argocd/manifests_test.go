@@ -0,0 +1,215 @@
+package argocd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func newApp(name string, spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "argoproj.io/v1alpha1",
+			"kind":       "Application",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "argocd",
+			},
+			"spec": spec,
+		},
+	}
+}
+
+// manifestServer serves manifests per app and revision, recording the requested revisions
+func manifestServer(t *testing.T, manifests map[string][]string, revisions map[string]string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		app := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v1/applications/"), "/manifests")
+		list, ok := manifests[app]
+		if !ok {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		revisions[app] = r.URL.Query().Get("revision")
+		json.NewEncoder(w).Encode(manifestResponse{Manifests: list})
+	}))
+}
+
+func TestGetAppDiff_Manifests(t *testing.T) {
+	prApp := newApp("pr-123-myapp", map[string]interface{}{
+		"source": map[string]interface{}{"targetRevision": "feature-branch"},
+	})
+	prodApp := newApp("myapp", map[string]interface{}{
+		"source": map[string]interface{}{"targetRevision": "main"},
+	})
+
+	manifests := map[string][]string{
+		"pr-123-myapp": {
+			`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"settings","namespace":"default","labels":{"argocd.argoproj.io/instance":"pr-123-myapp"}},"data":{"replicas":"3"}}`,
+			`{"apiVersion":"v1","kind":"Service","metadata":{"name":"api","namespace":"default"},"spec":{"port":80}}`,
+			`{"apiVersion":"v1","kind":"Secret","metadata":{"name":"new","namespace":"default"}}`,
+		},
+		"myapp": {
+			`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"settings","namespace":"default","labels":{"argocd.argoproj.io/instance":"myapp"}},"data":{"replicas":"2"}}`,
+			`{"apiVersion":"v1","kind":"Service","metadata":{"name":"api","namespace":"default"},"spec":{"port":80}}`,
+			`{"apiVersion":"batch/v1","kind":"Job","metadata":{"name":"old","namespace":"default"}}`,
+		},
+	}
+	revisions := map[string]string{}
+	server := manifestServer(t, manifests, revisions)
+	defer server.Close()
+
+	client := NewClient(fake.NewSimpleDynamicClient(runtime.NewScheme(), prApp, prodApp), "argocd", "pr-", "", logr.Discard())
+	client.SetManifestClient(NewManifestClient(server.URL+"/", "token", server.Client()))
+
+	diff, err := client.GetAppDiff(context.Background(), "pr-123-myapp", "myapp")
+	if err != nil {
+		t.Fatalf("GetAppDiff() error = %v", err)
+	}
+
+	if revisions["pr-123-myapp"] != "feature-branch" || revisions["myapp"] != "main" {
+		t.Errorf("fetched revisions = %v, want each app's targetRevision", revisions)
+	}
+	if len(diff.Additions) != 1 || diff.Additions[0].Name != "new" {
+		t.Errorf("Additions = %+v, want Secret/new", diff.Additions)
+	}
+	if len(diff.Deletions) != 1 || diff.Deletions[0].Name != "old" {
+		t.Errorf("Deletions = %+v, want Job/old", diff.Deletions)
+	}
+	// The unchanged Service is not reported; the tracking label is ignored
+	if len(diff.Modifications) != 1 || diff.Modifications[0].Name != "settings" {
+		t.Fatalf("Modifications = %+v, want ConfigMap/settings only", diff.Modifications)
+	}
+
+	modified := diff.Modifications[0].RawDiff
+	for _, want := range []string{"=== ConfigMap/settings (default) ===", `-   replicas: "2"`, `+   replicas: "3"`} {
+		if !strings.Contains(modified, want) {
+			t.Errorf("modification diff missing %q:\n%s", want, modified)
+		}
+	}
+	if strings.Contains(modified, "argocd.argoproj.io/instance") {
+		t.Errorf("modification diff includes tracking label:\n%s", modified)
+	}
+	if !strings.Contains(diff.RawDiff, "=== Job/old (default) ===") || !strings.Contains(diff.RawDiff, "=== Secret/new (default) ===") {
+		t.Errorf("RawDiff missing added or deleted resource:\n%s", diff.RawDiff)
+	}
+}
+
+func TestGetAppDiff_ManifestsFallback(t *testing.T) {
+	prApp := newApp("pr-123-myapp", map[string]interface{}{
+		"source": map[string]interface{}{"targetRevision": "feature-branch"},
+	})
+	prApp.Object["status"] = map[string]interface{}{
+		"resources": []interface{}{
+			map[string]interface{}{"version": "v1", "kind": "ConfigMap", "name": "settings", "namespace": "default"},
+		},
+	}
+	prodApp := newApp("myapp", map[string]interface{}{
+		"source": map[string]interface{}{"targetRevision": "main"},
+	})
+
+	// The API server doesn't know either app, so the resource lists are compared
+	server := manifestServer(t, map[string][]string{}, map[string]string{})
+	defer server.Close()
+
+	client := NewClient(fake.NewSimpleDynamicClient(runtime.NewScheme(), prApp, prodApp), "argocd", "pr-", "", logr.Discard())
+	client.SetManifestClient(NewManifestClient(server.URL, "token", server.Client()))
+
+	diff, err := client.GetAppDiff(context.Background(), "pr-123-myapp", "myapp")
+	if err != nil {
+		t.Fatalf("GetAppDiff() error = %v", err)
+	}
+	if len(diff.Additions) != 1 || diff.Additions[0].Name != "settings" {
+		t.Errorf("Additions = %+v, want resource-list fallback", diff.Additions)
+	}
+}
+
+func TestGetAppDiff_IgnoreDifferencesAndOrphans(t *testing.T) {
+	prApp := newApp("pr-123-myapp", map[string]interface{}{})
+	prodApp := newApp("myapp", map[string]interface{}{
+		"ignoreDifferences": []interface{}{
+			map[string]interface{}{"group": "apps", "kind": "Deployment", "jsonPointers": []interface{}{"/spec/replicas"}},
+		},
+	})
+
+	manifests := map[string][]string{
+		"pr-123-myapp": {`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"api","namespace":"default"},"spec":{"replicas":5}}`},
+		"myapp":        {`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"api","namespace":"default"},"spec":{"replicas":2}}`},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/applications/myapp/resource-tree":
+			w.Write([]byte(`{"orphanedNodes":[{"version":"v1","kind":"ConfigMap","namespace":"default","name":"leftover"}]}`))
+		default:
+			app := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v1/applications/"), "/manifests")
+			json.NewEncoder(w).Encode(manifestResponse{Manifests: manifests[app]})
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(fake.NewSimpleDynamicClient(runtime.NewScheme(), prApp, prodApp), "argocd", "pr-", "", logr.Discard())
+	client.SetManifestClient(NewManifestClient(server.URL, "", server.Client()))
+
+	diff, err := client.GetAppDiff(context.Background(), "pr-123-myapp", "myapp")
+	if err != nil {
+		t.Fatalf("GetAppDiff() error = %v", err)
+	}
+	if len(diff.Modifications) != 0 {
+		t.Errorf("Modifications = %+v, want the ignored replicas change left out", diff.Modifications)
+	}
+	want := []ResourceOrphan{{GVK: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, Name: "leftover", Namespace: "default"}}
+	if !reflect.DeepEqual(diff.Orphaned, want) {
+		t.Errorf("Orphaned = %+v, want %+v", diff.Orphaned, want)
+	}
+}
+
+func TestTargetRevision(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    map[string]interface{}
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "explicit revision",
+			spec: map[string]interface{}{"source": map[string]interface{}{"targetRevision": "v1.2.0"}},
+			want: "v1.2.0",
+		},
+		{
+			name: "defaults to HEAD",
+			spec: map[string]interface{}{"source": map[string]interface{}{"repoURL": "https://example.com/repo"}},
+			want: "HEAD",
+		},
+		{
+			name:    "multi-source",
+			spec:    map[string]interface{}{"sources": []interface{}{map[string]interface{}{"targetRevision": "main"}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := targetRevision(newApp("app", tt.spec))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("targetRevision() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("targetRevision() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
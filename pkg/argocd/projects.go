@@ -0,0 +1,72 @@
+package argocd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// defaultProject is the project of Applications that don't set spec.project
+const defaultProject = "default"
+
+// SetProjects restricts the Applications considered to those in the given AppProjects
+// Entries are project names or glob patterns such as "team-*"; empty allows every project
+func (c *Client) SetProjects(projects []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.projects = projects
+}
+
+// projectAllowed checks a project against the allowed projects
+func (c *Client) projectAllowed(project string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if len(c.projects) == 0 {
+		return true
+	}
+	for _, pattern := range c.projects {
+		if matched, _ := path.Match(pattern, project); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// appProject returns the AppProject of an Application
+func appProject(app *unstructured.Unstructured) string {
+	if project, _, _ := unstructured.NestedString(app.Object, "spec", "project"); project != "" {
+		return project
+	}
+	return defaultProject
+}
+
+// ProductionApp returns the production Application paired with a PR Application: the one named
+// after it without the PR prefix or suffix, in the same project
+// Returns an empty name if an Application of that name exists in another project, so apps
+// with identical names in different projects aren't cross-matched
+func (c *Client) ProductionApp(ctx context.Context, prAppName string) (string, error) {
+	prodAppName := c.GetProductionAppName(prAppName)
+	prApp, err := c.getApplication(ctx, prAppName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get PR application %s: %w", prAppName, err)
+	}
+
+	prodApp, err := c.getApplication(ctx, prodAppName)
+	if errors.Is(err, ErrNotFound) {
+		// Not deployed yet, or in a project that isn't allowed
+		return prodAppName, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get production application %s: %w", prodAppName, err)
+	}
+
+	if project := appProject(prApp); appProject(prodApp) != project {
+		c.logger.Info("Production application is in another project, not pairing it",
+			"prApp", prAppName, "prodApp", prodAppName, "project", project, "prodProject", appProject(prodApp))
+		return "", nil
+	}
+	return prodAppName, nil
+}
@@ -0,0 +1,64 @@
+package argocd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestProductionApp(t *testing.T) {
+	client := NewClient(fake.NewSimpleDynamicClient(runtime.NewScheme(),
+		newApp("pr-1-network", map[string]interface{}{"project": "platform"}),
+		newApp("network", map[string]interface{}{"project": "platform"}),
+		newApp("pr-1-billing", map[string]interface{}{"project": "payments"}),
+		newApp("billing", map[string]interface{}{"project": "platform"}),
+		newApp("pr-1-new", map[string]interface{}{"project": "platform"}),
+		newApp("pr-1-legacy", nil),
+		newApp("legacy", map[string]interface{}{"project": "default"}),
+	), "argocd", "pr-", "", logr.Discard())
+
+	tests := []struct {
+		prApp string
+		want  string
+	}{
+		{prApp: "pr-1-network", want: "network"},
+		{prApp: "pr-1-billing", want: ""}, // same name in another project
+		{prApp: "pr-1-new", want: "new"},  // not deployed yet
+		{prApp: "pr-1-legacy", want: "legacy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.prApp, func(t *testing.T) {
+			got, err := client.ProductionApp(context.Background(), tt.prApp)
+			if err != nil || got != tt.want {
+				t.Errorf("ProductionApp() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetProjects(t *testing.T) {
+	ctx := context.Background()
+	client := NewClient(fake.NewSimpleDynamicClient(runtime.NewScheme(),
+		newApp("pr-1-network", map[string]interface{}{"project": "platform-network"}),
+		newApp("pr-1-billing", map[string]interface{}{"project": "payments"}),
+	), "argocd", "pr-", "", logr.Discard())
+	client.SetProjects([]string{"platform-*"})
+
+	apps, err := client.FindPRApplications(ctx, 1)
+	if err != nil || len(apps) != 1 || apps[0] != "pr-1-network" {
+		t.Errorf("FindPRApplications() = %v, %v, want only the allowed project's app", apps, err)
+	}
+	if _, err := client.GetAppDiff(ctx, "pr-1-billing", "billing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetAppDiff() of an app in another project error = %v, want ErrNotFound", err)
+	}
+
+	client.SetProjects(nil)
+	if apps, _ := client.FindPRApplications(ctx, 1); len(apps) != 2 {
+		t.Errorf("FindPRApplications() = %v, want every app without a project allowlist", apps)
+	}
+}
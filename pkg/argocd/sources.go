@@ -0,0 +1,32 @@
+package argocd
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// SourcePaths returns the repository paths an Application deploys (spec.source and spec.sources)
+// Sources without a path, such as Helm charts from a chart repository, are left out
+func (c *Client) SourcePaths(ctx context.Context, appName string) ([]string, error) {
+	app, err := c.getApplication(ctx, appName)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	if path, _, _ := unstructured.NestedString(app.Object, "spec", "source", "path"); path != "" {
+		paths = append(paths, path)
+	}
+	sources, _, _ := unstructured.NestedSlice(app.Object, "spec", "sources")
+	for _, source := range sources {
+		sourceMap, ok := source.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if path, _, _ := unstructured.NestedString(sourceMap, "path"); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
@@ -0,0 +1,46 @@
+package argocd
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestSourcePaths(t *testing.T) {
+	client := NewClient(fake.NewSimpleDynamicClient(runtime.NewScheme(),
+		newApp("pr-1-network", map[string]interface{}{
+			"source": map[string]interface{}{"repoURL": "https://github.com/acme/platform", "path": "clusters/prod/network"},
+		}),
+		newApp("pr-1-data", map[string]interface{}{
+			"sources": []interface{}{
+				map[string]interface{}{"repoURL": "https://charts.example.com", "chart": "postgres"},
+				map[string]interface{}{"repoURL": "https://github.com/acme/platform", "path": "clusters/prod/data"},
+			},
+		}),
+	), "argocd", "pr-", "", logr.Discard())
+
+	tests := []struct {
+		app  string
+		want []string
+	}{
+		{app: "pr-1-network", want: []string{"clusters/prod/network"}},
+		{app: "pr-1-data", want: []string{"clusters/prod/data"}},
+	}
+	for _, tt := range tests {
+		got, err := client.SourcePaths(context.Background(), tt.app)
+		if err != nil {
+			t.Fatalf("SourcePaths(%s) error = %v", tt.app, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SourcePaths(%s) = %v, want %v", tt.app, got, tt.want)
+		}
+	}
+
+	if _, err := client.SourcePaths(context.Background(), "missing"); err == nil {
+		t.Error("SourcePaths() of a missing application should fail")
+	}
+}
@@ -0,0 +1,119 @@
+package argocd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// TrackingMethod is how ArgoCD marks the resources it manages
+// Matches application.resourceTrackingMethod in the argocd-cm ConfigMap
+type TrackingMethod string
+
+const (
+	// TrackingLabel marks resources with the argocd.argoproj.io/instance label (ArgoCD's default before 2.x)
+	TrackingLabel TrackingMethod = "label"
+
+	// TrackingAnnotation marks resources with the argocd.argoproj.io/tracking-id annotation only
+	TrackingAnnotation TrackingMethod = "annotation"
+
+	// TrackingAnnotationAndLabel sets the annotation for tracking and the label for information
+	// The label is truncated to 63 characters, so it may not name the Application
+	TrackingAnnotationAndLabel TrackingMethod = "annotation+label"
+)
+
+// ParseTrackingMethod validates a tracking method name
+func ParseTrackingMethod(s string) (TrackingMethod, error) {
+	switch method := TrackingMethod(s); method {
+	case TrackingLabel, TrackingAnnotation, TrackingAnnotationAndLabel:
+		return method, nil
+	default:
+		return "", fmt.Errorf("unknown ArgoCD tracking method %q (want label, annotation, or annotation+label)", s)
+	}
+}
+
+// SetTrackingMethod sets how ArgoCD tracks resources, which decides how an XR's Application is found
+func (c *Client) SetTrackingMethod(method TrackingMethod) {
+	c.tracking = method
+}
+
+// TrackingMethod returns how ArgoCD tracks resources (label unless set)
+func (c *Client) TrackingMethod() TrackingMethod {
+	if c.tracking == "" {
+		return TrackingLabel
+	}
+	return c.tracking
+}
+
+// FindApplicationsForResource returns the Applications whose status.resources include obj
+// Used when a resource doesn't carry the instance label, e.g. with annotation tracking
+func (c *Client) FindApplicationsForResource(ctx context.Context, obj *unstructured.Unstructured) ([]string, error) {
+	apps, err := c.listApplications(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	gvk := obj.GroupVersionKind()
+	var names []string
+	for _, app := range apps {
+		for _, res := range c.extractResourcesFromApp(app, "search") {
+			if res.Group == gvk.Group && res.Kind == gvk.Kind &&
+				res.Namespace == obj.GetNamespace() && res.Name == obj.GetName() {
+				names = append(names, app.GetName())
+				break
+			}
+		}
+	}
+	return names, nil
+}
+
+// TrackingIDAnnotation is set by ArgoCD on resources it tracks by annotation
+const TrackingIDAnnotation = "argocd.argoproj.io/tracking-id"
+
+// TrackingID is a parsed tracking-id annotation
+// Format: <app>:<group>/<kind>:<namespace>/<name>, where <app> is "<app-namespace>_<app-name>"
+// for Applications outside ArgoCD's own namespace
+type TrackingID struct {
+	AppName      string
+	AppNamespace string
+	Group        string
+	Kind         string
+	Namespace    string
+	Name         string
+}
+
+// ParseTrackingID parses a tracking-id annotation value
+func ParseTrackingID(value string) (*TrackingID, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid tracking id %q: want <app>:<group>/<kind>:<namespace>/<name>", value)
+	}
+
+	groupKind := strings.Split(parts[1], "/")
+	namespaceName := strings.Split(parts[2], "/")
+	if parts[0] == "" || len(groupKind) != 2 || len(namespaceName) != 2 || groupKind[1] == "" || namespaceName[1] == "" {
+		return nil, fmt.Errorf("invalid tracking id %q: want <app>:<group>/<kind>:<namespace>/<name>", value)
+	}
+
+	id := &TrackingID{
+		AppName:   parts[0],
+		Group:     groupKind[0],
+		Kind:      groupKind[1],
+		Namespace: namespaceName[0],
+		Name:      namespaceName[1],
+	}
+	if appNamespace, appName, found := strings.Cut(parts[0], "_"); found {
+		id.AppNamespace, id.AppName = appNamespace, appName
+	}
+	return id, nil
+}
+
+// Matches reports whether the tracking id was written for obj
+// ArgoCD ignores ids copied onto other resources (e.g., by cloning a manifest), and so does this
+func (id *TrackingID) Matches(obj *unstructured.Unstructured) bool {
+	gvk := obj.GroupVersionKind()
+	return id.Group == gvk.Group && id.Kind == gvk.Kind &&
+		id.Namespace == obj.GetNamespace() && id.Name == obj.GetName()
+}
@@ -0,0 +1,142 @@
+package argocd
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestParseTrackingMethod(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    TrackingMethod
+		wantErr bool
+	}{
+		{input: "label", want: TrackingLabel},
+		{input: "annotation", want: TrackingAnnotation},
+		{input: "annotation+label", want: TrackingAnnotationAndLabel},
+		{input: "", wantErr: true},
+		{input: "labels", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseTrackingMethod(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTrackingMethod() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseTrackingMethod() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindApplicationsForResource(t *testing.T) {
+	withResources := func(app *unstructured.Unstructured, resources ...interface{}) *unstructured.Unstructured {
+		app.Object["status"] = map[string]interface{}{"resources": resources}
+		return app
+	}
+	network := func(name string) interface{} {
+		return map[string]interface{}{
+			"group": "example.org", "version": "v1alpha1", "kind": "XNetwork", "name": name,
+		}
+	}
+
+	client := NewClient(fake.NewSimpleDynamicClient(runtime.NewScheme(),
+		withResources(newApp("pr-123-myapp", nil), network("pr-123-vpc")),
+		withResources(newApp("myapp", nil), network("vpc")),
+		withResources(newApp("other", nil), map[string]interface{}{"kind": "ConfigMap", "name": "pr-123-vpc", "namespace": "default"}),
+	), "argocd", "pr-", "", logr.Discard())
+
+	xr := &unstructured.Unstructured{}
+	xr.SetAPIVersion("example.org/v1") // versions may differ from the app's status
+	xr.SetKind("XNetwork")
+	xr.SetName("pr-123-vpc")
+
+	apps, err := client.FindApplicationsForResource(context.Background(), xr)
+	if err != nil {
+		t.Fatalf("FindApplicationsForResource() error = %v", err)
+	}
+	if want := []string{"pr-123-myapp"}; !reflect.DeepEqual(apps, want) {
+		t.Errorf("FindApplicationsForResource() = %v, want %v", apps, want)
+	}
+}
+
+func TestTrackingMethod_Default(t *testing.T) {
+	client := &Client{}
+	if got := client.TrackingMethod(); got != TrackingLabel {
+		t.Errorf("TrackingMethod() = %q, want %q", got, TrackingLabel)
+	}
+	client.SetTrackingMethod(TrackingAnnotation)
+	if got := client.TrackingMethod(); got != TrackingAnnotation {
+		t.Errorf("TrackingMethod() = %q, want %q", got, TrackingAnnotation)
+	}
+}
+
+func TestParseTrackingID(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    *TrackingID
+		wantErr bool
+	}{
+		{
+			name:  "cluster-scoped XR",
+			value: "pr-123-myapp:example.org/XNetwork:/pr-123-vpc",
+			want:  &TrackingID{AppName: "pr-123-myapp", Group: "example.org", Kind: "XNetwork", Name: "pr-123-vpc"},
+		},
+		{
+			name:  "core namespaced resource",
+			value: "myapp:/ConfigMap:default/settings",
+			want:  &TrackingID{AppName: "myapp", Kind: "ConfigMap", Namespace: "default", Name: "settings"},
+		},
+		{
+			name:  "app outside the ArgoCD namespace",
+			value: "team-a_pr-7-db:example.org/XDatabase:team-a/pr-7-db",
+			want:  &TrackingID{AppName: "pr-7-db", AppNamespace: "team-a", Group: "example.org", Kind: "XDatabase", Namespace: "team-a", Name: "pr-7-db"},
+		},
+		{name: "missing app", value: ":example.org/XNetwork:/vpc", wantErr: true},
+		{name: "missing kind", value: "myapp:example.org:/vpc", wantErr: true},
+		{name: "missing name", value: "myapp:example.org/XNetwork:default/", wantErr: true},
+		{name: "label value", value: "myapp", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTrackingID(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTrackingID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTrackingID() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrackingID_Matches(t *testing.T) {
+	xr := &unstructured.Unstructured{}
+	xr.SetAPIVersion("example.org/v1alpha1")
+	xr.SetKind("XNetwork")
+	xr.SetName("pr-123-vpc")
+
+	id, err := ParseTrackingID("pr-123-myapp:example.org/XNetwork:/pr-123-vpc")
+	if err != nil {
+		t.Fatalf("ParseTrackingID() error = %v", err)
+	}
+	if !id.Matches(xr) {
+		t.Error("Matches() = false for the tracked XR")
+	}
+
+	// An id copied along with a cloned manifest doesn't track the copy
+	xr.SetName("pr-123-vpc-copy")
+	if id.Matches(xr) {
+		t.Error("Matches() = true for a resource with a copied id")
+	}
+}
@@ -0,0 +1,157 @@
+package cliflags
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix is the prefix of environment variables bound to flags
+// e.g., --diff-concurrency is read from CROSSPLANE_PLAN_DIFF_CONCURRENCY
+const EnvPrefix = "CROSSPLANE_PLAN"
+
+// redacted replaces the values of sensitive flags in dumps
+const redacted = "<redacted>"
+
+// EnvName returns the environment variable bound to a flag
+func EnvName(prefix, flagName string) string {
+	return prefix + "_" + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// Apply layers the environment and a flags file onto flags not set on the command line
+// Precedence is command line, then environment, then file, then the flag default
+// The file is YAML mapping flag names to values; lists are joined with commas
+func Apply(fs *flag.FlagSet, path, envPrefix string) error {
+	fileValues := map[string]string{}
+	if path != "" {
+		var err error
+		if fileValues, err = readFile(fs, path); err != nil {
+			return err
+		}
+	}
+
+	explicit := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+
+	var errs []string
+	fs.VisitAll(func(f *flag.Flag) {
+		if explicit[f.Name] {
+			return
+		}
+
+		source := "file " + path
+		value, ok := fileValues[f.Name]
+		if env, found := os.LookupEnv(EnvName(envPrefix, f.Name)); found {
+			source, value, ok = EnvName(envPrefix, f.Name), env, true
+		}
+		if !ok {
+			return
+		}
+
+		if err := fs.Set(f.Name, value); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid value for --%s from %s: %v", f.Name, source, err))
+		}
+	})
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// readFile parses a flags file into flag name -> value
+func readFile(fs *flag.FlagSet, path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read flags file: %w", err)
+	}
+
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse flags file %s: %w", path, err)
+	}
+
+	values := make(map[string]string, len(raw))
+	for name, value := range raw {
+		if fs.Lookup(name) == nil {
+			return nil, fmt.Errorf("flags file %s: unknown flag %q", path, name)
+		}
+		values[name] = formatValue(value)
+	}
+	return values, nil
+}
+
+// formatValue converts a YAML value to the string form flag.Value.Set accepts
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, formatValue(item))
+		}
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Dump writes the effective flag values as a flags file
+// Flags in skip are omitted and non-empty values of flags in redact are masked
+func Dump(fs *flag.FlagSet, w io.Writer, skip, redact []string) error {
+	omit := toSet(skip)
+	sensitive := toSet(redact)
+
+	values := map[string]interface{}{}
+	fs.VisitAll(func(f *flag.Flag) {
+		if omit[f.Name] {
+			return
+		}
+		value := dumpValue(f.Value)
+		if sensitive[f.Name] && f.Value.String() != "" {
+			value = redacted
+		}
+		values[f.Name] = value
+	})
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	// Map keys are sorted, so dumps are stable
+	if err := encoder.Encode(values); err != nil {
+		return fmt.Errorf("failed to write flags: %w", err)
+	}
+	return encoder.Close()
+}
+
+// dumpValue returns a flag's value with its natural YAML type
+func dumpValue(value flag.Value) interface{} {
+	getter, ok := value.(flag.Getter)
+	if !ok {
+		return value.String()
+	}
+	switch v := getter.Get().(type) {
+	case bool, int, int64, uint, uint64, float64, string:
+		return v
+	case time.Duration:
+		return v.String()
+	default:
+		return value.String()
+	}
+}
+
+// toSet converts a list of names to a lookup set
+func toSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	return set
+}
@@ -0,0 +1,160 @@
+package cliflags
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newFlagSet returns a flag set with one flag of each common type
+func newFlagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("github-repo", "", "")
+	fs.String("github-token", "", "")
+	fs.String("allowed-target-repos", "", "")
+	fs.Int("diff-concurrency", 1, "")
+	fs.Bool("dry-run", false, "")
+	fs.Duration("shutdown-grace-period", 30*time.Second, "")
+	return fs
+}
+
+// writeFlagsFile writes a flags file and returns its path
+func writeFlagsFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "flags.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write flags file: %v", err)
+	}
+	return path
+}
+
+func TestEnvName(t *testing.T) {
+	if got := EnvName(EnvPrefix, "diff-concurrency"); got != "CROSSPLANE_PLAN_DIFF_CONCURRENCY" {
+		t.Errorf("EnvName() = %s, want CROSSPLANE_PLAN_DIFF_CONCURRENCY", got)
+	}
+}
+
+func TestApply_Precedence(t *testing.T) {
+	path := writeFlagsFile(t, `
+github-repo: millstonehq/from-file
+diff-concurrency: 4
+dry-run: true
+shutdown-grace-period: 1m
+allowed-target-repos:
+  - millstonehq/a
+  - millstonehq/*
+`)
+	t.Setenv("TEST_DIFF_CONCURRENCY", "8")
+
+	fs := newFlagSet()
+	if err := fs.Parse([]string{"--github-repo=millstonehq/from-cli"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Apply(fs, path, "TEST"); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	want := map[string]string{
+		"github-repo":           "millstonehq/from-cli",
+		"diff-concurrency":      "8",
+		"dry-run":               "true",
+		"shutdown-grace-period": "1m0s",
+		"allowed-target-repos":  "millstonehq/a,millstonehq/*",
+		"github-token":          "",
+	}
+	for name, value := range want {
+		if got := fs.Lookup(name).Value.String(); got != value {
+			t.Errorf("--%s = %q, want %q", name, got, value)
+		}
+	}
+}
+
+func TestApply_EnvWithoutFile(t *testing.T) {
+	t.Setenv("TEST_DRY_RUN", "true")
+
+	fs := newFlagSet()
+	if err := fs.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := Apply(fs, "", "TEST"); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if got := fs.Lookup("dry-run").Value.String(); got != "true" {
+		t.Errorf("--dry-run = %s, want true", got)
+	}
+}
+
+func TestApply_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		env     map[string]string
+		wantErr string
+	}{
+		{name: "unknown flag", content: "no-such-flag: 1\n", wantErr: `unknown flag "no-such-flag"`},
+		{name: "invalid YAML", content: "github-repo: [\n", wantErr: "failed to parse flags file"},
+		{name: "invalid file value", content: "diff-concurrency: many\n", wantErr: "invalid value for --diff-concurrency from file"},
+		{name: "invalid env value", content: "", env: map[string]string{"TEST_DRY_RUN": "maybe"}, wantErr: "invalid value for --dry-run from TEST_DRY_RUN"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			fs := newFlagSet()
+			if err := fs.Parse(nil); err != nil {
+				t.Fatal(err)
+			}
+			err := Apply(fs, writeFlagsFile(t, tt.content), "TEST")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Apply() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := Apply(newFlagSet(), "/nonexistent/flags.yaml", "TEST"); err == nil {
+		t.Error("Apply() error = nil, want error for missing file")
+	}
+}
+
+func TestDump_RoundTrip(t *testing.T) {
+	fs := newFlagSet()
+	if err := fs.Parse([]string{"--github-repo=millstonehq/infra", "--github-token=ghp_secret", "--diff-concurrency=3", "--dry-run"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := Dump(fs, &buf, []string{"allowed-target-repos"}, []string{"github-token"}); err != nil {
+		t.Fatalf("Dump() error = %v", err)
+	}
+
+	want := `diff-concurrency: 3
+dry-run: true
+github-repo: millstonehq/infra
+github-token: <redacted>
+shutdown-grace-period: 30s
+`
+	if buf.String() != want {
+		t.Errorf("Dump() =\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	// A dump (minus redacted values) loads back to the same settings
+	loaded := newFlagSet()
+	if err := loaded.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	dump := strings.Replace(buf.String(), "github-token: <redacted>\n", "", 1)
+	if err := Apply(loaded, writeFlagsFile(t, dump), "TEST"); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	for _, name := range []string{"github-repo", "diff-concurrency", "dry-run", "shutdown-grace-period"} {
+		if got, want := loaded.Lookup(name).Value.String(), fs.Lookup(name).Value.String(); got != want {
+			t.Errorf("--%s = %q after round trip, want %q", name, got, want)
+		}
+	}
+}
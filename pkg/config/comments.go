@@ -0,0 +1,142 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// CommentStyle sets the wording and decoration of comments, for organizations with their own conventions
+type CommentStyle struct {
+	// Emoji decorates headings, notices and badges with emoji (default true)
+	Emoji *bool `yaml:"emoji,omitempty"`
+
+	// Title replaces "Crossplane Preview" in comment headings, e.g. "Stack Preview"
+	Title string `yaml:"title,omitempty"`
+
+	// Headings replace headings and collapsible section titles, keyed by their default text without emoji
+	// Example: {"Modified Resources": "Changed Stacks"}
+	Headings map[string]string `yaml:"headings,omitempty"`
+
+	// PostProcessors rewrite each rendered comment in order, for tweaks the style doesn't cover
+	PostProcessors []PostProcessor `yaml:"postProcessors,omitempty"`
+
+	// Template replaces the layout of plan comments with a Go text/template
+	// Available fields: .Repository, .PRNumber, .Resources, .ArgoCD, .Body and .ByAction
+	Template string `yaml:"template,omitempty"`
+
+	// TemplatePath is a file holding the template, instead of Template
+	TemplatePath string `yaml:"templatePath,omitempty"`
+}
+
+// Layout returns the comment layout template, read from TemplatePath if set (empty for the
+// default layout)
+func (s CommentStyle) Layout() (string, error) {
+	if s.TemplatePath == "" {
+		return s.Template, nil
+	}
+	data, err := os.ReadFile(s.TemplatePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read template: %w", err)
+	}
+	return string(data), nil
+}
+
+// PostProcessor rewrites the rendered Markdown of comments; exactly one of Replace, RewriteLinks
+// and Append is set
+type PostProcessor struct {
+	// Replace is a regular expression whose matches anywhere in the comment are replaced by With
+	Replace string `yaml:"replace,omitempty"`
+
+	// RewriteLinks is a regular expression whose matches in link URLs are replaced by With
+	RewriteLinks string `yaml:"rewriteLinks,omitempty"`
+
+	// With replaces the matches of Replace or RewriteLinks; $1 or ${name} expand capture groups
+	With string `yaml:"with,omitempty"`
+
+	// Append is Markdown added at the end of the comment, e.g. legal text
+	Append string `yaml:"append,omitempty"`
+}
+
+// Pattern compiles the regular expression of a Replace or RewriteLinks post-processor
+// Append post-processors have none
+func (p PostProcessor) Pattern() (*regexp.Regexp, error) {
+	expr := p.Replace
+	if p.RewriteLinks != "" {
+		expr = p.RewriteLinks
+	}
+	if expr == "" {
+		return nil, nil
+	}
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", expr, err)
+	}
+	return pattern, nil
+}
+
+// EmojiEnabled reports whether comments are decorated with emoji
+func (s CommentStyle) EmojiEnabled() bool {
+	return s.Emoji == nil || *s.Emoji
+}
+
+// validateCommentStyle checks the title and heading replacements, which must fit on a single line
+func validateCommentStyle(style CommentStyle) error {
+	if strings.ContainsAny(style.Title, "\r\n") {
+		return fmt.Errorf("title must be a single line")
+	}
+
+	headings := make([]string, 0, len(style.Headings))
+	for heading := range style.Headings {
+		headings = append(headings, heading)
+	}
+	sort.Strings(headings)
+	for _, heading := range headings {
+		replacement := style.Headings[heading]
+		if strings.TrimSpace(replacement) == "" {
+			return fmt.Errorf("heading %q: replacement is required", heading)
+		}
+		if strings.ContainsAny(replacement, "\r\n") {
+			return fmt.Errorf("heading %q: replacement must be a single line", heading)
+		}
+	}
+
+	for i, processor := range style.PostProcessors {
+		if err := validatePostProcessor(processor); err != nil {
+			return fmt.Errorf("postProcessors[%d]: %w", i, err)
+		}
+	}
+
+	if style.Template != "" && style.TemplatePath != "" {
+		return fmt.Errorf("template and templatePath are mutually exclusive")
+	}
+	layout, err := style.Layout()
+	if err != nil {
+		return err
+	}
+	if _, err := template.New("layout").Parse(layout); err != nil {
+		return fmt.Errorf("invalid template: %w", err)
+	}
+	return nil
+}
+
+// validatePostProcessor checks that a post-processor does one thing, with a valid pattern
+func validatePostProcessor(p PostProcessor) error {
+	set := 0
+	for _, field := range []string{p.Replace, p.RewriteLinks, p.Append} {
+		if field != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("exactly one of replace, rewriteLinks or append is required")
+	}
+	if p.Append != "" && p.With != "" {
+		return fmt.Errorf("with is only used by replace and rewriteLinks")
+	}
+	_, err := p.Pattern()
+	return err
+}
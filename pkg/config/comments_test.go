@@ -0,0 +1,85 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCommentStyle_EmojiEnabled(t *testing.T) {
+	disabled := false
+	if !(CommentStyle{}).EmojiEnabled() {
+		t.Error("EmojiEnabled() = false by default, want true")
+	}
+	if (CommentStyle{Emoji: &disabled}).EmojiEnabled() {
+		t.Error("EmojiEnabled() = true with emoji: false, want false")
+	}
+}
+
+func TestValidateCommentStyle(t *testing.T) {
+	tests := []struct {
+		name    string
+		style   CommentStyle
+		wantErr string
+	}{
+		{name: "empty", style: CommentStyle{}},
+		{name: "title and headings", style: CommentStyle{Title: "Stack Preview", Headings: map[string]string{"Modified Resources": "Changed Stacks"}}},
+		{name: "multi-line title", style: CommentStyle{Title: "Stack\nPreview"}, wantErr: "title must be a single line"},
+		{name: "empty heading", style: CommentStyle{Headings: map[string]string{"No Changes": " "}}, wantErr: `heading "No Changes": replacement is required`},
+		{name: "multi-line heading", style: CommentStyle{Headings: map[string]string{"No Changes": "No\nChanges"}}, wantErr: "must be a single line"},
+		{name: "post-processors", style: CommentStyle{PostProcessors: []PostProcessor{
+			{Replace: `\bprod\b`, With: "production"},
+			{RewriteLinks: `^https://github\.com/`, With: "https://ghe.example.com/"},
+			{Append: "_Internal use only._"},
+		}}},
+		{name: "empty post-processor", style: CommentStyle{PostProcessors: []PostProcessor{{With: "x"}}}, wantErr: "postProcessors[0]: exactly one of replace, rewriteLinks or append is required"},
+		{name: "two-way post-processor", style: CommentStyle{PostProcessors: []PostProcessor{{Replace: "a", Append: "b"}}}, wantErr: "exactly one of"},
+		{name: "append with replacement", style: CommentStyle{PostProcessors: []PostProcessor{{Append: "b", With: "c"}}}, wantErr: "with is only used by replace and rewriteLinks"},
+		{name: "template", style: CommentStyle{Template: "{{ range .Resources }}{{ .Name }}{{ end }}"}},
+		{name: "invalid template", style: CommentStyle{Template: "{{ range .Resources }}"}, wantErr: "invalid template"},
+		{name: "template and path", style: CommentStyle{Template: "{{ .Body }}", TemplatePath: "comment.tmpl"}, wantErr: "mutually exclusive"},
+		{name: "missing template file", style: CommentStyle{TemplatePath: "testdata/missing.tmpl"}, wantErr: "failed to read template"},
+		{name: "invalid post-processor pattern", style: CommentStyle{PostProcessors: []PostProcessor{{Replace: "prod("}}}, wantErr: `invalid pattern "prod("`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCommentStyle(tt.style)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateCommentStyle() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateCommentStyle() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCommentStyle_Layout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "comment.tmpl")
+	if err := os.WriteFile(path, []byte("{{ .Body }}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		style CommentStyle
+		want  string
+	}{
+		{name: "default layout", style: CommentStyle{}, want: ""},
+		{name: "inline", style: CommentStyle{Template: "Plan for #{{ .PRNumber }}"}, want: "Plan for #{{ .PRNumber }}"},
+		{name: "file", style: CommentStyle{TemplatePath: path}, want: "{{ .Body }}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.style.Layout()
+			if err != nil || got != tt.want {
+				t.Errorf("Layout() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
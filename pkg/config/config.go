@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/millstonehq/crossplane-plan/pkg/errclass"
 	"gopkg.in/yaml.v3"
 )
 
@@ -48,9 +49,26 @@ func LoadConfig(path string) (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
+	return parseConfig(data, "config file "+path)
+}
+
+// ParseConfig parses configuration from YAML, e.g. the configuration stored in a plan bundle
+func ParseConfig(data []byte) (*Config, error) {
+	return parseConfig(data, "config")
+}
+
+// parseConfig parses YAML over the defaults and validates the result
+// source names the configuration in errors
+func parseConfig(data []byte, source string) (*Config, error) {
+	cfg := DefaultConfig()
+
 	// Parse YAML
 	if err := yaml.Unmarshal(data, cfg); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+		return nil, errclass.Wrap(errclass.ErrConfig, fmt.Errorf("failed to parse config file: %w", err))
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, errclass.Wrap(errclass.ErrConfig, fmt.Errorf("invalid %s: %w", source, err))
 	}
 
 	return cfg, nil
@@ -70,3 +88,61 @@ func (c *Config) GetAllStripRules() []StripRule {
 
 	return rules
 }
+
+// Profile returns the profile for a repository (empty if none is configured)
+func (c *Config) Profile(repo string) RepoProfile {
+	return c.Repos[repo]
+}
+
+// Detection returns the PR detection settings of the flags (or PlanConfig)
+func (c *Config) Detection() DetectionConfig {
+	return DetectionConfig{
+		Strategy:      c.DetectionStrategy,
+		NamePattern:   c.NamePattern,
+		LabelKey:      c.LabelKey,
+		AnnotationKey: c.AnnotationKey,
+		CELExpression: c.CELExpression,
+	}
+}
+
+// RepoDetections returns the detection settings of the repositories whose profile maps PR XRs
+// to them, keyed by "owner/repo"; empty fields keep the value of Detection
+func (c *Config) RepoDetections() map[string]DetectionConfig {
+	detections := make(map[string]DetectionConfig)
+	for repo, profile := range c.Repos {
+		if profile.Detection != nil {
+			detections[repo] = profile.Detection.Over(c.Detection())
+		}
+	}
+	return detections
+}
+
+// KindDetections returns the detection rules with empty fields taken from Detection
+func (c *Config) KindDetections() []DetectionRule {
+	rules := make([]DetectionRule, 0, len(c.DetectionRules))
+	for _, rule := range c.DetectionRules {
+		rule.DetectionConfig = rule.DetectionConfig.Over(c.Detection())
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// StripRulesFor returns the active strip rules for a repository
+// Global rules apply to every repository; profile rules are added on top
+func (c *Config) StripRulesFor(repo string) []StripRule {
+	profile := c.Profile(repo)
+
+	stripDefaults := c.Diff.StripDefaults
+	if profile.StripDefaults != nil {
+		stripDefaults = *profile.StripDefaults
+	}
+
+	var rules []StripRule
+	if stripDefaults {
+		rules = append(rules, DefaultStripRules()...)
+	}
+	rules = append(rules, c.Diff.StripRules...)
+	rules = append(rules, profile.StripRules...)
+
+	return rules
+}
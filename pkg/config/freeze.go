@@ -0,0 +1,166 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Freeze actions
+const (
+	// FreezeHold computes plans but holds their comments back until the window ends
+	FreezeHold = "hold"
+
+	// FreezeMark posts comments with a notice that they were posted during the freeze
+	FreezeMark = "mark"
+)
+
+// FreezeWindow is a period (e.g. a release or quiet hours) during which plans are still computed
+// but their PR comments are held back or marked
+// A window is either one-off (Start and End) or recurring (Days, From and To)
+type FreezeWindow struct {
+	// Name identifies the window in logs and comment notices
+	Name string `yaml:"name"`
+
+	// Start and End bound a one-off window (RFC 3339, e.g. "2025-12-20T00:00:00Z")
+	Start string `yaml:"start,omitempty"`
+	End   string `yaml:"end,omitempty"`
+
+	// Days restricts a recurring window to the days it starts on ("Mon" … "Sun"; empty means every day)
+	Days []string `yaml:"days,omitempty"`
+
+	// From and To bound a recurring window ("HH:MM"); a To at or before From ends the next day
+	From string `yaml:"from,omitempty"`
+	To   string `yaml:"to,omitempty"`
+
+	// TimeZone of a recurring window (IANA name, default "UTC")
+	TimeZone string `yaml:"timeZone,omitempty"`
+
+	// Action is "hold" (default) or "mark"
+	Action string `yaml:"action,omitempty"`
+}
+
+// weekdays maps day abbreviations to weekdays
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ActiveFreeze returns the first freeze window containing now, or nil
+// Windows are validated at load, so invalid ones are treated as inactive
+func (c *Config) ActiveFreeze(now time.Time) *FreezeWindow {
+	for i := range c.Freeze {
+		if active, err := c.Freeze[i].Contains(now); err == nil && active {
+			return &c.Freeze[i]
+		}
+	}
+	return nil
+}
+
+// FreezeAction returns the window's action, defaulting to hold
+func (w FreezeWindow) FreezeAction() string {
+	if w.Action == "" {
+		return FreezeHold
+	}
+	return w.Action
+}
+
+// Contains reports whether now falls within the window
+func (w FreezeWindow) Contains(now time.Time) (bool, error) {
+	if w.Start != "" || w.End != "" {
+		start, err := time.Parse(time.RFC3339, w.Start)
+		if err != nil {
+			return false, fmt.Errorf("invalid start: %w", err)
+		}
+		end, err := time.Parse(time.RFC3339, w.End)
+		if err != nil {
+			return false, fmt.Errorf("invalid end: %w", err)
+		}
+		return !now.Before(start) && now.Before(end), nil
+	}
+
+	loc := time.UTC
+	if w.TimeZone != "" {
+		var err error
+		if loc, err = time.LoadLocation(w.TimeZone); err != nil {
+			return false, fmt.Errorf("invalid timeZone: %w", err)
+		}
+	}
+	from, err := parseClock(w.From)
+	if err != nil {
+		return false, fmt.Errorf("invalid from: %w", err)
+	}
+	to, err := parseClock(w.To)
+	if err != nil {
+		return false, fmt.Errorf("invalid to: %w", err)
+	}
+	days := make(map[time.Weekday]bool, len(w.Days))
+	for _, day := range w.Days {
+		weekday, ok := weekdays[strings.ToLower(day)]
+		if !ok {
+			return false, fmt.Errorf("invalid day %q, expected Mon … Sun", day)
+		}
+		days[weekday] = true
+	}
+
+	// Check the windows starting today and yesterday, since a window may run past midnight
+	local := now.In(loc)
+	for _, offset := range []int{0, -1} {
+		day := time.Date(local.Year(), local.Month(), local.Day()+offset, 0, 0, 0, 0, loc)
+		if len(days) > 0 && !days[day.Weekday()] {
+			continue
+		}
+		// time.Date rather than Add, so windows keep their wall-clock times across DST changes
+		start := time.Date(day.Year(), day.Month(), day.Day(), 0, int(from.Minutes()), 0, 0, loc)
+		end := time.Date(day.Year(), day.Month(), day.Day(), 0, int(to.Minutes()), 0, 0, loc)
+		if to <= from {
+			end = end.AddDate(0, 0, 1)
+		}
+		if !local.Before(start) && local.Before(end) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// parseClock parses "HH:MM" into the duration since midnight
+func parseClock(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("expected HH:MM, got %q", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// validateFreezeWindow checks a single freeze window
+func validateFreezeWindow(w FreezeWindow) error {
+	if w.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	switch w.FreezeAction() {
+	case FreezeHold, FreezeMark:
+	default:
+		return fmt.Errorf("unknown action %q, expected hold or mark", w.Action)
+	}
+
+	oneOff := w.Start != "" || w.End != ""
+	recurring := w.From != "" || w.To != "" || len(w.Days) > 0 || w.TimeZone != ""
+	if oneOff && recurring {
+		return fmt.Errorf("start/end and days/from/to are mutually exclusive")
+	}
+	if !oneOff && !recurring {
+		return fmt.Errorf("either start and end, or from and to are required")
+	}
+
+	if _, err := w.Contains(time.Now()); err != nil {
+		return err
+	}
+	if oneOff {
+		start, _ := time.Parse(time.RFC3339, w.Start)
+		end, _ := time.Parse(time.RFC3339, w.End)
+		if !end.After(start) {
+			return fmt.Errorf("end must be after start")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFreezeWindow_Contains(t *testing.T) {
+	tests := []struct {
+		name   string
+		window FreezeWindow
+		now    string
+		want   bool
+	}{
+		{
+			name:   "inside one-off window",
+			window: FreezeWindow{Start: "2025-12-20T00:00:00Z", End: "2026-01-05T00:00:00Z"},
+			now:    "2025-12-24T12:00:00Z",
+			want:   true,
+		},
+		{
+			name:   "end is exclusive",
+			window: FreezeWindow{Start: "2025-12-20T00:00:00Z", End: "2026-01-05T00:00:00Z"},
+			now:    "2026-01-05T00:00:00Z",
+			want:   false,
+		},
+		{
+			name:   "daily quiet hours",
+			window: FreezeWindow{From: "09:00", To: "17:00"},
+			now:    "2025-06-03T12:00:00Z",
+			want:   true,
+		},
+		{
+			name:   "outside daily quiet hours",
+			window: FreezeWindow{From: "09:00", To: "17:00"},
+			now:    "2025-06-03T17:30:00Z",
+			want:   false,
+		},
+		{
+			name:   "overnight window after midnight",
+			window: FreezeWindow{Days: []string{"Fri"}, From: "22:00", To: "06:00"},
+			now:    "2025-06-07T03:00:00Z", // Saturday morning
+			want:   true,
+		},
+		{
+			name:   "overnight window on another day",
+			window: FreezeWindow{Days: []string{"Fri"}, From: "22:00", To: "06:00"},
+			now:    "2025-06-08T03:00:00Z", // Sunday morning
+			want:   false,
+		},
+		{
+			name:   "time zone",
+			window: FreezeWindow{From: "09:00", To: "17:00", TimeZone: "America/New_York"},
+			now:    "2025-06-03T14:00:00Z", // 10:00 in New York
+			want:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now, err := time.Parse(time.RFC3339, tt.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := tt.window.Contains(now)
+			if err != nil {
+				t.Fatalf("Contains() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Contains(%s) = %v, want %v", tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateFreezeWindow(t *testing.T) {
+	tests := []struct {
+		name    string
+		window  FreezeWindow
+		wantErr string
+	}{
+		{
+			name:   "valid recurring window",
+			window: FreezeWindow{Name: "weekend", Days: []string{"Sat", "Sun"}, From: "00:00", To: "00:00", Action: FreezeMark},
+		},
+		{
+			name:    "missing name",
+			window:  FreezeWindow{From: "09:00", To: "17:00"},
+			wantErr: "name is required",
+		},
+		{
+			name:    "unknown action",
+			window:  FreezeWindow{Name: "x", From: "09:00", To: "17:00", Action: "drop"},
+			wantErr: "unknown action",
+		},
+		{
+			name:    "one-off and recurring",
+			window:  FreezeWindow{Name: "x", Start: "2025-12-20T00:00:00Z", End: "2026-01-05T00:00:00Z", From: "09:00"},
+			wantErr: "mutually exclusive",
+		},
+		{
+			name:    "end before start",
+			window:  FreezeWindow{Name: "x", Start: "2026-01-05T00:00:00Z", End: "2025-12-20T00:00:00Z"},
+			wantErr: "end must be after start",
+		},
+		{
+			name:    "invalid day",
+			window:  FreezeWindow{Name: "x", Days: []string{"Funday"}, From: "09:00", To: "17:00"},
+			wantErr: "invalid day",
+		},
+		{
+			name:    "invalid clock",
+			window:  FreezeWindow{Name: "x", From: "9am", To: "17:00"},
+			wantErr: "invalid from",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateFreezeWindow(tt.window)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateFreezeWindow() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateFreezeWindow() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// LinkTemplate is a deep link (e.g. to ArgoCD, Grafana or a Kubernetes dashboard) shown with each resource of a comment
+type LinkTemplate struct {
+	// Name is the link text, e.g. "ArgoCD"
+	Name string `yaml:"name"`
+
+	// URL is a Go template interpolated with LinkData, e.g.
+	// "https://argocd.example.com/applications/argocd/{{.App}}"
+	// A link rendering to an empty URL is left out
+	URL string `yaml:"url"`
+
+	// Kinds restricts the link to XR kinds (empty means every kind)
+	Kinds []string `yaml:"kinds,omitempty"`
+}
+
+// LinkData are the values link URLs are interpolated with
+type LinkData struct {
+	Repository     string // "owner/repo"
+	PRNumber       int
+	APIVersion     string
+	Group          string
+	Kind           string
+	Name           string // Name of the PR XR
+	Namespace      string
+	ProductionName string // Name of the production XR the PR XR is compared against
+	App            string // PR ArgoCD application (empty without ArgoCD)
+	ProductionApp  string // Production ArgoCD application (empty without ArgoCD)
+}
+
+// AppliesTo reports whether the link is shown for an XR kind
+func (t LinkTemplate) AppliesTo(kind string) bool {
+	return len(t.Kinds) == 0 || slices.Contains(t.Kinds, kind)
+}
+
+// Render interpolates the URL template with data
+func (t LinkTemplate) Render(data LinkData) (string, error) {
+	tmpl, err := template.New(t.Name).Option("missingkey=error").Parse(t.URL)
+	if err != nil {
+		return "", fmt.Errorf("invalid url template: %w", err)
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render url: %w", err)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// validateLink checks a single link template
+func validateLink(link LinkTemplate) error {
+	if link.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if link.URL == "" {
+		return fmt.Errorf("url is required")
+	}
+	// Render with sample data, so references to unknown fields fail at load
+	_, err := link.Render(LinkData{Repository: "owner/repo", PRNumber: 1, Kind: "XKind", Name: "name"})
+	return err
+}
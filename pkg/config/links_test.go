@@ -0,0 +1,90 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLinkTemplate_Render(t *testing.T) {
+	data := LinkData{
+		Repository:     "owner/repo",
+		PRNumber:       42,
+		Kind:           "XDatabase",
+		Name:           "pr-42-db",
+		Namespace:      "team a",
+		ProductionName: "db",
+		App:            "pr-42-infra",
+	}
+
+	tests := []struct {
+		name    string
+		url     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "ArgoCD application",
+			url:  "https://argocd.example.com/applications/argocd/{{.App}}",
+			want: "https://argocd.example.com/applications/argocd/pr-42-infra",
+		},
+		{
+			name: "escaped query",
+			url:  "https://grafana.example.com/d/xr?var-name={{.ProductionName}}&var-ns={{.Namespace | urlquery}}",
+			want: "https://grafana.example.com/d/xr?var-name=db&var-ns=team+a",
+		},
+		{
+			name: "conditional on empty value",
+			url:  "{{if .ProductionApp}}https://argocd.example.com/applications/argocd/{{.ProductionApp}}{{end}}",
+			want: "",
+		},
+		{
+			name:    "unknown field",
+			url:     "https://example.com/{{.Cluster}}",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LinkTemplate{Name: "link", URL: tt.url}.Render(data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Render() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinkTemplate_AppliesTo(t *testing.T) {
+	if !(LinkTemplate{}).AppliesTo("XDatabase") {
+		t.Error("link without kinds should apply to every kind")
+	}
+	link := LinkTemplate{Kinds: []string{"XNetwork"}}
+	if link.AppliesTo("XDatabase") || !link.AppliesTo("XNetwork") {
+		t.Error("link with kinds should only apply to them")
+	}
+}
+
+func TestValidate_Links(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Links = []LinkTemplate{
+		{Name: "ArgoCD", URL: "https://argocd.example.com/applications/argocd/{{.App}}"},
+		{Name: "broken", URL: "https://example.com/{{.Name"},
+		{URL: "https://example.com"},
+	}
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Validate() error = nil, want invalid links")
+	}
+	for _, want := range []string{`links[1] (name "broken")`, `links[2] (name ""): name is required`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() error missing %q:\n%v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "links[0]") {
+		t.Errorf("Validate() rejected a valid link:\n%v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// teamPattern matches GitHub users and teams, e.g. "@alice" or "@acme/network"
+var teamPattern = regexp.MustCompile(`^@[A-Za-z0-9][A-Za-z0-9-]*(/[A-Za-z0-9_.-]+)?$`)
+
+// OwnerRule assigns the resources of some kinds or names to a team, which is mentioned in
+// comments of PRs modifying or deleting them
+type OwnerRule struct {
+	// Team is the GitHub team or user to mention, e.g. "@acme/network"
+	Team string `yaml:"team"`
+
+	// Kinds restricts the rule to resource kinds (empty means every kind)
+	Kinds []string `yaml:"kinds,omitempty"`
+
+	// NamePrefixes restricts the rule to resources whose production name starts with a prefix
+	// (empty means every name)
+	NamePrefixes []string `yaml:"namePrefixes,omitempty"`
+}
+
+// Owns reports whether the rule covers a resource
+func (r OwnerRule) Owns(kind, name string) bool {
+	if len(r.Kinds) > 0 && !slices.Contains(r.Kinds, kind) {
+		return false
+	}
+	if len(r.NamePrefixes) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(r.NamePrefixes, func(prefix string) bool {
+		return strings.HasPrefix(name, prefix)
+	})
+}
+
+// validateOwnerRule checks a single owner rule
+func validateOwnerRule(rule OwnerRule) error {
+	if !teamPattern.MatchString(rule.Team) {
+		return fmt.Errorf("team %q must be a GitHub user or team, e.g. @acme/network", rule.Team)
+	}
+	if len(rule.Kinds) == 0 && len(rule.NamePrefixes) == 0 {
+		return fmt.Errorf("kinds or namePrefixes is required")
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOwnerRule_Owns(t *testing.T) {
+	tests := []struct {
+		name string
+		rule OwnerRule
+		kind string
+		xr   string
+		want bool
+	}{
+		{name: "kind", rule: OwnerRule{Kinds: []string{"XNetwork"}}, kind: "XNetwork", xr: "vpc", want: true},
+		{name: "other kind", rule: OwnerRule{Kinds: []string{"XNetwork"}}, kind: "XDatabase", xr: "vpc", want: false},
+		{name: "prefix", rule: OwnerRule{NamePrefixes: []string{"payments-"}}, kind: "XDatabase", xr: "payments-db", want: true},
+		{name: "other prefix", rule: OwnerRule{NamePrefixes: []string{"payments-"}}, kind: "XDatabase", xr: "orders-db", want: false},
+		{name: "kind and prefix", rule: OwnerRule{Kinds: []string{"XDatabase"}, NamePrefixes: []string{"payments-"}}, kind: "XNetwork", xr: "payments-vpc", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Owns(tt.kind, tt.xr); got != tt.want {
+				t.Errorf("Owns(%q, %q) = %v, want %v", tt.kind, tt.xr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateOwnerRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    OwnerRule
+		wantErr string
+	}{
+		{name: "team", rule: OwnerRule{Team: "@acme/network", Kinds: []string{"XNetwork"}}},
+		{name: "user", rule: OwnerRule{Team: "@alice", NamePrefixes: []string{"payments-"}}},
+		{name: "missing @", rule: OwnerRule{Team: "acme/network", Kinds: []string{"XNetwork"}}, wantErr: "must be a GitHub user or team"},
+		{name: "no selector", rule: OwnerRule{Team: "@acme/network"}, wantErr: "kinds or namePrefixes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateOwnerRule(tt.rule)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateOwnerRule() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateOwnerRule() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// PlanConfigGVR identifies the PlanConfig custom resource
+var PlanConfigGVR = schema.GroupVersionResource{
+	Group:    "plan.millstone.tech",
+	Version:  "v1alpha1",
+	Resource: "planconfigs",
+}
+
+// PlanConfigSpec is the spec of a PlanConfig resource
+// It mirrors the config file and adds settings that are otherwise passed as flags
+type PlanConfigSpec struct {
+	// Detection overrides the PR detection flags (empty fields keep the flag value)
+	Detection DetectionConfig `yaml:"detection,omitempty"`
+
+	// DetectionRules give XR API groups and kinds their own detection strategy
+	DetectionRules []DetectionRule `yaml:"detectionRules,omitempty"`
+
+	// Diff replaces the diff section of the config file, except its plugins
+	Diff PlanConfigDiff `yaml:"diff"`
+
+	// Repos holds per-repository profiles keyed by "owner/repo"
+	Repos map[string]RepoProfile `yaml:"repos,omitempty"`
+
+	// Orgs allow XRs to target the repositories of an organization
+	Orgs []OrgSelector `yaml:"orgs,omitempty"`
+
+	// AllowedTargetRepos overrides --allowed-target-repos
+	AllowedTargetRepos []string `yaml:"allowedTargetRepos,omitempty"`
+
+	// Freeze windows hold back or mark PR comments
+	Freeze []FreezeWindow `yaml:"freeze,omitempty"`
+
+	// Links are deep links shown with each resource of a comment
+	Links []LinkTemplate `yaml:"links,omitempty"`
+
+	// Sources map XRs to the manifest files declaring them
+	Sources []SourceMapping `yaml:"sources,omitempty"`
+
+	// Owners assign resources to the teams mentioned when a PR modifies or deletes them
+	Owners []OwnerRule `yaml:"owners,omitempty"`
+
+	// Comments set the emoji and wording of comments
+	Comments CommentStyle `yaml:"comments,omitempty"`
+
+	// ArgoCD restricts which Applications are considered
+	ArgoCD ArgoCDConfig `yaml:"argocd,omitempty"`
+}
+
+// PlanConfigDiff is the diff section of a PlanConfig: that of the config file without plugins
+// Plugins run commands in the controller pod, which holds the GitHub credentials, so only the config
+// file declares them; the PlanConfig's engine rules can still use them
+type PlanConfigDiff struct {
+	StripDefaults    bool             `yaml:"stripDefaults"`
+	StripRules       []StripRule      `yaml:"stripRules,omitempty"`
+	Normalize        []NormalizeRule  `yaml:"normalize,omitempty"`
+	Drift            DriftConfig      `yaml:"drift"`
+	Engines          []EngineRule     `yaml:"engines,omitempty"`
+	DeletionPolicies []DeletionPolicy `yaml:"deletionPolicies,omitempty"`
+}
+
+// planConfigDiff returns the settings of a diff section a PlanConfig may replace
+func planConfigDiff(diff DiffConfig) PlanConfigDiff {
+	return PlanConfigDiff{
+		StripDefaults:    diff.StripDefaults,
+		StripRules:       diff.StripRules,
+		Normalize:        diff.Normalize,
+		Drift:            diff.Drift,
+		Engines:          diff.Engines,
+		DeletionPolicies: diff.DeletionPolicies,
+	}
+}
+
+// Over returns the diff section with the plugins of base
+func (d PlanConfigDiff) Over(base DiffConfig) DiffConfig {
+	return DiffConfig{
+		StripDefaults:    d.StripDefaults,
+		StripRules:       d.StripRules,
+		Normalize:        d.Normalize,
+		Drift:            d.Drift,
+		Engines:          d.Engines,
+		Plugins:          base.Plugins,
+		DeletionPolicies: d.DeletionPolicies,
+	}
+}
+
+// DetectionConfig holds PR detection settings
+type DetectionConfig struct {
+	Strategy      string `yaml:"strategy,omitempty"`
+	NamePattern   string `yaml:"namePattern,omitempty"`
+	LabelKey      string `yaml:"labelKey,omitempty"`
+	AnnotationKey string `yaml:"annotationKey,omitempty"`
+	CELExpression string `yaml:"celExpression,omitempty"`
+}
+
+// FromPlanConfig builds a Config from a PlanConfig resource layered over base
+// base supplies the CLI-only settings (repository, dry-run, detection defaults)
+func FromPlanConfig(obj *unstructured.Unstructured, base *Config) (*Config, error) {
+	rawSpec, found, err := unstructured.NestedMap(obj.Object, "spec")
+	if err != nil {
+		return nil, fmt.Errorf("invalid PlanConfig spec: %w", err)
+	}
+	if !found {
+		rawSpec = map[string]interface{}{}
+	}
+
+	// Round-trip through YAML so the spec decodes with the same tags as the config file
+	data, err := yaml.Marshal(rawSpec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode PlanConfig spec: %w", err)
+	}
+
+	spec := PlanConfigSpec{Diff: planConfigDiff(DefaultConfig().Diff)}
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("failed to parse PlanConfig spec: %w", err)
+	}
+
+	cfg := *base
+	cfg.DetectionRules = spec.DetectionRules
+	cfg.Diff = spec.Diff.Over(base.Diff)
+	cfg.Repos = spec.Repos
+	cfg.Orgs = spec.Orgs
+	cfg.Freeze = spec.Freeze
+	cfg.Links = spec.Links
+	cfg.Sources = spec.Sources
+	cfg.Owners = spec.Owners
+	cfg.Comments = spec.Comments
+	cfg.ArgoCD = spec.ArgoCD
+
+	detection := spec.Detection.Over(base.Detection())
+	cfg.DetectionStrategy = detection.Strategy
+	cfg.NamePattern = detection.NamePattern
+	cfg.LabelKey = detection.LabelKey
+	cfg.AnnotationKey = detection.AnnotationKey
+	cfg.CELExpression = detection.CELExpression
+	if len(spec.AllowedTargetRepos) > 0 {
+		cfg.AllowedTargetRepos = spec.AllowedTargetRepos
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+// Over returns the detection settings with empty fields taken from base
+func (d DetectionConfig) Over(base DetectionConfig) DetectionConfig {
+	if d.Strategy != "" {
+		base.Strategy = d.Strategy
+	}
+	if d.NamePattern != "" {
+		base.NamePattern = d.NamePattern
+	}
+	if d.LabelKey != "" {
+		base.LabelKey = d.LabelKey
+	}
+	if d.AnnotationKey != "" {
+		base.AnnotationKey = d.AnnotationKey
+	}
+	if d.CELExpression != "" {
+		base.CELExpression = d.CELExpression
+	}
+	return base
+}
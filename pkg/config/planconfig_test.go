@@ -0,0 +1,127 @@
+package config
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestFromPlanConfig(t *testing.T) {
+	base := DefaultConfig()
+	base.GitHubRepo = "millstonehq/mill"
+	base.Diff.StripRules = []StripRule{{Path: "from.file", Reason: "Replaced by PlanConfig"}}
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "plan.millstone.tech/v1alpha1",
+		"kind":       "PlanConfig",
+		"metadata":   map[string]interface{}{"name": "default"},
+		"spec": map[string]interface{}{
+			"detection": map[string]interface{}{
+				"strategy": "label",
+				"labelKey": "example.com/pr",
+			},
+			"detectionRules": []interface{}{
+				map[string]interface{}{"apiGroup": "network.example.com", "strategy": "name", "namePattern": "net-pr-{number}-*"},
+			},
+			"diff": map[string]interface{}{
+				"stripDefaults": false,
+				"stripRules": []interface{}{
+					map[string]interface{}{"path": "spec.replicas", "equals": int64(1), "reason": "Preview scaling"},
+				},
+			},
+			"repos": map[string]interface{}{
+				"millstonehq/platform": map[string]interface{}{
+					"protectedKinds": []interface{}{"XDatabase"},
+				},
+			},
+			"allowedTargetRepos": []interface{}{"millstonehq/*"},
+			"argocd": map[string]interface{}{
+				"projects": []interface{}{"platform-*"},
+			},
+		},
+	}}
+
+	cfg, err := FromPlanConfig(obj, base)
+	if err != nil {
+		t.Fatalf("FromPlanConfig() error = %v", err)
+	}
+
+	if cfg.DetectionStrategy != "label" || cfg.LabelKey != "example.com/pr" {
+		t.Errorf("detection = %s/%s, want label/example.com/pr", cfg.DetectionStrategy, cfg.LabelKey)
+	}
+	if cfg.NamePattern != base.NamePattern {
+		t.Errorf("NamePattern = %s, want unchanged %s", cfg.NamePattern, base.NamePattern)
+	}
+	if rules := cfg.KindDetections(); len(rules) != 1 || rules[0].NamePattern != "net-pr-{number}-*" || rules[0].LabelKey != "example.com/pr" {
+		t.Errorf("KindDetections() = %+v, want the network rule over the PlanConfig detection", rules)
+	}
+	if cfg.GitHubRepo != "millstonehq/mill" {
+		t.Errorf("GitHubRepo = %s, want millstonehq/mill", cfg.GitHubRepo)
+	}
+	if cfg.Diff.StripDefaults {
+		t.Error("Diff.StripDefaults = true, want false")
+	}
+	if len(cfg.Diff.StripRules) != 1 || cfg.Diff.StripRules[0].Path != "spec.replicas" {
+		t.Errorf("Diff.StripRules = %+v, want only spec.replicas", cfg.Diff.StripRules)
+	}
+	if !cfg.Diff.Drift.DefaultingAware {
+		t.Error("Diff.Drift.DefaultingAware should keep its default when omitted")
+	}
+	if got := cfg.Profile("millstonehq/platform").ProtectedKinds; len(got) != 1 || got[0] != "XDatabase" {
+		t.Errorf("ProtectedKinds = %v, want [XDatabase]", got)
+	}
+	if len(cfg.AllowedTargetRepos) != 1 || cfg.AllowedTargetRepos[0] != "millstonehq/*" {
+		t.Errorf("AllowedTargetRepos = %v, want [millstonehq/*]", cfg.AllowedTargetRepos)
+	}
+	if len(cfg.ArgoCD.Projects) != 1 || cfg.ArgoCD.Projects[0] != "platform-*" {
+		t.Errorf("ArgoCD.Projects = %v, want [platform-*]", cfg.ArgoCD.Projects)
+	}
+
+	// Base must not be modified
+	if base.DetectionStrategy != "name" || len(base.Diff.StripRules) != 1 {
+		t.Error("FromPlanConfig() modified the base config")
+	}
+}
+
+func TestFromPlanConfig_Plugins(t *testing.T) {
+	base := DefaultConfig()
+	base.Diff.Plugins = []DiffPlugin{{Name: "vcluster", Command: "/plugins/vcluster-diff"}}
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"diff": map[string]interface{}{
+				"engines": []interface{}{
+					map[string]interface{}{"apiGroup": "vcluster.example.com", "engines": []interface{}{"plugin:vcluster"}},
+				},
+				"plugins": []interface{}{
+					map[string]interface{}{"name": "shell", "command": "/bin/sh"},
+				},
+			},
+		},
+	}}
+
+	cfg, err := FromPlanConfig(obj, base)
+	if err != nil {
+		t.Fatalf("FromPlanConfig() error = %v", err)
+	}
+	if len(cfg.Diff.Plugins) != 1 || cfg.Diff.Plugins[0].Name != "vcluster" {
+		t.Errorf("Diff.Plugins = %+v, want only the config file's plugins", cfg.Diff.Plugins)
+	}
+	if len(cfg.Diff.Engines) != 1 {
+		t.Errorf("Diff.Engines = %+v, want the PlanConfig's engine rule", cfg.Diff.Engines)
+	}
+}
+
+func TestFromPlanConfig_InvalidSpec(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"diff": map[string]interface{}{
+				"stripDefaults": "not-a-bool",
+			},
+		},
+	}}
+
+	if _, err := FromPlanConfig(obj, DefaultConfig()); err == nil {
+		t.Error("FromPlanConfig() error = nil, want error for invalid spec")
+	}
+}
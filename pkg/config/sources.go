@@ -0,0 +1,63 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"slices"
+	"text/template"
+)
+
+// SourceMapping maps XRs to the manifest files in the repository that declare them
+type SourceMapping struct {
+	// Kinds restricts the mapping to XR kinds (empty means every kind)
+	Kinds []string `yaml:"kinds,omitempty"`
+
+	// Paths are path.Match globs of the declaring files, interpolated with SourceData, e.g.
+	// "clusters/*/networks/{{.Name}}.yaml"
+	Paths []string `yaml:"paths"`
+}
+
+// SourceData are the values source paths are interpolated with
+type SourceData struct {
+	Kind      string
+	Name      string // Name of the production XR, i.e. without the PR prefix
+	Namespace string
+}
+
+// AppliesTo reports whether the mapping is used for an XR kind
+func (m SourceMapping) AppliesTo(kind string) bool {
+	return len(m.Kinds) == 0 || slices.Contains(m.Kinds, kind)
+}
+
+// Matches reports whether a file is one of the mapping's paths for an XR
+func (m SourceMapping) Matches(file string, data SourceData) (bool, error) {
+	for _, pattern := range m.Paths {
+		tmpl, err := template.New("path").Option("missingkey=error").Parse(pattern)
+		if err != nil {
+			return false, fmt.Errorf("invalid path template %q: %w", pattern, err)
+		}
+		var b bytes.Buffer
+		if err := tmpl.Execute(&b, data); err != nil {
+			return false, fmt.Errorf("failed to render path %q: %w", pattern, err)
+		}
+		matched, err := path.Match(b.String(), file)
+		if err != nil {
+			return false, fmt.Errorf("invalid path glob %q: %w", b.String(), err)
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// validateSourceMapping checks a single source mapping
+func validateSourceMapping(mapping SourceMapping) error {
+	if len(mapping.Paths) == 0 {
+		return fmt.Errorf("at least one path is required")
+	}
+	// Match with sample data, so bad templates and globs fail at load
+	_, err := mapping.Matches("file.yaml", SourceData{Kind: "XKind", Name: "name", Namespace: "default"})
+	return err
+}
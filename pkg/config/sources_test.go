@@ -0,0 +1,61 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSourceMapping_Matches(t *testing.T) {
+	mapping := SourceMapping{Paths: []string{
+		"clusters/*/networks/{{.Name}}.yaml",
+		"teams/{{.Namespace}}/*.yaml",
+	}}
+	data := SourceData{Kind: "XNetwork", Name: "vpc", Namespace: "team-a"}
+
+	tests := []struct {
+		file string
+		want bool
+	}{
+		{file: "clusters/prod/networks/vpc.yaml", want: true},
+		{file: "teams/team-a/network.yaml", want: true},
+		{file: "clusters/prod/networks/edge.yaml", want: false},
+		{file: "clusters/prod/eu/networks/vpc.yaml", want: false},
+	}
+	for _, tt := range tests {
+		got, err := mapping.Matches(tt.file, data)
+		if err != nil {
+			t.Fatalf("Matches(%q) error = %v", tt.file, err)
+		}
+		if got != tt.want {
+			t.Errorf("Matches(%q) = %v, want %v", tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestValidateSourceMapping(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping SourceMapping
+		wantErr string
+	}{
+		{name: "valid", mapping: SourceMapping{Kinds: []string{"XNetwork"}, Paths: []string{"networks/{{.Name}}.yaml"}}},
+		{name: "no paths", mapping: SourceMapping{Kinds: []string{"XNetwork"}}, wantErr: "at least one path"},
+		{name: "unknown field", mapping: SourceMapping{Paths: []string{"{{.Cluster}}/*.yaml"}}, wantErr: "failed to render path"},
+		{name: "bad glob", mapping: SourceMapping{Paths: []string{"networks/[{{.Name}}.yaml"}}, wantErr: "invalid path glob"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSourceMapping(tt.mapping)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateSourceMapping() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateSourceMapping() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
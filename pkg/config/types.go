@@ -16,6 +16,104 @@ type StripRule struct {
 	Reason string `yaml:"reason"`
 }
 
+// NormalizeRule defines how an encoded string field is decoded before diff
+type NormalizeRule struct {
+	// Path is the dot-separated path to a string field (e.g., "spec.forProvider.userData")
+	Path string `yaml:"path"`
+
+	// Decoders are applied in order to the field value
+	// Supported values: "base64", "json", "yaml"
+	// Example: ["base64", "json"] decodes base64 then canonicalizes the JSON document
+	Decoders []string `yaml:"decoders"`
+}
+
+// DriftIgnoreRule excludes provider-populated fields from the declared-vs-actual comparison
+type DriftIgnoreRule struct {
+	// APIGroup matches the managed resource API group (e.g., "rds.aws.upbound.io")
+	// A leading "*." matches any subgroup (e.g., "*.aws.upbound.io")
+	APIGroup string `yaml:"apiGroup"`
+
+	// Kind optionally limits the rule to a single managed resource kind
+	Kind string `yaml:"kind,omitempty"`
+
+	// Fields are spec.forProvider field paths to ignore, dot-separated for nested fields
+	// (e.g., "tagsAll" or "tags.managed-by"); a "*" segment matches any field name
+	Fields []string `yaml:"fields"`
+
+	// Reason explains why these fields are ignored
+	Reason string `yaml:"reason,omitempty"`
+}
+
+// DriftConfig controls the declared-vs-actual infrastructure comparison
+type DriftConfig struct {
+	// DefaultingAware treats fields left empty in spec as accepting the provider default,
+	// and compares declared maps as a subset of the actual state
+	DefaultingAware bool `yaml:"defaultingAware"`
+
+	// IgnoreFields are per-provider lists of fields that are never reported as drift
+	IgnoreFields []DriftIgnoreRule `yaml:"ignoreFields,omitempty"`
+
+	// MaxDepth is how many levels of nested maps are compared field by field (0 for no limit)
+	// Maps deeper than that are compared as a whole; 1 only compares top-level fields
+	MaxDepth int `yaml:"maxDepth,omitempty"`
+}
+
+// EngineRule selects the diff engines used for XRs of a kind
+type EngineRule struct {
+	// APIGroup matches the XR API group (e.g., "platform.example.com")
+	// A leading "*." matches any subgroup (e.g., "*.example.com")
+	APIGroup string `yaml:"apiGroup"`
+
+	// Kind optionally limits the rule to a single XR kind
+	Kind string `yaml:"kind,omitempty"`
+
+	// Engines are tried in order until one succeeds
+	// Supported values: "crossplane-diff", "dry-run", "plugin:<name>"
+	Engines []string `yaml:"engines"`
+}
+
+// DetectionRule gives the XRs of an API group (and optionally a kind) their own PR detection settings
+type DetectionRule struct {
+	// APIGroup matches the XR API group (e.g., "database.example.com")
+	// A leading "*." matches any subgroup (e.g., "*.example.com")
+	APIGroup string `yaml:"apiGroup"`
+
+	// Kind optionally limits the rule to a single XR kind
+	Kind string `yaml:"kind,omitempty"`
+
+	// Detection settings of the rule; empty fields keep the global value
+	DetectionConfig `yaml:",inline"`
+}
+
+// DeletionPolicy sets the severity of deletions of a kind
+type DeletionPolicy struct {
+	// APIGroup matches the deleted resource's API group; empty matches any group
+	// A leading "*." matches any subgroup (e.g., "*.example.com")
+	APIGroup string `yaml:"apiGroup"`
+
+	// Kind optionally limits the policy to a single kind
+	Kind string `yaml:"kind,omitempty"`
+
+	// Severity is "info" (expected deletions), "warn" (the default for deletions) or "block"
+	Severity string `yaml:"severity"`
+}
+
+// DiffPlugin is an external diff engine run as a subprocess
+// The plugin receives the sanitized XR as JSON on stdin and writes a diff result as JSON to stdout
+type DiffPlugin struct {
+	// Name identifies the plugin in engine rules as "plugin:<name>"
+	Name string `yaml:"name"`
+
+	// Command is the plugin executable
+	Command string `yaml:"command"`
+
+	// Args are passed to the command
+	Args []string `yaml:"args,omitempty"`
+
+	// Timeout limits a single plugin run (Go duration, default "30s")
+	Timeout string `yaml:"timeout,omitempty"`
+}
+
 // DiffConfig controls diff behavior
 type DiffConfig struct {
 	// StripDefaults enables the built-in default strip rules
@@ -23,12 +121,81 @@ type DiffConfig struct {
 
 	// StripRules are additional user-defined strip rules
 	StripRules []StripRule `yaml:"stripRules,omitempty"`
+
+	// Normalize decodes encoded string fields so diffs show semantic changes
+	// rather than re-encoding or key ordering noise
+	Normalize []NormalizeRule `yaml:"normalize,omitempty"`
+
+	// Drift controls the infrastructure state analysis of managed resources
+	Drift DriftConfig `yaml:"drift"`
+
+	// Engines select diff engines per XR kind; the first matching rule wins
+	// XRs without a matching rule use crossplane-diff
+	Engines []EngineRule `yaml:"engines,omitempty"`
+
+	// Plugins are external diff engines referenced by engine rules
+	Plugins []DiffPlugin `yaml:"plugins,omitempty"`
+
+	// DeletionPolicies set the severity of deletions per kind; the first matching policy wins
+	// Deletions without a matching policy are warnings
+	DeletionPolicies []DeletionPolicy `yaml:"deletionPolicies,omitempty"`
+}
+
+// RepoProfile overrides policy for a single repository when one instance serves multiple repos
+type RepoProfile struct {
+	// StripDefaults overrides diff.stripDefaults for this repository
+	StripDefaults *bool `yaml:"stripDefaults,omitempty"`
+
+	// StripRules are added to the global strip rules for this repository
+	StripRules []StripRule `yaml:"stripRules,omitempty"`
+
+	// CommentIdentifier overrides the hidden marker used to find the plan comment
+	// Example: "<!-- crossplane-plan-comment:platform -->"
+	CommentIdentifier string `yaml:"commentIdentifier,omitempty"`
+
+	// ProtectedKinds are XR kinds whose changes are flagged in the comment
+	ProtectedKinds []string `yaml:"protectedKinds,omitempty"`
+
+	// Template is a Go text/template wrapping the formatted plan
+	// Available fields: .Body, .Repository, .PRNumber
+	Template string `yaml:"template,omitempty"`
+
+	// GitBaseline compares PR XRs against production as declared in this repository
+	// instead of the live cluster, so diffs are unaffected by live drift
+	GitBaseline *GitBaseline `yaml:"gitBaseline,omitempty"`
+
+	// Detection maps PR XRs to this repository with its own detection settings
+	// (empty fields keep the flag value), so XRs need no target-repo annotation
+	Detection *DetectionConfig `yaml:"detection,omitempty"`
+}
+
+// OrgSelector allows PR XRs to target the repositories of a GitHub organization
+type OrgSelector struct {
+	// Org is the GitHub organization (or user) owning the repositories
+	Org string `yaml:"org"`
+
+	// Topic limits the organization's repositories to those with this topic (default: all)
+	Topic string `yaml:"topic,omitempty"`
+}
+
+// GitBaseline locates the production manifests in a repository
+type GitBaseline struct {
+	// Ref is the branch, tag or commit holding the production manifests (default: the repository's default branch)
+	Ref string `yaml:"ref,omitempty"`
+
+	// Paths are directories or files holding the production manifests (default: the whole repository)
+	// Only plain YAML manifests are read; Helm charts and Kustomize overlays aren't rendered
+	Paths []string `yaml:"paths,omitempty"`
+
+	// ThreeWay also compares the declared production against the live cluster, so pre-existing
+	// drift is shown separately from the changes the PR introduces
+	ThreeWay bool `yaml:"threeWay,omitempty"`
 }
 
 // Config holds the application configuration
 type Config struct {
 	// DetectionStrategy defines how to extract PR numbers from XRs
-	// Supported values: "name", "label", "annotation"
+	// Supported values: "name", "label", "annotation", "cel"
 	DetectionStrategy string `yaml:"-"` // From CLI flag, not config file
 
 	// NamePattern is the pattern used for name-based detection
@@ -39,6 +206,10 @@ type Config struct {
 	// Format: "owner/repo"
 	GitHubRepo string `yaml:"-"` // From CLI flag, not config file
 
+	// AllowedTargetRepos are repositories XRs may target via the target-repo annotation
+	// Entries are "owner/repo" or glob patterns such as "owner/*"
+	AllowedTargetRepos []string `yaml:"-"` // From CLI flag or PlanConfig, not config file
+
 	// DryRun mode calculates diffs but doesn't post to GitHub
 	DryRun bool `yaml:"-"` // From CLI flag, not config file
 
@@ -46,12 +217,52 @@ type Config struct {
 	// Default: "millstone.tech/pr-number"
 	LabelKey string `yaml:"-"` // From CLI flag, not config file
 
+	// CELExpression is the CEL expression for cel-based detection
+	// Evaluated with the XR bound to "object"; returns the PR number or {"pr": N, "baseName": "..."}
+	CELExpression string `yaml:"-"` // From CLI flag, not config file
+
 	// AnnotationKey is the annotation key for annotation-based detection
 	// Default: "millstone.tech/preview-pr"
 	AnnotationKey string `yaml:"-"` // From CLI flag, not config file
 
+	// DetectionRules give XR API groups and kinds their own detection strategy; the first
+	// matching rule applies, other XRs use the global detection settings
+	DetectionRules []DetectionRule `yaml:"detectionRules,omitempty"`
+
 	// Diff controls diff calculation and formatting
 	Diff DiffConfig `yaml:"diff"`
+
+	// Repos holds per-repository profiles keyed by "owner/repo"
+	Repos map[string]RepoProfile `yaml:"repos,omitempty"`
+
+	// Orgs allow XRs to target every (non-archived) repository of an organization
+	Orgs []OrgSelector `yaml:"orgs,omitempty"`
+
+	// Freeze windows hold back or mark PR comments, e.g. during releases
+	Freeze []FreezeWindow `yaml:"freeze,omitempty"`
+
+	// Links are deep links shown with each resource of a comment
+	Links []LinkTemplate `yaml:"links,omitempty"`
+
+	// Sources map XRs to the manifest files declaring them, so each resource of a comment
+	// links to its changed files; XRs without a matching mapping are found by name in the PR's patches
+	Sources []SourceMapping `yaml:"sources,omitempty"`
+
+	// Owners assign resources to teams, which are mentioned in comments of PRs modifying or deleting them
+	Owners []OwnerRule `yaml:"owners,omitempty"`
+
+	// Comments set the emoji and wording of comments
+	Comments CommentStyle `yaml:"comments,omitempty"`
+
+	// ArgoCD restricts which Applications are considered
+	ArgoCD ArgoCDConfig `yaml:"argocd,omitempty"`
+}
+
+// ArgoCDConfig restricts which ArgoCD Applications are considered
+type ArgoCDConfig struct {
+	// Projects are the AppProjects whose Applications are considered, as names or glob
+	// patterns such as "team-*" (empty for all)
+	Projects []string `yaml:"projects,omitempty"`
 }
 
 // DefaultConfig returns a Config with sensible defaults
@@ -64,6 +275,10 @@ func DefaultConfig() *Config {
 		Diff: DiffConfig{
 			StripDefaults: true,
 			StripRules:    []StripRule{},
+			Drift: DriftConfig{
+				DefaultingAware: true,
+				MaxDepth:        5,
+			},
 		},
 	}
 }
@@ -38,6 +38,10 @@ func TestDefaultConfig(t *testing.T) {
 	if len(cfg.Diff.StripRules) != 0 {
 		t.Errorf("Diff.StripRules length = %d, want 0", len(cfg.Diff.StripRules))
 	}
+
+	if !cfg.Diff.Drift.DefaultingAware {
+		t.Error("Diff.Drift.DefaultingAware should be true by default")
+	}
 }
 
 func TestLoadConfig_EmptyPath(t *testing.T) {
@@ -250,3 +254,198 @@ func TestDefaultStripRules(t *testing.T) {
 		}
 	}
 }
+
+func TestLoadConfig_NormalizeRules(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+
+	configYAML := `diff:
+  stripDefaults: true
+  normalize:
+    - path: "spec.forProvider.userData"
+      decoders: ["base64"]
+    - path: "spec.forProvider.policy"
+      decoders: ["base64", "json"]
+`
+
+	if err := os.WriteFile(configPath, []byte(configYAML), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v, want nil", err)
+	}
+
+	if len(cfg.Diff.Normalize) != 2 {
+		t.Fatalf("len(Normalize) = %d, want 2", len(cfg.Diff.Normalize))
+	}
+
+	if cfg.Diff.Normalize[1].Path != "spec.forProvider.policy" {
+		t.Errorf("Normalize[1].Path = %s, want spec.forProvider.policy", cfg.Diff.Normalize[1].Path)
+	}
+	if len(cfg.Diff.Normalize[1].Decoders) != 2 || cfg.Diff.Normalize[1].Decoders[1] != "json" {
+		t.Errorf("Normalize[1].Decoders = %v, want [base64 json]", cfg.Diff.Normalize[1].Decoders)
+	}
+}
+
+func TestLoadConfig_RepoProfiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+
+	configYAML := `diff:
+  stripDefaults: true
+repos:
+  millstonehq/platform:
+    stripDefaults: false
+    stripRules:
+      - path: "spec.parameters.tier"
+        equals: "preview"
+        reason: "Tier is managed by the platform team"
+    commentIdentifier: "<!-- crossplane-plan-comment:platform -->"
+    protectedKinds: ["XDatabase"]
+    template: "{{ .Body }}"
+`
+
+	if err := os.WriteFile(configPath, []byte(configYAML), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v, want nil", err)
+	}
+
+	profile := cfg.Profile("millstonehq/platform")
+	if profile.CommentIdentifier != "<!-- crossplane-plan-comment:platform -->" {
+		t.Errorf("CommentIdentifier = %s, want platform identifier", profile.CommentIdentifier)
+	}
+	if len(profile.ProtectedKinds) != 1 || profile.ProtectedKinds[0] != "XDatabase" {
+		t.Errorf("ProtectedKinds = %v, want [XDatabase]", profile.ProtectedKinds)
+	}
+	if profile.Template != "{{ .Body }}" {
+		t.Errorf("Template = %q, want {{ .Body }}", profile.Template)
+	}
+
+	if got := cfg.Profile("millstonehq/other"); got.CommentIdentifier != "" || got.StripDefaults != nil {
+		t.Errorf("Profile() for unknown repo = %+v, want empty profile", got)
+	}
+}
+
+func TestStripRulesFor(t *testing.T) {
+	disabled := false
+	cfg := &Config{
+		Diff: DiffConfig{
+			StripDefaults: true,
+			StripRules:    []StripRule{{Path: "global.path", Reason: "Global rule"}},
+		},
+		Repos: map[string]RepoProfile{
+			"owner/no-defaults": {
+				StripDefaults: &disabled,
+				StripRules:    []StripRule{{Path: "repo.path", Reason: "Repo rule"}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		repo      string
+		wantPaths []string
+	}{
+		{
+			name:      "repo without profile uses global rules",
+			repo:      "owner/other",
+			wantPaths: append(stripRulePaths(DefaultStripRules()), "global.path"),
+		},
+		{
+			name:      "profile overrides defaults and adds rules",
+			repo:      "owner/no-defaults",
+			wantPaths: []string{"global.path", "repo.path"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := stripRulePaths(cfg.StripRulesFor(tt.repo))
+			if len(got) != len(tt.wantPaths) {
+				t.Fatalf("StripRulesFor() paths = %v, want %v", got, tt.wantPaths)
+			}
+			for i := range got {
+				if got[i] != tt.wantPaths[i] {
+					t.Errorf("StripRulesFor() paths = %v, want %v", got, tt.wantPaths)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestRepoDetections(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Repos = map[string]RepoProfile{
+		"acme/network": {Detection: &DetectionConfig{NamePattern: "net-pr-{number}-*"}},
+		"acme/data":    {Detection: &DetectionConfig{Strategy: "label"}},
+		"acme/other":   {CommentIdentifier: "<!-- other -->"},
+	}
+
+	detections := cfg.RepoDetections()
+	if len(detections) != 2 {
+		t.Fatalf("RepoDetections() = %v, want 2 repositories", detections)
+	}
+	if got := detections["acme/network"]; got.Strategy != "name" || got.NamePattern != "net-pr-{number}-*" {
+		t.Errorf("acme/network detection = %+v, want name strategy with its own pattern", got)
+	}
+	if got := detections["acme/data"]; got.Strategy != "label" || got.LabelKey != "millstone.tech/pr-number" {
+		t.Errorf("acme/data detection = %+v, want label strategy with the default key", got)
+	}
+}
+
+func TestKindDetections(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.DetectionRules = []DetectionRule{
+		{APIGroup: "*.database.example.com", DetectionConfig: DetectionConfig{Strategy: "label"}},
+		{APIGroup: "network.example.com", Kind: "XVPC", DetectionConfig: DetectionConfig{NamePattern: "net-pr-{number}-*"}},
+	}
+
+	rules := cfg.KindDetections()
+	if len(rules) != 2 {
+		t.Fatalf("KindDetections() = %v, want 2 rules", rules)
+	}
+	if got := rules[0]; got.Strategy != "label" || got.LabelKey != "millstone.tech/pr-number" {
+		t.Errorf("database rule = %+v, want label strategy with the default key", got)
+	}
+	if got := rules[1]; got.Kind != "XVPC" || got.Strategy != "name" || got.NamePattern != "net-pr-{number}-*" {
+		t.Errorf("network rule = %+v, want name strategy with its own pattern", got)
+	}
+	if cfg.DetectionRules[0].LabelKey != "" {
+		t.Error("KindDetections() must not modify the configured rules")
+	}
+}
+
+func stripRulePaths(rules []StripRule) []string {
+	paths := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		paths = append(paths, rule.Path)
+	}
+	return paths
+}
+
+func TestLoadConfig_InvalidStripRule(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+
+	configYAML := `diff:
+  stripRules:
+    - path: "metadata.annotations"
+      pattern: "^broken("
+      reason: "Invalid regex"
+`
+
+	if err := os.WriteFile(configPath, []byte(configYAML), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	if _, err := LoadConfig(configPath); err == nil {
+		t.Error("LoadConfig() error = nil, want error for invalid strip rule")
+	}
+}
@@ -0,0 +1,367 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// patternPaths are the only paths where Pattern is honored by the sanitizer
+var patternPaths = map[string]bool{
+	"metadata.annotations": true,
+	"metadata.labels":      true,
+}
+
+// diffEngines are the supported diff engine names
+var diffEngines = map[string]bool{
+	"crossplane-diff": true,
+	"dry-run":         true,
+}
+
+// deletionSeverities are the supported deletion policy severities
+var deletionSeverities = map[string]bool{
+	"info":  true,
+	"warn":  true,
+	"block": true,
+}
+
+// Validate checks all strip rules (global and per-repository) and engine rules and
+// returns an error listing every invalid rule, so misconfigurations fail at load
+// instead of being silently ignored at diff time
+func (c *Config) Validate() error {
+	var problems []string
+
+	for i, rule := range c.Diff.StripRules {
+		if err := validateStripRule(rule); err != nil {
+			problems = append(problems, fmt.Sprintf("diff.stripRules[%d] (path %q): %v", i, rule.Path, err))
+		}
+	}
+
+	// Sort repositories so the error message is stable
+	repos := make([]string, 0, len(c.Repos))
+	for repo := range c.Repos {
+		repos = append(repos, repo)
+	}
+	sort.Strings(repos)
+
+	for _, repo := range repos {
+		for i, rule := range c.Repos[repo].StripRules {
+			if err := validateStripRule(rule); err != nil {
+				problems = append(problems, fmt.Sprintf("repos[%s].stripRules[%d] (path %q): %v", repo, i, rule.Path, err))
+			}
+		}
+		if detection := c.Repos[repo].Detection; detection != nil {
+			if err := validateDetection(detection.Over(c.Detection())); err != nil {
+				problems = append(problems, fmt.Sprintf("repos[%s].detection: %v", repo, err))
+			}
+		}
+	}
+
+	for i, rule := range c.KindDetections() {
+		if rule.APIGroup == "" {
+			problems = append(problems, fmt.Sprintf("detectionRules[%d]: apiGroup is required", i))
+			continue
+		}
+		if err := validateDetection(rule.DetectionConfig); err != nil {
+			problems = append(problems, fmt.Sprintf("detectionRules[%d] (apiGroup %q): %v", i, rule.APIGroup, err))
+		}
+	}
+
+	for i, org := range c.Orgs {
+		if org.Org == "" {
+			problems = append(problems, fmt.Sprintf("orgs[%d]: org is required", i))
+		}
+	}
+
+	plugins := make(map[string]bool)
+	for i, plugin := range c.Diff.Plugins {
+		if err := validatePlugin(plugin, plugins); err != nil {
+			problems = append(problems, fmt.Sprintf("diff.plugins[%d] (name %q): %v", i, plugin.Name, err))
+		}
+		plugins[plugin.Name] = true
+	}
+
+	for i, rule := range c.Diff.Engines {
+		if err := validateEngineRule(rule, plugins); err != nil {
+			problems = append(problems, fmt.Sprintf("diff.engines[%d] (apiGroup %q): %v", i, rule.APIGroup, err))
+		}
+	}
+
+	for i, policy := range c.Diff.DeletionPolicies {
+		if err := validateDeletionPolicy(policy); err != nil {
+			problems = append(problems, fmt.Sprintf("diff.deletionPolicies[%d] (apiGroup %q, kind %q): %v", i, policy.APIGroup, policy.Kind, err))
+		}
+	}
+
+	if c.Diff.Drift.MaxDepth < 0 {
+		problems = append(problems, fmt.Sprintf("diff.drift.maxDepth: must not be negative, got %d", c.Diff.Drift.MaxDepth))
+	}
+	for i, rule := range c.Diff.Drift.IgnoreFields {
+		if err := validateDriftIgnoreRule(rule); err != nil {
+			problems = append(problems, fmt.Sprintf("diff.drift.ignoreFields[%d] (apiGroup %q): %v", i, rule.APIGroup, err))
+		}
+	}
+
+	for i, window := range c.Freeze {
+		if err := validateFreezeWindow(window); err != nil {
+			problems = append(problems, fmt.Sprintf("freeze[%d] (name %q): %v", i, window.Name, err))
+		}
+	}
+
+	for i, link := range c.Links {
+		if err := validateLink(link); err != nil {
+			problems = append(problems, fmt.Sprintf("links[%d] (name %q): %v", i, link.Name, err))
+		}
+	}
+
+	for i, mapping := range c.Sources {
+		if err := validateSourceMapping(mapping); err != nil {
+			problems = append(problems, fmt.Sprintf("sources[%d]: %v", i, err))
+		}
+	}
+
+	for i, rule := range c.Owners {
+		if err := validateOwnerRule(rule); err != nil {
+			problems = append(problems, fmt.Sprintf("owners[%d] (team %q): %v", i, rule.Team, err))
+		}
+	}
+
+	if err := validateCommentStyle(c.Comments); err != nil {
+		problems = append(problems, fmt.Sprintf("comments: %v", err))
+	}
+
+	for i, repo := range c.AllowedTargetRepos {
+		if err := validateRepoPattern(repo); err != nil {
+			problems = append(problems, fmt.Sprintf("allowedTargetRepos[%d]: %v", i, err))
+		}
+	}
+
+	for i, project := range c.ArgoCD.Projects {
+		if _, err := path.Match(project, ""); project == "" || err != nil {
+			problems = append(problems, fmt.Sprintf("argocd.projects[%d]: invalid project pattern %q", i, project))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid rules:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
+
+// Warnings lists rules that are valid but have no effect, such as strip rules with
+// neither equals nor pattern, which older releases accepted silently
+func (c *Config) Warnings() []string {
+	var warnings []string
+
+	for i, rule := range c.Diff.StripRules {
+		if isEmptyStripRule(rule) {
+			warnings = append(warnings, fmt.Sprintf("diff.stripRules[%d] (path %q): neither equals nor pattern is set, the rule never matches", i, rule.Path))
+		}
+	}
+
+	repos := make([]string, 0, len(c.Repos))
+	for repo := range c.Repos {
+		repos = append(repos, repo)
+	}
+	sort.Strings(repos)
+
+	for _, repo := range repos {
+		for i, rule := range c.Repos[repo].StripRules {
+			if isEmptyStripRule(rule) {
+				warnings = append(warnings, fmt.Sprintf("repos[%s].stripRules[%d] (path %q): neither equals nor pattern is set, the rule never matches", repo, i, rule.Path))
+			}
+		}
+	}
+
+	return warnings
+}
+
+// isEmptyStripRule reports whether a strip rule has neither equals nor pattern
+func isEmptyStripRule(rule StripRule) bool {
+	return rule.Pattern == "" && rule.Equals == nil
+}
+
+// ParseRepoPatterns splits a comma-separated list of repositories or repository globs
+// (e.g. --allowed-target-repos), trimming entries and dropping empty ones
+func ParseRepoPatterns(list string) ([]string, error) {
+	var patterns []string
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if err := validateRepoPattern(entry); err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, entry)
+	}
+	return patterns, nil
+}
+
+// validateRepoPattern checks an "owner/repo" repository or glob such as "owner/*"
+func validateRepoPattern(pattern string) error {
+	owner, repo, ok := strings.Cut(pattern, "/")
+	if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") || strings.TrimSpace(pattern) != pattern {
+		return fmt.Errorf("invalid repository pattern %q (must be owner/repo)", pattern)
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid repository pattern %q: %w", pattern, err)
+	}
+	return nil
+}
+
+// validatePlugin checks a single diff plugin; seen holds the names of earlier plugins
+func validatePlugin(plugin DiffPlugin, seen map[string]bool) error {
+	if plugin.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if seen[plugin.Name] {
+		return fmt.Errorf("duplicate plugin name")
+	}
+	if plugin.Command == "" {
+		return fmt.Errorf("command is required")
+	}
+	if plugin.Timeout != "" {
+		if _, err := time.ParseDuration(plugin.Timeout); err != nil {
+			return fmt.Errorf("invalid timeout: %w", err)
+		}
+	}
+	return nil
+}
+
+// validateDriftIgnoreRule checks a single drift ignore rule
+// validateDetection checks a repository's or rule's detection strategy and the setting it requires
+func validateDetection(detection DetectionConfig) error {
+	switch detection.Strategy {
+	case "name":
+		if !strings.Contains(detection.NamePattern, "{number}") {
+			return fmt.Errorf("namePattern %q must contain {number}", detection.NamePattern)
+		}
+	case "label", "annotation":
+	case "cel":
+		if detection.CELExpression == "" {
+			return fmt.Errorf("celExpression is required for strategy cel")
+		}
+	default:
+		return fmt.Errorf("unknown strategy %q (must be name, label, annotation or cel)", detection.Strategy)
+	}
+	return nil
+}
+
+func validateDriftIgnoreRule(rule DriftIgnoreRule) error {
+	if rule.APIGroup == "" {
+		return fmt.Errorf("apiGroup is required")
+	}
+	for _, field := range rule.Fields {
+		if slices.Contains(strings.Split(field, "."), "") {
+			return fmt.Errorf("field %q has an empty segment", field)
+		}
+	}
+	return nil
+}
+
+// validateEngineRule checks a single engine rule against the known engines and plugins
+func validateEngineRule(rule EngineRule, plugins map[string]bool) error {
+	if rule.APIGroup == "" {
+		return fmt.Errorf("apiGroup is required")
+	}
+	if len(rule.Engines) == 0 {
+		return fmt.Errorf("at least one engine is required")
+	}
+	for _, engine := range rule.Engines {
+		if name, ok := strings.CutPrefix(engine, "plugin:"); ok {
+			if !plugins[name] {
+				return fmt.Errorf("engine %q references an undefined plugin", engine)
+			}
+			continue
+		}
+		if !diffEngines[engine] {
+			return fmt.Errorf("unknown engine %q", engine)
+		}
+	}
+	return nil
+}
+
+// validateDeletionPolicy checks a single deletion policy
+func validateDeletionPolicy(policy DeletionPolicy) error {
+	if policy.APIGroup == "" && policy.Kind == "" {
+		return fmt.Errorf("apiGroup or kind is required")
+	}
+	if !deletionSeverities[policy.Severity] {
+		return fmt.Errorf("unknown severity %q (must be info, warn or block)", policy.Severity)
+	}
+	return nil
+}
+
+// validateStripRule checks a single strip rule
+// Rules with neither equals nor pattern are reported by Warnings instead
+func validateStripRule(rule StripRule) error {
+	if err := validatePath(rule.Path); err != nil {
+		return err
+	}
+
+	hasPattern := rule.Pattern != ""
+	hasEquals := rule.Equals != nil
+
+	switch {
+	case hasPattern && hasEquals:
+		return fmt.Errorf("equals and pattern are mutually exclusive")
+	case hasPattern:
+		if !patternPaths[rule.Path] {
+			return fmt.Errorf("pattern is only supported for metadata.annotations and metadata.labels")
+		}
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+	case hasEquals:
+		if err := validateEqualsValue(rule.Equals); err != nil {
+			return fmt.Errorf("invalid equals: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// validatePath checks dot-separated path syntax (e.g., "spec.forProvider.region")
+func validatePath(path string) error {
+	if path == "" {
+		return fmt.Errorf("path is required")
+	}
+	for _, part := range strings.Split(path, ".") {
+		if part == "" {
+			return fmt.Errorf("path has an empty segment")
+		}
+		if strings.ContainsAny(part, " \t[]*") {
+			return fmt.Errorf("path segment %q is not a plain field name (indexes and wildcards are not supported)", part)
+		}
+	}
+	return nil
+}
+
+// validateEqualsValue checks that an equals value can match a field of an unstructured object
+func validateEqualsValue(value interface{}) error {
+	switch v := value.(type) {
+	case string, bool, int, int64, float64:
+		return nil
+	case []interface{}:
+		for i, elem := range v {
+			if err := validateEqualsValue(elem); err != nil {
+				return fmt.Errorf("[%d]: %w", i, err)
+			}
+		}
+		return nil
+	case map[string]interface{}:
+		for key, elem := range v {
+			if err := validateEqualsValue(elem); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported type %T", value)
+	}
+}
@@ -0,0 +1,412 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateStripRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    StripRule
+		wantErr string
+	}{
+		{
+			name: "valid equals rule",
+			rule: StripRule{Path: "spec.managementPolicies", Equals: []interface{}{"Observe"}},
+		},
+		{
+			name: "valid pattern rule",
+			rule: StripRule{Path: "metadata.annotations", Pattern: `^argocd\.argoproj\.io/.*`},
+		},
+		{
+			name: "valid map equals",
+			rule: StripRule{Path: "spec.parameters", Equals: map[string]interface{}{"size": 3, "tier": "dev"}},
+		},
+		{
+			name:    "empty path",
+			rule:    StripRule{Equals: "x"},
+			wantErr: "path is required",
+		},
+		{
+			name:    "empty path segment",
+			rule:    StripRule{Path: "spec..field", Equals: "x"},
+			wantErr: "empty segment",
+		},
+		{
+			name:    "index in path",
+			rule:    StripRule{Path: "spec.items[0]", Equals: "x"},
+			wantErr: "not a plain field name",
+		},
+		{
+			name:    "invalid regex",
+			rule:    StripRule{Path: "metadata.labels", Pattern: "^foo("},
+			wantErr: "invalid pattern",
+		},
+		{
+			name:    "pattern on unsupported path",
+			rule:    StripRule{Path: "spec.region", Pattern: "^us-"},
+			wantErr: "only supported for metadata.annotations and metadata.labels",
+		},
+		{
+			name: "neither equals nor pattern",
+			rule: StripRule{Path: "spec.region"},
+		},
+		{
+			name:    "both equals and pattern",
+			rule:    StripRule{Path: "metadata.labels", Pattern: "^a", Equals: "b"},
+			wantErr: "mutually exclusive",
+		},
+		{
+			name:    "unsupported equals type",
+			rule:    StripRule{Path: "spec.region", Equals: map[interface{}]interface{}{1: "a"}},
+			wantErr: "unsupported type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateStripRule(tt.rule)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateStripRule() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateStripRule() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_ListsAllInvalidRules(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Diff.StripRules = []StripRule{
+		{Path: "metadata.labels", Pattern: "[", Reason: "bad regex"},
+		{Path: "spec.ok", Equals: "fine"},
+		{Path: "spec..bad", Equals: "x"},
+	}
+	cfg.Repos = map[string]RepoProfile{
+		"owner/repo": {StripRules: []StripRule{{Path: "spec..region"}}},
+	}
+	cfg.ArgoCD.Projects = []string{"team-*", "[", ""}
+	cfg.AllowedTargetRepos = []string{"acme/*", " acme/b"}
+	cfg.DetectionRules = []DetectionRule{
+		{DetectionConfig: DetectionConfig{Strategy: "label"}},
+		{APIGroup: "example.com", DetectionConfig: DetectionConfig{Strategy: "regex"}},
+	}
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Validate() error = nil, want error")
+	}
+
+	for _, want := range []string{"diff.stripRules[0]", "diff.stripRules[2]", "repos[owner/repo].stripRules[0]", "argocd.projects[1]", "argocd.projects[2]", "allowedTargetRepos[1]", "detectionRules[0]: apiGroup is required", "detectionRules[1]"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() error missing %q:\n%v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "diff.stripRules[1]") || strings.Contains(err.Error(), "argocd.projects[0]") {
+		t.Errorf("Validate() error should not list valid rules:\n%v", err)
+	}
+}
+
+func TestConfig_Warnings(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Diff.StripRules = []StripRule{
+		{Path: "spec.ok", Equals: "fine"},
+		{Path: "spec.region"},
+	}
+	cfg.Repos = map[string]RepoProfile{
+		"owner/repo": {StripRules: []StripRule{{Path: "spec.tier"}}},
+	}
+
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v, want empty strip rules accepted", err)
+	}
+
+	warnings := cfg.Warnings()
+	if len(warnings) != 2 {
+		t.Fatalf("Warnings() = %v, want 2 warnings", warnings)
+	}
+	for i, want := range []string{"diff.stripRules[1]", "repos[owner/repo].stripRules[0]"} {
+		if !strings.Contains(warnings[i], want) {
+			t.Errorf("Warnings()[%d] = %q, want it to mention %q", i, warnings[i], want)
+		}
+	}
+}
+
+func TestConfig_Validate_Defaults(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Diff.StripRules = DefaultStripRules()
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v for default strip rules", err)
+	}
+}
+
+func TestValidateEngineRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    EngineRule
+		wantErr string
+	}{
+		{
+			name: "fallback chain",
+			rule: EngineRule{APIGroup: "*.example.com", Engines: []string{"crossplane-diff", "dry-run"}},
+		},
+		{
+			name:    "missing apiGroup",
+			rule:    EngineRule{Kind: "XCluster", Engines: []string{"dry-run"}},
+			wantErr: "apiGroup is required",
+		},
+		{
+			name:    "no engines",
+			rule:    EngineRule{APIGroup: "example.com"},
+			wantErr: "at least one engine",
+		},
+		{
+			name:    "unknown engine",
+			rule:    EngineRule{APIGroup: "example.com", Engines: []string{"crossplane-diff", "helm"}},
+			wantErr: `unknown engine "helm"`,
+		},
+		{
+			name: "defined plugin",
+			rule: EngineRule{APIGroup: "example.com", Engines: []string{"plugin:vcluster", "dry-run"}},
+		},
+		{
+			name:    "undefined plugin",
+			rule:    EngineRule{APIGroup: "example.com", Engines: []string{"plugin:missing"}},
+			wantErr: "undefined plugin",
+		},
+	}
+
+	plugins := map[string]bool{"vcluster": true}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateEngineRule(tt.rule, plugins)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateEngineRule() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateEngineRule() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateDriftIgnoreRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    DriftIgnoreRule
+		wantErr string
+	}{
+		{
+			name: "nested paths and wildcards",
+			rule: DriftIgnoreRule{APIGroup: "*.aws.upbound.io", Fields: []string{"tagsAll", "tags.managed-by", "ingress.*.description"}},
+		},
+		{
+			name:    "missing apiGroup",
+			rule:    DriftIgnoreRule{Fields: []string{"tagsAll"}},
+			wantErr: "apiGroup is required",
+		},
+		{
+			name:    "empty segment",
+			rule:    DriftIgnoreRule{APIGroup: "ec2.aws.upbound.io", Fields: []string{"tags..env"}},
+			wantErr: "empty segment",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateDriftIgnoreRule(tt.rule)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateDriftIgnoreRule() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateDriftIgnoreRule() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatePlugin(t *testing.T) {
+	tests := []struct {
+		name    string
+		plugin  DiffPlugin
+		wantErr string
+	}{
+		{
+			name:   "valid plugin",
+			plugin: DiffPlugin{Name: "vcluster", Command: "/plugins/vcluster-diff", Timeout: "1m"},
+		},
+		{
+			name:    "missing name",
+			plugin:  DiffPlugin{Command: "/plugins/diff"},
+			wantErr: "name is required",
+		},
+		{
+			name:    "duplicate name",
+			plugin:  DiffPlugin{Name: "existing", Command: "/plugins/diff"},
+			wantErr: "duplicate plugin name",
+		},
+		{
+			name:    "missing command",
+			plugin:  DiffPlugin{Name: "vcluster"},
+			wantErr: "command is required",
+		},
+		{
+			name:    "invalid timeout",
+			plugin:  DiffPlugin{Name: "vcluster", Command: "/plugins/diff", Timeout: "soon"},
+			wantErr: "invalid timeout",
+		},
+	}
+
+	seen := map[string]bool{"existing": true}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePlugin(tt.plugin, seen)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validatePlugin() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validatePlugin() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseRepoPatterns(t *testing.T) {
+	tests := []struct {
+		name    string
+		list    string
+		want    []string
+		wantErr string
+	}{
+		{name: "empty", list: ""},
+		{name: "repositories and globs", list: "acme/network,acme/*", want: []string{"acme/network", "acme/*"}},
+		{name: "spaces are trimmed", list: "acme/a, acme/b ", want: []string{"acme/a", "acme/b"}},
+		{name: "empty entries are dropped", list: "acme/a,,acme/b,", want: []string{"acme/a", "acme/b"}},
+		{name: "missing owner", list: "acme/a,network", wantErr: `invalid repository pattern "network"`},
+		{name: "empty repository", list: "acme/", wantErr: "must be owner/repo"},
+		{name: "too many segments", list: "acme/net/work", wantErr: "must be owner/repo"},
+		{name: "invalid glob", list: "acme/[", wantErr: "syntax error in pattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRepoPatterns(tt.list)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseRepoPatterns() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRepoPatterns() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseRepoPatterns() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateDetection(t *testing.T) {
+	tests := []struct {
+		name      string
+		detection DetectionConfig
+		wantErr   string
+	}{
+		{
+			name:      "name pattern",
+			detection: DetectionConfig{Strategy: "name", NamePattern: "net-pr-{number}-*"},
+		},
+		{
+			name:      "label",
+			detection: DetectionConfig{Strategy: "label"},
+		},
+		{
+			name:      "name pattern without number",
+			detection: DetectionConfig{Strategy: "name", NamePattern: "net-*"},
+			wantErr:   "must contain {number}",
+		},
+		{
+			name:      "cel without expression",
+			detection: DetectionConfig{Strategy: "cel"},
+			wantErr:   "celExpression is required",
+		},
+		{
+			name:      "unknown strategy",
+			detection: DetectionConfig{Strategy: "branch"},
+			wantErr:   "unknown strategy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateDetection(tt.detection)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateDetection() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateDetection() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateDeletionPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  DeletionPolicy
+		wantErr string
+	}{
+		{
+			name:   "kind in any group",
+			policy: DeletionPolicy{Kind: "XTestBucket", Severity: "info"},
+		},
+		{
+			name:   "group wildcard",
+			policy: DeletionPolicy{APIGroup: "*.example.com", Severity: "block"},
+		},
+		{
+			name:    "neither group nor kind",
+			policy:  DeletionPolicy{Severity: "warn"},
+			wantErr: "apiGroup or kind is required",
+		},
+		{
+			name:    "unknown severity",
+			policy:  DeletionPolicy{Kind: "XDatabase", Severity: "critical"},
+			wantErr: "unknown severity",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateDeletionPolicy(tt.policy)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateDeletionPolicy() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateDeletionPolicy() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -51,5 +51,8 @@ func (d *AnnotationDetector) DetectPR(xr *unstructured.Unstructured) int {
 
 // GetBaseName returns the original name (annotation detector doesn't use name patterns)
 func (d *AnnotationDetector) GetBaseName(xr *unstructured.Unstructured) string {
+	if name, ok := productionNameOverride(xr, d.DetectPR(xr)); ok {
+		return name
+	}
 	return xr.GetName()
 }
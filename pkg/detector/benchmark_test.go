@@ -0,0 +1,30 @@
+package detector
+
+import (
+	"fmt"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// BenchmarkNameDetector_DetectPR covers the full-cluster scans, which detect every XR's PR
+func BenchmarkNameDetector_DetectPR(b *testing.B) {
+	d := NewNameDetector("pr-{number}-*")
+	xrs := make([]*unstructured.Unstructured, 1000)
+	for i := range xrs {
+		xrs[i] = &unstructured.Unstructured{}
+		if i%10 == 0 {
+			xrs[i].SetName(fmt.Sprintf("pr-%d-vpc", i))
+		} else {
+			xrs[i].SetName(fmt.Sprintf("vpc-%d", i))
+		}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, xr := range xrs {
+			d.DetectPR(xr)
+		}
+	}
+}
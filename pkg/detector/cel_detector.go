@@ -0,0 +1,143 @@
+package detector
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/ext"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// CELDetector extracts PR numbers and base names from XRs using a CEL expression
+// The expression is evaluated with the XR bound to the "object" variable and must return either:
+//   - an int (the PR number, 0 or negative if not a PR resource; base name is the XR name), or
+//   - a map with "pr" (int) and optional "baseName" (string) keys
+//
+// Example: "'preview.example.com/pr' in object.metadata.labels ? {'pr': int(object.metadata.labels['preview.example.com/pr']), 'baseName': object.metadata.name.split('--')[1]} : 0"
+type CELDetector struct {
+	expression string
+	program    cel.Program
+}
+
+// celResult is the normalized result of evaluating the CEL expression
+type celResult struct {
+	prNumber int
+	baseName string
+}
+
+// NewCELDetector compiles a CEL expression into a CELDetector
+func NewCELDetector(expression string) (*CELDetector, error) {
+	if expression == "" {
+		return nil, fmt.Errorf("CEL expression is required for cel detection strategy")
+	}
+
+	env, err := cel.NewEnv(
+		cel.Variable("object", cel.DynType),
+		ext.Strings(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+
+	// Type-check to surface errors (undeclared variables, bad syntax) at startup
+	if _, issues := env.Compile(expression); issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("failed to compile CEL expression: %w", issues.Err())
+	}
+
+	// Build the program from the parsed (unchecked) AST: the XR is dynamically typed,
+	// and checked overloads fail on conversions like int(object.metadata.labels['key'])
+	ast, issues := env.Parse(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("failed to parse CEL expression: %w", issues.Err())
+	}
+
+	program, err := env.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL program: %w", err)
+	}
+
+	return &CELDetector{
+		expression: expression,
+		program:    program,
+	}, nil
+}
+
+// DetectPR evaluates the expression and returns the PR number
+func (d *CELDetector) DetectPR(xr *unstructured.Unstructured) int {
+	result, ok := d.evaluate(xr)
+	if !ok {
+		return 0
+	}
+	return result.prNumber
+}
+
+// GetBaseName evaluates the expression and returns the production resource name
+// Returns original name if the expression doesn't yield a base name
+func (d *CELDetector) GetBaseName(xr *unstructured.Unstructured) string {
+	result, ok := d.evaluate(xr)
+	if !ok || result.prNumber == 0 {
+		return xr.GetName()
+	}
+	if name, ok := productionNameOverride(xr, result.prNumber); ok {
+		return name
+	}
+	if result.baseName == "" {
+		return xr.GetName()
+	}
+	return result.baseName
+}
+
+// evaluate runs the CEL program against the XR
+// Evaluation errors (e.g., missing fields) are treated as "not a PR resource"
+func (d *CELDetector) evaluate(xr *unstructured.Unstructured) (*celResult, bool) {
+	out, _, err := d.program.Eval(map[string]interface{}{
+		"object": xr.Object,
+	})
+	if err != nil || types.IsError(out) {
+		return nil, false
+	}
+
+	if prNumber, ok := toPRNumber(out.Value()); ok {
+		return &celResult{prNumber: prNumber}, true
+	}
+
+	native, err := out.ConvertToNative(reflect.TypeOf(map[string]interface{}{}))
+	if err != nil {
+		return nil, false
+	}
+	fields, ok := native.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	prNumber, ok := toPRNumber(fields["pr"])
+	if !ok {
+		return nil, false
+	}
+	result := &celResult{prNumber: prNumber}
+
+	if baseName, ok := fields["baseName"].(string); ok {
+		result.baseName = baseName
+	}
+
+	return result, true
+}
+
+// toPRNumber converts a CEL integer to a PR number
+// Zero, negative and out-of-range values are not PR numbers
+func toPRNumber(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int64:
+		if n > 0 && n <= math.MaxInt32 {
+			return int(n), true
+		}
+	case uint64:
+		if n > 0 && n <= math.MaxInt32 {
+			return int(n), true
+		}
+	}
+	return 0, false
+}
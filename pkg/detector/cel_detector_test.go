@@ -0,0 +1,148 @@
+package detector
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestNewCELDetector_InvalidExpression(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+	}{
+		{name: "empty expression", expression: ""},
+		{name: "syntax error", expression: "object.metadata.name =="},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewCELDetector(tt.expression); err == nil {
+				t.Error("NewCELDetector() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestCELDetector_IntExpression(t *testing.T) {
+	detector, err := NewCELDetector(
+		`has(object.metadata.labels) && 'example.com/pr' in object.metadata.labels ? int(object.metadata.labels['example.com/pr']) : 0`,
+	)
+	if err != nil {
+		t.Fatalf("NewCELDetector() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		labels       map[string]string
+		expectedPR   int
+		expectedBase string
+	}{
+		{
+			name:         "label present",
+			labels:       map[string]string{"example.com/pr": "42"},
+			expectedPR:   42,
+			expectedBase: "my-db",
+		},
+		{
+			name:         "label missing",
+			labels:       map[string]string{"app": "db"},
+			expectedPR:   0,
+			expectedBase: "my-db",
+		},
+		{
+			name:         "no labels",
+			labels:       nil,
+			expectedPR:   0,
+			expectedBase: "my-db",
+		},
+		{
+			name:         "non-numeric label",
+			labels:       map[string]string{"example.com/pr": "abc"},
+			expectedPR:   0,
+			expectedBase: "my-db",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			xr := &unstructured.Unstructured{}
+			xr.SetName("my-db")
+			if tt.labels != nil {
+				xr.SetLabels(tt.labels)
+			}
+
+			if got := detector.DetectPR(xr); got != tt.expectedPR {
+				t.Errorf("DetectPR() = %d, want %d", got, tt.expectedPR)
+			}
+			if got := detector.GetBaseName(xr); got != tt.expectedBase {
+				t.Errorf("GetBaseName() = %s, want %s", got, tt.expectedBase)
+			}
+		})
+	}
+}
+
+func TestCELDetector_OutOfRangePR(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+	}{
+		{name: "negative int", expression: "-1"},
+		{name: "int above int32", expression: "2147483648"},
+		{name: "uint above int32", expression: "18446744073709551615u"},
+		{name: "negative pr in map", expression: "{'pr': -5, 'baseName': 'db'}"},
+		{name: "pr above int32 in map", expression: "{'pr': 4294967296}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detector, err := NewCELDetector(tt.expression)
+			if err != nil {
+				t.Fatalf("NewCELDetector() error = %v", err)
+			}
+
+			xr := &unstructured.Unstructured{}
+			xr.SetName("my-db")
+			if got := detector.DetectPR(xr); got != 0 {
+				t.Errorf("DetectPR() = %d, want 0", got)
+			}
+			if got := detector.GetBaseName(xr); got != "my-db" {
+				t.Errorf("GetBaseName() = %s, want my-db", got)
+			}
+		})
+	}
+}
+
+func TestCELDetector_MapExpression(t *testing.T) {
+	// Convention: "<base>--preview<N>"
+	detector, err := NewCELDetector(
+		`object.metadata.name.matches('^.+--preview[0-9]+$') ? {'pr': int(object.metadata.name.split('--preview')[1]), 'baseName': object.metadata.name.split('--preview')[0]} : {'pr': 0}`,
+	)
+	if err != nil {
+		t.Fatalf("NewCELDetector() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		xrName       string
+		expectedPR   int
+		expectedBase string
+	}{
+		{name: "preview resource", xrName: "billing-db--preview17", expectedPR: 17, expectedBase: "billing-db"},
+		{name: "production resource", xrName: "billing-db", expectedPR: 0, expectedBase: "billing-db"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			xr := &unstructured.Unstructured{}
+			xr.SetName(tt.xrName)
+
+			if got := detector.DetectPR(xr); got != tt.expectedPR {
+				t.Errorf("DetectPR() = %d, want %d", got, tt.expectedPR)
+			}
+			if got := detector.GetBaseName(xr); got != tt.expectedBase {
+				t.Errorf("GetBaseName() = %s, want %s", got, tt.expectedBase)
+			}
+		})
+	}
+}
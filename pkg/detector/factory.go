@@ -0,0 +1,79 @@
+package detector
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Detection strategies supported by New
+const (
+	StrategyName       = "name"
+	StrategyLabel      = "label"
+	StrategyAnnotation = "annotation"
+	StrategyCEL        = "cel"
+)
+
+// defaultNamePattern is the name pattern used when none is given
+const defaultNamePattern = "pr-{number}-*"
+
+// options holds the settings of the detector New creates
+type options struct {
+	namePattern   string
+	labelKey      string
+	annotationKey string
+	celExpression string
+}
+
+// Option configures the detector New creates
+// Settings of other strategies than the one selected are ignored
+type Option func(*options)
+
+// WithNamePattern sets the name pattern of the name strategy (default "pr-{number}-*")
+func WithNamePattern(pattern string) Option {
+	return func(o *options) { o.namePattern = pattern }
+}
+
+// WithLabelKey sets the label holding the PR number for the label strategy
+func WithLabelKey(key string) Option {
+	return func(o *options) { o.labelKey = key }
+}
+
+// WithAnnotationKey sets the annotation holding the PR number for the annotation strategy
+func WithAnnotationKey(key string) Option {
+	return func(o *options) { o.annotationKey = key }
+}
+
+// WithCELExpression sets the expression of the cel strategy (required for it)
+func WithCELExpression(expression string) Option {
+	return func(o *options) { o.celExpression = expression }
+}
+
+// New creates the detector of a strategy
+// Returns an error for unknown strategies and invalid settings instead of panicking
+func New(strategy string, opts ...Option) (Detector, error) {
+	o := options{
+		namePattern:   defaultNamePattern,
+		labelKey:      defaultLabelKey,
+		annotationKey: defaultAnnotationKey,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	switch strategy {
+	case StrategyName:
+		pattern, err := regexp.Compile(namePatternRegexp(o.namePattern))
+		if err != nil {
+			return nil, fmt.Errorf("invalid name pattern %q: %w", o.namePattern, err)
+		}
+		return &NameDetector{pattern: pattern}, nil
+	case StrategyLabel:
+		return NewLabelDetectorWithKey(o.labelKey), nil
+	case StrategyAnnotation:
+		return NewAnnotationDetectorWithKey(o.annotationKey), nil
+	case StrategyCEL:
+		return NewCELDetector(o.celExpression)
+	default:
+		return nil, fmt.Errorf("unknown detection strategy: %s", strategy)
+	}
+}
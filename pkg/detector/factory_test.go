@@ -0,0 +1,150 @@
+package detector
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		opts     []Option
+		xrName   string
+		labels   map[string]string
+		wantPR   int
+		wantErr  bool
+	}{
+		{
+			name:     "default name pattern",
+			strategy: StrategyName,
+			xrName:   "pr-4-bucket",
+			wantPR:   4,
+		},
+		{
+			name:     "custom name pattern",
+			strategy: StrategyName,
+			opts:     []Option{WithNamePattern("preview-{number}-*")},
+			xrName:   "preview-9-bucket",
+			wantPR:   9,
+		},
+		{
+			name:     "invalid name pattern",
+			strategy: StrategyName,
+			opts:     []Option{WithNamePattern("pr-(-{number}")},
+			wantErr:  true,
+		},
+		{
+			name:     "label key",
+			strategy: StrategyLabel,
+			opts:     []Option{WithLabelKey("acme.io/pr"), WithNamePattern("ignored-{number}")},
+			xrName:   "bucket",
+			labels:   map[string]string{"acme.io/pr": "12"},
+			wantPR:   12,
+		},
+		{
+			name:     "cel without expression",
+			strategy: StrategyCEL,
+			wantErr:  true,
+		},
+		{
+			name:     "unknown strategy",
+			strategy: "regex",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := New(tt.strategy, tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			xr := &unstructured.Unstructured{}
+			xr.SetName(tt.xrName)
+			xr.SetLabels(tt.labels)
+			if got := d.DetectPR(xr); got != tt.wantPR {
+				t.Errorf("DetectPR() = %d, want %d", got, tt.wantPR)
+			}
+		})
+	}
+}
+
+func TestProductionNameAnnotation(t *testing.T) {
+	tests := []struct {
+		name         string
+		strategy     string
+		opts         []Option
+		xrName       string
+		labels       map[string]string
+		annotations  map[string]string
+		wantBaseName string
+	}{
+		{
+			name:         "name strategy",
+			strategy:     StrategyName,
+			xrName:       "pr-4-bucket",
+			annotations:  map[string]string{ProductionNameAnnotation: "legacy-bucket-01"},
+			wantBaseName: "legacy-bucket-01",
+		},
+		{
+			name:         "label strategy",
+			strategy:     StrategyLabel,
+			xrName:       "bucket-preview",
+			labels:       map[string]string{defaultLabelKey: "4"},
+			annotations:  map[string]string{ProductionNameAnnotation: "bucket"},
+			wantBaseName: "bucket",
+		},
+		{
+			name:         "annotation strategy",
+			strategy:     StrategyAnnotation,
+			xrName:       "bucket-preview",
+			annotations:  map[string]string{defaultAnnotationKey: "4", ProductionNameAnnotation: "bucket"},
+			wantBaseName: "bucket",
+		},
+		{
+			name:         "cel strategy overrides the expression's base name",
+			strategy:     StrategyCEL,
+			opts:         []Option{WithCELExpression(`{'pr': 4, 'baseName': 'derived'}`)},
+			xrName:       "bucket-preview",
+			annotations:  map[string]string{ProductionNameAnnotation: "bucket"},
+			wantBaseName: "bucket",
+		},
+		{
+			name:         "production XR keeps its name",
+			strategy:     StrategyName,
+			xrName:       "bucket",
+			annotations:  map[string]string{ProductionNameAnnotation: "other"},
+			wantBaseName: "bucket",
+		},
+		{
+			name:         "empty override is ignored",
+			strategy:     StrategyName,
+			xrName:       "pr-4-bucket",
+			annotations:  map[string]string{ProductionNameAnnotation: ""},
+			wantBaseName: "bucket",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := New(tt.strategy, tt.opts...)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			xr := &unstructured.Unstructured{}
+			xr.SetName(tt.xrName)
+			xr.SetLabels(tt.labels)
+			xr.SetAnnotations(tt.annotations)
+			if got := d.GetBaseName(xr); got != tt.wantBaseName {
+				t.Errorf("GetBaseName() = %q, want %q", got, tt.wantBaseName)
+			}
+		})
+	}
+}
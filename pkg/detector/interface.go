@@ -4,6 +4,10 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
+// ProductionNameAnnotation names the production counterpart of a PR XR whose name can't be
+// derived by the detection strategy (e.g. legacy naming); every detector's GetBaseName honors it
+const ProductionNameAnnotation = "millstone.tech/production-name"
+
 // Detector extracts PR numbers from Crossplane XRs
 type Detector interface {
 	// DetectPR returns the PR number if found, or 0 if not found
@@ -13,3 +17,13 @@ type Detector interface {
 	// Returns original name if not a PR resource
 	GetBaseName(xr *unstructured.Unstructured) string
 }
+
+// productionNameOverride returns the production name a PR XR's annotation forces
+// Production XRs (prNumber 0) are never renamed
+func productionNameOverride(xr *unstructured.Unstructured, prNumber int) (string, bool) {
+	if prNumber == 0 {
+		return "", false
+	}
+	name := xr.GetAnnotations()[ProductionNameAnnotation]
+	return name, name != ""
+}
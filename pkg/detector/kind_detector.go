@@ -0,0 +1,66 @@
+package detector
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// KindRule maps the XRs of an API group (and optionally a kind) to a detector
+type KindRule struct {
+	// APIGroup matches the XR API group; a leading "*." matches any subgroup
+	APIGroup string
+
+	// Kind optionally limits the rule to a single XR kind
+	Kind     string
+	Detector Detector
+}
+
+// KindDetector picks the detector of an XR by its API group and kind, so teams can keep
+// their own conventions (e.g. databases use labels, networking uses name patterns)
+// Rules are tried in order; XRs no rule matches use the fallback detector
+type KindDetector struct {
+	rules    []KindRule
+	fallback Detector
+}
+
+// NewKindDetector creates a KindDetector
+func NewKindDetector(rules []KindRule, fallback Detector) *KindDetector {
+	return &KindDetector{
+		rules:    rules,
+		fallback: fallback,
+	}
+}
+
+// DetectPR returns the PR number found by the XR's detector
+func (d *KindDetector) DetectPR(xr *unstructured.Unstructured) int {
+	return d.detectorFor(xr).DetectPR(xr)
+}
+
+// GetBaseName strips the PR prefix using the XR's detector
+func (d *KindDetector) GetBaseName(xr *unstructured.Unstructured) string {
+	return d.detectorFor(xr).GetBaseName(xr)
+}
+
+// detectorFor returns the detector of the first rule matching the XR's kind, or the fallback
+func (d *KindDetector) detectorFor(xr *unstructured.Unstructured) Detector {
+	gvk := xr.GroupVersionKind()
+	for _, rule := range d.rules {
+		if !matchesAPIGroup(rule.APIGroup, gvk.Group) {
+			continue
+		}
+		if rule.Kind != "" && rule.Kind != gvk.Kind {
+			continue
+		}
+		return rule.Detector
+	}
+	return d.fallback
+}
+
+// matchesAPIGroup matches an API group against a pattern ("example.com" or "*.example.com")
+func matchesAPIGroup(pattern, group string) bool {
+	if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+		return group == suffix || strings.HasSuffix(group, "."+suffix)
+	}
+	return pattern == group
+}
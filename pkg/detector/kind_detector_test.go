@@ -0,0 +1,83 @@
+package detector
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestKindDetector(t *testing.T) {
+	d := NewKindDetector([]KindRule{
+		{APIGroup: "*.database.example.com", Detector: NewLabelDetectorWithKey("example.com/pr")},
+		{APIGroup: "network.example.com", Kind: "XVPC", Detector: NewNameDetector("net-pr-{number}-*")},
+	}, NewNameDetector("pr-{number}-*"))
+
+	tests := []struct {
+		name         string
+		apiVersion   string
+		kind         string
+		xrName       string
+		labels       map[string]string
+		wantPR       int
+		wantBaseName string
+	}{
+		{
+			name:         "subgroup uses labels",
+			apiVersion:   "aws.database.example.com/v1alpha1",
+			kind:         "XPostgres",
+			xrName:       "orders",
+			labels:       map[string]string{"example.com/pr": "7"},
+			wantPR:       7,
+			wantBaseName: "orders",
+		},
+		{
+			name:         "group without subgroup uses labels",
+			apiVersion:   "database.example.com/v1alpha1",
+			kind:         "XPostgres",
+			xrName:       "pr-3-orders",
+			wantBaseName: "pr-3-orders",
+		},
+		{
+			name:         "kind uses its name pattern",
+			apiVersion:   "network.example.com/v1alpha1",
+			kind:         "XVPC",
+			xrName:       "net-pr-12-vpc",
+			wantPR:       12,
+			wantBaseName: "vpc",
+		},
+		{
+			name:         "other kind of the group falls back",
+			apiVersion:   "network.example.com/v1alpha1",
+			kind:         "XSubnet",
+			xrName:       "pr-4-subnet",
+			wantPR:       4,
+			wantBaseName: "subnet",
+		},
+		{
+			name:         "unmatched group falls back",
+			apiVersion:   "storage.example.com/v1alpha1",
+			kind:         "XBucket",
+			xrName:       "pr-5-bucket",
+			labels:       map[string]string{"example.com/pr": "7"},
+			wantPR:       5,
+			wantBaseName: "bucket",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			xr := &unstructured.Unstructured{}
+			xr.SetAPIVersion(tt.apiVersion)
+			xr.SetKind(tt.kind)
+			xr.SetName(tt.xrName)
+			xr.SetLabels(tt.labels)
+
+			if got := d.DetectPR(xr); got != tt.wantPR {
+				t.Errorf("DetectPR() = %d, want %d", got, tt.wantPR)
+			}
+			if got := d.GetBaseName(xr); got != tt.wantBaseName {
+				t.Errorf("GetBaseName() = %q, want %q", got, tt.wantBaseName)
+			}
+		})
+	}
+}
@@ -51,5 +51,8 @@ func (d *LabelDetector) DetectPR(xr *unstructured.Unstructured) int {
 
 // GetBaseName returns the original name (label detector doesn't use name patterns)
 func (d *LabelDetector) GetBaseName(xr *unstructured.Unstructured) string {
+	if name, ok := productionNameOverride(xr, d.DetectPR(xr)); ok {
+		return name
+	}
 	return xr.GetName()
 }
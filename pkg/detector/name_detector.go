@@ -14,18 +14,21 @@ type NameDetector struct {
 
 // NewNameDetector creates a NameDetector from a pattern string
 // Pattern format: "pr-{number}-*" where {number} is replaced with (\d+)
+// Panics if the pattern isn't a valid regular expression; New returns an error instead
 func NewNameDetector(pattern string) *NameDetector {
-	// Convert pattern to regex
-	// "pr-{number}-*" becomes "^pr-(\d+)-(.*)$"
-	regexPattern := regexp.MustCompile(`\{number\}`).ReplaceAllString(pattern, `(\d+)`)
-	regexPattern = regexp.MustCompile(`\*`).ReplaceAllString(regexPattern, `(.*)`)
-	regexPattern = "^" + regexPattern + "$"
-
 	return &NameDetector{
-		pattern: regexp.MustCompile(regexPattern),
+		pattern: regexp.MustCompile(namePatternRegexp(pattern)),
 	}
 }
 
+// namePatternRegexp converts a name pattern to a regular expression
+// "pr-{number}-*" becomes "^pr-(\d+)-(.*)$"
+func namePatternRegexp(pattern string) string {
+	regexPattern := regexp.MustCompile(`\{number\}`).ReplaceAllString(pattern, `(\d+)`)
+	regexPattern = regexp.MustCompile(`\*`).ReplaceAllString(regexPattern, `(.*)`)
+	return "^" + regexPattern + "$"
+}
+
 // DetectPR extracts the PR number from the XR name
 func (d *NameDetector) DetectPR(xr *unstructured.Unstructured) int {
 	name := xr.GetName()
@@ -53,6 +56,9 @@ func (d *NameDetector) GetBaseName(xr *unstructured.Unstructured) string {
 		// Not a PR XR, return original name
 		return name
 	}
+	if override, ok := productionNameOverride(xr, d.DetectPR(xr)); ok {
+		return override
+	}
 
 	// Pattern format: "pr-{number}-*" becomes "^pr-(\d+)-(.*)$"
 	// matches[0] = full match (pr-2-mill)
@@ -0,0 +1,62 @@
+package detector
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Route maps the XRs a detector recognizes to a repository
+type Route struct {
+	// Repository is the "owner/repo" the route's PR XRs belong to
+	Repository string
+	Detector   Detector
+}
+
+// RoutingDetector serves several repositories, each with its own detection settings
+// Routes are tried in order; XRs no route recognizes fall back to the default detector
+type RoutingDetector struct {
+	routes   []Route
+	fallback Detector
+}
+
+// NewRoutingDetector creates a RoutingDetector
+func NewRoutingDetector(routes []Route, fallback Detector) *RoutingDetector {
+	return &RoutingDetector{
+		routes:   routes,
+		fallback: fallback,
+	}
+}
+
+// DetectPR returns the PR number of the first route recognizing the XR, or of the fallback
+func (d *RoutingDetector) DetectPR(xr *unstructured.Unstructured) int {
+	if route, ok := d.match(xr); ok {
+		return route.Detector.DetectPR(xr)
+	}
+	return d.fallback.DetectPR(xr)
+}
+
+// GetBaseName strips the PR prefix using the detector that recognized the XR
+func (d *RoutingDetector) GetBaseName(xr *unstructured.Unstructured) string {
+	if route, ok := d.match(xr); ok {
+		return route.Detector.GetBaseName(xr)
+	}
+	return d.fallback.GetBaseName(xr)
+}
+
+// Repository returns the repository of the route recognizing the XR
+// Returns "" for XRs only the fallback recognizes, which belong to the default repository
+func (d *RoutingDetector) Repository(xr *unstructured.Unstructured) string {
+	if route, ok := d.match(xr); ok {
+		return route.Repository
+	}
+	return ""
+}
+
+// match returns the first route whose detector finds a PR number in the XR
+func (d *RoutingDetector) match(xr *unstructured.Unstructured) (Route, bool) {
+	for _, route := range d.routes {
+		if route.Detector.DetectPR(xr) > 0 {
+			return route, true
+		}
+	}
+	return Route{}, false
+}
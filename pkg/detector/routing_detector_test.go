@@ -0,0 +1,68 @@
+package detector
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestRoutingDetector(t *testing.T) {
+	d := NewRoutingDetector([]Route{
+		{Repository: "acme/network", Detector: NewNameDetector("net-pr-{number}-*")},
+		{Repository: "acme/data", Detector: NewLabelDetectorWithKey("acme.io/data-pr")},
+	}, NewNameDetector("pr-{number}-*"))
+
+	tests := []struct {
+		name           string
+		xrName         string
+		labels         map[string]string
+		wantPR         int
+		wantBaseName   string
+		wantRepository string
+	}{
+		{
+			name:           "name route",
+			xrName:         "net-pr-12-vpc",
+			wantPR:         12,
+			wantBaseName:   "vpc",
+			wantRepository: "acme/network",
+		},
+		{
+			name:           "label route",
+			xrName:         "warehouse",
+			labels:         map[string]string{"acme.io/data-pr": "7"},
+			wantPR:         7,
+			wantBaseName:   "warehouse",
+			wantRepository: "acme/data",
+		},
+		{
+			name:         "fallback",
+			xrName:       "pr-3-bucket",
+			wantPR:       3,
+			wantBaseName: "bucket",
+		},
+		{
+			name:         "production XR",
+			xrName:       "bucket",
+			wantBaseName: "bucket",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			xr := &unstructured.Unstructured{}
+			xr.SetName(tt.xrName)
+			xr.SetLabels(tt.labels)
+
+			if got := d.DetectPR(xr); got != tt.wantPR {
+				t.Errorf("DetectPR() = %d, want %d", got, tt.wantPR)
+			}
+			if got := d.GetBaseName(xr); got != tt.wantBaseName {
+				t.Errorf("GetBaseName() = %q, want %q", got, tt.wantBaseName)
+			}
+			if got := d.Repository(xr); got != tt.wantRepository {
+				t.Errorf("Repository() = %q, want %q", got, tt.wantRepository)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package differ
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// syncWaveAnnotation orders the resources ArgoCD applies, lower waves first
+const syncWaveAnnotation = "argocd.argoproj.io/sync-wave"
+
+// ApplyStep is a changed resource in the order a merge would apply it
+type ApplyStep struct {
+	// Name identifies the resource's item in the plan
+	Name string
+
+	// Wave is the resource's ArgoCD sync wave
+	Wave int
+
+	// Deleted resources are pruned after the sync, highest wave first
+	Deleted bool
+
+	// DependsOn are the names of the changed resources this one references
+	DependsOn []string
+}
+
+// ApplyOrder orders the changed resources of a plan as ArgoCD would apply them on merge: by sync
+// wave, and within a wave after the resources they reference; deletions come last, in reverse wave order
+// References are spec fields named *Ref or *Refs naming another resource of the plan
+func ApplyOrder(items []PlanItem) []ApplyStep {
+	byName := make(map[string]string)
+	xrs := make(map[string]*unstructured.Unstructured)
+	var applied, deleted []ApplyStep
+	for _, item := range items {
+		if !item.Changed() {
+			continue
+		}
+		step := ApplyStep{Name: item.Name, Wave: syncWave(item.XR), Deleted: item.Action == ActionDelete}
+		if step.Deleted {
+			deleted = append(deleted, step)
+			continue
+		}
+		if item.XR != nil {
+			byName[item.XR.GetName()] = item.Name
+			xrs[item.Name] = item.XR
+		}
+		applied = append(applied, step)
+	}
+
+	for i := range applied {
+		seen := make(map[string]bool)
+		for _, name := range referencedNames(xrs[applied[i].Name]) {
+			if dep, ok := byName[name]; ok && dep != applied[i].Name && !seen[dep] {
+				seen[dep] = true
+				applied[i].DependsOn = append(applied[i].DependsOn, dep)
+			}
+		}
+		sort.Strings(applied[i].DependsOn)
+	}
+
+	sort.Slice(applied, func(i, j int) bool {
+		if applied[i].Wave != applied[j].Wave {
+			return applied[i].Wave < applied[j].Wave
+		}
+		return applied[i].Name < applied[j].Name
+	})
+	sort.Slice(deleted, func(i, j int) bool {
+		if deleted[i].Wave != deleted[j].Wave {
+			return deleted[i].Wave > deleted[j].Wave
+		}
+		return deleted[i].Name < deleted[j].Name
+	})
+
+	var order []ApplyStep
+	for start := 0; start < len(applied); {
+		end := start
+		for end < len(applied) && applied[end].Wave == applied[start].Wave {
+			end++
+		}
+		order = append(order, orderWave(applied[start:end])...)
+		start = end
+	}
+	return append(order, deleted...)
+}
+
+// orderWave orders the steps of a wave after the steps they depend on, keeping the given order otherwise
+// Dependencies on other waves are already satisfied; steps in a cycle keep the given order
+func orderWave(steps []ApplyStep) []ApplyStep {
+	inWave := make(map[string]bool, len(steps))
+	for _, step := range steps {
+		inWave[step.Name] = true
+	}
+
+	done := make(map[string]bool, len(steps))
+	var order []ApplyStep
+	for len(order) < len(steps) {
+		progressed := false
+		for _, step := range steps {
+			if done[step.Name] || !dependenciesDone(step, inWave, done) {
+				continue
+			}
+			done[step.Name] = true
+			order = append(order, step)
+			progressed = true
+		}
+		if !progressed {
+			// A cycle: apply its steps in the given order
+			for _, step := range steps {
+				if !done[step.Name] {
+					done[step.Name] = true
+					order = append(order, step)
+				}
+			}
+		}
+	}
+	return order
+}
+
+// dependenciesDone reports whether the dependencies of a step within its wave are ordered
+func dependenciesDone(step ApplyStep, inWave, done map[string]bool) bool {
+	for _, dep := range step.DependsOn {
+		if inWave[dep] && !done[dep] {
+			return false
+		}
+	}
+	return true
+}
+
+// syncWave returns the sync wave of a resource (0 when unset or invalid)
+func syncWave(xr *unstructured.Unstructured) int {
+	if xr == nil {
+		return 0
+	}
+	wave, err := strconv.Atoi(strings.TrimSpace(xr.GetAnnotations()[syncWaveAnnotation]))
+	if err != nil {
+		return 0
+	}
+	return wave
+}
+
+// referencedNames returns the names in the spec's *Ref and *Refs fields, e.g. spec.parameters.networkRef.name
+func referencedNames(xr *unstructured.Unstructured) []string {
+	if xr == nil {
+		return nil
+	}
+	spec, _, _ := unstructured.NestedMap(xr.Object, "spec")
+	var names []string
+	collectReferences(spec, &names)
+	return names
+}
+
+// collectReferences walks a value for reference fields
+func collectReferences(value interface{}, names *[]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			switch {
+			case strings.HasSuffix(key, "Ref"):
+				if name := referenceName(child); name != "" {
+					*names = append(*names, name)
+				}
+			case strings.HasSuffix(key, "Refs"):
+				if refs, ok := child.([]interface{}); ok {
+					for _, ref := range refs {
+						if name := referenceName(ref); name != "" {
+							*names = append(*names, name)
+						}
+					}
+				}
+			default:
+				collectReferences(child, names)
+			}
+		}
+	case []interface{}:
+		for _, child := range v {
+			collectReferences(child, names)
+		}
+	}
+}
+
+// referenceName returns the name of a reference ({name: ...})
+func referenceName(ref interface{}) string {
+	refMap, ok := ref.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	name, _ := refMap["name"].(string)
+	return name
+}
@@ -0,0 +1,68 @@
+package differ
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestApplyOrder(t *testing.T) {
+	xr := func(kind, name, wave string, spec map[string]interface{}) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "example.org/v1alpha1",
+			"kind":       kind,
+			"metadata":   map[string]interface{}{"name": name},
+			"spec":       spec,
+		}}
+		if wave != "" {
+			u.SetAnnotations(map[string]string{syncWaveAnnotation: wave})
+		}
+		return u
+	}
+
+	items := []PlanItem{
+		NewPlanItem("db", &DiffResult{HasChanges: true, XR: xr("XDatabase", "db", "", map[string]interface{}{
+			"parameters": map[string]interface{}{"subnetRefs": []interface{}{map[string]interface{}{"name": "subnet"}}},
+		})}),
+		NewPlanItem("subnet", &DiffResult{HasChanges: true, XR: xr("XSubnet", "subnet", "", map[string]interface{}{
+			"networkRef": map[string]interface{}{"name": "vpc"},
+		})}),
+		NewPlanItem("vpc", &DiffResult{HasChanges: true, XR: xr("XNetwork", "vpc", "-1", nil)}),
+		NewPlanItem("cache", &DiffResult{HasChanges: true, XR: xr("XCache", "cache", "", nil)}),
+		NewPlanItem("unchanged", &DiffResult{HasChanges: false, XR: xr("XCache", "unchanged", "", nil)}),
+	}
+	queue := schema.GroupVersionKind{Group: "example.org", Version: "v1alpha1", Kind: "XQueue"}
+	topic := schema.GroupVersionKind{Group: "example.org", Version: "v1alpha1", Kind: "XTopic"}
+	items = AddDeletion(items, queue, "", "old-queue", &DiffResult{HasChanges: true})
+	items = AddDeletion(items, topic, "", "old-topic", &DiffResult{HasChanges: true, XR: xr("XTopic", "old-topic", "3", nil)})
+
+	var got []string
+	for _, step := range ApplyOrder(items) {
+		got = append(got, step.Name)
+	}
+	want := []string{
+		"vpc",
+		"cache", "subnet", "db",
+		"XTopic.example.org/old-topic", "XQueue.example.org/old-queue",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ApplyOrder() = %v, want %v", got, want)
+	}
+}
+
+func TestApplyOrder_Cycle(t *testing.T) {
+	ref := func(name string) map[string]interface{} {
+		return map[string]interface{}{"peerRef": map[string]interface{}{"name": name}}
+	}
+	items := []PlanItem{
+		NewPlanItem("a", &DiffResult{HasChanges: true, XR: &unstructured.Unstructured{Object: map[string]interface{}{"metadata": map[string]interface{}{"name": "a"}, "spec": ref("b")}}}),
+		NewPlanItem("b", &DiffResult{HasChanges: true, XR: &unstructured.Unstructured{Object: map[string]interface{}{"metadata": map[string]interface{}{"name": "b"}, "spec": ref("a")}}}),
+	}
+
+	steps := ApplyOrder(items)
+	if len(steps) != 2 || steps[0].Name != "a" || steps[1].Name != "b" {
+		t.Errorf("ApplyOrder() = %+v, want a, b", steps)
+	}
+}
@@ -0,0 +1,213 @@
+package differ
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/millstonehq/crossplane-plan/pkg/errclass"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// populatedFields are set on live XRs by the API server or Crossplane rather than declared in Git
+// They are dropped from the PR XR unless the declared XR sets them too
+var populatedFields = [][]string{
+	{"status"},
+	{"metadata", "uid"},
+	{"metadata", "resourceVersion"},
+	{"metadata", "generation"},
+	{"metadata", "creationTimestamp"},
+	{"metadata", "managedFields"},
+	{"metadata", "finalizers"},
+	{"metadata", "ownerReferences"},
+	{"metadata", "annotations", "kubectl.kubernetes.io/last-applied-configuration"},
+	{"spec", "resourceRefs"},
+	{"spec", "compositionRef"},
+	{"spec", "compositionRevisionRef"},
+	{"spec", "compositionUpdatePolicy"},
+	{"spec", "claimRef"},
+	{"spec", "crossplane"},
+}
+
+// Declared holds production objects as declared in Git, keyed by group, kind, namespace and name
+type Declared map[string]*unstructured.Unstructured
+
+// declaredKey identifies an object independently of its API version
+func declaredKey(group, kind, namespace, name string) string {
+	return strings.Join([]string{group, kind, namespace, name}, "/")
+}
+
+// ParseDeclared reads the Kubernetes objects in YAML manifests
+// Documents that aren't objects (e.g., templates, values files) are skipped and returned as errors
+func ParseDeclared(files map[string][]byte) (Declared, []error) {
+	declared := make(Declared)
+	var errs []error
+
+	// Parse in a stable order so duplicates resolve the same way every time
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(files[path])))
+		for {
+			doc, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", path, err))
+				break
+			}
+
+			obj := &unstructured.Unstructured{}
+			if err := yaml.Unmarshal(doc, &obj.Object); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", path, err))
+				continue
+			}
+			if obj.Object == nil || obj.GetKind() == "" || obj.GetName() == "" {
+				continue // Empty document or not an object
+			}
+
+			gvk := obj.GroupVersionKind()
+			declared[declaredKey(gvk.Group, gvk.Kind, obj.GetNamespace(), obj.GetName())] = obj
+		}
+	}
+	return declared, errs
+}
+
+// Lookup returns the declared object for an XR, or nil if it isn't declared
+// Objects declared without a namespace (set by the GitOps tool at sync) match any namespace
+func (d Declared) Lookup(xr *unstructured.Unstructured) *unstructured.Unstructured {
+	gvk := xr.GroupVersionKind()
+	if obj, ok := d[declaredKey(gvk.Group, gvk.Kind, xr.GetNamespace(), xr.GetName())]; ok {
+		return obj
+	}
+	return d[declaredKey(gvk.Group, gvk.Kind, "", xr.GetName())]
+}
+
+// CalculateDeclaredDiff diffs the XR against its production version as declared in Git,
+// instead of the live cluster, so the diff is unaffected by live drift
+// Only the XR itself is compared; compositions aren't rendered and managed resources aren't analyzed
+// A nil declared XR means the XR is new
+// With threeWay, the declared production XR is also compared against the live one (see DiffResult.ProductionDrift)
+func (c *Calculator) CalculateDeclaredDiff(ctx context.Context, xr, declared *unstructured.Unstructured, threeWay bool) (*DiffResult, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.calculateDeclaredDiff(ctx, xr, declared, c.sanitizer, threeWay)
+}
+
+// CalculateDeclaredDiffWithSanitizer is CalculateDeclaredDiff with the given sanitizer instead of the default one
+func (c *Calculator) CalculateDeclaredDiffWithSanitizer(ctx context.Context, xr, declared *unstructured.Unstructured, sanitizer *Sanitizer, threeWay bool) (*DiffResult, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.calculateDeclaredDiff(ctx, xr, declared, sanitizer, threeWay)
+}
+
+// calculateDeclaredDiff performs the declared diff; callers must hold c.mu for reading
+func (c *Calculator) calculateDeclaredDiff(ctx context.Context, xr, declared *unstructured.Unstructured, sanitizer *Sanitizer, threeWay bool) (*DiffResult, error) {
+	var strippedFields []StrippedField
+	xrForDiff := xr
+	if sanitizer != nil {
+		sanitizeResult := sanitizer.Sanitize(xr)
+		xrForDiff = sanitizeResult.SanitizedXR
+		strippedFields = sanitizeResult.StrippedFields
+	}
+
+	current, desired := declaredPair(xrForDiff, declared)
+	if current != nil && sanitizer != nil {
+		current = sanitizer.Sanitize(current).SanitizedXR
+	}
+
+	diffOutput, err := c.renderDiff(ctx, current, desired)
+	if err != nil {
+		return nil, errclass.Wrap(errclass.ErrDiffEngine, fmt.Errorf("failed to calculate declared diff: %w", err))
+	}
+
+	hasChanges := len(strings.TrimSpace(diffOutput)) > 0
+	result := &DiffResult{
+		XR:             xr,
+		RawDiff:        diffOutput,
+		HasChanges:     hasChanges,
+		Summary:        c.generateSummary(xr, diffOutput, hasChanges),
+		StrippedFields: strippedFields,
+		DiffInput:      xrForDiff,
+		New:            declared == nil,
+	}
+
+	// Separate drift that predates the PR from the changes it introduces
+	if threeWay && declared != nil {
+		drift, err := c.productionDrift(ctx, xr, declared, sanitizer)
+		if err != nil {
+			c.logger.Info("Failed to compare declared production against the cluster", "xr", xr.GetName(), "error", err)
+			// Non-fatal: continue with the declared diff only
+		}
+		result.ProductionDrift = drift
+	}
+
+	return result, nil
+}
+
+// productionDrift diffs the production XR as declared in Git against the live one
+// Returns an empty string if they match or the XR isn't deployed yet
+func (c *Calculator) productionDrift(ctx context.Context, xr, declared *unstructured.Unstructured, sanitizer *Sanitizer) (string, error) {
+	e, err := c.acquire(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to initialize calculator: %w", err)
+	}
+	resourceClient := e.k8sClients.Resource
+	c.release(e, nil)
+
+	live, err := resourceClient.GetResource(ctx, xr.GroupVersionKind(), xr.GetNamespace(), xr.GetName())
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get live production XR: %w", err)
+	}
+
+	current, desired := declaredPair(live, declared)
+	if sanitizer != nil {
+		current = sanitizer.Sanitize(current).SanitizedXR
+		desired = sanitizer.Sanitize(desired).SanitizedXR
+	}
+	return c.renderDiff(ctx, current, desired)
+}
+
+// declaredPair prepares the declared (current) and PR (desired) XRs for comparison
+// Fields populated on the live PR XR are dropped unless declared, and a declared XR without
+// a namespace takes the PR XR's
+func declaredPair(xr, declared *unstructured.Unstructured) (*unstructured.Unstructured, *unstructured.Unstructured) {
+	desired := xr.DeepCopy()
+
+	var current *unstructured.Unstructured
+	if declared != nil {
+		current = declared.DeepCopy()
+		if current.GetNamespace() == "" {
+			current.SetNamespace(desired.GetNamespace())
+		}
+	}
+
+	for _, path := range populatedFields {
+		if current != nil {
+			if _, found, _ := unstructured.NestedFieldNoCopy(current.Object, path...); found {
+				continue
+			}
+		}
+		unstructured.RemoveNestedField(desired.Object, path...)
+	}
+	if len(desired.GetAnnotations()) == 0 {
+		unstructured.RemoveNestedField(desired.Object, "metadata", "annotations")
+	}
+	return current, desired
+}
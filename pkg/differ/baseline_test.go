@@ -0,0 +1,112 @@
+package differ
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestParseDeclared(t *testing.T) {
+	files := map[string][]byte{
+		"prod/db.yaml": []byte(`apiVersion: example.org/v1alpha1
+kind: XDatabase
+metadata:
+  name: orders
+  namespace: prod
+spec:
+  parameters:
+    size: small
+---
+# Empty document
+---
+apiVersion: example.org/v1alpha1
+kind: XNetwork
+metadata:
+  name: vpc
+`),
+		"prod/chart/templates/bucket.yaml": []byte("apiVersion: v1\nkind: {{ .Values.kind }}\n"),
+	}
+
+	declared, errs := ParseDeclared(files)
+	if len(errs) != 1 {
+		t.Errorf("ParseDeclared() errors = %v, want 1 error for the template", errs)
+	}
+	if len(declared) != 2 {
+		t.Fatalf("ParseDeclared() = %d objects, want 2", len(declared))
+	}
+
+	tests := []struct {
+		name       string
+		apiVersion string
+		kind       string
+		namespace  string
+		xrName     string
+		want       bool
+	}{
+		{name: "same namespace", apiVersion: "example.org/v1alpha1", kind: "XDatabase", namespace: "prod", xrName: "orders", want: true},
+		{name: "other version", apiVersion: "example.org/v1", kind: "XDatabase", namespace: "prod", xrName: "orders", want: true},
+		{name: "other namespace", apiVersion: "example.org/v1alpha1", kind: "XDatabase", namespace: "dev", xrName: "orders", want: false},
+		{name: "declared without namespace", apiVersion: "example.org/v1alpha1", kind: "XNetwork", namespace: "prod", xrName: "vpc", want: true},
+		{name: "not declared", apiVersion: "example.org/v1alpha1", kind: "XDatabase", namespace: "prod", xrName: "payments", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			xr := &unstructured.Unstructured{}
+			xr.SetAPIVersion(tt.apiVersion)
+			xr.SetKind(tt.kind)
+			xr.SetNamespace(tt.namespace)
+			xr.SetName(tt.xrName)
+			if got := declared.Lookup(xr) != nil; got != tt.want {
+				t.Errorf("Lookup() found = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeclaredPair(t *testing.T) {
+	xr := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.org/v1alpha1",
+		"kind":       "XDatabase",
+		"metadata": map[string]interface{}{
+			"name":        "orders",
+			"namespace":   "prod",
+			"annotations": map[string]interface{}{"kubectl.kubernetes.io/last-applied-configuration": "{}"},
+		},
+		"spec": map[string]interface{}{
+			"parameters":     map[string]interface{}{"size": "large"},
+			"resourceRefs":   []interface{}{map[string]interface{}{"name": "orders-abc"}},
+			"compositionRef": map[string]interface{}{"name": "generated"},
+		},
+		"status": map[string]interface{}{"ready": true},
+	}}
+	declared := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.org/v1alpha1",
+		"kind":       "XDatabase",
+		"metadata":   map[string]interface{}{"name": "orders"},
+		"spec": map[string]interface{}{
+			"parameters":     map[string]interface{}{"size": "small"},
+			"compositionRef": map[string]interface{}{"name": "pinned"},
+		},
+	}}
+
+	current, desired := declaredPair(xr, declared)
+
+	if current.GetNamespace() != "prod" {
+		t.Errorf("current namespace = %q, want the PR XR's", current.GetNamespace())
+	}
+	for _, path := range [][]string{{"status"}, {"spec", "resourceRefs"}, {"metadata", "annotations"}} {
+		if _, found, _ := unstructured.NestedFieldNoCopy(desired.Object, path...); found {
+			t.Errorf("desired still has populated field %v", path)
+		}
+	}
+	if _, found, _ := unstructured.NestedFieldNoCopy(desired.Object, "spec", "compositionRef"); !found {
+		t.Error("desired lost spec.compositionRef, which is declared in Git")
+	}
+	if _, found, _ := unstructured.NestedFieldNoCopy(xr.Object, "status"); !found {
+		t.Error("declaredPair() modified the PR XR")
+	}
+
+	if current, _ := declaredPair(xr, nil); current != nil {
+		t.Error("declaredPair() current != nil for an undeclared XR")
+	}
+}
@@ -0,0 +1,58 @@
+package differ
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/millstonehq/crossplane-plan/pkg/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// benchmarkXR returns an XR with Crossplane-populated metadata and a sizable spec
+func benchmarkXR() *unstructured.Unstructured {
+	parameters := make(map[string]interface{}, 50)
+	for i := 0; i < 50; i++ {
+		parameters[fmt.Sprintf("field%d", i)] = fmt.Sprintf("value-%d", i)
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.org/v1alpha1",
+		"kind":       "XNetwork",
+		"metadata": map[string]interface{}{
+			"name":        "vpc",
+			"labels":      map[string]interface{}{"crossplane.io/composite": "vpc", "team": "platform"},
+			"annotations": map[string]interface{}{"kubectl.kubernetes.io/last-applied-configuration": "{}"},
+		},
+		"spec": map[string]interface{}{
+			"parameters":            parameters,
+			"compositionRef":        map[string]interface{}{"name": "network"},
+			"resourceRefs":          []interface{}{map[string]interface{}{"kind": "VPC", "name": "vpc-abc"}},
+			"compositeDeletePolicy": "Background",
+		},
+	}}
+}
+
+func BenchmarkSanitizer_Sanitize(b *testing.B) {
+	sanitizer := NewSanitizer(config.DefaultStripRules())
+	xr := benchmarkXR()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sanitizer.Sanitize(xr)
+	}
+}
+
+func BenchmarkAttributeFields(b *testing.B) {
+	desired := benchmarkXR()
+	current := benchmarkXR()
+	params, _, _ := unstructured.NestedMap(current.Object, "spec", "parameters")
+	for i := 0; i < 50; i += 2 {
+		params[fmt.Sprintf("field%d", i)] = "changed"
+	}
+	_ = unstructured.SetNestedMap(current.Object, params, "spec", "parameters")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		attributeFields(desired, current)
+	}
+}
@@ -0,0 +1,223 @@
+package differ
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/crossplane-contrib/crossplane-diff/cmd/diff/diffprocessor"
+	"github.com/crossplane/crossplane-runtime/v2/pkg/logging"
+	"github.com/crossplane/crossplane/v2/cmd/crank/render"
+	fnv1 "github.com/crossplane/crossplane/v2/proto/fn/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// BundleVersion is the format version of plan bundles
+const BundleVersion = 1
+
+// Bundle holds the recorded inputs of a plan, so it can be replayed offline
+// Plugin engines are not recorded; they run again on replay
+type Bundle struct {
+	Version    int       `json:"version"`
+	RecordedAt time.Time `json:"recordedAt"`
+
+	// Config is the crossplane-plan configuration (config.yaml) the plan ran with
+	Config string `json:"config,omitempty"`
+
+	// XRs are the diffed XRs, before sanitizing
+	XRs []map[string]interface{} `json:"xrs"`
+
+	// Requests are the Kubernetes API responses the diff engines read, in order
+	Requests []RecordedRequest `json:"requests"`
+
+	// Renders are the composition function pipeline outputs, in call order
+	Renders []RecordedRender `json:"renders"`
+
+	// Diffs are the recorded raw diffs, keyed by XR name
+	Diffs map[string]string `json:"diffs"`
+}
+
+// RecordedRequest is a Kubernetes API request and its response
+type RecordedRequest struct {
+	Method      string `json:"method"`
+	URI         string `json:"uri"`
+	Status      int    `json:"status"`
+	ContentType string `json:"contentType,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// RecordedRender is the output of a composition function pipeline run
+type RecordedRender struct {
+	CompositeResource map[string]interface{}     `json:"compositeResource,omitempty"`
+	ComposedResources []map[string]interface{}   `json:"composedResources,omitempty"`
+	Results           []map[string]interface{}   `json:"results,omitempty"`
+	Context           map[string]interface{}     `json:"context,omitempty"`
+	Requirements      map[string]json.RawMessage `json:"requirements,omitempty"`
+	Error             string                     `json:"error,omitempty"`
+}
+
+// LoadBundle reads a plan bundle from a file
+func LoadBundle(path string) (*Bundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+	var bundle Bundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle: %w", err)
+	}
+	if bundle.Version != BundleVersion {
+		return nil, fmt.Errorf("unsupported bundle version %d, expected %d", bundle.Version, BundleVersion)
+	}
+	return &bundle, nil
+}
+
+// Write writes the bundle as JSON
+func (b *Bundle) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(b)
+}
+
+// Recorder captures the inputs and diffs of a Calculator into a Bundle
+type Recorder struct {
+	mu     sync.Mutex
+	bundle Bundle
+}
+
+// NewRecorder creates a Recorder; config is the configuration file content stored in the bundle
+func NewRecorder(config string) *Recorder {
+	return &Recorder{bundle: Bundle{
+		Version:    BundleVersion,
+		RecordedAt: time.Now().UTC(),
+		Config:     config,
+		Diffs:      make(map[string]string),
+	}}
+}
+
+// Bundle returns the recorded bundle
+func (r *Recorder) Bundle() *Bundle {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	bundle := r.bundle
+	return &bundle
+}
+
+// SetRecorder records the inputs of every diff into r
+// Must be called before the first diff
+func (c *Calculator) SetRecorder(r *Recorder) {
+	c.recorder = r
+	c.wrapTransport = func(rt http.RoundTripper) http.RoundTripper {
+		return &recordingTransport{next: rt, recorder: r}
+	}
+	c.renderFunc = r.render
+}
+
+// recordDiff records a diffed XR and its raw diff
+func (r *Recorder) recordDiff(xr *unstructured.Unstructured, rawDiff string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bundle.XRs = append(r.bundle.XRs, xr.DeepCopy().Object)
+	r.bundle.Diffs[xr.GetName()] = rawDiff
+}
+
+// render runs the composition function pipeline and records its output
+func (r *Recorder) render(ctx context.Context, log logging.Logger, in render.Inputs) (render.Outputs, error) {
+	out, err := render.Render(ctx, log, in)
+	recorded, recordErr := recordRender(out, err)
+	if recordErr != nil {
+		return out, fmt.Errorf("failed to record render: %w", recordErr)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bundle.Renders = append(r.bundle.Renders, recorded)
+	return out, err
+}
+
+// recordRender converts a render's outputs to their recorded form
+func recordRender(out render.Outputs, renderErr error) (RecordedRender, error) {
+	var recorded RecordedRender
+	if renderErr != nil {
+		recorded.Error = renderErr.Error()
+		return recorded, nil
+	}
+
+	if out.CompositeResource != nil {
+		recorded.CompositeResource = out.CompositeResource.UnstructuredContent()
+	}
+	for _, composed := range out.ComposedResources {
+		recorded.ComposedResources = append(recorded.ComposedResources, composed.UnstructuredContent())
+	}
+	for _, result := range out.Results {
+		recorded.Results = append(recorded.Results, result.Object)
+	}
+	if out.Context != nil {
+		recorded.Context = out.Context.Object
+	}
+	// Render outputs hold requirements by value; reflect copies them out of the map without
+	// tripping vet's copylocks check on the generated protobuf messages
+	requirements := reflect.ValueOf(out.Requirements)
+	for _, name := range requirements.MapKeys() {
+		message := reflect.New(requirements.Type().Elem())
+		message.Elem().Set(requirements.MapIndex(name))
+		data, err := protojson.Marshal(message.Interface().(*fnv1.Requirements))
+		if err != nil {
+			return recorded, fmt.Errorf("failed to encode requirements of %s: %w", name.String(), err)
+		}
+		if recorded.Requirements == nil {
+			recorded.Requirements = make(map[string]json.RawMessage)
+		}
+		recorded.Requirements[name.String()] = data
+	}
+	return recorded, nil
+}
+
+// recordingTransport records the Kubernetes API responses passing through it
+type recordingTransport struct {
+	next     http.RoundTripper
+	recorder *Recorder
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response to record: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	t.recorder.mu.Lock()
+	defer t.recorder.mu.Unlock()
+	t.recorder.bundle.Requests = append(t.recorder.bundle.Requests, RecordedRequest{
+		Method:      req.Method,
+		URI:         req.URL.RequestURI(),
+		Status:      resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		Body:        body,
+	})
+	return resp, nil
+}
+
+// renderFuncOption returns the processor option replacing the render function
+// Live renders can capture their pipeline steps; recorded and replayed renders can't, as replays
+// serve renders in recorded order
+func (c *Calculator) renderFuncOption() []diffprocessor.ProcessorOption {
+	if c.renderFunc == nil {
+		return []diffprocessor.ProcessorOption{diffprocessor.WithRenderFunc(capturingRender(render.Render))}
+	}
+	return []diffprocessor.ProcessorOption{diffprocessor.WithRenderFunc(c.renderFunc)}
+}
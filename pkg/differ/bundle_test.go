@@ -0,0 +1,158 @@
+package differ
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/v2/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/v2/pkg/resource/unstructured/composed"
+	ucomposite "github.com/crossplane/crossplane-runtime/v2/pkg/resource/unstructured/composite"
+	"github.com/crossplane/crossplane/v2/cmd/crank/render"
+	fnv1 "github.com/crossplane/crossplane/v2/proto/fn/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestBundle_RecordAndReplayRequests(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"call":%d}`, calls)
+	}))
+	defer server.Close()
+
+	recorder := NewRecorder("diff:\n  stripDefaults: true\n")
+	client := &http.Client{Transport: &recordingTransport{next: http.DefaultTransport, recorder: recorder}}
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL + "/apis/example.org/v1/xnetworks?limit=500")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if want := fmt.Sprintf(`{"call":%d}`, i+1); string(body) != want {
+			t.Fatalf("recorded response body = %s, want %s", body, want)
+		}
+	}
+
+	// Round trip the bundle through a file
+	var buf bytes.Buffer
+	if err := recorder.Bundle().Write(&buf); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "bundle.json")
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	bundle, err := LoadBundle(path)
+	if err != nil {
+		t.Fatalf("LoadBundle() error = %v", err)
+	}
+	if len(bundle.Requests) != 2 || bundle.Config == "" {
+		t.Fatalf("bundle = %+v, want 2 requests and the config", bundle)
+	}
+
+	replay := NewReplayCalculator(bundle, logging.NewNopLogger())
+	client = &http.Client{Transport: replay.config.Transport}
+	for _, want := range []string{`{"call":1}`, `{"call":2}`, `{"call":2}`} {
+		resp, err := client.Get(replayHost + "/apis/example.org/v1/xnetworks?limit=500")
+		if err != nil {
+			t.Fatalf("replayed Get() error = %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != want || resp.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("replayed response = %s (%s), want %s", body, resp.Header.Get("Content-Type"), want)
+		}
+	}
+
+	if _, err := client.Get(replayHost + "/apis/example.org/v1/xdatabases"); err == nil || !strings.Contains(err.Error(), "not recorded") {
+		t.Errorf("unrecorded request error = %v, want not recorded", err)
+	}
+}
+
+func TestBundle_RenderRoundTrip(t *testing.T) {
+	xr := ucomposite.New()
+	xr.SetUnstructuredContent(map[string]interface{}{
+		"apiVersion": "example.org/v1alpha1",
+		"kind":       "XNetwork",
+		"metadata":   map[string]interface{}{"name": "vpc"},
+	})
+	bucket := composed.New()
+	bucket.SetUnstructuredContent(map[string]interface{}{"apiVersion": "s3.aws.upbound.io/v1beta1", "kind": "Bucket"})
+	out := render.Outputs{
+		CompositeResource: xr,
+		ComposedResources: []composed.Unstructured{*bucket},
+		Requirements: map[string]fnv1.Requirements{
+			"lookup": {ExtraResources: map[string]*fnv1.ResourceSelector{
+				"vpc": {ApiVersion: "example.org/v1alpha1", Kind: "XNetwork"},
+			}},
+		},
+	}
+
+	recorded, err := recordRender(out, nil)
+	if err != nil {
+		t.Fatalf("recordRender() error = %v", err)
+	}
+	replayed, err := replayRender(recorded, render.Inputs{CompositeResource: xr})
+	if err != nil {
+		t.Fatalf("replayRender() error = %v", err)
+	}
+
+	if replayed.CompositeResource.GetName() != "vpc" || len(replayed.ComposedResources) != 1 {
+		t.Errorf("replayed outputs = %+v", replayed)
+	}
+	if got := replayed.ComposedResources[0].GetKind(); got != "Bucket" {
+		t.Errorf("composed kind = %q, want Bucket", got)
+	}
+	if selector := replayed.Requirements["lookup"].ExtraResources["vpc"]; selector.GetKind() != "XNetwork" {
+		t.Errorf("replayed requirements selector = %v", selector)
+	}
+
+	failed, _ := recordRender(render.Outputs{}, fmt.Errorf("function timed out"))
+	if _, err := replayRender(failed, render.Inputs{}); err == nil || !strings.Contains(err.Error(), "function timed out") {
+		t.Errorf("replayed failure error = %v", err)
+	}
+}
+
+func TestReplayer_RendersInOrder(t *testing.T) {
+	bundle := &Bundle{Renders: []RecordedRender{
+		{CompositeResource: map[string]interface{}{"metadata": map[string]interface{}{"name": "first"}}},
+	}}
+	replay := &replayer{bundle: bundle}
+
+	out, err := replay.render(context.Background(), logging.NewNopLogger(), render.Inputs{})
+	if err != nil || out.CompositeResource.GetName() != "first" {
+		t.Fatalf("render() = %v, %v, want first", out.CompositeResource, err)
+	}
+	if _, err := replay.render(context.Background(), logging.NewNopLogger(), render.Inputs{}); err == nil {
+		t.Error("render() past the recording succeeded, want error")
+	}
+}
+
+func TestLoadBundle_Version(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bundle.json")
+	if err := os.WriteFile(path, []byte(`{"version": 99}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadBundle(path); err == nil || !strings.Contains(err.Error(), "unsupported bundle version") {
+		t.Errorf("LoadBundle() error = %v, want unsupported version", err)
+	}
+}
+
+func TestBundle_Resources(t *testing.T) {
+	bundle := &Bundle{XRs: []map[string]interface{}{{"kind": "XNetwork", "metadata": map[string]interface{}{"name": "vpc"}}}}
+	xrs := bundle.Resources()
+	xrs[0].SetName("changed")
+	if name := (&unstructured.Unstructured{Object: bundle.XRs[0]}).GetName(); name != "vpc" {
+		t.Errorf("Resources() shares state with the bundle: name = %q", name)
+	}
+}
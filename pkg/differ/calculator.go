@@ -1,17 +1,26 @@
 package differ
 
 import (
-	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"math"
+	"net/http"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/crossplane-contrib/crossplane-diff/cmd/diff/client/core"
 	xp "github.com/crossplane-contrib/crossplane-diff/cmd/diff/client/crossplane"
 	k8 "github.com/crossplane-contrib/crossplane-diff/cmd/diff/client/kubernetes"
 	"github.com/crossplane-contrib/crossplane-diff/cmd/diff/diffprocessor"
 	"github.com/crossplane/crossplane-runtime/v2/pkg/logging"
+	"github.com/millstonehq/crossplane-plan/pkg/config"
+	"github.com/millstonehq/crossplane-plan/pkg/errclass"
+	"github.com/millstonehq/crossplane-plan/pkg/severity"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/rest"
@@ -70,6 +79,57 @@ type DiffResult struct {
 
 	// StrippedFields tracks fields that were removed before diff for transparency
 	StrippedFields []StrippedField
+
+	// DiffInput is the sanitized XR exactly as passed to crossplane-diff
+	DiffInput *unstructured.Unstructured
+
+	// Debug requests that DiffInput is shown alongside the diff
+	Debug bool
+
+	// FieldOwners attributes the changed spec fields to their field managers in production
+	FieldOwners []FieldOwner
+
+	// ProductionDrift is the diff from production as declared in Git to the live production XR
+	// Only set by three-way comparisons; it predates the PR and isn't part of RawDiff
+	ProductionDrift string
+
+	// Links are deep links for triaging the resource (ArgoCD, dashboards, ...)
+	Links []Link
+
+	// Sources link to the files of the PR that likely declare the resource
+	Sources []Link
+
+	// Severity rates the result for every surface rendering it (see AssignSeverities)
+	Severity severity.Level
+
+	// Blocked marks deletions of kinds a deletion policy blocks
+	Blocked bool
+
+	// Error is why the resource could not be planned, for results of severity.Error
+	Error string
+
+	// New marks XRs without a production counterpart, which the PR creates
+	New bool
+
+	// OrderingChanges are changes to sync-wave and hook annotations, which alter apply ordering
+	// even when the spec is unchanged
+	OrderingChanges []OrderingChange
+
+	// Pipeline is the output of every composition function pipeline step, when the diff
+	// requested it (see WithPipelineCapture)
+	Pipeline []PipelineCapture
+
+	// PipelineArtifact is the file Pipeline was written to, if any
+	PipelineArtifact string
+}
+
+// Link is a named URL shown with a resource
+type Link struct {
+	Name string
+	URL  string
+
+	// Line is the line of a source file declaring the resource (0 if unknown)
+	Line int
 }
 
 // StrippedField represents a field that was stripped before diff
@@ -79,37 +139,99 @@ type StrippedField struct {
 }
 
 // Calculator uses crossplane-diff library to calculate diffs
+// Diffs run on a pool of engines so concurrent PRs don't share a diff processor
 type Calculator struct {
 	config      *rest.Config
 	logger      logging.Logger
-	k8sClients  k8.Clients
-	xpClients   xp.Clients
-	processor   diffprocessor.DiffProcessor
 	sanitizer   *Sanitizer
+	normalizer  *Normalizer
+	drift       config.DriftConfig
+	engineRules []config.EngineRule
+	plugins     map[string]config.DiffPlugin
+	mu          sync.RWMutex // guards sanitizer, normalizer, drift, engineRules and plugins for hot reload
 	initialized bool
+	poolSize    int
+	poolOnce    sync.Once
+	engines     chan *engine // idle engines
+
+	// Recording and replay of plan bundles
+	recorder      *Recorder
+	wrapTransport func(http.RoundTripper) http.RoundTripper
+	renderFunc    diffprocessor.RenderFunc
 }
 
 // NewCalculator creates a new Calculator
-func NewCalculator(config *rest.Config, logger logging.Logger) *Calculator {
-	return &Calculator{
+func NewCalculator(config *rest.Config, logger logging.Logger, opts ...Option) *Calculator {
+	c := &Calculator{
 		config: config,
 		logger: logger,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // SetSanitizer sets the sanitizer for stripping noise fields
 func (c *Calculator) SetSanitizer(sanitizer *Sanitizer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.sanitizer = sanitizer
 }
 
-// Initialize sets up the Kubernetes and Crossplane clients
+// SetNormalizer sets the normalizer for decoding encoded string fields
+func (c *Calculator) SetNormalizer(normalizer *Normalizer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.normalizer = normalizer
+}
+
+// SetDriftConfig sets the options for the declared-vs-actual comparison
+func (c *Calculator) SetDriftConfig(drift config.DriftConfig) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.drift = drift
+}
+
+// Configure applies the diff settings of a configuration, e.g. after a hot reload
+func (c *Calculator) Configure(cfg *config.Config) {
+	var sanitizer *Sanitizer
+	if stripRules := cfg.GetAllStripRules(); len(stripRules) > 0 {
+		sanitizer = NewSanitizer(stripRules)
+	}
+	var normalizer *Normalizer
+	if len(cfg.Diff.Normalize) > 0 {
+		normalizer = NewNormalizer(cfg.Diff.Normalize)
+	}
+
+	c.SetSanitizer(sanitizer)
+	c.SetNormalizer(normalizer)
+	c.SetDriftConfig(cfg.Diff.Drift)
+	c.SetPlugins(cfg.Diff.Plugins)
+	c.SetEngineRules(cfg.Diff.Engines)
+}
+
+// Initialize sets up the Kubernetes and Crossplane clients of one engine,
+// surfacing configuration errors before the first diff
 func (c *Calculator) Initialize(ctx context.Context) error {
-	if c.initialized {
-		return nil
+	e, err := c.acquire(ctx)
+	if err != nil {
+		return err
 	}
+	c.release(e, nil)
+	c.initialized = true
+	return nil
+}
 
+// initEngine sets up an engine's Kubernetes and Crossplane clients and diff processor
+func (c *Calculator) initEngine(ctx context.Context, e *engine) error {
 	// Create core clients
-	coreClients, err := core.NewClients(c.config)
+	restConfig := c.config
+	if c.wrapTransport != nil {
+		restConfig = rest.CopyConfig(c.config)
+		restConfig.Wrap(c.wrapTransport)
+	}
+	coreClients, err := core.NewClients(restConfig)
 	if err != nil {
 		return fmt.Errorf("failed to create core clients: %w", err)
 	}
@@ -118,7 +240,7 @@ func (c *Calculator) Initialize(ctx context.Context) error {
 	tc := k8.NewTypeConverter(coreClients, c.logger)
 
 	// Create K8s clients
-	c.k8sClients = k8.Clients{
+	e.k8sClients = k8.Clients{
 		Type:     tc,
 		Apply:    k8.NewApplyClient(coreClients, tc, c.logger),
 		Resource: k8.NewResourceClient(coreClients, tc, c.logger),
@@ -126,69 +248,90 @@ func (c *Calculator) Initialize(ctx context.Context) error {
 	}
 
 	// Create Crossplane clients
-	defClient := xp.NewDefinitionClient(c.k8sClients.Resource, c.logger)
-	c.xpClients = xp.Clients{
+	defClient := xp.NewDefinitionClient(e.k8sClients.Resource, c.logger)
+	e.xpClients = xp.Clients{
 		Definition:   defClient,
-		Composition:  xp.NewCompositionClient(c.k8sClients.Resource, defClient, c.logger),
-		Environment:  xp.NewEnvironmentClient(c.k8sClients.Resource, c.logger),
-		Function:     xp.NewFunctionClient(c.k8sClients.Resource, c.logger),
+		Composition:  xp.NewCompositionClient(e.k8sClients.Resource, defClient, c.logger),
+		Environment:  xp.NewEnvironmentClient(e.k8sClients.Resource, c.logger),
+		Function:     xp.NewFunctionClient(e.k8sClients.Resource, c.logger),
 		ResourceTree: xp.NewResourceTreeClient(coreClients.Tree, c.logger),
 	}
 
 	// Initialize Crossplane clients
-	if err := c.xpClients.Initialize(ctx, c.logger); err != nil {
+	if err := e.xpClients.Initialize(ctx, c.logger); err != nil {
 		return fmt.Errorf("failed to initialize crossplane clients: %w", err)
 	}
 
 	// Create diff processor
-	c.processor = diffprocessor.NewDiffProcessor(
-		c.k8sClients,
-		c.xpClients,
+	opts := []diffprocessor.ProcessorOption{
 		diffprocessor.WithLogger(c.logger),
 		diffprocessor.WithNamespace("default"),
 		diffprocessor.WithColorize(false),   // No colors for structured output
 		diffprocessor.WithCompact(false),
 		diffprocessor.WithMaxNestedDepth(10), // Default depth limit for nested XRs
-	)
+	}
+	opts = append(opts, c.renderFuncOption()...)
+
+	// Decode encoded fields on both sides before the diff is rendered
+	// The normalizer is looked up per render so it can be swapped on config reload
+	opts = append(opts, diffprocessor.WithDiffRendererFactory(newNormalizingRendererFactory(func() *Normalizer {
+		return c.normalizer // Read during PerformDiff, while c.mu is held
+	})))
+
+	e.processor = diffprocessor.NewDiffProcessor(e.k8sClients, e.xpClients, opts...)
 
 	// Initialize processor
-	if err := c.processor.Initialize(ctx); err != nil {
+	if err := e.processor.Initialize(ctx); err != nil {
 		return fmt.Errorf("failed to initialize diff processor: %w", err)
 	}
 
-	c.initialized = true
+	e.ready = true
 	return nil
 }
 
 // CalculateDiff calculates the diff for an XR using crossplane-diff library
 func (c *Calculator) CalculateDiff(ctx context.Context, xr *unstructured.Unstructured) (*DiffResult, error) {
-	if !c.initialized {
-		if err := c.Initialize(ctx); err != nil {
-			return nil, fmt.Errorf("failed to initialize calculator: %w", err)
-		}
-	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.calculateDiff(ctx, xr, c.sanitizer)
+}
 
+// CalculateDiffWithSanitizer calculates the diff using the given sanitizer instead of the default one
+// Used to apply per-repository strip rules; a nil sanitizer disables stripping
+func (c *Calculator) CalculateDiffWithSanitizer(ctx context.Context, xr *unstructured.Unstructured, sanitizer *Sanitizer) (*DiffResult, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.calculateDiff(ctx, xr, sanitizer)
+}
+
+// calculateDiff performs the diff; callers must hold c.mu for reading
+func (c *Calculator) calculateDiff(ctx context.Context, xr *unstructured.Unstructured, sanitizer *Sanitizer) (*DiffResult, error) {
 	// Sanitize XR if sanitizer is configured
 	var strippedFields []StrippedField
 	xrForDiff := xr
-	if c.sanitizer != nil {
-		sanitizeResult := c.sanitizer.Sanitize(xr)
+	if sanitizer != nil {
+		sanitizeResult := sanitizer.Sanitize(xr)
 		xrForDiff = sanitizeResult.SanitizedXR
 		strippedFields = sanitizeResult.StrippedFields
 	}
 
-	// Use a buffer to capture diff output
-	var buf bytes.Buffer
+	e, err := c.acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize calculator: %w", err)
+	}
+
+	// Diff with the engines selected for this kind
+	diffOutput, err := c.runEngines(ctx, e, xrForDiff)
+	resourceClient := e.k8sClients.Resource
+	c.release(e, err)
 
-	// Perform diff - PerformDiff writes to io.Writer
-	resources := []*unstructured.Unstructured{xrForDiff}
-	err := c.processor.PerformDiff(ctx, &buf, resources, c.xpClients.Composition.FindMatchingComposition)
-	
-	diffOutput := buf.String()
 	hasChanges := len(strings.TrimSpace(diffOutput)) > 0
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to calculate diff: %w", err)
+		return nil, errclass.Wrap(errclass.ErrDiffEngine, fmt.Errorf("failed to calculate diff: %w", err))
+	}
+	if c.recorder != nil {
+		c.recorder.recordDiff(xr, diffOutput)
 	}
 
 	result := &DiffResult{
@@ -197,10 +340,29 @@ func (c *Calculator) CalculateDiff(ctx context.Context, xr *unstructured.Unstruc
 		HasChanges:     hasChanges,
 		Summary:        c.generateSummary(xr, diffOutput, hasChanges),
 		StrippedFields: strippedFields,
+		DiffInput:      xrForDiff,
+	}
+	if capture := pipelineCaptureFrom(ctx); capture != nil {
+		result.Pipeline = capture.list()
+	}
+
+	if current, err := resourceClient.GetResource(ctx, xr.GroupVersionKind(), xr.GetNamespace(), xr.GetName()); err == nil {
+		// Attribute changed fields to their owners in production, to spot out-of-band edits
+		if hasChanges {
+			result.FieldOwners = attributeFields(xrForDiff, current)
+		}
+
+		// Call out ordering changes, which the diff may not show
+		if result.OrderingChanges = orderingChanges(xr, current); len(result.OrderingChanges) > 0 && !hasChanges {
+			result.HasChanges = true
+			result.Summary = fmt.Sprintf("Ordering changes for %s/%s", xr.GetKind(), xr.GetName())
+		}
+	} else if apierrors.IsNotFound(err) {
+		result.New = true
 	}
 
 	// Fetch and analyze managed resources
-	managedResources, err := c.fetchManagedResources(ctx, xr)
+	managedResources, err := c.fetchManagedResources(ctx, resourceClient, xr)
 	if err != nil {
 		c.logger.Info("Failed to fetch managed resources", "error", err)
 		// Non-fatal: continue with cluster diff only
@@ -238,7 +400,7 @@ func (c *Calculator) generateSummary(xr *unstructured.Unstructured, diff string,
 }
 
 // fetchManagedResources fetches managed resources for an XR and analyzes their state
-func (c *Calculator) fetchManagedResources(ctx context.Context, xr *unstructured.Unstructured) ([]ManagedResourceState, error) {
+func (c *Calculator) fetchManagedResources(ctx context.Context, resources k8.ResourceClient, xr *unstructured.Unstructured) ([]ManagedResourceState, error) {
 	// Get resourceRefs from XR spec
 	resourceRefs, found, err := unstructured.NestedSlice(xr.Object, "spec", "resourceRefs")
 	if err != nil || !found || len(resourceRefs) == 0 {
@@ -276,7 +438,7 @@ func (c *Calculator) fetchManagedResources(ctx context.Context, xr *unstructured
 		}
 
 		// Fetch the managed resource (managed resources are cluster-scoped)
-		mr, err := c.k8sClients.Resource.GetResource(ctx, gvk, "", name)
+		mr, err := resources.GetResource(ctx, gvk, "", name)
 		if err != nil {
 			c.logger.Info("Failed to fetch managed resource", "name", name, "gvk", gvk.String(), "error", err)
 			continue
@@ -297,6 +459,11 @@ func (c *Calculator) analyzeManagedResource(mr *unstructured.Unstructured) Manag
 		DeclaredVsActual:   make(map[string]FieldComparison),
 	}
 
+	// Compare decoded representations so encoding differences aren't reported as drift
+	if c.normalizer != nil {
+		mr = c.normalizer.Normalize(mr)
+	}
+
 	// Extract managementPolicies
 	policies, found, _ := unstructured.NestedStringSlice(mr.Object, "spec", "managementPolicies")
 	if found {
@@ -338,38 +505,170 @@ func (c *Calculator) analyzeManagedResource(mr *unstructured.Unstructured) Manag
 	// Compare spec.forProvider vs status.atProvider
 	if state.HasAtProvider && state.SpecForProvider != nil {
 		state.DeclaredVsActual = c.compareFields(state.SpecForProvider, state.StatusAtProvider)
+		c.dropIgnoredDrift(mr.GroupVersionKind(), state.DeclaredVsActual)
 	}
 
 	return state
 }
 
-// compareFields compares two maps and returns differences
+// compareFields compares two maps and returns differences keyed by dot-separated path
+// Nested maps are compared field by field down to the drift config's max depth,
+// so a changed tag is reported as "tags.env" rather than the whole "tags" map
 func (c *Calculator) compareFields(declared, actual map[string]interface{}) map[string]FieldComparison {
 	differences := make(map[string]FieldComparison)
+	c.compareNested(declared, actual, "", 1, differences)
+	return differences
+}
 
-	// Check all fields in declared state
-	for key, declaredValue := range declared {
-		actualValue, exists := actual[key]
+// valuesEqual compares two values for semantic equality
+// Providers often report numbers, booleans, and quantities in a different
+// representation than they were declared ("2" vs 2 vs 2.0, "1Gi" vs "1024Mi"),
+// so scalars are coerced before comparison and collections are compared element-wise
+func (c *Calculator) valuesEqual(a, b interface{}) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
 
-		// Skip if actual doesn't have this field
-		if !exists {
-			continue
+	switch aVal := a.(type) {
+	case map[string]interface{}:
+		bVal, ok := b.(map[string]interface{})
+		if !ok || len(aVal) != len(bVal) {
+			return false
 		}
-
-		// Compare values (simple comparison, could be enhanced)
-		if !c.valuesEqual(declaredValue, actualValue) {
-			differences[key] = FieldComparison{
-				Path:     key,
-				Declared: declaredValue,
-				Actual:   actualValue,
+		for key, av := range aVal {
+			bv, exists := bVal[key]
+			if !exists || !c.valuesEqual(av, bv) {
+				return false
 			}
 		}
+		return true
+	case []interface{}:
+		bVal, ok := b.([]interface{})
+		if !ok || len(aVal) != len(bVal) {
+			return false
+		}
+		for i := range aVal {
+			if !c.valuesEqual(aVal[i], bVal[i]) {
+				return false
+			}
+		}
+		return true
 	}
 
-	return differences
+	return scalarsEqual(a, b)
 }
 
-// valuesEqual compares two values for equality using deep comparison
-func (c *Calculator) valuesEqual(a, b interface{}) bool {
-	return reflect.DeepEqual(a, b)
+// scalarsEqual compares two scalar values with numeric, boolean, and quantity coercion
+// Numbers and booleans are only coerced across representations, so two strings
+// such as "t" and "true" still differ
+func scalarsEqual(a, b interface{}) bool {
+	if reflect.TypeOf(a) != reflect.TypeOf(b) {
+		// Numeric comparison: 2 == 2.0 == "2"
+		aNum, aIsNum := toFloat(a)
+		bNum, bIsNum := toFloat(b)
+		if aIsNum && bIsNum {
+			return aNum == bNum
+		}
+
+		// Boolean comparison: true == "true"
+		aBool, aIsBool := toBool(a)
+		bBool, bIsBool := toBool(b)
+		if aIsBool && bIsBool {
+			return aBool == bBool
+		}
+	}
+
+	// Kubernetes quantity comparison: "1Gi" == "1024Mi", "500m" == "0.5"
+	// Two plain numeric strings ("1.0" and "1", versions or IDs) are compared as strings
+	aStr, aIsStr := a.(string)
+	bStr, bIsStr := b.(string)
+	if aIsStr && bIsStr && !hasQuantitySuffix(aStr) && !hasQuantitySuffix(bStr) {
+		return false
+	}
+	aQty, aIsQty := toQuantity(a)
+	bQty, bIsQty := toQuantity(b)
+	if aIsQty && bIsQty {
+		return aQty.Cmp(bQty) == 0
+	}
+
+	return false
+}
+
+// toFloat converts finite numeric values and numeric strings to float64
+func toFloat(v interface{}) (float64, bool) {
+	f, ok := parseFloat(v)
+	if !ok || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, false
+	}
+	return f, true
+}
+
+// parseFloat converts numeric values and numeric strings to float64
+func parseFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// toBool converts booleans and the strings "true" and "false" to bool
+func toBool(v interface{}) (bool, bool) {
+	switch b := v.(type) {
+	case bool:
+		return b, true
+	case string:
+		switch strings.ToLower(strings.TrimSpace(b)) {
+		case "true":
+			return true, true
+		case "false":
+			return false, true
+		}
+	}
+	return false, false
+}
+
+// hasQuantitySuffix reports whether a string ends in a quantity suffix such as Gi, M or m
+func hasQuantitySuffix(s string) bool {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return false
+	}
+	last := s[len(s)-1]
+	return (last >= 'a' && last <= 'z') || (last >= 'A' && last <= 'Z')
+}
+
+// toQuantity parses a value as a Kubernetes resource.Quantity
+func toQuantity(v interface{}) (resource.Quantity, bool) {
+	var s string
+	switch q := v.(type) {
+	case string:
+		s = strings.TrimSpace(q)
+	default:
+		if f, ok := toFloat(v); ok {
+			s = strconv.FormatFloat(f, 'f', -1, 64)
+		} else {
+			return resource.Quantity{}, false
+		}
+	}
+
+	qty, err := resource.ParseQuantity(s)
+	if err != nil {
+		return resource.Quantity{}, false
+	}
+	return qty, true
 }
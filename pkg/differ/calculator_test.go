@@ -1,6 +1,7 @@
 package differ
 
 import (
+	"math"
 	"strings"
 	"testing"
 
@@ -155,3 +156,89 @@ func TestCalculator_SetSanitizer(t *testing.T) {
 		t.Error("SetSanitizer() did not set the correct sanitizer instance")
 	}
 }
+
+func TestCalculator_valuesEqual(t *testing.T) {
+	calc := &Calculator{}
+
+	tests := []struct {
+		name string
+		a    interface{}
+		b    interface{}
+		want bool
+	}{
+		{name: "identical strings", a: "us-east-1", b: "us-east-1", want: true},
+		{name: "different strings", a: "us-east-1", b: "us-west-2", want: false},
+		{name: "string vs int", a: "2", b: int64(2), want: true},
+		{name: "int vs float", a: int64(2), b: float64(2.0), want: true},
+		{name: "different numbers", a: int64(2), b: float64(2.5), want: false},
+		{name: "bool vs string", a: true, b: "true", want: true},
+		{name: "bool mismatch", a: false, b: "true", want: false},
+		{name: "binary vs decimal quantity", a: "1Gi", b: "1024Mi", want: true},
+		{name: "milli quantity", a: "500m", b: "0.5", want: true},
+		{name: "quantity mismatch", a: "1Gi", b: "1G", want: false},
+		{name: "number vs non-numeric string", a: int64(1), b: "one", want: false},
+		{name: "boolean-like strings", a: "t", b: "true", want: false},
+		{name: "numeric string vs bool", a: "1", b: true, want: false},
+		{name: "non-finite string vs float", a: "inf", b: math.Inf(1), want: false},
+		{name: "NaN strings", a: "NaN", b: "nan", want: false},
+		{name: "plain decimal strings", a: "1.0", b: "1", want: false},
+		{name: "plain exponent strings", a: "1e3", b: "1000", want: false},
+		{name: "suffixed vs plain string", a: "1k", b: "1000", want: true},
+		{
+			name: "nested map with coercion",
+			a:    map[string]interface{}{"size": "20", "tags": map[string]interface{}{"env": "prod"}},
+			b:    map[string]interface{}{"size": int64(20), "tags": map[string]interface{}{"env": "prod"}},
+			want: true,
+		},
+		{
+			name: "map with extra key",
+			a:    map[string]interface{}{"size": "20"},
+			b:    map[string]interface{}{"size": "20", "iops": int64(3000)},
+			want: false,
+		},
+		{
+			name: "slice with coercion",
+			a:    []interface{}{"1", "2"},
+			b:    []interface{}{int64(1), float64(2)},
+			want: true,
+		},
+		{
+			name: "slice length mismatch",
+			a:    []interface{}{"1"},
+			b:    []interface{}{"1", "2"},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := calc.valuesEqual(tt.a, tt.b); got != tt.want {
+				t.Errorf("valuesEqual(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculator_compareFields_IgnoresRepresentationDifferences(t *testing.T) {
+	calc := &Calculator{}
+
+	declared := map[string]interface{}{
+		"allocatedStorage": "20",
+		"memory":           "1Gi",
+		"instanceClass":    "db.t3.medium",
+	}
+	actual := map[string]interface{}{
+		"allocatedStorage": int64(20),
+		"memory":           "1024Mi",
+		"instanceClass":    "db.t3.large",
+	}
+
+	differences := calc.compareFields(declared, actual)
+
+	if len(differences) != 1 {
+		t.Fatalf("len(differences) = %d, want 1: %v", len(differences), differences)
+	}
+	if _, ok := differences["instanceClass"]; !ok {
+		t.Error("expected instanceClass to be reported as drift")
+	}
+}
@@ -0,0 +1,45 @@
+package differ
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/millstonehq/crossplane-plan/pkg/detector"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ProductionNameCollisions finds PR XRs that map to the same production XR: the same group, kind,
+// namespace and production name (baseName), e.g. "pr-123-app" and "pr-123-app-v2" both reduced to
+// "app" by a too greedy name pattern
+// Their diffs would compare against the same production XR, so each colliding XR gets an error
+// to report instead of its diff
+func ProductionNameCollisions(xrs []*unstructured.Unstructured, baseName func(*unstructured.Unstructured) string) map[*unstructured.Unstructured]error {
+	groups := make(map[string][]*unstructured.Unstructured)
+	names := make(map[string]string)
+	for _, xr := range xrs {
+		name := baseName(xr)
+		gvk := xr.GroupVersionKind()
+		key := declaredKey(gvk.Group, gvk.Kind, xr.GetNamespace(), name)
+		groups[key] = append(groups[key], xr)
+		names[key] = name
+	}
+
+	collisions := make(map[*unstructured.Unstructured]error)
+	for key, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+		previews := make([]string, 0, len(group))
+		for _, xr := range group {
+			previews = append(previews, xr.GetName())
+		}
+		sort.Strings(previews)
+		err := fmt.Errorf("production name collision: %s %s map to %q; fix the detection pattern or set the %s annotation",
+			group[0].GetKind(), strings.Join(previews, ", "), names[key], detector.ProductionNameAnnotation)
+		for _, xr := range group {
+			collisions[xr] = err
+		}
+	}
+	return collisions
+}
@@ -0,0 +1,51 @@
+package differ
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestProductionNameCollisions(t *testing.T) {
+	newXR := func(kind, namespace, name string) *unstructured.Unstructured {
+		xr := &unstructured.Unstructured{}
+		xr.SetAPIVersion("platform.example.com/v1alpha1")
+		xr.SetKind(kind)
+		xr.SetNamespace(namespace)
+		xr.SetName(name)
+		return xr
+	}
+	app := newXR("XApp", "previews", "pr-123-app")
+	appV2 := newXR("XApp", "previews", "pr-123-app-v2")
+	otherNamespace := newXR("XApp", "staging", "pr-123-app")
+	otherKind := newXR("XDatabase", "previews", "pr-123-app")
+	unique := newXR("XApp", "previews", "pr-123-api")
+
+	// A buggy pattern reducing everything starting with "app" to "app"
+	baseName := func(xr *unstructured.Unstructured) string {
+		name := strings.TrimPrefix(xr.GetName(), "pr-123-")
+		if strings.HasPrefix(name, "app") {
+			return "app"
+		}
+		return name
+	}
+
+	collisions := ProductionNameCollisions([]*unstructured.Unstructured{app, appV2, otherNamespace, otherKind, unique}, baseName)
+	if len(collisions) != 2 {
+		t.Fatalf("ProductionNameCollisions() = %v, want app and app-v2", collisions)
+	}
+	for _, xr := range []*unstructured.Unstructured{app, appV2} {
+		err := collisions[xr]
+		if err == nil {
+			t.Fatalf("%s: no collision", xr.GetName())
+		}
+		if want := `XApp pr-123-app, pr-123-app-v2 map to "app"`; !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: error = %v, want it to contain %q", xr.GetName(), err, want)
+		}
+	}
+
+	if collisions := ProductionNameCollisions([]*unstructured.Unstructured{app, unique}, baseName); len(collisions) != 0 {
+		t.Errorf("ProductionNameCollisions() = %v, want none", collisions)
+	}
+}
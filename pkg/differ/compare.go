@@ -0,0 +1,144 @@
+package differ
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// EngineResult is the diff of an XR by one engine
+type EngineResult struct {
+	Engine string
+	Diff   string
+	Err    error
+}
+
+// EngineComparison is the result of diffing an XR with several engines
+type EngineComparison struct {
+	// Resource is the XR's "Kind/name"
+	Resource string
+	Results  []EngineResult
+
+	// Discrepancies describe where the engines disagree about the XR itself
+	// Composed resources are only rendered by crossplane-diff, so they aren't compared
+	Discrepancies []string
+}
+
+// CompareEngines diffs an XR with each engine on the same sanitized input and reports
+// where their diffs of the XR disagree
+func (c *Calculator) CompareEngines(ctx context.Context, xr *unstructured.Unstructured, engines []string) (*EngineComparison, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	xrForDiff := xr
+	if c.sanitizer != nil {
+		xrForDiff = c.sanitizer.Sanitize(xr).SanitizedXR
+	}
+
+	e, err := c.acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize calculator: %w", err)
+	}
+
+	comparison := &EngineComparison{Resource: fmt.Sprintf("%s/%s", xr.GetKind(), xr.GetName())}
+	var lastErr error
+	for _, name := range engines {
+		diff, err := c.runEngine(ctx, e, name, xrForDiff)
+		comparison.Results = append(comparison.Results, EngineResult{Engine: name, Diff: diff, Err: err})
+		if err != nil {
+			lastErr = err
+		}
+	}
+	c.release(e, lastErr)
+
+	comparison.Discrepancies = compareResults(comparison.Resource, comparison.Results)
+	return comparison, nil
+}
+
+// compareResults compares the engines' diffs of a resource against the first engine's
+func compareResults(resource string, results []EngineResult) []string {
+	var discrepancies []string
+	var base *EngineResult
+	var baseLines []string
+	for i := range results {
+		result := &results[i]
+		if result.Err != nil {
+			discrepancies = append(discrepancies, fmt.Sprintf("%s failed: %v", result.Engine, result.Err))
+			continue
+		}
+		lines := changedLines(resourceSection(result.Diff, resource))
+		if base == nil {
+			base, baseLines = result, lines
+			continue
+		}
+
+		switch {
+		case len(baseLines) > 0 && len(lines) == 0:
+			discrepancies = append(discrepancies, fmt.Sprintf("%s reports changes, %s reports none", base.Engine, result.Engine))
+		case len(baseLines) == 0 && len(lines) > 0:
+			discrepancies = append(discrepancies, fmt.Sprintf("%s reports no changes, %s reports changes", base.Engine, result.Engine))
+		default:
+			for _, line := range missing(baseLines, lines) {
+				discrepancies = append(discrepancies, fmt.Sprintf("only %s: %s", base.Engine, line))
+			}
+			for _, line := range missing(lines, baseLines) {
+				discrepancies = append(discrepancies, fmt.Sprintf("only %s: %s", result.Engine, line))
+			}
+		}
+	}
+	return discrepancies
+}
+
+// resourceSection returns the lines of a resource's section in a rendered diff
+// Sections start with a "+++ ", "--- " or "~~~ " header and end with a "---" line
+func resourceSection(diff, resource string) []string {
+	var section []string
+	inSection := false
+	for _, line := range strings.Split(diff, "\n") {
+		if inSection {
+			if line == "---" {
+				break
+			}
+			section = append(section, line)
+			continue
+		}
+		for _, marker := range []string{"+++ ", "--- ", "~~~ "} {
+			if line == marker+resource {
+				inSection = true
+			}
+		}
+	}
+	return section
+}
+
+// changedLines returns the added and removed lines of a section, sorted and without trailing spaces
+func changedLines(section []string) []string {
+	var lines []string
+	for _, line := range section {
+		if strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-") {
+			lines = append(lines, strings.TrimRight(line, " "))
+		}
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+// missing returns the lines of a that aren't in b
+func missing(a, b []string) []string {
+	present := make(map[string]int, len(b))
+	for _, line := range b {
+		present[line]++
+	}
+	var out []string
+	for _, line := range a {
+		if present[line] > 0 {
+			present[line]--
+			continue
+		}
+		out = append(out, line)
+	}
+	return out
+}
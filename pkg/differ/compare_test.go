@@ -0,0 +1,77 @@
+package differ
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCompareResults(t *testing.T) {
+	crossplaneDiff := strings.Join([]string{
+		"~~~ XNetwork/vpc",
+		"  spec:",
+		"-   size: small",
+		"+   size: large",
+		"---",
+		"+++ Subnet/vpc-a",
+		"+ cidr: 10.0.0.0/24",
+		"---",
+	}, "\n")
+
+	tests := []struct {
+		name    string
+		results []EngineResult
+		want    []string
+	}{
+		{
+			name: "engines agree on the XR",
+			results: []EngineResult{
+				{Engine: EngineCrossplaneDiff, Diff: crossplaneDiff},
+				{Engine: EngineDryRun, Diff: "~~~ XNetwork/vpc\n  spec:\n+   size: large  \n-   size: small\n---\n"},
+			},
+		},
+		{
+			name: "different fields",
+			results: []EngineResult{
+				{Engine: EngineCrossplaneDiff, Diff: crossplaneDiff},
+				{Engine: EngineDryRun, Diff: "~~~ XNetwork/vpc\n-   size: small\n+   size: large\n+   zone: a\n---\n"},
+			},
+			want: []string{"only dry-run: +   zone: a"},
+		},
+		{
+			name: "no changes from one engine",
+			results: []EngineResult{
+				{Engine: EngineCrossplaneDiff, Diff: crossplaneDiff},
+				{Engine: EngineDryRun, Diff: ""},
+			},
+			want: []string{"crossplane-diff reports changes, dry-run reports none"},
+		},
+		{
+			name: "engine failure",
+			results: []EngineResult{
+				{Engine: EngineCrossplaneDiff, Diff: crossplaneDiff},
+				{Engine: EngineDryRun, Err: errors.New("dry-run apply failed")},
+			},
+			want: []string{"dry-run failed: dry-run apply failed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := compareResults("XNetwork/vpc", tt.results)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("compareResults() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResourceSection(t *testing.T) {
+	diff := "--- XNetwork/old\n- a: 1\n---\n~~~ XNetwork/vpc\n+ b: 2\n---\n"
+	if got := strings.Join(resourceSection(diff, "XNetwork/vpc"), "\n"); got != "+ b: 2" {
+		t.Errorf("resourceSection() = %q, want %q", got, "+ b: 2")
+	}
+	if got := resourceSection(diff, "XNetwork/missing"); got != nil {
+		t.Errorf("resourceSection() of a missing resource = %q, want nil", got)
+	}
+}
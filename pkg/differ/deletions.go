@@ -0,0 +1,64 @@
+package differ
+
+import (
+	"github.com/millstonehq/crossplane-plan/pkg/config"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Deletion policy severities
+const (
+	// PolicyInfo marks expected deletions, e.g. of ephemeral test resources
+	PolicyInfo = "info"
+	// PolicyWarn is the severity of deletions without a policy
+	PolicyWarn = "warn"
+	// PolicyBlock marks deletions that should never happen without review
+	PolicyBlock = "block"
+)
+
+// DeletionName identifies a deleted resource in a plan, as Kind[.group]/[namespace/]name
+// The API version is left out, since ArgoCD and the XRD may report the same resource at different versions
+// Names can't contain slashes, so a deletion never shares its name with an XR of the plan
+func DeletionName(gvk schema.GroupVersionKind, namespace, name string) string {
+	id := gvk.Kind
+	if gvk.Group != "" {
+		id += "." + gvk.Group
+	}
+	if namespace != "" {
+		id += "/" + namespace
+	}
+	return id + "/" + name
+}
+
+// AddDeletion adds a deleted resource to a plan, once per resource
+// When both the ArgoCD diff and legacy detection report a resource, the first report is kept and
+// completed with the XR and raw diff of the second
+func AddDeletion(items []PlanItem, gvk schema.GroupVersionKind, namespace, name string, result *DiffResult) []PlanItem {
+	id := DeletionName(gvk, namespace, name)
+	for _, item := range items {
+		if item.Action != ActionDelete || item.Name != id {
+			continue
+		}
+		if item.XR == nil {
+			item.XR = result.XR
+		}
+		if item.RawDiff == "" {
+			item.RawDiff = result.RawDiff
+		}
+		return items
+	}
+	return append(items, PlanItem{Name: id, Action: ActionDelete, GroupKind: gvk.GroupKind(), DiffResult: result})
+}
+
+// deletionPolicy returns the severity of the first policy matching a kind (PolicyWarn without one)
+func deletionPolicy(policies []config.DeletionPolicy, gk schema.GroupKind) string {
+	for _, policy := range policies {
+		if policy.APIGroup != "" && !matchesAPIGroup(policy.APIGroup, gk.Group) {
+			continue
+		}
+		if policy.Kind != "" && policy.Kind != gk.Kind {
+			continue
+		}
+		return policy.Severity
+	}
+	return PolicyWarn
+}
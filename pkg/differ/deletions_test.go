@@ -0,0 +1,68 @@
+package differ
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestDeletionName(t *testing.T) {
+	tests := []struct {
+		name      string
+		gvk       schema.GroupVersionKind
+		namespace string
+		want      string
+	}{
+		{
+			name: "cluster-scoped XR",
+			gvk:  schema.GroupVersionKind{Group: "example.org", Version: "v1alpha1", Kind: "XNetwork"},
+			want: "XNetwork.example.org/vpc",
+		},
+		{
+			name:      "namespaced core resource",
+			gvk:       schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+			namespace: "default",
+			want:      "ConfigMap/default/vpc",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DeletionName(tt.gvk, tt.namespace, "vpc"); got != tt.want {
+				t.Errorf("DeletionName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddDeletion(t *testing.T) {
+	var items []PlanItem
+	v1 := schema.GroupVersionKind{Group: "example.org", Version: "v1", Kind: "XNetwork"}
+	v1alpha1 := schema.GroupVersionKind{Group: "example.org", Version: "v1alpha1", Kind: "XNetwork"}
+
+	// ArgoCD reports the deletion first, with its diff but without the XR
+	items = AddDeletion(items, v1, "", "vpc", &DiffResult{HasChanges: true, Summary: "argocd", RawDiff: "- spec: {}"})
+
+	// Legacy detection reports the same resource at another version
+	xr := &unstructured.Unstructured{}
+	xr.SetName("vpc")
+	items = AddDeletion(items, v1alpha1, "", "vpc", &DiffResult{HasChanges: true, Summary: "legacy", RawDiff: "will be deleted", XR: xr})
+
+	// Same name, other kind: a separate deletion
+	items = AddDeletion(items, schema.GroupVersionKind{Group: "example.org", Kind: "XSubnet"}, "", "vpc", &DiffResult{HasChanges: true})
+
+	if len(items) != 2 {
+		t.Fatalf("AddDeletion() recorded %d items, want 2: %v", len(items), items)
+	}
+	got := items[0]
+	if got.Name != "XNetwork.example.org/vpc" || got.Action != ActionDelete || got.GroupKind != v1.GroupKind() {
+		t.Errorf("deletion item = %s %s %v", got.Name, got.Action, got.GroupKind)
+	}
+	if got.Summary != "argocd" || got.RawDiff != "- spec: {}" {
+		t.Errorf("first report not kept: %+v", got.DiffResult)
+	}
+	if got.XR != xr {
+		t.Error("XR of the second report not merged into the first")
+	}
+}
@@ -0,0 +1,157 @@
+package differ
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// declaredMatches reports whether the declared value is satisfied by the actual value
+// With defaulting-aware comparison, empty declarations accept whatever the provider
+// defaulted, and declared maps only need to be a subset of the actual map
+func (c *Calculator) declaredMatches(declared, actual interface{}) bool {
+	if !c.drift.DefaultingAware {
+		return c.valuesEqual(declared, actual)
+	}
+
+	if isEmptyValue(declared) {
+		return true
+	}
+
+	declaredMap, ok := declared.(map[string]interface{})
+	if !ok {
+		return c.valuesEqual(declared, actual)
+	}
+
+	actualMap, ok := actual.(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	for key, declaredValue := range declaredMap {
+		actualValue, exists := actualMap[key]
+		if !exists {
+			// Same rule as top-level fields: provider doesn't report it
+			continue
+		}
+		if !c.declaredMatches(declaredValue, actualValue) {
+			return false
+		}
+	}
+	return true
+}
+
+// compareNested records the differences between a declared and an actual map under prefix
+// depth is the nesting level of the maps' fields (1 for the top level of forProvider)
+// Without defaulting-aware comparison, fields only present in a nested actual map are drift too
+func (c *Calculator) compareNested(declared, actual map[string]interface{}, prefix string, depth int, differences map[string]FieldComparison) {
+	if depth > 1 && !c.drift.DefaultingAware {
+		for key, actualValue := range actual {
+			if _, declaredHas := declared[key]; !declaredHas {
+				path := joinFieldPath(prefix, key)
+				differences[path] = FieldComparison{Path: path, Actual: actualValue}
+			}
+		}
+	}
+
+	for key, declaredValue := range declared {
+		path := joinFieldPath(prefix, key)
+		actualValue, exists := actual[key]
+		if !exists {
+			// The provider doesn't report top-level fields it doesn't manage; nested ones are
+			// compared like the whole map was before, so strict comparison reports them
+			if depth > 1 && !c.drift.DefaultingAware {
+				differences[path] = FieldComparison{Path: path, Declared: declaredValue}
+			}
+			continue
+		}
+
+		declaredMap, declaredIsMap := declaredValue.(map[string]interface{})
+		actualMap, actualIsMap := actualValue.(map[string]interface{})
+		if declaredIsMap && actualIsMap && c.descends(depth) && !(c.drift.DefaultingAware && len(declaredMap) == 0) {
+			c.compareNested(declaredMap, actualMap, path, depth+1, differences)
+			continue
+		}
+
+		// Compare values (defaulting-aware when enabled)
+		if !c.declaredMatches(declaredValue, actualValue) {
+			differences[path] = FieldComparison{
+				Path:     path,
+				Declared: declaredValue,
+				Actual:   actualValue,
+			}
+		}
+	}
+}
+
+// descends reports whether maps at depth are compared field by field rather than as a whole
+func (c *Calculator) descends(depth int) bool {
+	return c.drift.MaxDepth <= 0 || depth < c.drift.MaxDepth
+}
+
+// joinFieldPath appends a field name to a dot-separated path
+func joinFieldPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// dropIgnoredDrift removes fields covered by a drift ignore rule for the given kind
+func (c *Calculator) dropIgnoredDrift(gvk schema.GroupVersionKind, differences map[string]FieldComparison) {
+	for _, rule := range c.drift.IgnoreFields {
+		if !matchesAPIGroup(rule.APIGroup, gvk.Group) {
+			continue
+		}
+		if rule.Kind != "" && rule.Kind != gvk.Kind {
+			continue
+		}
+		for path := range differences {
+			for _, field := range rule.Fields {
+				if matchesFieldPath(field, path) {
+					delete(differences, path)
+					break
+				}
+			}
+		}
+	}
+}
+
+// matchesFieldPath reports whether a dot-separated path is or lies under an ignored field
+// A "*" segment matches any single field name, e.g. "tags.*" or "rules.*.cidr"
+func matchesFieldPath(pattern, path string) bool {
+	patternSegments := strings.Split(pattern, ".")
+	pathSegments := strings.Split(path, ".")
+	if len(patternSegments) > len(pathSegments) {
+		return false
+	}
+	for i, segment := range patternSegments {
+		if segment != "*" && segment != pathSegments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesAPIGroup matches an API group against a pattern ("rds.aws.upbound.io" or "*.aws.upbound.io")
+func matchesAPIGroup(pattern, group string) bool {
+	if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+		return group == suffix || strings.HasSuffix(group, "."+suffix)
+	}
+	return pattern == group
+}
+
+// isEmptyValue reports whether a declared value is unset for defaulting purposes
+func isEmptyValue(v interface{}) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case string:
+		return val == ""
+	case map[string]interface{}:
+		return len(val) == 0
+	case []interface{}:
+		return len(val) == 0
+	}
+	return false
+}
@@ -0,0 +1,209 @@
+package differ
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/millstonehq/crossplane-plan/pkg/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestCalculator_declaredMatches_DefaultingAware(t *testing.T) {
+	calc := &Calculator{drift: config.DriftConfig{DefaultingAware: true}}
+
+	tests := []struct {
+		name     string
+		declared interface{}
+		actual   interface{}
+		want     bool
+	}{
+		{name: "empty string accepts default", declared: "", actual: "gp3", want: true},
+		{name: "nil accepts default", declared: nil, actual: int64(3000), want: true},
+		{name: "empty list accepts default", declared: []interface{}{}, actual: []interface{}{"a"}, want: true},
+		{name: "declared value still compared", declared: "gp2", actual: "gp3", want: false},
+		{
+			name:     "declared map is subset of actual",
+			declared: map[string]interface{}{"env": "prod"},
+			actual:   map[string]interface{}{"env": "prod", "managed-by": "crossplane"},
+			want:     true,
+		},
+		{
+			name:     "subset with changed value",
+			declared: map[string]interface{}{"env": "prod"},
+			actual:   map[string]interface{}{"env": "staging", "managed-by": "crossplane"},
+			want:     false,
+		},
+		{
+			name:     "map vs scalar",
+			declared: map[string]interface{}{"env": "prod"},
+			actual:   "prod",
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := calc.declaredMatches(tt.declared, tt.actual); got != tt.want {
+				t.Errorf("declaredMatches(%v, %v) = %v, want %v", tt.declared, tt.actual, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculator_declaredMatches_Strict(t *testing.T) {
+	calc := &Calculator{}
+
+	if calc.declaredMatches("", "gp3") {
+		t.Error("strict comparison should report empty declaration as drift")
+	}
+	if calc.declaredMatches(
+		map[string]interface{}{"env": "prod"},
+		map[string]interface{}{"env": "prod", "extra": "x"},
+	) {
+		t.Error("strict comparison should not treat subset maps as equal")
+	}
+}
+
+func TestMatchesAPIGroup(t *testing.T) {
+	tests := []struct {
+		pattern string
+		group   string
+		want    bool
+	}{
+		{"rds.aws.upbound.io", "rds.aws.upbound.io", true},
+		{"rds.aws.upbound.io", "ec2.aws.upbound.io", false},
+		{"*.aws.upbound.io", "ec2.aws.upbound.io", true},
+		{"*.aws.upbound.io", "aws.upbound.io", true},
+		{"*.aws.upbound.io", "gcp.upbound.io", false},
+		{"*.aws.upbound.io", "notaws.upbound.io", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchesAPIGroup(tt.pattern, tt.group); got != tt.want {
+			t.Errorf("matchesAPIGroup(%q, %q) = %v, want %v", tt.pattern, tt.group, got, tt.want)
+		}
+	}
+}
+
+func TestCalculator_analyzeManagedResource_IgnoreFields(t *testing.T) {
+	calc := &Calculator{drift: config.DriftConfig{
+		IgnoreFields: []config.DriftIgnoreRule{
+			{APIGroup: "*.aws.upbound.io", Fields: []string{"tagsAll"}},
+			{APIGroup: "rds.aws.upbound.io", Kind: "Cluster", Fields: []string{"engineVersion"}},
+		},
+	}}
+
+	mr := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "rds.aws.upbound.io/v1beta1",
+		"kind":       "Instance",
+		"metadata":   map[string]interface{}{"name": "db"},
+		"spec": map[string]interface{}{
+			"forProvider": map[string]interface{}{
+				"tagsAll":       map[string]interface{}{"env": "prod"},
+				"engineVersion": "15",
+			},
+		},
+		"status": map[string]interface{}{
+			"atProvider": map[string]interface{}{
+				"tagsAll":       map[string]interface{}{"env": "prod", "owner": "platform"},
+				"engineVersion": "15.4",
+			},
+		},
+	}}
+
+	state := calc.analyzeManagedResource(mr)
+
+	if _, ok := state.DeclaredVsActual["tagsAll"]; ok {
+		t.Error("tagsAll should be ignored for *.aws.upbound.io")
+	}
+	if _, ok := state.DeclaredVsActual["engineVersion"]; !ok {
+		t.Error("engineVersion rule is scoped to Cluster and should not apply to Instance")
+	}
+}
+
+func TestCalculator_compareFields_Nested(t *testing.T) {
+	declared := map[string]interface{}{
+		"region": "us-east-1",
+		"tags":   map[string]interface{}{"env": "prod", "team": "platform"},
+		"network": map[string]interface{}{
+			"subnet": map[string]interface{}{"cidr": "10.0.0.0/24"},
+		},
+	}
+	actual := map[string]interface{}{
+		"region": "us-east-1",
+		"tags":   map[string]interface{}{"env": "staging", "team": "platform", "managed-by": "crossplane"},
+		"network": map[string]interface{}{
+			"subnet": map[string]interface{}{"cidr": "10.0.1.0/24"},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		drift config.DriftConfig
+		want  []string
+	}{
+		{
+			name:  "defaulting-aware reports changed nested fields",
+			drift: config.DriftConfig{DefaultingAware: true},
+			want:  []string{"network.subnet.cidr", "tags.env"},
+		},
+		{
+			name:  "strict reports fields only set by the provider",
+			drift: config.DriftConfig{},
+			want:  []string{"network.subnet.cidr", "tags.env", "tags.managed-by"},
+		},
+		{
+			name:  "max depth compares deeper maps as a whole",
+			drift: config.DriftConfig{DefaultingAware: true, MaxDepth: 2},
+			want:  []string{"network.subnet", "tags.env"},
+		},
+		{
+			name:  "top-level only",
+			drift: config.DriftConfig{DefaultingAware: true, MaxDepth: 1},
+			want:  []string{"network", "tags"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calc := &Calculator{drift: tt.drift}
+			differences := calc.compareFields(declared, actual)
+
+			var got []string
+			for path, comparison := range differences {
+				if comparison.Path != path {
+					t.Errorf("differences[%q].Path = %q", path, comparison.Path)
+				}
+				got = append(got, path)
+			}
+			sort.Strings(got)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("compareFields() paths = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchesFieldPath(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"tagsAll", "tagsAll", true},
+		{"tagsAll", "tagsAll.env", true},
+		{"tags", "tagsAll", false},
+		{"tags.env", "tags.env", true},
+		{"tags.env", "tags", false},
+		{"tags.*", "tags.env", true},
+		{"ingress.*.description", "ingress.http.description", true},
+		{"ingress.*.description", "ingress.http.port", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchesFieldPath(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("matchesFieldPath(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package differ
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/crossplane-contrib/crossplane-diff/cmd/diff/renderer"
+	dt "github.com/crossplane-contrib/crossplane-diff/cmd/diff/renderer/types"
+	"github.com/millstonehq/crossplane-plan/pkg/config"
+	"github.com/millstonehq/crossplane-plan/pkg/metrics"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// EngineCrossplaneDiff renders the composition pipeline with crossplane-diff
+	EngineCrossplaneDiff = "crossplane-diff"
+
+	// EngineDryRun diffs the XR against a server-side dry-run apply
+	// Useful for kinds crossplane-diff can't render (e.g., compositions with unsupported functions)
+	EngineDryRun = "dry-run"
+)
+
+// SetEngineRules sets the per-kind diff engine selection
+func (c *Calculator) SetEngineRules(rules []config.EngineRule) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.engineRules = rules
+}
+
+// enginesFor returns the diff engines to try, in order, for an XR kind
+func (c *Calculator) enginesFor(gvk schema.GroupVersionKind) []string {
+	for _, rule := range c.engineRules {
+		if !matchesAPIGroup(rule.APIGroup, gvk.Group) {
+			continue
+		}
+		if rule.Kind != "" && rule.Kind != gvk.Kind {
+			continue
+		}
+		return rule.Engines
+	}
+	return []string{EngineCrossplaneDiff}
+}
+
+// runEngines diffs the XR with each selected engine until one succeeds
+func (c *Calculator) runEngines(ctx context.Context, e *engine, xr *unstructured.Unstructured) (string, error) {
+	var errs []error
+	for _, name := range c.enginesFor(xr.GroupVersionKind()) {
+		output, err := c.runEngine(ctx, e, name, xr)
+		if err == nil {
+			metrics.DiffEngineRuns.WithLabelValues(engineLabel(name)).Inc()
+			return output, nil
+		}
+
+		c.logger.Info("Diff engine failed, trying next engine", "engine", name, "xr", xr.GetName(), "error", err.Error())
+		errs = append(errs, fmt.Errorf("%s: %w", name, err))
+	}
+	return "", errors.Join(errs...)
+}
+
+// engineLabel is the metrics label of an engine; plugins share one label, as their names are user-defined
+func engineLabel(name string) string {
+	if strings.HasPrefix(name, PluginEnginePrefix) {
+		return "plugin"
+	}
+	return name
+}
+
+// runEngine diffs the XR with a single engine
+func (c *Calculator) runEngine(ctx context.Context, e *engine, name string, xr *unstructured.Unstructured) (string, error) {
+	switch name {
+	case EngineCrossplaneDiff:
+		// Use a buffer to capture diff output
+		var buf bytes.Buffer
+
+		// Perform diff - PerformDiff writes to io.Writer
+		resources := []*unstructured.Unstructured{xr}
+		err := e.processor.PerformDiff(ctx, &buf, resources, e.xpClients.Composition.FindMatchingComposition)
+		return buf.String(), err
+	case EngineDryRun:
+		return c.dryRunDiff(ctx, e, xr)
+	default:
+		if plugin, ok := strings.CutPrefix(name, PluginEnginePrefix); ok {
+			return c.runPlugin(ctx, plugin, xr)
+		}
+		return "", fmt.Errorf("unknown diff engine %q", name)
+	}
+}
+
+// dryRunDiff diffs the current XR against the result of a server-side dry-run apply
+// Only the XR itself is diffed; composed resources are not rendered
+func (c *Calculator) dryRunDiff(ctx context.Context, e *engine, xr *unstructured.Unstructured) (string, error) {
+	current, err := e.k8sClients.Resource.GetResource(ctx, xr.GroupVersionKind(), xr.GetNamespace(), xr.GetName())
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return "", fmt.Errorf("failed to get current resource: %w", err)
+		}
+		current = nil // New resource
+	}
+
+	desired, err := e.k8sClients.Apply.DryRunApply(ctx, xr)
+	if err != nil {
+		return "", fmt.Errorf("dry-run apply failed: %w", err)
+	}
+
+	return c.renderDiff(ctx, current, desired)
+}
+
+// renderDiff renders the diff between two versions of a resource (nil current means a new resource)
+// Returns an empty string if they are equal
+func (c *Calculator) renderDiff(ctx context.Context, current, desired *unstructured.Unstructured) (string, error) {
+	opts := renderer.DefaultDiffOptions()
+	opts.UseColors = false
+	diff, err := renderer.GenerateDiffWithOptions(ctx, current, desired, c.logger, opts)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate diff: %w", err)
+	}
+	if diff == nil || diff.DiffType == dt.DiffTypeEqual {
+		return "", nil
+	}
+
+	var buf bytes.Buffer
+	r := newNormalizingRendererFactory(func() *Normalizer { return c.normalizer })(c.logger, opts)
+	if err := r.RenderDiffs(&buf, map[string]*dt.ResourceDiff{diff.ResourceName: diff}); err != nil {
+		return "", fmt.Errorf("failed to render diff: %w", err)
+	}
+	return buf.String(), nil
+}
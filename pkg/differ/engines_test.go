@@ -0,0 +1,73 @@
+package differ
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/v2/pkg/logging"
+	"github.com/millstonehq/crossplane-plan/pkg/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestCalculator_EnginesFor(t *testing.T) {
+	calc := &Calculator{}
+	calc.SetEngineRules([]config.EngineRule{
+		{APIGroup: "platform.example.com", Kind: "XSecretStore", Engines: []string{EngineDryRun}},
+		{APIGroup: "*.example.com", Engines: []string{EngineCrossplaneDiff, EngineDryRun}},
+	})
+
+	tests := []struct {
+		name string
+		gvk  schema.GroupVersionKind
+		want []string
+	}{
+		{
+			name: "kind rule",
+			gvk:  schema.GroupVersionKind{Group: "platform.example.com", Version: "v1", Kind: "XSecretStore"},
+			want: []string{EngineDryRun},
+		},
+		{
+			name: "group wildcard with fallback",
+			gvk:  schema.GroupVersionKind{Group: "platform.example.com", Version: "v1", Kind: "XDatabase"},
+			want: []string{EngineCrossplaneDiff, EngineDryRun},
+		},
+		{
+			name: "no matching rule",
+			gvk:  schema.GroupVersionKind{Group: "other.io", Version: "v1", Kind: "XDatabase"},
+			want: []string{EngineCrossplaneDiff},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := calc.enginesFor(tt.gvk); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("enginesFor(%s) = %v, want %v", tt.gvk, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculator_RunEngines_ReportsEveryFailure(t *testing.T) {
+	calc := &Calculator{logger: logging.NewNopLogger()}
+	calc.SetEngineRules([]config.EngineRule{
+		{APIGroup: "example.com", Engines: []string{"first", "second"}},
+	})
+
+	xr := &unstructured.Unstructured{}
+	xr.SetAPIVersion("example.com/v1")
+	xr.SetKind("XApp")
+	xr.SetName("app")
+
+	_, err := calc.runEngines(context.Background(), &engine{}, xr)
+	if err == nil {
+		t.Fatal("runEngines() error = nil, want error")
+	}
+	for _, want := range []string{`first: unknown diff engine "first"`, `second: unknown diff engine "second"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("runEngines() error missing %q:\n%v", want, err)
+		}
+	}
+}
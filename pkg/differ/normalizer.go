@@ -0,0 +1,188 @@
+package differ
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/crossplane-contrib/crossplane-diff/cmd/diff/renderer"
+	dt "github.com/crossplane-contrib/crossplane-diff/cmd/diff/renderer/types"
+	"github.com/crossplane/crossplane-runtime/v2/pkg/logging"
+	"github.com/millstonehq/crossplane-plan/pkg/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// DecoderBase64 decodes standard (or URL-safe) base64 strings
+	DecoderBase64 = "base64"
+
+	// DecoderJSON canonicalizes JSON documents (sorted keys, stable indentation)
+	DecoderJSON = "json"
+
+	// DecoderYAML parses YAML documents and canonicalizes them as JSON
+	DecoderYAML = "yaml"
+)
+
+// Normalizer decodes encoded string fields so both sides of a diff are compared semantically
+type Normalizer struct {
+	rules []config.NormalizeRule
+}
+
+// NewNormalizer creates a new Normalizer with the given normalize rules
+func NewNormalizer(rules []config.NormalizeRule) *Normalizer {
+	return &Normalizer{
+		rules: rules,
+	}
+}
+
+// Normalize returns a copy of the object with all matching fields decoded
+// Rules targeting spec.forProvider are mirrored to status.atProvider so that
+// declared-vs-actual comparisons see the same representation on both sides
+func (n *Normalizer) Normalize(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	if obj == nil {
+		return nil
+	}
+
+	normalized := obj.DeepCopy()
+	for _, rule := range n.rules {
+		n.applyRule(normalized, rule.Path, rule.Decoders)
+
+		if rest, ok := strings.CutPrefix(rule.Path, "spec.forProvider."); ok {
+			n.applyRule(normalized, "status.atProvider."+rest, rule.Decoders)
+		}
+	}
+
+	return normalized
+}
+
+// applyRule decodes a single string field in place
+func (n *Normalizer) applyRule(obj *unstructured.Unstructured, path string, decoders []string) {
+	pathParts := strings.Split(path, ".")
+
+	value, found, err := unstructured.NestedString(obj.Object, pathParts...)
+	if err != nil || !found || value == "" {
+		return // Field doesn't exist or isn't a string, nothing to normalize
+	}
+
+	decoded, err := normalizeValue(value, decoders)
+	if err != nil {
+		return // Not encoded the way the rule expects, leave untouched
+	}
+
+	_ = unstructured.SetNestedField(obj.Object, decoded, pathParts...)
+}
+
+// normalizeValue runs a value through the decoder chain
+func normalizeValue(value string, decoders []string) (string, error) {
+	result := value
+	for _, decoder := range decoders {
+		var err error
+		switch decoder {
+		case DecoderBase64:
+			result, err = decodeBase64(result)
+		case DecoderJSON:
+			result, err = canonicalJSON([]byte(result))
+		case DecoderYAML:
+			var jsonBytes []byte
+			jsonBytes, err = yaml.YAMLToJSON([]byte(result))
+			if err == nil {
+				result, err = canonicalJSON(jsonBytes)
+			}
+		default:
+			err = fmt.Errorf("unknown decoder: %s", decoder)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return result, nil
+}
+
+// decodeBase64 decodes standard or URL-safe base64, with or without padding
+func decodeBase64(value string) (string, error) {
+	trimmed := strings.TrimSpace(value)
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if decoded, err := enc.DecodeString(trimmed); err == nil {
+			return string(decoded), nil
+		}
+	}
+	return "", fmt.Errorf("value is not valid base64")
+}
+
+// canonicalJSON re-marshals a JSON document with sorted keys and stable indentation
+func canonicalJSON(data []byte) (string, error) {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return "", fmt.Errorf("value is not valid JSON: %w", err)
+	}
+
+	// encoding/json sorts map keys, which removes key-ordering noise
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// normalizingRenderer re-generates crossplane-diff resource diffs from normalized
+// copies of the current and desired objects before handing them to the real renderer
+type normalizingRenderer struct {
+	next       renderer.DiffRenderer
+	normalizer func() *Normalizer // nil result disables normalization
+	logger     logging.Logger
+	opts       renderer.DiffOptions
+}
+
+// newNormalizingRendererFactory returns a DiffRenderer factory for diffprocessor.WithDiffRendererFactory
+func newNormalizingRendererFactory(normalizer func() *Normalizer) func(logging.Logger, renderer.DiffOptions) renderer.DiffRenderer {
+	return func(logger logging.Logger, opts renderer.DiffOptions) renderer.DiffRenderer {
+		return &normalizingRenderer{
+			next:       renderer.NewDiffRenderer(logger, opts),
+			normalizer: normalizer,
+			logger:     logger,
+			opts:       opts,
+		}
+	}
+}
+
+// RenderDiffs implements renderer.DiffRenderer
+func (r *normalizingRenderer) RenderDiffs(stdout io.Writer, diffs map[string]*dt.ResourceDiff) error {
+	normalizer := r.normalizer()
+	if normalizer == nil {
+		return r.next.RenderDiffs(stdout, diffs)
+	}
+
+	normalized := make(map[string]*dt.ResourceDiff, len(diffs))
+	for key, diff := range diffs {
+		normalized[key] = r.normalizeDiff(normalizer, diff)
+	}
+	return r.next.RenderDiffs(stdout, normalized)
+}
+
+// normalizeDiff regenerates a modification diff from normalized objects
+func (r *normalizingRenderer) normalizeDiff(normalizer *Normalizer, diff *dt.ResourceDiff) *dt.ResourceDiff {
+	// Additions and removals only have one side, nothing to reconcile
+	if diff == nil || diff.DiffType != dt.DiffTypeModified || diff.Current == nil || diff.Desired == nil {
+		return diff
+	}
+
+	regenerated, err := renderer.GenerateDiffWithOptions(
+		context.Background(),
+		normalizer.Normalize(diff.Current),
+		normalizer.Normalize(diff.Desired),
+		r.logger,
+		r.opts,
+	)
+	if err != nil {
+		r.logger.Debug("Failed to regenerate normalized diff, using original", "resource", diff.ResourceName, "error", err)
+		return diff
+	}
+
+	// Keep the display name crossplane-diff chose (e.g., "(generated)" suffixes)
+	regenerated.ResourceName = diff.ResourceName
+	return regenerated
+}
@@ -0,0 +1,175 @@
+package differ
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/crossplane-contrib/crossplane-diff/cmd/diff/renderer"
+	dt "github.com/crossplane-contrib/crossplane-diff/cmd/diff/renderer/types"
+	"github.com/crossplane/crossplane-runtime/v2/pkg/logging"
+	"github.com/millstonehq/crossplane-plan/pkg/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestNormalizeValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		decoders []string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "base64 only",
+			value:    base64.StdEncoding.EncodeToString([]byte("#!/bin/bash\necho hi")),
+			decoders: []string{"base64"},
+			want:     "#!/bin/bash\necho hi",
+		},
+		{
+			name:     "json sorts keys",
+			value:    `{"b":1,"a":{"d":2,"c":3}}`,
+			decoders: []string{"json"},
+			want:     "{\n  \"a\": {\n    \"c\": 3,\n    \"d\": 2\n  },\n  \"b\": 1\n}",
+		},
+		{
+			name:     "base64 then json",
+			value:    base64.StdEncoding.EncodeToString([]byte(`{"z":true,"a":false}`)),
+			decoders: []string{"base64", "json"},
+			want:     "{\n  \"a\": false,\n  \"z\": true\n}",
+		},
+		{
+			name:     "yaml canonicalized as json",
+			value:    "b: 1\na: two\n",
+			decoders: []string{"yaml"},
+			want:     "{\n  \"a\": \"two\",\n  \"b\": 1\n}",
+		},
+		{
+			name:     "invalid base64",
+			value:    "not base64!!",
+			decoders: []string{"base64"},
+			wantErr:  true,
+		},
+		{
+			name:     "invalid json",
+			value:    "{not json",
+			decoders: []string{"json"},
+			wantErr:  true,
+		},
+		{
+			name:     "unknown decoder",
+			value:    "anything",
+			decoders: []string{"rot13"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeValue(tt.value, tt.decoders)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizeValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("normalizeValue() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizer_Normalize(t *testing.T) {
+	normalizer := NewNormalizer([]config.NormalizeRule{
+		{Path: "spec.forProvider.policy", Decoders: []string{"json"}},
+	})
+
+	mr := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"forProvider": map[string]interface{}{
+				"policy": `{"Version":"2012-10-17","Statement":[]}`,
+			},
+		},
+		"status": map[string]interface{}{
+			"atProvider": map[string]interface{}{
+				"policy": `{"Statement":[],"Version":"2012-10-17"}`,
+			},
+		},
+	}}
+
+	normalized := normalizer.Normalize(mr)
+
+	declared, _, _ := unstructured.NestedString(normalized.Object, "spec", "forProvider", "policy")
+	actual, _, _ := unstructured.NestedString(normalized.Object, "status", "atProvider", "policy")
+	if declared != actual {
+		t.Errorf("declared and actual should match after normalization:\n%s\nvs\n%s", declared, actual)
+	}
+
+	// Original must not be modified
+	original, _, _ := unstructured.NestedString(mr.Object, "spec", "forProvider", "policy")
+	if original != `{"Version":"2012-10-17","Statement":[]}` {
+		t.Errorf("Normalize() modified the original object: %s", original)
+	}
+}
+
+func TestNormalizer_Normalize_LeavesInvalidValues(t *testing.T) {
+	normalizer := NewNormalizer([]config.NormalizeRule{
+		{Path: "spec.userData", Decoders: []string{"base64"}},
+		{Path: "spec.missing", Decoders: []string{"json"}},
+	})
+
+	xr := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"userData": "plain text, not encoded",
+		},
+	}}
+
+	normalized := normalizer.Normalize(xr)
+
+	got, _, _ := unstructured.NestedString(normalized.Object, "spec", "userData")
+	if got != "plain text, not encoded" {
+		t.Errorf("userData = %q, want unchanged value", got)
+	}
+	if _, found, _ := unstructured.NestedFieldNoCopy(normalized.Object, "spec", "missing"); found {
+		t.Error("Normalize() should not create missing fields")
+	}
+}
+
+func TestNormalizingRenderer_SuppressesEncodingNoise(t *testing.T) {
+	normalizer := NewNormalizer([]config.NormalizeRule{
+		{Path: "spec.config", Decoders: []string{"base64", "json"}},
+	})
+	logger := logging.NewNopLogger()
+	r := newNormalizingRendererFactory(func() *Normalizer { return normalizer })(logger, renderer.DefaultDiffOptions())
+
+	newObj := func(config string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "example.org/v1",
+			"kind":       "XApp",
+			"metadata":   map[string]interface{}{"name": "app"},
+			"spec":       map[string]interface{}{"config": config},
+		}}
+		return obj
+	}
+
+	current := newObj(base64.StdEncoding.EncodeToString([]byte(`{"a":1,"b":2}`)))
+	desired := newObj(base64.StdEncoding.EncodeToString([]byte(`{"b":2,"a":1}`)))
+
+	diffs := map[string]*dt.ResourceDiff{
+		"XApp/app": {
+			Gvk:          current.GroupVersionKind(),
+			ResourceName: "app",
+			DiffType:     dt.DiffTypeModified,
+			Current:      current,
+			Desired:      desired,
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := r.RenderDiffs(&buf, diffs); err != nil {
+		t.Fatalf("RenderDiffs() error = %v", err)
+	}
+
+	if strings.TrimSpace(buf.String()) != "" {
+		t.Errorf("expected no rendered diff for re-encoded value, got:\n%s", buf.String())
+	}
+}
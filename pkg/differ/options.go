@@ -0,0 +1,48 @@
+package differ
+
+import "github.com/millstonehq/crossplane-plan/pkg/config"
+
+// Option configures a Calculator when it is created
+// Each option has a setter of the same purpose for reconfiguring a running Calculator
+type Option func(*Calculator)
+
+// WithPoolSize sets how many diffs may run concurrently (default 1)
+func WithPoolSize(size int) Option {
+	return func(c *Calculator) { c.SetPoolSize(size) }
+}
+
+// WithSanitizer strips noise fields from diffs
+func WithSanitizer(sanitizer *Sanitizer) Option {
+	return func(c *Calculator) { c.SetSanitizer(sanitizer) }
+}
+
+// WithNormalizer decodes encoded string fields before diffing
+func WithNormalizer(normalizer *Normalizer) Option {
+	return func(c *Calculator) { c.SetNormalizer(normalizer) }
+}
+
+// WithDriftConfig sets the options for the declared-vs-actual comparison
+func WithDriftConfig(drift config.DriftConfig) Option {
+	return func(c *Calculator) { c.SetDriftConfig(drift) }
+}
+
+// WithEngineRules selects the diff engines per XR kind
+func WithEngineRules(rules []config.EngineRule) Option {
+	return func(c *Calculator) { c.SetEngineRules(rules) }
+}
+
+// WithPlugins sets the diff plugins available to engine rules
+func WithPlugins(plugins []config.DiffPlugin) Option {
+	return func(c *Calculator) { c.SetPlugins(plugins) }
+}
+
+// WithRecorder records the inputs of every diff into r
+func WithRecorder(r *Recorder) Option {
+	return func(c *Calculator) { c.SetRecorder(r) }
+}
+
+// WithConfig applies the diff settings of a configuration: field stripping, normalization,
+// drift comparison, plugins and engines
+func WithConfig(cfg *config.Config) Option {
+	return func(c *Calculator) { c.Configure(cfg) }
+}
@@ -0,0 +1,40 @@
+package differ
+
+import (
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/v2/pkg/logging"
+	"github.com/millstonehq/crossplane-plan/pkg/config"
+)
+
+func TestNewCalculator_Options(t *testing.T) {
+	calc := NewCalculator(nil, logging.NewNopLogger(),
+		WithPoolSize(3),
+		WithDriftConfig(config.DriftConfig{DefaultingAware: true}),
+		WithPlugins([]config.DiffPlugin{{Name: "terraform"}}),
+	)
+
+	if calc.poolSize != 3 {
+		t.Errorf("poolSize = %d, want 3", calc.poolSize)
+	}
+	if !calc.drift.DefaultingAware {
+		t.Error("drift not configured")
+	}
+	if _, ok := calc.plugins["terraform"]; !ok {
+		t.Errorf("plugins = %v, want terraform", calc.plugins)
+	}
+}
+
+func TestCalculator_Configure(t *testing.T) {
+	cfg := config.DefaultConfig()
+	calc := NewCalculator(nil, logging.NewNopLogger(), WithConfig(cfg))
+	if calc.sanitizer == nil {
+		t.Error("sanitizer = nil, want the default strip rules")
+	}
+
+	cfg.Diff.StripDefaults = false
+	calc.Configure(cfg)
+	if calc.sanitizer != nil {
+		t.Error("sanitizer kept after the default strip rules were disabled")
+	}
+}
@@ -0,0 +1,56 @@
+package differ
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// orderingAnnotations change when or whether ArgoCD applies a resource, without changing its spec
+// Helm hooks are honored by ArgoCD as well
+var orderingAnnotations = []struct {
+	name string
+	// unset is the value the annotation defaults to when it is absent
+	unset string
+}{
+	{name: "argocd.argoproj.io/sync-wave", unset: "0"},
+	{name: "argocd.argoproj.io/hook"},
+	{name: "argocd.argoproj.io/hook-delete-policy"},
+	{name: "argocd.argoproj.io/sync-options"},
+	{name: "helm.sh/hook"},
+	{name: "helm.sh/hook-weight", unset: "0"},
+	{name: "helm.sh/hook-delete-policy"},
+}
+
+// OrderingChange is a change to an annotation controlling apply ordering or hooks
+type OrderingChange struct {
+	// Annotation is the annotation's key, e.g. "argocd.argoproj.io/sync-wave"
+	Annotation string
+
+	// Before and After are the production and PR values (empty when unset)
+	Before string
+	After  string
+}
+
+// orderingChanges returns the ordering annotations the desired XR changes in current
+// The desired XR is read before sanitizing, so the changes show even if strip rules drop annotations
+func orderingChanges(desired, current *unstructured.Unstructured) []OrderingChange {
+	desiredAnnotations := desired.GetAnnotations()
+	currentAnnotations := current.GetAnnotations()
+
+	var changes []OrderingChange
+	for _, annotation := range orderingAnnotations {
+		before, after := currentAnnotations[annotation.name], desiredAnnotations[annotation.name]
+		if orDefault(before, annotation.unset) == orDefault(after, annotation.unset) {
+			continue
+		}
+		changes = append(changes, OrderingChange{Annotation: annotation.name, Before: before, After: after})
+	}
+	return changes
+}
+
+// orDefault returns value, or def when value is empty
+func orDefault(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
@@ -0,0 +1,55 @@
+package differ
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestOrderingChanges(t *testing.T) {
+	withAnnotations := func(annotations map[string]string) *unstructured.Unstructured {
+		xr := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		xr.SetAnnotations(annotations)
+		return xr
+	}
+
+	tests := []struct {
+		name    string
+		desired map[string]string
+		current map[string]string
+		want    []OrderingChange
+	}{
+		{
+			name:    "sync wave changed",
+			desired: map[string]string{"argocd.argoproj.io/sync-wave": "5"},
+			current: map[string]string{"argocd.argoproj.io/sync-wave": "2"},
+			want:    []OrderingChange{{Annotation: "argocd.argoproj.io/sync-wave", Before: "2", After: "5"}},
+		},
+		{
+			name:    "hook added",
+			desired: map[string]string{"argocd.argoproj.io/hook": "PreSync", "team": "a"},
+			current: map[string]string{"team": "b"},
+			want:    []OrderingChange{{Annotation: "argocd.argoproj.io/hook", Before: "", After: "PreSync"}},
+		},
+		{
+			name:    "default sync wave made explicit",
+			desired: map[string]string{"argocd.argoproj.io/sync-wave": "0"},
+			current: nil,
+		},
+		{
+			name:    "unchanged",
+			desired: map[string]string{"helm.sh/hook": "pre-install"},
+			current: map[string]string{"helm.sh/hook": "pre-install"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := orderingChanges(withAnnotations(tt.desired), withAnnotations(tt.current))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("orderingChanges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
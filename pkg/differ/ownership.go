@@ -0,0 +1,97 @@
+package differ
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// maxFieldOwners limits how many changed fields are attributed per XR
+const maxFieldOwners = 20
+
+// FieldOwner attributes a changed field to the field managers that own it in production
+type FieldOwner struct {
+	// Path is the dot-separated path of the changed field (e.g., "spec.parameters.size")
+	Path string
+
+	// Managers are the field managers owning the production value, e.g. "argocd-controller"
+	Managers []string
+}
+
+// attributeFields returns the owners of the spec fields the desired XR changes in current
+// Only fields set in desired are considered, so fields Crossplane populates (resourceRefs, ...) are ignored
+func attributeFields(desired, current *unstructured.Unstructured) []FieldOwner {
+	desiredSpec, _, _ := unstructured.NestedMap(desired.Object, "spec")
+	currentSpec, _, _ := unstructured.NestedMap(current.Object, "spec")
+
+	var paths [][]string
+	changedPaths(desiredSpec, currentSpec, []string{"spec"}, &paths)
+	sort.Slice(paths, func(i, j int) bool {
+		return strings.Join(paths[i], ".") < strings.Join(paths[j], ".")
+	})
+	if len(paths) > maxFieldOwners {
+		paths = paths[:maxFieldOwners]
+	}
+
+	managed := current.GetManagedFields()
+	fieldSets := make([]map[string]interface{}, len(managed))
+	for i, entry := range managed {
+		if entry.FieldsV1 != nil {
+			_ = json.Unmarshal(entry.FieldsV1.Raw, &fieldSets[i])
+		}
+	}
+
+	var owners []FieldOwner
+	for _, path := range paths {
+		owner := FieldOwner{Path: strings.Join(path, ".")}
+		for i, entry := range managed {
+			if ownsField(fieldSets[i], path) && !slices.Contains(owner.Managers, entry.Manager) {
+				owner.Managers = append(owner.Managers, entry.Manager)
+			}
+		}
+		owners = append(owners, owner)
+	}
+	return owners
+}
+
+// changedPaths collects the paths of leaf fields in desired whose value differs from current
+// Lists are compared as a whole
+func changedPaths(desired, current map[string]interface{}, prefix []string, paths *[][]string) {
+	for key, desiredValue := range desired {
+		path := append(append([]string(nil), prefix...), key)
+		currentValue, found := current[key]
+
+		desiredMap, desiredIsMap := desiredValue.(map[string]interface{})
+		currentMap, currentIsMap := currentValue.(map[string]interface{})
+		if desiredIsMap && (currentIsMap || !found) {
+			changedPaths(desiredMap, currentMap, path, paths)
+			continue
+		}
+
+		if !found || !reflect.DeepEqual(desiredValue, currentValue) {
+			*paths = append(*paths, path)
+		}
+	}
+}
+
+// ownsField reports whether a managedFields set (fieldsV1) contains a field
+// A manager owning a parent as a leaf (e.g., an atomic list) owns its children too
+func ownsField(fields map[string]interface{}, path []string) bool {
+	node := fields
+	for _, segment := range path {
+		child, ok := node["f:"+segment]
+		if !ok {
+			return false
+		}
+		childMap, _ := child.(map[string]interface{})
+		if len(childMap) == 0 {
+			return true
+		}
+		node = childMap
+	}
+	return true
+}
@@ -0,0 +1,82 @@
+package differ
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestAttributeFields(t *testing.T) {
+	desired := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"parameters": map[string]interface{}{
+				"size":   "large",
+				"region": "us-east-1",
+				"zones":  []interface{}{"a", "b"},
+				"tier":   "gold",
+			},
+		},
+	}}
+	current := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"parameters": map[string]interface{}{
+				"size":   "small",
+				"region": "us-east-1",
+				"zones":  []interface{}{"a"},
+			},
+			"resourceRefs": []interface{}{"populated-by-crossplane"},
+		},
+	}}
+	current.SetManagedFields([]metav1.ManagedFieldsEntry{
+		{
+			Manager:  "argocd-controller",
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:parameters":{"f:size":{},"f:region":{},"f:zones":{}}}}`)},
+		},
+		{
+			Manager:  "kubectl-edit",
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:parameters":{"f:size":{}}}}`)},
+		},
+	})
+
+	owners := attributeFields(desired, current)
+
+	var got []string
+	for _, owner := range owners {
+		got = append(got, owner.Path+"="+strings.Join(owner.Managers, ","))
+	}
+	want := []string{
+		"spec.parameters.size=argocd-controller,kubectl-edit",
+		"spec.parameters.tier=",
+		"spec.parameters.zones=argocd-controller",
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("attributeFields() = %v, want %v", got, want)
+	}
+}
+
+func TestOwnsField(t *testing.T) {
+	fields := map[string]interface{}{
+		"f:spec": map[string]interface{}{
+			"f:parameters": map[string]interface{}{
+				"f:tags": map[string]interface{}{},
+			},
+		},
+	}
+
+	tests := []struct {
+		path []string
+		want bool
+	}{
+		{[]string{"spec", "parameters", "tags"}, true},
+		{[]string{"spec", "parameters", "tags", "team"}, true}, // Owned as a whole
+		{[]string{"spec", "parameters", "size"}, false},
+		{[]string{"metadata", "labels"}, false},
+	}
+	for _, tt := range tests {
+		if got := ownsField(fields, tt.path); got != tt.want {
+			t.Errorf("ownsField(%v) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package differ
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/crossplane-contrib/crossplane-diff/cmd/diff/diffprocessor"
+	"github.com/crossplane/crossplane-runtime/v2/pkg/logging"
+	"github.com/crossplane/crossplane/v2/cmd/crank/render"
+)
+
+// PipelineCapture is what each step of a composition function pipeline produced for one composite
+type PipelineCapture struct {
+	// Composite identifies the rendered composite as Kind/name; nested XRs get their own capture
+	Composite   string         `json:"composite"`
+	Composition string         `json:"composition"`
+	Steps       []PipelineStep `json:"steps"`
+}
+
+// PipelineStep is the desired state after a pipeline step, i.e. the output of the pipeline
+// cut off after that step
+type PipelineStep struct {
+	Step     string `json:"step"`
+	Function string `json:"function"`
+
+	// Composite is the desired composite resource
+	Composite map[string]interface{} `json:"composite,omitempty"`
+
+	// Composed are the desired composed resources
+	Composed []map[string]interface{} `json:"composed,omitempty"`
+
+	// Results are the results (warnings, fatal errors, ...) the functions returned so far
+	Results []map[string]interface{} `json:"results,omitempty"`
+
+	// Error is why the pipeline failed at this step
+	Error string `json:"error,omitempty"`
+}
+
+// pipelineCapture collects the pipeline captures of one diff
+type pipelineCapture struct {
+	mu       sync.Mutex
+	captures map[string]PipelineCapture
+}
+
+type pipelineCaptureKey struct{}
+
+// WithPipelineCapture requests that diffs run with ctx capture the output of every step of
+// the composition function pipeline, in DiffResult.Pipeline
+// Every step re-runs the pipeline up to it, so this is meant for debugging single XRs
+func WithPipelineCapture(ctx context.Context) context.Context {
+	return context.WithValue(ctx, pipelineCaptureKey{}, &pipelineCapture{captures: make(map[string]PipelineCapture)})
+}
+
+// pipelineCaptureFrom returns the pipeline capture requested by ctx, if any
+func pipelineCaptureFrom(ctx context.Context) *pipelineCapture {
+	capture, _ := ctx.Value(pipelineCaptureKey{}).(*pipelineCapture)
+	return capture
+}
+
+// list returns the captures ordered by composite
+func (p *pipelineCapture) list() []PipelineCapture {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	captures := make([]PipelineCapture, 0, len(p.captures))
+	for _, capture := range p.captures {
+		captures = append(captures, capture)
+	}
+	sort.Slice(captures, func(i, j int) bool { return captures[i].Composite < captures[j].Composite })
+	return captures
+}
+
+// capturingRender wraps a render function to capture the pipeline steps of renders whose context
+// requests it
+// A composite rendered several times (e.g. while resolving required resources) keeps its last capture
+func capturingRender(next diffprocessor.RenderFunc) diffprocessor.RenderFunc {
+	return func(ctx context.Context, log logging.Logger, in render.Inputs) (render.Outputs, error) {
+		out, err := next(ctx, log, in)
+		if capture := pipelineCaptureFrom(ctx); capture != nil && in.Composition != nil && in.CompositeResource != nil {
+			captured := capturePipeline(ctx, log, next, in, out, err)
+			capture.mu.Lock()
+			capture.captures[captured.Composite] = captured
+			capture.mu.Unlock()
+		}
+		return out, err
+	}
+}
+
+// capturePipeline renders every prefix of the pipeline; the full pipeline's outputs are out and err
+// Steps after the first failing one are not run
+func capturePipeline(ctx context.Context, log logging.Logger, next diffprocessor.RenderFunc, in render.Inputs, out render.Outputs, err error) PipelineCapture {
+	xr := in.CompositeResource
+	captured := PipelineCapture{
+		Composite:   fmt.Sprintf("%s/%s", xr.GetKind(), xr.GetName()),
+		Composition: in.Composition.GetName(),
+	}
+
+	pipeline := in.Composition.Spec.Pipeline
+	for i, step := range pipeline {
+		stepOut, stepErr := out, err
+		if i < len(pipeline)-1 {
+			prefix := in
+			prefix.Composition = in.Composition.DeepCopy()
+			prefix.Composition.Spec.Pipeline = pipeline[:i+1]
+			stepOut, stepErr = next(ctx, log, prefix)
+		}
+
+		captured.Steps = append(captured.Steps, pipelineStep(step.Step, step.FunctionRef.Name, stepOut, stepErr))
+		if stepErr != nil {
+			break
+		}
+	}
+	return captured
+}
+
+// pipelineStep converts the outputs of a pipeline cut off after a step
+func pipelineStep(step, function string, out render.Outputs, err error) PipelineStep {
+	captured := PipelineStep{Step: step, Function: function}
+	if err != nil {
+		captured.Error = err.Error()
+	}
+	if out.CompositeResource != nil {
+		captured.Composite = out.CompositeResource.UnstructuredContent()
+	}
+	for _, composed := range out.ComposedResources {
+		captured.Composed = append(captured.Composed, composed.UnstructuredContent())
+	}
+	for _, result := range out.Results {
+		captured.Results = append(captured.Results, result.Object)
+	}
+	return captured
+}
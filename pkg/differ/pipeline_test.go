@@ -0,0 +1,83 @@
+package differ
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/v2/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/v2/pkg/resource/unstructured/composed"
+	ucomposite "github.com/crossplane/crossplane-runtime/v2/pkg/resource/unstructured/composite"
+	apiextensionsv1 "github.com/crossplane/crossplane/v2/apis/apiextensions/v1"
+	"github.com/crossplane/crossplane/v2/cmd/crank/render"
+)
+
+func TestCapturingRender(t *testing.T) {
+	xr := ucomposite.New()
+	xr.SetKind("XNetwork")
+	xr.SetName("network")
+
+	comp := &apiextensionsv1.Composition{}
+	comp.SetName("xnetworks")
+	for _, step := range []string{"resources", "auto-ready", "broken"} {
+		comp.Spec.Pipeline = append(comp.Spec.Pipeline, apiextensionsv1.PipelineStep{
+			Step:        step,
+			FunctionRef: apiextensionsv1.FunctionReference{Name: "function-" + step},
+		})
+	}
+
+	// Each step composes one resource; the third fails
+	renders := 0
+	fake := func(_ context.Context, _ logging.Logger, in render.Inputs) (render.Outputs, error) {
+		renders++
+		steps := len(in.Composition.Spec.Pipeline)
+		if steps == 3 {
+			return render.Outputs{}, errors.New("function-broken failed")
+		}
+		out := render.Outputs{CompositeResource: in.CompositeResource}
+		for i := 0; i < steps; i++ {
+			resource := composed.New()
+			resource.SetName(fmt.Sprintf("resource-%d", i))
+			out.ComposedResources = append(out.ComposedResources, *resource)
+		}
+		return out, nil
+	}
+	renderFunc := capturingRender(fake)
+
+	// Without a capture in the context only the full pipeline runs
+	if _, err := renderFunc(context.Background(), logging.NewNopLogger(), render.Inputs{CompositeResource: xr, Composition: comp}); err == nil {
+		t.Fatal("expected the full pipeline's error")
+	}
+	if renders != 1 {
+		t.Fatalf("renders = %d, want 1", renders)
+	}
+
+	ctx := WithPipelineCapture(context.Background())
+	if _, err := renderFunc(ctx, logging.NewNopLogger(), render.Inputs{CompositeResource: xr, Composition: comp}); err == nil {
+		t.Fatal("expected the full pipeline's error")
+	}
+	if len(comp.Spec.Pipeline) != 3 {
+		t.Fatal("capturing must not modify the composition")
+	}
+
+	captures := pipelineCaptureFrom(ctx).list()
+	if len(captures) != 1 {
+		t.Fatalf("captures = %d, want 1", len(captures))
+	}
+	capture := captures[0]
+	if capture.Composite != "XNetwork/network" || capture.Composition != "xnetworks" {
+		t.Errorf("capture = %s (%s)", capture.Composite, capture.Composition)
+	}
+	if len(capture.Steps) != 3 {
+		t.Fatalf("steps = %d, want 3", len(capture.Steps))
+	}
+	for i, step := range capture.Steps[:2] {
+		if len(step.Composed) != i+1 || step.Error != "" {
+			t.Errorf("step %s: %d composed resources, error %q", step.Step, len(step.Composed), step.Error)
+		}
+	}
+	if last := capture.Steps[2]; last.Function != "function-broken" || last.Error != "function-broken failed" {
+		t.Errorf("last step = %+v", last)
+	}
+}
@@ -0,0 +1,66 @@
+package differ
+
+import (
+	"sort"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Action is what merging a PR does to a resource
+type Action string
+
+// Plan item actions
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+	ActionNoOp   Action = "no-op"
+	ActionError  Action = "error"
+)
+
+// PlanItem is the planned change of one resource, as the watcher, the diff engines, ArgoCD
+// and the formatter exchange them
+type PlanItem struct {
+	// Name identifies the item in its plan: the PR XR's name, or Kind[.group]/[namespace/]name
+	// for deleted resources (see DeletionName)
+	Name string
+
+	// Action is what merging the PR does to the resource
+	Action Action
+
+	// GroupKind is the resource's group and kind, also set for deleted resources without an XR
+	GroupKind schema.GroupKind
+
+	*DiffResult
+}
+
+// NewPlanItem returns the plan item of an XR's diff result, with the action the result implies
+func NewPlanItem(name string, result *DiffResult) PlanItem {
+	item := PlanItem{Name: name, DiffResult: result}
+	if result.XR != nil {
+		item.GroupKind = result.XR.GroupVersionKind().GroupKind()
+	}
+	switch {
+	case result.Error != "":
+		item.Action = ActionError
+	case !result.HasChanges:
+		item.Action = ActionNoOp
+	case result.New:
+		item.Action = ActionCreate
+	default:
+		item.Action = ActionUpdate
+	}
+	return item
+}
+
+// Changed reports whether merging the PR creates, updates or deletes the resource
+func (i PlanItem) Changed() bool {
+	return i.Action == ActionCreate || i.Action == ActionUpdate || i.Action == ActionDelete
+}
+
+// SortItems sorts plan items by name, so their order is stable across plans
+func SortItems(items []PlanItem) {
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Name < items[j].Name
+	})
+}
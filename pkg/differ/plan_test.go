@@ -0,0 +1,38 @@
+package differ
+
+import (
+	"errors"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestNewPlanItem(t *testing.T) {
+	xr := previewXR("XNetwork", "pr-1-vpc", nil)
+
+	tests := []struct {
+		name   string
+		result *DiffResult
+		want   Action
+	}{
+		{name: "new XR", result: &DiffResult{XR: xr, HasChanges: true, New: true}, want: ActionCreate},
+		{name: "changed XR", result: &DiffResult{XR: xr, HasChanges: true}, want: ActionUpdate},
+		{name: "unchanged XR", result: &DiffResult{XR: xr}, want: ActionNoOp},
+		{name: "failed XR", result: FailedResult(xr, errors.New("composition not found")), want: ActionError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := NewPlanItem("pr-1-vpc", tt.result)
+			if item.Action != tt.want {
+				t.Errorf("Action = %q, want %q", item.Action, tt.want)
+			}
+			if item.GroupKind != (schema.GroupKind{Group: "example.org", Kind: "XNetwork"}) {
+				t.Errorf("GroupKind = %v", item.GroupKind)
+			}
+			if item.Changed() != (tt.want == ActionCreate || tt.want == ActionUpdate) {
+				t.Errorf("Changed() = %v", item.Changed())
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package differ
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/millstonehq/crossplane-plan/pkg/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// PluginEnginePrefix selects a diff plugin in engine rules ("plugin:<name>")
+	PluginEnginePrefix = "plugin:"
+
+	// defaultPluginTimeout limits a plugin run when the plugin doesn't set a timeout
+	defaultPluginTimeout = 30 * time.Second
+)
+
+// PluginResult is the JSON a diff plugin writes to stdout
+// Either Diff (pre-rendered unified diff lines) or Changes may be set; neither means no changes
+type PluginResult struct {
+	// Diff is a rendered diff, with "+" and "-" line prefixes
+	Diff string `json:"diff,omitempty"`
+
+	// Changes are field-level changes, rendered by crossplane-plan
+	Changes []PluginChange `json:"changes,omitempty"`
+}
+
+// PluginChange is a single field change reported by a diff plugin
+type PluginChange struct {
+	// Path is the changed field (e.g., "spec.parameters.size")
+	Path string `json:"path"`
+
+	// Old is the current value; omitted for added fields
+	Old interface{} `json:"old,omitempty"`
+
+	// New is the desired value; omitted for removed fields
+	New interface{} `json:"new,omitempty"`
+}
+
+// SetPlugins sets the diff plugins available to engine rules
+func (c *Calculator) SetPlugins(plugins []config.DiffPlugin) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.plugins = make(map[string]config.DiffPlugin, len(plugins))
+	for _, plugin := range plugins {
+		c.plugins[plugin.Name] = plugin
+	}
+}
+
+// runPlugin diffs the XR with an exec diff plugin
+func (c *Calculator) runPlugin(ctx context.Context, name string, xr *unstructured.Unstructured) (string, error) {
+	plugin, ok := c.plugins[name]
+	if !ok {
+		return "", fmt.Errorf("diff plugin %q is not configured", name)
+	}
+
+	timeout := defaultPluginTimeout
+	if plugin.Timeout != "" {
+		parsed, err := time.ParseDuration(plugin.Timeout)
+		if err != nil {
+			return "", fmt.Errorf("invalid timeout for diff plugin %q: %w", name, err)
+		}
+		timeout = parsed
+	}
+
+	input, err := json.Marshal(xr.Object)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode XR for diff plugin %q: %w", name, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, plugin.Command, plugin.Args...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = time.Second // Don't hang on output pipes held open by the plugin's children
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("diff plugin %q failed: %w: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var result PluginResult
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		return "", fmt.Errorf("diff plugin %q returned invalid JSON: %w", name, err)
+	}
+
+	return renderPluginResult(xr, result), nil
+}
+
+// renderPluginResult converts a plugin result to diff text
+func renderPluginResult(xr *unstructured.Unstructured, result PluginResult) string {
+	if result.Diff != "" || len(result.Changes) == 0 {
+		return result.Diff
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("~~~ %s/%s\n", xr.GetKind(), xr.GetName()))
+	for _, change := range result.Changes {
+		if change.Old != nil {
+			b.WriteString(fmt.Sprintf("- %s: %s\n", change.Path, renderPluginValue(change.Old)))
+		}
+		if change.New != nil {
+			b.WriteString(fmt.Sprintf("+ %s: %s\n", change.Path, renderPluginValue(change.New)))
+		}
+	}
+	return b.String()
+}
+
+// renderPluginValue renders a changed value on a single line
+func renderPluginValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(out)
+}
@@ -0,0 +1,87 @@
+package differ
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/millstonehq/crossplane-plan/pkg/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestCalculator_RunPlugin(t *testing.T) {
+	xr := &unstructured.Unstructured{}
+	xr.SetAPIVersion("example.com/v1")
+	xr.SetKind("XCluster")
+	xr.SetName("app")
+
+	tests := []struct {
+		name    string
+		script  string
+		timeout string
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "receives XR on stdin",
+			script: `grep -q '"name":"app"' && echo '{"diff": "+ spec.size: 3"}'`,
+			want:   "+ spec.size: 3",
+		},
+		{
+			name:   "structured changes",
+			script: `cat >/dev/null; echo '{"changes": [{"path": "spec.size", "old": 2, "new": 3}, {"path": "spec.tier", "new": "prod"}]}'`,
+			want:   "~~~ XCluster/app\n- spec.size: 2\n+ spec.size: 3\n+ spec.tier: prod\n",
+		},
+		{
+			name:   "no changes",
+			script: `cat >/dev/null; echo '{}'`,
+			want:   "",
+		},
+		{
+			name:    "non-zero exit",
+			script:  `cat >/dev/null; echo 'vcluster not reachable' >&2; exit 1`,
+			wantErr: "vcluster not reachable",
+		},
+		{
+			name:    "invalid output",
+			script:  `cat >/dev/null; echo 'not json'`,
+			wantErr: "invalid JSON",
+		},
+		{
+			name:    "timeout",
+			script:  `exec sleep 5`,
+			timeout: "50ms",
+			wantErr: "failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calc := &Calculator{}
+			calc.SetPlugins([]config.DiffPlugin{
+				{Name: "test", Command: "sh", Args: []string{"-c", tt.script}, Timeout: tt.timeout},
+			})
+
+			got, err := calc.runPlugin(context.Background(), "test", xr)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("runPlugin() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("runPlugin() error = %v", err)
+			}
+			if strings.TrimSpace(got) != strings.TrimSpace(tt.want) {
+				t.Errorf("runPlugin() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculator_RunPlugin_Unconfigured(t *testing.T) {
+	calc := &Calculator{}
+	if _, err := calc.runPlugin(context.Background(), "missing", &unstructured.Unstructured{}); err == nil {
+		t.Error("runPlugin() error = nil, want error for unconfigured plugin")
+	}
+}
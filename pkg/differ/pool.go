@@ -0,0 +1,87 @@
+package differ
+
+import (
+	"context"
+	"fmt"
+
+	xp "github.com/crossplane-contrib/crossplane-diff/cmd/diff/client/crossplane"
+	k8 "github.com/crossplane-contrib/crossplane-diff/cmd/diff/client/kubernetes"
+	"github.com/crossplane-contrib/crossplane-diff/cmd/diff/diffprocessor"
+	"github.com/millstonehq/crossplane-plan/pkg/metrics"
+)
+
+// maxEngineFailures is how many consecutive failed diffs an engine may have before it is recycled
+const maxEngineFailures = 3
+
+// engine is a crossplane-diff processor with its clients
+// The diff processor isn't safe for concurrent PerformDiff calls, so an engine serves one diff at a time
+type engine struct {
+	id         int
+	k8sClients k8.Clients
+	xpClients  xp.Clients
+	processor  diffprocessor.DiffProcessor
+	ready      bool // clients and processor are initialized
+	failures   int  // consecutive failed diffs
+}
+
+// SetPoolSize sets how many diffs may run concurrently, each on its own engine (default 1)
+// Must be called before the first diff
+func (c *Calculator) SetPoolSize(size int) {
+	c.poolSize = size
+}
+
+// initPool fills the pool with uninitialized engines
+func (c *Calculator) initPool() {
+	size := c.poolSize
+	if size < 1 {
+		size = 1
+	}
+
+	c.engines = make(chan *engine, size)
+	for i := 0; i < size; i++ {
+		c.engines <- &engine{id: i}
+	}
+}
+
+// acquire takes an idle engine, initializing it on first use
+// A failed initialization returns the engine to the pool uninitialized, so it is
+// retried by the next diff instead of poisoning every PR
+func (c *Calculator) acquire(ctx context.Context) (*engine, error) {
+	c.poolOnce.Do(c.initPool)
+
+	var e *engine
+	select {
+	case e = <-c.engines:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for a diff engine: %w", ctx.Err())
+	}
+
+	if !e.ready {
+		if err := c.initEngine(ctx, e); err != nil {
+			c.engines <- &engine{id: e.id}
+			return nil, err
+		}
+	}
+
+	return e, nil
+}
+
+// release returns an engine to the pool after a diff
+// Engines that failed maxEngineFailures diffs in a row are replaced by a fresh one
+func (c *Calculator) release(e *engine, diffErr error) {
+	if diffErr == nil {
+		e.failures = 0
+		c.engines <- e
+		return
+	}
+
+	e.failures++
+	if e.failures < maxEngineFailures {
+		c.engines <- e
+		return
+	}
+
+	c.logger.Info("Recycling diff engine after consecutive failures", "engine", e.id, "failures", e.failures, "lastError", diffErr.Error())
+	metrics.DiffEngineRecycles.Inc()
+	c.engines <- &engine{id: e.id}
+}
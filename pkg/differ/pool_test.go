@@ -0,0 +1,94 @@
+package differ
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/v2/pkg/logging"
+)
+
+// newReadyPool creates a Calculator whose engines skip initialization
+func newReadyPool(size int) *Calculator {
+	calc := &Calculator{logger: logging.NewNopLogger()}
+	calc.SetPoolSize(size)
+	calc.poolOnce.Do(calc.initPool)
+
+	engines := make([]*engine, 0, size)
+	for i := 0; i < size; i++ {
+		e := <-calc.engines
+		e.ready = true
+		engines = append(engines, e)
+	}
+	for _, e := range engines {
+		calc.engines <- e
+	}
+	return calc
+}
+
+func TestCalculator_PoolIsolatesConcurrentDiffs(t *testing.T) {
+	calc := newReadyPool(2)
+	ctx := context.Background()
+
+	first, err := calc.acquire(ctx)
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+	second, err := calc.acquire(ctx)
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+	if first == second {
+		t.Fatal("acquire() returned the same engine to two diffs")
+	}
+
+	// Pool exhausted: acquire waits until an engine is released
+	timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := calc.acquire(timeoutCtx); err == nil {
+		t.Error("acquire() on exhausted pool error = nil, want timeout")
+	}
+
+	calc.release(first, nil)
+	if got, err := calc.acquire(ctx); err != nil || got != first {
+		t.Errorf("acquire() = %v, %v, want released engine", got, err)
+	}
+}
+
+func TestCalculator_RecyclesFailingEngine(t *testing.T) {
+	calc := newReadyPool(1)
+	ctx := context.Background()
+	diffErr := errors.New("function pipeline failed")
+
+	original, _ := calc.acquire(ctx)
+	for i := 1; i < maxEngineFailures; i++ {
+		calc.release(original, diffErr)
+		if e, _ := calc.acquire(ctx); e != original {
+			t.Fatalf("engine recycled after %d failures, want %d", i, maxEngineFailures)
+		}
+	}
+
+	calc.release(original, diffErr)
+	recycled := <-calc.engines
+	if recycled == original || recycled.ready {
+		t.Error("release() should replace an engine with a fresh, uninitialized one after repeated failures")
+	}
+	if recycled.id != original.id {
+		t.Errorf("recycled engine id = %d, want %d", recycled.id, original.id)
+	}
+}
+
+func TestCalculator_SuccessResetsEngineFailures(t *testing.T) {
+	calc := newReadyPool(1)
+	ctx := context.Background()
+
+	e, _ := calc.acquire(ctx)
+	calc.release(e, errors.New("transient"))
+	e, _ = calc.acquire(ctx)
+	calc.release(e, nil)
+
+	if e.failures != 0 {
+		t.Errorf("failures = %d, want 0 after a successful diff", e.failures)
+	}
+}
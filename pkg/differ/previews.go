@@ -0,0 +1,159 @@
+package differ
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Preview comparison statuses
+const (
+	PreviewOnlyInPR      = "only-in-pr"
+	PreviewOnlyInAgainst = "only-in-against"
+	PreviewChanged       = "changed"
+)
+
+// FieldDifference is a spec field whose value differs between two previews
+type FieldDifference struct {
+	// Path is the dot-separated path of the field (e.g., "spec.parameters.size")
+	Path string
+
+	// PR and Against are the JSON values of the field in each preview, empty when unset
+	PR      string
+	Against string
+}
+
+// PreviewDifference is an XR that differs between two preview environments
+type PreviewDifference struct {
+	// Resource is the XR's "Kind/name", by its production name
+	Resource string
+	Status   string
+	Fields   []FieldDifference
+}
+
+// ComparePreviews compares the XRs of two preview environments, matching them by group, kind,
+// namespace and production name (baseName)
+// Only spec fields are compared, without the ones Crossplane populates, and the XRs' own names in
+// values are replaced by their production name, so PR-specific names don't count as differences
+func ComparePreviews(pr, against []*unstructured.Unstructured, baseName func(*unstructured.Unstructured) string) []PreviewDifference {
+	type preview struct {
+		resource string
+		spec     map[string]interface{}
+	}
+	index := func(xrs []*unstructured.Unstructured) map[string]preview {
+		previews := make(map[string]preview, len(xrs))
+		for _, xr := range xrs {
+			name := baseName(xr)
+			gvk := xr.GroupVersionKind()
+			previews[declaredKey(gvk.Group, gvk.Kind, xr.GetNamespace(), name)] = preview{
+				resource: fmt.Sprintf("%s/%s", xr.GetKind(), name),
+				spec:     comparableSpec(xr, name),
+			}
+		}
+		return previews
+	}
+	prPreviews, againstPreviews := index(pr), index(against)
+
+	var differences []PreviewDifference
+	for key, p := range prPreviews {
+		a, found := againstPreviews[key]
+		if !found {
+			differences = append(differences, PreviewDifference{Resource: p.resource, Status: PreviewOnlyInPR})
+			continue
+		}
+		if fields := fieldDifferences(p.spec, a.spec); len(fields) > 0 {
+			differences = append(differences, PreviewDifference{Resource: p.resource, Status: PreviewChanged, Fields: fields})
+		}
+	}
+	for key, a := range againstPreviews {
+		if _, found := prPreviews[key]; !found {
+			differences = append(differences, PreviewDifference{Resource: a.resource, Status: PreviewOnlyInAgainst})
+		}
+	}
+
+	sort.Slice(differences, func(i, j int) bool {
+		return differences[i].Resource < differences[j].Resource
+	})
+	return differences
+}
+
+// comparableSpec returns a copy of an XR's spec without populated fields, with its name replaced by baseName
+func comparableSpec(xr *unstructured.Unstructured, baseName string) map[string]interface{} {
+	xr = xr.DeepCopy()
+	for _, path := range populatedFields {
+		if path[0] == "spec" {
+			unstructured.RemoveNestedField(xr.Object, path...)
+		}
+	}
+	spec, _, _ := unstructured.NestedMap(xr.Object, "spec")
+	if xr.GetName() == baseName {
+		return spec
+	}
+	renamed, _ := replaceName(spec, xr.GetName(), baseName).(map[string]interface{})
+	return renamed
+}
+
+// replaceName replaces name by baseName in the string values of a JSON value
+func replaceName(value interface{}, name, baseName string) interface{} {
+	switch v := value.(type) {
+	case string:
+		return strings.ReplaceAll(v, name, baseName)
+	case map[string]interface{}:
+		for key, child := range v {
+			v[key] = replaceName(child, name, baseName)
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = replaceName(child, name, baseName)
+		}
+	}
+	return value
+}
+
+// fieldDifferences returns the leaf fields set in either spec whose values differ, sorted by path
+func fieldDifferences(pr, against map[string]interface{}) []FieldDifference {
+	var paths [][]string
+	changedPaths(pr, against, []string{"spec"}, &paths)
+	changedPaths(against, pr, []string{"spec"}, &paths)
+
+	seen := make(map[string]bool)
+	var fields []FieldDifference
+	for _, path := range paths {
+		joined := strings.Join(path, ".")
+		if seen[joined] {
+			continue
+		}
+		seen[joined] = true
+
+		prValue, prFound, _ := unstructured.NestedFieldNoCopy(pr, path[1:]...)
+		againstValue, againstFound, _ := unstructured.NestedFieldNoCopy(against, path[1:]...)
+		if prFound && againstFound && reflect.DeepEqual(prValue, againstValue) {
+			continue
+		}
+		fields = append(fields, FieldDifference{
+			Path:    joined,
+			PR:      jsonValue(prValue, prFound),
+			Against: jsonValue(againstValue, againstFound),
+		})
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Path < fields[j].Path
+	})
+	return fields
+}
+
+// jsonValue renders a field value as JSON, or "" when the field is unset
+func jsonValue(value interface{}, found bool) string {
+	if !found {
+		return ""
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
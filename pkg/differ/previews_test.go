@@ -0,0 +1,70 @@
+package differ
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func previewXR(kind, name string, spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.org/v1alpha1",
+		"kind":       kind,
+		"metadata":   map[string]interface{}{"name": name},
+		"spec":       spec,
+	}}
+}
+
+func TestComparePreviews(t *testing.T) {
+	baseName := func(xr *unstructured.Unstructured) string {
+		name := xr.GetName()
+		if i := strings.Index(name, "-"); strings.HasPrefix(name, "pr") && i > 0 {
+			return name[i+1:]
+		}
+		return name
+	}
+
+	pr := []*unstructured.Unstructured{
+		previewXR("XNetwork", "pr123-vpc", map[string]interface{}{
+			"parameters":                 map[string]interface{}{"cidr": "10.1.0.0/16", "region": "us-east-1"},
+			"writeConnectionSecretToRef": map[string]interface{}{"name": "pr123-vpc-conn"},
+			"resourceRefs":               []interface{}{"pr123-vpc-abcde"},
+		}),
+		previewXR("XDatabase", "pr123-db", map[string]interface{}{"size": "small"}),
+		previewXR("XBucket", "pr123-logs", map[string]interface{}{"versioning": true}),
+	}
+	against := []*unstructured.Unstructured{
+		previewXR("XNetwork", "pr456-vpc", map[string]interface{}{
+			"parameters":                 map[string]interface{}{"cidr": "10.0.0.0/16", "region": "us-east-1", "ipv6": true},
+			"writeConnectionSecretToRef": map[string]interface{}{"name": "pr456-vpc-conn"},
+			"resourceRefs":               []interface{}{"pr456-vpc-fghij"},
+		}),
+		previewXR("XDatabase", "pr456-db", map[string]interface{}{"size": "small"}),
+		previewXR("XCache", "pr456-sessions", map[string]interface{}{"nodes": int64(2)}),
+	}
+
+	differences := ComparePreviews(pr, against, baseName)
+
+	var got []string
+	for _, difference := range differences {
+		entry := difference.Status + " " + difference.Resource
+		for _, field := range difference.Fields {
+			entry += fmt.Sprintf(" %s=%s|%s", field.Path, field.PR, field.Against)
+		}
+		got = append(got, entry)
+	}
+	want := []string{
+		"only-in-pr XBucket/logs",
+		"only-in-against XCache/sessions",
+		`changed XNetwork/vpc spec.parameters.cidr="10.1.0.0/16"|"10.0.0.0/16" spec.parameters.ipv6=|true`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("ComparePreviews() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if differences := ComparePreviews(pr, pr, baseName); len(differences) != 0 {
+		t.Errorf("ComparePreviews() of identical previews = %v, want none", differences)
+	}
+}
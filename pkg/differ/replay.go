@@ -0,0 +1,151 @@
+package differ
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sync"
+
+	"github.com/crossplane/crossplane-runtime/v2/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/v2/pkg/resource/unstructured/composed"
+	ucomposite "github.com/crossplane/crossplane-runtime/v2/pkg/resource/unstructured/composite"
+	"github.com/crossplane/crossplane/v2/cmd/crank/render"
+	fnv1 "github.com/crossplane/crossplane/v2/proto/fn/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
+)
+
+// replayHost is the API server address replayed clients are configured with; it is never dialed
+const replayHost = "http://bundle.replay.invalid"
+
+// NewReplayCalculator creates a Calculator whose engines read the cluster and the composition
+// function outputs from a bundle instead of a live cluster
+func NewReplayCalculator(bundle *Bundle, logger logging.Logger) *Calculator {
+	replay := &replayer{bundle: bundle, requests: make(map[string][]RecordedRequest)}
+	for _, recorded := range bundle.Requests {
+		key := recorded.Method + " " + recorded.URI
+		replay.requests[key] = append(replay.requests[key], recorded)
+	}
+
+	c := NewCalculator(&rest.Config{Host: replayHost, Transport: replay}, logger)
+	c.renderFunc = replay.render
+	return c
+}
+
+// Resources returns copies of the bundle's recorded XRs
+func (b *Bundle) Resources() []*unstructured.Unstructured {
+	xrs := make([]*unstructured.Unstructured, 0, len(b.XRs))
+	for _, obj := range b.XRs {
+		xrs = append(xrs, (&unstructured.Unstructured{Object: obj}).DeepCopy())
+	}
+	return xrs
+}
+
+// replayer serves a bundle's recorded API responses and render outputs
+// Responses to a repeated request are served in recorded order, the last one repeating;
+// renders are served in call order
+type replayer struct {
+	bundle   *Bundle
+	mu       sync.Mutex
+	requests map[string][]RecordedRequest
+	renders  int
+}
+
+func (r *replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := req.Method + " " + req.URL.RequestURI()
+
+	r.mu.Lock()
+	responses := r.requests[key]
+	if len(responses) == 0 {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("request not recorded in bundle: %s", key)
+	}
+	recorded := responses[0]
+	if len(responses) > 1 {
+		r.requests[key] = responses[1:]
+	}
+	r.mu.Unlock()
+
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	header := make(http.Header)
+	if recorded.ContentType != "" {
+		header.Set("Content-Type", recorded.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.Status, http.StatusText(recorded.Status)),
+		StatusCode:    recorded.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}, nil
+}
+
+// render returns the next recorded render output
+func (r *replayer) render(_ context.Context, _ logging.Logger, in render.Inputs) (render.Outputs, error) {
+	r.mu.Lock()
+	if r.renders >= len(r.bundle.Renders) {
+		r.mu.Unlock()
+		return render.Outputs{}, fmt.Errorf("bundle has no recorded render left (%d recorded)", len(r.bundle.Renders))
+	}
+	recorded := r.bundle.Renders[r.renders]
+	r.renders++
+	r.mu.Unlock()
+
+	return replayRender(recorded, in)
+}
+
+// replayRender converts a recorded render back to render outputs
+func replayRender(recorded RecordedRender, in render.Inputs) (render.Outputs, error) {
+	var out render.Outputs
+	if recorded.Error != "" {
+		return out, fmt.Errorf("recorded render failed: %s", recorded.Error)
+	}
+
+	if recorded.CompositeResource != nil {
+		out.CompositeResource = ucomposite.New()
+		out.CompositeResource.SetUnstructuredContent(deepCopyObject(recorded.CompositeResource))
+		if in.CompositeResource != nil {
+			out.CompositeResource.Schema = in.CompositeResource.Schema
+		}
+	}
+	for _, obj := range recorded.ComposedResources {
+		out.ComposedResources = append(out.ComposedResources, composed.Unstructured{Unstructured: unstructured.Unstructured{Object: deepCopyObject(obj)}})
+	}
+	for _, obj := range recorded.Results {
+		out.Results = append(out.Results, unstructured.Unstructured{Object: deepCopyObject(obj)})
+	}
+	if recorded.Context != nil {
+		out.Context = &unstructured.Unstructured{Object: deepCopyObject(recorded.Context)}
+	}
+	if len(recorded.Requirements) > 0 {
+		out.Requirements = make(map[string]fnv1.Requirements, len(recorded.Requirements))
+		requirementsMap := reflect.ValueOf(out.Requirements) // See recordRender
+		for name, data := range recorded.Requirements {
+			requirements := &fnv1.Requirements{}
+			if err := protojson.Unmarshal(data, requirements); err != nil {
+				return out, fmt.Errorf("failed to decode requirements of %s: %w", name, err)
+			}
+			requirementsMap.SetMapIndex(reflect.ValueOf(name), reflect.ValueOf(requirements).Elem())
+		}
+	}
+	return out, nil
+}
+
+// deepCopyObject copies a recorded object so replays don't share state
+func deepCopyObject(obj map[string]interface{}) map[string]interface{} {
+	data, _ := json.Marshal(obj)
+	var copied map[string]interface{}
+	_ = json.Unmarshal(data, &copied)
+	return copied
+}
@@ -0,0 +1,60 @@
+package differ
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// ReadResources reads the XRs of a YAML or JSON file (several documents or a List) and clears
+// the server-populated metadata a plan clears before diffing
+func ReadResources(path string) ([]*unstructured.Unstructured, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open XR file: %w", err)
+	}
+	defer file.Close()
+
+	var xrs []*unstructured.Unstructured
+	decoder := utilyaml.NewYAMLOrJSONDecoder(file, 4096)
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to parse XR file: %w", err)
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+		if obj.IsList() {
+			if err := obj.EachListItem(func(item runtime.Object) error {
+				xrs = append(xrs, item.(*unstructured.Unstructured))
+				return nil
+			}); err != nil {
+				return nil, fmt.Errorf("failed to read XR list: %w", err)
+			}
+			continue
+		}
+		xrs = append(xrs, obj)
+	}
+	if len(xrs) == 0 {
+		return nil, fmt.Errorf("no XRs found in %s", path)
+	}
+
+	for _, xr := range xrs {
+		xr.SetUID("")
+		xr.SetResourceVersion("")
+		xr.SetGeneration(0)
+		xr.SetCreationTimestamp(metav1.Time{})
+		xr.SetManagedFields(nil)
+	}
+	return xrs, nil
+}
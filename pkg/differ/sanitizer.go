@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/millstonehq/crossplane-plan/pkg/config"
+	"github.com/millstonehq/crossplane-plan/pkg/metrics"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
@@ -16,6 +17,11 @@ type Sanitizer struct {
 
 // NewSanitizer creates a new Sanitizer with the given strip rules
 func NewSanitizer(rules []config.StripRule) *Sanitizer {
+	// Register every rule so rules that never fire are reported with zero hits
+	for _, rule := range rules {
+		metrics.StripRuleHits.WithLabelValues(rule.Path, rule.Reason)
+	}
+
 	return &Sanitizer{
 		rules: rules,
 	}
@@ -42,8 +48,13 @@ func (s *Sanitizer) Sanitize(xr *unstructured.Unstructured) *SanitizeResult {
 
 	// Apply each strip rule
 	for _, rule := range s.rules {
+		stripped := len(result.StrippedFields)
 		s.applyRule(sanitized, rule, result)
+		if len(result.StrippedFields) > stripped {
+			metrics.StripRuleHits.WithLabelValues(rule.Path, rule.Reason).Inc()
+		}
 	}
+	metrics.SanitizedXRs.Inc()
 
 	return result
 }
@@ -129,10 +140,26 @@ func (s *Sanitizer) valuesEqual(a, b interface{}) bool {
 		return true
 	}
 
+	// YAML decodes integers as int while unstructured objects hold int64
+	if isNumberKind(aVal.Kind()) && isNumberKind(bVal.Kind()) {
+		return aVal.Convert(reflect.TypeOf(float64(0))).Float() == bVal.Convert(reflect.TypeOf(float64(0))).Float()
+	}
+
 	// Fall back to deep equal for non-slice types
 	return reflect.DeepEqual(a, b)
 }
 
+// isNumberKind reports whether a reflect kind is an integer or float
+func isNumberKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
 // stripMatchingAnnotations strips annotations matching a pattern
 func (s *Sanitizer) stripMatchingAnnotations(xr *unstructured.Unstructured, rule config.StripRule, result *SanitizeResult) {
 	annotations := xr.GetAnnotations()
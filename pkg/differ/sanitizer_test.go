@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/millstonehq/crossplane-plan/pkg/config"
+	"github.com/millstonehq/crossplane-plan/pkg/metrics"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
@@ -359,6 +360,18 @@ func TestSanitizer_valuesEqual(t *testing.T) {
 			b:     43,
 			equal: false,
 		},
+		{
+			name:  "yaml int equals unstructured int64",
+			a:     int64(3),
+			b:     3,
+			equal: true,
+		},
+		{
+			name:  "number does not equal numeric string",
+			a:     int64(3),
+			b:     "3",
+			equal: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -437,3 +450,37 @@ func TestSanitizer_Sanitize_NoLabels(t *testing.T) {
 		t.Error("Nothing should be stripped when no labels exist")
 	}
 }
+
+func TestSanitizer_Sanitize_RecordsRuleHits(t *testing.T) {
+	metrics.StripRuleHits.Reset()
+	t.Cleanup(metrics.StripRuleHits.Reset)
+
+	rules := []config.StripRule{
+		{Path: "spec.managementPolicies", Equals: []interface{}{"Observe"}, Reason: "Read-only previews"},
+		{Path: "spec.unused", Equals: "never", Reason: "Dead rule"},
+	}
+	sanitizer := NewSanitizer(rules)
+
+	xr := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"managementPolicies": []interface{}{"Observe"},
+		},
+	}}
+	sanitizer.Sanitize(xr)
+	sanitizer.Sanitize(xr)
+
+	hits := make(map[string]int64)
+	for _, count := range metrics.StripRuleHitCounts() {
+		hits[count.Path] = count.Hits
+	}
+
+	if hits["spec.managementPolicies"] != 2 {
+		t.Errorf("spec.managementPolicies hits = %d, want 2", hits["spec.managementPolicies"])
+	}
+	if hits["spec.unused"] != 0 {
+		t.Errorf("spec.unused hits = %d, want 0", hits["spec.unused"])
+	}
+	if _, ok := hits["spec.unused"]; !ok {
+		t.Error("dead rule should be reported with zero hits")
+	}
+}
@@ -0,0 +1,47 @@
+package differ
+
+import (
+	"slices"
+
+	"github.com/millstonehq/crossplane-plan/pkg/config"
+	"github.com/millstonehq/crossplane-plan/pkg/severity"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// failedSummary is the summary of the results of XRs that could not be planned
+const failedSummary = "Failed to plan"
+
+// AssignSeverities rates the items of a plan
+// Deletions are rated by the first matching deletion policy (destructive without one), and
+// changes to protected kinds are destructive
+func AssignSeverities(items []PlanItem, policies []config.DeletionPolicy, protectedKinds []string) {
+	for _, item := range items {
+		item.Blocked = false
+		switch item.Action {
+		case ActionError:
+			item.Severity = severity.Error
+		case ActionDelete:
+			switch deletionPolicy(policies, item.GroupKind) {
+			case PolicyInfo:
+				item.Severity = severity.Info
+			case PolicyBlock:
+				item.Severity, item.Blocked = severity.Destructive, true
+			default:
+				item.Severity = severity.Destructive
+			}
+		case ActionNoOp:
+			item.Severity = severity.Info
+		default:
+			if slices.Contains(protectedKinds, item.GroupKind.Kind) {
+				item.Severity = severity.Destructive
+			} else {
+				item.Severity = severity.Change
+			}
+		}
+	}
+}
+
+// FailedResult is the result of an XR that could not be planned
+func FailedResult(xr *unstructured.Unstructured, err error) *DiffResult {
+	return &DiffResult{XR: xr, Summary: failedSummary, Error: err.Error(), Severity: severity.Error}
+}
@@ -0,0 +1,59 @@
+package differ
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/millstonehq/crossplane-plan/pkg/config"
+	"github.com/millstonehq/crossplane-plan/pkg/severity"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestAssignSeverities(t *testing.T) {
+	bucket := schema.GroupVersionKind{Group: "storage.example.com", Version: "v1", Kind: "XTestBucket"}
+	database := schema.GroupVersionKind{Group: "db.example.com", Version: "v1", Kind: "XDatabase"}
+	configMap := schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
+
+	items := []PlanItem{
+		NewPlanItem("pr-1-vpc", &DiffResult{HasChanges: true, XR: previewXR("XNetwork", "pr-1-vpc", nil)}),
+		NewPlanItem("pr-1-db", &DiffResult{HasChanges: true, XR: previewXR("XDatabase", "pr-1-db", nil)}),
+		NewPlanItem("pr-1-cache", &DiffResult{XR: previewXR("XCache", "pr-1-cache", nil)}),
+		NewPlanItem("pr-1-broken", FailedResult(previewXR("XQueue", "pr-1-broken", nil), errors.New("composition not found"))),
+	}
+	items = AddDeletion(items, bucket, "", "scratch", &DiffResult{HasChanges: true})
+	items = AddDeletion(items, database, "", "orders", &DiffResult{HasChanges: true})
+	items = AddDeletion(items, configMap, "default", "settings", &DiffResult{HasChanges: true})
+
+	AssignSeverities(items, []config.DeletionPolicy{
+		{APIGroup: "storage.example.com", Kind: "XTestBucket", Severity: PolicyInfo},
+		{APIGroup: "*.example.com", Kind: "XDatabase", Severity: PolicyBlock},
+		{APIGroup: "*.example.com", Severity: PolicyInfo},
+	}, []string{"XDatabase"})
+
+	tests := []struct {
+		key         string
+		want        severity.Level
+		wantBlocked bool
+	}{
+		{key: "pr-1-vpc", want: severity.Change},
+		{key: "pr-1-db", want: severity.Destructive},
+		{key: "pr-1-cache", want: severity.Info},
+		{key: "pr-1-broken", want: severity.Error},
+		{key: DeletionName(bucket, "", "scratch"), want: severity.Info},
+		{key: DeletionName(database, "", "orders"), want: severity.Destructive, wantBlocked: true},
+		{key: DeletionName(configMap, "default", "settings"), want: severity.Destructive},
+	}
+	byName := make(map[string]PlanItem, len(items))
+	for _, item := range items {
+		byName[item.Name] = item
+	}
+	for _, tt := range tests {
+		result := byName[tt.key]
+		if result.Severity != tt.want || result.Blocked != tt.wantBlocked {
+			t.Errorf("%s severity = %v (blocked %v), want %v (blocked %v)", tt.key, result.Severity, result.Blocked, tt.want, tt.wantBlocked)
+		}
+	}
+	if byName["pr-1-broken"].Error != "composition not found" {
+		t.Errorf("failed result error = %q", byName["pr-1-broken"].Error)
+	}
+}
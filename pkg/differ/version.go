@@ -0,0 +1,90 @@
+package differ
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"golang.org/x/mod/semver"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// MinCrossplaneVersion is the oldest Crossplane version the embedded crossplane-diff renders like
+	MinCrossplaneVersion = "v2.0.0"
+
+	// MaxCrossplaneVersion is the first Crossplane version the embedded crossplane-diff isn't built
+	// against; compositions may render differently from it on
+	MaxCrossplaneVersion = "v2.1.0"
+
+	// crossplaneSelector selects the deployment of the Crossplane Helm chart
+	crossplaneSelector = "app=crossplane"
+
+	// crossplaneVersionLabel holds the version on deployments with the recommended labels
+	crossplaneVersionLabel = "app.kubernetes.io/version"
+)
+
+// DetectCrossplaneVersion returns the version of the Crossplane deployment in namespace, from the
+// tag of its crossplane container image, or its version label when the tag isn't a version
+func DetectCrossplaneVersion(ctx context.Context, clientset kubernetes.Interface, namespace string) (string, error) {
+	deployments, err := clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{LabelSelector: crossplaneSelector})
+	if err != nil {
+		return "", fmt.Errorf("failed to list Crossplane deployments: %w", err)
+	}
+	for i := range deployments.Items {
+		if version := deploymentVersion(&deployments.Items[i]); version != "" {
+			return version, nil
+		}
+	}
+	return "", fmt.Errorf("no Crossplane deployment with a version found in namespace %s", namespace)
+}
+
+// deploymentVersion returns the Crossplane version a deployment runs, or "" if it can't tell
+func deploymentVersion(deployment *appsv1.Deployment) string {
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		if container.Name != "crossplane" && len(deployment.Spec.Template.Spec.Containers) > 1 {
+			continue
+		}
+		if version := canonicalVersion(imageTag(container.Image)); version != "" {
+			return version
+		}
+	}
+	return canonicalVersion(deployment.Labels[crossplaneVersionLabel])
+}
+
+// imageTag returns the tag of an image reference, without its digest
+func imageTag(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[i+1:]
+	}
+	return ""
+}
+
+// canonicalVersion returns version as a semantic version with a "v" prefix, or "" if it isn't one
+func canonicalVersion(version string) string {
+	if version != "" && !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	if !semver.IsValid(version) {
+		return ""
+	}
+	return version
+}
+
+// CrossplaneVersionSkew returns a warning if plans may be wrong for a Crossplane version,
+// i.e. if it is outside [MinCrossplaneVersion, MaxCrossplaneVersion); "" if it is supported
+func CrossplaneVersionSkew(version string) string {
+	// Pre-releases of a supported version render like it
+	release := semver.Canonical(version)
+	if pre := semver.Prerelease(release); pre != "" {
+		release = strings.TrimSuffix(release, pre)
+	}
+	if semver.Compare(release, MinCrossplaneVersion) >= 0 && semver.Compare(release, MaxCrossplaneVersion) < 0 {
+		return ""
+	}
+	return fmt.Sprintf("The cluster runs Crossplane %s, but plans are rendered for Crossplane %s to %s (exclusive); "+
+		"compositions may render differently, so this plan may be inaccurate.", version, MinCrossplaneVersion, MaxCrossplaneVersion)
+}
@@ -0,0 +1,89 @@
+package differ
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func crossplaneDeployment(name, image string, labels map[string]string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "crossplane-system", Labels: labels},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "crossplane", Image: image}},
+		}}},
+	}
+}
+
+func TestDetectCrossplaneVersion(t *testing.T) {
+	tests := []struct {
+		name       string
+		deployment *appsv1.Deployment
+		want       string
+		wantErr    bool
+	}{
+		{
+			name:       "image tag",
+			deployment: crossplaneDeployment("crossplane", "xpkg.crossplane.io/crossplane/crossplane:v2.0.2", map[string]string{"app": "crossplane"}),
+			want:       "v2.0.2",
+		},
+		{
+			name:       "image tag with registry port and digest",
+			deployment: crossplaneDeployment("crossplane", "registry:5000/crossplane/crossplane:1.20.0@sha256:abc", map[string]string{"app": "crossplane"}),
+			want:       "v1.20.0",
+		},
+		{
+			name: "version label when the tag isn't a version",
+			deployment: crossplaneDeployment("crossplane", "crossplane/crossplane:stable",
+				map[string]string{"app": "crossplane", "app.kubernetes.io/version": "2.1.0"}),
+			want: "v2.1.0",
+		},
+		{
+			name:       "not the Crossplane deployment",
+			deployment: crossplaneDeployment("crossplane-rbac-manager", "crossplane/crossplane:v2.0.2", map[string]string{"app": "crossplane-rbac-manager"}),
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset(tt.deployment)
+			got, err := DetectCrossplaneVersion(context.Background(), clientset, "crossplane-system")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DetectCrossplaneVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("DetectCrossplaneVersion() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCrossplaneVersionSkew(t *testing.T) {
+	tests := []struct {
+		version  string
+		wantSkew bool
+	}{
+		{"v2.0.0", false},
+		{"v2.0.2", false},
+		{"v2.0.0-rc.1", false},
+		{"v1.20.0", true},
+		{"v2.1.0", true},
+		{"v3.0.0", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			warning := CrossplaneVersionSkew(tt.version)
+			if (warning != "") != tt.wantSkew {
+				t.Errorf("CrossplaneVersionSkew(%s) = %q, want skew %v", tt.version, warning, tt.wantSkew)
+			}
+			if tt.wantSkew && !strings.Contains(warning, tt.version) {
+				t.Errorf("warning should name the cluster's version: %q", warning)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package errclass
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Class is the category of an error, used for retry decisions and metric labels
+type Class string
+
+// Error classes
+const (
+	Auth        Class = "auth"
+	RateLimited Class = "rate_limited"
+	DiffEngine  Class = "diff_engine"
+	NotFound    Class = "not_found"
+	Config      Class = "config"
+	Unknown     Class = "unknown"
+)
+
+// Sentinels marking an error's class; test with errors.Is
+var (
+	// ErrAuth indicates missing or rejected credentials or permissions
+	ErrAuth = errors.New("authentication failed")
+
+	// ErrRateLimited indicates an API throttled the request
+	ErrRateLimited = errors.New("rate limited")
+
+	// ErrDiffEngine indicates a diff engine failed to render or compare a resource
+	ErrDiffEngine = errors.New("diff engine failed")
+
+	// ErrNotFound indicates a resource, Application or comment doesn't exist
+	ErrNotFound = errors.New("not found")
+
+	// ErrConfig indicates invalid configuration
+	ErrConfig = errors.New("invalid configuration")
+)
+
+// classes maps sentinels to classes, in the order ClassOf checks them
+// Transient classes come first so an error joined from several failures is retried if any part may succeed
+var classes = []struct {
+	sentinel error
+	class    Class
+}{
+	{ErrRateLimited, RateLimited},
+	{ErrDiffEngine, DiffEngine},
+	{ErrAuth, Auth},
+	{ErrConfig, Config},
+	{ErrNotFound, NotFound},
+}
+
+// classified is an error marked with a class sentinel; its message is the wrapped error's
+type classified struct {
+	err      error
+	sentinel error
+}
+
+func (e *classified) Error() string   { return e.err.Error() }
+func (e *classified) Unwrap() []error { return []error{e.err, e.sentinel} }
+
+// Wrap marks err with a class sentinel, keeping its message and chain
+// Returns nil for a nil err
+func Wrap(sentinel, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &classified{err: err, sentinel: sentinel}
+}
+
+// ClassOf returns the class of err, or Unknown for unclassified errors
+func ClassOf(err error) Class {
+	for _, c := range classes {
+		if errors.Is(err, c.sentinel) {
+			return c.class
+		}
+	}
+	return Unknown
+}
+
+// Retryable reports whether retrying err later may succeed
+// Auth, config and not-found errors persist until something else changes
+func Retryable(err error) bool {
+	switch ClassOf(err) {
+	case Auth, Config, NotFound:
+		return false
+	default:
+		return true
+	}
+}
+
+// WrapStatus marks err with the class of an HTTP status code
+// 401 and 403 are auth errors, 404 not found and 429 rate limiting; other codes leave err unmarked
+func WrapStatus(code int, err error) error {
+	switch code {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return Wrap(ErrAuth, err)
+	case http.StatusNotFound:
+		return Wrap(ErrNotFound, err)
+	case http.StatusTooManyRequests:
+		return Wrap(ErrRateLimited, err)
+	default:
+		return err
+	}
+}
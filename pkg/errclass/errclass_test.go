@@ -0,0 +1,75 @@
+package errclass
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestClassOf(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		want          Class
+		wantRetryable bool
+	}{
+		{name: "unclassified", err: errors.New("boom"), want: Unknown, wantRetryable: true},
+		{name: "wrapped", err: Wrap(ErrAuth, errors.New("401 Bad credentials")), want: Auth},
+		{name: "wrapped twice with fmt", err: fmt.Errorf("post: %w", Wrap(ErrRateLimited, errors.New("403 rate limit"))), want: RateLimited, wantRetryable: true},
+		{name: "sentinel directly", err: fmt.Errorf("app x: %w", ErrNotFound), want: NotFound},
+		{name: "config", err: Wrap(ErrConfig, errors.New("bad repo")), want: Config},
+		{
+			name:          "joined prefers transient classes",
+			err:           errors.Join(Wrap(ErrAuth, errors.New("a")), Wrap(ErrDiffEngine, errors.New("b"))),
+			want:          DiffEngine,
+			wantRetryable: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassOf(tt.err); got != tt.want {
+				t.Errorf("ClassOf() = %q, want %q", got, tt.want)
+			}
+			if got := Retryable(tt.err); got != tt.wantRetryable {
+				t.Errorf("Retryable() = %v, want %v", got, tt.wantRetryable)
+			}
+		})
+	}
+}
+
+func TestWrap(t *testing.T) {
+	if Wrap(ErrAuth, nil) != nil {
+		t.Error("Wrap(nil) should be nil")
+	}
+
+	cause := errors.New("401 Bad credentials")
+	err := Wrap(ErrAuth, cause)
+	if err.Error() != cause.Error() {
+		t.Errorf("Error() = %q, want the wrapped message %q", err.Error(), cause.Error())
+	}
+	if !errors.Is(err, cause) || !errors.Is(err, ErrAuth) {
+		t.Error("Wrap() should match both the cause and the sentinel")
+	}
+}
+
+func TestWrapStatus(t *testing.T) {
+	tests := []struct {
+		code int
+		want Class
+	}{
+		{code: 401, want: Auth},
+		{code: 403, want: Auth},
+		{code: 404, want: NotFound},
+		{code: 429, want: RateLimited},
+		{code: 500, want: Unknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.code), func(t *testing.T) {
+			if got := ClassOf(WrapStatus(tt.code, errors.New("request failed"))); got != tt.want {
+				t.Errorf("ClassOf(WrapStatus(%d)) = %q, want %q", tt.code, got, tt.want)
+			}
+		})
+	}
+}
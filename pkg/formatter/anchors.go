@@ -0,0 +1,55 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+)
+
+// minTOCSections is how many resource sections a comment needs before it gets a table of contents
+const minTOCSections = 10
+
+// resourceAnchor returns the anchor of a resource's section in a comment, stable across plans
+// Deleted resources, named "Kind/name", get their own prefix so they can't collide with modified ones
+func resourceAnchor(name string, deleted bool) string {
+	prefix := "crossplane-plan-"
+	if deleted {
+		prefix += "deleted-"
+	}
+
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return prefix + strings.TrimSuffix(b.String(), "-")
+}
+
+// anchorTag returns the HTML anchor placed before a resource's section
+func anchorTag(name string, deleted bool) string {
+	return fmt.Sprintf("<a id=\"%s\"></a>\n\n", resourceAnchor(name, deleted))
+}
+
+// formatTableOfContents links to the section of every modified and deleted resource,
+// for comments with at least minTOCSections sections
+func formatTableOfContents(b *strings.Builder, modified, deleted []differ.PlanItem) {
+	if len(modified)+len(deleted) < minTOCSections {
+		return
+	}
+
+	b.WriteString(fmt.Sprintf("<details>\n<summary>📑 Contents (%d resources)</summary>\n\n", len(modified)+len(deleted)))
+	for _, item := range modified {
+		b.WriteString(fmt.Sprintf("- [`%s`](#%s): %s\n", item.Name, resourceAnchor(item.Name, false), item.Summary))
+	}
+	for _, item := range deleted {
+		b.WriteString(fmt.Sprintf("- [`%s`](#%s) (DELETION)\n", item.Name, resourceAnchor(item.Name, true)))
+	}
+	b.WriteString("\n</details>\n\n")
+}
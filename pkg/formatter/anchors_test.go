@@ -0,0 +1,55 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestResourceAnchor(t *testing.T) {
+	tests := []struct {
+		name    string
+		deleted bool
+		want    string
+	}{
+		{name: "pr-42-vpc", want: "crossplane-plan-pr-42-vpc"},
+		{name: "My_Bucket..Logs-", want: "crossplane-plan-my-bucket-logs"},
+		{name: "XCache/sessions", deleted: true, want: "crossplane-plan-deleted-xcache-sessions"},
+	}
+	for _, tt := range tests {
+		if got := resourceAnchor(tt.name, tt.deleted); got != tt.want {
+			t.Errorf("resourceAnchor(%q, %v) = %q, want %q", tt.name, tt.deleted, got, tt.want)
+		}
+	}
+}
+
+func TestGitHubFormatter_TableOfContents(t *testing.T) {
+	var items []differ.PlanItem
+	for i := 0; i < minTOCSections-1; i++ {
+		items = append(items, differ.NewPlanItem(fmt.Sprintf("pr-1-bucket-%d", i), &differ.DiffResult{HasChanges: true, Summary: "1 resource modified"}))
+	}
+
+	output := NewGitHubFormatter().FormatMultipleDiffs(items, nil)
+	if strings.Contains(output, "📑 Contents") {
+		t.Errorf("Table of contents in a small comment:\n%s", output)
+	}
+	if !strings.Contains(output, "<a id=\"crossplane-plan-pr-1-bucket-0\"></a>\n\n### `pr-1-bucket-0`") {
+		t.Errorf("Missing section anchor:\n%s", output)
+	}
+
+	items = differ.AddDeletion(items, schema.GroupVersionKind{Kind: "XCache"}, "", "sessions", &differ.DiffResult{HasChanges: true, Summary: "deleted"})
+	output = NewGitHubFormatter().FormatMultipleDiffs(items, nil)
+	for _, want := range []string{
+		"<summary>📑 Contents (10 resources)</summary>",
+		"- [`pr-1-bucket-0`](#crossplane-plan-pr-1-bucket-0): 1 resource modified\n- [`pr-1-bucket-1`]",
+		"- [`XCache/sessions`](#crossplane-plan-deleted-xcache-sessions) (DELETION)",
+		"<a id=\"crossplane-plan-deleted-xcache-sessions\"></a>\n\n### `XCache/sessions` (DELETION)",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Missing %q:\n%s", want, output)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+)
+
+// formatProductionDrift adds the drift between production as declared in Git and the live cluster,
+// kept apart from the PR's changes so reviewers aren't blamed for drift they didn't cause
+func (f *GitHubFormatter) formatProductionDrift(b *strings.Builder, items []differ.PlanItem) {
+	var drifted []differ.PlanItem
+	for _, item := range items {
+		if strings.TrimSpace(item.ProductionDrift) != "" {
+			drifted = append(drifted, item)
+		}
+	}
+	if len(drifted) == 0 {
+		return
+	}
+
+	b.WriteString("### 🌊 Pre-existing Drift in Production\n\n")
+	b.WriteString("These differences between Git and the live cluster already exist and are **not caused by this PR** (`-` declared in Git, `+` live):\n\n")
+	for _, item := range drifted {
+		b.WriteString("<details>\n")
+		b.WriteString(fmt.Sprintf("<summary>`%s`</summary>\n\n", item.Name))
+		b.WriteString("```diff\n")
+		b.WriteString(hideFields(item.ProductionDrift, f.hintsFor(item.XR).HideFields))
+		b.WriteString("\n```\n")
+		b.WriteString("</details>\n\n")
+	}
+}
@@ -0,0 +1,61 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestGitHubFormatter_ProductionDrift(t *testing.T) {
+	formatter := NewGitHubFormatter()
+
+	xr := &unstructured.Unstructured{}
+	xr.SetKind("XDatabase")
+	xr.SetName("orders")
+
+	tests := []struct {
+		name   string
+		result *differ.DiffResult
+	}{
+		{
+			name: "with changes",
+			result: &differ.DiffResult{
+				XR:              xr,
+				HasChanges:      true,
+				Summary:         "1 resource modified",
+				RawDiff:         "-     size: small\n+     size: large",
+				ProductionDrift: "-     tier: gold\n+     tier: silver",
+			},
+		},
+		{
+			name: "without changes",
+			result: &differ.DiffResult{
+				XR:              xr,
+				ProductionDrift: "-     tier: gold\n+     tier: silver",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, output := range []string{
+				formatter.FormatDiff(xr, tt.result),
+				formatter.FormatMultipleDiffs([]differ.PlanItem{
+					differ.NewPlanItem("orders", tt.result),
+					differ.NewPlanItem("vpc", &differ.DiffResult{}),
+				}, nil),
+			} {
+				if !strings.Contains(output, "Pre-existing Drift in Production") || !strings.Contains(output, "+     tier: silver") {
+					t.Errorf("Missing production drift:\n%s", output)
+				}
+			}
+		})
+	}
+
+	output := formatter.FormatDiff(xr, &differ.DiffResult{XR: xr, HasChanges: true, RawDiff: "+ x"})
+	if strings.Contains(output, "Pre-existing Drift") {
+		t.Errorf("Drift section rendered without drift:\n%s", output)
+	}
+}
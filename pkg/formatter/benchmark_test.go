@@ -0,0 +1,48 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// benchmarkItems returns n modified XRs with a 40-line diff each
+func benchmarkItems(n int) []differ.PlanItem {
+	var diff strings.Builder
+	diff.WriteString("~~~ XNetwork/vpc\n  spec:\n    parameters:\n")
+	for i := 0; i < 20; i++ {
+		fmt.Fprintf(&diff, "-     field%d: old\n+     field%d: new\n", i, i)
+	}
+
+	items := make([]differ.PlanItem, 0, n)
+	for i := 0; i < n; i++ {
+		xr := &unstructured.Unstructured{}
+		xr.SetAPIVersion("example.org/v1alpha1")
+		xr.SetKind("XNetwork")
+		xr.SetName(fmt.Sprintf("vpc-%d", i))
+		items = append(items, differ.NewPlanItem(xr.GetName(), &differ.DiffResult{
+			XR:         xr,
+			RawDiff:    diff.String(),
+			HasChanges: true,
+			Summary:    "1 resource modified",
+		}))
+	}
+	return items
+}
+
+func BenchmarkFormatMultipleDiffs(b *testing.B) {
+	for _, n := range []int{10, 100} {
+		b.Run(fmt.Sprintf("xrs=%d", n), func(b *testing.B) {
+			formatter := NewGitHubFormatter()
+			items := benchmarkItems(n)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				formatter.FormatMultipleDiffs(items, nil)
+			}
+		})
+	}
+}
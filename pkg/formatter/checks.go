@@ -0,0 +1,116 @@
+package formatter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+	"github.com/millstonehq/crossplane-plan/pkg/severity"
+	"github.com/millstonehq/crossplane-plan/pkg/vcs"
+)
+
+// maxCheckResources limits the resources listed in a check run summary
+const maxCheckResources = 50
+
+// PlanTitle summarizes a plan in one line, e.g. "2 changed, 1 deleted (destructive)"
+// It titles check runs and describes commit statuses
+func PlanTitle(entry DashboardEntry) string {
+	switch {
+	case entry.Failed > 0:
+		return fmt.Sprintf("%d changed, %d deleted, %d failed (%s)", entry.Changed, entry.Deletions, entry.Failed, entry.Severity())
+	case entry.Changed > 0 || entry.Deletions > 0:
+		return fmt.Sprintf("%d changed, %d deleted (%s)", entry.Changed, entry.Deletions, entry.Severity())
+	default:
+		return "No changes"
+	}
+}
+
+// FormatCheckSummary formats the concise summary and severity of a plan for a check run,
+// listing the resources it changes, deletes or fails to plan
+// With the URL of the PR comment, resources link to their section of the comment
+// Returns the check run's title and summary
+func (f *GitHubFormatter) FormatCheckSummary(entry DashboardEntry, items []differ.PlanItem, commentURL string) (title, summary string) {
+	title = PlanTitle(entry)
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("**Severity:** %s\n\n", severityBadges[entry.Severity()]))
+	b.WriteString(fmt.Sprintf("**Resources:** %d total, %d with changes, %d deletions, %d of protected kinds\n\n",
+		entry.Resources, entry.Changed, entry.Deletions, entry.Protected))
+
+	var rows []string
+	for _, item := range items {
+		switch item.Action {
+		case differ.ActionError:
+			rows = append(rows, fmt.Sprintf("| `%s` | ❌ %s |", item.Name, item.Summary))
+		case differ.ActionDelete:
+			rows = append(rows, fmt.Sprintf("| %s | 🗑️ %s%s |", commentLink(item.Name, commentURL, true), deletionBadge(item.DiffResult), item.Summary))
+		case differ.ActionCreate, differ.ActionUpdate:
+			label := commentLink(item.Name, commentURL, false)
+			if displayName := f.hintsFor(item.XR).DisplayName; displayName != "" {
+				label += " (" + displayName + ")"
+			}
+			rows = append(rows, fmt.Sprintf("| %s | %s |", label, item.Summary))
+		}
+	}
+	if len(rows) == 0 {
+		b.WriteString("This PR will not modify any infrastructure resources.\n")
+		return title, f.ApplyStyle(b.String())
+	}
+
+	if commentURL != "" {
+		b.WriteString(fmt.Sprintf("[View the full plan](%s)\n\n", commentURL))
+	}
+	sort.Strings(rows)
+	b.WriteString("| Resource | Change |\n|----------|--------|\n")
+	for i, row := range rows {
+		if i == maxCheckResources {
+			b.WriteString(fmt.Sprintf("| … | %d more |\n", len(rows)-maxCheckResources))
+			break
+		}
+		b.WriteString(row + "\n")
+	}
+	return title, f.ApplyStyle(b.String())
+}
+
+// commentLink returns a resource's name, linked to its section of the comment when its URL is known
+func commentLink(name, commentURL string, deleted bool) string {
+	if commentURL == "" {
+		return fmt.Sprintf("`%s`", name)
+	}
+	return fmt.Sprintf("[`%s`](%s#%s)", name, commentURL, resourceAnchor(name, deleted))
+}
+
+// FormatCheckAnnotations annotates the source files of the resources of a plan with their outcome:
+// a failure for resources that failed to plan, a warning for destructive changes and a notice
+// for other changes
+// Annotations point at the line declaring the resource when known, or else the file's first line
+func (f *GitHubFormatter) FormatCheckAnnotations(items []differ.PlanItem) []vcs.CheckAnnotation {
+	var annotations []vcs.CheckAnnotation
+	for _, item := range items {
+		if item.DiffResult == nil || item.Action == differ.ActionNoOp {
+			continue
+		}
+		level := vcs.AnnotationNotice
+		switch {
+		case item.Action == differ.ActionError:
+			level = vcs.AnnotationFailure
+		case item.Severity >= severity.Destructive:
+			level = vcs.AnnotationWarning
+		}
+		for _, source := range item.Sources {
+			line := source.Line
+			if line == 0 {
+				line = 1
+			}
+			annotations = append(annotations, vcs.CheckAnnotation{
+				Path:    source.Name,
+				Line:    line,
+				Level:   level,
+				Title:   fmt.Sprintf("%s: %s", item.Name, item.Action),
+				Message: item.Summary,
+			})
+		}
+	}
+	return annotations
+}
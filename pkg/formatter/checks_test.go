@@ -0,0 +1,135 @@
+package formatter
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+	"github.com/millstonehq/crossplane-plan/pkg/severity"
+	"github.com/millstonehq/crossplane-plan/pkg/vcs"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// cacheGVK is the kind of the deleted resources of the check summary tests
+var cacheGVK = schema.GroupVersionKind{Version: "v1alpha1", Kind: "XCache"}
+
+func TestGitHubFormatter_FormatCheckSummary(t *testing.T) {
+	xr := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.org/v1alpha1",
+		"kind":       "XNetwork",
+		"metadata":   map[string]interface{}{"name": "pr-1-vpc"},
+	}}
+	items := []differ.PlanItem{
+		differ.NewPlanItem("pr-1-vpc", &differ.DiffResult{XR: xr, HasChanges: true, Summary: "1 resource modified"}),
+		differ.NewPlanItem("pr-1-logs", &differ.DiffResult{XR: xr, HasChanges: false, Summary: "No changes"}),
+	}
+	items = differ.AddDeletion(items, cacheGVK, "", "sessions", &differ.DiffResult{HasChanges: true, Summary: "⚠️ XCache will be **DELETED**"})
+	entry := DashboardEntry{Resources: 3, Changed: 2, Deletions: 1}
+
+	title, summary := NewGitHubFormatter().FormatCheckSummary(entry, items, "")
+
+	if title != "2 changed, 1 deleted (destructive)" {
+		t.Errorf("title = %q", title)
+	}
+	for _, want := range []string{
+		"**Severity:** 🔴 destructive",
+		"**Resources:** 3 total, 2 with changes, 1 deletions, 0 of protected kinds",
+		"| `pr-1-vpc` | 1 resource modified |",
+		"| `XCache/sessions` | 🗑️ ⚠️ XCache will be **DELETED** |",
+	} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Missing %q:\n%s", want, summary)
+		}
+	}
+	if strings.Contains(summary, "pr-1-logs") {
+		t.Errorf("Unchanged resource listed:\n%s", summary)
+	}
+
+	title, summary = NewGitHubFormatter().FormatCheckSummary(DashboardEntry{Resources: 1}, []differ.PlanItem{
+		differ.NewPlanItem("pr-1-logs", &differ.DiffResult{XR: xr, Summary: "No changes"}),
+	}, "")
+	if title != "No changes" || !strings.Contains(summary, "will not modify any infrastructure resources") {
+		t.Errorf("FormatCheckSummary() without changes = %q, %q", title, summary)
+	}
+
+	title, summary = NewGitHubFormatter().FormatCheckSummary(DashboardEntry{Resources: 1, Failed: 1}, []differ.PlanItem{
+		differ.NewPlanItem("pr-1-queue", differ.FailedResult(xr, errors.New("composition not found"))),
+	}, "")
+	if title != "0 changed, 0 deleted, 1 failed (error)" || !strings.Contains(summary, "| `pr-1-queue` | ❌ Failed to plan |") {
+		t.Errorf("FormatCheckSummary() of a failure = %q, %q", title, summary)
+	}
+}
+
+func TestGitHubFormatter_FormatCheckSummary_Links(t *testing.T) {
+	items := []differ.PlanItem{
+		differ.NewPlanItem("pr-1-vpc", &differ.DiffResult{HasChanges: true, Summary: "1 resource modified"}),
+	}
+	items = differ.AddDeletion(items, cacheGVK, "", "sessions", &differ.DiffResult{HasChanges: true, Summary: "deleted"})
+	url := "https://github.com/acme/infra/pull/1#issuecomment-9"
+
+	_, summary := NewGitHubFormatter().FormatCheckSummary(DashboardEntry{Resources: 2, Changed: 2, Deletions: 1}, items, url)
+
+	for _, want := range []string{
+		"[View the full plan](" + url + ")",
+		"| [`pr-1-vpc`](" + url + "#crossplane-plan-pr-1-vpc) |",
+		"| [`XCache/sessions`](" + url + "#crossplane-plan-deleted-xcache-sessions) |",
+	} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Missing %q:\n%s", want, summary)
+		}
+	}
+}
+
+func TestGitHubFormatter_FormatCheckAnnotations(t *testing.T) {
+	xr := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.org/v1alpha1",
+		"kind":       "XNetwork",
+		"metadata":   map[string]interface{}{"name": "pr-1-vpc"},
+	}}
+	source := []differ.Link{{Name: "apps/network.yaml", URL: "https://github.com/acme/infra/pull/1/files#diff-1", Line: 12}}
+	protected := &differ.DiffResult{XR: xr, HasChanges: true, Summary: "1 resource replaced", Sources: []differ.Link{{Name: "apps/db.yaml"}}}
+	protected.Severity = severity.Destructive
+	failed := differ.FailedResult(xr, errors.New("composition not found"))
+	failed.Sources = source
+
+	tests := []struct {
+		name string
+		item differ.PlanItem
+		want []vcs.CheckAnnotation
+	}{
+		{
+			name: "change",
+			item: differ.NewPlanItem("pr-1-vpc", &differ.DiffResult{XR: xr, HasChanges: true, Summary: "1 resource modified", Sources: source}),
+			want: []vcs.CheckAnnotation{{Path: "apps/network.yaml", Line: 12, Level: vcs.AnnotationNotice, Title: "pr-1-vpc: update", Message: "1 resource modified"}},
+		},
+		{
+			name: "destructive change without a known line",
+			item: differ.NewPlanItem("pr-1-db", protected),
+			want: []vcs.CheckAnnotation{{Path: "apps/db.yaml", Line: 1, Level: vcs.AnnotationWarning, Title: "pr-1-db: update", Message: "1 resource replaced"}},
+		},
+		{
+			name: "failure",
+			item: differ.NewPlanItem("pr-1-queue", failed),
+			want: []vcs.CheckAnnotation{{Path: "apps/network.yaml", Line: 12, Level: vcs.AnnotationFailure, Title: "pr-1-queue: error", Message: "Failed to plan"}},
+		},
+		{
+			name: "no changes",
+			item: differ.NewPlanItem("pr-1-logs", &differ.DiffResult{XR: xr, Summary: "No changes", Sources: source}),
+		},
+		{
+			name: "no sources",
+			item: differ.NewPlanItem("pr-1-cache", &differ.DiffResult{XR: xr, HasChanges: true, Summary: "1 resource modified"}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewGitHubFormatter().FormatCheckAnnotations([]differ.PlanItem{tt.item})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FormatCheckAnnotations() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
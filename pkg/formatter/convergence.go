@@ -0,0 +1,28 @@
+package formatter
+
+import (
+	"fmt"
+	"time"
+)
+
+// FormatConvergenceNotice formats the notice estimating how long a PR's resources take to
+// become Ready after merge, from the past time-to-Ready of their slowest kind
+func (f *GitHubFormatter) FormatConvergenceNotice(estimate time.Duration, slowestKind string) string {
+	return f.ApplyStyle(fmt.Sprintf("> [!NOTE]\n> ⏱️ Estimated time to converge after merge: **%s** (slowest: `%s`, from past time-to-Ready).\n\n",
+		formatApproxDuration(estimate), slowestKind))
+}
+
+// formatApproxDuration rounds a duration to the minute, e.g. "~12m" or "~1h 20m"
+func formatApproxDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	switch {
+	case d < time.Minute:
+		return "<1m"
+	case d < time.Hour:
+		return fmt.Sprintf("~%dm", int(d.Minutes()))
+	case d%time.Hour == 0:
+		return fmt.Sprintf("~%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("~%dh %dm", int(d.Hours()), int((d % time.Hour).Minutes()))
+	}
+}
@@ -0,0 +1,34 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatApproxDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{d: 20 * time.Second, want: "<1m"},
+		{d: 11*time.Minute + 40*time.Second, want: "~12m"},
+		{d: time.Hour, want: "~1h"},
+		{d: 80 * time.Minute, want: "~1h 20m"},
+	}
+	for _, tt := range tests {
+		if got := formatApproxDuration(tt.d); got != tt.want {
+			t.Errorf("formatApproxDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestGitHubFormatter_FormatConvergenceNotice(t *testing.T) {
+	output := NewGitHubFormatter().FormatConvergenceNotice(12*time.Minute, "XDatabase.example.org")
+	if !strings.Contains(output, "Estimated time to converge after merge: **~12m** (slowest: `XDatabase.example.org`") {
+		t.Errorf("Missing estimate:\n%s", output)
+	}
+	if !strings.HasSuffix(output, "\n\n") {
+		t.Error("Notice should be separated from the comment by a blank line")
+	}
+}
@@ -0,0 +1,96 @@
+package formatter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/millstonehq/crossplane-plan/pkg/severity"
+)
+
+// DashboardEntry summarizes the latest plan of an open PR
+type DashboardEntry struct {
+	Repository string
+	PRNumber   int
+	Resources  int
+	Changed    int
+	Deletions  int
+	// Protected counts the changed resources of protected kinds
+	Protected int
+	// Failed counts the XRs that could not be planned
+	Failed int
+}
+
+// Severity rates a plan by its most severe result: failures are errors, deletions and
+// protected kinds destructive
+func (e DashboardEntry) Severity() severity.Level {
+	switch {
+	case e.Failed > 0:
+		return severity.Error
+	case e.Deletions > 0 || e.Protected > 0:
+		return severity.Destructive
+	case e.Changed > 0:
+		return severity.Change
+	default:
+		return severity.Info
+	}
+}
+
+// FormatDashboard formats the dashboard of the plans of all open PRs, most severe first
+// The repository column is only shown when the PRs span several repositories
+func (f *GitHubFormatter) FormatDashboard(entries []DashboardEntry) string {
+	var b strings.Builder
+
+	b.WriteString("## 📋 Crossplane Plan Dashboard\n\n")
+	if len(entries) == 0 {
+		b.WriteString("No open PRs have a plan.\n")
+		return f.ApplyStyle(b.String())
+	}
+
+	sorted := append([]DashboardEntry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if si, sj := sorted[i].Severity(), sorted[j].Severity(); si != sj {
+			return si > sj
+		}
+		if sorted[i].Repository != sorted[j].Repository {
+			return sorted[i].Repository < sorted[j].Repository
+		}
+		return sorted[i].PRNumber < sorted[j].PRNumber
+	})
+
+	repos := make(map[string]bool)
+	counts := make(map[severity.Level]int)
+	for _, entry := range sorted {
+		repos[entry.Repository] = true
+		counts[entry.Severity()]++
+	}
+	multiRepo := len(repos) > 1
+
+	b.WriteString(fmt.Sprintf("**%d open PRs:** ", len(sorted)))
+	if counts[severity.Error] > 0 {
+		b.WriteString(fmt.Sprintf("%d failed, ", counts[severity.Error]))
+	}
+	b.WriteString(fmt.Sprintf("%d destructive, %d with changes, %d without changes\n\n",
+		counts[severity.Destructive], counts[severity.Change], counts[severity.Info]))
+
+	if multiRepo {
+		b.WriteString("| PR | Repository | Resources | Changes | Deletions | Protected | Severity |\n")
+		b.WriteString("|----|------------|-----------|---------|-----------|-----------|----------|\n")
+	} else {
+		b.WriteString("| PR | Resources | Changes | Deletions | Protected | Severity |\n")
+		b.WriteString("|----|-----------|---------|-----------|-----------|----------|\n")
+	}
+	for _, entry := range sorted {
+		pr := fmt.Sprintf("%s#%d", entry.Repository, entry.PRNumber)
+		if multiRepo {
+			b.WriteString(fmt.Sprintf("| %s | `%s` ", pr, entry.Repository))
+		} else {
+			b.WriteString(fmt.Sprintf("| %s ", pr))
+		}
+		b.WriteString(fmt.Sprintf("| %d | %d | %d | %d | %s |\n",
+			entry.Resources, entry.Changed, entry.Deletions, entry.Protected, severityBadges[entry.Severity()]))
+	}
+
+	b.WriteString("\n_Updated after every plan. Deletions and changes to protected kinds are destructive._\n")
+	return f.ApplyStyle(b.String())
+}
@@ -0,0 +1,70 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/millstonehq/crossplane-plan/pkg/severity"
+)
+
+func TestDashboardEntry_Severity(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry DashboardEntry
+		want  severity.Level
+	}{
+		{name: "failure", entry: DashboardEntry{Changed: 1, Deletions: 1, Failed: 1}, want: severity.Error},
+		{name: "deletion", entry: DashboardEntry{Changed: 1, Deletions: 1}, want: severity.Destructive},
+		{name: "protected kind", entry: DashboardEntry{Changed: 1, Protected: 1}, want: severity.Destructive},
+		{name: "changes", entry: DashboardEntry{Resources: 3, Changed: 2}, want: severity.Change},
+		{name: "no changes", entry: DashboardEntry{Resources: 3}, want: severity.Info},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.entry.Severity(); got != tt.want {
+				t.Errorf("Severity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitHubFormatter_FormatDashboard(t *testing.T) {
+	formatter := NewGitHubFormatter()
+
+	output := formatter.FormatDashboard([]DashboardEntry{
+		{Repository: "owner/infra", PRNumber: 12, Resources: 2},
+		{Repository: "owner/infra", PRNumber: 40, Resources: 3, Changed: 1},
+		{Repository: "owner/infra", PRNumber: 7, Resources: 5, Changed: 2, Deletions: 1},
+	})
+
+	for _, want := range []string{
+		"**3 open PRs:** 1 destructive, 1 with changes, 1 without changes",
+		"| owner/infra#7 | 5 | 2 | 1 | 0 | 🔴 destructive |",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("FormatDashboard() missing %q:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "| Repository |") {
+		t.Errorf("Repository column shown for a single repository:\n%s", output)
+	}
+	high := strings.Index(output, "owner/infra#7")
+	medium := strings.Index(output, "owner/infra#40")
+	none := strings.Index(output, "owner/infra#12")
+	if !(high < medium && medium < none) {
+		t.Errorf("PRs not sorted by severity:\n%s", output)
+	}
+
+	output = formatter.FormatDashboard([]DashboardEntry{
+		{Repository: "owner/infra", PRNumber: 1},
+		{Repository: "owner/apps", PRNumber: 2},
+	})
+	if !strings.Contains(output, "| owner/apps#2 | `owner/apps` |") {
+		t.Errorf("Missing repository column:\n%s", output)
+	}
+
+	if output := formatter.FormatDashboard(nil); !strings.Contains(output, "No open PRs have a plan.") {
+		t.Errorf("FormatDashboard(nil) =\n%s", output)
+	}
+}
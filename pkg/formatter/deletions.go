@@ -0,0 +1,30 @@
+package formatter
+
+import (
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+	"github.com/millstonehq/crossplane-plan/pkg/severity"
+)
+
+// deletionBadge prefixes the summary of a deletion in the deleted resources list
+func deletionBadge(result *differ.DiffResult) string {
+	switch {
+	case result.Blocked:
+		return "🛑 **blocked by policy** - "
+	case result.Severity == severity.Info:
+		return "ℹ️ expected - "
+	default:
+		return ""
+	}
+}
+
+// deletionNotice returns the alert shown with a deleted resource's details
+func deletionNotice(result *differ.DiffResult) string {
+	switch {
+	case result.Blocked:
+		return "> [!CAUTION]\n> **🛑 BLOCKED:** This resource will be **DELETED** when the PR is merged, and deletions of this kind are blocked by policy.\n\n"
+	case result.Severity == severity.Info:
+		return "> [!NOTE]\n> This resource will be deleted when the PR is merged. Deletions of this kind are expected.\n\n"
+	default:
+		return "> **⚠️ WARNING:** This resource will be **DELETED** when the PR is merged.\n\n"
+	}
+}
@@ -0,0 +1,42 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+	"github.com/millstonehq/crossplane-plan/pkg/severity"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestGitHubFormatter_DeletionSeverities(t *testing.T) {
+	formatter := NewGitHubFormatter()
+	var items []differ.PlanItem
+	items = differ.AddDeletion(items, schema.GroupVersionKind{Group: "storage.example.com", Version: "v1", Kind: "XTestBucket"}, "", "scratch", &differ.DiffResult{
+		HasChanges: true,
+		Summary:    "Resource will be deleted",
+		Severity:   severity.Info,
+	})
+	items = differ.AddDeletion(items, schema.GroupVersionKind{Group: "db.example.com", Version: "v1", Kind: "XDatabase"}, "", "orders", &differ.DiffResult{
+		HasChanges: true,
+		Summary:    "Resource will be deleted",
+		Severity:   severity.Destructive,
+		Blocked:    true,
+	})
+
+	output := formatter.FormatMultipleDiffs(items, nil)
+
+	for _, want := range []string{
+		"**XTestBucket.storage.example.com/scratch**: ℹ️ expected - Resource will be deleted",
+		"**XDatabase.db.example.com/orders**: 🛑 **blocked by policy** - Resource will be deleted",
+		"> [!NOTE]\n> This resource will be deleted when the PR is merged. Deletions of this kind are expected.",
+		"> [!CAUTION]\n> **🛑 BLOCKED:**",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Missing %q:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "⚠️ WARNING:") {
+		t.Errorf("Warning shown for deletions with a policy:\n%s", output)
+	}
+}
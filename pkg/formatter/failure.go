@@ -0,0 +1,30 @@
+package formatter
+
+import (
+	"fmt"
+
+	"github.com/millstonehq/crossplane-plan/pkg/errclass"
+)
+
+// failureCauses explain the classes of errors failing a whole plan
+var failureCauses = map[errclass.Class]string{
+	errclass.Auth:        "crossplane-plan's credentials or permissions were rejected",
+	errclass.RateLimited: "an API rate limited crossplane-plan",
+	errclass.DiffEngine:  "the diff engine failed",
+	errclass.NotFound:    "a resource the plan needs was not found",
+	errclass.Config:      "crossplane-plan's configuration is invalid",
+	errclass.Unknown:     "an unexpected error occurred",
+}
+
+// FormatPlanFailure formats the comment of a PR whose plan failed before any resource was planned
+// It names the error's class and an ID to find the error in the logs, but not the error itself,
+// which may reveal cluster details
+func (f *GitHubFormatter) FormatPlanFailure(class errclass.Class, failureID string) string {
+	cause, ok := failureCauses[class]
+	if !ok {
+		cause = failureCauses[errclass.Unknown]
+	}
+	return f.ApplyStyle(fmt.Sprintf("## ❌ Crossplane Preview\n\n**Preview failed:** `%s` — %s.\n\n"+
+		"See the crossplane-plan logs for failure ID `%s`. This comment is replaced by the plan once it succeeds.\n",
+		class, cause, failureID))
+}
@@ -0,0 +1,30 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/millstonehq/crossplane-plan/pkg/errclass"
+)
+
+func TestGitHubFormatter_FormatPlanFailure(t *testing.T) {
+	tests := []struct {
+		name  string
+		class errclass.Class
+		want  string
+	}{
+		{name: "auth", class: errclass.Auth, want: "**Preview failed:** `auth` — crossplane-plan's credentials or permissions were rejected."},
+		{name: "unknown", class: errclass.Unknown, want: "**Preview failed:** `unknown` — an unexpected error occurred."},
+		{name: "unlisted class", class: errclass.Class("other"), want: "**Preview failed:** `other` — an unexpected error occurred."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewGitHubFormatter().FormatPlanFailure(tt.class, "1a2b3c4d")
+			for _, want := range []string{"## ❌ Crossplane Preview", tt.want, "failure ID `1a2b3c4d`"} {
+				if !strings.Contains(got, want) {
+					t.Errorf("Missing %q:\n%s", want, got)
+				}
+			}
+		})
+	}
+}
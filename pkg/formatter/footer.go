@@ -0,0 +1,53 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// releasesURL lists the crossplane-plan releases
+const releasesURL = "https://github.com/millstonehq/crossplane-plan/releases"
+
+// SetFooterWarning shows a warning in the footer of every comment, e.g. that the cluster runs a
+// Crossplane version plans may be wrong for; empty removes it
+func (f *GitHubFormatter) SetFooterWarning(warning string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.footerWarning = warning
+}
+
+// SetVersion shows the running crossplane-plan version in the footer of every comment; empty hides it
+func (f *GitHubFormatter) SetVersion(version string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.version = version
+}
+
+// SetLatestVersion adds a hint to the footer that a newer release is available; empty removes it
+// Only shown along with the running version (see SetVersion)
+func (f *GitHubFormatter) SetLatestVersion(latest string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.latestVersion = latest
+}
+
+// formatGeneratedBy adds the attribution line of the footer, with the running version if it is
+// shown, and the footer warning if there is one
+func (f *GitHubFormatter) formatGeneratedBy(b *strings.Builder) {
+	f.mu.RLock()
+	version, latest, warning := f.version, f.latestVersion, f.footerWarning
+	f.mu.RUnlock()
+
+	b.WriteString("_Generated by [crossplane-plan](https://github.com/millstonehq/crossplane-plan)")
+	if version != "" {
+		b.WriteString(" " + version)
+		if latest != "" {
+			b.WriteString(fmt.Sprintf(" · [%s is available](%s/tag/%s)", latest, releasesURL, latest))
+		}
+	}
+	b.WriteString("_\n")
+
+	if warning != "" {
+		b.WriteString("\n> ⚠️ " + warning + "\n")
+	}
+}
@@ -3,64 +3,123 @@ package formatter
 import (
 	"fmt"
 	"reflect"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
+	"text/template"
 
 	"github.com/millstonehq/crossplane-plan/pkg/argocd"
 	"github.com/millstonehq/crossplane-plan/pkg/differ"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/yaml"
 )
 
 // GitHubFormatter formats diffs for GitHub PR comments
-type GitHubFormatter struct{}
+type GitHubFormatter struct {
+	mu        sync.RWMutex
+	hints     map[schema.GroupKind]RenderHints // rendering hints from XRD annotations
+	style     Style                            // wording and decoration of comments
+	plainText bool                             // no collapsible sections, for VCSes that render them poorly
+
+	postProcessors []PostProcessor    // rewrite rendered comments, in order
+	layout         *template.Template // replaces the default layout of plan comments, if set
+
+	footerWarning string // shown in the footer of every comment
+	version       string // running version shown in the footer, if set
+	latestVersion string // newer release hinted at in the footer, if set
+}
+
+// Option configures a GitHubFormatter when it is created
+type Option func(*GitHubFormatter)
+
+// WithRenderHints sets the rendering hints, keyed by XR group and kind
+func WithRenderHints(hints map[schema.GroupKind]RenderHints) Option {
+	return func(f *GitHubFormatter) { f.SetRenderHints(hints) }
+}
 
 // NewGitHubFormatter creates a new GitHubFormatter
-func NewGitHubFormatter() *GitHubFormatter {
-	return &GitHubFormatter{}
+func NewGitHubFormatter(opts ...Option) *GitHubFormatter {
+	f := &GitHubFormatter{}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
 }
 
 // FormatDiff formats a diff result as a GitHub-flavored markdown comment
 func (f *GitHubFormatter) FormatDiff(xr *unstructured.Unstructured, result *differ.DiffResult) string {
+	return f.ApplyStyle(f.formatDiff(xr, result))
+}
+
+// formatDiff formats a diff result, before the style is applied
+func (f *GitHubFormatter) formatDiff(xr *unstructured.Unstructured, result *differ.DiffResult) string {
 	var b strings.Builder
 
 	// Header
 	b.WriteString("## 🔄 Crossplane Preview\n\n")
 	
 	// XR information
-	b.WriteString(fmt.Sprintf("**Resource:** `%s/%s`\n", xr.GetKind(), xr.GetName()))
+	hints := f.hintsFor(xr)
+	b.WriteString(fmt.Sprintf("**Resource:** %s\n", resourceLabel(xr, hints)))
 	if xr.GetNamespace() != "" {
 		b.WriteString(fmt.Sprintf("**Namespace:** `%s`\n", xr.GetNamespace()))
 	}
 	b.WriteString("\n")
+	formatSummaryFields(&b, xr, hints)
+	formatLinks(&b, result.Links)
+	formatSources(&b, result.Sources)
+
+	item := differ.NewPlanItem(xr.GetName(), result)
+	if item.Action == differ.ActionError {
+		formatFailures(&b, []differ.PlanItem{item})
+		b.WriteString("---\n")
+		f.formatGeneratedBy(&b)
+		return b.String()
+	}
 
 	// Summary
 	if !result.HasChanges {
 		b.WriteString("### ✅ No Changes\n\n")
 		b.WriteString("This PR will not modify any infrastructure resources.\n\n")
+		f.formatProductionDrift(&b, []differ.PlanItem{item})
+		f.formatDiffInput(&b, xr.GetName(), result)
+		formatPipeline(&b, xr.GetName(), result)
 		// Footer
 		b.WriteString("---\n")
-		b.WriteString("_Generated by [crossplane-plan](https://github.com/millstonehq/crossplane-plan)_\n")
+		f.formatGeneratedBy(&b)
 		return b.String()
 	}
 
 	// Changes detected
+	b.WriteString(anchorTag(xr.GetName(), false))
 	b.WriteString("### 📋 Changes Detected\n\n")
 	b.WriteString(result.Summary)
 	b.WriteString("\n\n")
 
-	// Diff output
-	b.WriteString("<details>\n")
-	b.WriteString("<summary>📝 View Full Diff</summary>\n\n")
-	b.WriteString("```diff\n")
-	b.WriteString(result.RawDiff)
-	b.WriteString("\n```\n")
-	b.WriteString("</details>\n\n")
+	// Diff output (empty when only the ordering changes)
+	if result.RawDiff != "" {
+		b.WriteString("<details>\n")
+		b.WriteString("<summary>📝 View Full Diff</summary>\n\n")
+		b.WriteString("```diff\n")
+		b.WriteString(hideFields(result.RawDiff, hints.HideFields))
+		b.WriteString("\n```\n")
+		b.WriteString("</details>\n\n")
+	}
+
+	formatOrderingChanges(&b, []differ.PlanItem{item})
+	formatFieldOwners(&b, result.FieldOwners)
+	f.formatProductionDrift(&b, []differ.PlanItem{item})
 
 	// Infrastructure drift detection
 	if len(result.ManagedResources) > 0 {
 		f.formatInfrastructureDrift(&b, result.ManagedResources)
 	}
 
+	f.formatDiffInput(&b, xr.GetName(), result)
+	formatPipeline(&b, xr.GetName(), result)
+
 	// Footer with transparency about stripped fields
 	f.formatStrippedFieldsFooter(&b, result.StrippedFields)
 
@@ -128,10 +187,16 @@ func (f *GitHubFormatter) formatInfrastructureDrift(b *strings.Builder, managedR
 			b.WriteString("| Field | Your Declaration | Actual Infrastructure |\n")
 			b.WriteString("|-------|------------------|----------------------|\n")
 
-			for field, comparison := range scalarFields {
-				declaredStr := fmt.Sprintf("`%v`", comparison.Declared)
-				actualStr := fmt.Sprintf("`%v`", comparison.Actual)
-				b.WriteString(fmt.Sprintf("| `%s` | %s | %s |\n", field, declaredStr, actualStr))
+			// Nested fields are reported as separate paths, so keep related rows together
+			fields := make([]string, 0, len(scalarFields))
+			for field := range scalarFields {
+				fields = append(fields, field)
+			}
+			sort.Strings(fields)
+
+			for _, field := range fields {
+				comparison := scalarFields[field]
+				b.WriteString(fmt.Sprintf("| `%s` | %s | %s |\n", field, formatDriftValue(comparison.Declared), formatDriftValue(comparison.Actual)))
 			}
 			b.WriteString("\n")
 		}
@@ -163,11 +228,61 @@ func (f *GitHubFormatter) formatInfrastructureDrift(b *strings.Builder, managedR
 	}
 }
 
+// FormatFreezeNotice formats the notice prefixed to comments posted during a freeze window
+func (f *GitHubFormatter) FormatFreezeNotice(window string) string {
+	return f.ApplyStyle(fmt.Sprintf("> [!NOTE]\n> 🧊 Posted during the **%s** freeze window.\n\n", window))
+}
+
+// FormatShedNotice formats the notice prefixed to comments of PRs with more XRs than a plan may cover
+func (f *GitHubFormatter) FormatShedNotice(planned, total int) string {
+	return f.ApplyStyle(fmt.Sprintf("> [!WARNING]\n> Only %d of this PR's %d XRs were planned to stay within crossplane-plan's resource limits; the others are not shown.\n\n", planned, total))
+}
+
+// FormatUnchangedNotice formats the notice prefixed to comments of PRs with XRs whose sources they don't change
+func (f *GitHubFormatter) FormatUnchangedNotice(names []string) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("> [!NOTE]\n> %d XRs were not planned because this PR doesn't change their source files:", len(names)))
+	for _, name := range names {
+		b.WriteString(fmt.Sprintf(" `%s`", name))
+	}
+	b.WriteString("\n\n")
+	return f.ApplyStyle(b.String())
+}
+
+// FormatOwnersNotice formats the notice mentioning the teams owning resources a PR modifies or deletes
+func (f *GitHubFormatter) FormatOwnersNotice(teams []string) string {
+	return f.ApplyStyle(fmt.Sprintf("> [!IMPORTANT]\n> cc %s: this PR modifies or deletes resources you own.\n\n", strings.Join(teams, " ")))
+}
+
+// FormatPlaceholder formats the comment shown while a long-running plan is computed
+func (f *GitHubFormatter) FormatPlaceholder(resourceCount int) string {
+	var b strings.Builder
+
+	b.WriteString("## 🔄 Crossplane Preview\n\n")
+	noun := "resources"
+	if resourceCount == 1 {
+		noun = "resource"
+	}
+	b.WriteString(fmt.Sprintf("⏳ Computing preview for %d %s…\n\n", resourceCount, noun))
+	b.WriteString("_This comment will be updated with the results._\n")
+
+	return f.ApplyStyle(b.String())
+}
+
 // FormatMultipleDiffs formats multiple XR diffs into a single comment
 // argocdDiff is optional - pass nil if ArgoCD integration is not available
-func (f *GitHubFormatter) FormatMultipleDiffs(results map[string]*differ.DiffResult, argocdDiff *argocd.AppDiff) string {
+func (f *GitHubFormatter) FormatMultipleDiffs(items []differ.PlanItem, argocdDiff *argocd.AppDiff) string {
+	return f.ApplyStyle(f.formatMultipleDiffs(items, argocdDiff))
+}
+
+// formatMultipleDiffs formats multiple XR diffs, before the style is applied
+func (f *GitHubFormatter) formatMultipleDiffs(items []differ.PlanItem, argocdDiff *argocd.AppDiff) string {
 	var b strings.Builder
 
+	// Sections are sorted so their order, like their anchors, is stable across plans
+	items = slices.Clone(items)
+	differ.SortItems(items)
+
 	// Header
 	b.WriteString("## 🔄 Crossplane Preview\n\n")
 
@@ -179,19 +294,22 @@ func (f *GitHubFormatter) FormatMultipleDiffs(results map[string]*differ.DiffRes
 
 	// Count total changes
 	totalChanges := 0
-	totalResources := len(results)
-	for _, result := range results {
-		if result.HasChanges {
+	totalResources := len(items)
+	for _, item := range items {
+		if item.Changed() {
 			totalChanges++
 		}
 	}
 
 	// Summary
 	b.WriteString(fmt.Sprintf("**Resources:** %d total, %d with changes\n\n", totalResources, totalChanges))
+	formatFailures(&b, items)
 
 	if totalChanges == 0 && argocdDiff == nil {
 		b.WriteString("### ✅ No Changes\n\n")
 		b.WriteString("This PR will not modify any infrastructure resources.\n")
+		f.formatProductionDrift(&b, items)
+		f.formatDiffInputs(&b, items)
 		return b.String()
 	}
 
@@ -199,6 +317,8 @@ func (f *GitHubFormatter) FormatMultipleDiffs(results map[string]*differ.DiffRes
 		// We have ArgoCD diff but no crossplane-diff changes
 		b.WriteString("### ✅ No Composition Changes\n\n")
 		b.WriteString("Crossplane compositions will not create additional resources.\n\n")
+		f.formatProductionDrift(&b, items)
+		f.formatDiffInputs(&b, items)
 		f.formatStrippedFieldsFooter(&b, []differ.StrippedField{})
 		return b.String()
 	}
@@ -207,26 +327,27 @@ func (f *GitHubFormatter) FormatMultipleDiffs(results map[string]*differ.DiffRes
 	b.WriteString("### 🔧 Crossplane Composition Preview\n\n")
 
 	// Separate modifications and deletions for better presentation
-	modifications := make(map[string]*differ.DiffResult)
-	deletions := make(map[string]*differ.DiffResult)
-
-	for name, result := range results {
-		if result.HasChanges {
-			if strings.HasPrefix(name, "DELETED-") {
-				// Strip the prefix for display
-				actualName := strings.TrimPrefix(name, "DELETED-")
-				deletions[actualName] = result
-			} else {
-				modifications[name] = result
-			}
+	var modifications, deletions []differ.PlanItem
+	for _, item := range items {
+		switch {
+		case !item.Changed():
+		case item.Action == differ.ActionDelete:
+			deletions = append(deletions, item)
+		default:
+			modifications = append(modifications, item)
 		}
 	}
+	formatTableOfContents(&b, modifications, deletions)
 
 	// List modified resources
 	if len(modifications) > 0 {
 		b.WriteString("### 📋 Modified Resources\n\n")
-		for name, result := range modifications {
-			b.WriteString(fmt.Sprintf("- **%s**: %s\n", name, result.Summary))
+		for _, item := range modifications {
+			name := item.Name
+			if displayName := f.hintsFor(item.XR).DisplayName; displayName != "" {
+				name += " (" + displayName + ")"
+			}
+			b.WriteString(fmt.Sprintf("- **%s**: %s\n", name, item.Summary))
 		}
 		b.WriteString("\n")
 	}
@@ -234,33 +355,46 @@ func (f *GitHubFormatter) FormatMultipleDiffs(results map[string]*differ.DiffRes
 	// List deleted resources (with warning)
 	if len(deletions) > 0 {
 		b.WriteString("### 🗑️ Deleted Resources\n\n")
-		for name, result := range deletions {
-			b.WriteString(fmt.Sprintf("- **%s**: %s\n", name, result.Summary))
+		for _, item := range deletions {
+			b.WriteString(fmt.Sprintf("- **%s**: %s%s\n", item.Name, deletionBadge(item.DiffResult), item.Summary))
 		}
 		b.WriteString("\n")
 	}
 
+	formatOrderingChanges(&b, modifications)
+	formatApplyOrder(&b, items)
+
 	// Individual diffs for modifications
-	for name, result := range modifications {
-		b.WriteString(fmt.Sprintf("### `%s`\n\n", name))
-		b.WriteString("<details>\n")
-		b.WriteString("<summary>📝 View Diff</summary>\n\n")
-		b.WriteString("```diff\n")
-		b.WriteString(result.RawDiff)
-		b.WriteString("\n```\n")
-		b.WriteString("</details>\n\n")
+	for _, item := range modifications {
+		hints := f.hintsFor(item.XR)
+		b.WriteString(anchorTag(item.Name, false))
+		b.WriteString(fmt.Sprintf("### `%s`\n\n", item.Name))
+		formatSummaryFields(&b, item.XR, hints)
+		formatLinks(&b, item.Links)
+		formatSources(&b, item.Sources)
+		if item.RawDiff != "" {
+			b.WriteString("<details>\n")
+			b.WriteString("<summary>📝 View Diff</summary>\n\n")
+			b.WriteString("```diff\n")
+			b.WriteString(hideFields(item.RawDiff, hints.HideFields))
+			b.WriteString("\n```\n")
+			b.WriteString("</details>\n\n")
+		}
+		formatFieldOwners(&b, item.FieldOwners)
 	}
 
 	// Individual diffs for deletions
-	for name, result := range deletions {
-		b.WriteString(fmt.Sprintf("### `%s` (DELETION)\n\n", name))
-		b.WriteString("> **⚠️ WARNING:** This resource will be **DELETED** when the PR is merged.\n\n")
+	for _, item := range deletions {
+		result := item.DiffResult
+		b.WriteString(anchorTag(item.Name, true))
+		b.WriteString(fmt.Sprintf("### `%s` (DELETION)\n\n", item.Name))
+		b.WriteString(deletionNotice(result))
 		b.WriteString("<details>\n")
 		b.WriteString("<summary>📄 View Resource Details</summary>\n\n")
 		b.WriteString("```yaml\n")
 		// Format the XR as YAML for display
 		if result.XR != nil {
-			yamlBytes, err := yaml.Marshal(result.XR.Object)
+			yamlBytes, err := yaml.Marshal(withoutFields(result.XR.Object, f.hintsFor(result.XR).HideFields))
 			if err == nil {
 				b.WriteString(string(yamlBytes))
 			} else {
@@ -276,8 +410,8 @@ func (f *GitHubFormatter) FormatMultipleDiffs(results map[string]*differ.DiffRes
 	// Collect all stripped fields from all results
 	var allStrippedFields []differ.StrippedField
 	seenFields := make(map[string]bool)
-	for _, result := range results {
-		for _, field := range result.StrippedFields {
+	for _, item := range items {
+		for _, field := range item.StrippedFields {
 			// Deduplicate by path (same fields stripped across multiple XRs)
 			if !seenFields[field.Path] {
 				allStrippedFields = append(allStrippedFields, field)
@@ -286,6 +420,9 @@ func (f *GitHubFormatter) FormatMultipleDiffs(results map[string]*differ.DiffRes
 		}
 	}
 
+	f.formatProductionDrift(&b, items)
+	f.formatDiffInputs(&b, items)
+
 	// Footer with transparency about stripped fields
 	f.formatStrippedFieldsFooter(&b, allStrippedFields)
 
@@ -300,6 +437,7 @@ func (f *GitHubFormatter) formatArgoCDDiff(b *strings.Builder, diff *argocd.AppD
 	totalChanges := len(diff.Additions) + len(diff.Modifications) + len(diff.Deletions)
 	if totalChanges == 0 {
 		b.WriteString("**No ArgoCD-managed resource changes detected.**\n\n")
+		formatOrphans(b, diff.Orphaned)
 		return
 	}
 
@@ -355,6 +493,7 @@ func (f *GitHubFormatter) formatArgoCDDiff(b *strings.Builder, diff *argocd.AppD
 		}
 		b.WriteString("\n")
 	}
+	formatOrphans(b, diff.Orphaned)
 
 	// Optional: Full diff details
 	if diff.RawDiff != "" {
@@ -367,10 +506,53 @@ func (f *GitHubFormatter) formatArgoCDDiff(b *strings.Builder, diff *argocd.AppD
 	}
 }
 
+// formatOrphans lists the production app's orphaned resources, which syncing leaves alone
+func formatOrphans(b *strings.Builder, orphans []argocd.ResourceOrphan) {
+	if len(orphans) == 0 {
+		return
+	}
+	b.WriteString("**👻 Orphaned Resources** (not managed by any Application, left unchanged):\n\n")
+	for _, orphan := range orphans {
+		resourceID := fmt.Sprintf("%s/%s", orphan.GVK.Kind, orphan.Name)
+		if orphan.Namespace != "" {
+			resourceID = fmt.Sprintf("%s/%s (%s)", orphan.GVK.Kind, orphan.Name, orphan.Namespace)
+		}
+		b.WriteString(fmt.Sprintf("- `%s`\n", resourceID))
+	}
+	b.WriteString("\n")
+}
+
+// formatDiffInputs adds the diff input and pipeline capture of every item that requested debug output
+func (f *GitHubFormatter) formatDiffInputs(b *strings.Builder, items []differ.PlanItem) {
+	for _, item := range items {
+		f.formatDiffInput(b, item.Name, item.DiffResult)
+		formatPipeline(b, item.Name, item.DiffResult)
+	}
+}
+
+// formatDiffInput adds the sanitized XR used as diff input (collapsed) when debug output is requested
+func (f *GitHubFormatter) formatDiffInput(b *strings.Builder, name string, result *differ.DiffResult) {
+	if !result.Debug || result.DiffInput == nil {
+		return
+	}
+
+	yamlBytes, err := yaml.Marshal(result.DiffInput.Object)
+	if err != nil {
+		return
+	}
+
+	b.WriteString("\n<details>\n")
+	b.WriteString(fmt.Sprintf("<summary>🐛 Diff input for <code>%s</code> (sanitized XR)</summary>\n\n", name))
+	b.WriteString("```yaml\n")
+	b.WriteString(string(yamlBytes))
+	b.WriteString("```\n")
+	b.WriteString("</details>\n\n")
+}
+
 // formatStrippedFieldsFooter adds a transparency footer showing stripped fields
 func (f *GitHubFormatter) formatStrippedFieldsFooter(b *strings.Builder, strippedFields []differ.StrippedField) {
 	b.WriteString("---\n")
-	b.WriteString("_Generated by [crossplane-plan](https://github.com/millstonehq/crossplane-plan)_\n")
+	f.formatGeneratedBy(b)
 
 	// Only show stripped fields section if fields were actually stripped
 	if len(strippedFields) == 0 {
@@ -448,6 +630,14 @@ func (f *GitHubFormatter) formatArrayDiff(b *strings.Builder, field string, comp
 	b.WriteString("\n")
 }
 
+// formatDriftValue formats a drift table cell; nil means the field is only set on one side
+func formatDriftValue(v interface{}) string {
+	if v == nil {
+		return "_(unset)_"
+	}
+	return fmt.Sprintf("`%v`", v)
+}
+
 // toStringSlice converts an interface{} to a string slice for comparison
 func toStringSlice(v interface{}) []string {
 	if v == nil {
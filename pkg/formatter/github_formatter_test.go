@@ -98,18 +98,18 @@ func TestGitHubFormatter_FormatDiff_WithNamespace(t *testing.T) {
 func TestGitHubFormatter_FormatMultipleDiffs_NoChanges(t *testing.T) {
 	formatter := NewGitHubFormatter()
 
-	results := map[string]*differ.DiffResult{
-		"mill": {
+	items := []differ.PlanItem{
+		differ.NewPlanItem("mill", &differ.DiffResult{
 			HasChanges: false,
 			Summary:    "No changes",
-		},
-		"books": {
+		}),
+		differ.NewPlanItem("books", &differ.DiffResult{
 			HasChanges: false,
 			Summary:    "No changes",
-		},
+		}),
 	}
 
-	output := formatter.FormatMultipleDiffs(results, nil)
+	output := formatter.FormatMultipleDiffs(items, nil)
 
 	if !strings.Contains(output, "**Resources:** 2 total, 0 with changes") {
 		t.Error("Missing resource count")
@@ -122,19 +122,19 @@ func TestGitHubFormatter_FormatMultipleDiffs_NoChanges(t *testing.T) {
 func TestGitHubFormatter_FormatMultipleDiffs_WithChanges(t *testing.T) {
 	formatter := NewGitHubFormatter()
 
-	results := map[string]*differ.DiffResult{
-		"mill": {
+	items := []differ.PlanItem{
+		differ.NewPlanItem("mill", &differ.DiffResult{
 			RawDiff:    "+ change",
 			HasChanges: true,
 			Summary:    "Changes: +1 lines",
-		},
-		"books": {
+		}),
+		differ.NewPlanItem("books", &differ.DiffResult{
 			HasChanges: false,
 			Summary:    "No changes",
-		},
+		}),
 	}
 
-	output := formatter.FormatMultipleDiffs(results, nil)
+	output := formatter.FormatMultipleDiffs(items, nil)
 
 	if !strings.Contains(output, "**Resources:** 2 total, 1 with changes") {
 		t.Error("Missing resource count")
@@ -160,21 +160,21 @@ func TestGitHubFormatter_FormatMultipleDiffs_WithDeletions(t *testing.T) {
 		"name": "provider-tailscale",
 	}
 
-	results := map[string]*differ.DiffResult{
-		"pr-5-provider-upjet-tailscale": {
+	items := []differ.PlanItem{
+		differ.NewPlanItem("pr-5-provider-upjet-tailscale", &differ.DiffResult{
 			RawDiff:    "+ new resource",
 			HasChanges: true,
 			Summary:    "Changes detected",
-		},
-		"DELETED-provider-tailscale": {
+		}),
+		{Name: "provider-tailscale", Action: differ.ActionDelete, DiffResult: &differ.DiffResult{
 			XR:         xr,
 			RawDiff:    "Resource will be deleted",
 			HasChanges: true,
 			Summary:    "⚠️  Resource will be **DELETED**",
-		},
+		}},
 	}
 
-	output := formatter.FormatMultipleDiffs(results, nil)
+	output := formatter.FormatMultipleDiffs(items, nil)
 
 	if !strings.Contains(output, "**Resources:** 2 total, 2 with changes") {
 		t.Error("Missing resource count")
@@ -203,25 +203,25 @@ func TestGitHubFormatter_FormatMultipleDiffs_MixedChanges(t *testing.T) {
 	deletedXR.SetKind("XGitHubRepository")
 	deletedXR.SetName("old-repo")
 
-	results := map[string]*differ.DiffResult{
-		"modified-repo": {
+	items := []differ.PlanItem{
+		differ.NewPlanItem("modified-repo", &differ.DiffResult{
 			RawDiff:    "+ modified",
 			HasChanges: true,
 			Summary:    "Modified",
-		},
-		"DELETED-old-repo": {
+		}),
+		{Name: "old-repo", Action: differ.ActionDelete, DiffResult: &differ.DiffResult{
 			XR:         deletedXR,
 			RawDiff:    "Deleted",
 			HasChanges: true,
 			Summary:    "⚠️  Resource will be **DELETED**",
-		},
-		"no-change-repo": {
+		}},
+		differ.NewPlanItem("no-change-repo", &differ.DiffResult{
 			HasChanges: false,
 			Summary:    "No changes",
-		},
+		}),
 	}
 
-	output := formatter.FormatMultipleDiffs(results, nil)
+	output := formatter.FormatMultipleDiffs(items, nil)
 
 	if !strings.Contains(output, "**Resources:** 3 total, 2 with changes") {
 		t.Error("Missing resource count")
@@ -243,3 +243,183 @@ func TestGitHubFormatter_FormatMultipleDiffs_MixedChanges(t *testing.T) {
 		}
 	}
 }
+
+func TestGitHubFormatter_FormatDiff_DebugDiffInput(t *testing.T) {
+	formatter := NewGitHubFormatter()
+
+	xr := &unstructured.Unstructured{}
+	xr.SetKind("XGitHubRepository")
+	xr.SetName("mill")
+
+	diffInput := xr.DeepCopy()
+	diffInput.Object["spec"] = map[string]interface{}{"visibility": "private"}
+
+	result := &differ.DiffResult{
+		XR:         xr,
+		RawDiff:    "+ visibility: private",
+		HasChanges: true,
+		Summary:    "1 field changed",
+		DiffInput:  diffInput,
+	}
+
+	if output := formatter.FormatDiff(xr, result); strings.Contains(output, "Diff input") {
+		t.Error("diff input should only be shown when debug is requested")
+	}
+
+	result.Debug = true
+	output := formatter.FormatDiff(xr, result)
+	if !strings.Contains(output, "🐛 Diff input for <code>mill</code>") {
+		t.Error("Missing diff input section")
+	}
+	if !strings.Contains(output, "visibility: private") {
+		t.Error("Missing sanitized XR YAML")
+	}
+
+	multi := formatter.FormatMultipleDiffs([]differ.PlanItem{differ.NewPlanItem("mill", result)}, nil)
+	if !strings.Contains(multi, "🐛 Diff input for <code>mill</code>") {
+		t.Error("Missing diff input section in combined comment")
+	}
+}
+
+func TestGitHubFormatter_FormatPlaceholder(t *testing.T) {
+	formatter := NewGitHubFormatter()
+
+	output := formatter.FormatPlaceholder(12)
+	if !strings.Contains(output, "⏳ Computing preview for 12 resources…") {
+		t.Errorf("Missing progress message:\n%s", output)
+	}
+	if !strings.HasPrefix(output, "## 🔄 Crossplane Preview") {
+		t.Error("Missing header")
+	}
+
+	if !strings.Contains(formatter.FormatPlaceholder(1), "for 1 resource…") {
+		t.Error("Single resource should not be pluralized")
+	}
+}
+
+func TestGitHubFormatter_FormatShedNotice(t *testing.T) {
+	output := NewGitHubFormatter().FormatShedNotice(500, 812)
+	if !strings.Contains(output, "Only 500 of this PR's 812 XRs were planned") {
+		t.Errorf("Missing counts:\n%s", output)
+	}
+	if !strings.HasSuffix(output, "\n\n") {
+		t.Error("Notice should be separated from the comment by a blank line")
+	}
+}
+
+func TestGitHubFormatter_FormatUnchangedNotice(t *testing.T) {
+	output := NewGitHubFormatter().FormatUnchangedNotice([]string{"pr-1-vpc", "pr-1-db"})
+	if !strings.Contains(output, "2 XRs were not planned") {
+		t.Errorf("Missing count:\n%s", output)
+	}
+	if !strings.Contains(output, "`pr-1-vpc` `pr-1-db`") {
+		t.Errorf("Missing names:\n%s", output)
+	}
+	if !strings.HasSuffix(output, "\n\n") {
+		t.Error("Notice should be separated from the comment by a blank line")
+	}
+}
+
+func TestGitHubFormatter_FormatOwnersNotice(t *testing.T) {
+	output := NewGitHubFormatter().FormatOwnersNotice([]string{"@acme/network", "@acme/payments"})
+	if !strings.Contains(output, "cc @acme/network @acme/payments:") {
+		t.Errorf("Missing mentions:\n%s", output)
+	}
+	if !strings.HasSuffix(output, "\n\n") {
+		t.Error("Notice should be separated from the comment by a blank line")
+	}
+}
+
+func TestGitHubFormatter_FormatFreezeNotice(t *testing.T) {
+	output := NewGitHubFormatter().FormatFreezeNotice("release")
+	if !strings.Contains(output, "**release** freeze window") {
+		t.Errorf("Missing window name:\n%s", output)
+	}
+	if !strings.HasSuffix(output, "\n\n") {
+		t.Error("Notice should be separated from the comment by a blank line")
+	}
+}
+
+func TestGitHubFormatter_FormatDiff_Pipeline(t *testing.T) {
+	formatter := NewGitHubFormatter()
+
+	xr := &unstructured.Unstructured{}
+	xr.SetKind("XNetwork")
+	xr.SetName("network")
+
+	result := &differ.DiffResult{
+		XR:      xr,
+		Summary: "No changes detected for XNetwork/network",
+		Pipeline: []differ.PipelineCapture{{
+			Composite:   "XNetwork/network",
+			Composition: "xnetworks",
+			Steps: []differ.PipelineStep{
+				{Step: "resources", Function: "function-patch-and-transform", Composed: []map[string]interface{}{{}}},
+				{Step: "policy", Function: "function-kcl", Error: "kcl: invalid | input"},
+			},
+		}},
+		PipelineArtifact: "/captures/millstonehq/infra/pr-7/network.yaml",
+	}
+
+	output := formatter.FormatDiff(xr, result)
+	for _, want := range []string{
+		"🔬 Function pipeline for <code>network</code>",
+		"**XNetwork/network** (composition `xnetworks`)",
+		"| 1 | `resources` | `function-patch-and-transform` | 1 | 0 |",
+		"| 2 | `policy` | `function-kcl` | 0 | ❌ kcl: invalid \\| input |",
+		"`/captures/millstonehq/infra/pr-7/network.yaml`",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestGitHubFormatter_FooterWarning(t *testing.T) {
+	formatter := NewGitHubFormatter()
+
+	xr := &unstructured.Unstructured{}
+	xr.SetKind("XNetwork")
+	xr.SetName("network")
+	result := &differ.DiffResult{XR: xr, RawDiff: "+ cidr: 10.0.0.0/16", HasChanges: true, Summary: "1 field changed"}
+
+	if output := formatter.FormatDiff(xr, result); strings.Contains(output, "⚠️ The cluster runs") {
+		t.Error("no warning should be shown by default")
+	}
+
+	formatter.SetFooterWarning("The cluster runs Crossplane v2.1.0")
+	for _, output := range []string{
+		formatter.FormatDiff(xr, result),
+		formatter.FormatMultipleDiffs([]differ.PlanItem{differ.NewPlanItem("network", result)}, nil),
+	} {
+		if !strings.Contains(output, "> ⚠️ The cluster runs Crossplane v2.1.0") {
+			t.Errorf("missing footer warning:\n%s", output)
+		}
+	}
+}
+
+func TestGitHubFormatter_FooterVersion(t *testing.T) {
+	formatter := NewGitHubFormatter()
+
+	xr := &unstructured.Unstructured{}
+	xr.SetKind("XNetwork")
+	xr.SetName("network")
+	result := &differ.DiffResult{XR: xr, Summary: "No changes detected for XNetwork/network"}
+
+	formatter.SetLatestVersion("v0.10.0")
+	if output := formatter.FormatDiff(xr, result); strings.Contains(output, "v0.10.0") {
+		t.Error("the release hint should only be shown along with the running version")
+	}
+
+	formatter.SetVersion("v0.9.0")
+	want := "_Generated by [crossplane-plan](https://github.com/millstonehq/crossplane-plan) v0.9.0 · " +
+		"[v0.10.0 is available](https://github.com/millstonehq/crossplane-plan/releases/tag/v0.10.0)_"
+	if output := formatter.FormatDiff(xr, result); !strings.Contains(output, want) {
+		t.Errorf("missing version footer:\n%s", output)
+	}
+
+	formatter.SetLatestVersion("")
+	if output := formatter.FormatDiff(xr, result); !strings.Contains(output, "crossplane-plan) v0.9.0_") {
+		t.Errorf("missing running version:\n%s", output)
+	}
+}
@@ -0,0 +1,176 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// XRD annotations with rendering hints for the XRs of its kind
+const (
+	// DisplayNameAnnotation is shown next to the kind, e.g. "Production Database"
+	DisplayNameAnnotation = "millstone.tech/plan-display-name"
+
+	// SummaryFieldsAnnotation lists comma-separated field paths whose values are summarized
+	// above the diff, e.g. "spec.parameters.region,spec.parameters.size"
+	SummaryFieldsAnnotation = "millstone.tech/plan-summary-fields"
+
+	// HideFieldsAnnotation lists comma-separated field paths left out of the rendered diff
+	HideFieldsAnnotation = "millstone.tech/plan-hide-fields"
+)
+
+// RenderHints tune how the XRs of a kind are shown in comments
+type RenderHints struct {
+	DisplayName   string
+	SummaryFields []string
+	HideFields    []string
+}
+
+// HintsFromAnnotations reads the rendering hints of an XRD's annotations
+// Returns false if the XRD has none
+func HintsFromAnnotations(annotations map[string]string) (RenderHints, bool) {
+	hints := RenderHints{
+		DisplayName:   strings.TrimSpace(annotations[DisplayNameAnnotation]),
+		SummaryFields: splitFields(annotations[SummaryFieldsAnnotation]),
+		HideFields:    splitFields(annotations[HideFieldsAnnotation]),
+	}
+	ok := hints.DisplayName != "" || len(hints.SummaryFields) > 0 || len(hints.HideFields) > 0
+	return hints, ok
+}
+
+// splitFields splits a comma-separated list of field paths
+func splitFields(value string) []string {
+	var fields []string
+	for _, field := range strings.Split(value, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// SetRenderHints replaces the rendering hints, keyed by XR group and kind
+func (f *GitHubFormatter) SetRenderHints(hints map[schema.GroupKind]RenderHints) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.hints = hints
+}
+
+// hintsFor returns the rendering hints of an XR's kind (empty for nil XRs or kinds without hints)
+func (f *GitHubFormatter) hintsFor(xr *unstructured.Unstructured) RenderHints {
+	if xr == nil {
+		return RenderHints{}
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.hints[xr.GroupVersionKind().GroupKind()]
+}
+
+// resourceLabel returns "Kind/name", prefixed with the kind's display name if it has one
+func resourceLabel(xr *unstructured.Unstructured, hints RenderHints) string {
+	label := fmt.Sprintf("`%s/%s`", xr.GetKind(), xr.GetName())
+	if hints.DisplayName != "" {
+		label = hints.DisplayName + " " + label
+	}
+	return label
+}
+
+// formatSummaryFields lists the values of an XR's summary fields
+// Missing fields are skipped
+func formatSummaryFields(b *strings.Builder, xr *unstructured.Unstructured, hints RenderHints) {
+	if xr == nil {
+		return
+	}
+
+	written := false
+	for _, path := range hints.SummaryFields {
+		value, found, err := unstructured.NestedFieldNoCopy(xr.Object, strings.Split(path, ".")...)
+		if err != nil || !found {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("- `%s`: `%v`\n", path, value))
+		written = true
+	}
+	if written {
+		b.WriteString("\n")
+	}
+}
+
+// hideFields removes the lines of hidden fields (and their nested lines) from a YAML diff
+// The field path of each line is tracked by indentation; diff markers and headers are kept
+func hideFields(rawDiff string, paths []string) string {
+	if len(paths) == 0 {
+		return rawDiff
+	}
+
+	hidden := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		hidden[path] = true
+	}
+
+	type level struct {
+		indent int
+		key    string
+	}
+	var stack []level
+	hiddenIndent := -1
+
+	lines := strings.Split(rawDiff, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		content := line
+		if len(content) > 0 && strings.ContainsRune("+- ", rune(content[0])) {
+			content = content[1:]
+		}
+		trimmed := strings.TrimLeft(content, " ")
+		indent := len(content) - len(trimmed)
+
+		key, _, isKey := strings.Cut(trimmed, ":")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.ContainsAny(key, " {}[]\"'") {
+			isKey = false
+		}
+
+		if hiddenIndent >= 0 && (indent > hiddenIndent || (indent == hiddenIndent && strings.HasPrefix(trimmed, "- "))) {
+			continue // Nested under a hidden field
+		}
+		hiddenIndent = -1
+
+		if !isKey {
+			if indent == 0 && trimmed != "" {
+				stack = stack[:0] // Header or document separator: a new resource starts
+			}
+			kept = append(kept, line)
+			continue
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		stack = append(stack, level{indent: indent, key: key})
+
+		keys := make([]string, len(stack))
+		for i, l := range stack {
+			keys[i] = l.key
+		}
+		if hidden[strings.Join(keys, ".")] {
+			hiddenIndent = indent
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n")
+}
+
+// withoutFields returns a copy of obj without the hidden fields
+func withoutFields(obj map[string]interface{}, paths []string) map[string]interface{} {
+	if len(paths) == 0 {
+		return obj
+	}
+	copied := (&unstructured.Unstructured{Object: obj}).DeepCopy().Object
+	for _, path := range paths {
+		unstructured.RemoveNestedField(copied, strings.Split(path, ".")...)
+	}
+	return copied
+}
@@ -0,0 +1,96 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestHintsFromAnnotations(t *testing.T) {
+	hints, ok := HintsFromAnnotations(map[string]string{
+		DisplayNameAnnotation:   " Network ",
+		SummaryFieldsAnnotation: "spec.parameters.region, spec.parameters.cidr,",
+		"unrelated":             "x",
+	})
+	if !ok {
+		t.Fatal("HintsFromAnnotations() ok = false, want true")
+	}
+	if hints.DisplayName != "Network" {
+		t.Errorf("DisplayName = %q, want %q", hints.DisplayName, "Network")
+	}
+	if got := strings.Join(hints.SummaryFields, "|"); got != "spec.parameters.region|spec.parameters.cidr" {
+		t.Errorf("SummaryFields = %q", got)
+	}
+
+	if _, ok := HintsFromAnnotations(map[string]string{"unrelated": "x"}); ok {
+		t.Error("HintsFromAnnotations() ok = true for an XRD without hints")
+	}
+}
+
+func TestHideFields(t *testing.T) {
+	rawDiff := strings.Join([]string{
+		"~~~ XNetwork/vpc",
+		"  spec:",
+		"    parameters:",
+		"-     internalId: abc",
+		"+     internalId: def",
+		"      region: us-east-1",
+		"      tags:",
+		"      - team-a",
+		"+     - team-b",
+		"-     cidr: 10.0.0.0/16",
+		"+     cidr: 10.1.0.0/16",
+	}, "\n")
+
+	got := hideFields(rawDiff, []string{"spec.parameters.internalId", "spec.parameters.tags"})
+
+	for _, hidden := range []string{"internalId", "tags", "team-a", "team-b"} {
+		if strings.Contains(got, hidden) {
+			t.Errorf("hideFields() kept %q:\n%s", hidden, got)
+		}
+	}
+	for _, kept := range []string{"~~~ XNetwork/vpc", "region: us-east-1", "-     cidr: 10.0.0.0/16", "+     cidr: 10.1.0.0/16"} {
+		if !strings.Contains(got, kept) {
+			t.Errorf("hideFields() dropped %q:\n%s", kept, got)
+		}
+	}
+}
+
+func TestGitHubFormatter_RenderHints(t *testing.T) {
+	formatter := NewGitHubFormatter()
+	formatter.SetRenderHints(map[schema.GroupKind]RenderHints{
+		{Group: "example.org", Kind: "XNetwork"}: {
+			DisplayName:   "Network",
+			SummaryFields: []string{"spec.parameters.region"},
+			HideFields:    []string{"spec.parameters.internalId"},
+		},
+	})
+
+	xr := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.org/v1alpha1",
+		"kind":       "XNetwork",
+		"metadata":   map[string]interface{}{"name": "vpc"},
+		"spec": map[string]interface{}{
+			"parameters": map[string]interface{}{"region": "us-east-1", "internalId": "abc"},
+		},
+	}}
+	output := formatter.FormatDiff(xr, &differ.DiffResult{
+		XR:         xr,
+		HasChanges: true,
+		Summary:    "1 resource modified",
+		RawDiff:    "  spec:\n    parameters:\n-     internalId: abc\n+     internalId: def",
+	})
+
+	if !strings.Contains(output, "**Resource:** Network `XNetwork/vpc`") {
+		t.Errorf("Missing display name:\n%s", output)
+	}
+	if !strings.Contains(output, "- `spec.parameters.region`: `us-east-1`") {
+		t.Errorf("Missing summary field:\n%s", output)
+	}
+	if strings.Contains(output, "internalId") {
+		t.Errorf("Hidden field rendered:\n%s", output)
+	}
+}
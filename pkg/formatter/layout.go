@@ -0,0 +1,84 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/millstonehq/crossplane-plan/pkg/argocd"
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+)
+
+// LayoutData is the data available to comment layout templates
+type LayoutData struct {
+	// Repository is the repository the comment is posted to (format: owner/repo)
+	Repository string
+
+	// PRNumber is the pull request the plan belongs to
+	PRNumber int
+
+	// Resources are the PR's XRs and the production resources it deletes, in comment order
+	// Each has .Name, .Action and .GroupKind, and the fields of its diff result such as .Summary,
+	// .RawDiff, .ManagedResources and .StrippedFields
+	Resources []differ.PlanItem
+
+	// ArgoCD is the diff of the PR's ArgoCD applications (nil without one)
+	ArgoCD *argocd.AppDiff
+
+	// Body is the plan in the default layout
+	Body string
+}
+
+// ByAction returns the resources with an action: create, update, delete, no-op or error
+// Example: {{ len (.ByAction "create") }} to add
+func (d LayoutData) ByAction(action string) []differ.PlanItem {
+	var items []differ.PlanItem
+	for _, item := range d.Resources {
+		if string(item.Action) == action {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// ParseLayout parses a comment layout template
+func ParseLayout(text string) (*template.Template, error) {
+	tmpl, err := template.New("layout").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse comment layout: %w", err)
+	}
+	return tmpl, nil
+}
+
+// SetLayout replaces the layout of plan comments with a Go text/template executed with LayoutData
+// (empty restores the default layout)
+func (f *GitHubFormatter) SetLayout(text string) error {
+	var layout *template.Template
+	if text != "" {
+		var err error
+		if layout, err = ParseLayout(text); err != nil {
+			return err
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.layout = layout
+	return nil
+}
+
+// FormatLayout renders a plan with the configured layout, or returns data.Body without one
+func (f *GitHubFormatter) FormatLayout(data LayoutData) (string, error) {
+	f.mu.RLock()
+	layout := f.layout
+	f.mu.RUnlock()
+	if layout == nil {
+		return data.Body, nil
+	}
+
+	var b strings.Builder
+	if err := layout.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render comment layout: %w", err)
+	}
+	return b.String(), nil
+}
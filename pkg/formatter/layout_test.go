@@ -0,0 +1,81 @@
+package formatter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/millstonehq/crossplane-plan/pkg/argocd"
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+)
+
+func TestGitHubFormatter_FormatLayout(t *testing.T) {
+	items := []differ.PlanItem{
+		differ.NewPlanItem("pr-1-vpc", &differ.DiffResult{
+			HasChanges:     true,
+			Summary:        "1 resource modified",
+			RawDiff:        "~ XNetwork/vpc",
+			StrippedFields: []differ.StrippedField{{Path: "spec.managementPolicies", Reason: "read-only previews"}},
+		}),
+		differ.NewPlanItem("pr-1-logs", &differ.DiffResult{Summary: "No changes"}),
+		differ.NewPlanItem("pr-1-queue", differ.FailedResult(nil, errors.New("composition not found"))),
+	}
+	items = differ.AddDeletion(items, cacheGVK, "", "sessions", &differ.DiffResult{HasChanges: true, Summary: "deleted"})
+	data := LayoutData{
+		Repository: "acme/infra",
+		PRNumber:   1,
+		Resources:  items,
+		ArgoCD:     &argocd.AppDiff{Additions: []argocd.ResourceChange{{Name: "dashboard"}}},
+		Body:       "## 🔄 Crossplane Preview",
+	}
+
+	tests := []struct {
+		name    string
+		layout  string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "default layout",
+			want: "## 🔄 Crossplane Preview",
+		},
+		{
+			name:   "terraform style",
+			layout: "Plan for {{ .Repository }}#{{ .PRNumber }}: {{ len (.ByAction \"create\") }} to add, {{ len (.ByAction \"update\") }} to change, {{ len (.ByAction \"delete\") }} to destroy, {{ len (.ByAction \"error\") }} failed\n{{ range .ByAction \"update\" }}```diff\n{{ .RawDiff }}\n```\n{{ range .StrippedFields }}(ignored {{ .Path }})\n{{ end }}{{ end }}{{ with .ArgoCD }}{{ len .Additions }} ArgoCD additions{{ end }}",
+			want:   "Plan for acme/infra#1: 0 to add, 1 to change, 1 to destroy, 1 failed\n```diff\n~ XNetwork/vpc\n```\n(ignored spec.managementPolicies)\n1 ArgoCD additions",
+		},
+		{
+			name:   "wrapped default layout",
+			layout: "{{ .Body }}\n\n_{{ len .Resources }} resources_",
+			want:   "## 🔄 Crossplane Preview\n\n_4 resources_",
+		},
+		{
+			name:    "unknown field",
+			layout:  "{{ .Missing }}",
+			wantErr: "failed to render comment layout",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewGitHubFormatter()
+			if err := f.SetLayout(tt.layout); err != nil {
+				t.Fatalf("SetLayout() error = %v", err)
+			}
+			got, err := f.FormatLayout(data)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("FormatLayout() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("FormatLayout() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+
+	if err := NewGitHubFormatter().SetLayout("{{ range .Resources }}"); err == nil {
+		t.Error("SetLayout() of an invalid template succeeded")
+	}
+}
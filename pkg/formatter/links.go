@@ -0,0 +1,36 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+)
+
+// formatLinks writes a resource's deep links as a single row
+func formatLinks(b *strings.Builder, links []differ.Link) {
+	if len(links) == 0 {
+		return
+	}
+	rendered := make([]string, 0, len(links))
+	for _, link := range links {
+		rendered = append(rendered, fmt.Sprintf("[%s](%s)", link.Name, link.URL))
+	}
+	b.WriteString("**Links:** " + strings.Join(rendered, " · ") + "\n\n")
+}
+
+// formatSources writes the changed files declaring a resource as a single row
+func formatSources(b *strings.Builder, sources []differ.Link) {
+	if len(sources) == 0 {
+		return
+	}
+	rendered := make([]string, 0, len(sources))
+	for _, source := range sources {
+		if source.URL == "" {
+			rendered = append(rendered, fmt.Sprintf("`%s`", source.Name))
+			continue
+		}
+		rendered = append(rendered, fmt.Sprintf("[`%s`](%s)", source.Name, source.URL))
+	}
+	b.WriteString("**Source:** " + strings.Join(rendered, " · ") + "\n\n")
+}
@@ -0,0 +1,51 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestGitHubFormatter_Links(t *testing.T) {
+	formatter := NewGitHubFormatter()
+	links := []differ.Link{
+		{Name: "ArgoCD", URL: "https://argocd.example.com/applications/argocd/pr-1-infra"},
+		{Name: "Grafana", URL: "https://grafana.example.com/d/xr?var-name=db"},
+	}
+	want := "**Links:** [ArgoCD](https://argocd.example.com/applications/argocd/pr-1-infra) · [Grafana](https://grafana.example.com/d/xr?var-name=db)"
+
+	xr := &unstructured.Unstructured{}
+	xr.SetKind("XDatabase")
+	xr.SetName("db")
+	single := formatter.FormatDiff(xr, &differ.DiffResult{HasChanges: true, Summary: "1 resource modified", RawDiff: "+ change", Links: links})
+	if !strings.Contains(single, want) {
+		t.Errorf("FormatDiff() missing links row:\n%s", single)
+	}
+
+	multiple := formatter.FormatMultipleDiffs([]differ.PlanItem{
+		differ.NewPlanItem("pr-1-db", &differ.DiffResult{HasChanges: true, Summary: "1 resource modified", RawDiff: "+ change", Links: links}),
+		differ.NewPlanItem("pr-1-net", &differ.DiffResult{HasChanges: true, Summary: "1 resource modified", RawDiff: "+ change"}),
+	}, nil)
+	if strings.Count(multiple, "**Links:**") != 1 || !strings.Contains(multiple, want) {
+		t.Errorf("FormatMultipleDiffs() should show links only for the resource that has them:\n%s", multiple)
+	}
+}
+
+func TestGitHubFormatter_Sources(t *testing.T) {
+	formatter := NewGitHubFormatter()
+	sources := []differ.Link{
+		{Name: "clusters/prod/db.yaml", URL: "https://github.com/acme/platform/pull/1/files#diff-abc"},
+		{Name: "clusters/prod/values.yaml"},
+	}
+	want := "**Source:** [`clusters/prod/db.yaml`](https://github.com/acme/platform/pull/1/files#diff-abc) · `clusters/prod/values.yaml`"
+
+	multiple := formatter.FormatMultipleDiffs([]differ.PlanItem{
+		differ.NewPlanItem("pr-1-db", &differ.DiffResult{HasChanges: true, Summary: "1 resource modified", RawDiff: "+ change", Sources: sources}),
+		differ.NewPlanItem("pr-1-net", &differ.DiffResult{HasChanges: true, Summary: "1 resource modified", RawDiff: "+ change"}),
+	}, nil)
+	if strings.Count(multiple, "**Source:**") != 1 || !strings.Contains(multiple, want) {
+		t.Errorf("FormatMultipleDiffs() should show sources only for the resource that has them:\n%s", multiple)
+	}
+}
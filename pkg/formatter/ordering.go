@@ -0,0 +1,72 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+)
+
+// formatOrderingChanges lists the sync-wave and hook changes of the resources, which alter
+// apply ordering even when their specs don't change
+func formatOrderingChanges(b *strings.Builder, items []differ.PlanItem) {
+	var reordered []differ.PlanItem
+	for _, item := range items {
+		if len(item.OrderingChanges) > 0 {
+			reordered = append(reordered, item)
+		}
+	}
+	if len(reordered) == 0 {
+		return
+	}
+
+	b.WriteString("### ⏱️ Ordering & Hooks\n\n")
+	b.WriteString("These changes alter when ArgoCD applies the resources, or run them as hooks:\n\n")
+	b.WriteString("| Resource | Annotation | Before | After |\n")
+	b.WriteString("|----------|------------|--------|-------|\n")
+	for _, item := range reordered {
+		for _, change := range item.OrderingChanges {
+			b.WriteString(fmt.Sprintf("| `%s` | `%s` | %s | %s |\n", item.Name, change.Annotation, annotationValue(change.Before), annotationValue(change.After)))
+		}
+	}
+	b.WriteString("\n")
+}
+
+// annotationValue formats an annotation value for a table cell
+func annotationValue(value string) string {
+	if value == "" {
+		return "_unset_"
+	}
+	return "`" + value + "`"
+}
+
+// formatApplyOrder lists the changed resources in the order a merge would apply them, grouped by
+// sync wave, so reviewers can check migration sequences such as a subnet before its database
+func formatApplyOrder(b *strings.Builder, items []differ.PlanItem) {
+	steps := differ.ApplyOrder(items)
+	if len(steps) < 2 {
+		return
+	}
+
+	b.WriteString("### 🪜 Apply Order\n\n")
+	b.WriteString("On merge, ArgoCD applies the changes by sync wave, and within a wave after the resources they reference:\n\n")
+	for i, step := range steps {
+		if i == 0 || step.Deleted != steps[i-1].Deleted || (!step.Deleted && step.Wave != steps[i-1].Wave) {
+			if step.Deleted {
+				b.WriteString("\n**Pruned after the sync**\n\n")
+			} else {
+				b.WriteString(fmt.Sprintf("\n**Wave %d**\n\n", step.Wave))
+			}
+		}
+
+		b.WriteString(fmt.Sprintf("%d. `%s`", i+1, step.Name))
+		if step.Deleted && step.Wave != 0 {
+			b.WriteString(fmt.Sprintf(" (wave %d)", step.Wave))
+		}
+		if len(step.DependsOn) > 0 {
+			b.WriteString(fmt.Sprintf(" (after `%s`)", strings.Join(step.DependsOn, "`, `")))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+}
@@ -0,0 +1,81 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestGitHubFormatter_OrderingChanges(t *testing.T) {
+	xr := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.org/v1alpha1",
+		"kind":       "XDatabase",
+		"metadata":   map[string]interface{}{"name": "db"},
+	}}
+	result := &differ.DiffResult{
+		XR:         xr,
+		HasChanges: true,
+		Summary:    "Ordering changes for XDatabase/db",
+		OrderingChanges: []differ.OrderingChange{
+			{Annotation: "argocd.argoproj.io/sync-wave", Before: "2", After: "-1"},
+			{Annotation: "argocd.argoproj.io/hook", After: "PreSync"},
+		},
+	}
+
+	output := NewGitHubFormatter().FormatDiff(xr, result)
+
+	for _, want := range []string{
+		"### ⏱️ Ordering & Hooks",
+		"| `db` | `argocd.argoproj.io/sync-wave` | `2` | `-1` |",
+		"| `db` | `argocd.argoproj.io/hook` | _unset_ | `PreSync` |",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Missing %q:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "View Full Diff") {
+		t.Errorf("Empty diff rendered:\n%s", output)
+	}
+}
+
+func TestFormatApplyOrder(t *testing.T) {
+	xr := func(name, wave string, spec map[string]interface{}) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "example.org/v1alpha1",
+			"kind":       "XResource",
+			"metadata":   map[string]interface{}{"name": name},
+			"spec":       spec,
+		}}
+		if wave != "" {
+			u.SetAnnotations(map[string]string{"argocd.argoproj.io/sync-wave": wave})
+		}
+		return u
+	}
+	vpc := differ.NewPlanItem("vpc", &differ.DiffResult{HasChanges: true, XR: xr("vpc", "-1", nil)})
+	items := []differ.PlanItem{
+		vpc,
+		differ.NewPlanItem("db", &differ.DiffResult{HasChanges: true, XR: xr("db", "", map[string]interface{}{
+			"subnetRef": map[string]interface{}{"name": "subnet"},
+		})}),
+		differ.NewPlanItem("subnet", &differ.DiffResult{HasChanges: true, XR: xr("subnet", "", nil)}),
+	}
+	items = differ.AddDeletion(items, schema.GroupVersionKind{Kind: "XQueue"}, "", "old", &differ.DiffResult{HasChanges: true})
+
+	var b strings.Builder
+	formatApplyOrder(&b, items)
+	output := b.String()
+
+	want := "**Wave -1**\n\n1. `vpc`\n\n**Wave 0**\n\n2. `subnet`\n3. `db` (after `subnet`)\n\n**Pruned after the sync**\n\n4. `XQueue/old`\n"
+	if !strings.Contains(output, want) {
+		t.Errorf("Apply order = \n%s\nwant it to contain\n%s", output, want)
+	}
+
+	b.Reset()
+	formatApplyOrder(&b, []differ.PlanItem{vpc})
+	if b.Len() != 0 {
+		t.Errorf("Apply order rendered for a single resource:\n%s", b.String())
+	}
+}
@@ -0,0 +1,57 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+)
+
+// managerLabels describe well-known field managers, matched by prefix
+var managerLabels = []struct {
+	prefix string
+	label  string
+}{
+	{"argocd", "ArgoCD"},
+	{"crossplane", "Crossplane"},
+	{"apiextensions.crossplane.io", "Crossplane"},
+	{"kubectl", "kubectl (manual edit)"},
+	{"helm", "Helm"},
+	{"kustomize-controller", "Flux"},
+	{"helm-controller", "Flux"},
+}
+
+// describeManager returns a readable name for a field manager
+func describeManager(manager string) string {
+	for _, m := range managerLabels {
+		if strings.HasPrefix(manager, m.prefix) {
+			return fmt.Sprintf("%s (`%s`)", m.label, manager)
+		}
+	}
+	return fmt.Sprintf("`%s`", manager)
+}
+
+// formatFieldOwners lists who owns the production values of the fields a PR changes (collapsed)
+func formatFieldOwners(b *strings.Builder, owners []differ.FieldOwner) {
+	if len(owners) == 0 {
+		return
+	}
+
+	b.WriteString("<details>\n")
+	b.WriteString("<summary>👤 Field ownership in production</summary>\n\n")
+	b.WriteString("| Field | Owned by |\n")
+	b.WriteString("|-------|----------|\n")
+	for _, owner := range owners {
+		managers := "_unowned (new field)_"
+		if len(owner.Managers) > 0 {
+			described := make([]string, len(owner.Managers))
+			for i, manager := range owner.Managers {
+				described[i] = describeManager(manager)
+			}
+			managers = strings.Join(described, ", ")
+		}
+		b.WriteString(fmt.Sprintf("| `%s` | %s |\n", owner.Path, managers))
+	}
+	b.WriteString("\nFields owned by a manager other than your GitOps tool were likely edited out of band.\n")
+	b.WriteString("</details>\n\n")
+}
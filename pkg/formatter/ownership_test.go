@@ -0,0 +1,51 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestDescribeManager(t *testing.T) {
+	tests := []struct {
+		manager string
+		want    string
+	}{
+		{"argocd-controller", "ArgoCD (`argocd-controller`)"},
+		{"crossplane-composition", "Crossplane (`crossplane-composition`)"},
+		{"kubectl-edit", "kubectl (manual edit) (`kubectl-edit`)"},
+		{"terraform", "`terraform`"},
+	}
+	for _, tt := range tests {
+		if got := describeManager(tt.manager); got != tt.want {
+			t.Errorf("describeManager(%q) = %q, want %q", tt.manager, got, tt.want)
+		}
+	}
+}
+
+func TestGitHubFormatter_FieldOwners(t *testing.T) {
+	formatter := NewGitHubFormatter()
+
+	xr := &unstructured.Unstructured{}
+	xr.SetKind("XDatabase")
+	xr.SetName("db")
+	output := formatter.FormatDiff(xr, &differ.DiffResult{
+		XR:         xr,
+		HasChanges: true,
+		Summary:    "1 resource modified",
+		RawDiff:    "-     size: small\n+     size: large",
+		FieldOwners: []differ.FieldOwner{
+			{Path: "spec.parameters.size", Managers: []string{"kubectl-edit"}},
+			{Path: "spec.parameters.tier"},
+		},
+	})
+
+	if !strings.Contains(output, "| `spec.parameters.size` | kubectl (manual edit) (`kubectl-edit`) |") {
+		t.Errorf("Missing owned field:\n%s", output)
+	}
+	if !strings.Contains(output, "| `spec.parameters.tier` | _unowned (new field)_ |") {
+		t.Errorf("Missing unowned field:\n%s", output)
+	}
+}
@@ -0,0 +1,43 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+)
+
+// formatPipeline adds a collapsed overview of the captured composition function pipeline steps
+// The full desired state per step is too large for a comment and goes to the pipeline artifact
+func formatPipeline(b *strings.Builder, name string, result *differ.DiffResult) {
+	if result == nil || len(result.Pipeline) == 0 {
+		return
+	}
+
+	b.WriteString("\n<details>\n")
+	b.WriteString(fmt.Sprintf("<summary>🔬 Function pipeline for <code>%s</code></summary>\n\n", name))
+	for _, capture := range result.Pipeline {
+		b.WriteString(fmt.Sprintf("**%s** (composition `%s`)\n\n", capture.Composite, capture.Composition))
+		b.WriteString("| # | Step | Function | Composed resources | Results |\n")
+		b.WriteString("|---|------|----------|--------------------|---------|\n")
+		for i, step := range capture.Steps {
+			outcome := fmt.Sprintf("%d", len(step.Results))
+			if step.Error != "" {
+				outcome = "❌ " + escapeTableCell(step.Error)
+			}
+			b.WriteString(fmt.Sprintf("| %d | `%s` | `%s` | %d | %s |\n", i+1, step.Step, step.Function, len(step.Composed), outcome))
+		}
+		b.WriteString("\n")
+	}
+	if result.PipelineArtifact != "" {
+		b.WriteString(fmt.Sprintf("The desired state after each step is in `%s`.\n", result.PipelineArtifact))
+	} else {
+		b.WriteString("The desired state after each step is logged.\n")
+	}
+	b.WriteString("</details>\n\n")
+}
+
+// escapeTableCell keeps a value on one Markdown table row
+func escapeTableCell(s string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(s)
+}
@@ -0,0 +1,57 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxPlainBlockLines bounds code blocks in plain-text comments, which can't fold them away
+const maxPlainBlockLines = 50
+
+// WithPlainText formats comments for VCSes that don't render collapsible sections
+func WithPlainText(plain bool) Option {
+	return func(f *GitHubFormatter) { f.SetPlainText(plain) }
+}
+
+// SetPlainText replaces collapsible sections with bold titles and cuts code blocks to
+// maxPlainBlockLines lines, for VCSes that render <details> poorly
+func (f *GitHubFormatter) SetPlainText(plain bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.plainText = plain
+}
+
+// flattenCollapsibles replaces collapsible sections with their bold title and content,
+// cutting code blocks longer than maxPlainBlockLines
+func flattenCollapsibles(text string) string {
+	lines := strings.Split(text, "\n")
+	out := make([]string, 0, len(lines))
+	inFence, blockLines := false, 0
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			if inFence && blockLines > maxPlainBlockLines {
+				out = append(out, fmt.Sprintf("... (%d more lines)", blockLines-maxPlainBlockLines))
+			}
+			inFence, blockLines = !inFence, 0
+			out = append(out, line)
+			continue
+		}
+		if inFence {
+			if blockLines++; blockLines <= maxPlainBlockLines {
+				out = append(out, line)
+			}
+			continue
+		}
+
+		switch trimmed := strings.TrimSpace(line); {
+		case trimmed == "<details>" || trimmed == "</details>":
+			continue
+		case strings.HasPrefix(trimmed, "<summary>") && strings.HasSuffix(trimmed, "</summary>"):
+			title := strings.TrimSuffix(strings.TrimPrefix(trimmed, "<summary>"), "</summary>")
+			out = append(out, "**"+title+"**")
+		default:
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
@@ -0,0 +1,47 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFlattenCollapsibles(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "collapsible section",
+			text: "### Modified\n<details>\n<summary>📝 View Diff</summary>\n\n```diff\n+ a\n```\n</details>\n",
+			want: "### Modified\n**📝 View Diff**\n\n```diff\n+ a\n```\n",
+		},
+		{
+			name: "summary in a code block",
+			text: "```\n<details>\n<summary>x</summary>\n```",
+			want: "```\n<details>\n<summary>x</summary>\n```",
+		},
+		{
+			name: "long code block",
+			text: "```diff\n" + strings.Repeat("+ a\n", maxPlainBlockLines+3) + "```",
+			want: "```diff\n" + strings.Repeat("+ a\n", maxPlainBlockLines) + fmt.Sprintf("... (%d more lines)\n```", 3),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := flattenCollapsibles(tt.text); got != tt.want {
+				t.Errorf("flattenCollapsibles() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitHubFormatter_PlainText(t *testing.T) {
+	f := NewGitHubFormatter(WithPlainText(true), WithStyle(Style{Headings: map[string]string{"View Diff": "Show changes"}}))
+	got := f.ApplyStyle("<details>\n<summary>📝 View Diff</summary>\n\n</details>")
+	if got != "**📝 Show changes**\n" {
+		t.Errorf("ApplyStyle() = %q, want the renamed title in bold", got)
+	}
+}
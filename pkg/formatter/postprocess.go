@@ -0,0 +1,61 @@
+package formatter
+
+import (
+	"regexp"
+	"strings"
+)
+
+// PostProcessor rewrites a rendered comment, for organization-specific tweaks
+type PostProcessor func(body string) string
+
+// linkURL matches the URLs of Markdown links and HTML href attributes
+var linkURL = regexp.MustCompile(`(\]\(|href=")([^)"\s]+)`)
+
+// ReplaceText replaces the matches of pattern anywhere in a comment, expanding $1 or ${name} in
+// the replacement
+func ReplaceText(pattern *regexp.Regexp, replacement string) PostProcessor {
+	return func(body string) string {
+		return pattern.ReplaceAllString(body, replacement)
+	}
+}
+
+// RewriteLinks replaces the matches of pattern in the URLs of a comment's links, e.g. to point
+// them at a GitHub Enterprise mirror
+func RewriteLinks(pattern *regexp.Regexp, replacement string) PostProcessor {
+	return func(body string) string {
+		return linkURL.ReplaceAllStringFunc(body, func(link string) string {
+			match := linkURL.FindStringSubmatch(link)
+			return match[1] + pattern.ReplaceAllString(match[2], replacement)
+		})
+	}
+}
+
+// AppendText adds Markdown at the end of a comment, separated by a blank line
+func AppendText(text string) PostProcessor {
+	return func(body string) string {
+		return strings.TrimRight(body, "\n") + "\n\n" + strings.TrimSpace(text) + "\n"
+	}
+}
+
+// WithPostProcessors sets the post-processors applied to comments, in order
+func WithPostProcessors(processors ...PostProcessor) Option {
+	return func(f *GitHubFormatter) { f.SetPostProcessors(processors) }
+}
+
+// SetPostProcessors replaces the post-processors applied to comments, in order
+func (f *GitHubFormatter) SetPostProcessors(processors []PostProcessor) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.postProcessors = processors
+}
+
+// PostProcess applies the post-processors to a rendered comment, in order
+func (f *GitHubFormatter) PostProcess(body string) string {
+	f.mu.RLock()
+	processors := f.postProcessors
+	f.mu.RUnlock()
+	for _, process := range processors {
+		body = process(body)
+	}
+	return body
+}
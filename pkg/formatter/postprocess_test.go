@@ -0,0 +1,48 @@
+package formatter
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestGitHubFormatter_PostProcess(t *testing.T) {
+	body := "## Crossplane Preview\n\nPlanned `prod-vpc` in prod.\n\n[`pr-1-vpc`](https://github.com/acme/infra/pull/1#crossplane-plan-pr-1-vpc) · <a href=\"https://github.com/acme/infra/blob/main/vpc.yaml\">vpc.yaml</a>\n"
+
+	tests := []struct {
+		name       string
+		processors []PostProcessor
+		want       string
+	}{
+		{
+			name: "none",
+			want: body,
+		},
+		{
+			name:       "replace",
+			processors: []PostProcessor{ReplaceText(regexp.MustCompile(`\bin (prod)\b`), "in ${1}uction")},
+			want:       "## Crossplane Preview\n\nPlanned `prod-vpc` in production.\n\n[`pr-1-vpc`](https://github.com/acme/infra/pull/1#crossplane-plan-pr-1-vpc) · <a href=\"https://github.com/acme/infra/blob/main/vpc.yaml\">vpc.yaml</a>\n",
+		},
+		{
+			name:       "rewrite links",
+			processors: []PostProcessor{RewriteLinks(regexp.MustCompile(`^https://github\.com/`), "https://ghe.example.com/")},
+			want:       "## Crossplane Preview\n\nPlanned `prod-vpc` in prod.\n\n[`pr-1-vpc`](https://ghe.example.com/acme/infra/pull/1#crossplane-plan-pr-1-vpc) · <a href=\"https://ghe.example.com/acme/infra/blob/main/vpc.yaml\">vpc.yaml</a>\n",
+		},
+		{
+			name: "chain",
+			processors: []PostProcessor{
+				AppendText("_Internal use only._\n"),
+				ReplaceText(regexp.MustCompile(`Internal`), "Confidential, internal"),
+			},
+			want: body + "\n_Confidential, internal use only._\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewGitHubFormatter(WithPostProcessors(tt.processors...))
+			if got := f.PostProcess(body); got != tt.want {
+				t.Errorf("PostProcess() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
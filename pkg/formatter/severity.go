@@ -0,0 +1,50 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+	"github.com/millstonehq/crossplane-plan/pkg/severity"
+)
+
+// severityBadges label the severity of plans in the dashboard and check runs
+var severityBadges = map[severity.Level]string{
+	severity.Info:        "🟢 info",
+	severity.Change:      "🟡 change",
+	severity.Destructive: "🔴 destructive",
+	severity.Error:       "❌ error",
+}
+
+// failedItems returns the items that could not be planned
+func failedItems(items []differ.PlanItem) []differ.PlanItem {
+	var failed []differ.PlanItem
+	for _, item := range items {
+		if item.Action == differ.ActionError {
+			failed = append(failed, item)
+		}
+	}
+	return failed
+}
+
+// formatFailures lists the resources that could not be planned, with their errors
+func formatFailures(b *strings.Builder, items []differ.PlanItem) {
+	failed := failedItems(items)
+	if len(failed) == 0 {
+		return
+	}
+
+	b.WriteString("### ❌ Failed Resources\n\n")
+	b.WriteString("These resources could not be planned, so their changes are not shown:\n\n")
+	for _, item := range failed {
+		b.WriteString(fmt.Sprintf("- **%s**: %s\n", item.Name, item.Summary))
+	}
+	b.WriteString("\n<details>\n")
+	b.WriteString("<summary>🐛 View Errors</summary>\n\n")
+	b.WriteString("```text\n")
+	for _, item := range failed {
+		b.WriteString(fmt.Sprintf("%s: %s\n", item.Name, item.Error))
+	}
+	b.WriteString("```\n")
+	b.WriteString("</details>\n\n")
+}
@@ -0,0 +1,39 @@
+package formatter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestGitHubFormatter_FailedResults(t *testing.T) {
+	xr := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.org/v1alpha1",
+		"kind":       "XQueue",
+		"metadata":   map[string]interface{}{"name": "pr-1-queue"},
+	}}
+	failed := differ.FailedResult(xr, errors.New("composition not found"))
+	formatter := NewGitHubFormatter()
+
+	output := formatter.FormatMultipleDiffs([]differ.PlanItem{
+		differ.NewPlanItem("pr-1-queue", failed),
+		differ.NewPlanItem("pr-1-vpc", &differ.DiffResult{HasChanges: false, Summary: "No changes"}),
+	}, nil)
+	for _, want := range []string{
+		"### ❌ Failed Resources",
+		"- **pr-1-queue**: Failed to plan",
+		"pr-1-queue: composition not found",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("FormatMultipleDiffs() missing %q:\n%s", want, output)
+		}
+	}
+
+	output = formatter.FormatDiff(xr, failed)
+	if !strings.Contains(output, "pr-1-queue: composition not found") || strings.Contains(output, "No Changes") {
+		t.Errorf("FormatDiff() of a failed result:\n%s", output)
+	}
+}
@@ -0,0 +1,161 @@
+package formatter
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// defaultTitle is the title of plan comments
+const defaultTitle = "Crossplane Preview"
+
+// Style sets the wording and decoration of formatted comments
+type Style struct {
+	// NoEmoji strips emoji from comments, except in code
+	NoEmoji bool
+
+	// Title replaces "Crossplane Preview" in headings (empty keeps it)
+	Title string
+
+	// Headings replace headings and collapsible section titles, keyed by their default text without emoji
+	Headings map[string]string
+}
+
+// WithStyle sets the wording and decoration of comments
+func WithStyle(style Style) Option {
+	return func(f *GitHubFormatter) { f.SetStyle(style) }
+}
+
+// SetStyle replaces the wording and decoration of comments
+func (f *GitHubFormatter) SetStyle(style Style) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.style = style
+}
+
+// ApplyStyle applies the style, and plain-text formatting if enabled, to markdown formatted outside
+// the formatter, e.g. a timing footer
+// Code blocks and inline code are left as is
+func (f *GitHubFormatter) ApplyStyle(text string) string {
+	f.mu.RLock()
+	style, plain := f.style, f.plainText
+	f.mu.RUnlock()
+	if style.NoEmoji || style.Title != "" || len(style.Headings) > 0 {
+		text = style.apply(text)
+	}
+	if plain {
+		text = flattenCollapsibles(text)
+	}
+	return text
+}
+
+// apply rewords and redecorates text outside of code
+func (s Style) apply(text string) string {
+	lines := strings.Split(text, "\n")
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if s.NoEmoji {
+			line = outsideInlineCode(line, stripEmoji)
+		}
+		lines[i] = s.renameHeading(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renameHeading replaces the text of a markdown heading or a collapsible section title,
+// keeping its emoji
+func (s Style) renameHeading(line string) string {
+	prefix, text, suffix, ok := cutHeading(line)
+	if !ok {
+		return line
+	}
+	decoration, text := cutLeadingEmoji(text)
+	if replacement, found := s.Headings[text]; found {
+		text = replacement
+	} else if text == defaultTitle && s.Title != "" {
+		text = s.Title
+	}
+	return prefix + decoration + text + suffix
+}
+
+// cutHeading splits a heading ("### text") or a collapsible section title ("<summary>text</summary>")
+// around its text
+func cutHeading(line string) (prefix, text, suffix string, ok bool) {
+	if hashes := len(line) - len(strings.TrimLeft(line, "#")); hashes > 0 {
+		if !strings.HasPrefix(line[hashes:], " ") {
+			return "", "", "", false
+		}
+		return line[:hashes+1], line[hashes+1:], "", true
+	}
+	if text, found := strings.CutPrefix(line, "<summary>"); found {
+		if text, found = strings.CutSuffix(text, "</summary>"); found {
+			return "<summary>", text, "</summary>", true
+		}
+	}
+	return "", "", "", false
+}
+
+// cutLeadingEmoji splits the emoji (and the spaces after them) off the start of text
+func cutLeadingEmoji(text string) (decoration, rest string) {
+	end := 0
+	for end < len(text) {
+		r, size := utf8.DecodeRuneInString(text[end:])
+		if !isEmoji(r) {
+			break
+		}
+		end += size
+	}
+	if end == 0 {
+		return "", text
+	}
+	rest = strings.TrimLeft(text[end:], " ")
+	return text[:len(text)-len(rest)], rest
+}
+
+// outsideInlineCode applies fn to the parts of a line outside `inline code`
+func outsideInlineCode(line string, fn func(string) string) string {
+	parts := strings.Split(line, "`")
+	for i := 0; i < len(parts); i += 2 {
+		parts[i] = fn(parts[i])
+	}
+	return strings.Join(parts, "`")
+}
+
+// stripEmoji removes emoji and the spaces following them
+func stripEmoji(s string) string {
+	var b strings.Builder
+	afterEmoji := false
+	for _, r := range s {
+		if isEmoji(r) {
+			afterEmoji = true
+			continue
+		}
+		if afterEmoji && r == ' ' {
+			continue
+		}
+		afterEmoji = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// isEmoji reports whether r is an emoji, or a variation selector or joiner of an emoji sequence
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF: // pictographs, emoticons, transport and map symbols
+		return true
+	case r >= 0x2600 && r <= 0x27BF: // miscellaneous symbols and dingbats
+		return true
+	case r >= 0x2B00 && r <= 0x2BFF: // arrows and shapes, e.g. ⬆️ and ⭐
+		return true
+	case r >= 0x23E9 && r <= 0x23FA: // media and clock symbols, e.g. ⏳ and ⏱️
+		return true
+	}
+	return r == 0x2139 || r == 0xFE0F || r == 0x200D // ℹ️, variation selector 16, zero width joiner
+}
@@ -0,0 +1,123 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestGitHubFormatter_ApplyStyle(t *testing.T) {
+	text := strings.Join([]string{
+		"## 🔄 Crossplane Preview",
+		"### 📋 Modified Resources",
+		"- **pr-1-vpc**: ⚠️  Resource will be **DELETED**",
+		"> **🛑 BLOCKED:** deletion of `🗑️ keep`",
+		"<summary>📝 View Diff</summary>",
+		"```diff",
+		"+ name: 🚀 launch",
+		"```",
+	}, "\n")
+
+	tests := []struct {
+		name  string
+		style Style
+		want  []string
+	}{
+		{
+			name:  "default",
+			style: Style{},
+			want:  strings.Split(text, "\n"),
+		},
+		{
+			name:  "no emoji",
+			style: Style{NoEmoji: true},
+			want: []string{
+				"## Crossplane Preview",
+				"### Modified Resources",
+				"- **pr-1-vpc**: Resource will be **DELETED**",
+				"> **BLOCKED:** deletion of `🗑️ keep`",
+				"<summary>View Diff</summary>",
+				"```diff",
+				"+ name: 🚀 launch",
+				"```",
+			},
+		},
+		{
+			name:  "terminology",
+			style: Style{Title: "Stack Preview", Headings: map[string]string{"Modified Resources": "Changed Stacks", "View Diff": "Show changes"}},
+			want: []string{
+				"## 🔄 Stack Preview",
+				"### 📋 Changed Stacks",
+				"- **pr-1-vpc**: ⚠️  Resource will be **DELETED**",
+				"> **🛑 BLOCKED:** deletion of `🗑️ keep`",
+				"<summary>📝 Show changes</summary>",
+				"```diff",
+				"+ name: 🚀 launch",
+				"```",
+			},
+		},
+		{
+			name:  "heading overrides title",
+			style: Style{NoEmoji: true, Title: "Stack Preview", Headings: map[string]string{"Crossplane Preview": "Plan"}},
+			want: []string{
+				"## Plan",
+				"### Modified Resources",
+				"- **pr-1-vpc**: Resource will be **DELETED**",
+				"> **BLOCKED:** deletion of `🗑️ keep`",
+				"<summary>View Diff</summary>",
+				"```diff",
+				"+ name: 🚀 launch",
+				"```",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewGitHubFormatter(WithStyle(tt.style)).ApplyStyle(text)
+			if want := strings.Join(tt.want, "\n"); got != want {
+				t.Errorf("ApplyStyle() =\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestGitHubFormatter_StyleAppliesToAllFormats(t *testing.T) {
+	f := NewGitHubFormatter(WithStyle(Style{NoEmoji: true, Title: "Stack Preview"}))
+	items := []differ.PlanItem{
+		differ.NewPlanItem("pr-1-vpc", &differ.DiffResult{HasChanges: true, Summary: "1 resource modified", RawDiff: "+ 🚀"}),
+	}
+	items = differ.AddDeletion(items, schema.GroupVersionKind{Kind: "XCache"}, "", "sessions", &differ.DiffResult{HasChanges: true, Summary: "⚠️  Resource will be **DELETED**"})
+
+	outputs := map[string]string{
+		"FormatMultipleDiffs": f.FormatMultipleDiffs(items, nil),
+		"FormatPlaceholder":   f.FormatPlaceholder(2),
+		"FormatFreezeNotice":  f.FormatFreezeNotice("release"),
+	}
+	for name, output := range outputs {
+		if strings.Contains(output, "Crossplane Preview") || strings.ContainsAny(stripCode(output), "🔄🗑️⚠️⏳🧊") {
+			t.Errorf("%s() not styled:\n%s", name, output)
+		}
+	}
+	if !strings.HasPrefix(outputs["FormatMultipleDiffs"], "## Stack Preview\n") {
+		t.Errorf("FormatMultipleDiffs() title not replaced:\n%s", outputs["FormatMultipleDiffs"])
+	}
+}
+
+// stripCode removes code blocks, whose emoji are left as is
+func stripCode(text string) string {
+	var kept []string
+	inFence := false
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(line, "```") {
+			inFence = !inFence
+			continue
+		}
+		if !inFence {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
@@ -0,0 +1,34 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// CommentData is the data available to comment templates
+type CommentData struct {
+	// Body is the formatted plan
+	Body string
+
+	// Repository is the repository the comment is posted to (format: owner/repo)
+	Repository string
+
+	// PRNumber is the pull request the plan belongs to
+	PRNumber int
+}
+
+// RenderTemplate wraps a formatted plan using a Go text/template
+func RenderTemplate(text string, data CommentData) (string, error) {
+	tmpl, err := template.New("comment").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse comment template: %w", err)
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render comment template: %w", err)
+	}
+
+	return b.String(), nil
+}
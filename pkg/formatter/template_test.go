@@ -0,0 +1,51 @@
+package formatter
+
+import "testing"
+
+func TestRenderTemplate(t *testing.T) {
+	data := CommentData{
+		Body:       "## 🔄 Crossplane Preview",
+		Repository: "millstonehq/platform",
+		PRNumber:   42,
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "body only",
+			template: "{{ .Body }}",
+			want:     "## 🔄 Crossplane Preview",
+		},
+		{
+			name:     "header and footer",
+			template: "Plan for {{ .Repository }}#{{ .PRNumber }}\n\n{{ .Body }}\n\n_Owned by @platform-team_",
+			want:     "Plan for millstonehq/platform#42\n\n## 🔄 Crossplane Preview\n\n_Owned by @platform-team_",
+		},
+		{
+			name:     "parse error",
+			template: "{{ .Body",
+			wantErr:  true,
+		},
+		{
+			name:     "unknown field",
+			template: "{{ .Missing }}",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderTemplate(tt.template, data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RenderTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("RenderTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
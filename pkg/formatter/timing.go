@@ -0,0 +1,44 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Timing is how long a plan took, broken down by phase
+type Timing struct {
+	Resources int
+	Total     time.Duration
+	Phases    []TimingPhase
+}
+
+// TimingPhase is the time spent in one phase of a plan (e.g., "diff")
+type TimingPhase struct {
+	Name     string
+	Duration time.Duration
+}
+
+// FormatTiming renders a one-line timing footer
+// Example: "rendered 12 resources in 8.3s: discovery 1.2s, diff 5.1s, ArgoCD 0.9s"
+func FormatTiming(t Timing) string {
+	noun := "resources"
+	if t.Resources == 1 {
+		noun = "resource"
+	}
+
+	line := fmt.Sprintf("rendered %d %s in %s", t.Resources, noun, formatSeconds(t.Total))
+	if len(t.Phases) > 0 {
+		phases := make([]string, 0, len(t.Phases))
+		for _, phase := range t.Phases {
+			phases = append(phases, phase.Name+" "+formatSeconds(phase.Duration))
+		}
+		line += ": " + strings.Join(phases, ", ")
+	}
+	return "<sub>⏱️ " + line + "</sub>"
+}
+
+// formatSeconds formats a duration as seconds with one decimal
+func formatSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.1fs", d.Seconds())
+}
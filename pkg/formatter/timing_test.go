@@ -0,0 +1,41 @@
+package formatter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatTiming(t *testing.T) {
+	tests := []struct {
+		name   string
+		timing Timing
+		want   string
+	}{
+		{
+			name: "phases",
+			timing: Timing{
+				Resources: 12,
+				Total:     8300 * time.Millisecond,
+				Phases: []TimingPhase{
+					{Name: "discovery", Duration: 1200 * time.Millisecond},
+					{Name: "diff", Duration: 5100 * time.Millisecond},
+					{Name: "ArgoCD", Duration: 940 * time.Millisecond},
+				},
+			},
+			want: "<sub>⏱️ rendered 12 resources in 8.3s: discovery 1.2s, diff 5.1s, ArgoCD 0.9s</sub>",
+		},
+		{
+			name:   "single resource without phases",
+			timing: Timing{Resources: 1, Total: 450 * time.Millisecond},
+			want:   "<sub>⏱️ rendered 1 resource in 0.5s</sub>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatTiming(tt.timing); got != tt.want {
+				t.Errorf("FormatTiming() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/millstonehq/crossplane-plan/pkg/transport"
+)
+
+// shutdownTimeout bounds how long open connections may finish when the server stops
+const shutdownTimeout = 5 * time.Second
+
+// Options configure an HTTP endpoint (metrics, admin API, webhook receiver)
+type Options struct {
+	// Addr is the listen address, e.g. ":8080" or "127.0.0.1:9090"
+	Addr string
+
+	// TLSCertFile and TLSKeyFile enable HTTPS
+	TLSCertFile string
+	TLSKeyFile  string
+
+	// ClientCAFile requires clients to present a certificate signed by one of its CAs (mTLS)
+	// Requires TLS
+	ClientCAFile string
+
+	// AuthTokenFile requires "Authorization: Bearer <token>" matching the file's contents
+	AuthTokenFile string
+}
+
+// Server is an HTTP server with optional TLS, mTLS and bearer token auth
+type Server struct {
+	name   string
+	server *http.Server
+	tls    bool
+	logger logr.Logger
+}
+
+// New creates a Server serving handler with the given options
+// name identifies the endpoint in logs (e.g., "metrics")
+func New(name string, handler http.Handler, opts Options, logger logr.Logger) (*Server, error) {
+	if opts.Addr == "" {
+		return nil, fmt.Errorf("%s server: listen address is required", name)
+	}
+	if (opts.TLSCertFile == "") != (opts.TLSKeyFile == "") {
+		return nil, fmt.Errorf("%s server: TLS certificate and key must be set together", name)
+	}
+	if opts.ClientCAFile != "" && opts.TLSCertFile == "" {
+		return nil, fmt.Errorf("%s server: client CA requires a TLS certificate", name)
+	}
+
+	if opts.AuthTokenFile != "" {
+		token, err := readToken(opts.AuthTokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s server: %w", name, err)
+		}
+		handler = requireToken(handler, token)
+	}
+
+	server := &http.Server{
+		Addr:              opts.Addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	if opts.TLSCertFile != "" {
+		tlsConfig, err := transport.ServerTLSConfig(opts.TLSCertFile, opts.TLSKeyFile, opts.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s server: %w", name, err)
+		}
+		server.TLSConfig = tlsConfig
+	}
+
+	return &Server{
+		name:   name,
+		server: server,
+		tls:    server.TLSConfig != nil,
+		logger: logger.WithName(name),
+	}, nil
+}
+
+// Run serves until the context is cancelled, then shuts down gracefully
+func (s *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return fmt.Errorf("%s server: failed to listen: %w", s.name, err)
+	}
+	return s.Serve(ctx, listener)
+}
+
+// Serve serves on listener until the context is cancelled, then shuts down gracefully
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	errCh := make(chan error, 1)
+	go func() {
+		s.logger.Info("Serving", "addr", listener.Addr().String(), "tls", s.tls)
+		if s.tls {
+			// Certificates are already loaded into TLSConfig
+			errCh <- s.server.ServeTLS(listener, "", "")
+		} else {
+			errCh <- s.server.Serve(listener)
+		}
+	}()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return fmt.Errorf("%s server failed: %w", s.name, err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := s.server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("%s server shutdown: %w", s.name, err)
+	}
+	return nil
+}
+
+// readToken reads a bearer token from a file, ignoring surrounding whitespace
+func readToken(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read auth token: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("auth token file %s is empty", path)
+	}
+	return token, nil
+}
+
+// requireToken rejects requests without a matching bearer token
+func requireToken(next http.Handler, token string) http.Handler {
+	expected := []byte(token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), expected) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="crossplane-plan"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
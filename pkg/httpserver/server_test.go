@@ -0,0 +1,151 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+)
+
+// writeFile writes content to a file in a temp dir and returns its path
+func writeFile(t *testing.T, name string, content []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestNew_InvalidOptions(t *testing.T) {
+	tokenPath := writeFile(t, "token", []byte("secret\n"))
+	emptyToken := writeFile(t, "empty", []byte("  \n"))
+
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr string
+	}{
+		{name: "missing address", opts: Options{}, wantErr: "listen address is required"},
+		{name: "cert without key", opts: Options{Addr: ":0", TLSCertFile: "tls.crt"}, wantErr: "must be set together"},
+		{name: "client CA without TLS", opts: Options{Addr: ":0", ClientCAFile: "ca.crt"}, wantErr: "client CA requires a TLS certificate"},
+		{name: "missing token file", opts: Options{Addr: ":0", AuthTokenFile: "/nonexistent/token"}, wantErr: "failed to read auth token"},
+		{name: "empty token file", opts: Options{Addr: ":0", AuthTokenFile: emptyToken}, wantErr: "is empty"},
+		{name: "missing certificate", opts: Options{Addr: ":0", TLSCertFile: "/nonexistent/tls.crt", TLSKeyFile: "/nonexistent/tls.key", AuthTokenFile: tokenPath}, wantErr: "failed to load TLS certificate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New("test", http.NotFoundHandler(), tt.opts, logr.Discard())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("New() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRequireToken(t *testing.T) {
+	handler := requireToken(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}), "secret")
+
+	tests := []struct {
+		name          string
+		authorization string
+		want          int
+	}{
+		{name: "valid token", authorization: "Bearer secret", want: http.StatusNoContent},
+		{name: "wrong token", authorization: "Bearer nope", want: http.StatusUnauthorized},
+		{name: "token prefix", authorization: "Bearer secre", want: http.StatusUnauthorized},
+		{name: "missing header", authorization: "", want: http.StatusUnauthorized},
+		{name: "basic auth", authorization: "Basic c2VjcmV0", want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_ServeTLSWithToken(t *testing.T) {
+	// Reuse the httptest certificate as the serving certificate
+	ts := httptest.NewUnstartedServer(nil)
+	ts.StartTLS()
+	cert := ts.TLS.Certificates[0]
+	ts.Close()
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]})
+	opts := Options{
+		Addr:          "127.0.0.1:0",
+		TLSCertFile:   writeFile(t, "tls.crt", certPEM),
+		TLSKeyFile:    writeFile(t, "tls.key", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})),
+		AuthTokenFile: writeFile(t, "token", []byte("secret")),
+	}
+
+	server, err := New("test", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}), opts, logr.Discard())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	listener, err := net.Listen("tcp", opts.Addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(ctx, listener) }()
+
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(certPEM)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+	url := "https://" + listener.Addr().String() + "/metrics"
+
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("status without token = %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("status with token = %d, want %d", resp.StatusCode, http.StatusNoContent)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Serve() error = %v, want nil after shutdown", err)
+	}
+}
@@ -0,0 +1,312 @@
+// Package loadtest synthesizes PR XRs in an API server and measures how fast and with how
+// much memory the plan pipeline processes them
+package loadtest
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/millstonehq/crossplane-plan/pkg/workqueue"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// Group is the API group of the synthesized XR kinds
+const Group = "loadtest.crossplane-plan.io"
+
+// NamePattern is the name-detector pattern of the synthesized PR XRs
+const NamePattern = "pr-{number}-*"
+
+// memorySampleInterval is how often the heap is sampled for its peak
+const memorySampleInterval = 50 * time.Millisecond
+
+// Options size a load test
+type Options struct {
+	// PRs is the number of PRs (N)
+	PRs int
+
+	// GVRs is the number of XR kinds the PRs' XRs are spread across (M)
+	GVRs int
+
+	// XRsPerPR is the number of XRs of each PR
+	XRsPerPR int
+}
+
+var (
+	crdGVR = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+	xrdGVR = schema.GroupVersionResource{Group: "apiextensions.crossplane.io", Version: "v1", Resource: "compositeresourcedefinitions"}
+)
+
+// Kind returns the name of the i-th synthesized XR kind
+func Kind(i int) string {
+	return fmt.Sprintf("XLoad%d", i)
+}
+
+// GVR returns the resource of the i-th synthesized XR kind
+func GVR(i int) schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: Group, Version: "v1alpha1", Resource: strings.ToLower(Kind(i)) + "s"}
+}
+
+// CRDs returns the CRDs a load test installs: the Crossplane kinds the pipeline reads, and one
+// cluster-scoped CRD per synthesized XR kind
+func CRDs(opts Options) []*unstructured.Unstructured {
+	crds := []*unstructured.Unstructured{
+		crd("apiextensions.crossplane.io", "CompositeResourceDefinition", "compositeresourcedefinitions", "v1"),
+		crd("apiextensions.crossplane.io", "Composition", "compositions", "v1"),
+		crd("apiextensions.crossplane.io", "CompositionRevision", "compositionrevisions", "v1"),
+		crd("apiextensions.crossplane.io", "EnvironmentConfig", "environmentconfigs", "v1beta1"),
+		crd("pkg.crossplane.io", "Function", "functions", "v1"),
+	}
+	for i := 0; i < opts.GVRs; i++ {
+		gvr := GVR(i)
+		crds = append(crds, crd(gvr.Group, Kind(i), gvr.Resource, gvr.Version))
+	}
+	return crds
+}
+
+// crd returns a cluster-scoped CRD accepting any fields
+func crd(group, kind, plural, version string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]interface{}{"name": plural + "." + group},
+		"spec": map[string]interface{}{
+			"group": group,
+			"scope": "Cluster",
+			"names": map[string]interface{}{
+				"kind":     kind,
+				"listKind": kind + "List",
+				"plural":   plural,
+				"singular": strings.ToLower(kind),
+			},
+			"versions": []interface{}{map[string]interface{}{
+				"name":    version,
+				"served":  true,
+				"storage": true,
+				"schema": map[string]interface{}{
+					"openAPIV3Schema": map[string]interface{}{
+						"type":                                 "object",
+						"x-kubernetes-preserve-unknown-fields": true,
+					},
+				},
+			}},
+		},
+	}}
+}
+
+// XRDs returns one XRD per synthesized XR kind, so the watcher discovers them
+func XRDs(opts Options) []*unstructured.Unstructured {
+	xrds := make([]*unstructured.Unstructured, 0, opts.GVRs)
+	for i := 0; i < opts.GVRs; i++ {
+		gvr := GVR(i)
+		xrds = append(xrds, &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apiextensions.crossplane.io/v1",
+			"kind":       "CompositeResourceDefinition",
+			"metadata":   map[string]interface{}{"name": gvr.Resource + "." + gvr.Group},
+			"spec": map[string]interface{}{
+				"group":    gvr.Group,
+				"names":    map[string]interface{}{"kind": Kind(i), "plural": gvr.Resource},
+				"versions": []interface{}{map[string]interface{}{"name": gvr.Version, "served": true, "referenceable": true}},
+			},
+		}})
+	}
+	return xrds
+}
+
+// XRs returns the synthesized XRs: for every PR, XRsPerPR XRs spread round-robin across the
+// kinds, each with a production counterpart whose size differs so every diff has changes
+func XRs(opts Options) map[schema.GroupVersionResource][]*unstructured.Unstructured {
+	xrs := make(map[schema.GroupVersionResource][]*unstructured.Unstructured)
+	for pr := 1; pr <= opts.PRs; pr++ {
+		for j := 0; j < opts.XRsPerPR; j++ {
+			kind := (pr*opts.XRsPerPR + j) % opts.GVRs
+			base := fmt.Sprintf("xr-%d-%d", pr, j)
+			xrs[GVR(kind)] = append(xrs[GVR(kind)],
+				xr(kind, base, "small"),
+				xr(kind, fmt.Sprintf("pr-%d-%s", pr, base), "large"),
+			)
+		}
+	}
+	return xrs
+}
+
+// xr returns a synthesized XR
+func xr(kind int, name, size string) *unstructured.Unstructured {
+	gvr := GVR(kind)
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": gvr.Group + "/" + gvr.Version,
+		"kind":       Kind(kind),
+		"metadata":   map[string]interface{}{"name": name},
+		"spec": map[string]interface{}{
+			"parameters": map[string]interface{}{"size": size, "region": "us-east-1"},
+		},
+	}}
+}
+
+// Setup installs the CRDs, XRDs and XRs of a load test
+func Setup(ctx context.Context, client dynamic.Interface, opts Options) error {
+	for _, obj := range CRDs(opts) {
+		if err := create(ctx, client.Resource(crdGVR), obj); err != nil {
+			return err
+		}
+	}
+	// CRDs are served asynchronously once established
+	for i := 0; i < opts.GVRs; i++ {
+		if err := waitServed(ctx, client, GVR(i)); err != nil {
+			return err
+		}
+	}
+	if err := waitServed(ctx, client, xrdGVR); err != nil {
+		return err
+	}
+
+	for _, obj := range XRDs(opts) {
+		if err := create(ctx, client.Resource(xrdGVR), obj); err != nil {
+			return err
+		}
+	}
+	for gvr, objs := range XRs(opts) {
+		for _, obj := range objs {
+			if err := create(ctx, client.Resource(gvr), obj); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// create creates an object, ignoring objects that already exist
+func create(ctx context.Context, client dynamic.ResourceInterface, obj *unstructured.Unstructured) error {
+	if _, err := client.Create(ctx, obj, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create %s %s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+	return nil
+}
+
+// waitServed waits until a resource can be listed
+func waitServed(ctx context.Context, client dynamic.Interface, gvr schema.GroupVersionResource) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	for {
+		_, err := client.Resource(gvr).List(ctx, metav1.ListOptions{Limit: 1})
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s not served: %w", gvr.String(), err)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// Run plans every PR once, one after the other, and reports their latency and memory use
+func Run(ctx context.Context, processor workqueue.PRProcessor, opts Options) *Report {
+	sampler := startMemorySampler()
+	start := time.Now()
+
+	latencies := make([]time.Duration, 0, opts.PRs)
+	report := &Report{PRs: opts.PRs, GVRs: opts.GVRs, XRs: opts.PRs * opts.XRsPerPR}
+	for pr := 1; pr <= opts.PRs; pr++ {
+		planStart := time.Now()
+		if err := processor.ProcessPR(ctx, workqueue.PR{Number: pr}); err != nil {
+			report.Errors++
+		}
+		latencies = append(latencies, time.Since(planStart))
+	}
+
+	report.DurationSeconds = time.Since(start).Seconds()
+	report.Latency = summarize(latencies)
+	report.PeakHeapBytes, report.AllocatedBytes = sampler.stop()
+	return report
+}
+
+// memorySampler tracks the peak heap while a load test runs
+type memorySampler struct {
+	done       chan struct{}
+	wg         sync.WaitGroup
+	peak       uint64
+	allocStart uint64
+}
+
+// startMemorySampler starts sampling the heap
+func startMemorySampler() *memorySampler {
+	var stats runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&stats)
+	s := &memorySampler{done: make(chan struct{}), peak: stats.HeapInuse, allocStart: stats.TotalAlloc}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(memorySampleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.done:
+				return
+			case <-ticker.C:
+				s.sample()
+			}
+		}
+	}()
+	return s
+}
+
+// sample records the current heap if it is the largest so far
+func (s *memorySampler) sample() uint64 {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	if stats.HeapInuse > s.peak {
+		s.peak = stats.HeapInuse
+	}
+	return stats.TotalAlloc
+}
+
+// stop stops sampling and returns the peak heap and the bytes allocated since the start
+func (s *memorySampler) stop() (peak, allocated uint64) {
+	close(s.done)
+	s.wg.Wait()
+	total := s.sample()
+	return s.peak, total - s.allocStart
+}
+
+// summarize computes latency percentiles in milliseconds
+func summarize(latencies []time.Duration) Latency {
+	if len(latencies) == 0 {
+		return Latency{}
+	}
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, latency := range sorted {
+		total += latency
+	}
+	percentile := func(p float64) float64 {
+		index := int(p*float64(len(sorted))+0.5) - 1
+		if index < 0 {
+			index = 0
+		}
+		return millis(sorted[index])
+	}
+	return Latency{
+		P50:  percentile(0.50),
+		P95:  percentile(0.95),
+		Max:  millis(sorted[len(sorted)-1]),
+		Mean: millis(total / time.Duration(len(sorted))),
+	}
+}
+
+// millis converts a duration to fractional milliseconds
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,123 @@
+package loadtest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/millstonehq/crossplane-plan/pkg/detector"
+	"github.com/millstonehq/crossplane-plan/pkg/workqueue"
+)
+
+func TestXRs(t *testing.T) {
+	opts := Options{PRs: 4, GVRs: 3, XRsPerPR: 2}
+	nameDetector := detector.NewNameDetector(NamePattern)
+
+	prXRs := make(map[int]int)
+	total := 0
+	for gvr, xrs := range XRs(opts) {
+		if gvr.Group != Group {
+			t.Errorf("XR resource %s outside group %s", gvr, Group)
+		}
+		for _, xr := range xrs {
+			total++
+			if pr := nameDetector.DetectPR(xr); pr != 0 {
+				prXRs[pr]++
+				if got := nameDetector.GetBaseName(xr); !strings.HasPrefix(got, "xr-") {
+					t.Errorf("base name of %s = %q, want its production XR", xr.GetName(), got)
+				}
+			}
+		}
+	}
+
+	// Every PR XR has a production counterpart
+	if want := 2 * opts.PRs * opts.XRsPerPR; total != want {
+		t.Errorf("XRs() created %d XRs, want %d", total, want)
+	}
+	for pr := 1; pr <= opts.PRs; pr++ {
+		if prXRs[pr] != opts.XRsPerPR {
+			t.Errorf("PR %d has %d XRs, want %d", pr, prXRs[pr], opts.XRsPerPR)
+		}
+	}
+	if len(XRDs(opts)) != opts.GVRs || len(CRDs(opts)) != opts.GVRs+5 {
+		t.Errorf("got %d XRDs and %d CRDs for %d GVRs", len(XRDs(opts)), len(CRDs(opts)), opts.GVRs)
+	}
+}
+
+type fakeProcessor struct {
+	failing map[int]bool
+	calls   int
+}
+
+func (f *fakeProcessor) ProcessPR(ctx context.Context, pr workqueue.PR) error {
+	f.calls++
+	time.Sleep(time.Millisecond)
+	if f.failing[pr.Number] {
+		return fmt.Errorf("plan failed")
+	}
+	return nil
+}
+
+func TestRun(t *testing.T) {
+	processor := &fakeProcessor{failing: map[int]bool{2: true}}
+	report := Run(context.Background(), processor, Options{PRs: 3, GVRs: 2, XRsPerPR: 2})
+
+	if processor.calls != 3 || report.Errors != 1 || report.XRs != 6 {
+		t.Errorf("Run() = %+v after %d calls, want 3 calls, 1 error and 6 XRs", report, processor.calls)
+	}
+	if report.Latency.P50 < 1 || report.Latency.Max < report.Latency.P50 || report.PeakHeapBytes == 0 {
+		t.Errorf("Run() latency %+v, peak heap %d", report.Latency, report.PeakHeapBytes)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	var latencies []time.Duration
+	for i := 100; i >= 1; i-- {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+	got := summarize(latencies)
+	want := Latency{P50: 50, P95: 95, Max: 100, Mean: 50.5}
+	if got != want {
+		t.Errorf("summarize() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRegressions(t *testing.T) {
+	baseline := &Report{PRs: 10, GVRs: 2, XRs: 20, Latency: Latency{P50: 100, P95: 200}, PeakHeapBytes: 100 << 20}
+
+	tests := []struct {
+		name    string
+		current Report
+		want    []string
+	}{
+		{
+			name:    "within tolerance",
+			current: Report{PRs: 10, GVRs: 2, XRs: 20, Latency: Latency{P50: 110, P95: 150}, PeakHeapBytes: 115 << 20},
+		},
+		{
+			name:    "slower and larger",
+			current: Report{PRs: 10, GVRs: 2, XRs: 20, Latency: Latency{P50: 150, P95: 200}, PeakHeapBytes: 200 << 20, Errors: 1},
+			want: []string{
+				"p50 latency (ms) regressed by 50% (100.0 -> 150.0)",
+				"peak heap (MiB) regressed by 100% (100.0 -> 200.0)",
+				"failed plans increased (0 -> 1)",
+			},
+		},
+		{
+			name:    "different size",
+			current: Report{PRs: 20, GVRs: 2, XRs: 40},
+			want:    []string{"baseline size (10 PRs, 2 GVRs, 20 XRs) differs from this run (20 PRs, 2 GVRs, 40 XRs)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Regressions(baseline, &tt.current, 0.2)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Regressions() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package loadtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Report is the CI-friendly result of a load test
+type Report struct {
+	PRs  int `json:"prs"`
+	GVRs int `json:"gvrs"`
+	XRs  int `json:"xrs"`
+
+	// Errors is the number of PRs whose plan failed
+	Errors int `json:"errors"`
+
+	// Latency of a PR's plan, in milliseconds
+	Latency Latency `json:"latencyMillis"`
+
+	DurationSeconds float64 `json:"durationSeconds"`
+
+	// PeakHeapBytes is the largest sampled in-use heap
+	PeakHeapBytes uint64 `json:"peakHeapBytes"`
+
+	// AllocatedBytes is the total allocated during the run
+	AllocatedBytes uint64 `json:"allocatedBytes"`
+}
+
+// Latency summarizes plan latencies in milliseconds
+type Latency struct {
+	P50  float64 `json:"p50"`
+	P95  float64 `json:"p95"`
+	Max  float64 `json:"max"`
+	Mean float64 `json:"mean"`
+}
+
+// LoadReport reads a report, e.g. the baseline of a previous run
+func LoadReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report: %w", err)
+	}
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse report %s: %w", path, err)
+	}
+	return &report, nil
+}
+
+// Write writes the report as JSON
+func (r *Report) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// Regressions compares a report to a baseline of the same size and describes the metrics that
+// grew by more than tolerance (e.g. 0.2 for 20%)
+func Regressions(baseline, current *Report, tolerance float64) []string {
+	var regressions []string
+	if baseline.PRs != current.PRs || baseline.GVRs != current.GVRs || baseline.XRs != current.XRs {
+		return []string{fmt.Sprintf("baseline size (%d PRs, %d GVRs, %d XRs) differs from this run (%d PRs, %d GVRs, %d XRs)",
+			baseline.PRs, baseline.GVRs, baseline.XRs, current.PRs, current.GVRs, current.XRs)}
+	}
+
+	check := func(name string, before, after float64) {
+		if before > 0 && after > before*(1+tolerance) {
+			regressions = append(regressions, fmt.Sprintf("%s regressed by %.0f%% (%.1f -> %.1f)", name, (after/before-1)*100, before, after))
+		}
+	}
+	check("p50 latency (ms)", baseline.Latency.P50, current.Latency.P50)
+	check("p95 latency (ms)", baseline.Latency.P95, current.Latency.P95)
+	check("peak heap (MiB)", mib(baseline.PeakHeapBytes), mib(current.PeakHeapBytes))
+	check("allocated (MiB)", mib(baseline.AllocatedBytes), mib(current.AllocatedBytes))
+	if current.Errors > baseline.Errors {
+		regressions = append(regressions, fmt.Sprintf("failed plans increased (%d -> %d)", baseline.Errors, current.Errors))
+	}
+	return regressions
+}
+
+// mib converts bytes to MiB
+func mib(bytes uint64) float64 {
+	return float64(bytes) / (1 << 20)
+}
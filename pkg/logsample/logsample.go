@@ -0,0 +1,113 @@
+package logsample
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/utils/clock"
+)
+
+// DetailLevel is the verbosity at which hot-path messages are all logged, unsampled
+const DetailLevel = 2
+
+// Sampler rate-limits the Info messages of a hot path, e.g. one per XR event
+// Each message is logged at most once per interval, with the number of occurrences suppressed
+// since it was last logged; with the logger at DetailLevel, every occurrence is logged
+type Sampler struct {
+	logger   logr.Logger
+	interval time.Duration
+	clock    clock.PassiveClock
+
+	mu         sync.Mutex
+	last       map[string]time.Time // when each message was last logged
+	suppressed map[string]int       // occurrences of each message since
+}
+
+// NewSampler creates a Sampler logging each message through logger at most once per interval
+// (0 to log every occurrence)
+func NewSampler(logger logr.Logger, interval time.Duration, clk clock.PassiveClock) *Sampler {
+	return &Sampler{
+		logger:     logger,
+		interval:   interval,
+		clock:      clk,
+		last:       make(map[string]time.Time),
+		suppressed: make(map[string]int),
+	}
+}
+
+// Info logs a message unless it was logged less than an interval ago
+func (s *Sampler) Info(msg string, keysAndValues ...interface{}) {
+	if s.interval <= 0 || s.logger.V(DetailLevel).Enabled() {
+		s.logger.Info(msg, keysAndValues...)
+		return
+	}
+
+	s.mu.Lock()
+	now := s.clock.Now()
+	if last, ok := s.last[msg]; ok && now.Sub(last) < s.interval {
+		s.suppressed[msg]++
+		s.mu.Unlock()
+		return
+	}
+	suppressed := s.suppressed[msg]
+	s.last[msg] = now
+	delete(s.suppressed, msg)
+	s.mu.Unlock()
+
+	if suppressed > 0 {
+		keysAndValues = append(keysAndValues, "suppressed", suppressed)
+	}
+	s.logger.Info(msg, keysAndValues...)
+}
+
+// Counts aggregates hot-path events by key, e.g. XR events by GVR, to log them once per cycle
+type Counts struct {
+	mu     sync.Mutex
+	counts map[string]map[string]int
+}
+
+// NewCounts creates empty Counts
+func NewCounts() *Counts {
+	return &Counts{counts: make(map[string]map[string]int)}
+}
+
+// Add counts an event of a key
+func (c *Counts) Add(key, event string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counts[key] == nil {
+		c.counts[key] = make(map[string]int)
+	}
+	c.counts[key][event]++
+}
+
+// Flush logs a message per key, with keyName set to the key and the count of each of its events,
+// and resets the counts
+// Keys are logged in order, and nothing is logged without events
+func (c *Counts) Flush(logger logr.Logger, msg, keyName string) {
+	c.mu.Lock()
+	counts := c.counts
+	c.counts = make(map[string]map[string]int)
+	c.mu.Unlock()
+
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		events := make([]string, 0, len(counts[key]))
+		for event := range counts[key] {
+			events = append(events, event)
+		}
+		sort.Strings(events)
+
+		keysAndValues := []interface{}{keyName, key}
+		for _, event := range events {
+			keysAndValues = append(keysAndValues, event, counts[key][event])
+		}
+		logger.Info(msg, keysAndValues...)
+	}
+}
@@ -0,0 +1,87 @@
+package logsample
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+// recorder returns a logger at verbosity recording its messages
+func recorder(verbosity int) (logr.Logger, *[]string) {
+	var lines []string
+	logger := funcr.New(func(prefix, args string) {
+		lines = append(lines, args)
+	}, funcr.Options{Verbosity: verbosity})
+	return logger, &lines
+}
+
+func TestSampler_Info(t *testing.T) {
+	clk := clocktesting.NewFakePassiveClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	logger, lines := recorder(1)
+	sampler := NewSampler(logger, time.Minute, clk)
+
+	sampler.Info("Processing XR event", "name", "pr-1-a")
+	sampler.Info("Processing XR event", "name", "pr-1-b")
+	sampler.Info("Detected deletion", "resource", "vpc")
+	sampler.Info("Processing XR event", "name", "pr-1-c")
+	clk.SetTime(clk.Now().Add(time.Minute))
+	sampler.Info("Processing XR event", "name", "pr-1-d")
+	sampler.Info("Processing XR event", "name", "pr-1-e")
+
+	want := []string{
+		`"level"=0 "msg"="Processing XR event" "name"="pr-1-a"`,
+		`"level"=0 "msg"="Detected deletion" "resource"="vpc"`,
+		`"level"=0 "msg"="Processing XR event" "name"="pr-1-d" "suppressed"=2`,
+	}
+	if !reflect.DeepEqual(*lines, want) {
+		t.Errorf("logged %q, want %q", *lines, want)
+	}
+}
+
+func TestSampler_Info_Unsampled(t *testing.T) {
+	clk := clocktesting.NewFakePassiveClock(time.Now())
+	tests := []struct {
+		name      string
+		verbosity int
+		interval  time.Duration
+	}{
+		{name: "no interval", verbosity: 0, interval: 0},
+		{name: "detail level", verbosity: DetailLevel, interval: time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, lines := recorder(tt.verbosity)
+			sampler := NewSampler(logger, tt.interval, clk)
+			for i := 0; i < 3; i++ {
+				sampler.Info("Processing XR event")
+			}
+			if len(*lines) != 3 {
+				t.Errorf("logged %d messages, want 3", len(*lines))
+			}
+		})
+	}
+}
+
+func TestCounts_Flush(t *testing.T) {
+	logger, lines := recorder(0)
+	counts := NewCounts()
+	counts.Add("example.org/v1, Resource=xnetworks", "MODIFIED")
+	counts.Add("example.org/v1, Resource=xnetworks", "ADDED")
+	counts.Add("example.org/v1, Resource=xnetworks", "MODIFIED")
+	counts.Add("example.org/v1, Resource=xcaches", "DELETED")
+
+	counts.Flush(logger, "XR events", "gvr")
+	counts.Flush(logger, "XR events", "gvr")
+
+	want := []string{
+		`"level"=0 "msg"="XR events" "gvr"="example.org/v1, Resource=xcaches" "DELETED"=1`,
+		`"level"=0 "msg"="XR events" "gvr"="example.org/v1, Resource=xnetworks" "ADDED"=1 "MODIFIED"=2`,
+	}
+	if !reflect.DeepEqual(*lines, want) {
+		t.Errorf("logged %q, want %q", *lines, want)
+	}
+}
@@ -0,0 +1,225 @@
+package metrics
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+)
+
+const namespace = "crossplane_plan"
+
+// Registry holds all crossplane-plan metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	// SanitizedXRs counts XRs passed through the sanitizer
+	SanitizedXRs = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "sanitizer",
+		Name:      "xrs_total",
+		Help:      "Number of XRs sanitized before diffing",
+	})
+
+	// StripRuleHits counts how often each strip rule removed a field
+	StripRuleHits = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "sanitizer",
+		Name:      "strip_rule_hits_total",
+		Help:      "Number of times a strip rule removed a field from an XR",
+	}, []string{"path", "reason"})
+
+	// DiffEngineRecycles counts diff engines replaced after repeated failures
+	DiffEngineRecycles = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "differ",
+		Name:      "engine_recycles_total",
+		Help:      "Number of diff engines recycled after consecutive failed diffs",
+	})
+
+	// DiffEngineRuns counts successful diffs by engine (crossplane-diff, dry-run, plugin)
+	DiffEngineRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "differ",
+		Name:      "engine_runs_total",
+		Help:      "Number of XRs diffed by each diff engine (crossplane-diff, dry-run, plugin)",
+	}, []string{"engine"})
+
+	// Plans counts the PR plans computed
+	Plans = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "plans_total",
+		Help:      "Number of PR plans computed",
+	})
+
+	// VCSCircuitState is the state of the VCS circuit breaker
+	VCSCircuitState = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "vcs",
+		Name:      "circuit_breaker_state",
+		Help:      "State of the VCS circuit breaker (0 closed, 1 open, 2 half-open)",
+	})
+
+	// VCSCircuitOpens counts how often the VCS circuit breaker opened
+	VCSCircuitOpens = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "vcs",
+		Name:      "circuit_breaker_opens_total",
+		Help:      "Number of times the VCS circuit breaker opened after consecutive failures",
+	})
+
+	// VCSCircuitRejections counts VCS calls skipped while the circuit breaker was open
+	VCSCircuitRejections = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "vcs",
+		Name:      "circuit_breaker_rejections_total",
+		Help:      "Number of VCS calls skipped because the circuit breaker was open",
+	})
+
+	// Errors counts failed plans by component and error class
+	Errors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "errors_total",
+		Help:      "Number of errors by component (differ, argocd, vcs, store) and class (auth, rate_limited, diff_engine, not_found, config, unknown)",
+	}, []string{"component", "class"})
+
+	// Shed counts the work dropped to stay within the resource limits, by kind (xr, diff)
+	Shed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "shed_total",
+		Help:      "Number of XRs left unplanned (xr) and diffs omitted from comments (diff) because a plan exceeded --max-batch-xrs or --max-diffs",
+	}, []string{"kind"})
+
+	// StateEvictions counts the PRs whose state was garbage-collected, by reason (closed, expired, absent)
+	StateEvictions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "state_evictions_total",
+		Help:      "Number of PRs whose state was garbage-collected because the PR was closed (closed), its last plan exceeded --state-ttl (expired), or it had no XRs left (absent)",
+	}, []string{"reason"})
+
+	// MissedEvents counts the PRs reconciliation replanned because their XRs changed without a watch event
+	MissedEvents = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "missed_events_total",
+		Help:      "Number of PRs replanned by periodic reconciliation because the resourceVersions of their XRs changed since their last plan without a watch event",
+	})
+
+	// AbandonedPRs counts the PRs whose processing was abandoned after the shutdown grace period
+	AbandonedPRs = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "abandoned_prs_total",
+		Help:      "Number of PRs whose processing was still running after --shutdown-grace-period on shutdown or leadership loss, left for the next leader to replan",
+	})
+
+	// ReadOnlyRejections counts the requests the read-only guard rejected, by HTTP method
+	ReadOnlyRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "readonly_rejections_total",
+		Help:      "Number of Kubernetes requests the read-only guard rejected because they could modify the cluster",
+	}, []string{"method"})
+)
+
+func init() {
+	Registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		SanitizedXRs,
+		StripRuleHits,
+		DiffEngineRecycles,
+		DiffEngineRuns,
+		Plans,
+		VCSCircuitState,
+		VCSCircuitOpens,
+		VCSCircuitRejections,
+		Errors,
+		Shed,
+		StateEvictions,
+		MissedEvents,
+		AbandonedPRs,
+		ReadOnlyRejections,
+	)
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// RuleHits is the number of times a strip rule fired
+type RuleHits struct {
+	Path   string
+	Reason string
+	Hits   int64
+}
+
+// StripRuleHitCounts returns the hit count of every strip rule seen since startup,
+// least used first so dead rules stand out
+func StripRuleHitCounts() []RuleHits {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		StripRuleHits.Collect(ch)
+		close(ch)
+	}()
+
+	var counts []RuleHits
+	for metric := range ch {
+		var m dto.Metric
+		if err := metric.Write(&m); err != nil {
+			continue
+		}
+
+		hits := RuleHits{Hits: int64(m.GetCounter().GetValue())}
+		for _, label := range m.GetLabel() {
+			switch label.GetName() {
+			case "path":
+				hits.Path = label.GetValue()
+			case "reason":
+				hits.Reason = label.GetValue()
+			}
+		}
+		counts = append(counts, hits)
+	}
+
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Hits != counts[j].Hits {
+			return counts[i].Hits < counts[j].Hits
+		}
+		return counts[i].Path < counts[j].Path
+	})
+
+	return counts
+}
+
+// PlanCount returns the number of PR plans computed since startup
+func PlanCount() int64 {
+	var m dto.Metric
+	if err := Plans.Write(&m); err != nil {
+		return 0
+	}
+	return int64(m.GetCounter().GetValue())
+}
+
+// EngineRunCounts returns the number of XRs each diff engine diffed since startup
+func EngineRunCounts() map[string]int64 {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		DiffEngineRuns.Collect(ch)
+		close(ch)
+	}()
+
+	counts := make(map[string]int64)
+	for metric := range ch {
+		var m dto.Metric
+		if err := metric.Write(&m); err != nil {
+			continue
+		}
+		for _, label := range m.GetLabel() {
+			if label.GetName() == "engine" {
+				counts[label.GetValue()] = int64(m.GetCounter().GetValue())
+			}
+		}
+	}
+	return counts
+}
@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStripRuleHitCounts(t *testing.T) {
+	StripRuleHits.Reset()
+	t.Cleanup(StripRuleHits.Reset)
+
+	StripRuleHits.WithLabelValues("spec.dead", "Never fires")
+	StripRuleHits.WithLabelValues("metadata.labels", "Noisy labels").Add(5)
+	StripRuleHits.WithLabelValues("spec.managementPolicies", "Read-only previews").Add(2)
+
+	counts := StripRuleHitCounts()
+	if len(counts) != 3 {
+		t.Fatalf("len(StripRuleHitCounts()) = %d, want 3", len(counts))
+	}
+
+	want := []RuleHits{
+		{Path: "spec.dead", Reason: "Never fires", Hits: 0},
+		{Path: "spec.managementPolicies", Reason: "Read-only previews", Hits: 2},
+		{Path: "metadata.labels", Reason: "Noisy labels", Hits: 5},
+	}
+	for i := range want {
+		if counts[i] != want[i] {
+			t.Errorf("counts[%d] = %+v, want %+v", i, counts[i], want[i])
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	StripRuleHits.Reset()
+	t.Cleanup(StripRuleHits.Reset)
+	StripRuleHits.WithLabelValues("spec.field", "Test").Inc()
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	body := rec.Body.String()
+	if !strings.Contains(body, `crossplane_plan_sanitizer_strip_rule_hits_total{path="spec.field",reason="Test"} 1`) {
+		t.Errorf("metrics output missing strip rule counter:\n%s", body)
+	}
+}
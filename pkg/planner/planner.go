@@ -0,0 +1,166 @@
+// Package planner is the entry point for embedding crossplane-plan in another program
+//
+// Run plans the open PRs of a cluster until its context is done, the way the crossplane-plan
+// binary does; Plan plans them once and returns the outcome. Neither panics nor exits: every
+// failure is returned as an error. For more control, New returns the configured watcher,
+// whose setters tune it before it is started.
+package planner
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/crossplane/crossplane-runtime/v2/pkg/logging"
+	"github.com/go-logr/logr"
+	"github.com/millstonehq/crossplane-plan/pkg/argocd"
+	"github.com/millstonehq/crossplane-plan/pkg/config"
+	"github.com/millstonehq/crossplane-plan/pkg/detector"
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+	"github.com/millstonehq/crossplane-plan/pkg/formatter"
+	"github.com/millstonehq/crossplane-plan/pkg/store"
+	"github.com/millstonehq/crossplane-plan/pkg/vcs"
+	"github.com/millstonehq/crossplane-plan/pkg/watcher"
+	"k8s.io/client-go/rest"
+)
+
+// Options configures an embedded planner
+type Options struct {
+	// KubeConfig is the cluster whose preview XRs are planned (required)
+	KubeConfig *rest.Config
+
+	// Config holds the plan settings: detection, diff exclusions, repositories, ...
+	// Defaults to config.DefaultConfig()
+	Config *config.Config
+
+	// Logger receives the planner's logs; defaults to discarding them
+	Logger logr.Logger
+
+	// VCS posts plans as PR comments (e.g. a *github.Client); nil plans without posting (dry run)
+	VCS vcs.Client
+
+	// ArgoCD maps XRs to the PRs of ArgoCD preview applications; nil disables the integration
+	ArgoCD *argocd.Client
+
+	// State keeps plan state across restarts; defaults to memory
+	State store.Store
+
+	// ReconciliationInterval is how often open PRs are re-planned, in minutes (0 to disable)
+	ReconciliationInterval int
+
+	// CalculatorOptions and FormatterOptions are applied after the settings of Config
+	CalculatorOptions []differ.Option
+	FormatterOptions  []formatter.Option
+}
+
+// Run plans the open PRs until ctx is done, with leader election among the replicas
+func Run(ctx context.Context, opts Options) error {
+	w, err := New(opts)
+	if err != nil {
+		return err
+	}
+	return w.Start(ctx)
+}
+
+// Plan plans the open PRs once, or only prNumber when it isn't 0, and returns the outcome
+func Plan(ctx context.Context, opts Options, prNumber int) (watcher.Outcome, error) {
+	w, err := New(opts)
+	if err != nil {
+		return watcher.Outcome{}, err
+	}
+	return w.RunOnce(ctx, prNumber)
+}
+
+// New creates a watcher configured from opts without starting it
+func New(opts Options) (*watcher.XRWatcher, error) {
+	if opts.KubeConfig == nil {
+		return nil, fmt.Errorf("a kubernetes config is required")
+	}
+	cfg := opts.Config
+	if cfg == nil {
+		cfg = config.DefaultConfig()
+	}
+	logger := opts.Logger
+	if logger.GetSink() == nil {
+		logger = logr.Discard()
+	}
+
+	prDetector, err := NewDetector(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PR detector: %w", err)
+	}
+
+	calculatorOptions := append([]differ.Option{differ.WithConfig(cfg)}, opts.CalculatorOptions...)
+	calculator := differ.NewCalculator(opts.KubeConfig, logging.NewLogrLogger(logger), calculatorOptions...)
+
+	w, err := watcher.NewXRWatcher(opts.KubeConfig, prDetector, calculator,
+		formatter.NewGitHubFormatter(opts.FormatterOptions...),
+		watcher.WithLogger(logger),
+		watcher.WithVCS(opts.VCS),
+		watcher.WithArgoCD(opts.ArgoCD),
+		watcher.WithReconcileInterval(opts.ReconciliationInterval),
+	)
+	if err != nil {
+		return nil, err
+	}
+	w.SetConfig(cfg)
+	w.SetAllowedTargetRepos(cfg.AllowedTargetRepos)
+	if opts.State != nil {
+		w.SetStateStore(opts.State)
+	}
+	return w, nil
+}
+
+// NewDetector creates the PR detector of a configuration
+// Repositories with their own detection settings are routed to their own detector;
+// XR kinds with detection rules use theirs unless a repository's detector claims the XR
+func NewDetector(cfg *config.Config) (detector.Detector, error) {
+	fallback, err := newDetector(cfg.Detection())
+	if err != nil {
+		return nil, err
+	}
+
+	// XR kinds with their own detection settings are picked before the default detector
+	if rules := cfg.KindDetections(); len(rules) > 0 {
+		kindRules := make([]detector.KindRule, 0, len(rules))
+		for i, rule := range rules {
+			d, err := newDetector(rule.DetectionConfig)
+			if err != nil {
+				return nil, fmt.Errorf("detection rule %d (apiGroup %s): %w", i, rule.APIGroup, err)
+			}
+			kindRules = append(kindRules, detector.KindRule{APIGroup: rule.APIGroup, Kind: rule.Kind, Detector: d})
+		}
+		fallback = detector.NewKindDetector(kindRules, fallback)
+	}
+
+	// Repositories with their own detection settings are routed in a stable order
+	detections := cfg.RepoDetections()
+	if len(detections) == 0 {
+		return fallback, nil
+	}
+	repos := make([]string, 0, len(detections))
+	for repo := range detections {
+		repos = append(repos, repo)
+	}
+	sort.Strings(repos)
+
+	routes := make([]detector.Route, 0, len(repos))
+	for _, repo := range repos {
+		d, err := newDetector(detections[repo])
+		if err != nil {
+			return nil, fmt.Errorf("repository %s: %w", repo, err)
+		}
+		routes = append(routes, detector.Route{Repository: repo, Detector: d})
+	}
+	return detector.NewRoutingDetector(routes, fallback), nil
+}
+
+// newDetector creates the detector of one set of detection settings
+func newDetector(detection config.DetectionConfig) (detector.Detector, error) {
+	return detector.New(detection.Strategy,
+		detector.WithNamePattern(detection.NamePattern),
+		detector.WithLabelKey(detection.LabelKey),
+		detector.WithAnnotationKey(detection.AnnotationKey),
+		detector.WithCELExpression(detection.CELExpression),
+	)
+}
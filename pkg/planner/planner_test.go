@@ -0,0 +1,97 @@
+package planner
+
+import (
+	"testing"
+
+	"github.com/millstonehq/crossplane-plan/pkg/config"
+	"github.com/millstonehq/crossplane-plan/pkg/detector"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
+)
+
+func TestNew(t *testing.T) {
+	if _, err := New(Options{}); err == nil {
+		t.Error("New() without a kubernetes config should fail")
+	}
+
+	invalid := config.DefaultConfig()
+	invalid.DetectionStrategy = "regex"
+	if _, err := New(Options{KubeConfig: &rest.Config{Host: "https://127.0.0.1:6443"}, Config: invalid}); err == nil {
+		t.Error("New() with an unknown detection strategy should fail")
+	}
+
+	w, err := New(Options{KubeConfig: &rest.Config{Host: "https://127.0.0.1:6443"}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if w == nil {
+		t.Fatal("New() returned nil watcher")
+	}
+}
+
+func TestNewDetector_RoutesRepositories(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Repos = map[string]config.RepoProfile{
+		"acme/data": {Detection: &config.DetectionConfig{Strategy: detector.StrategyLabel, LabelKey: "acme.io/data-pr"}},
+	}
+
+	d, err := NewDetector(cfg)
+	if err != nil {
+		t.Fatalf("NewDetector() error = %v", err)
+	}
+
+	xr := &unstructured.Unstructured{}
+	xr.SetName("warehouse")
+	xr.SetLabels(map[string]string{"acme.io/data-pr": "7"})
+	if got := d.DetectPR(xr); got != 7 {
+		t.Errorf("DetectPR() = %d, want 7 from the repository's detector", got)
+	}
+
+	xr = &unstructured.Unstructured{}
+	xr.SetName("pr-3-bucket")
+	if got := d.DetectPR(xr); got != 3 {
+		t.Errorf("DetectPR() = %d, want 3 from the default detector", got)
+	}
+}
+
+func TestNewDetector_RoutesKinds(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.DetectionRules = []config.DetectionRule{
+		{APIGroup: "*.database.example.com", DetectionConfig: config.DetectionConfig{Strategy: detector.StrategyLabel}},
+	}
+	cfg.Repos = map[string]config.RepoProfile{
+		"acme/network": {Detection: &config.DetectionConfig{NamePattern: "net-pr-{number}-*"}},
+	}
+
+	d, err := NewDetector(cfg)
+	if err != nil {
+		t.Fatalf("NewDetector() error = %v", err)
+	}
+
+	xr := &unstructured.Unstructured{}
+	xr.SetAPIVersion("aws.database.example.com/v1alpha1")
+	xr.SetKind("XPostgres")
+	xr.SetName("pr-3-orders")
+	xr.SetLabels(map[string]string{"millstone.tech/pr-number": "7"})
+	if got := d.DetectPR(xr); got != 7 {
+		t.Errorf("DetectPR() = %d, want 7 from the kind's detector", got)
+	}
+
+	xr.SetName("net-pr-12-orders")
+	if got := d.DetectPR(xr); got != 12 {
+		t.Errorf("DetectPR() = %d, want 12 from the repository's detector", got)
+	}
+
+	xr = &unstructured.Unstructured{}
+	xr.SetAPIVersion("storage.example.com/v1alpha1")
+	xr.SetKind("XBucket")
+	xr.SetName("pr-3-bucket")
+	if got := d.DetectPR(xr); got != 3 {
+		t.Errorf("DetectPR() = %d, want 3 from the default detector", got)
+	}
+
+	cfg.DetectionRules[0].Strategy = "regex"
+	if _, err := NewDetector(cfg); err == nil {
+		t.Error("NewDetector() with an unknown rule strategy should fail")
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !readonly
+
+package readonly
+
+// Enforced is set by building with -tags readonly: the guard then covers every Kubernetes
+// client, including those of the state store and PlanConfig status, not only the planning ones
+const Enforced = false
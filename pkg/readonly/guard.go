@@ -0,0 +1,64 @@
+// Package readonly guards Kubernetes clients against requests that could modify the cluster
+package readonly
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/millstonehq/crossplane-plan/pkg/metrics"
+	"k8s.io/client-go/rest"
+)
+
+// ErrMutation is returned for requests the guard rejects
+var ErrMutation = errors.New("read-only guard rejected a request that could modify the cluster")
+
+// Wrap returns a copy of cfg whose clients only send requests that can't modify the cluster:
+// reads, server-side dry runs, access reviews and leader election leases
+func Wrap(cfg *rest.Config) *rest.Config {
+	guarded := rest.CopyConfig(cfg)
+	guarded.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &guard{next: rt}
+	})
+	return guarded
+}
+
+// guard rejects mutating requests before they reach the API server
+type guard struct {
+	next http.RoundTripper
+}
+
+// RoundTrip sends allowed requests and rejects the rest
+func (g *guard) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !Allowed(req) {
+		metrics.ReadOnlyRejections.WithLabelValues(req.Method).Inc()
+		return nil, fmt.Errorf("%w: %s %s", ErrMutation, req.Method, req.URL.Path)
+	}
+	return g.next.RoundTrip(req)
+}
+
+// Allowed reports whether a request can't modify the cluster
+func Allowed(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+
+	// Server-side dry runs (the diff's apply) are validated and admitted but never persisted
+	if req.URL.Query().Get("dryRun") == "All" {
+		return true
+	}
+
+	path := req.URL.Path
+	switch {
+	case strings.HasPrefix(path, "/apis/coordination.k8s.io/") && strings.Contains(path, "/leases"):
+		// Leader election holds its lease
+		return true
+	case req.Method == http.MethodPost && (strings.HasPrefix(path, "/apis/authorization.k8s.io/") ||
+		strings.HasPrefix(path, "/apis/authentication.k8s.io/")):
+		// Access and token reviews (the RBAC preflight) are answered without being stored
+		return true
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package readonly
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+)
+
+func TestAllowed(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		want   bool
+	}{
+		{name: "get", method: http.MethodGet, url: "/apis/example.org/v1/xnetworks/vpc", want: true},
+		{name: "watch", method: http.MethodGet, url: "/apis/example.org/v1/xnetworks?watch=true", want: true},
+		{name: "dry-run apply", method: http.MethodPatch, url: "/apis/example.org/v1/xnetworks/vpc?dryRun=All&fieldManager=crossplane-diff", want: true},
+		{name: "lease update", method: http.MethodPut, url: "/apis/coordination.k8s.io/v1/namespaces/crossplane-system/leases/crossplane-plan-leader", want: true},
+		{name: "access review", method: http.MethodPost, url: "/apis/authorization.k8s.io/v1/selfsubjectaccessreviews", want: true},
+		{name: "patch", method: http.MethodPatch, url: "/apis/example.org/v1/xnetworks/vpc", want: false},
+		{name: "delete", method: http.MethodDelete, url: "/apis/example.org/v1/xnetworks/vpc", want: false},
+		{name: "create", method: http.MethodPost, url: "/api/v1/namespaces/default/configmaps", want: false},
+		{name: "other dry-run value", method: http.MethodPost, url: "/api/v1/namespaces/default/configmaps?dryRun=None", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.url, nil)
+			if got := Allowed(req); got != tt.want {
+				t.Errorf("Allowed(%s %s) = %v, want %v", tt.method, tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrap(t *testing.T) {
+	var mutations int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			mutations++
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"apiVersion":"example.org/v1","kind":"XNetwork","metadata":{"name":"vpc"}}`))
+	}))
+	defer server.Close()
+
+	client, err := dynamic.NewForConfig(Wrap(&rest.Config{Host: server.URL}))
+	if err != nil {
+		t.Fatalf("dynamic.NewForConfig() error = %v", err)
+	}
+	xrs := client.Resource(schema.GroupVersionResource{Group: "example.org", Version: "v1", Resource: "xnetworks"})
+
+	ctx := context.Background()
+	if _, err := xrs.Get(ctx, "vpc", metav1.GetOptions{}); err != nil {
+		t.Errorf("Get() error = %v, want reads to pass", err)
+	}
+
+	xr := &unstructured.Unstructured{}
+	xr.SetAPIVersion("example.org/v1")
+	xr.SetKind("XNetwork")
+	xr.SetName("vpc")
+	if _, err := xrs.Update(ctx, xr, metav1.UpdateOptions{}); !errors.Is(err, ErrMutation) {
+		t.Errorf("Update() error = %v, want ErrMutation", err)
+	}
+	if err := xrs.Delete(ctx, "vpc", metav1.DeleteOptions{}); !errors.Is(err, ErrMutation) {
+		t.Errorf("Delete() error = %v, want ErrMutation", err)
+	}
+	if mutations != 0 {
+		t.Errorf("server received %d mutating requests, want 0", mutations)
+	}
+}
@@ -0,0 +1,70 @@
+// Package severity rates plan results, so comments, check runs, the dashboard and the
+// --once outcome agree on which results need attention
+package severity
+
+import "fmt"
+
+// Level is the severity of a plan result, from least to most severe
+// The zero value is that of results that weren't rated
+type Level int
+
+const (
+	// Info results need no attention: no changes, or deletions a policy marks as expected
+	Info Level = iota + 1
+	// Change results create or update resources
+	Change
+	// Destructive results delete resources or change protected kinds
+	Destructive
+	// Error results could not be planned
+	Error
+)
+
+// names are the levels' text representations, in order
+var names = [...]string{"info", "change", "destructive", "error"}
+
+// String returns the level's name, e.g. "destructive"
+func (l Level) String() string {
+	if l < Info || l > Error {
+		return fmt.Sprintf("Level(%d)", int(l))
+	}
+	return names[l-Info]
+}
+
+// Parse returns the level of a name
+func Parse(name string) (Level, error) {
+	for i, n := range names {
+		if n == name {
+			return Info + Level(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown severity %q (must be info, change, destructive or error)", name)
+}
+
+// MarshalText encodes the level as its name, e.g. in JSON
+func (l Level) MarshalText() ([]byte, error) {
+	if l < Info || l > Error {
+		return nil, fmt.Errorf("invalid severity %d", int(l))
+	}
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText decodes a level from its name
+func (l *Level) UnmarshalText(text []byte) error {
+	level, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// Max returns the most severe of levels (the zero value for none)
+func Max(levels ...Level) Level {
+	var max Level
+	for _, l := range levels {
+		if l > max {
+			max = l
+		}
+	}
+	return max
+}
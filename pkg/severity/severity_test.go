@@ -0,0 +1,48 @@
+package severity
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestLevel_Text(t *testing.T) {
+	for _, level := range []Level{Info, Change, Destructive, Error} {
+		data, err := json.Marshal(level)
+		if err != nil {
+			t.Fatalf("Marshal(%v) error = %v", level, err)
+		}
+		var got Level
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("Unmarshal(%s) error = %v", data, err)
+		}
+		if got != level {
+			t.Errorf("round trip of %v = %v", level, got)
+		}
+	}
+
+	if data, _ := json.Marshal(Destructive); string(data) != `"destructive"` {
+		t.Errorf("Marshal(Destructive) = %s, want \"destructive\"", data)
+	}
+	if _, err := Parse("warn"); err == nil {
+		t.Error("Parse(\"warn\") error = nil, want error")
+	}
+	if _, err := json.Marshal(Level(0)); err == nil {
+		t.Error("Marshal(Level(0)) error = nil, want error")
+	}
+}
+
+func TestMax(t *testing.T) {
+	tests := []struct {
+		levels []Level
+		want   Level
+	}{
+		{levels: nil, want: 0},
+		{levels: []Level{Change, Info}, want: Change},
+		{levels: []Level{Destructive, Error, Change}, want: Error},
+	}
+	for _, tt := range tests {
+		if got := Max(tt.levels...); got != tt.want {
+			t.Errorf("Max(%v) = %v, want %v", tt.levels, got, tt.want)
+		}
+	}
+}
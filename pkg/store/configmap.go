@@ -0,0 +1,140 @@
+package store
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+// DefaultName is the default ConfigMap name and PlanState name prefix
+const DefaultName = "crossplane-plan-state"
+
+// ConfigMap is a Store backed by the binaryData of a single ConfigMap
+// Keys are base64url-encoded, since ConfigMap keys can't contain "/"
+type ConfigMap struct {
+	client    kubernetes.Interface
+	namespace string
+	name      string
+}
+
+// NewConfigMap creates a store in the ConfigMap namespace/name, which is created on first write
+func NewConfigMap(client kubernetes.Interface, namespace, name string) *ConfigMap {
+	if name == "" {
+		name = DefaultName
+	}
+	return &ConfigMap{client: client, namespace: namespace, name: name}
+}
+
+// encodeKey turns a key into a valid ConfigMap key
+func encodeKey(key string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+// decodeKey reverses encodeKey
+func decodeKey(encoded string) (string, bool) {
+	key, err := base64.RawURLEncoding.DecodeString(encoded)
+	return string(key), err == nil
+}
+
+// get returns the ConfigMap, or nil if it doesn't exist yet
+func (c *ConfigMap) get(ctx context.Context) (*corev1.ConfigMap, error) {
+	cm, err := c.client.CoreV1().ConfigMaps(c.namespace).Get(ctx, c.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ConfigMap %s/%s: %w", c.namespace, c.name, err)
+	}
+	return cm, nil
+}
+
+// Get returns the value of a key
+func (c *ConfigMap) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	cm, err := c.get(ctx)
+	if err != nil || cm == nil {
+		return nil, false, err
+	}
+	value, ok := cm.BinaryData[encodeKey(key)]
+	return value, ok, nil
+}
+
+// Put sets the value of a key, creating the ConfigMap if needed
+func (c *ConfigMap) Put(ctx context.Context, key string, value []byte) error {
+	return c.update(ctx, func(data map[string][]byte) {
+		data[encodeKey(key)] = value
+	})
+}
+
+// Delete removes a key
+func (c *ConfigMap) Delete(ctx context.Context, key string) error {
+	return c.update(ctx, func(data map[string][]byte) {
+		delete(data, encodeKey(key))
+	})
+}
+
+// update applies mutate to the ConfigMap's data, retrying on conflicts
+func (c *ConfigMap) update(ctx context.Context, mutate func(map[string][]byte)) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := c.get(ctx)
+		if err != nil {
+			return err
+		}
+
+		if cm == nil {
+			cm = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      c.name,
+					Namespace: c.namespace,
+					Labels:    map[string]string{"app.kubernetes.io/managed-by": "crossplane-plan"},
+				},
+				BinaryData: make(map[string][]byte),
+			}
+			mutate(cm.BinaryData)
+			_, err = c.client.CoreV1().ConfigMaps(c.namespace).Create(ctx, cm, metav1.CreateOptions{})
+			if apierrors.IsAlreadyExists(err) {
+				return apierrors.NewConflict(corev1.Resource("configmaps"), c.name, err)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to create ConfigMap %s/%s: %w", c.namespace, c.name, err)
+			}
+			return nil
+		}
+
+		if cm.BinaryData == nil {
+			cm.BinaryData = make(map[string][]byte)
+		}
+		mutate(cm.BinaryData)
+		_, err = c.client.CoreV1().ConfigMaps(c.namespace).Update(ctx, cm, metav1.UpdateOptions{})
+		if err != nil && !apierrors.IsConflict(err) {
+			return fmt.Errorf("failed to update ConfigMap %s/%s: %w", c.namespace, c.name, err)
+		}
+		return err
+	})
+}
+
+// List returns the keys starting with prefix
+func (c *ConfigMap) List(ctx context.Context, prefix string) ([]string, error) {
+	cm, err := c.get(ctx)
+	if err != nil || cm == nil {
+		return nil, err
+	}
+	var keys []string
+	for encoded := range cm.BinaryData {
+		if key, ok := decodeKey(encoded); ok && strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// Close is a no-op
+func (c *ConfigMap) Close() error {
+	return nil
+}
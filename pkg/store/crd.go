@@ -0,0 +1,143 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/retry"
+)
+
+// PlanStateGVR identifies the PlanState custom resource
+var PlanStateGVR = schema.GroupVersionResource{
+	Group:    "plan.millstone.tech",
+	Version:  "v1alpha1",
+	Resource: "planstates",
+}
+
+// storeLabel marks the PlanStates of a store, so several installations can share a namespace
+const storeLabel = "plan.millstone.tech/state-store"
+
+// CRD is a Store keeping each entry in its own PlanState resource
+// Entries are named after a hash of their key; the key itself is kept in spec.key
+type CRD struct {
+	client    dynamic.ResourceInterface
+	namespace string
+	name      string
+}
+
+// NewCRD creates a store of PlanStates in namespace, named with the prefix name
+func NewCRD(client dynamic.Interface, namespace, name string) *CRD {
+	if name == "" {
+		name = DefaultName
+	}
+	return &CRD{client: client.Resource(PlanStateGVR).Namespace(namespace), namespace: namespace, name: name}
+}
+
+// objectName returns the PlanState name of a key
+func (c *CRD) objectName(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return c.name + "-" + hex.EncodeToString(sum[:16])
+}
+
+// Get returns the value of a key
+func (c *CRD) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	obj, err := c.client.Get(ctx, c.objectName(key), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get PlanState for %s: %w", key, err)
+	}
+	return decodeValue(obj)
+}
+
+// decodeValue returns the value held by a PlanState
+func decodeValue(obj *unstructured.Unstructured) ([]byte, bool, error) {
+	encoded, _, _ := unstructured.NestedString(obj.Object, "spec", "value")
+	value, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid value in PlanState %s: %w", obj.GetName(), err)
+	}
+	return value, true, nil
+}
+
+// Put sets the value of a key, creating its PlanState if needed
+func (c *CRD) Put(ctx context.Context, key string, value []byte) error {
+	name := c.objectName(key)
+	encoded := base64.StdEncoding.EncodeToString(value)
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		obj, err := c.client.Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			obj = &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": PlanStateGVR.GroupVersion().String(),
+				"kind":       "PlanState",
+				"metadata": map[string]interface{}{
+					"name":      name,
+					"namespace": c.namespace,
+					"labels":    map[string]interface{}{storeLabel: c.name},
+				},
+				"spec": map[string]interface{}{"key": key, "value": encoded},
+			}}
+			_, err = c.client.Create(ctx, obj, metav1.CreateOptions{})
+			if apierrors.IsAlreadyExists(err) {
+				return apierrors.NewConflict(PlanStateGVR.GroupResource(), name, err)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to create PlanState for %s: %w", key, err)
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get PlanState for %s: %w", key, err)
+		}
+
+		if err := unstructured.SetNestedField(obj.Object, encoded, "spec", "value"); err != nil {
+			return err
+		}
+		_, err = c.client.Update(ctx, obj, metav1.UpdateOptions{})
+		if err != nil && !apierrors.IsConflict(err) {
+			return fmt.Errorf("failed to update PlanState for %s: %w", key, err)
+		}
+		return err
+	})
+}
+
+// Delete removes a key
+func (c *CRD) Delete(ctx context.Context, key string) error {
+	err := c.client.Delete(ctx, c.objectName(key), metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete PlanState for %s: %w", key, err)
+	}
+	return nil
+}
+
+// List returns the keys starting with prefix
+func (c *CRD) List(ctx context.Context, prefix string) ([]string, error) {
+	list, err := c.client.List(ctx, metav1.ListOptions{LabelSelector: storeLabel + "=" + c.name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PlanStates: %w", err)
+	}
+	var keys []string
+	for _, item := range list.Items {
+		key, _, _ := unstructured.NestedString(item.Object, "spec", "key")
+		if key != "" && strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// Close is a no-op
+func (c *CRD) Close() error {
+	return nil
+}
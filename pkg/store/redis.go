@@ -0,0 +1,83 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisPrefix namespaces the keys of the store in a shared Redis
+const redisPrefix = "crossplane-plan:"
+
+// Redis is a Store backed by a Redis server
+type Redis struct {
+	client *redis.Client
+}
+
+// NewRedis connects to the Redis server at addr
+func NewRedis(ctx context.Context, addr, password string, db int) (*Redis, error) {
+	if addr == "" {
+		return nil, fmt.Errorf("redis backend requires an address")
+	}
+	client := redis.NewClient(&redis.Options{Addr: addr, Password: password, DB: db})
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to redis at %s: %w", addr, err)
+	}
+	return &Redis{client: client}, nil
+}
+
+// Get returns the value of a key
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := r.client.Get(ctx, redisPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get %s: %w", key, err)
+	}
+	return value, true, nil
+}
+
+// Put sets the value of a key
+func (r *Redis) Put(ctx context.Context, key string, value []byte) error {
+	if err := r.client.Set(ctx, redisPrefix+key, value, 0).Err(); err != nil {
+		return fmt.Errorf("failed to set %s: %w", key, err)
+	}
+	return nil
+}
+
+// Delete removes a key
+func (r *Redis) Delete(ctx context.Context, key string) error {
+	if err := r.client.Del(ctx, redisPrefix+key).Err(); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	return nil
+}
+
+// List returns the keys starting with prefix
+func (r *Redis) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	iter := r.client.Scan(ctx, 0, redisPrefix+escapeGlob(prefix)+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, strings.TrimPrefix(iter.Val(), redisPrefix))
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", prefix, err)
+	}
+	return keys, nil
+}
+
+// escapeGlob escapes the glob characters of a SCAN pattern
+func escapeGlob(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`)
+	return replacer.Replace(s)
+}
+
+// Close closes the connection
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
@@ -0,0 +1,391 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPlanHistory bounds the plans kept per PR
+const maxPlanHistory = 20
+
+// retryKey holds the PRs to replan after a restart or leader failover
+const retryKey = "retry"
+
+// plannedVersionsKind holds, per PR, the resourceVersions of the XRs it was last planned with
+const plannedVersionsKind = "versions"
+
+// prKinds are the kinds of per-PR entries
+var prKinds = []string{"comments", "component-comments", "plans", "latest", "checks", "statuses", plannedVersionsKind}
+
+// PlanRecord summarizes one plan of a PR
+type PlanRecord struct {
+	Time      time.Time `json:"time"`
+	Resources int       `json:"resources"`
+	Changed   int       `json:"changed"`
+	Posted    bool      `json:"posted"` // the comment was created or edited
+	Deletions int       `json:"deletions,omitempty"`
+	Protected int       `json:"protected,omitempty"` // changed resources of protected kinds
+	Failed    int       `json:"failed,omitempty"`    // XRs that could not be planned
+}
+
+// PlannedPR is a PR with a plan history
+type PlannedPR struct {
+	Repository string `json:"repository"`
+	PRNumber   int    `json:"pr"`
+}
+
+// State reads and writes plan state in a Store
+type State struct {
+	store Store
+}
+
+// NewState creates a State backed by store
+func NewState(store Store) *State {
+	return &State{store: store}
+}
+
+// HashComment returns the hash recorded for a comment body
+func HashComment(body string) string {
+	sum := sha256.Sum256([]byte(body))
+	return hex.EncodeToString(sum[:])
+}
+
+// prKey returns the key of a PR's entry under kind
+func prKey(kind, repo string, prNumber int) string {
+	return fmt.Sprintf("%s/%s/%d", kind, repo, prNumber)
+}
+
+// CommentHash returns the hash of the last comment posted to a PR (empty if unknown)
+func (s *State) CommentHash(ctx context.Context, repo string, prNumber int) (string, error) {
+	value, _, err := s.store.Get(ctx, prKey("comments", repo, prNumber))
+	return string(value), err
+}
+
+// SetCommentHash records the hash of the comment posted to a PR
+func (s *State) SetCommentHash(ctx context.Context, repo string, prNumber int, hash string) error {
+	return s.store.Put(ctx, prKey("comments", repo, prNumber), []byte(hash))
+}
+
+// ForgetComment drops the comment hashes of a PR, including its component comments,
+// e.g. after its comment was edited or deleted out of band
+func (s *State) ForgetComment(ctx context.Context, repo string, prNumber int) error {
+	if err := s.store.Delete(ctx, prKey("component-comments", repo, prNumber)); err != nil {
+		return err
+	}
+	return s.store.Delete(ctx, prKey("comments", repo, prNumber))
+}
+
+// ComponentCommentHashes returns the hashes of the component comments posted to a PR, by component
+func (s *State) ComponentCommentHashes(ctx context.Context, repo string, prNumber int) (map[string]string, error) {
+	value, ok, err := s.store.Get(ctx, prKey("component-comments", repo, prNumber))
+	if err != nil || !ok {
+		return nil, err
+	}
+	var hashes map[string]string
+	if err := json.Unmarshal(value, &hashes); err != nil {
+		return nil, fmt.Errorf("invalid component comment hashes of %s#%d: %w", repo, prNumber, err)
+	}
+	return hashes, nil
+}
+
+// SetComponentCommentHash records the hash of a component comment posted to a PR
+func (s *State) SetComponentCommentHash(ctx context.Context, repo string, prNumber int, component, hash string) error {
+	hashes, err := s.ComponentCommentHashes(ctx, repo, prNumber)
+	if err != nil || hashes == nil {
+		hashes = make(map[string]string) // Start over rather than failing on corrupt hashes
+	}
+	hashes[component] = hash
+
+	value, err := json.Marshal(hashes)
+	if err != nil {
+		return err
+	}
+	return s.store.Put(ctx, prKey("component-comments", repo, prNumber), value)
+}
+
+// CheckHash returns the hash of the last check run published for a PR (empty if unknown)
+func (s *State) CheckHash(ctx context.Context, repo string, prNumber int) (string, error) {
+	value, _, err := s.store.Get(ctx, prKey("checks", repo, prNumber))
+	return string(value), err
+}
+
+// SetCheckHash records the hash of the check run published for a PR
+func (s *State) SetCheckHash(ctx context.Context, repo string, prNumber int, hash string) error {
+	return s.store.Put(ctx, prKey("checks", repo, prNumber), []byte(hash))
+}
+
+// StatusHash returns the hash of the last commit status published for a PR (empty if unknown)
+func (s *State) StatusHash(ctx context.Context, repo string, prNumber int) (string, error) {
+	value, _, err := s.store.Get(ctx, prKey("statuses", repo, prNumber))
+	return string(value), err
+}
+
+// SetStatusHash records the hash of the commit status published for a PR
+func (s *State) SetStatusHash(ctx context.Context, repo string, prNumber int, hash string) error {
+	return s.store.Put(ctx, prKey("statuses", repo, prNumber), []byte(hash))
+}
+
+// Plans returns a PR's recent plans, oldest first
+func (s *State) Plans(ctx context.Context, repo string, prNumber int) ([]PlanRecord, error) {
+	value, ok, err := s.store.Get(ctx, prKey("plans", repo, prNumber))
+	if err != nil || !ok {
+		return nil, err
+	}
+	var records []PlanRecord
+	if err := json.Unmarshal(value, &records); err != nil {
+		return nil, fmt.Errorf("invalid plan history of %s#%d: %w", repo, prNumber, err)
+	}
+	return records, nil
+}
+
+// RecordPlan appends a plan to a PR's history, keeping the most recent ones
+func (s *State) RecordPlan(ctx context.Context, repo string, prNumber int, record PlanRecord) error {
+	records, err := s.Plans(ctx, repo, prNumber)
+	if err != nil {
+		records = nil // Start over rather than failing on a corrupt history
+	}
+	records = append(records, record)
+	if len(records) > maxPlanHistory {
+		records = records[len(records)-maxPlanHistory:]
+	}
+
+	value, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+	return s.store.Put(ctx, prKey("plans", repo, prNumber), value)
+}
+
+// PlannedPRs returns the PRs with a plan history, by repository and PR number
+func (s *State) PlannedPRs(ctx context.Context) ([]PlannedPR, error) {
+	return s.listPRs(ctx, "plans")
+}
+
+// StoredPRs returns the PRs with any state (comment hash, plan history or latest plan),
+// by repository and PR number
+func (s *State) StoredPRs(ctx context.Context) ([]PlannedPR, error) {
+	seen := make(map[PlannedPR]bool)
+	var prs []PlannedPR
+	for _, kind := range prKinds {
+		listed, err := s.listPRs(ctx, kind)
+		if err != nil {
+			return nil, err
+		}
+		for _, pr := range listed {
+			if !seen[pr] {
+				seen[pr] = true
+				prs = append(prs, pr)
+			}
+		}
+	}
+	sortPRs(prs)
+	return prs, nil
+}
+
+// ForgetPR drops all state of a PR, e.g. once it is closed
+func (s *State) ForgetPR(ctx context.Context, repo string, prNumber int) error {
+	for _, kind := range prKinds {
+		if err := s.store.Delete(ctx, prKey(kind, repo, prNumber)); err != nil {
+			return fmt.Errorf("failed to delete %s of %s#%d: %w", kind, repo, prNumber, err)
+		}
+	}
+	return nil
+}
+
+// listPRs returns the PRs with an entry under kind, by repository and PR number
+func (s *State) listPRs(ctx context.Context, kind string) ([]PlannedPR, error) {
+	prefix := kind + "/"
+	keys, err := s.store.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var prs []PlannedPR
+	for _, key := range keys {
+		// <kind>/<owner>/<repo>/<pr>
+		slash := strings.LastIndex(key, "/")
+		prNumber, err := strconv.Atoi(key[slash+1:])
+		if err != nil || slash <= len(prefix) {
+			continue
+		}
+		prs = append(prs, PlannedPR{Repository: key[len(prefix):slash], PRNumber: prNumber})
+	}
+	sortPRs(prs)
+	return prs, nil
+}
+
+// sortPRs sorts PRs by repository and PR number
+func sortPRs(prs []PlannedPR) {
+	sort.Slice(prs, func(i, j int) bool {
+		if prs[i].Repository != prs[j].Repository {
+			return prs[i].Repository < prs[j].Repository
+		}
+		return prs[i].PRNumber < prs[j].PRNumber
+	})
+}
+
+// RetryPRs returns the PRs that were waiting to be replanned
+func (s *State) RetryPRs(ctx context.Context) ([]PlannedPR, error) {
+	value, ok, err := s.store.Get(ctx, retryKey)
+	if err != nil || !ok {
+		return nil, err
+	}
+	var prs []PlannedPR
+	if err := json.Unmarshal(value, &prs); err != nil {
+		return nil, fmt.Errorf("invalid retry state: %w", err)
+	}
+	return prs, nil
+}
+
+// SetRetryPRs records the PRs waiting to be replanned
+func (s *State) SetRetryPRs(ctx context.Context, prs []PlannedPR) error {
+	sorted := append([]PlannedPR(nil), prs...)
+	sortPRs(sorted)
+	value, err := json.Marshal(sorted)
+	if err != nil {
+		return err
+	}
+	return s.store.Put(ctx, retryKey, value)
+}
+
+// PlannedVersions returns the resourceVersions of the XRs each PR was last planned with, by PR
+// and XR ("Kind/namespace/name")
+func (s *State) PlannedVersions(ctx context.Context) (map[PlannedPR]map[string]string, error) {
+	prs, err := s.listPRs(ctx, plannedVersionsKind)
+	if err != nil {
+		return nil, err
+	}
+
+	planned := make(map[PlannedPR]map[string]string, len(prs))
+	for _, pr := range prs {
+		value, ok, err := s.store.Get(ctx, prKey(plannedVersionsKind, pr.Repository, pr.PRNumber))
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		var versions map[string]string
+		if err := json.Unmarshal(value, &versions); err != nil {
+			return nil, fmt.Errorf("invalid planned versions of %s#%d: %w", pr.Repository, pr.PRNumber, err)
+		}
+		planned[pr] = versions
+	}
+	return planned, nil
+}
+
+// SetPlannedVersions records the resourceVersions of the XRs a PR was planned with
+func (s *State) SetPlannedVersions(ctx context.Context, repo string, prNumber int, versions map[string]string) error {
+	value, err := json.Marshal(versions)
+	if err != nil {
+		return err
+	}
+	return s.store.Put(ctx, prKey(plannedVersionsKind, repo, prNumber), value)
+}
+
+// ForgetPlannedVersions drops the planned resourceVersions of a PR without XRs
+func (s *State) ForgetPlannedVersions(ctx context.Context, repo string, prNumber int) error {
+	return s.store.Delete(ctx, prKey(plannedVersionsKind, repo, prNumber))
+}
+
+// LatestPlan decodes the latest plan of a PR into plan; ok is false if the PR has none
+func (s *State) LatestPlan(ctx context.Context, repo string, prNumber int, plan interface{}) (bool, error) {
+	value, ok, err := s.store.Get(ctx, prKey("latest", repo, prNumber))
+	if err != nil || !ok {
+		return false, err
+	}
+	if err := json.Unmarshal(value, plan); err != nil {
+		return false, fmt.Errorf("invalid latest plan of %s#%d: %w", repo, prNumber, err)
+	}
+	return true, nil
+}
+
+// SetLatestPlan records the latest plan of a PR, replacing the previous one
+func (s *State) SetLatestPlan(ctx context.Context, repo string, prNumber int, plan interface{}) error {
+	value, err := json.Marshal(plan)
+	if err != nil {
+		return err
+	}
+	return s.store.Put(ctx, prKey("latest", repo, prNumber), value)
+}
+
+// maxReadinessSamples bounds the time-to-Ready samples kept per kind
+const maxReadinessSamples = 20
+
+// minReadinessSamples is how many samples a kind needs before its time-to-Ready is estimated
+const minReadinessSamples = 3
+
+// ReadinessSample is how long an XR took to become Ready after it was created
+type ReadinessSample struct {
+	UID     string  `json:"uid"`
+	Seconds float64 `json:"seconds"`
+}
+
+// readinessKey returns the key of a kind's time-to-Ready samples
+func readinessKey(kind string) string {
+	return "readiness/" + kind
+}
+
+// ReadinessSamples returns the recent time-to-Ready samples of a kind ("Kind.group"), oldest first
+func (s *State) ReadinessSamples(ctx context.Context, kind string) ([]ReadinessSample, error) {
+	value, ok, err := s.store.Get(ctx, readinessKey(kind))
+	if err != nil || !ok {
+		return nil, err
+	}
+	var samples []ReadinessSample
+	if err := json.Unmarshal(value, &samples); err != nil {
+		return nil, fmt.Errorf("invalid readiness samples of %s: %w", kind, err)
+	}
+	return samples, nil
+}
+
+// RecordReadiness adds a sample to a kind's time-to-Ready history, keeping the most recent ones
+// Returns false if the XR already has a sample
+func (s *State) RecordReadiness(ctx context.Context, kind string, sample ReadinessSample) (bool, error) {
+	samples, err := s.ReadinessSamples(ctx, kind)
+	if err != nil {
+		samples = nil // Start over rather than failing on a corrupt history
+	}
+	for _, existing := range samples {
+		if existing.UID == sample.UID {
+			return false, nil
+		}
+	}
+	samples = append(samples, sample)
+	if len(samples) > maxReadinessSamples {
+		samples = samples[len(samples)-maxReadinessSamples:]
+	}
+
+	value, err := json.Marshal(samples)
+	if err != nil {
+		return false, err
+	}
+	return true, s.store.Put(ctx, readinessKey(kind), value)
+}
+
+// TimeToReady estimates how long a new XR of a kind takes to become Ready: the median of its samples
+// ok is false until the kind has enough samples
+func (s *State) TimeToReady(ctx context.Context, kind string) (time.Duration, bool, error) {
+	samples, err := s.ReadinessSamples(ctx, kind)
+	if err != nil || len(samples) < minReadinessSamples {
+		return 0, false, err
+	}
+	seconds := make([]float64, len(samples))
+	for i, sample := range samples {
+		seconds[i] = sample.Seconds
+	}
+	sort.Float64s(seconds)
+
+	median := seconds[len(seconds)/2]
+	if len(seconds)%2 == 0 {
+		median = (seconds[len(seconds)/2-1] + median) / 2
+	}
+	return time.Duration(median * float64(time.Second)), true, nil
+}
@@ -0,0 +1,290 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestState_CommentHash(t *testing.T) {
+	ctx := context.Background()
+	state := NewState(NewMemory())
+
+	if hash, err := state.CommentHash(ctx, "owner/repo", 42); err != nil || hash != "" {
+		t.Fatalf("CommentHash() of unknown PR = %q, %v", hash, err)
+	}
+	if err := state.SetCommentHash(ctx, "owner/repo", 42, HashComment("plan")); err != nil {
+		t.Fatal(err)
+	}
+	if hash, _ := state.CommentHash(ctx, "owner/repo", 42); hash != HashComment("plan") {
+		t.Errorf("CommentHash() = %q, want hash of the recorded comment", hash)
+	}
+	if hash, _ := state.CommentHash(ctx, "owner/other", 42); hash != "" {
+		t.Errorf("CommentHash() leaked across repositories: %q", hash)
+	}
+
+	if err := state.ForgetComment(ctx, "owner/repo", 42); err != nil {
+		t.Fatal(err)
+	}
+	if hash, _ := state.CommentHash(ctx, "owner/repo", 42); hash != "" {
+		t.Errorf("CommentHash() after ForgetComment = %q", hash)
+	}
+}
+
+func TestState_ComponentCommentHashes(t *testing.T) {
+	ctx := context.Background()
+	state := NewState(NewMemory())
+
+	if hashes, err := state.ComponentCommentHashes(ctx, "owner/repo", 42); err != nil || hashes != nil {
+		t.Fatalf("ComponentCommentHashes() of unknown PR = %v, %v", hashes, err)
+	}
+	for _, component := range []string{"network", "database"} {
+		if err := state.SetComponentCommentHash(ctx, "owner/repo", 42, component, HashComment(component)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := map[string]string{"network": HashComment("network"), "database": HashComment("database")}
+	if hashes, _ := state.ComponentCommentHashes(ctx, "owner/repo", 42); !reflect.DeepEqual(hashes, want) {
+		t.Errorf("ComponentCommentHashes() = %v, want %v", hashes, want)
+	}
+	if prs, _ := state.StoredPRs(ctx); !reflect.DeepEqual(prs, []PlannedPR{{Repository: "owner/repo", PRNumber: 42}}) {
+		t.Errorf("StoredPRs() = %v, want the PR with component comments", prs)
+	}
+
+	if err := state.ForgetComment(ctx, "owner/repo", 42); err != nil {
+		t.Fatal(err)
+	}
+	if hashes, _ := state.ComponentCommentHashes(ctx, "owner/repo", 42); hashes != nil {
+		t.Errorf("ComponentCommentHashes() after ForgetComment = %v", hashes)
+	}
+}
+
+func TestState_RecordPlan(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemory()
+	state := NewState(s)
+
+	for i := 0; i < maxPlanHistory+5; i++ {
+		if err := state.RecordPlan(ctx, "owner/repo", 42, PlanRecord{Resources: i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	plans, err := state.Plans(ctx, "owner/repo", 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plans) != maxPlanHistory || plans[0].Resources != 5 || plans[len(plans)-1].Resources != maxPlanHistory+4 {
+		t.Errorf("Plans() kept %d plans from %d, want the %d most recent", len(plans), plans[0].Resources, maxPlanHistory)
+	}
+
+	// A corrupt history is replaced instead of blocking new plans
+	_ = s.Put(ctx, prKey("plans", "owner/repo", 42), []byte("{"))
+	if err := state.RecordPlan(ctx, "owner/repo", 42, PlanRecord{Resources: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if plans, _ := state.Plans(ctx, "owner/repo", 42); len(plans) != 1 {
+		t.Errorf("Plans() after corrupt history = %d plans, want 1", len(plans))
+	}
+}
+
+func TestState_RetryPRs(t *testing.T) {
+	ctx := context.Background()
+	state := NewState(NewMemory())
+
+	if prs, err := state.RetryPRs(ctx); err != nil || len(prs) != 0 {
+		t.Fatalf("RetryPRs() of empty store = %v, %v", prs, err)
+	}
+	if err := state.SetRetryPRs(ctx, []PlannedPR{{"owner/repo", 7}, {"owner/repo", 3}, {"owner/other", 7}}); err != nil {
+		t.Fatal(err)
+	}
+	prs, err := state.RetryPRs(ctx)
+	want := []PlannedPR{{"owner/other", 7}, {"owner/repo", 3}, {"owner/repo", 7}}
+	if err != nil || !reflect.DeepEqual(prs, want) {
+		t.Errorf("RetryPRs() = %v, %v, want %v", prs, err, want)
+	}
+}
+
+func TestState_PlannedVersions(t *testing.T) {
+	ctx := context.Background()
+	state := NewState(NewMemory())
+
+	if planned, err := state.PlannedVersions(ctx); err != nil || len(planned) != 0 {
+		t.Fatalf("PlannedVersions() of empty store = %v, %v", planned, err)
+	}
+	if err := state.SetPlannedVersions(ctx, "owner/repo", 7, map[string]string{"XNetwork//pr-7-vpc": "12"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := state.SetPlannedVersions(ctx, "owner/repo", 9, map[string]string{"XDatabase/team-a/pr-9-db": "30"}); err != nil {
+		t.Fatal(err)
+	}
+	// PR 7 of another repository is a different PR
+	if err := state.SetPlannedVersions(ctx, "owner/other", 7, map[string]string{"XNetwork//pr-7-subnet": "14"}); err != nil {
+		t.Fatal(err)
+	}
+
+	planned, err := state.PlannedVersions(ctx)
+	want := map[PlannedPR]map[string]string{
+		{"owner/repo", 7}:  {"XNetwork//pr-7-vpc": "12"},
+		{"owner/repo", 9}:  {"XDatabase/team-a/pr-9-db": "30"},
+		{"owner/other", 7}: {"XNetwork//pr-7-subnet": "14"},
+	}
+	if err != nil || !reflect.DeepEqual(planned, want) {
+		t.Errorf("PlannedVersions() = %v, %v, want %v", planned, err, want)
+	}
+
+	if err := state.ForgetPlannedVersions(ctx, "owner/repo", 7); err != nil {
+		t.Fatal(err)
+	}
+	planned, _ = state.PlannedVersions(ctx)
+	if len(planned) != 2 || planned[PlannedPR{"owner/repo", 9}] == nil || planned[PlannedPR{"owner/other", 7}] == nil {
+		t.Errorf("PlannedVersions() after ForgetPlannedVersions = %v", planned)
+	}
+
+	// Planned versions are PR state, so dropping the PR drops them too
+	if err := state.ForgetPR(ctx, "owner/repo", 9); err != nil {
+		t.Fatal(err)
+	}
+	if planned, _ := state.PlannedVersions(ctx); len(planned) != 1 {
+		t.Errorf("PlannedVersions() after ForgetPR = %v", planned)
+	}
+}
+
+func TestState_LatestPlan(t *testing.T) {
+	ctx := context.Background()
+	state := NewState(NewMemory())
+
+	var plan map[string]int
+	if ok, err := state.LatestPlan(ctx, "owner/repo", 42, &plan); err != nil || ok {
+		t.Fatalf("LatestPlan() of unknown PR = %v, %v", ok, err)
+	}
+	if err := state.SetLatestPlan(ctx, "owner/repo", 42, map[string]int{"resources": 3}); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := state.LatestPlan(ctx, "owner/repo", 42, &plan); err != nil || !ok || plan["resources"] != 3 {
+		t.Errorf("LatestPlan() = %v, %v, %v", plan, ok, err)
+	}
+}
+
+func TestState_PlannedPRs(t *testing.T) {
+	ctx := context.Background()
+	state := NewState(NewMemory())
+
+	for _, pr := range []PlannedPR{{"owner/repo", 42}, {"owner/other", 7}, {"owner/repo", 3}} {
+		if err := state.RecordPlan(ctx, pr.Repository, pr.PRNumber, PlanRecord{Resources: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := state.SetCommentHash(ctx, "owner/repo", 99, "hash"); err != nil {
+		t.Fatal(err)
+	}
+
+	prs, err := state.PlannedPRs(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []PlannedPR{{"owner/other", 7}, {"owner/repo", 3}, {"owner/repo", 42}}
+	if len(prs) != len(want) {
+		t.Fatalf("PlannedPRs() = %v, want %v", prs, want)
+	}
+	for i := range want {
+		if prs[i] != want[i] {
+			t.Errorf("PlannedPRs()[%d] = %v, want %v", i, prs[i], want[i])
+		}
+	}
+}
+
+func TestState_ForgetPR(t *testing.T) {
+	ctx := context.Background()
+	state := NewState(NewMemory())
+
+	for _, prNumber := range []int{42, 43} {
+		if err := state.SetCommentHash(ctx, "owner/repo", prNumber, "hash"); err != nil {
+			t.Fatal(err)
+		}
+		if err := state.RecordPlan(ctx, "owner/repo", prNumber, PlanRecord{Resources: 1}); err != nil {
+			t.Fatal(err)
+		}
+		if err := state.SetLatestPlan(ctx, "owner/repo", prNumber, map[string]int{"resources": 1}); err != nil {
+			t.Fatal(err)
+		}
+		if err := state.SetCheckHash(ctx, "owner/repo", prNumber, "hash"); err != nil {
+			t.Fatal(err)
+		}
+		if err := state.SetStatusHash(ctx, "owner/repo", prNumber, "hash"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := state.SetLatestPlan(ctx, "owner/other", 7, map[string]int{"resources": 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	prs, err := state.StoredPRs(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []PlannedPR{{"owner/other", 7}, {"owner/repo", 42}, {"owner/repo", 43}}
+	if !reflect.DeepEqual(prs, want) {
+		t.Fatalf("StoredPRs() = %v, want %v", prs, want)
+	}
+
+	if err := state.ForgetPR(ctx, "owner/repo", 42); err != nil {
+		t.Fatal(err)
+	}
+	if hash, _ := state.CommentHash(ctx, "owner/repo", 42); hash != "" {
+		t.Errorf("CommentHash() after ForgetPR = %q", hash)
+	}
+	if records, _ := state.Plans(ctx, "owner/repo", 42); len(records) != 0 {
+		t.Errorf("Plans() after ForgetPR = %v", records)
+	}
+	if hash, _ := state.CheckHash(ctx, "owner/repo", 42); hash != "" {
+		t.Errorf("CheckHash() after ForgetPR = %q", hash)
+	}
+	if hash, _ := state.StatusHash(ctx, "owner/repo", 42); hash != "" {
+		t.Errorf("StatusHash() after ForgetPR = %q", hash)
+	}
+	var plan map[string]int
+	if ok, _ := state.LatestPlan(ctx, "owner/repo", 42, &plan); ok {
+		t.Error("LatestPlan() found a plan after ForgetPR")
+	}
+	if records, _ := state.Plans(ctx, "owner/repo", 43); len(records) != 1 {
+		t.Errorf("ForgetPR() dropped the state of another PR: %v", records)
+	}
+}
+
+func TestState_TimeToReady(t *testing.T) {
+	ctx := context.Background()
+	state := NewState(NewMemory())
+	kind := "XDatabase.example.org"
+
+	for i, seconds := range []float64{600, 120} {
+		if _, err := state.RecordReadiness(ctx, kind, ReadinessSample{UID: fmt.Sprint(i), Seconds: seconds}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, ok, _ := state.TimeToReady(ctx, kind); ok {
+		t.Error("TimeToReady() ok = true with too few samples")
+	}
+
+	if recorded, _ := state.RecordReadiness(ctx, kind, ReadinessSample{UID: "0", Seconds: 1}); recorded {
+		t.Error("RecordReadiness() recorded a second sample of the same XR")
+	}
+	for i, seconds := range []float64{300, 900} {
+		if _, err := state.RecordReadiness(ctx, kind, ReadinessSample{UID: fmt.Sprint(i + 2), Seconds: seconds}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, ok, err := state.TimeToReady(ctx, kind); err != nil || !ok || got != 450*time.Second {
+		t.Errorf("TimeToReady() = %v, %v, %v, want the median 7m30s", got, ok, err)
+	}
+
+	for i := 0; i < maxReadinessSamples+5; i++ {
+		if _, err := state.RecordReadiness(ctx, kind, ReadinessSample{UID: fmt.Sprint("new-", i), Seconds: 60}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if samples, _ := state.ReadinessSamples(ctx, kind); len(samples) != maxReadinessSamples {
+		t.Errorf("ReadinessSamples() kept %d samples, want %d", len(samples), maxReadinessSamples)
+	}
+}
@@ -0,0 +1,100 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Storage backends
+const (
+	// BackendMemory keeps state in memory; it is lost on restart and leader failover
+	BackendMemory = "memory"
+
+	// BackendConfigMap keeps state in a single ConfigMap (up to 1 MiB)
+	BackendConfigMap = "configmap"
+
+	// BackendCRD keeps each entry in a PlanState resource
+	BackendCRD = "crd"
+
+	// BackendRedis keeps state in Redis
+	BackendRedis = "redis"
+
+	// BackendSQLite keeps state in a SQLite database, e.g. on a PersistentVolume
+	BackendSQLite = "sqlite"
+)
+
+// Store is a key-value store for plan state (comment hashes, plan history, retry state)
+// Keys are "/"-separated paths such as "comments/owner/repo/42"
+type Store interface {
+	// Get returns the value of a key; ok is false if the key doesn't exist
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+
+	// Put sets the value of a key
+	Put(ctx context.Context, key string, value []byte) error
+
+	// Delete removes a key; deleting a missing key is not an error
+	Delete(ctx context.Context, key string) error
+
+	// List returns the keys starting with prefix, in no particular order
+	List(ctx context.Context, prefix string) ([]string, error)
+
+	// Close releases the store's connections
+	Close() error
+}
+
+// Options configure the storage backend
+type Options struct {
+	// Backend is one of the Backend* constants (default memory)
+	Backend string
+
+	// Namespace holds the ConfigMap or PlanState resources
+	Namespace string
+
+	// Name is the ConfigMap name, or the prefix of PlanState names
+	Name string
+
+	// Clientset is used by the configmap backend
+	Clientset kubernetes.Interface
+
+	// DynamicClient is used by the crd backend
+	DynamicClient dynamic.Interface
+
+	// RedisAddr is the address ("host:port") of the Redis server
+	RedisAddr string
+
+	// RedisPassword authenticates to the Redis server (optional)
+	RedisPassword string
+
+	// RedisDB selects the Redis database
+	RedisDB int
+
+	// SQLitePath is the SQLite database file
+	SQLitePath string
+}
+
+// New creates the store selected by opts
+func New(ctx context.Context, opts Options) (Store, error) {
+	switch opts.Backend {
+	case "", BackendMemory:
+		return NewMemory(), nil
+	case BackendConfigMap:
+		if opts.Clientset == nil {
+			return nil, fmt.Errorf("configmap backend requires a Kubernetes client")
+		}
+		return NewConfigMap(opts.Clientset, opts.Namespace, opts.Name), nil
+	case BackendCRD:
+		if opts.DynamicClient == nil {
+			return nil, fmt.Errorf("crd backend requires a Kubernetes client")
+		}
+		return NewCRD(opts.DynamicClient, opts.Namespace, opts.Name), nil
+	case BackendRedis:
+		return NewRedis(ctx, opts.RedisAddr, opts.RedisPassword, opts.RedisDB)
+	case BackendSQLite:
+		return NewSQLite(ctx, opts.SQLitePath)
+	default:
+		return nil, fmt.Errorf("unknown state store backend %q, expected memory, configmap, crd, redis, or sqlite", opts.Backend)
+	}
+}
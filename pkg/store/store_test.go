@@ -0,0 +1,94 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestStores(t *testing.T) {
+	ctx := context.Background()
+	sqlite, err := NewSQLite(ctx, filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v", err)
+	}
+	defer sqlite.Close()
+
+	stores := map[string]Store{
+		"memory":    NewMemory(),
+		"configmap": NewConfigMap(fake.NewSimpleClientset(), "crossplane-plan", ""),
+		"crd": NewCRD(dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{PlanStateGVR: "PlanStateList"}), "crossplane-plan", ""),
+		"sqlite": sqlite,
+	}
+
+	for name, s := range stores {
+		t.Run(name, func(t *testing.T) {
+			if _, ok, err := s.Get(ctx, "comments/owner/repo/1"); err != nil || ok {
+				t.Fatalf("Get() of missing key = ok %v, error %v", ok, err)
+			}
+
+			for key, value := range map[string]string{
+				"comments/owner/repo/1": "a",
+				"comments/owner/repo/2": "b",
+				"plans/owner/repo/1":    "c",
+			} {
+				if err := s.Put(ctx, key, []byte(value)); err != nil {
+					t.Fatalf("Put(%s) error = %v", key, err)
+				}
+			}
+			if err := s.Put(ctx, "comments/owner/repo/1", []byte("updated")); err != nil {
+				t.Fatalf("Put() overwrite error = %v", err)
+			}
+
+			value, ok, err := s.Get(ctx, "comments/owner/repo/1")
+			if err != nil || !ok || string(value) != "updated" {
+				t.Errorf("Get() = %q, %v, %v, want %q", value, ok, err, "updated")
+			}
+
+			keys, err := s.List(ctx, "comments/")
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			sort.Strings(keys)
+			if got := strings.Join(keys, ","); got != "comments/owner/repo/1,comments/owner/repo/2" {
+				t.Errorf("List() = %s", got)
+			}
+
+			if err := s.Delete(ctx, "comments/owner/repo/1"); err != nil {
+				t.Fatalf("Delete() error = %v", err)
+			}
+			if err := s.Delete(ctx, "comments/owner/repo/1"); err != nil {
+				t.Errorf("Delete() of missing key error = %v", err)
+			}
+			if _, ok, _ := s.Get(ctx, "comments/owner/repo/1"); ok {
+				t.Error("Get() found deleted key")
+			}
+		})
+	}
+}
+
+func TestEncodeKey(t *testing.T) {
+	for _, key := range []string{"retry", "comments/owner/repo/42", "plans/a b/ü/1"} {
+		encoded := encodeKey(key)
+		if strings.ContainsAny(encoded, "/ ") {
+			t.Errorf("encodeKey(%q) = %q, not a valid ConfigMap key", key, encoded)
+		}
+		if decoded, ok := decodeKey(encoded); !ok || decoded != key {
+			t.Errorf("decodeKey(encodeKey(%q)) = %q, %v", key, decoded, ok)
+		}
+	}
+}
+
+func TestNew_UnknownBackend(t *testing.T) {
+	if _, err := New(context.Background(), Options{Backend: "etcd"}); err == nil {
+		t.Error("New() with unknown backend succeeded")
+	}
+}
@@ -0,0 +1,127 @@
+// Package telemetry sends anonymous usage reports, when explicitly enabled, so maintainers
+// can see which features are used
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/millstonehq/crossplane-plan/pkg/metrics"
+	"github.com/millstonehq/crossplane-plan/pkg/version"
+)
+
+// requestTimeout bounds sending one report
+const requestTimeout = 10 * time.Second
+
+// Report is an anonymous usage report
+// It holds counts and build information only: no repository, PR, resource or cluster names
+type Report struct {
+	// InstanceID is random per process start, so reports of one run can be told apart
+	// without identifying the installation
+	InstanceID string `json:"instanceId"`
+
+	Version   string `json:"version"`
+	GoVersion string `json:"goVersion"`
+	Platform  string `json:"platform"`
+
+	UptimeSeconds int64 `json:"uptimeSeconds"`
+
+	// Plans is the number of PR plans computed since startup
+	Plans int64 `json:"plans"`
+
+	// Engines is the number of XRs each diff engine diffed since startup
+	Engines map[string]int64 `json:"engines"`
+}
+
+// Reporter periodically posts a Report to an endpoint
+type Reporter struct {
+	endpoint   string
+	interval   time.Duration
+	client     *http.Client
+	logger     logr.Logger
+	instanceID string
+	started    time.Time
+}
+
+// NewReporter creates a Reporter posting to endpoint every interval
+func NewReporter(endpoint string, interval time.Duration, logger logr.Logger) *Reporter {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return &Reporter{
+		endpoint:   endpoint,
+		interval:   interval,
+		client:     &http.Client{Timeout: requestTimeout},
+		logger:     logger.WithName("telemetry"),
+		instanceID: hex.EncodeToString(id),
+		started:    time.Now(),
+	}
+}
+
+// Run reports every interval until ctx is done, then sends a final report
+// Failed reports are logged and otherwise ignored
+func (r *Reporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// The final report outlives ctx, bounded by the client timeout
+			r.send(context.Background())
+			return
+		case <-ticker.C:
+			r.send(ctx)
+		}
+	}
+}
+
+// Send posts a single report
+func (r *Reporter) Send(ctx context.Context) error {
+	body, err := json.Marshal(r.report())
+	if err != nil {
+		return fmt.Errorf("failed to encode usage report: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create usage report request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send usage report: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("usage report rejected: %s", resp.Status)
+	}
+	return nil
+}
+
+// send posts a report and logs failures
+func (r *Reporter) send(ctx context.Context) {
+	if err := r.Send(ctx); err != nil {
+		r.logger.Info("Failed to send usage report", "error", err.Error())
+	}
+}
+
+// report builds the current Report
+func (r *Reporter) report() Report {
+	return Report{
+		InstanceID:    r.instanceID,
+		Version:       version.Current(),
+		GoVersion:     runtime.Version(),
+		Platform:      runtime.GOOS + "/" + runtime.GOARCH,
+		UptimeSeconds: int64(time.Since(r.started).Seconds()),
+		Plans:         metrics.PlanCount(),
+		Engines:       metrics.EngineRunCounts(),
+	}
+}
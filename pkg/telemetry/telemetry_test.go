@@ -0,0 +1,69 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/millstonehq/crossplane-plan/pkg/metrics"
+)
+
+func TestReporter_Send(t *testing.T) {
+	metrics.DiffEngineRuns.Reset()
+	t.Cleanup(metrics.DiffEngineRuns.Reset)
+	metrics.DiffEngineRuns.WithLabelValues("crossplane-diff").Add(3)
+	metrics.DiffEngineRuns.WithLabelValues("plugin").Inc()
+	plans := metrics.PlanCount()
+	metrics.Plans.Add(2)
+
+	var got map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("request = %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode report: %v", err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	reporter := NewReporter(server.URL, time.Hour, logr.Discard())
+	if err := reporter.Send(context.Background()); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if got["plans"] != float64(plans+2) {
+		t.Errorf("plans = %v, want %d", got["plans"], plans+2)
+	}
+	engines, _ := got["engines"].(map[string]interface{})
+	if engines["crossplane-diff"] != float64(3) || engines["plugin"] != float64(1) {
+		t.Errorf("engines = %v", engines)
+	}
+	if id, _ := got["instanceId"].(string); len(id) != 32 {
+		t.Errorf("instanceId = %q", id)
+	}
+
+	// Only counts and build information are reported
+	allowed := map[string]bool{"instanceId": true, "version": true, "goVersion": true, "platform": true, "uptimeSeconds": true, "plans": true, "engines": true}
+	for field := range got {
+		if !allowed[field] {
+			t.Errorf("unexpected report field %q", field)
+		}
+	}
+}
+
+func TestReporter_SendRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	if err := NewReporter(server.URL, time.Hour, logr.Discard()).Send(context.Background()); err == nil {
+		t.Error("expected an error for a rejected report")
+	}
+}
@@ -0,0 +1,99 @@
+package transport
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// Options configure outbound HTTP connections to external services (e.g., GitHub)
+type Options struct {
+	// ProxyURL routes requests through this proxy instead of HTTPS_PROXY/HTTP_PROXY/NO_PROXY
+	ProxyURL string
+
+	// CABundlePath is a PEM file of additional trusted CAs (e.g., a corporate proxy's private CA)
+	// The system roots remain trusted
+	CABundlePath string
+}
+
+// New creates an HTTP transport honoring the proxy and CA options
+// Without a ProxyURL, the standard proxy environment variables are used
+func New(opts Options) (*http.Transport, error) {
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.Proxy = http.ProxyFromEnvironment
+
+	if opts.ProxyURL != "" {
+		proxyURL, err := url.Parse(opts.ProxyURL)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", opts.ProxyURL)
+		}
+		tr.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if opts.CABundlePath != "" {
+		tlsConfig, err := ClientTLSConfig(opts.CABundlePath)
+		if err != nil {
+			return nil, err
+		}
+		tr.TLSClientConfig = tlsConfig
+	}
+
+	return tr, nil
+}
+
+// ClientTLSConfig returns a TLS config trusting the system roots plus the CAs in caBundlePath
+func ClientTLSConfig(caBundlePath string) (*tls.Config, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+
+	if err := appendCABundle(pool, caBundlePath); err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		RootCAs:    pool,
+		MinVersion: tls.VersionTLS12,
+	}, nil
+}
+
+// ServerTLSConfig returns a TLS config for serving HTTPS with the given certificate
+// When clientCAPath is set, clients must present a certificate signed by one of its CAs (mTLS)
+func ServerTLSConfig(certPath, keyPath, clientCAPath string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if clientCAPath != "" {
+		pool := x509.NewCertPool()
+		if err := appendCABundle(pool, clientCAPath); err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
+}
+
+// appendCABundle adds the PEM certificates in path to pool
+func appendCABundle(pool *x509.CertPool, path string) error {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return fmt.Errorf("no PEM certificates found in CA bundle %s", path)
+	}
+	return nil
+}
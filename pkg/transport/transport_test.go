@@ -0,0 +1,144 @@
+package transport
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeServerCA writes the httptest server's certificate as a PEM CA bundle
+func writeServerCA(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	block := &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatalf("failed to write CA bundle: %v", err)
+	}
+	return path
+}
+
+func TestNew_TrustsCABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	// Without the bundle the self-signed certificate is rejected
+	plain, err := New(Options{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := (&http.Client{Transport: plain}).Get(server.URL); err == nil {
+		t.Error("expected certificate error without CA bundle")
+	}
+
+	tr, err := New(Options{CABundlePath: writeServerCA(t, server)})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	resp, err := (&http.Client{Transport: tr}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() with CA bundle error = %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestNew_ProxyURL(t *testing.T) {
+	tr, err := New(Options{ProxyURL: "http://proxy.corp.example:3128"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://api.github.com/repos", nil)
+	proxy, err := tr.Proxy(req)
+	if err != nil || proxy == nil || proxy.Host != "proxy.corp.example:3128" {
+		t.Errorf("Proxy() = %v, %v, want proxy.corp.example:3128", proxy, err)
+	}
+}
+
+func TestNew_InvalidOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr string
+	}{
+		{name: "proxy without host", opts: Options{ProxyURL: "not a url"}, wantErr: "invalid proxy URL"},
+		{name: "missing CA bundle", opts: Options{CABundlePath: "/nonexistent/ca.pem"}, wantErr: "failed to read CA bundle"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("New() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClientTLSConfig_NoCertificates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.pem")
+	if err := os.WriteFile(path, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ClientTLSConfig(path); err == nil || !strings.Contains(err.Error(), "no PEM certificates") {
+		t.Errorf("ClientTLSConfig() error = %v, want no PEM certificates error", err)
+	}
+}
+
+func TestServerTLSConfig_ClientCA(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	caPath := writeServerCA(t, server)
+
+	// Reuse the httptest certificate as the serving certificate
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "tls.crt")
+	keyPath := filepath.Join(dir, "tls.key")
+	cert := server.TLS.Certificates[0]
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	keyPEM, err := marshalKey(cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyPath, keyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tlsConfig, err := ServerTLSConfig(certPath, keyPath, "")
+	if err != nil {
+		t.Fatalf("ServerTLSConfig() error = %v", err)
+	}
+	if tlsConfig.ClientAuth != tls.NoClientCert {
+		t.Errorf("ClientAuth = %v, want NoClientCert without a client CA", tlsConfig.ClientAuth)
+	}
+
+	tlsConfig, err = ServerTLSConfig(certPath, keyPath, caPath)
+	if err != nil {
+		t.Fatalf("ServerTLSConfig() error = %v", err)
+	}
+	if tlsConfig.ClientAuth != tls.RequireAndVerifyClientCert || tlsConfig.ClientCAs == nil {
+		t.Error("ServerTLSConfig() with client CA should require verified client certificates")
+	}
+
+	if _, err := ServerTLSConfig(filepath.Join(dir, "missing.crt"), keyPath, ""); err == nil {
+		t.Error("ServerTLSConfig() error = nil, want error for missing certificate")
+	}
+}
+
+// marshalKey encodes a certificate's private key as PKCS#8 PEM
+func marshalKey(cert tls.Certificate) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
@@ -0,0 +1,205 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-github/v57/github"
+	"github.com/millstonehq/crossplane-plan/pkg/errclass"
+	"github.com/millstonehq/crossplane-plan/pkg/metrics"
+)
+
+// ErrCircuitOpen is returned instead of calling GitHub while the circuit breaker is open
+var ErrCircuitOpen = errors.New("GitHub circuit breaker is open")
+
+// BreakerState is the state of a CircuitBreaker
+type BreakerState int
+
+const (
+	// BreakerClosed lets all calls through
+	BreakerClosed BreakerState = iota
+	// BreakerOpen rejects all calls until the cooldown has passed
+	BreakerOpen
+	// BreakerHalfOpen lets a single probe call through to test whether GitHub recovered
+	BreakerHalfOpen
+)
+
+// String returns the state name
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// CircuitBreaker stops calling GitHub after repeated failures
+// After threshold consecutive failures it opens and rejects calls; once cooldown
+// has passed a single probe is let through, closing the circuit on success
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	logger    logr.Logger
+	now       func() time.Time
+	state     BreakerState
+	failures  int       // consecutive failures while closed
+	openedAt  time.Time // when the circuit last opened
+	probing   bool      // a half-open probe is in flight
+}
+
+// NewCircuitBreaker creates a CircuitBreaker that opens after threshold consecutive failures
+func NewCircuitBreaker(threshold int, cooldown time.Duration, logger logr.Logger) *CircuitBreaker {
+	metrics.VCSCircuitState.Set(float64(BreakerClosed))
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		logger:    logger.WithName("circuit-breaker"),
+		now:       time.Now,
+	}
+}
+
+// State returns the current state
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Allow reports whether a call may proceed, returning ErrCircuitOpen if not
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			metrics.VCSCircuitRejections.Inc()
+			return ErrCircuitOpen
+		}
+		b.transition(BreakerHalfOpen)
+		b.probing = true
+		return nil
+	case BreakerHalfOpen:
+		if b.probing {
+			metrics.VCSCircuitRejections.Inc()
+			return ErrCircuitOpen
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// Record reports the outcome of a call that was allowed
+func (b *CircuitBreaker) Record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerHalfOpen {
+		b.probing = false
+	}
+
+	if !isBreakerFailure(err) {
+		b.failures = 0
+		if b.state != BreakerClosed {
+			b.transition(BreakerClosed)
+			b.logger.Info("GitHub calls recovered, circuit closed")
+		}
+		return
+	}
+
+	switch b.state {
+	case BreakerHalfOpen:
+		b.open()
+		b.logger.Error(err, "GitHub probe failed, circuit reopened", "cooldown", b.cooldown)
+	case BreakerClosed:
+		b.failures++
+		if b.failures >= b.threshold {
+			b.open()
+			b.logger.Error(err, "GitHub calls failing, circuit opened: comments are skipped until GitHub recovers",
+				"consecutiveFailures", b.failures,
+				"cooldown", b.cooldown)
+		}
+	}
+}
+
+// open moves the breaker to the open state
+func (b *CircuitBreaker) open() {
+	b.openedAt = b.now()
+	b.failures = 0
+	b.transition(BreakerOpen)
+	metrics.VCSCircuitOpens.Inc()
+}
+
+// transition changes state and updates the state gauge
+func (b *CircuitBreaker) transition(state BreakerState) {
+	b.state = state
+	metrics.VCSCircuitState.Set(float64(state))
+}
+
+// isBreakerFailure reports whether an error indicates GitHub is unhealthy
+// Client errors (e.g., a missing PR) mean GitHub answered and don't count;
+// server errors, rate limiting and transport errors do
+func isBreakerFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var rateLimitErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &rateLimitErr) || errors.As(err, &abuseErr) {
+		return true
+	}
+
+	var respErr *github.ErrorResponse
+	if errors.As(err, &respErr) && respErr.Response != nil {
+		status := respErr.Response.StatusCode
+		return status >= http.StatusInternalServerError || status == http.StatusTooManyRequests
+	}
+
+	return true
+}
+
+// SetCircuitBreaker guards GitHub calls with a circuit breaker
+// Clients derived with ForRepository or WithCommentIdentifier share the breaker
+func (c *Client) SetCircuitBreaker(breaker *CircuitBreaker) {
+	c.breaker = breaker
+}
+
+// guard runs a GitHub call through the circuit breaker, if one is set
+func (c *Client) guard(call func() error) error {
+	if c.breaker == nil {
+		return classify(call())
+	}
+	if err := c.breaker.Allow(); err != nil {
+		return fmt.Errorf("skipping GitHub call for %s: %w", c.Repository(), err)
+	}
+	err := call()
+	c.breaker.Record(err)
+	return classify(err)
+}
+
+// classify marks a GitHub API error with its class (auth, rate limited, not found)
+func classify(err error) error {
+	var rateLimit *github.RateLimitError
+	var abuse *github.AbuseRateLimitError
+	var response *github.ErrorResponse
+	switch {
+	case errors.As(err, &rateLimit), errors.As(err, &abuse):
+		return errclass.Wrap(errclass.ErrRateLimited, err)
+	case errors.As(err, &response) && response.Response != nil:
+		return errclass.WrapStatus(response.Response.StatusCode, err)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,173 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-github/v57/github"
+	"github.com/millstonehq/crossplane-plan/pkg/errclass"
+)
+
+func TestCircuitBreaker_OpensAfterThreshold(t *testing.T) {
+	breaker := NewCircuitBreaker(3, time.Minute, logr.Discard())
+	failure := errors.New("connection refused")
+
+	for i := 0; i < 3; i++ {
+		if err := breaker.Allow(); err != nil {
+			t.Fatalf("Allow() call %d error = %v, want nil", i, err)
+		}
+		breaker.Record(failure)
+	}
+
+	if got := breaker.State(); got != BreakerOpen {
+		t.Fatalf("State() = %s, want open", got)
+	}
+	if err := breaker.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Allow() error = %v, want ErrCircuitOpen", err)
+	}
+}
+
+func TestCircuitBreaker_SuccessResetsFailures(t *testing.T) {
+	breaker := NewCircuitBreaker(2, time.Minute, logr.Discard())
+	failure := errors.New("connection refused")
+
+	breaker.Record(failure)
+	breaker.Record(nil)
+	breaker.Record(failure)
+
+	if got := breaker.State(); got != BreakerClosed {
+		t.Errorf("State() = %s, want closed (failures weren't consecutive)", got)
+	}
+}
+
+func TestCircuitBreaker_HalfOpenProbe(t *testing.T) {
+	now := time.Now()
+	breaker := NewCircuitBreaker(1, time.Minute, logr.Discard())
+	breaker.now = func() time.Time { return now }
+
+	breaker.Record(errors.New("connection refused"))
+	if got := breaker.State(); got != BreakerOpen {
+		t.Fatalf("State() = %s, want open", got)
+	}
+
+	// After the cooldown a single probe is allowed
+	now = now.Add(2 * time.Minute)
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("Allow() probe error = %v, want nil", err)
+	}
+	if got := breaker.State(); got != BreakerHalfOpen {
+		t.Fatalf("State() = %s, want half-open", got)
+	}
+	if err := breaker.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Allow() during probe error = %v, want ErrCircuitOpen", err)
+	}
+
+	// Failed probe reopens
+	breaker.Record(errors.New("still down"))
+	if got := breaker.State(); got != BreakerOpen {
+		t.Fatalf("State() = %s, want open after failed probe", got)
+	}
+
+	// Successful probe closes
+	now = now.Add(2 * time.Minute)
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("Allow() probe error = %v, want nil", err)
+	}
+	breaker.Record(nil)
+	if got := breaker.State(); got != BreakerClosed {
+		t.Errorf("State() = %s, want closed after successful probe", got)
+	}
+}
+
+func TestIsBreakerFailure(t *testing.T) {
+	responseErr := func(status int) error {
+		return &github.ErrorResponse{Response: &http.Response{StatusCode: status}}
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "context cancelled", err: context.Canceled, want: false},
+		{name: "not found", err: responseErr(http.StatusNotFound), want: false},
+		{name: "validation failed", err: responseErr(http.StatusUnprocessableEntity), want: false},
+		{name: "server error", err: responseErr(http.StatusBadGateway), want: true},
+		{name: "too many requests", err: responseErr(http.StatusTooManyRequests), want: true},
+		{name: "rate limited", err: &github.RateLimitError{Response: &http.Response{StatusCode: http.StatusForbidden}}, want: true},
+		{name: "transport error", err: errors.New("dial tcp: i/o timeout"), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isBreakerFailure(tt.err); got != tt.want {
+				t.Errorf("isBreakerFailure(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassify(t *testing.T) {
+	responseErr := func(status int) error {
+		return &github.ErrorResponse{Response: &http.Response{StatusCode: status}}
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want errclass.Class
+	}{
+		{name: "unauthorized", err: responseErr(http.StatusUnauthorized), want: errclass.Auth},
+		{name: "forbidden", err: responseErr(http.StatusForbidden), want: errclass.Auth},
+		{name: "not found", err: responseErr(http.StatusNotFound), want: errclass.NotFound},
+		{name: "server error", err: responseErr(http.StatusBadGateway), want: errclass.Unknown},
+		{name: "rate limited", err: &github.RateLimitError{Response: &http.Response{StatusCode: http.StatusForbidden}}, want: errclass.RateLimited},
+		{name: "secondary rate limit", err: &github.AbuseRateLimitError{Response: &http.Response{StatusCode: http.StatusForbidden}}, want: errclass.RateLimited},
+		{name: "wrapped", err: fmt.Errorf("failed to list comments: %w", responseErr(http.StatusUnauthorized)), want: errclass.Auth},
+		{name: "transport error", err: errors.New("dial tcp: i/o timeout"), want: errclass.Unknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classify(tt.err)
+			if got := errclass.ClassOf(err); got != tt.want {
+				t.Errorf("ClassOf(classify(%v)) = %q, want %q", tt.err, got, tt.want)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Error("classify() should keep the original error in the chain")
+			}
+		})
+	}
+}
+
+func TestClient_ForRepository_SharesBreaker(t *testing.T) {
+	client, err := NewClient("test-token", "owner/repo")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	breaker := NewCircuitBreaker(1, time.Minute, logr.Discard())
+	client.SetCircuitBreaker(breaker)
+
+	other, err := client.ForRepository("other-org/infra")
+	if err != nil {
+		t.Fatalf("ForRepository() error = %v", err)
+	}
+	if other.(*Client).breaker != breaker {
+		t.Error("ForRepository() should share the circuit breaker")
+	}
+	if client.WithCommentIdentifier("<!-- x -->").(*Client).breaker != breaker {
+		t.Error("WithCommentIdentifier() should share the circuit breaker")
+	}
+
+	// An open circuit rejects calls without reaching GitHub
+	breaker.Record(errors.New("connection refused"))
+	if err := client.PostComment(context.Background(), 1, "body"); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("PostComment() error = %v, want ErrCircuitOpen", err)
+	}
+}
@@ -0,0 +1,55 @@
+package github
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/millstonehq/crossplane-plan/pkg/vcs"
+)
+
+// ChangedFile is a file a PR adds, modifies, renames or removes
+type ChangedFile = vcs.ChangedFile
+
+// ListChangedFiles returns the files a PR changes
+func (c *Client) ListChangedFiles(ctx context.Context, prNumber int) ([]ChangedFile, error) {
+	var files []ChangedFile
+	err := c.guard(func() error {
+		opts := &github.ListOptions{PerPage: 100}
+		for {
+			page, resp, err := c.client.PullRequests.ListFiles(ctx, c.owner, c.repo, prNumber, opts)
+			if err != nil {
+				return fmt.Errorf("failed to list files of PR #%d: %w", prNumber, err)
+			}
+			for _, file := range page {
+				files = append(files, ChangedFile{
+					Path:         file.GetFilename(),
+					PreviousPath: file.GetPreviousFilename(),
+					Status:       file.GetStatus(),
+					Patch:        file.GetPatch(),
+					URL:          prFileURL(file.GetBlobURL(), prNumber, file.GetFilename()),
+				})
+			}
+
+			if resp.NextPage == 0 {
+				return nil
+			}
+			opts.Page = resp.NextPage
+		}
+	})
+	return files, err
+}
+
+// prFileURL links to a file in a PR's "Files changed" view, whose anchors are the SHA-256 of the path
+// The repository URL is taken from the file's blob URL, so it also works on GitHub Enterprise
+func prFileURL(blobURL string, prNumber int, file string) string {
+	repoURL, _, found := strings.Cut(blobURL, "/blob/")
+	if !found {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(file))
+	return fmt.Sprintf("%s/pull/%d/files#diff-%s", repoURL, prNumber, hex.EncodeToString(sum[:]))
+}
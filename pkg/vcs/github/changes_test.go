@@ -0,0 +1,73 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient_ListChangedFiles(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/repos/acme/platform/pulls/42/files") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"filename": "clusters/prod/network.yaml", "status": "modified", "patch": "@@ -1 +1 @@\n-  cidr: 10.0.0.0/16\n+  cidr: 10.1.0.0/16",
+			 "blob_url": "https://github.com/acme/platform/blob/abc123/clusters/prod/network.yaml"}
+		]`))
+	}))
+	defer server.Close()
+
+	client, err := NewClientFromConfig(&ClientConfig{Token: "token", BaseURL: server.URL + "/", Repository: "acme/platform"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := client.ListChangedFiles(context.Background(), 42)
+	if err != nil {
+		t.Fatalf("ListChangedFiles() error = %v", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("ListChangedFiles() = %v, want 1 file", files)
+	}
+	if files[0].Path != "clusters/prod/network.yaml" || files[0].Status != "modified" {
+		t.Errorf("ListChangedFiles()[0] = %+v", files[0])
+	}
+	want := "https://github.com/acme/platform/pull/42/files#diff-"
+	if !strings.HasPrefix(files[0].URL, want) || len(files[0].URL) != len(want)+64 {
+		t.Errorf("URL = %q, want %s<sha256>", files[0].URL, want)
+	}
+}
+
+func TestChangedFile_Mentions(t *testing.T) {
+	file := ChangedFile{Patch: strings.Join([]string{
+		"@@ -1,6 +1,6 @@",
+		" apiVersion: example.org/v1alpha1",
+		" kind: XNetwork",
+		" metadata:",
+		"   name: vpc",
+		" spec:",
+		"-  cidr: 10.0.0.0/16",
+		"+  cidr: 10.1.0.0/16",
+		"+- name: \"subnet-a\"",
+	}, "\n")}
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "vpc", want: true},
+		{name: "subnet-a", want: true},
+		{name: "vp", want: false},
+		{name: "", want: false},
+	}
+	for _, tt := range tests {
+		if got := file.Mentions(tt.name); got != tt.want {
+			t.Errorf("Mentions(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
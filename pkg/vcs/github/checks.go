@@ -0,0 +1,153 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/millstonehq/crossplane-plan/pkg/vcs"
+)
+
+// CheckRunName is the name of the check runs crossplane-plan publishes
+const CheckRunName = "crossplane-plan"
+
+// StatusContext identifies the commit statuses crossplane-plan publishes, e.g. in branch protection rules
+const StatusContext = "crossplane-plan/plan"
+
+// maxStatusDescription is GitHub's size limit of a commit status description
+const maxStatusDescription = 140
+
+// maxCheckOutput is GitHub's size limit of a check run's output summary and text
+const maxCheckOutput = 65535
+
+// maxAnnotationsPerRequest is how many annotations GitHub accepts per check run request
+const maxAnnotationsPerRequest = 50
+
+// Check run conclusions
+const (
+	ConclusionSuccess = vcs.ConclusionSuccess
+	ConclusionNeutral = vcs.ConclusionNeutral
+)
+
+// CheckRun is the output of a completed check run
+type CheckRun = vcs.CheckRun
+
+// HeadSHA returns the SHA of a PR's head commit
+func (c *Client) HeadSHA(ctx context.Context, prNumber int) (string, error) {
+	var sha string
+	err := c.guard(func() error {
+		pull, _, err := c.client.PullRequests.Get(ctx, c.owner, c.repo, prNumber)
+		if err != nil {
+			return fmt.Errorf("failed to get PR #%d: %w", prNumber, err)
+		}
+		sha = pull.GetHead().GetSHA()
+		return nil
+	})
+	return sha, err
+}
+
+// CreateCheckRun publishes a completed check run on a commit
+// The summary and text are truncated to GitHub's limits; annotations beyond the first
+// maxAnnotationsPerRequest are added by updating the check run
+// Check runs can only be created with GitHub App credentials, not personal access tokens
+func (c *Client) CreateCheckRun(ctx context.Context, headSHA string, run CheckRun) error {
+	output := func(annotations []vcs.CheckAnnotation) *github.CheckRunOutput {
+		output := &github.CheckRunOutput{
+			Title:   github.String(run.Title),
+			Summary: github.String(truncateCheckOutput(run.Summary)),
+		}
+		if run.Text != "" {
+			output.Text = github.String(truncateCheckOutput(run.Text))
+		}
+		for _, annotation := range annotations {
+			output.Annotations = append(output.Annotations, &github.CheckRunAnnotation{
+				Path:            github.String(annotation.Path),
+				StartLine:       github.Int(annotation.Line),
+				EndLine:         github.Int(annotation.Line),
+				AnnotationLevel: github.String(annotation.Level),
+				Title:           github.String(annotation.Title),
+				Message:         github.String(annotation.Message),
+			})
+		}
+		return output
+	}
+	annotations := run.Annotations
+	first := annotations[:min(len(annotations), maxAnnotationsPerRequest)]
+	opts := github.CreateCheckRunOptions{
+		Name:        CheckRunName,
+		HeadSHA:     headSHA,
+		Status:      github.String("completed"),
+		Conclusion:  github.String(run.Conclusion),
+		CompletedAt: &github.Timestamp{Time: time.Now()},
+		Output:      output(first),
+	}
+
+	return c.guard(func() error {
+		created, _, err := c.client.Checks.CreateCheckRun(ctx, c.owner, c.repo, opts)
+		if err != nil {
+			return fmt.Errorf("failed to create check run: %w", err)
+		}
+		for rest := annotations[len(first):]; len(rest) > 0; {
+			batch := rest[:min(len(rest), maxAnnotationsPerRequest)]
+			rest = rest[len(batch):]
+			update := github.UpdateCheckRunOptions{Name: CheckRunName, Output: output(batch)}
+			if _, _, err := c.client.Checks.UpdateCheckRun(ctx, c.owner, c.repo, created.GetID(), update); err != nil {
+				return fmt.Errorf("failed to add check run annotations: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// CreateCommitStatus publishes a status on a commit under StatusContext
+// Unlike check runs, statuses can be created with personal access tokens
+func (c *Client) CreateCommitStatus(ctx context.Context, sha string, status vcs.CommitStatus) error {
+	description := status.Description
+	if len(description) > maxStatusDescription {
+		cut := maxStatusDescription - len("…")
+		for cut > 0 && !utf8.RuneStart(description[cut]) {
+			cut--
+		}
+		description = description[:cut] + "…"
+	}
+	repoStatus := &github.RepoStatus{
+		State:       github.String(status.State),
+		Description: github.String(description),
+		Context:     github.String(StatusContext),
+	}
+	if status.TargetURL != "" {
+		repoStatus.TargetURL = github.String(status.TargetURL)
+	}
+
+	return c.guard(func() error {
+		if _, _, err := c.client.Repositories.CreateStatus(ctx, c.owner, c.repo, sha, repoStatus); err != nil {
+			return fmt.Errorf("failed to create commit status: %w", err)
+		}
+		return nil
+	})
+}
+
+// truncateCheckOutput cuts a check run output to maxCheckOutput bytes, on a rune boundary
+func truncateCheckOutput(s string) string {
+	return truncate(s, maxCheckOutput)
+}
+
+// truncate cuts s to limit bytes, on a rune boundary, with a notice
+// A code block left open by the cut is closed
+func truncate(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	const notice, fence = "\n\n_(truncated)_", "\n```"
+	cut := max(limit-len(notice)-len(fence), 0)
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	if strings.Count(s[:cut], "```")%2 == 1 {
+		return s[:cut] + fence + notice
+	}
+	return s[:cut] + notice
+}
@@ -0,0 +1,166 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/millstonehq/crossplane-plan/pkg/vcs"
+)
+
+func TestClient_CreateCheckRun(t *testing.T) {
+	var created map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/repos/acme/platform/pulls/7"):
+			_, _ = w.Write([]byte(`{"number": 7, "head": {"sha": "abc123"}}`))
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/repos/acme/platform/issues/7/comments"):
+			_, _ = w.Write([]byte(`[{"id": 1, "body": "lgtm"}, {"id": 2, "body": "<!-- crossplane-plan-comment -->\n\n## Plan", "html_url": "https://github.com/acme/platform/pull/7#issuecomment-2"}]`))
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/repos/acme/platform/issues/8/comments"):
+			_, _ = w.Write([]byte(`[]`))
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/repos/acme/platform/check-runs"):
+			_ = json.NewDecoder(r.Body).Decode(&created)
+			_, _ = w.Write([]byte(`{"id": 1}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClientFromConfig(&ClientConfig{Token: "token", BaseURL: server.URL + "/", Repository: "acme/platform"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sha, err := client.HeadSHA(context.Background(), 7)
+	if err != nil || sha != "abc123" {
+		t.Fatalf("HeadSHA() = %q, %v, want abc123", sha, err)
+	}
+	run := CheckRun{Title: "2 changes", Summary: "**Risk:** medium", Conclusion: ConclusionNeutral}
+	if err := client.CreateCheckRun(context.Background(), sha, run); err != nil {
+		t.Fatalf("CreateCheckRun() error = %v", err)
+	}
+
+	if created["name"] != CheckRunName || created["head_sha"] != "abc123" || created["status"] != "completed" || created["conclusion"] != "neutral" {
+		t.Errorf("created check run = %v", created)
+	}
+	output, _ := created["output"].(map[string]interface{})
+	if output["title"] != "2 changes" || output["summary"] != "**Risk:** medium" {
+		t.Errorf("check run output = %v", output)
+	}
+	if _, ok := output["text"]; ok {
+		t.Error("check run output has a text without details")
+	}
+
+	if url, err := client.CommentURL(context.Background(), 7); err != nil || url != "https://github.com/acme/platform/pull/7#issuecomment-2" {
+		t.Errorf("CommentURL() = %q, %v", url, err)
+	}
+	if url, err := client.CommentURL(context.Background(), 8); err != nil || url != "" {
+		t.Errorf("CommentURL() of a PR without a comment = %q, %v, want empty", url, err)
+	}
+}
+
+func TestClient_CreateCheckRun_Annotations(t *testing.T) {
+	var batches []int
+	var first map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Output struct {
+				Annotations []map[string]interface{} `json:"annotations"`
+			} `json:"output"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/repos/acme/platform/check-runs"):
+			first = body.Output.Annotations[0]
+		case r.Method == http.MethodPatch && strings.HasSuffix(r.URL.Path, "/repos/acme/platform/check-runs/9"):
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		batches = append(batches, len(body.Output.Annotations))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": 9}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClientFromConfig(&ClientConfig{Token: "token", BaseURL: server.URL + "/", Repository: "acme/platform"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run := CheckRun{Title: "60 changed, 0 deleted (change)", Summary: "summary", Conclusion: ConclusionNeutral}
+	for i := 1; i <= 60; i++ {
+		run.Annotations = append(run.Annotations, vcs.CheckAnnotation{Path: "apps/network.yaml", Line: i, Level: vcs.AnnotationNotice, Title: "vpc", Message: "1 resource modified"})
+	}
+	if err := client.CreateCheckRun(context.Background(), "abc123", run); err != nil {
+		t.Fatalf("CreateCheckRun() error = %v", err)
+	}
+
+	if len(batches) != 2 || batches[0] != 50 || batches[1] != 10 {
+		t.Errorf("annotation batches = %v, want [50 10]", batches)
+	}
+	if first["path"] != "apps/network.yaml" || first["start_line"] != float64(1) || first["end_line"] != float64(1) || first["annotation_level"] != "notice" {
+		t.Errorf("first annotation = %v", first)
+	}
+}
+
+func TestClient_CreateCommitStatus(t *testing.T) {
+	var path string
+	var created map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&created)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": 1}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClientFromConfig(&ClientConfig{Token: "token", BaseURL: server.URL + "/", Repository: "acme/platform"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status := vcs.CommitStatus{State: vcs.StatusFailure, Description: strings.Repeat("é", 100), TargetURL: "https://github.com/acme/platform/pull/7#issuecomment-2"}
+	if err := client.CreateCommitStatus(context.Background(), "abc123", status); err != nil {
+		t.Fatalf("CreateCommitStatus() error = %v", err)
+	}
+
+	if !strings.HasSuffix(path, "/repos/acme/platform/statuses/abc123") {
+		t.Errorf("posted to %s, want the statuses of abc123", path)
+	}
+	if created["context"] != StatusContext || created["state"] != "failure" || created["target_url"] != status.TargetURL {
+		t.Errorf("created status = %v", created)
+	}
+	description, _ := created["description"].(string)
+	if len(description) > maxStatusDescription || !utf8.ValidString(description) || !strings.HasSuffix(description, "…") {
+		t.Errorf("description = %q, want it cut to %d bytes", description, maxStatusDescription)
+	}
+}
+
+func TestTruncateCheckOutput(t *testing.T) {
+	if got := truncateCheckOutput("short"); got != "short" {
+		t.Errorf("truncateCheckOutput() = %q, want it unchanged", got)
+	}
+
+	long := strings.Repeat("é", maxCheckOutput)
+	got := truncateCheckOutput(long)
+	if len(got) > maxCheckOutput || !strings.HasSuffix(got, "_(truncated)_") {
+		t.Errorf("truncateCheckOutput() = %d bytes, want at most %d with a notice", len(got), maxCheckOutput)
+	}
+	if !utf8.ValidString(got) {
+		t.Error("truncateCheckOutput() cut a rune")
+	}
+}
+
+func TestTruncate_ClosesCodeBlock(t *testing.T) {
+	got := truncate("```diff\n"+strings.Repeat("+ line\n", 100), 100)
+	if len(got) > 100 || !strings.HasSuffix(got, "\n```\n\n_(truncated)_") {
+		t.Errorf("truncate() = %q, want the code block closed before the notice", got)
+	}
+}
@@ -0,0 +1,91 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// staleMarker follows the identifier of comments marked stale
+const staleMarker = "<!-- crossplane-plan-stale -->"
+
+// ListOpenPRs returns the numbers of the repository's open PRs
+func (c *Client) ListOpenPRs(ctx context.Context) ([]int, error) {
+	var prs []int
+	err := c.guard(func() error {
+		opts := &github.PullRequestListOptions{
+			State:       "open",
+			ListOptions: github.ListOptions{PerPage: 100},
+		}
+		for {
+			pulls, resp, err := c.client.PullRequests.List(ctx, c.owner, c.repo, opts)
+			if err != nil {
+				return fmt.Errorf("failed to list open pull requests: %w", err)
+			}
+			for _, pull := range pulls {
+				prs = append(prs, pull.GetNumber())
+			}
+
+			if resp.NextPage == 0 {
+				return nil
+			}
+			opts.Page = resp.NextPage
+		}
+	})
+	return prs, err
+}
+
+// ListCommentedPRs returns the open PRs that have a crossplane-plan comment
+func (c *Client) ListCommentedPRs(ctx context.Context) ([]int, error) {
+	open, err := c.ListOpenPRs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var prs []int
+	err = c.guard(func() error {
+		for _, prNumber := range open {
+			comment, err := c.findComment(ctx, prNumber)
+			if err != nil {
+				return fmt.Errorf("failed to list comments of PR #%d: %w", prNumber, err)
+			}
+			if comment != nil {
+				prs = append(prs, prNumber)
+			}
+		}
+		return nil
+	})
+	return prs, err
+}
+
+// MarkCommentStale prefixes a PR's crossplane-plan comment with a notice that it is out of date
+// Returns whether the comment was edited; comments already marked are left alone
+// The next plan of the PR replaces the notice along with the rest of the comment
+func (c *Client) MarkCommentStale(ctx context.Context, prNumber int, reason string) (bool, error) {
+	edited := false
+	err := c.guard(func() error {
+		existing, err := c.findComment(ctx, prNumber)
+		if err != nil {
+			return fmt.Errorf("failed to find existing comment: %w", err)
+		}
+		if existing == nil || strings.Contains(existing.GetBody(), staleMarker) {
+			return nil
+		}
+
+		if err := c.writeComment(ctx, prNumber, existing, c.staleBody(existing.GetBody(), reason)); err != nil {
+			return err
+		}
+		edited = true
+		return nil
+	})
+	return edited, err
+}
+
+// staleBody inserts the stale notice after a comment's identifier
+func (c *Client) staleBody(commentBody, reason string) string {
+	notice := fmt.Sprintf("%s\n> [!WARNING]\n> **Stale preview:** %s. This comment no longer reflects the PR.\n", staleMarker, reason)
+	rest := strings.TrimPrefix(commentBody, c.Identifier())
+	return c.Identifier() + "\n" + notice + rest
+}
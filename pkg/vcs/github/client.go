@@ -7,29 +7,48 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/bradleyfalzon/ghinstallation/v2"
 	"github.com/google/go-github/v57/github"
+	"github.com/millstonehq/crossplane-plan/pkg/errclass"
+	"github.com/millstonehq/crossplane-plan/pkg/vcs"
 	"golang.org/x/oauth2"
 )
 
 const (
 	// CommentIdentifier is used to identify crossplane-plan comments
 	CommentIdentifier = "<!-- crossplane-plan-comment -->"
+
+	// maxCommentLength is GitHub's size limit of an issue comment
+	maxCommentLength = 65536
+
+	// footerMarker precedes the optional footer (timing, "last updated" line), which is ignored when comparing comments
+	footerMarker = "<!-- crossplane-plan-last-updated -->"
 )
 
 // Client is a GitHub API client for posting PR comments
 type Client struct {
-	client *github.Client
-	owner  string
-	repo   string
+	client            *github.Client
+	owner             string
+	repo              string
+	commentIdentifier string // overrides CommentIdentifier when set
+	breaker           *CircuitBreaker
+	showLastUpdated   bool // append a "last updated" line to comments
+	blobs             *blobCache
 }
 
+var _ vcs.Client = (*Client)(nil)
+
 // ClientConfig holds authentication configuration for GitHub
 type ClientConfig struct {
 	// Token-based authentication (PAT or OAuth token)
 	Token string
 
+	// TokenFile holds a pre-minted token, e.g. an installation access token refreshed by a sidecar
+	// where the GitHub App private key can't be distributed to the cluster; re-read when it changes
+	TokenFile string
+
 	// GitHub App authentication
 	AppID          string // GitHub App ID
 	InstallationID string // Installation ID for the app
@@ -41,6 +60,14 @@ type ClientConfig struct {
 
 	// Repository (required)
 	Repository string // Format: owner/repo
+
+	// BaseURL is the API URL of GitHub Enterprise Server, e.g. "https://github.example.com/api/v3/"
+	// Defaults to github.com
+	BaseURL string
+
+	// Transport carries all GitHub requests (e.g., configured with a proxy or private CA)
+	// Defaults to http.DefaultTransport
+	Transport http.RoundTripper
 }
 
 // crossplaneProviderCredentials represents the JSON structure used by crossplane-provider-github
@@ -61,58 +88,149 @@ func NewClient(token, repository string) (*Client, error) {
 	})
 }
 
+// Option configures a Client when it is created
+type Option func(*Client)
+
+// WithShowLastUpdated appends a "last updated" line to comments
+func WithShowLastUpdated(show bool) Option {
+	return func(c *Client) { c.SetShowLastUpdated(show) }
+}
+
+// WithCircuitBreaker stops calling GitHub while it keeps failing
+func WithCircuitBreaker(breaker *CircuitBreaker) Option {
+	return func(c *Client) { c.SetCircuitBreaker(breaker) }
+}
+
 // NewClientFromConfig creates a new GitHub client from configuration
 // Supports multiple authentication methods:
 // 1. Token authentication (PAT or OAuth)
-// 2. Crossplane provider credentials format (plain JSON from Kubernetes secret)
-// 3. GitHub App authentication (direct credentials)
-func NewClientFromConfig(config *ClientConfig) (*Client, error) {
+// 2. Token file (pre-minted installation access token)
+// 3. Crossplane provider credentials format (plain JSON from Kubernetes secret)
+// 4. GitHub App authentication (direct credentials)
+func NewClientFromConfig(config *ClientConfig, opts ...Option) (*Client, error) {
 	// Parse repository (format: owner/repo)
-	parts := strings.Split(config.Repository, "/")
-	if len(parts) != 2 {
-		return nil, fmt.Errorf("invalid repository format: %s (expected owner/repo)", config.Repository)
+	owner, repo, err := parseRepository(config.Repository)
+	if err != nil {
+		return nil, errclass.Wrap(errclass.ErrConfig, err)
 	}
-	owner, repo := parts[0], parts[1]
 
 	var httpClient *http.Client
 
+	base := config.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
 	// Determine authentication method (in priority order)
 	if config.Token != "" {
 		// Token-based authentication (PAT or OAuth)
-		ctx := context.Background()
+		ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Transport: base})
 		ts := oauth2.StaticTokenSource(
 			&oauth2.Token{AccessToken: config.Token},
 		)
 		httpClient = oauth2.NewClient(ctx, ts)
+	} else if config.TokenFile != "" {
+		// Pre-minted token, refreshed outside crossplane-plan
+		ts, err := newFileTokenSource(config.TokenFile)
+		if err != nil {
+			return nil, errclass.Wrap(errclass.ErrConfig, err)
+		}
+		httpClient = &http.Client{Transport: &oauth2.Transport{Source: ts, Base: base}}
 	} else if config.Credentials != "" {
 		// Crossplane provider credentials format (plain JSON from Kubernetes)
-		client, err := createClientFromCrossplaneCredentials(config.Credentials)
+		client, err := createClientFromCrossplaneCredentials(base, config.BaseURL, config.Credentials)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse crossplane credentials: %w", err)
+			return nil, errclass.Wrap(errclass.ErrConfig, fmt.Errorf("failed to parse crossplane credentials: %w", err))
 		}
 		httpClient = client
 	} else if config.AppID != "" && config.InstallationID != "" && len(config.PrivateKey) > 0 {
 		// GitHub App authentication (direct credentials)
-		client, err := createClientFromGitHubApp(config.AppID, config.InstallationID, config.PrivateKey)
+		client, err := createClientFromGitHubApp(base, config.BaseURL, config.AppID, config.InstallationID, config.PrivateKey)
 		if err != nil {
 			return nil, err
 		}
 		httpClient = client
 	} else {
-		return nil, fmt.Errorf("no valid authentication provided: either token, credentials, or GitHub App credentials (appID, installationID, privateKey) required")
+		return nil, errclass.Wrap(errclass.ErrConfig, fmt.Errorf("no valid authentication provided: either token, token file, credentials, or GitHub App credentials (appID, installationID, privateKey) required"))
 	}
 
-	return &Client{
-		client: github.NewClient(httpClient),
+	ghClient := github.NewClient(httpClient)
+	if config.BaseURL != "" {
+		ghClient, err = ghClient.WithEnterpriseURLs(config.BaseURL, config.BaseURL)
+		if err != nil {
+			return nil, errclass.Wrap(errclass.ErrConfig, fmt.Errorf("invalid GitHub API URL: %w", err))
+		}
+	}
+
+	c := &Client{
+		client: ghClient,
 		owner:  owner,
 		repo:   repo,
+		blobs:  &blobCache{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Repository returns the repository this client posts to (format: owner/repo)
+func (c *Client) Repository() string {
+	return c.owner + "/" + c.repo
+}
+
+// ForRepository returns a client for another repository that shares this client's authentication
+func (c *Client) ForRepository(repository string) (vcs.Client, error) {
+	owner, repo, err := parseRepository(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		client:          c.client,
+		owner:           owner,
+		repo:            repo,
+		breaker:         c.breaker,
+		showLastUpdated: c.showLastUpdated,
+		blobs:           c.blobs,
 	}, nil
 }
 
+// WithCommentIdentifier returns a copy of the client that marks its comments with identifier
+func (c *Client) WithCommentIdentifier(identifier string) vcs.Client {
+	clone := *c
+	clone.commentIdentifier = identifier
+	return &clone
+}
+
+// SetShowLastUpdated appends a "last updated" line to comments
+// Comments whose content is otherwise unchanged are not edited, so the line only
+// moves when the plan does
+func (c *Client) SetShowLastUpdated(show bool) {
+	c.showLastUpdated = show
+}
+
+// Identifier returns the hidden marker used to find this client's comments
+func (c *Client) Identifier() string {
+	if c.commentIdentifier != "" {
+		return c.commentIdentifier
+	}
+	return CommentIdentifier
+}
+
+// parseRepository splits a repository into owner and name (format: owner/repo)
+func parseRepository(repository string) (string, string, error) {
+	parts := strings.Split(repository, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid repository format: %s (expected owner/repo)", repository)
+	}
+	return parts[0], parts[1], nil
+}
+
 // createClientFromCrossplaneCredentials parses crossplane provider credentials and creates HTTP client
 // Note: Kubernetes automatically decodes base64 when mounting secrets as env vars,
 // so the input is already plain JSON (not base64-encoded)
-func createClientFromCrossplaneCredentials(credentialsJSON string) (*http.Client, error) {
+func createClientFromCrossplaneCredentials(base http.RoundTripper, baseURL, credentialsJSON string) (*http.Client, error) {
 	// Parse JSON directly (already decoded by Kubernetes)
 	var creds crossplaneProviderCredentials
 	if err := json.Unmarshal([]byte(credentialsJSON), &creds); err != nil {
@@ -130,11 +248,12 @@ func createClientFromCrossplaneCredentials(credentialsJSON string) (*http.Client
 	}
 
 	// Create GitHub App client
-	return createClientFromGitHubApp(appAuth.ID, appAuth.InstallationID, []byte(appAuth.PemFile))
+	return createClientFromGitHubApp(base, baseURL, appAuth.ID, appAuth.InstallationID, []byte(appAuth.PemFile))
 }
 
 // createClientFromGitHubApp creates an HTTP client using GitHub App credentials
-func createClientFromGitHubApp(appID, installationID string, privateKey []byte) (*http.Client, error) {
+// baseURL is the GitHub Enterprise Server API URL (empty for github.com)
+func createClientFromGitHubApp(base http.RoundTripper, baseURL, appID, installationID string, privateKey []byte) (*http.Client, error) {
 	appIDInt, err := strconv.ParseInt(appID, 10, 64)
 	if err != nil {
 		return nil, fmt.Errorf("invalid GitHub App ID: %w", err)
@@ -146,32 +265,101 @@ func createClientFromGitHubApp(appID, installationID string, privateKey []byte)
 	}
 
 	// Create GitHub App transport
-	itr, err := ghinstallation.New(http.DefaultTransport, appIDInt, installationIDInt, privateKey)
+	itr, err := ghinstallation.New(base, appIDInt, installationIDInt, privateKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create GitHub App transport: %w", err)
 	}
+	if baseURL != "" {
+		itr.BaseURL = strings.TrimSuffix(baseURL, "/")
+	}
 
 	return &http.Client{Transport: itr}, nil
 }
 
 // PostComment posts or updates a comment on a PR
 // If a crossplane-plan comment already exists, it updates it; otherwise creates a new one
+// An existing comment with the same content is left untouched to avoid "edited" notifications
 func (c *Client) PostComment(ctx context.Context, prNumber int, body string) error {
-	// Add identifier to comment body
-	commentBody := CommentIdentifier + "\n\n" + body
+	_, err := c.PostCommentIfChanged(ctx, prNumber, body)
+	return err
+}
 
-	// Find existing crossplane-plan comment
-	existingCommentID, err := c.findExistingComment(ctx, prNumber)
-	if err != nil {
-		return fmt.Errorf("failed to find existing comment: %w", err)
+// PostCommentIfChanged posts or updates a comment on a PR unless the existing comment already has this content
+// The "last updated" line is ignored in the comparison. Returns whether the comment was written
+func (c *Client) PostCommentIfChanged(ctx context.Context, prNumber int, body string) (bool, error) {
+	return c.PostCommentWithFooter(ctx, prNumber, body, "")
+}
+
+// PostCommentWithFooter is PostCommentIfChanged with a footer that changes on every plan (e.g., timing)
+// The footer is ignored when deciding whether the comment changed
+func (c *Client) PostCommentWithFooter(ctx context.Context, prNumber int, body, footer string) (bool, error) {
+	commentBody := c.commentBody(body, footer)
+
+	written := false
+	err := c.guard(func() error {
+		existing, err := c.findComment(ctx, prNumber)
+		if err != nil {
+			return fmt.Errorf("failed to find existing comment: %w", err)
+		}
+
+		if existing != nil && commentContent(existing.GetBody()) == commentContent(commentBody) {
+			return nil
+		}
+
+		if err := c.writeComment(ctx, prNumber, existing, commentBody); err != nil {
+			return err
+		}
+		written = true
+		return nil
+	})
+	return written, err
+}
+
+// CommentBody returns the body PostCommentWithFooter would write for a comment and footer
+func (c *Client) CommentBody(body, footer string) string {
+	return c.commentBody(body, footer)
+}
+
+// Capabilities returns how GitHub renders comments
+func (c *Client) Capabilities() vcs.Capabilities {
+	return vcs.Capabilities{CollapsibleSections: true, MaxCommentLength: maxCommentLength}
+}
+
+// commentBody adds the identifier and the footer (with the "last updated" line, if enabled) to a comment body
+// The body is truncated so the comment fits GitHub's size limit
+func (c *Client) commentBody(body, footer string) string {
+	header := c.Identifier() + "\n\n"
+	var trailer string
+	if footer != "" || c.showLastUpdated {
+		trailer = "\n" + footerMarker + "\n"
+		if footer != "" {
+			trailer += footer + "\n"
+		}
+		if c.showLastUpdated {
+			trailer += fmt.Sprintf("_Last updated: %s_\n", time.Now().UTC().Format("2006-01-02 15:04:05 UTC"))
+		}
+	}
+	return header + truncate(body, maxCommentLength-len(header)-len(trailer)) + trailer
+}
+
+// commentContent returns the part of a comment body that is compared for changes,
+// dropping the footer
+func commentContent(commentBody string) string {
+	if i := strings.Index(commentBody, "\n"+footerMarker); i >= 0 {
+		return commentBody[:i]
 	}
+	return commentBody
+}
 
-	if existingCommentID != nil {
+// writeComment updates the existing comment, or creates one if there is none
+// commentBody already carries the identifier
+func (c *Client) writeComment(ctx context.Context, prNumber int, existing *github.IssueComment, commentBody string) error {
+	if existing != nil {
 		// Update existing comment
 		comment := &github.IssueComment{
 			Body: &commentBody,
 		}
-		_, _, err := c.client.Issues.EditComment(ctx, c.owner, c.repo, *existingCommentID, comment)
+		_, _, err := c.client.Issues.EditComment(ctx, c.owner, c.repo, existing.GetID(), comment)
 		if err != nil {
 			return fmt.Errorf("failed to update comment: %w", err)
 		}
@@ -182,7 +370,7 @@ func (c *Client) PostComment(ctx context.Context, prNumber int, body string) err
 	comment := &github.IssueComment{
 		Body: &commentBody,
 	}
-	_, _, err = c.client.Issues.CreateComment(ctx, c.owner, c.repo, prNumber, comment)
+	_, _, err := c.client.Issues.CreateComment(ctx, c.owner, c.repo, prNumber, comment)
 	if err != nil {
 		return fmt.Errorf("failed to create comment: %w", err)
 	}
@@ -190,8 +378,17 @@ func (c *Client) PostComment(ctx context.Context, prNumber int, body string) err
 	return nil
 }
 
-// findExistingComment finds an existing crossplane-plan comment on the PR
+// findExistingComment finds the ID of an existing crossplane-plan comment on the PR
 func (c *Client) findExistingComment(ctx context.Context, prNumber int) (*int64, error) {
+	comment, err := c.findComment(ctx, prNumber)
+	if err != nil || comment == nil {
+		return nil, err
+	}
+	return comment.ID, nil
+}
+
+// findComment finds an existing crossplane-plan comment on the PR
+func (c *Client) findComment(ctx context.Context, prNumber int) (*github.IssueComment, error) {
 	opts := &github.IssueListCommentsOptions{
 		ListOptions: github.ListOptions{PerPage: 100},
 	}
@@ -203,8 +400,8 @@ func (c *Client) findExistingComment(ctx context.Context, prNumber int) (*int64,
 		}
 
 		for _, comment := range comments {
-			if comment.Body != nil && strings.HasPrefix(*comment.Body, CommentIdentifier) {
-				return comment.ID, nil
+			if comment.Body != nil && strings.HasPrefix(*comment.Body, c.Identifier()) {
+				return comment, nil
 			}
 		}
 
@@ -217,8 +414,29 @@ func (c *Client) findExistingComment(ctx context.Context, prNumber int) (*int64,
 	return nil, nil
 }
 
+// CommentURL returns the web URL of a PR's crossplane-plan comment, or "" if it has none
+func (c *Client) CommentURL(ctx context.Context, prNumber int) (string, error) {
+	var url string
+	err := c.guard(func() error {
+		comment, err := c.findComment(ctx, prNumber)
+		if err != nil {
+			return fmt.Errorf("failed to find existing comment: %w", err)
+		}
+		url = comment.GetHTMLURL()
+		return nil
+	})
+	return url, err
+}
+
 // DeleteComment deletes a crossplane-plan comment from a PR
 func (c *Client) DeleteComment(ctx context.Context, prNumber int) error {
+	return c.guard(func() error {
+		return c.deleteComment(ctx, prNumber)
+	})
+}
+
+// deleteComment deletes the crossplane-plan comment, if there is one
+func (c *Client) deleteComment(ctx context.Context, prNumber int) error {
 	commentID, err := c.findExistingComment(ctx, prNumber)
 	if err != nil {
 		return fmt.Errorf("failed to find existing comment: %w", err)
@@ -2,7 +2,12 @@ package github
 
 import (
 	"encoding/json"
+	"net/http"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
 )
 
 func TestNewClient_ValidRepo(t *testing.T) {
@@ -24,6 +29,23 @@ func TestNewClient_ValidRepo(t *testing.T) {
 	}
 }
 
+func TestNewClientFromConfig_Options(t *testing.T) {
+	breaker := NewCircuitBreaker(1, time.Minute, logr.Discard())
+	client, err := NewClientFromConfig(&ClientConfig{Token: "test-token", Repository: "owner/repo"},
+		WithShowLastUpdated(true),
+		WithCircuitBreaker(breaker),
+	)
+	if err != nil {
+		t.Fatalf("NewClientFromConfig() error = %v", err)
+	}
+	if !client.showLastUpdated {
+		t.Error("showLastUpdated = false, want true")
+	}
+	if client.breaker != breaker {
+		t.Error("breaker not set")
+	}
+}
+
 func TestNewClient_InvalidRepo(t *testing.T) {
 	tests := []struct {
 		name string
@@ -260,7 +282,7 @@ func TestCreateClientFromCrossplaneCredentials(t *testing.T) {
 		"owner": "test-owner"
 	}`
 
-	_, err := createClientFromCrossplaneCredentials(validCreds)
+	_, err := createClientFromCrossplaneCredentials(http.DefaultTransport, "", validCreds)
 	// Will fail on transport creation with fake PEM, but parsing should work
 	if err == nil {
 		t.Error("Expected error with fake PEM")
@@ -302,7 +324,7 @@ func TestCreateClientFromGitHubApp(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := createClientFromGitHubApp(tt.appID, tt.installationID, tt.privateKey)
+			_, err := createClientFromGitHubApp(http.DefaultTransport, "", tt.appID, tt.installationID, tt.privateKey)
 			if err == nil {
 				t.Error("createClientFromGitHubApp() error = nil, want error")
 			} else if !contains(err.Error(), tt.wantErrPart) {
@@ -336,3 +358,135 @@ func contains(s, substr string) bool {
 //
 // For a thin wrapper with minimal business logic, integration tests are more appropriate.
 // See docs/testing.md for integration test strategy.
+
+func TestClient_ForRepository(t *testing.T) {
+	client, err := NewClient("test-token", "owner/repo")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	if got := client.Repository(); got != "owner/repo" {
+		t.Errorf("Repository() = %s, want owner/repo", got)
+	}
+
+	other, err := client.ForRepository("other-org/infra")
+	if err != nil {
+		t.Fatalf("ForRepository() error = %v", err)
+	}
+	if got := other.Repository(); got != "other-org/infra" {
+		t.Errorf("Repository() = %s, want other-org/infra", got)
+	}
+	if other.(*Client).client != client.client {
+		t.Error("ForRepository() should share the authenticated GitHub client")
+	}
+
+	// Original client is unchanged
+	if got := client.Repository(); got != "owner/repo" {
+		t.Errorf("original Repository() = %s, want owner/repo", got)
+	}
+}
+
+func TestClient_ForRepository_InvalidRepo(t *testing.T) {
+	client, err := NewClient("test-token", "owner/repo")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	for _, repo := range []string{"", "ownerrepo", "owner/", "/repo", "owner/repo/extra"} {
+		t.Run(repo, func(t *testing.T) {
+			if _, err := client.ForRepository(repo); err == nil {
+				t.Errorf("ForRepository(%q) error = nil, want error", repo)
+			}
+		})
+	}
+}
+
+func TestClient_WithCommentIdentifier(t *testing.T) {
+	client, err := NewClient("test-token", "owner/repo")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	if got := client.Identifier(); got != CommentIdentifier {
+		t.Errorf("identifier() = %q, want %q", got, CommentIdentifier)
+	}
+
+	custom := client.WithCommentIdentifier("<!-- crossplane-plan-comment:platform -->")
+	if got := custom.Identifier(); got != "<!-- crossplane-plan-comment:platform -->" {
+		t.Errorf("identifier() = %q, want custom identifier", got)
+	}
+	if got := client.Identifier(); got != CommentIdentifier {
+		t.Errorf("original identifier() = %q, want %q", got, CommentIdentifier)
+	}
+	if custom.Repository() != client.Repository() {
+		t.Errorf("Repository() = %s, want %s", custom.Repository(), client.Repository())
+	}
+}
+
+func TestCommentContent_IgnoresLastUpdated(t *testing.T) {
+	client, err := NewClient("test-token", "owner/repo")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	client.SetShowLastUpdated(true)
+
+	body := client.commentBody("## Plan", "")
+	if !strings.Contains(body, "_Last updated: ") {
+		t.Fatalf("commentBody() = %q, want last updated line", body)
+	}
+
+	earlier := CommentIdentifier + "\n\n## Plan\n" + footerMarker + "\n_Last updated: 2020-01-01 00:00:00 UTC_\n"
+	if commentContent(earlier) != commentContent(body) {
+		t.Errorf("commentContent() should ignore the last updated line:\n%q\nvs\n%q", commentContent(earlier), commentContent(body))
+	}
+
+	changed := CommentIdentifier + "\n\n## Other plan\n" + footerMarker + "\n_Last updated: 2020-01-01 00:00:00 UTC_\n"
+	if commentContent(changed) == commentContent(body) {
+		t.Error("commentContent() should differ when the plan changes")
+	}
+
+	// Comments posted without the line compare equal to the same content with it
+	client.SetShowLastUpdated(false)
+	if commentContent(client.commentBody("## Plan", "")) != commentContent(body) {
+		t.Error("commentContent() should match with and without the last updated line")
+	}
+
+	// The timing footer is ignored as well
+	withFooter := client.commentBody("## Plan", "rendered 1 resource in 0.4s")
+	if !strings.Contains(withFooter, "\n"+footerMarker+"\nrendered 1 resource in 0.4s\n") {
+		t.Fatalf("commentBody() = %q, want footer after the marker", withFooter)
+	}
+	if commentContent(withFooter) != commentContent(body) {
+		t.Error("commentContent() should ignore the footer")
+	}
+}
+
+func TestCommentBody_FitsSizeLimit(t *testing.T) {
+	client, err := NewClient("test-token", "owner/repo")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	body := client.commentBody(strings.Repeat("x", maxCommentLength), "rendered 1 resource in 0.4s")
+	if len(body) > client.Capabilities().MaxCommentLength {
+		t.Errorf("commentBody() = %d bytes, want at most %d", len(body), client.Capabilities().MaxCommentLength)
+	}
+	if !strings.HasPrefix(body, CommentIdentifier) || !strings.Contains(body, "_(truncated)_") || !strings.Contains(body, "rendered 1 resource in 0.4s") {
+		t.Errorf("commentBody() should keep the identifier and footer around the truncated body")
+	}
+}
+
+func TestStaleBody(t *testing.T) {
+	client, err := NewClient("test-token", "owner/repo")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	stale := client.staleBody(client.commentBody("## Plan", ""), "this PR no longer has preview resources")
+	if !strings.HasPrefix(stale, CommentIdentifier+"\n"+staleMarker+"\n") {
+		t.Errorf("staleBody() = %q, want identifier followed by the stale marker", stale)
+	}
+	if !strings.Contains(stale, "this PR no longer has preview resources") || !strings.HasSuffix(stale, "## Plan") {
+		t.Errorf("staleBody() = %q, want the notice followed by the original plan", stale)
+	}
+}
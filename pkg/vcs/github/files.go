@@ -0,0 +1,108 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+)
+
+// maxCachedBlobs bounds the blob cache; it is cleared when full
+const maxCachedBlobs = 2048
+
+// blobCache caches file contents by blob SHA, which never changes for a given content
+type blobCache struct {
+	mu    sync.Mutex
+	blobs map[string][]byte
+}
+
+// get returns a cached blob
+func (b *blobCache) get(sha string) ([]byte, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	data, ok := b.blobs[sha]
+	return data, ok
+}
+
+// put caches a blob
+func (b *blobCache) put(sha string, data []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.blobs == nil || len(b.blobs) >= maxCachedBlobs {
+		b.blobs = make(map[string][]byte)
+	}
+	b.blobs[sha] = data
+}
+
+// GetManifests returns the YAML files under paths at ref, keyed by file path
+// An empty ref reads the repository's default branch; empty paths read the whole repository
+// Unchanged files are served from a cache, so repeated reads only fetch the tree
+func (c *Client) GetManifests(ctx context.Context, ref string, paths []string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	err := c.guard(func() error {
+		if ref == "" {
+			repository, _, err := c.client.Repositories.Get(ctx, c.owner, c.repo)
+			if err != nil {
+				return fmt.Errorf("failed to get default branch: %w", err)
+			}
+			ref = repository.GetDefaultBranch()
+		}
+
+		tree, _, err := c.client.Git.GetTree(ctx, c.owner, c.repo, ref, true)
+		if err != nil {
+			return fmt.Errorf("failed to get tree of %s: %w", ref, err)
+		}
+		if tree.GetTruncated() {
+			return fmt.Errorf("tree of %s is too large to list, narrow down the paths", ref)
+		}
+
+		for _, entry := range tree.Entries {
+			if entry.GetType() != "blob" || !isManifest(entry.GetPath(), paths) {
+				continue
+			}
+			data, err := c.blob(ctx, entry.GetSHA())
+			if err != nil {
+				return fmt.Errorf("failed to get %s: %w", entry.GetPath(), err)
+			}
+			files[entry.GetPath()] = data
+		}
+		return nil
+	})
+	return files, err
+}
+
+// blob returns the contents of a blob, fetching it if it isn't cached
+func (c *Client) blob(ctx context.Context, sha string) ([]byte, error) {
+	if c.blobs != nil {
+		if data, ok := c.blobs.get(sha); ok {
+			return data, nil
+		}
+	}
+
+	data, _, err := c.client.Git.GetBlobRaw(ctx, c.owner, c.repo, sha)
+	if err != nil {
+		return nil, err
+	}
+	if c.blobs != nil {
+		c.blobs.put(sha, data)
+	}
+	return data, nil
+}
+
+// isManifest reports whether a file is a YAML file under one of paths (directories or files)
+func isManifest(file string, paths []string) bool {
+	if ext := path.Ext(file); ext != ".yaml" && ext != ".yml" {
+		return false
+	}
+	if len(paths) == 0 {
+		return true
+	}
+	for _, p := range paths {
+		p = strings.Trim(path.Clean(p), "/")
+		if p == "." || file == p || strings.HasPrefix(file, p+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+package github
+
+import "testing"
+
+func TestIsManifest(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string
+		paths []string
+		want  bool
+	}{
+		{name: "any yaml without paths", file: "apps/db.yaml", want: true},
+		{name: "yml extension", file: "apps/db.yml", want: true},
+		{name: "not yaml", file: "apps/README.md", want: false},
+		{name: "under directory", file: "infra/prod/db.yaml", paths: []string{"infra/prod"}, want: true},
+		{name: "trailing slash", file: "infra/prod/db.yaml", paths: []string{"/infra/prod/"}, want: true},
+		{name: "directory name prefix only", file: "infra/production/db.yaml", paths: []string{"infra/prod"}, want: false},
+		{name: "exact file", file: "infra/db.yaml", paths: []string{"infra/db.yaml"}, want: true},
+		{name: "outside paths", file: "apps/db.yaml", paths: []string{"infra"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isManifest(tt.file, tt.paths); got != tt.want {
+				t.Errorf("isManifest(%q, %v) = %v, want %v", tt.file, tt.paths, got, tt.want)
+			}
+		})
+	}
+}
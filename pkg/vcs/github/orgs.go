@@ -0,0 +1,38 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// ListOrgRepositories returns the "owner/repo" names of an organization's repositories
+// Archived repositories are skipped, as are repositories without topic when one is given
+func (c *Client) ListOrgRepositories(ctx context.Context, org, topic string) ([]string, error) {
+	var repos []string
+	err := c.guard(func() error {
+		opts := &github.RepositoryListByOrgOptions{
+			ListOptions: github.ListOptions{PerPage: 100},
+		}
+		for {
+			page, resp, err := c.client.Repositories.ListByOrg(ctx, org, opts)
+			if err != nil {
+				return fmt.Errorf("failed to list repositories of %s: %w", org, err)
+			}
+			for _, repo := range page {
+				if repo.GetArchived() || (topic != "" && !slices.Contains(repo.Topics, topic)) {
+					continue
+				}
+				repos = append(repos, repo.GetFullName())
+			}
+
+			if resp.NextPage == 0 {
+				return nil
+			}
+			opts.Page = resp.NextPage
+		}
+	})
+	return repos, err
+}
@@ -0,0 +1,49 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient_ListOrgRepositories(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/orgs/acme/repos") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"full_name": "acme/network", "topics": ["platform"]},
+			{"full_name": "acme/data", "topics": ["platform", "data"]},
+			{"full_name": "acme/website", "topics": ["web"]},
+			{"full_name": "acme/legacy", "topics": ["platform"], "archived": true}
+		]`))
+	}))
+	defer server.Close()
+
+	client, err := NewClientFromConfig(&ClientConfig{Token: "token", BaseURL: server.URL + "/", Repository: "acme/platform"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		topic string
+		want  string
+	}{
+		{topic: "", want: "acme/network,acme/data,acme/website"},
+		{topic: "platform", want: "acme/network,acme/data"},
+		{topic: "missing", want: ""},
+	}
+	for _, tt := range tests {
+		repos, err := client.ListOrgRepositories(context.Background(), "acme", tt.topic)
+		if err != nil {
+			t.Fatalf("ListOrgRepositories(%q) error = %v", tt.topic, err)
+		}
+		if got := strings.Join(repos, ","); got != tt.want {
+			t.Errorf("ListOrgRepositories(%q) = %q, want %q", tt.topic, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/bradleyfalzon/ghinstallation/v2"
+	"github.com/google/go-github/v57/github"
+	"github.com/millstonehq/crossplane-plan/pkg/errclass"
+)
+
+// appPermissions are the permissions of a GitHub App installation token relevant to commenting
+type appPermissions struct {
+	Issues       string
+	PullRequests string
+}
+
+// Probe verifies the credentials can read the repository and comment on its PRs
+// Returns an actionable error, so misconfigured credentials fail at startup instead of on the first comment
+// Permissions that can't be inspected (fine-grained tokens, token files) are only checked for read access
+func (c *Client) Probe(ctx context.Context) error {
+	repo, resp, err := c.client.Repositories.Get(ctx, c.owner, c.repo)
+	if err != nil {
+		status := 0
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		switch status {
+		case http.StatusUnauthorized:
+			return errclass.Wrap(errclass.ErrAuth, fmt.Errorf("GitHub rejected the credentials: check the token or GitHub App ID, installation ID and private key: %w", err))
+		case http.StatusForbidden, http.StatusNotFound:
+			return errclass.Wrap(errclass.ErrAuth, fmt.Errorf("repository %s is not visible to the credentials: check the repository name and that the token (or GitHub App installation) has access to it: %w", c.Repository(), err))
+		}
+		return fmt.Errorf("failed to read repository %s: %w", c.Repository(), err)
+	}
+
+	var header http.Header
+	if resp != nil {
+		header = resp.Header
+	}
+	return commentAccessProblem(repo, header, c.installationPermissions())
+}
+
+// installationPermissions returns the permissions of a GitHub App installation token, or nil for other credentials
+// The token is minted on the first request, so this must be called after one
+func (c *Client) installationPermissions() *appPermissions {
+	itr, ok := c.client.Client().Transport.(*ghinstallation.Transport)
+	if !ok {
+		return nil
+	}
+	perms, err := itr.Permissions()
+	if err != nil {
+		return nil
+	}
+	return &appPermissions{Issues: perms.GetIssues(), PullRequests: perms.GetPullRequests()}
+}
+
+// commentAccessProblem checks that credentials that can read repo may also comment on its PRs
+// header is the response of reading the repository; app is set for GitHub App installation tokens
+func commentAccessProblem(repo *github.Repository, header http.Header, app *appPermissions) error {
+	name := repo.GetFullName()
+
+	if app != nil {
+		if app.Issues != "write" && app.PullRequests != "write" {
+			return errclass.Wrap(errclass.ErrAuth, fmt.Errorf("the GitHub App installation can't comment on pull requests in %s: grant it the \"Pull requests: Read and write\" (or \"Issues: Read and write\") permission and accept the updated permissions", name))
+		}
+		return nil
+	}
+
+	// Classic personal access tokens report their scopes
+	if scopes, ok := header["X-Oauth-Scopes"]; ok {
+		var granted []string
+		for _, scope := range strings.Split(strings.Join(scopes, ","), ",") {
+			granted = append(granted, strings.TrimSpace(scope))
+		}
+		if !slices.Contains(granted, "repo") && (repo.GetPrivate() || !slices.Contains(granted, "public_repo")) {
+			return errclass.Wrap(errclass.ErrAuth, fmt.Errorf("the token can't comment on pull requests in %s: it needs the \"repo\" scope (\"public_repo\" for public repositories)", name))
+		}
+	}
+
+	if repo.Permissions != nil && !repo.Permissions["pull"] {
+		return errclass.Wrap(errclass.ErrAuth, fmt.Errorf("the token's user has no read access to %s, which commenting on pull requests requires", name))
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+)
+
+func TestCommentAccessProblem(t *testing.T) {
+	private := &github.Repository{FullName: github.String("owner/repo"), Private: github.Bool(true)}
+	public := &github.Repository{FullName: github.String("owner/repo"), Private: github.Bool(false)}
+
+	tests := []struct {
+		name    string
+		repo    *github.Repository
+		header  http.Header
+		app     *appPermissions
+		wantErr string
+	}{
+		{
+			name: "app with pull requests write",
+			repo: private,
+			app:  &appPermissions{PullRequests: "write"},
+		},
+		{
+			name: "app with issues write",
+			repo: private,
+			app:  &appPermissions{Issues: "write", PullRequests: "read"},
+		},
+		{
+			name:    "app with read only",
+			repo:    private,
+			app:     &appPermissions{Issues: "read", PullRequests: "read"},
+			wantErr: "Pull requests: Read and write",
+		},
+		{
+			name:   "classic token with repo scope",
+			repo:   private,
+			header: http.Header{"X-Oauth-Scopes": {"read:org, repo"}},
+		},
+		{
+			name:    "classic token without repo scope",
+			repo:    private,
+			header:  http.Header{"X-Oauth-Scopes": {"read:org"}},
+			wantErr: `needs the "repo" scope`,
+		},
+		{
+			name:   "public_repo scope on public repository",
+			repo:   public,
+			header: http.Header{"X-Oauth-Scopes": {"public_repo"}},
+		},
+		{
+			name:    "public_repo scope on private repository",
+			repo:    private,
+			header:  http.Header{"X-Oauth-Scopes": {"public_repo"}},
+			wantErr: `needs the "repo" scope`,
+		},
+		{
+			name: "fine-grained token can't be inspected",
+			repo: private,
+		},
+		{
+			name:    "user without read access",
+			repo:    &github.Repository{FullName: github.String("owner/repo"), Permissions: map[string]bool{"pull": false}},
+			wantErr: "no read access",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := commentAccessProblem(tt.repo, tt.header, tt.app)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("commentAccessProblem() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("commentAccessProblem() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClient_Probe(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		scopes  string
+		wantErr string
+	}{
+		{name: "accessible", status: http.StatusOK, scopes: "repo"},
+		{name: "missing scope", status: http.StatusOK, scopes: "gist", wantErr: `"repo" scope`},
+		{name: "bad credentials", status: http.StatusUnauthorized, wantErr: "rejected the credentials"},
+		{name: "repository not visible", status: http.StatusNotFound, wantErr: "not visible to the credentials"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("X-OAuth-Scopes", tt.scopes)
+				w.WriteHeader(tt.status)
+				if tt.status == http.StatusOK {
+					_, _ = w.Write([]byte(`{"full_name": "owner/repo", "private": true, "permissions": {"pull": true}}`))
+				} else {
+					_, _ = w.Write([]byte(`{"message": "error"}`))
+				}
+			}))
+			defer server.Close()
+
+			client, err := NewClientFromConfig(&ClientConfig{Token: "token", BaseURL: server.URL + "/", Repository: "owner/repo"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = client.Probe(context.Background())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Probe() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Probe() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// IsDraft reports whether a PR is a draft, i.e. not yet ready for review
+func (c *Client) IsDraft(ctx context.Context, prNumber int) (bool, error) {
+	var draft bool
+	err := c.guard(func() error {
+		pull, _, err := c.client.PullRequests.Get(ctx, c.owner, c.repo, prNumber)
+		if err != nil {
+			return fmt.Errorf("failed to get PR #%d: %w", prNumber, err)
+		}
+		draft = pull.GetDraft()
+		return nil
+	})
+	return draft, err
+}
+
+// FindOpenPR returns the number of the open PR whose head is a branch of the repository
+func (c *Client) FindOpenPR(ctx context.Context, branch string) (int, error) {
+	var prNumber int
+	err := c.guard(func() error {
+		opts := &github.PullRequestListOptions{State: "open", Head: c.owner + ":" + branch}
+		pulls, _, err := c.client.PullRequests.List(ctx, c.owner, c.repo, opts)
+		if err != nil {
+			return fmt.Errorf("failed to list pull requests of branch %s: %w", branch, err)
+		}
+		if len(pulls) == 0 {
+			return fmt.Errorf("branch %s has no open pull request", branch)
+		}
+		prNumber = pulls[0].GetNumber()
+		return nil
+	})
+	return prNumber, err
+}
@@ -0,0 +1,78 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient_IsDraft(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/repos/acme/platform/pulls/1"):
+			_, _ = w.Write([]byte(`{"number": 1, "draft": true}`))
+		case strings.HasSuffix(r.URL.Path, "/repos/acme/platform/pulls/2"):
+			_, _ = w.Write([]byte(`{"number": 2, "draft": false}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClientFromConfig(&ClientConfig{Token: "token", BaseURL: server.URL + "/", Repository: "acme/platform"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		prNumber int
+		want     bool
+		wantErr  bool
+	}{
+		{prNumber: 1, want: true},
+		{prNumber: 2, want: false},
+		{prNumber: 3, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := client.IsDraft(context.Background(), tt.prNumber)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("IsDraft(%d) error = %v, wantErr %v", tt.prNumber, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("IsDraft(%d) = %v, want %v", tt.prNumber, got, tt.want)
+		}
+	}
+}
+
+func TestClient_FindOpenPR(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if !strings.HasSuffix(r.URL.Path, "/repos/acme/platform/pulls") || r.URL.Query().Get("state") != "open" {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.URL.Query().Get("head") {
+		case "acme:feature/network":
+			_, _ = w.Write([]byte(`[{"number": 42}]`))
+		default:
+			_, _ = w.Write([]byte(`[]`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClientFromConfig(&ClientConfig{Token: "token", BaseURL: server.URL + "/", Repository: "acme/platform"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := client.FindOpenPR(context.Background(), "feature/network")
+	if err != nil || got != 42 {
+		t.Errorf("FindOpenPR() = %d, %v, want 42", got, err)
+	}
+	if _, err := client.FindOpenPR(context.Background(), "merged"); err == nil {
+		t.Error("FindOpenPR() of a branch without an open PR succeeded, want an error")
+	}
+}
@@ -0,0 +1,54 @@
+package github
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// fileTokenSource reads a pre-minted token, e.g. an installation access token refreshed by a sidecar
+// The file is re-read whenever it changes, so rotated tokens are picked up without a restart
+type fileTokenSource struct {
+	path    string
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	token   string
+}
+
+// newFileTokenSource creates a token source reading path, failing if it holds no token yet
+func newFileTokenSource(path string) (*fileTokenSource, error) {
+	ts := &fileTokenSource{path: path}
+	if _, err := ts.Token(); err != nil {
+		return nil, err
+	}
+	return ts, nil
+}
+
+// Token returns the file's token, re-reading the file if it changed
+func (s *fileTokenSource) Token() (*oauth2.Token, error) {
+	// Stat follows symlinks, so Secret volume updates (an atomic symlink swap) are noticed too
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token file: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token == "" || !info.ModTime().Equal(s.modTime) || info.Size() != s.size {
+		data, err := os.ReadFile(s.path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read token file: %w", err)
+		}
+		token := strings.TrimSpace(string(data))
+		if token == "" {
+			return nil, fmt.Errorf("token file %s is empty", s.path)
+		}
+		s.token, s.modTime, s.size = token, info.ModTime(), info.Size()
+	}
+	return &oauth2.Token{AccessToken: s.token, TokenType: "Bearer"}, nil
+}
@@ -0,0 +1,80 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewClientFromConfig_TokenFile(t *testing.T) {
+	var mu sync.Mutex
+	var authHeaders []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"default_branch": "main"}`))
+	}))
+	defer server.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("ghs_first\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := NewClientFromConfig(&ClientConfig{
+		TokenFile:  tokenFile,
+		BaseURL:    server.URL + "/api/v3/",
+		Repository: "owner/repo",
+	})
+	if err != nil {
+		t.Fatalf("NewClientFromConfig() error = %v", err)
+	}
+
+	if _, _, err := client.client.Repositories.Get(context.Background(), "owner", "repo"); err != nil {
+		t.Fatalf("request error = %v", err)
+	}
+
+	// A sidecar rotates the token
+	if err := os.WriteFile(tokenFile, []byte("ghs_second_token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(tokenFile, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := client.client.Repositories.Get(context.Background(), "owner", "repo"); err != nil {
+		t.Fatalf("request error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"Bearer ghs_first", "Bearer ghs_second_token"}
+	if len(authHeaders) != 2 || authHeaders[0] != want[0] || authHeaders[1] != want[1] {
+		t.Errorf("Authorization headers = %q, want %q", authHeaders, want)
+	}
+}
+
+func TestNewClientFromConfig_TokenFileErrors(t *testing.T) {
+	empty := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(empty, []byte("\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, path := range map[string]string{
+		"missing file": filepath.Join(t.TempDir(), "missing"),
+		"empty file":   empty,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewClientFromConfig(&ClientConfig{TokenFile: path, Repository: "owner/repo"}); err == nil {
+				t.Error("NewClientFromConfig() error = nil, want error")
+			}
+		})
+	}
+}
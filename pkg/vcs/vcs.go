@@ -0,0 +1,211 @@
+// Package vcs describes the version control systems plans are posted to, so the watcher
+// doesn't depend on a particular provider
+package vcs
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Check run conclusions
+const (
+	ConclusionSuccess = "success"
+	ConclusionNeutral = "neutral"
+)
+
+// Check run annotation levels
+const (
+	AnnotationNotice  = "notice"
+	AnnotationWarning = "warning"
+	AnnotationFailure = "failure"
+)
+
+// Commit status states
+const (
+	StatusSuccess = "success"
+	StatusFailure = "failure"
+	StatusPending = "pending"
+)
+
+// Capabilities describe how a VCS renders PR comments
+type Capabilities struct {
+	// CollapsibleSections is true if <details> sections render folded
+	CollapsibleSections bool
+
+	// MaxCommentLength is the size limit of a comment, in bytes (0 for no limit)
+	MaxCommentLength int
+}
+
+// ChangedFile is a file a PR adds, modifies, renames or removes
+type ChangedFile struct {
+	Path string
+	// PreviousPath is the path before a rename
+	PreviousPath string
+	// Status is "added", "modified", "renamed", "removed", ...
+	Status string
+	// Patch is the unified diff of the file (empty for binary or very large files)
+	Patch string
+	// URL links to the file in the PR's "Files changed" view
+	URL string
+}
+
+// Mentions reports whether the file's patch touches a manifest named name
+// It matches "name: <name>" lines, so it also finds the manifests of XRs the PR only partly changes
+func (f ChangedFile) Mentions(name string) bool {
+	if name == "" || f.Patch == "" {
+		return false
+	}
+	pattern := regexp.MustCompile(`(?m)^[ +-]\s*(?:- )?name:\s*["']?` + regexp.QuoteMeta(name) + `["']?\s*$`)
+	return pattern.MatchString(f.Patch)
+}
+
+// Line returns the line of the new file at which its patch declares a manifest named name,
+// or 0 if the patch doesn't show it
+func (f ChangedFile) Line(name string) int {
+	if name == "" || f.Patch == "" {
+		return 0
+	}
+	pattern := regexp.MustCompile(`^[ +]\s*(?:- )?name:\s*["']?` + regexp.QuoteMeta(name) + `["']?\s*$`)
+	var line int
+	for _, text := range strings.Split(f.Patch, "\n") {
+		if match := hunkHeader.FindStringSubmatch(text); match != nil {
+			line, _ = strconv.Atoi(match[1])
+			continue
+		}
+		if pattern.MatchString(text) {
+			return line
+		}
+		if !strings.HasPrefix(text, "-") {
+			line++
+		}
+	}
+	return 0
+}
+
+// hunkHeader matches the header of a patch hunk, capturing the hunk's first line in the new file
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// CheckRun is the output of a completed check run
+type CheckRun struct {
+	Title   string
+	Summary string
+	// Text holds details shown below the summary (optional)
+	Text       string
+	Conclusion string
+	// Annotations point at lines of the PR's files (optional)
+	Annotations []CheckAnnotation
+}
+
+// CheckAnnotation is a check run message on a line of a file
+type CheckAnnotation struct {
+	Path string
+	// Line is the line of the file, from 1
+	Line  int
+	Level string
+	Title string
+	// Message is plain text
+	Message string
+}
+
+// CommitStatus is the status of a commit, e.g. required by branch protection
+type CommitStatus struct {
+	State string
+	// Description is a one-line summary
+	Description string
+	// TargetURL links the status to the plan (optional)
+	TargetURL string
+}
+
+// Client posts plans to the PRs of one repository
+// A client keeps one sticky comment per PR, found by its hidden identifier
+type Client interface {
+	Comments
+	PullRequests
+	Checks
+	Repositories
+
+	// Repository returns the repository this client posts to (format: owner/repo)
+	Repository() string
+
+	// ForRepository returns a client for another repository that shares this client's authentication
+	ForRepository(repository string) (Client, error)
+
+	// Capabilities describe how the VCS renders comments
+	Capabilities() Capabilities
+
+	// Probe verifies the credentials can read the repository and comment on its PRs
+	Probe(ctx context.Context) error
+}
+
+// Comments manages the sticky comment of PRs
+type Comments interface {
+	// Identifier returns the hidden marker used to find this client's comments
+	Identifier() string
+
+	// WithCommentIdentifier returns a copy of the client that marks its comments with identifier
+	WithCommentIdentifier(identifier string) Client
+
+	// PostCommentIfChanged posts or updates a PR's comment unless it already has this content
+	// Returns whether the comment was written
+	PostCommentIfChanged(ctx context.Context, prNumber int, body string) (bool, error)
+
+	// PostCommentWithFooter is PostCommentIfChanged with a footer that is ignored when deciding
+	// whether the comment changed
+	PostCommentWithFooter(ctx context.Context, prNumber int, body, footer string) (bool, error)
+
+	// CommentBody returns the body PostCommentWithFooter would write for a comment and footer
+	CommentBody(body, footer string) string
+
+	// CommentURL returns the web URL of a PR's comment, or "" if it has none
+	CommentURL(ctx context.Context, prNumber int) (string, error)
+
+	// DeleteComment deletes a PR's comment
+	DeleteComment(ctx context.Context, prNumber int) error
+
+	// MarkCommentStale prefixes a PR's comment with a notice that it is out of date
+	// Returns whether the comment was edited
+	MarkCommentStale(ctx context.Context, prNumber int, reason string) (bool, error)
+
+	// ListCommentedPRs returns the open PRs that have a comment
+	ListCommentedPRs(ctx context.Context) ([]int, error)
+}
+
+// PullRequests reads PRs
+type PullRequests interface {
+	// ListOpenPRs returns the numbers of the repository's open PRs
+	ListOpenPRs(ctx context.Context) ([]int, error)
+
+	// FindOpenPR returns the number of the open PR whose head is a branch of the repository
+	FindOpenPR(ctx context.Context, branch string) (int, error)
+
+	// IsDraft reports whether a PR is a draft
+	IsDraft(ctx context.Context, prNumber int) (bool, error)
+
+	// HeadSHA returns the SHA of a PR's head commit
+	HeadSHA(ctx context.Context, prNumber int) (string, error)
+
+	// ListChangedFiles returns the files a PR changes
+	ListChangedFiles(ctx context.Context, prNumber int) ([]ChangedFile, error)
+}
+
+// Checks publishes check runs and statuses on commits
+type Checks interface {
+	// CreateCheckRun publishes a completed check run on a commit
+	CreateCheckRun(ctx context.Context, headSHA string, run CheckRun) error
+
+	// CreateCommitStatus publishes a status on a commit, replacing the previous one
+	CreateCommitStatus(ctx context.Context, sha string, status CommitStatus) error
+}
+
+// Repositories reads repository content and lists repositories
+type Repositories interface {
+	// GetManifests returns the YAML files under paths at ref, keyed by file path
+	// An empty ref reads the default branch; empty paths read the whole repository
+	GetManifests(ctx context.Context, ref string, paths []string) (map[string][]byte, error)
+
+	// ListOrgRepositories returns the "owner/repo" names of an organization's repositories,
+	// optionally only those with a topic
+	ListOrgRepositories(ctx context.Context, org, topic string) ([]string, error)
+}
@@ -0,0 +1,35 @@
+package vcs
+
+import "testing"
+
+func TestChangedFile_Line(t *testing.T) {
+	file := ChangedFile{Patch: `@@ -1,4 +1,4 @@
+ apiVersion: platform.example.com/v1alpha1
+ kind: XNetwork
+ metadata:
+-  name: legacy
++  name: vpc
+@@ -20,3 +20,6 @@ spec:
+ ---
+ kind: XDatabase
+ metadata:
++  name: "orders"
++  labels:
++    app: orders`}
+
+	tests := []struct {
+		name string
+		want int
+	}{
+		{name: "vpc", want: 4},
+		{name: "orders", want: 23},
+		{name: "legacy", want: 0},
+		{name: "missing", want: 0},
+		{name: "", want: 0},
+	}
+	for _, tt := range tests {
+		if got := file.Line(tt.name); got != tt.want {
+			t.Errorf("Line(%q) = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Package version reports the running crossplane-plan version and checks for newer releases
+package version
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-github/v57/github"
+	"golang.org/x/mod/semver"
+)
+
+const (
+	// Owner and Repo are the GitHub repository crossplane-plan is released from
+	Owner = "millstonehq"
+	Repo  = "crossplane-plan"
+
+	// CheckInterval is how often releases are checked; GitHub is asked at most once per interval
+	CheckInterval = 24 * time.Hour
+
+	// requestTimeout bounds a release check
+	requestTimeout = 30 * time.Second
+)
+
+// Current returns the module version crossplane-plan was built from, e.g. "v0.9.0",
+// "(devel)" for local builds, or "unknown"
+func Current() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "unknown"
+}
+
+// Checker looks up the latest crossplane-plan release on GitHub
+type Checker struct {
+	client  *github.Client
+	current string
+	logger  logr.Logger
+}
+
+// NewChecker creates a Checker for the running version current, sending requests through
+// transport (nil for the default transport)
+// Releases are read anonymously, which GitHub allows 60 times an hour per IP
+func NewChecker(current string, transport http.RoundTripper, logger logr.Logger) *Checker {
+	return newChecker(github.NewClient(&http.Client{Transport: transport, Timeout: requestTimeout}), current, logger)
+}
+
+func newChecker(client *github.Client, current string, logger logr.Logger) *Checker {
+	return &Checker{client: client, current: current, logger: logger.WithName("update-check")}
+}
+
+// Newer returns the latest release if it is newer than the running version, or ""
+// Pre-releases and drafts are never reported; neither are newer releases of unversioned builds
+func (c *Checker) Newer(ctx context.Context) (string, error) {
+	if !semver.IsValid(c.current) {
+		return "", nil
+	}
+	release, _, err := c.client.Repositories.GetLatestRelease(ctx, Owner, Repo)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the latest release: %w", err)
+	}
+	latest := release.GetTagName()
+	if !strings.HasPrefix(latest, "v") {
+		latest = "v" + latest
+	}
+	if !semver.IsValid(latest) || semver.Compare(latest, c.current) <= 0 {
+		return "", nil
+	}
+	return latest, nil
+}
+
+// Run checks for a newer release now and every CheckInterval until ctx is done, passing the
+// result ("" if the running version is current) to update
+// Failed checks are logged and keep the previous result
+func (c *Checker) Run(ctx context.Context, update func(latest string)) {
+	ticker := time.NewTicker(CheckInterval)
+	defer ticker.Stop()
+	for {
+		latest, err := c.Newer(ctx)
+		if err != nil {
+			c.logger.Info("Update check failed", "error", err.Error())
+		} else {
+			if latest != "" {
+				c.logger.Info("A newer crossplane-plan release is available", "current", c.current, "latest", latest)
+			}
+			update(latest)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
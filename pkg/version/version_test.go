@@ -0,0 +1,52 @@
+package version
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-github/v57/github"
+)
+
+func TestChecker_Newer(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		latest  string
+		want    string
+	}{
+		{name: "newer release", current: "v0.9.0", latest: "v0.10.0", want: "v0.10.0"},
+		{name: "tag without v prefix", current: "v0.9.0", latest: "0.9.1", want: "v0.9.1"},
+		{name: "up to date", current: "v0.10.0", latest: "v0.10.0", want: ""},
+		{name: "running a newer build", current: "v0.11.0-rc.1", latest: "v0.10.0", want: ""},
+		{name: "local build", current: "(devel)", latest: "v0.10.0", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/repos/millstonehq/crossplane-plan/releases/latest" {
+					http.NotFound(w, r)
+					return
+				}
+				fmt.Fprintf(w, `{"tag_name": %q}`, tt.latest)
+			}))
+			defer server.Close()
+
+			client := github.NewClient(nil)
+			client.BaseURL, _ = url.Parse(server.URL + "/")
+			checker := newChecker(client, tt.current, logr.Discard())
+
+			got, err := checker.Newer(context.Background())
+			if err != nil {
+				t.Fatalf("Newer() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Newer() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
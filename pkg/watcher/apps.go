@@ -0,0 +1,225 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/millstonehq/crossplane-plan/pkg/api"
+	"github.com/millstonehq/crossplane-plan/pkg/argocd"
+	"github.com/millstonehq/crossplane-plan/pkg/store"
+	"github.com/millstonehq/crossplane-plan/pkg/workqueue"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// watchApplications watches ArgoCD Applications so PRs that only deploy non-XR resources are planned too
+func (w *XRWatcher) watchApplications(ctx context.Context) {
+	w.logger.Info("Watching ArgoCD Applications", "namespace", w.argocdClient.Namespace())
+
+	w.runWatch(ctx, "applications", w.watchApplicationsOnce, w.relistApplications)
+}
+
+// watchApplicationsOnce performs a single watch operation on Applications, resuming from their bookmark
+func (w *XRWatcher) watchApplicationsOnce(ctx context.Context) error {
+	gvr := argocd.ApplicationGVR
+	if w.tracker.bookmark(gvr) == "" {
+		if err := w.relistApplications(ctx); err != nil {
+			return err
+		}
+	}
+
+	watcher, err := w.dynamicClient.Resource(gvr).Namespace(w.argocdClient.Namespace()).Watch(ctx, metav1.ListOptions{
+		ResourceVersion:     w.tracker.bookmark(gvr),
+		AllowWatchBookmarks: true,
+	})
+	if err != nil {
+		if apierrors.IsResourceExpired(err) || apierrors.IsGone(err) {
+			return errWatchExpired
+		}
+		return fmt.Errorf("failed to create application watcher: %w", err)
+	}
+	defer watcher.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return fmt.Errorf("application watch channel closed")
+			}
+
+			if event.Type == watch.Error {
+				if isExpired(event) {
+					w.tracker.resetBookmark(gvr)
+					return errWatchExpired
+				}
+				w.logger.Error(nil, "application watch error event")
+				continue
+			}
+
+			app, ok := event.Object.(*unstructured.Unstructured)
+			if !ok {
+				w.logger.Error(nil, "unexpected application object type")
+				continue
+			}
+
+			w.tracker.setBookmark(gvr, app.GetResourceVersion())
+			if event.Type == watch.Bookmark {
+				continue
+			}
+
+			w.handleApplicationEvent(ctx, event.Type, app)
+		}
+	}
+}
+
+// handleApplicationEvent enqueues a PR when its app's revision or resources changed
+// Applications are updated on every refresh, so other status changes are ignored
+func (w *XRWatcher) handleApplicationEvent(ctx context.Context, eventType watch.EventType, app *unstructured.Unstructured) {
+	// PR applications are matched by PR number, so they are planned in the default repository
+	pr := workqueue.PR{Number: w.argocdClient.PRNumber(app.GetName())}
+	if pr.Number == 0 {
+		return
+	}
+
+	if eventType == watch.Deleted {
+		// The plan updates the comment if this removed the PR's last preview
+		w.tracker.forgetApp(app.GetName())
+		w.tracker.markRemoved(pr)
+		w.tracker.markDirty(pr)
+		w.workQueue.Enqueue(ctx, pr)
+		return
+	}
+
+	if !w.tracker.appChanged(app.GetName(), fingerprintApp(app)) {
+		return
+	}
+
+	w.logger.V(1).Info("PR application changed", "app", app.GetName(), "pr", pr)
+	w.tracker.markDirty(pr)
+	w.workQueue.Enqueue(ctx, pr)
+}
+
+// fingerprintApp identifies an Application's synced revision and resource set
+func fingerprintApp(app *unstructured.Unstructured) string {
+	revision, _, _ := unstructured.NestedString(app.Object, "status", "sync", "revision")
+	resources, _, _ := unstructured.NestedSlice(app.Object, "status", "resources")
+
+	keys := make([]string, 0, len(resources))
+	for _, res := range resources {
+		if m, ok := res.(map[string]interface{}); ok {
+			keys = append(keys, fmt.Sprintf("%v/%v/%v/%v", m["group"], m["kind"], m["namespace"], m["name"]))
+		}
+	}
+	sort.Strings(keys)
+	return revision + "|" + strings.Join(keys, ",")
+}
+
+// handleAppOnlyPR plans a PR without preview XRs from the ArgoCD diff of its applications
+// This covers PRs that only add or change bare Kubernetes resources
+func (w *XRWatcher) handleAppOnlyPR(ctx context.Context, prNumber int) error {
+	pr := workqueue.PR{Number: prNumber}
+	apps, err := w.argocdClient.FindPRApplications(ctx, prNumber)
+	if err != nil {
+		err = fmt.Errorf("failed to find PR applications: %w", err)
+		w.reportPlanFailure(ctx, "", prNumber, err)
+		return err
+	}
+	if len(apps) == 0 {
+		return w.handlePreviewRemoved(ctx, pr)
+	}
+	draft := w.isDraft(ctx, "", prNumber)
+	if w.skipDraft(ctx, "", prNumber, draft) {
+		return nil
+	}
+
+	combined, errs := w.combinedAppDiff(ctx, apps)
+	if len(errs) > 0 {
+		w.planFailed(pr, nil, errs)
+		w.reportPlanFailure(ctx, "", prNumber, errors.Join(errs...))
+		return errors.Join(errs...)
+	}
+
+	// Remember the PR had a preview, so removing its applications updates the comment
+	w.tracker.markPlanned(pr, nil)
+	w.recordLatestPlan(ctx, "", prNumber, api.NewPlan(nil, combined))
+	appChanges := len(combined.Additions) + len(combined.Modifications) + len(combined.Deletions)
+	w.recordOutcome("", prNumber, draft, appChanges, appChanges, len(combined.Deletions), 0, 0, 0)
+	if !hasAppChanges(combined) {
+		w.logger.Info("PR applications have no changes", "prNumber", prNumber, "apps", apps)
+		return nil
+	}
+
+	comment, post := w.applyFreeze(prNumber, w.appOnlyComment(prNumber, combined))
+	if !post {
+		w.tracker.markDirty(pr)
+		return nil
+	}
+
+	if w.vcsClient == nil {
+		w.logger.Info("Dry-run: would post ArgoCD-only comment", "prNumber", prNumber, "apps", apps)
+		return nil
+	}
+
+	if w.commentUnchanged(ctx, "", prNumber, "", comment) {
+		w.logger.Info("ArgoCD-only plan unchanged since last comment, skipping update", "prNumber", prNumber)
+		return nil
+	}
+
+	posted, err := w.vcsClient.PostCommentWithFooter(ctx, prNumber, comment, "")
+	if err != nil {
+		recordError("vcs", err)
+		w.planFailed(pr, nil, []error{err})
+		return fmt.Errorf("failed to post GitHub comment: %w", err)
+	}
+	w.recordComment(ctx, "", prNumber, "", comment)
+	changed := len(combined.Additions) + len(combined.Modifications) + len(combined.Deletions)
+	w.recordPlan(ctx, "", prNumber, store.PlanRecord{Resources: changed, Changed: changed, Deletions: len(combined.Deletions), Posted: posted})
+	w.requestDashboardUpdate()
+	if posted {
+		w.logger.Info("Posted ArgoCD-only GitHub comment", "prNumber", prNumber, "apps", apps)
+	}
+	return nil
+}
+
+// combinedAppDiff merges the ArgoCD diffs of a PR's applications
+func (w *XRWatcher) combinedAppDiff(ctx context.Context, apps []string) (*argocd.AppDiff, []error) {
+	var errs []error
+	combined := &argocd.AppDiff{}
+	for _, appName := range apps {
+		var appDiff *argocd.AppDiff
+		scope, err := w.scopeForApp(ctx, appName)
+		if err == nil {
+			appDiff, err = w.argocdClient.GetAppDiff(ctx, scope.PRAppName, scope.ProdAppName)
+		}
+		if err != nil {
+			recordError("argocd", err)
+			errs = append(errs, fmt.Errorf("ArgoCD diff of %s failed: %w", appName, err))
+			continue
+		}
+		combined.Additions = append(combined.Additions, appDiff.Additions...)
+		combined.Modifications = append(combined.Modifications, appDiff.Modifications...)
+		combined.Deletions = append(combined.Deletions, appDiff.Deletions...)
+		if appDiff.RawDiff != "" {
+			combined.RawDiff = strings.TrimPrefix(combined.RawDiff+"\n"+appDiff.RawDiff, "\n")
+		}
+	}
+	return combined, errs
+}
+
+// appOnlyComment formats the comment of a PR without preview XRs
+func (w *XRWatcher) appOnlyComment(prNumber int, combined *argocd.AppDiff) string {
+	comment := w.formatter.FormatMultipleDiffs(nil, combined)
+	return w.applyProfileTemplate("", prNumber, comment)
+}
+
+// hasAppChanges reports whether a combined ArgoCD diff changes anything
+func hasAppChanges(combined *argocd.AppDiff) bool {
+	return len(combined.Additions)+len(combined.Modifications)+len(combined.Deletions) > 0
+}
@@ -0,0 +1,168 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/millstonehq/crossplane-plan/pkg/argocd"
+	"github.com/millstonehq/crossplane-plan/pkg/workqueue"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+// newApp returns an ArgoCD Application in the argocd namespace, synced at revision and
+// managing the ConfigMaps named by resources
+func newApp(name, revision string, resources ...string) *unstructured.Unstructured {
+	var statusResources []interface{}
+	for _, resource := range resources {
+		statusResources = append(statusResources, map[string]interface{}{
+			"version": "v1", "kind": "ConfigMap", "namespace": "web", "name": resource,
+		})
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Application",
+		"metadata":   map[string]interface{}{"name": name, "namespace": "argocd"},
+		"status": map[string]interface{}{
+			"sync":      map[string]interface{}{"revision": revision},
+			"resources": statusResources,
+		},
+	}}
+}
+
+// newAppWatcher creates a test watcher whose ArgoCD client matches "pr-{number}-" applications
+func newAppWatcher(t *testing.T, objects ...runtime.Object) *XRWatcher {
+	t.Helper()
+	w := newTestWatcher(t, clocktesting.NewFakeClock(time.Now()), objects)
+	w.argocdClient = argocd.NewClient(w.dynamicClient, "argocd", "pr-", "", logr.Discard())
+	return w
+}
+
+func TestFingerprintApp(t *testing.T) {
+	base := fingerprintApp(newApp("pr-4-web", "abc", "config", "settings"))
+
+	tests := []struct {
+		name string
+		app  *unstructured.Unstructured
+		same bool
+	}{
+		{name: "same revision and resources", app: newApp("pr-4-web", "abc", "config", "settings"), same: true},
+		{name: "resources in another order", app: newApp("pr-4-web", "abc", "settings", "config"), same: true},
+		{name: "new revision", app: newApp("pr-4-web", "def", "config", "settings")},
+		{name: "resource added", app: newApp("pr-4-web", "abc", "config", "settings", "flags")},
+		{name: "resource removed", app: newApp("pr-4-web", "abc", "config")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fingerprintApp(tt.app) == base; got != tt.same {
+				t.Errorf("fingerprint unchanged = %v, want %v", got, tt.same)
+			}
+		})
+	}
+}
+
+func TestXRWatcher_handleApplicationEvent(t *testing.T) {
+	tests := []struct {
+		name      string
+		seen      *unstructured.Unstructured // the application's last event, planned since
+		eventType watch.EventType
+		app       *unstructured.Unstructured
+		want      []workqueue.PR
+	}{
+		{name: "production application", eventType: watch.Added, app: newApp("web", "abc", "config")},
+		{name: "new PR application", eventType: watch.Added, app: newApp("pr-4-web", "abc", "config"), want: []workqueue.PR{{Number: 4}}},
+		{
+			name:      "status refresh",
+			seen:      newApp("pr-4-web", "abc", "config"),
+			eventType: watch.Modified,
+			app:       newApp("pr-4-web", "abc", "config"),
+		},
+		{
+			name:      "new revision",
+			seen:      newApp("pr-4-web", "abc", "config"),
+			eventType: watch.Modified,
+			app:       newApp("pr-4-web", "def", "config"),
+			want:      []workqueue.PR{{Number: 4}},
+		},
+		{
+			name:      "deleted",
+			seen:      newApp("pr-4-web", "abc", "config"),
+			eventType: watch.Deleted,
+			app:       newApp("pr-4-web", "abc", "config"),
+			want:      []workqueue.PR{{Number: 4}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newAppWatcher(t)
+			if tt.seen != nil {
+				w.tracker.appChanged(tt.seen.GetName(), fingerprintApp(tt.seen))
+				w.tracker.markPlanned(workqueue.PR{Number: 4}, nil)
+			}
+
+			w.handleApplicationEvent(context.Background(), tt.eventType, tt.app)
+
+			got := w.tracker.dirtyPRs()
+			if len(got)+len(tt.want) > 0 && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PRs flagged for replanning = %v, want %v", got, tt.want)
+			}
+			if queued := len(w.QueueSnapshot()); queued != len(tt.want) {
+				t.Errorf("%d PRs queued, want %d", queued, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestXRWatcher_handleAppOnlyPR(t *testing.T) {
+	tests := []struct {
+		name        string
+		apps        []runtime.Object
+		listErr     error
+		wantErr     bool
+		wantSettled bool
+	}{
+		{name: "no PR applications"},
+		{name: "new application", apps: []runtime.Object{newApp("pr-4-web", "abc", "config")}, wantSettled: true},
+		{
+			name:        "application unchanged from production",
+			apps:        []runtime.Object{newApp("pr-4-web", "abc", "config"), newApp("web", "abc", "config")},
+			wantSettled: true,
+		},
+		{name: "applications can't be listed", listErr: errors.New("connection refused"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newAppWatcher(t, tt.apps...)
+			if tt.listErr != nil {
+				w.dynamicClient.(*fake.FakeDynamicClient).PrependReactor("list", "applications", func(clienttesting.Action) (bool, runtime.Object, error) {
+					return true, nil, tt.listErr
+				})
+			}
+			// The PR had a preview before, so losing its applications forgets it
+			w.tracker.markPlanned(workqueue.PR{Number: 4}, nil)
+			w.tracker.markDirty(workqueue.PR{Number: 4})
+
+			err := w.handleAppOnlyPR(context.Background(), 4)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("handleAppOnlyPR() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if settled := len(w.tracker.settledPRs()) == 1; settled != tt.wantSettled {
+				t.Errorf("PR settled = %v, want %v", settled, tt.wantSettled)
+			}
+			if forgotten := !w.tracker.forgetPR(workqueue.PR{Number: 4}); forgotten != (len(tt.apps) == 0 && !tt.wantErr) {
+				t.Errorf("PR forgotten = %v", forgotten)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+)
+
+// loadGitBaseline reads the production objects declared in a repository with a git baseline
+// Returns nil if the repository is compared against the live cluster
+func (w *XRWatcher) loadGitBaseline(ctx context.Context, repo string) (differ.Declared, error) {
+	baseline := w.profileFor(repo).GitBaseline
+	if baseline == nil {
+		return nil, nil
+	}
+	if w.vcsClient == nil {
+		return nil, fmt.Errorf("git baseline requires GitHub credentials")
+	}
+
+	vcsClient, err := w.vcsClientFor(repo)
+	if err != nil {
+		return nil, err
+	}
+	files, err := vcsClient.GetManifests(ctx, baseline.Ref, baseline.Paths)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read production manifests: %w", err)
+	}
+
+	declared, errs := differ.ParseDeclared(files)
+	if len(errs) > 0 {
+		w.logger.Info("Skipped unparsable production manifests", "repo", w.repositoryName(repo), "count", len(errs), "first", errs[0].Error())
+	}
+	w.logger.Info("Loaded git baseline", "repo", w.repositoryName(repo), "ref", baseline.Ref, "files", len(files), "objects", len(declared))
+	return declared, nil
+}
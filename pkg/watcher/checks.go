@@ -0,0 +1,131 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+	"github.com/millstonehq/crossplane-plan/pkg/formatter"
+	"github.com/millstonehq/crossplane-plan/pkg/severity"
+	"github.com/millstonehq/crossplane-plan/pkg/store"
+	"github.com/millstonehq/crossplane-plan/pkg/vcs"
+)
+
+// Where plans are published
+const (
+	// PlacementComment puts the whole plan in the PR comment
+	PlacementComment = "comment"
+	// PlacementSplit puts the summary and severity in a check run and the full diff in the PR comment
+	PlacementSplit = "split"
+	// PlacementCheck puts the whole plan in a check run: the summary, with the diff as its details
+	PlacementCheck = "check"
+)
+
+// SetPlacement sets where plans are published: PlacementComment, PlacementSplit or PlacementCheck
+// Check runs need GitHub App credentials
+func (w *XRWatcher) SetPlacement(placement string) {
+	w.placement = placement
+}
+
+// SetCommitStatus publishes a commit status summarizing each plan on the PR's head commit
+// Plans at or above failOn fail the status, so branch protection can require it; 0 disables statuses
+func (w *XRWatcher) SetCommitStatus(failOn severity.Level) {
+	w.commitStatusFailOn = failOn
+}
+
+// SetCheckAnnotations annotates the changed files declaring each resource in check runs, with the
+// resource's outcome
+func (w *XRWatcher) SetCheckAnnotations(enabled bool) {
+	w.checkAnnotations = enabled
+}
+
+// publishesCheckRuns reports whether plans are published as check runs
+func (w *XRWatcher) publishesCheckRuns() bool {
+	return w.placement == PlacementSplit || w.placement == PlacementCheck
+}
+
+// publishCheckRun publishes the summary of a plan as a check run on the PR's head commit
+// With PlacementCheck, the comment is the check run's details; with PlacementSplit, the summary
+// links to the sections of the PR comment
+// A check run identical to the last one published for the PR isn't published again
+func (w *XRWatcher) publishCheckRun(ctx context.Context, repo string, prNumber int, entry formatter.DashboardEntry, items []differ.PlanItem, comment string) error {
+	vcsClient, err := w.vcsClientFor(repo)
+	if err != nil {
+		return err
+	}
+	headSHA, err := vcsClient.HeadSHA(ctx, prNumber)
+	if err != nil {
+		return err
+	}
+
+	var commentURL string
+	if w.placement == PlacementSplit {
+		if commentURL, err = vcsClient.CommentURL(ctx, prNumber); err != nil {
+			w.logger.Error(err, "failed to find the comment to link from the check run", "prNumber", prNumber)
+		}
+	}
+
+	run := vcs.CheckRun{Conclusion: vcs.ConclusionNeutral}
+	run.Title, run.Summary = w.formatter.FormatCheckSummary(entry, items, commentURL)
+	if entry.Severity() == severity.Info {
+		run.Conclusion = vcs.ConclusionSuccess
+	}
+	if w.placement == PlacementCheck {
+		run.Text = comment
+	}
+	if w.checkAnnotations {
+		run.Annotations = w.formatter.FormatCheckAnnotations(items)
+	}
+
+	hash := store.HashComment(headSHA + "\n" + run.Title + "\n" + run.Summary + "\n" + run.Text + "\n" + fmt.Sprint(run.Annotations))
+	if last, err := w.state.CheckHash(ctx, w.repositoryName(repo), prNumber); err == nil && last == hash {
+		return nil
+	}
+	if err := vcsClient.CreateCheckRun(ctx, headSHA, run); err != nil {
+		return fmt.Errorf("failed to publish check run of PR #%d: %w", prNumber, err)
+	}
+	if err := w.state.SetCheckHash(ctx, w.repositoryName(repo), prNumber, hash); err != nil {
+		recordError("store", err)
+		w.logger.Error(err, "failed to record check run hash", "prNumber", prNumber)
+	}
+	w.logger.Info("Published check run", "prNumber", prNumber, "repo", vcsClient.Repository(), "sha", headSHA)
+	return nil
+}
+
+// publishCommitStatus publishes the result of a plan as a commit status on the PR's head commit,
+// linking to the PR comment when there is one
+// A status identical to the last one published for the PR isn't published again
+func (w *XRWatcher) publishCommitStatus(ctx context.Context, repo string, prNumber int, entry formatter.DashboardEntry) error {
+	vcsClient, err := w.vcsClientFor(repo)
+	if err != nil {
+		return err
+	}
+	headSHA, err := vcsClient.HeadSHA(ctx, prNumber)
+	if err != nil {
+		return err
+	}
+
+	status := vcs.CommitStatus{State: vcs.StatusSuccess, Description: formatter.PlanTitle(entry)}
+	if entry.Severity() >= w.commitStatusFailOn {
+		status.State = vcs.StatusFailure
+	}
+	if w.placement != PlacementCheck {
+		if status.TargetURL, err = vcsClient.CommentURL(ctx, prNumber); err != nil {
+			w.logger.Error(err, "failed to find the comment to link from the commit status", "prNumber", prNumber)
+		}
+	}
+
+	hash := store.HashComment(headSHA + "\n" + status.State + "\n" + status.Description + "\n" + status.TargetURL)
+	if last, err := w.state.StatusHash(ctx, w.repositoryName(repo), prNumber); err == nil && last == hash {
+		return nil
+	}
+	if err := vcsClient.CreateCommitStatus(ctx, headSHA, status); err != nil {
+		return fmt.Errorf("failed to publish commit status of PR #%d: %w", prNumber, err)
+	}
+	if err := w.state.SetStatusHash(ctx, w.repositoryName(repo), prNumber, hash); err != nil {
+		recordError("store", err)
+		w.logger.Error(err, "failed to record commit status hash", "prNumber", prNumber)
+	}
+	w.logger.Info("Published commit status", "prNumber", prNumber, "repo", vcsClient.Repository(), "sha", headSHA, "state", status.State)
+	return nil
+}
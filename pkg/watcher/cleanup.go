@@ -0,0 +1,95 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/millstonehq/crossplane-plan/pkg/admin"
+	"github.com/millstonehq/crossplane-plan/pkg/workqueue"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// CleanupComments finds comments on open PRs that no longer have a preview (no PR XRs, and no PR
+// application when ArgoCD is enabled) and deletes them or marks them stale
+// Only the default repository is scanned. With dryRun, the comments are only reported
+func (w *XRWatcher) CleanupComments(ctx context.Context, action string, dryRun bool) ([]admin.CleanedComment, error) {
+	if w.vcsClient == nil {
+		return nil, fmt.Errorf("no GitHub client configured")
+	}
+
+	prs, err := w.vcsClient.ListCommentedPRs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	previews, err := w.prsWithXRs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reason := "no preview XRs"
+	if w.argocdClient != nil {
+		reason = "no preview XRs or PR application"
+	}
+
+	var cleaned []admin.CleanedComment
+	for _, prNumber := range prs {
+		if previews[workqueue.PR{Number: prNumber}] {
+			continue
+		}
+		if w.argocdClient != nil {
+			apps, err := w.argocdClient.FindPRApplications(ctx, prNumber)
+			if err != nil {
+				return cleaned, fmt.Errorf("failed to find applications of PR #%d: %w", prNumber, err)
+			}
+			if len(apps) > 0 {
+				continue
+			}
+		}
+
+		comment := admin.CleanedComment{PRNumber: prNumber, Reason: reason}
+		if !dryRun {
+			switch action {
+			case admin.CleanupDelete:
+				err = w.vcsClient.DeleteComment(ctx, prNumber)
+				comment.Done = err == nil
+			case admin.CleanupStale:
+				comment.Done, err = w.vcsClient.MarkCommentStale(ctx, prNumber, "this PR no longer has preview resources")
+			default:
+				err = fmt.Errorf("unknown cleanup action %q", action)
+			}
+			if err != nil {
+				return cleaned, fmt.Errorf("failed to clean up comment of PR #%d: %w", prNumber, err)
+			}
+			w.forgetComment(ctx, "", prNumber)
+		}
+
+		w.logger.Info("Orphaned comment", "prNumber", prNumber, "action", action, "dryRun", dryRun, "done", comment.Done)
+		cleaned = append(cleaned, comment)
+	}
+
+	return cleaned, nil
+}
+
+// prsWithXRs returns the PRs that have preview XRs, including ignored ones, by the repository the
+// XRs target; XRs with a disallowed target repository don't count
+// Listing failures are errors, since a partial listing would make PRs look orphaned
+func (w *XRWatcher) prsWithXRs(ctx context.Context) (map[workqueue.PR]bool, error) {
+	gvrs, err := w.discoverXRDGVRs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover XRDs: %w", err)
+	}
+
+	prs := make(map[workqueue.PR]bool)
+	for _, gvr := range gvrs {
+		_, err := w.listEach(ctx, gvr, func(xr *unstructured.Unstructured) {
+			if pr, err := w.prOf(xr); pr.Number != 0 && err == nil {
+				prs[pr] = true
+			}
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", gvr.String(), err)
+		}
+	}
+	return prs, nil
+}
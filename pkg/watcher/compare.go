@@ -0,0 +1,62 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/millstonehq/crossplane-plan/pkg/admin"
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+)
+
+// ComparePreviews implements admin.PreviewComparer: it compares the preview XRs of two PRs,
+// matched by production name, without running their plans
+func (w *XRWatcher) ComparePreviews(ctx context.Context, pr, against string) (*admin.CompareResult, error) {
+	prNumber, err := w.resolvePRRef(ctx, pr)
+	if err != nil {
+		return nil, err
+	}
+	againstNumber, err := w.resolvePRRef(ctx, against)
+	if err != nil {
+		return nil, err
+	}
+
+	prXRs, err := w.findAllPRResources(ctx, prNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find resources of PR #%d: %w", prNumber, err)
+	}
+	againstXRs, err := w.findAllPRResources(ctx, againstNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find resources of PR #%d: %w", againstNumber, err)
+	}
+
+	result := &admin.CompareResult{PRNumber: prNumber, AgainstPRNumber: againstNumber}
+	statuses := map[string]string{
+		differ.PreviewOnlyInPR:      admin.CompareOnlyInPR,
+		differ.PreviewOnlyInAgainst: admin.CompareOnlyInAgainst,
+		differ.PreviewChanged:       admin.CompareChanged,
+	}
+	for _, difference := range differ.ComparePreviews(withoutIgnored(prXRs), withoutIgnored(againstXRs), w.currentDetector().GetBaseName) {
+		resource := admin.ComparedResource{Resource: difference.Resource, Status: statuses[difference.Status]}
+		for _, field := range difference.Fields {
+			resource.Fields = append(resource.Fields, admin.ComparedField{Path: field.Path, PR: field.PR, Against: field.Against})
+		}
+		result.Resources = append(result.Resources, resource)
+	}
+	return result, nil
+}
+
+// resolvePRRef returns the PR number of a ref: a PR number, or the branch of an open PR
+// of the default repository
+func (w *XRWatcher) resolvePRRef(ctx context.Context, ref string) (int, error) {
+	if prNumber, err := strconv.Atoi(ref); err == nil {
+		if prNumber <= 0 {
+			return 0, fmt.Errorf("invalid PR number %d", prNumber)
+		}
+		return prNumber, nil
+	}
+	if w.vcsClient == nil {
+		return 0, fmt.Errorf("cannot resolve branch %s without a GitHub client", ref)
+	}
+	return w.vcsClient.FindOpenPR(ctx, ref)
+}
@@ -0,0 +1,90 @@
+package watcher
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+	"github.com/millstonehq/crossplane-plan/pkg/vcs"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// ComponentLabel groups the XRs of a monorepo component into their own plan comment
+	ComponentLabel = "millstone.tech/component"
+)
+
+// SetComponentComments posts a separate sticky comment per component (see ComponentLabel)
+// instead of one comment per PR; resources without the label share the PR's usual comment
+func (w *XRWatcher) SetComponentComments(enabled bool) {
+	w.componentComments = enabled
+}
+
+// componentPlan is the part of a plan posted in one component's comment
+type componentPlan struct {
+	component string // empty for the PR's usual comment
+	items     []differ.PlanItem
+}
+
+// componentOf returns the component an XR belongs to, or "" if it has none
+func componentOf(xr *unstructured.Unstructured) string {
+	if xr == nil {
+		return ""
+	}
+	return xr.GetLabels()[ComponentLabel]
+}
+
+// splitByComponent groups plan items by component, in component order
+// Deletions detected through ArgoCD carry no labels and stay with the unlabeled resources
+func splitByComponent(items []differ.PlanItem) []componentPlan {
+	byComponent := make(map[string][]differ.PlanItem)
+	for _, item := range items {
+		component := componentOf(item.XR)
+		byComponent[component] = append(byComponent[component], item)
+	}
+
+	plans := make([]componentPlan, 0, len(byComponent))
+	for component, items := range byComponent {
+		plans = append(plans, componentPlan{component: component, items: items})
+	}
+	sort.Slice(plans, func(i, j int) bool { return plans[i].component < plans[j].component })
+	return plans
+}
+
+// componentIdentifier derives a component's comment marker from the PR's comment marker,
+// e.g. "<!-- crossplane-plan-comment -->" becomes "<!-- crossplane-plan-comment:network -->"
+func componentIdentifier(identifier, component string) string {
+	if component == "" {
+		return identifier
+	}
+	if base, ok := strings.CutSuffix(identifier, " -->"); ok {
+		return base + ":" + component + " -->"
+	}
+	return identifier + "<!-- component:" + component + " -->"
+}
+
+// componentClientFor returns the VCS client posting a component's comment to a target repository
+func (w *XRWatcher) componentClientFor(repo, component string) (vcs.Client, error) {
+	client, err := w.vcsClientFor(repo)
+	if err != nil || component == "" {
+		return client, err
+	}
+	return client.WithCommentIdentifier(componentIdentifier(client.Identifier(), component)), nil
+}
+
+// postedComponents returns the components with a comment on a PR, as far as the state store knows
+func (w *XRWatcher) postedComponents(ctx context.Context, repo string, prNumber int) []string {
+	hashes, err := w.state.ComponentCommentHashes(ctx, w.repositoryName(repo), prNumber)
+	if err != nil {
+		recordError("store", err)
+		w.logger.Error(err, "failed to read component comment hashes", "prNumber", prNumber)
+		return nil
+	}
+	components := make([]string, 0, len(hashes))
+	for component := range hashes {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+	return components
+}
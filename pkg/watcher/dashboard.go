@@ -0,0 +1,145 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+	"github.com/millstonehq/crossplane-plan/pkg/formatter"
+	"github.com/millstonehq/crossplane-plan/pkg/severity"
+	"github.com/millstonehq/crossplane-plan/pkg/vcs"
+)
+
+// dashboardIdentifier marks the dashboard comment, so it isn't mistaken for a plan comment
+const dashboardIdentifier = "<!-- crossplane-plan-dashboard -->"
+
+// dashboardInterval coalesces the dashboard updates of plans finishing in quick succession
+const dashboardInterval = 30 * time.Second
+
+// SetDashboardIssue maintains a dashboard of the plans of all open PRs as a comment on an
+// issue of the default repository, updated after plans (0 to disable)
+func (w *XRWatcher) SetDashboardIssue(issue int) {
+	w.dashboardIssue = issue
+}
+
+// requestDashboardUpdate schedules a dashboard update; requests made while one is pending are merged
+func (w *XRWatcher) requestDashboardUpdate() {
+	if w.dashboardIssue == 0 {
+		return
+	}
+	select {
+	case w.dashboardUpdates <- struct{}{}:
+	default:
+	}
+}
+
+// runDashboard updates the dashboard on request, at most once per dashboardInterval
+func (w *XRWatcher) runDashboard(ctx context.Context) {
+	for {
+		select {
+		case <-w.dashboardUpdates:
+			if err := w.updateDashboard(ctx); err != nil {
+				recordError("vcs", err)
+				w.logger.Error(err, "failed to update dashboard", "issue", w.dashboardIssue)
+			}
+		case <-ctx.Done():
+			return
+		}
+
+		select {
+		case <-w.clock.After(dashboardInterval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// updateDashboard rewrites the dashboard from the latest plan of every open PR
+func (w *XRWatcher) updateDashboard(ctx context.Context) error {
+	planned, err := w.state.PlannedPRs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list planned PRs: %w", err)
+	}
+
+	entries := []formatter.DashboardEntry{}
+	open := make(map[string][]int)
+	for _, pr := range planned {
+		prs, listed := open[pr.Repository]
+		if !listed {
+			client, err := w.dashboardClientFor(pr.Repository)
+			if err != nil {
+				return err
+			}
+			if prs, err = client.ListOpenPRs(ctx); err != nil {
+				return fmt.Errorf("failed to list open PRs of %s: %w", pr.Repository, err)
+			}
+			open[pr.Repository] = prs
+		}
+		if !slices.Contains(prs, pr.PRNumber) {
+			continue
+		}
+
+		records, err := w.state.Plans(ctx, pr.Repository, pr.PRNumber)
+		if err != nil || len(records) == 0 {
+			continue
+		}
+		latest := records[len(records)-1]
+		entries = append(entries, formatter.DashboardEntry{
+			Repository: pr.Repository,
+			PRNumber:   pr.PRNumber,
+			Resources:  latest.Resources,
+			Changed:    latest.Changed,
+			Deletions:  latest.Deletions,
+			Protected:  latest.Protected,
+			Failed:     latest.Failed,
+		})
+	}
+
+	posted, err := w.vcsClient.WithCommentIdentifier(dashboardIdentifier).
+		PostCommentIfChanged(ctx, w.dashboardIssue, w.formatter.FormatDashboard(entries))
+	if err != nil {
+		return fmt.Errorf("failed to post dashboard: %w", err)
+	}
+	if posted {
+		w.logger.Info("Updated dashboard", "issue", w.dashboardIssue, "prCount", len(entries))
+	}
+	return nil
+}
+
+// dashboardClientFor returns a client for a repository named in the state store
+func (w *XRWatcher) dashboardClientFor(repository string) (vcs.Client, error) {
+	if strings.EqualFold(repository, w.vcsClient.Repository()) {
+		return w.vcsClient, nil
+	}
+	return w.vcsClient.ForRepository(repository)
+}
+
+// countRisks counts the destructive items: deleted resources and changed resources of protected kinds
+// Deletions a policy marks as expected aren't destructive
+func countRisks(items []differ.PlanItem) (deletions, protected int) {
+	for _, item := range items {
+		if item.Severity != severity.Destructive {
+			continue
+		}
+		if item.Action == differ.ActionDelete {
+			deletions++
+		} else {
+			protected++
+		}
+	}
+	return deletions, protected
+}
+
+// countFailed counts the XRs that could not be planned
+func countFailed(items []differ.PlanItem) int {
+	failed := 0
+	for _, item := range items {
+		if item.Action == differ.ActionError {
+			failed++
+		}
+	}
+	return failed
+}
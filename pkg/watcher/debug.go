@@ -0,0 +1,91 @@
+package watcher
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// PlanDebugAnnotation attaches the sanitized diff input to the comment when set to "true"
+	PlanDebugAnnotation = "millstone.tech/plan-debug"
+
+	// PlanDebugPipelineAnnotation captures the output of every composition function pipeline step
+	// when set to "true"
+	PlanDebugPipelineAnnotation = "millstone.tech/plan-debug-pipeline"
+)
+
+// SetDebugDiffInput attaches the sanitized diff input of every XR to comments and logs
+func (w *XRWatcher) SetDebugDiffInput(enabled bool) {
+	w.debugDiffInput = enabled
+}
+
+// isDebugRequested reports whether diff input should be recorded for an XR
+func (w *XRWatcher) isDebugRequested(xr *unstructured.Unstructured) bool {
+	if w.debugDiffInput {
+		return true
+	}
+	return strings.EqualFold(strings.TrimSpace(xr.GetAnnotations()[PlanDebugAnnotation]), "true")
+}
+
+// recordDiffInput marks a result for debug output and logs the exact diff input
+func (w *XRWatcher) recordDiffInput(prNumber int, xr *unstructured.Unstructured, result *differ.DiffResult) {
+	if !w.isDebugRequested(xr) || result.DiffInput == nil {
+		return
+	}
+
+	result.Debug = true
+
+	yamlBytes, err := yaml.Marshal(result.DiffInput.Object)
+	if err != nil {
+		w.logger.Error(err, "failed to marshal diff input", "name", xr.GetName())
+		return
+	}
+	w.logger.Info("Diff input", "prNumber", prNumber, "name", xr.GetName(), "sanitizedXR", string(yamlBytes))
+}
+
+// SetPipelineCaptureDir writes pipeline captures (see PlanDebugPipelineAnnotation) to files under dir,
+// as <repository>/pr-<number>/<name>.yaml; without it captures are logged
+func (w *XRWatcher) SetPipelineCaptureDir(dir string) {
+	w.pipelineCaptureDir = dir
+}
+
+// isPipelineCaptureRequested reports whether the pipeline steps of an XR should be captured
+func isPipelineCaptureRequested(xr *unstructured.Unstructured) bool {
+	return strings.EqualFold(strings.TrimSpace(xr.GetAnnotations()[PlanDebugPipelineAnnotation]), "true")
+}
+
+// recordPipeline writes a result's pipeline capture to the capture directory, or logs it
+func (w *XRWatcher) recordPipeline(repo string, prNumber int, xr *unstructured.Unstructured, result *differ.DiffResult) {
+	if len(result.Pipeline) == 0 {
+		return
+	}
+
+	yamlBytes, err := yaml.Marshal(result.Pipeline)
+	if err != nil {
+		w.logger.Error(err, "failed to marshal pipeline capture", "name", xr.GetName())
+		return
+	}
+	if w.pipelineCaptureDir == "" {
+		w.logger.Info("Pipeline capture", "prNumber", prNumber, "name", xr.GetName(), "pipeline", string(yamlBytes))
+		return
+	}
+
+	dir := filepath.Join(w.pipelineCaptureDir, filepath.FromSlash(w.repositoryName(repo)), fmt.Sprintf("pr-%d", prNumber))
+	path := filepath.Join(dir, xr.GetName()+".yaml")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		w.logger.Error(err, "failed to create pipeline capture directory", "dir", dir)
+		return
+	}
+	if err := os.WriteFile(path, yamlBytes, 0o644); err != nil {
+		w.logger.Error(err, "failed to write pipeline capture", "path", path)
+		return
+	}
+	result.PipelineArtifact = path
+	w.logger.Info("Wrote pipeline capture", "prNumber", prNumber, "name", xr.GetName(), "path", path)
+}
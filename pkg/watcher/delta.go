@@ -0,0 +1,322 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/millstonehq/crossplane-plan/pkg/logsample"
+	"github.com/millstonehq/crossplane-plan/pkg/metrics"
+	"github.com/millstonehq/crossplane-plan/pkg/workqueue"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// reconcileTracker remembers what was last planned so periodic reconciliation
+// only touches PRs with actual changes
+type reconcileTracker struct {
+	mu        sync.Mutex
+	planned   map[workqueue.PR]map[string]string     // PR -> lastPlannedResourceVersions, by XR
+	dirty     map[workqueue.PR]bool                  // PRs with events since their last successful plan
+	bookmarks map[schema.GroupVersionResource]string // GVR -> last seen resourceVersion
+	apps      map[string]string                      // PR app -> fingerprint of its revision and resources
+	removed   map[workqueue.PR]bool                  // PRs with XR deletions since their last plan
+	ignored   map[string]bool                        // XRs with the plan-ignore annotation, by "Kind/namespace/name"
+}
+
+// newReconcileTracker creates an empty reconcileTracker
+func newReconcileTracker() *reconcileTracker {
+	return &reconcileTracker{
+		planned:   make(map[workqueue.PR]map[string]string),
+		dirty:     make(map[workqueue.PR]bool),
+		bookmarks: make(map[schema.GroupVersionResource]string),
+		apps:      make(map[string]string),
+		removed:   make(map[workqueue.PR]bool),
+		ignored:   make(map[string]bool),
+	}
+}
+
+// markDirty flags a PR for replanning
+func (t *reconcileTracker) markDirty(pr workqueue.PR) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.dirty[pr] = true
+}
+
+// markPlanned records a successful plan of a PR's XRs, at their resourceVersions
+func (t *reconcileTracker) markPlanned(pr workqueue.PR, versions map[string]string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.planned[pr] = versions
+	delete(t.dirty, pr)
+	delete(t.removed, pr)
+}
+
+// markRemoved records that one of a PR's XRs was deleted
+func (t *reconcileTracker) markRemoved(pr workqueue.PR) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.removed[pr] = true
+}
+
+// forgetPR drops a PR without preview resources and reports whether it had any:
+// it was planned before, or lost XRs since
+func (t *reconcileTracker) forgetPR(pr workqueue.PR) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, planned := t.planned[pr]
+	hadPreview := planned || t.removed[pr]
+	delete(t.planned, pr)
+	delete(t.dirty, pr)
+	delete(t.removed, pr)
+	return hadPreview
+}
+
+// settledPRs returns the planned PRs that aren't flagged for replanning and didn't lose XRs
+func (t *reconcileTracker) settledPRs() []workqueue.PR {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var prs []workqueue.PR
+	for pr := range t.planned {
+		if !t.dirty[pr] && !t.removed[pr] {
+			prs = append(prs, pr)
+		}
+	}
+	return prs
+}
+
+// dirtyPRs returns the PRs flagged for replanning
+func (t *reconcileTracker) dirtyPRs() []workqueue.PR {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	prs := make([]workqueue.PR, 0, len(t.dirty))
+	for pr := range t.dirty {
+		prs = append(prs, pr)
+	}
+	return prs
+}
+
+// plannedElsewhere returns the PRs with pr's number in other repositories that were last
+// planned with any of xrs, e.g. before their target repository changed
+func (t *reconcileTracker) plannedElsewhere(pr workqueue.PR, xrs map[string]string) []workqueue.PR {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var prs []workqueue.PR
+	for other, planned := range t.planned {
+		if other.Number != pr.Number || other.Repo == pr.Repo {
+			continue
+		}
+		for xr := range xrs {
+			if _, ok := planned[xr]; ok {
+				prs = append(prs, other)
+				break
+			}
+		}
+	}
+	return prs
+}
+
+// needsPlan reports whether a PR changed since it was last planned
+// versions are the resourceVersions of all the PR's XRs, so XRs missing from them were deleted
+func (t *reconcileTracker) needsPlan(pr workqueue.PR, versions map[string]string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	planned, ok := t.planned[pr]
+	return t.dirty[pr] || !ok || len(planned) != len(versions) || len(changedXRs(planned, versions)) > 0
+}
+
+// unchanged reports whether a planned PR that isn't flagged for replanning still has its XRs at the
+// resourceVersions of its last plan
+// versions may only hold some of the PR's XRs (e.g., those of one GVR); the others aren't checked
+func (t *reconcileTracker) unchanged(pr workqueue.PR, versions map[string]string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	planned, ok := t.planned[pr]
+	return ok && !t.dirty[pr] && len(changedXRs(planned, versions)) == 0
+}
+
+// missedXRs returns the XRs of a planned PR, sorted, that were created, updated or deleted since its
+// last plan although no watch event flagged the PR for replanning
+// versions are the resourceVersions of all the PR's XRs
+func (t *reconcileTracker) missedXRs(pr workqueue.PR, versions map[string]string) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	planned, ok := t.planned[pr]
+	if !ok || t.dirty[pr] {
+		return nil
+	}
+	missed := changedXRs(planned, versions)
+	for xr := range planned {
+		if _, ok := versions[xr]; !ok {
+			missed = append(missed, xr)
+		}
+	}
+	sort.Strings(missed)
+	return missed
+}
+
+// restorePlanned records the resourceVersions of the plans of a previous leader, for the PRs
+// this replica hasn't planned yet
+func (t *reconcileTracker) restorePlanned(planned map[workqueue.PR]map[string]string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for pr, versions := range planned {
+		if _, ok := t.planned[pr]; !ok {
+			t.planned[pr] = versions
+		}
+	}
+}
+
+// setIgnored records whether an XR opted out of planning and reports whether that changed
+// XRs not seen before count as planned
+func (t *reconcileTracker) setIgnored(xr string, ignored bool) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	changed := t.ignored[xr] != ignored
+	if ignored {
+		t.ignored[xr] = true
+	} else {
+		delete(t.ignored, xr)
+	}
+	return changed
+}
+
+// bookmark returns the last seen resourceVersion for a GVR
+func (t *reconcileTracker) bookmark(gvr schema.GroupVersionResource) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.bookmarks[gvr]
+}
+
+// setBookmark records the last seen resourceVersion for a GVR
+func (t *reconcileTracker) setBookmark(gvr schema.GroupVersionResource, resourceVersion string) {
+	if resourceVersion == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.bookmarks[gvr] = resourceVersion
+}
+
+// resetBookmark forgets a GVR's resourceVersion (e.g., after it expired)
+func (t *reconcileTracker) resetBookmark(gvr schema.GroupVersionResource) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.bookmarks, gvr)
+}
+
+// appChanged records a PR app's fingerprint and reports whether it differs from the last one seen
+func (t *reconcileTracker) appChanged(appName, fingerprint string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	changed := t.apps[appName] != fingerprint
+	t.apps[appName] = fingerprint
+	return changed
+}
+
+// forgetApp drops a deleted PR app's fingerprint
+func (t *reconcileTracker) forgetApp(appName string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.apps, appName)
+}
+
+// resourceVersions returns the resourceVersion of each XR, by "Kind/namespace/name"
+func resourceVersions(xrs []*unstructured.Unstructured) map[string]string {
+	versions := make(map[string]string, len(xrs))
+	for _, xr := range xrs {
+		versions[xrKey(xr)] = xr.GetResourceVersion()
+	}
+	return versions
+}
+
+// xrKey identifies an XR as "Kind/namespace/name"
+func xrKey(xr *unstructured.Unstructured) string {
+	return fmt.Sprintf("%s/%s/%s", xr.GetKind(), xr.GetNamespace(), xr.GetName())
+}
+
+// changedXRs returns the XRs of versions, sorted, that are missing from planned or at another resourceVersion
+func changedXRs(planned, versions map[string]string) []string {
+	var changed []string
+	for xr, version := range versions {
+		if plannedVersion, ok := planned[xr]; !ok || plannedVersion != version {
+			changed = append(changed, xr)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// SetFullReconciliationInterval sets how often (in minutes) periodic reconciliation
+// replans every PR instead of only PRs that changed
+func (w *XRWatcher) SetFullReconciliationInterval(minutes int) {
+	w.fullSweepInterval = minutes
+}
+
+// reconcilePRs plans PR XRs across all GVRs
+// Unless full is set, PRs whose XRs are unchanged since their last plan are skipped
+func (w *XRWatcher) reconcilePRs(ctx context.Context, gvrs []schema.GroupVersionResource, full bool) {
+	w.eventCounts.Flush(w.logger, "XR events since last reconciliation", "gvr")
+
+	prXRs := make(map[workqueue.PR][]*unstructured.Unstructured)
+	listed := logsample.NewCounts()
+	for _, gvr := range gvrs {
+		_, err := w.listEach(ctx, gvr, func(xr *unstructured.Unstructured) {
+			listed.Add(gvr.String(), "total")
+			pr, err := w.prOf(xr)
+			if pr.Number == 0 {
+				return
+			}
+			listed.Add(gvr.String(), "prXRs")
+			if err != nil {
+				listed.Add(gvr.String(), "disallowedTargetRepo")
+				return
+			}
+			prXRs[pr] = append(prXRs[pr], xr.DeepCopy())
+		})
+		if err != nil {
+			w.logger.Error(err, "periodic reconciliation failed", "gvr", gvr.String())
+			return // Partial listings would make PRs look changed
+		}
+	}
+	listed.Flush(w.logger, "Listed XRs", "gvr")
+
+	skipped := 0
+	for pr, xrs := range prXRs {
+		if ctx.Err() != nil {
+			return // Shutting down, don't start another PR
+		}
+		if len(withoutIgnored(xrs)) == 0 {
+			continue // Ignored XRs are only planned along with others of their PR
+		}
+		// Watch events flag PRs for replanning; XRs that changed without one reveal dropped events
+		versions := resourceVersions(xrs)
+		if missed := w.tracker.missedXRs(pr, versions); len(missed) > 0 {
+			metrics.MissedEvents.Inc()
+			w.logger.Info("Recovering missed XR events", "pr", pr, "xrs", missed)
+		}
+		if !full && !w.tracker.needsPlan(pr, versions) {
+			skipped++
+			continue
+		}
+		// Replanned through the queue below, like other PRs without XRs
+		w.movedFrom(pr, versions)
+
+		w.logger.Info("Reconciling PR XRs", "pr", pr, "count", len(xrs), "full", full)
+		if err := w.runPR(ctx, pr, xrs); err != nil {
+			w.logger.Error(err, "failed to process PR batch", "pr", pr)
+		}
+	}
+
+	// PRs without XRs (ArgoCD-only PRs, held or failed comments) are replanned through the queue
+	for _, pr := range w.tracker.dirtyPRs() {
+		if _, ok := prXRs[pr]; !ok {
+			w.workQueue.Enqueue(ctx, pr)
+		}
+	}
+
+	w.saveRetryState(ctx)
+
+	w.logger.Info("Periodic reconciliation complete", "prCount", len(prXRs), "unchanged", skipped, "full", full)
+}
@@ -0,0 +1,255 @@
+package watcher
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/millstonehq/crossplane-plan/pkg/workqueue"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestReconcileTracker_needsPlan(t *testing.T) {
+	pr := workqueue.PR{Number: 1}
+	planned := map[string]string{"XBucket/team/pr-1-data": "1", "XBucket/team/pr-1-logs": "4"}
+
+	tests := []struct {
+		name     string
+		planned  map[string]string
+		dirty    bool
+		versions map[string]string
+		want     bool
+	}{
+		{name: "never planned", versions: planned, want: true},
+		{name: "unchanged", planned: planned, versions: planned},
+		{name: "flagged for replanning", planned: planned, dirty: true, versions: planned, want: true},
+		{
+			name:     "XR updated",
+			planned:  planned,
+			versions: map[string]string{"XBucket/team/pr-1-data": "2", "XBucket/team/pr-1-logs": "4"},
+			want:     true,
+		},
+		{
+			name:     "XR created",
+			planned:  planned,
+			versions: map[string]string{"XBucket/team/pr-1-data": "1", "XBucket/team/pr-1-logs": "4", "XQueue/team/pr-1-jobs": "7"},
+			want:     true,
+		},
+		{name: "XR deleted", planned: planned, versions: map[string]string{"XBucket/team/pr-1-data": "1"}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newReconcileTracker()
+			if tt.planned != nil {
+				tracker.markPlanned(pr, tt.planned)
+			}
+			if tt.dirty {
+				tracker.markDirty(pr)
+			}
+			if got := tracker.needsPlan(pr, tt.versions); got != tt.want {
+				t.Errorf("needsPlan() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcileTracker_unchanged(t *testing.T) {
+	pr := workqueue.PR{Number: 1}
+	planned := map[string]string{"XBucket/team/pr-1-data": "1", "XQueue/team/pr-1-jobs": "4"}
+
+	tests := []struct {
+		name     string
+		planned  map[string]string
+		dirty    bool
+		versions map[string]string
+		want     bool
+	}{
+		{name: "never planned", versions: planned},
+		{name: "unchanged", planned: planned, versions: planned, want: true},
+		{name: "XRs of one GVR unchanged", planned: planned, versions: map[string]string{"XBucket/team/pr-1-data": "1"}, want: true},
+		{name: "flagged for replanning", planned: planned, dirty: true, versions: planned},
+		{name: "XR updated", planned: planned, versions: map[string]string{"XBucket/team/pr-1-data": "2"}},
+		{name: "XR created", planned: planned, versions: map[string]string{"XBucket/team/pr-1-logs": "9"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newReconcileTracker()
+			if tt.planned != nil {
+				tracker.markPlanned(pr, tt.planned)
+			}
+			if tt.dirty {
+				tracker.markDirty(pr)
+			}
+			if got := tracker.unchanged(pr, tt.versions); got != tt.want {
+				t.Errorf("unchanged() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcileTracker_missedXRs(t *testing.T) {
+	pr := workqueue.PR{Number: 1}
+	planned := map[string]string{"XBucket/team/pr-1-data": "1", "XBucket/team/pr-1-logs": "4"}
+
+	tests := []struct {
+		name     string
+		planned  map[string]string
+		dirty    bool
+		versions map[string]string
+		want     []string
+	}{
+		{name: "never planned", versions: planned},
+		{name: "unchanged", planned: planned, versions: planned},
+		{
+			name:     "flagged by a watch event",
+			planned:  planned,
+			dirty:    true,
+			versions: map[string]string{"XBucket/team/pr-1-data": "2"},
+		},
+		{
+			name:     "updated, created and deleted without events",
+			planned:  planned,
+			versions: map[string]string{"XBucket/team/pr-1-data": "2", "XQueue/team/pr-1-jobs": "7"},
+			want:     []string{"XBucket/team/pr-1-data", "XBucket/team/pr-1-logs", "XQueue/team/pr-1-jobs"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newReconcileTracker()
+			if tt.planned != nil {
+				tracker.markPlanned(pr, tt.planned)
+			}
+			if tt.dirty {
+				tracker.markDirty(pr)
+			}
+			if got := tracker.missedXRs(pr, tt.versions); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("missedXRs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcileTracker_plannedElsewhere(t *testing.T) {
+	tracker := newReconcileTracker()
+	tracker.markPlanned(workqueue.PR{Number: 1}, map[string]string{"XBucket/team/pr-1-data": "1", "XBucket/team/pr-1-vpc": "1"})
+	tracker.markPlanned(workqueue.PR{Repo: "acme/network", Number: 1}, map[string]string{"XBucket/team/pr-1-subnet": "1"})
+	tracker.markPlanned(workqueue.PR{Number: 2}, map[string]string{"XBucket/team/pr-1-vpc": "1"})
+
+	tests := []struct {
+		name string
+		pr   workqueue.PR
+		xrs  map[string]string
+		want []workqueue.PR
+	}{
+		{
+			name: "XR moved to a target repository",
+			pr:   workqueue.PR{Repo: "acme/network", Number: 1},
+			xrs:  map[string]string{"XBucket/team/pr-1-subnet": "1", "XBucket/team/pr-1-vpc": "2"},
+			want: []workqueue.PR{{Number: 1}},
+		},
+		{
+			name: "XR moved back to the default repository",
+			pr:   workqueue.PR{Number: 1},
+			xrs:  map[string]string{"XBucket/team/pr-1-subnet": "2"},
+			want: []workqueue.PR{{Repo: "acme/network", Number: 1}},
+		},
+		{
+			name: "XRs planned in the same repository",
+			pr:   workqueue.PR{Number: 1},
+			xrs:  map[string]string{"XBucket/team/pr-1-data": "2"},
+		},
+		{
+			name: "same XR in a PR of another number",
+			pr:   workqueue.PR{Repo: "acme/data", Number: 2},
+			xrs:  map[string]string{"XBucket/team/pr-1-subnet": "1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tracker.plannedElsewhere(tt.pr, tt.xrs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("plannedElsewhere() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcileTracker_forgetPR(t *testing.T) {
+	pr := workqueue.PR{Number: 1}
+
+	tests := []struct {
+		name  string
+		setup func(*reconcileTracker)
+		want  bool
+	}{
+		{name: "unknown PR", setup: func(*reconcileTracker) {}},
+		{name: "flagged but never planned", setup: func(t *reconcileTracker) { t.markDirty(pr) }},
+		{name: "planned", setup: func(t *reconcileTracker) { t.markPlanned(pr, map[string]string{"XBucket/team/pr-1-data": "1"}) }, want: true},
+		{name: "lost XRs", setup: func(t *reconcileTracker) { t.markRemoved(pr) }, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newReconcileTracker()
+			tt.setup(tracker)
+			if got := tracker.forgetPR(pr); got != tt.want {
+				t.Errorf("forgetPR() = %v, want %v", got, tt.want)
+			}
+			if len(tracker.dirtyPRs()) > 0 || len(tracker.settledPRs()) > 0 || tracker.forgetPR(pr) {
+				t.Error("forgetPR() left state behind")
+			}
+		})
+	}
+}
+
+func TestXRWatcher_reconcilePRs(t *testing.T) {
+	gvrs := []schema.GroupVersionResource{{Group: "example.com", Version: "v1", Resource: "xbuckets"}}
+	xr := func(name, resourceVersion string) *unstructured.Unstructured {
+		xr := newXR("XBucket", "team", name, nil)
+		xr.SetResourceVersion(resourceVersion)
+		return xr
+	}
+
+	tests := []struct {
+		name        string
+		dirty       []workqueue.PR
+		wantPending []int
+	}{
+		{name: "unchanged PRs are skipped"},
+		{name: "dirty PRs without XRs are enqueued", dirty: []workqueue.PR{{Number: 9}}, wantPending: []int{9}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWatcher(t, clocktesting.NewFakeClock(time.Now()), []runtime.Object{
+				xr("pr-1-data", "3"),
+				xr("pr-2-data", "5"),
+				xr("data", "8"),
+			})
+			w.tracker.markPlanned(workqueue.PR{Number: 1}, map[string]string{"XBucket/team/pr-1-data": "3"})
+			w.tracker.markPlanned(workqueue.PR{Number: 2}, map[string]string{"XBucket/team/pr-2-data": "5"})
+			for _, pr := range tt.dirty {
+				w.tracker.markDirty(pr)
+			}
+
+			w.reconcilePRs(context.Background(), gvrs, false)
+
+			var pending []int
+			for _, item := range w.QueueSnapshot() {
+				pending = append(pending, item.PRNumber)
+			}
+			sort.Ints(pending)
+			if !reflect.DeepEqual(pending, tt.wantPending) {
+				t.Errorf("enqueued PRs %v, want %v", pending, tt.wantPending)
+			}
+		})
+	}
+}
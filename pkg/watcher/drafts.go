@@ -0,0 +1,50 @@
+package watcher
+
+import (
+	"context"
+)
+
+// What to do with draft PRs
+const (
+	// DraftPlan plans draft PRs like any other
+	DraftPlan = "plan"
+	// DraftSkip doesn't plan draft PRs until they are ready for review
+	DraftSkip = "skip"
+	// DraftNoFail plans draft PRs, but their plans don't fail --once
+	DraftNoFail = "no-fail"
+)
+
+// SetDraftPRs sets what to do with draft PRs: DraftPlan, DraftSkip or DraftNoFail
+func (w *XRWatcher) SetDraftPRs(mode string) {
+	w.draftPRs = mode
+}
+
+// isDraft reports whether a PR is a draft the draft mode applies to
+// Without GitHub access, or if the PR can't be read, it is treated as ready for review
+func (w *XRWatcher) isDraft(ctx context.Context, repo string, prNumber int) bool {
+	if w.draftPRs == "" || w.draftPRs == DraftPlan || w.vcsClient == nil {
+		return false
+	}
+	vcsClient, err := w.vcsClientFor(repo)
+	if err != nil {
+		w.logger.Error(err, "failed to check whether PR is a draft", "prNumber", prNumber)
+		return false
+	}
+	draft, err := vcsClient.IsDraft(ctx, prNumber)
+	if err != nil {
+		recordError("vcs", err)
+		w.logger.Error(err, "failed to check whether PR is a draft", "prNumber", prNumber)
+		return false
+	}
+	return draft
+}
+
+// skipDraft reports whether a PR's plan is skipped because it is a draft
+// Previews requested through the API are always computed
+func (w *XRWatcher) skipDraft(ctx context.Context, repo string, prNumber int, draft bool) bool {
+	if !draft || w.draftPRs != DraftSkip || previewFrom(ctx) != nil {
+		return false
+	}
+	w.logger.Info("Skipping draft PR until it is ready for review", "prNumber", prNumber, "repo", w.repositoryName(repo))
+	return true
+}
@@ -0,0 +1,187 @@
+package watcher
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/millstonehq/crossplane-plan/pkg/metrics"
+	"github.com/millstonehq/crossplane-plan/pkg/store"
+	"github.com/millstonehq/crossplane-plan/pkg/vcs"
+	"github.com/millstonehq/crossplane-plan/pkg/workqueue"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/clock"
+)
+
+// abandonedStatusDescription describes the commit status of a PR abandoned while it was planned
+const abandonedStatusDescription = "Plan interrupted by shutdown, replanning"
+
+// prRuns tracks the PR runs started outside the work queue, by reconciliation, so Drain
+// waits for them like for the queue's
+type prRuns struct {
+	mu       sync.Mutex
+	wg       sync.WaitGroup
+	draining bool
+	inFlight map[*prRun]struct{}
+}
+
+// prRun is a PR run started outside the work queue
+type prRun struct {
+	pr     workqueue.PR
+	cancel context.CancelFunc
+}
+
+// start registers a run of pr, whose context outlives ctx until the run is abandoned
+// It returns false while draining: the run mustn't start
+func (r *prRuns) start(ctx context.Context, pr workqueue.PR) (context.Context, func(), bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.draining {
+		return nil, nil, false
+	}
+	if r.inFlight == nil {
+		r.inFlight = make(map[*prRun]struct{})
+	}
+
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	run := &prRun{pr: pr, cancel: cancel}
+	r.inFlight[run] = struct{}{}
+	r.wg.Add(1)
+	return runCtx, func() {
+		r.mu.Lock()
+		delete(r.inFlight, run)
+		r.mu.Unlock()
+		cancel()
+		r.wg.Done()
+	}, true
+}
+
+// count returns the number of runs in flight
+func (r *prRuns) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.inFlight)
+}
+
+// drain stops runs from starting and waits up to grace for those in flight, then cancels
+// and returns the PRs of the runs still going. Runs may start again once drain returns
+func (r *prRuns) drain(clk clock.Clock, grace time.Duration) []workqueue.PR {
+	r.mu.Lock()
+	r.draining = true
+	r.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	var abandoned []workqueue.PR
+	select {
+	case <-done:
+	case <-clk.After(grace):
+		r.mu.Lock()
+		for run := range r.inFlight {
+			abandoned = append(abandoned, run.pr)
+			run.cancel()
+		}
+		r.mu.Unlock()
+		<-done
+	}
+
+	r.mu.Lock()
+	r.draining = false
+	r.mu.Unlock()
+	return abandoned
+}
+
+// SetShutdownGracePeriod sets how long in-flight PR processing may run after
+// shutdown or leadership loss before it is abandoned
+func (w *XRWatcher) SetShutdownGracePeriod(grace time.Duration) {
+	w.shutdownGracePeriod = grace
+}
+
+// runPR plans a PR outside the work queue, tracked so Drain waits for it
+// While draining, the PR isn't planned but left for the next leader
+func (w *XRWatcher) runPR(ctx context.Context, pr workqueue.PR, xrs []*unstructured.Unstructured) error {
+	runCtx, finish, ok := w.runs.start(ctx, pr)
+	if !ok {
+		w.tracker.markDirty(pr)
+		return nil
+	}
+	defer finish()
+	return w.handlePRBatch(runCtx, pr, xrs)
+}
+
+// Drain finishes in-flight PR processing, of the work queue and of reconciliation, within the
+// shutdown grace period
+// Call before releasing leadership so a new leader doesn't race a half-updated comment
+func (w *XRWatcher) Drain() {
+	inFlight := w.workQueue.InFlightCount() + w.runs.count()
+	if inFlight > 0 {
+		w.logger.Info("Draining in-flight PR processing", "inFlight", inFlight, "gracePeriod", w.shutdownGracePeriod)
+	}
+
+	queueAbandoned := make(chan []workqueue.PR, 1)
+	go func() {
+		queueAbandoned <- w.workQueue.Drain(w.shutdownGracePeriod)
+	}()
+	abandoned := w.runs.drain(w.clock, w.shutdownGracePeriod)
+	abandoned = append(abandoned, <-queueAbandoned...)
+	sort.Slice(abandoned, func(i, j int) bool {
+		return abandoned[i].Less(abandoned[j])
+	})
+
+	// The watcher's context is already cancelled, so abandoning and saving get their own deadline
+	ctx, cancel := context.WithTimeout(context.Background(), stateSaveTimeout)
+	defer cancel()
+	for i, pr := range abandoned {
+		if i > 0 && pr == abandoned[i-1] {
+			continue // Run by both reconciliation and the queue
+		}
+		w.abandon(ctx, pr)
+	}
+	w.saveRetryState(ctx)
+}
+
+// abandon leaves a PR whose processing outlived the grace period to the next leader, whose
+// warm-up replans it and repairs its comment
+// With commit statuses, the PR's status turns pending so it doesn't vouch for a half-updated plan
+func (w *XRWatcher) abandon(ctx context.Context, pr workqueue.PR) {
+	w.tracker.markDirty(pr)
+	metrics.AbandonedPRs.Inc()
+	w.logger.Info("Abandoned in-flight PR processing after grace period", "pr", pr)
+
+	if w.commitStatusFailOn == 0 || w.vcsClient == nil {
+		return
+	}
+	if err := w.publishAbandonedStatus(ctx, pr); err != nil {
+		recordError("vcs", err)
+		w.logger.Error(err, "failed to publish commit status of abandoned PR", "pr", pr)
+	}
+}
+
+// publishAbandonedStatus publishes a pending commit status on the head commit of an abandoned PR
+// Its hash is recorded so the next plan publishes its status even if unchanged from before
+func (w *XRWatcher) publishAbandonedStatus(ctx context.Context, pr workqueue.PR) error {
+	vcsClient, err := w.vcsClientFor(pr.Repo)
+	if err != nil {
+		return err
+	}
+	headSHA, err := vcsClient.HeadSHA(ctx, pr.Number)
+	if err != nil {
+		return err
+	}
+
+	status := vcs.CommitStatus{State: vcs.StatusPending, Description: abandonedStatusDescription}
+	if err := vcsClient.CreateCommitStatus(ctx, headSHA, status); err != nil {
+		return err
+	}
+	hash := store.HashComment(headSHA + "\n" + status.State + "\n" + status.Description + "\n" + status.TargetURL)
+	if err := w.state.SetStatusHash(ctx, w.repositoryName(pr.Repo), pr.Number, hash); err != nil {
+		recordError("store", err)
+		w.logger.Error(err, "failed to record commit status hash", "pr", pr)
+	}
+	return nil
+}
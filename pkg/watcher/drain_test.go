@@ -0,0 +1,276 @@
+package watcher
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/millstonehq/crossplane-plan/pkg/severity"
+	"github.com/millstonehq/crossplane-plan/pkg/vcs"
+	"github.com/millstonehq/crossplane-plan/pkg/workqueue"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+// statusClient records the commit statuses published through it, in any repository
+// Other calls aren't implemented
+type statusClient struct {
+	vcs.Client
+
+	mu       sync.Mutex
+	statuses []vcs.CommitStatus
+}
+
+func (c *statusClient) Repository() string { return "acme/infra" }
+
+func (c *statusClient) ForRepository(repository string) (vcs.Client, error) {
+	return c, nil
+}
+
+func (c *statusClient) HeadSHA(ctx context.Context, prNumber int) (string, error) {
+	return "abc123", nil
+}
+
+func (c *statusClient) CreateCommitStatus(ctx context.Context, sha string, status vcs.CommitStatus) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.statuses = append(c.statuses, status)
+	return nil
+}
+
+// runsDraining reports whether Drain stopped reconciliation runs from starting
+func (w *XRWatcher) runsDraining() bool {
+	w.runs.mu.Lock()
+	defer w.runs.mu.Unlock()
+	return w.runs.draining
+}
+
+func TestXRWatcher_DrainReconcileRuns(t *testing.T) {
+	tests := []struct {
+		name          string
+		finishes      bool // whether the run finishes within the grace period
+		wantAbandoned bool
+	}{
+		{name: "run finishes within grace period", finishes: true},
+		{name: "run outlives grace period", wantAbandoned: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clocktesting.NewFakeClock(time.Now())
+			w := newTestWatcher(t, clk, nil)
+			w.SetShutdownGracePeriod(10 * time.Second)
+			w.SetCommitStatus(severity.Error)
+			client := &statusClient{}
+			w.vcsClient = client
+
+			// A reconciliation run of PR 5, whose context is cancelled like the watcher's on leadership loss
+			pr := workqueue.PR{Number: 5}
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			runCtx, finish, ok := w.runs.start(ctx, pr)
+			if !ok {
+				t.Fatal("run refused before draining")
+			}
+			if runCtx.Err() != nil {
+				t.Fatal("run cancelled with the watcher's context instead of by Drain")
+			}
+			release := make(chan struct{})
+			cancelled := make(chan bool, 1)
+			go func() {
+				defer finish()
+				select {
+				case <-release:
+					cancelled <- false
+				case <-runCtx.Done():
+					cancelled <- true
+				}
+			}()
+
+			drained := make(chan struct{})
+			go func() {
+				defer close(drained)
+				w.Drain()
+			}()
+
+			// Drain waits for the run and no other run starts meanwhile
+			for !w.runsDraining() {
+				time.Sleep(time.Millisecond)
+			}
+			select {
+			case <-drained:
+				t.Fatal("Drain returned while a reconciliation run was in flight")
+			case <-time.After(50 * time.Millisecond):
+			}
+			if _, _, ok := w.runs.start(context.Background(), workqueue.PR{Number: 6}); ok {
+				t.Error("run started while draining")
+			}
+
+			if tt.finishes {
+				close(release)
+			}
+			for done := false; !done; {
+				select {
+				case <-drained:
+					done = true
+				case <-time.After(time.Millisecond):
+					if !tt.finishes {
+						clk.Step(10 * time.Second)
+					}
+				}
+			}
+
+			if got := <-cancelled; got != tt.wantAbandoned {
+				t.Errorf("run cancelled = %v, want %v", got, tt.wantAbandoned)
+			}
+			var wantDirty []workqueue.PR
+			var wantStatuses []vcs.CommitStatus
+			if tt.wantAbandoned {
+				wantDirty = []workqueue.PR{pr}
+				wantStatuses = []vcs.CommitStatus{{State: vcs.StatusPending, Description: abandonedStatusDescription}}
+			}
+			if got := w.tracker.dirtyPRs(); len(got)+len(wantDirty) > 0 && !reflect.DeepEqual(got, wantDirty) {
+				t.Errorf("dirty PRs = %v, want %v", got, wantDirty)
+			}
+			if !reflect.DeepEqual(client.statuses, wantStatuses) {
+				t.Errorf("commit statuses = %v, want %v", client.statuses, wantStatuses)
+			}
+			if tt.wantAbandoned {
+				if hash, _ := w.state.StatusHash(context.Background(), "acme/infra", 5); hash == "" {
+					t.Error("pending commit status not recorded, the next plan's status would be skipped")
+				}
+			}
+
+			// Runs start again once drained
+			if _, finish, ok := w.runs.start(context.Background(), pr); !ok {
+				t.Error("run refused after draining")
+			} else {
+				finish()
+			}
+		})
+	}
+}
+
+// blockingProcessor processes PRs until they are released or abandoned
+type blockingProcessor struct {
+	started chan workqueue.PR
+	release chan struct{}
+}
+
+func (p *blockingProcessor) ProcessPR(ctx context.Context, pr workqueue.PR) error {
+	p.started <- pr
+	select {
+	case <-p.release:
+	case <-ctx.Done():
+	}
+	return nil
+}
+
+func TestXRWatcher_Drain(t *testing.T) {
+	tests := []struct {
+		name          string
+		queued        []workqueue.PR // PRs processed by the work queue
+		reconciled    []workqueue.PR // PRs planned by reconciliation
+		finishes      bool           // whether the runs finish within the grace period
+		statuses      bool           // whether commit statuses are published
+		wantAbandoned []workqueue.PR
+	}{
+		{name: "nothing in flight", statuses: true},
+		{name: "queue run finishes", queued: []workqueue.PR{{Number: 1}}, finishes: true, statuses: true},
+		{
+			name:          "queue and reconciliation runs abandoned",
+			queued:        []workqueue.PR{{Number: 1}},
+			reconciled:    []workqueue.PR{{Repo: "acme/network", Number: 2}},
+			statuses:      true,
+			wantAbandoned: []workqueue.PR{{Number: 1}, {Repo: "acme/network", Number: 2}},
+		},
+		{
+			name:          "PR run by both abandoned once",
+			queued:        []workqueue.PR{{Number: 1}},
+			reconciled:    []workqueue.PR{{Number: 1}},
+			statuses:      true,
+			wantAbandoned: []workqueue.PR{{Number: 1}},
+		},
+		{
+			name:          "abandoned without commit statuses",
+			reconciled:    []workqueue.PR{{Number: 2}},
+			wantAbandoned: []workqueue.PR{{Number: 2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clocktesting.NewFakeClock(time.Now())
+			processor := &blockingProcessor{started: make(chan workqueue.PR, len(tt.queued)), release: make(chan struct{})}
+			w := newTestWatcher(t, clk, nil, WithWorkQueue(func(workqueue.PRProcessor) WorkQueue {
+				return workqueue.NewPRWorkQueue(processor, logr.Discard(), time.Second, workqueue.WithClock(clk))
+			}))
+			w.SetShutdownGracePeriod(10 * time.Second)
+			if tt.statuses {
+				w.SetCommitStatus(severity.Error)
+			}
+			client := &statusClient{}
+			w.vcsClient = client
+
+			for _, pr := range tt.queued {
+				w.workQueue.Enqueue(context.Background(), pr)
+			}
+			clk.Step(time.Second)
+			for range tt.queued {
+				select {
+				case <-processor.started:
+				case <-time.After(5 * time.Second):
+					t.Fatal("timed out waiting for the queue to process a PR")
+				}
+			}
+			for _, pr := range tt.reconciled {
+				runCtx, finish, ok := w.runs.start(context.Background(), pr)
+				if !ok {
+					t.Fatal("run refused before draining")
+				}
+				go func() {
+					defer finish()
+					select {
+					case <-processor.release:
+					case <-runCtx.Done():
+					}
+				}()
+			}
+
+			drained := make(chan struct{})
+			go func() {
+				defer close(drained)
+				w.Drain()
+			}()
+			if tt.finishes {
+				close(processor.release)
+			}
+			for done := false; !done; {
+				select {
+				case <-drained:
+					done = true
+				case <-time.After(time.Millisecond):
+					if !tt.finishes {
+						clk.Step(10 * time.Second)
+					}
+				}
+			}
+
+			got := w.tracker.dirtyPRs()
+			sort.Slice(got, func(i, j int) bool { return got[i].Less(got[j]) })
+			if len(got)+len(tt.wantAbandoned) > 0 && !reflect.DeepEqual(got, tt.wantAbandoned) {
+				t.Errorf("PRs left for the next leader = %v, want %v", got, tt.wantAbandoned)
+			}
+			wantStatuses := 0
+			if tt.statuses {
+				wantStatuses = len(tt.wantAbandoned)
+			}
+			if len(client.statuses) != wantStatuses {
+				t.Errorf("%d commit statuses published, want %d", len(client.statuses), wantStatuses)
+			}
+		})
+	}
+}
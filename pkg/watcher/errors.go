@@ -0,0 +1,74 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/millstonehq/crossplane-plan/pkg/errclass"
+	"github.com/millstonehq/crossplane-plan/pkg/metrics"
+	"github.com/millstonehq/crossplane-plan/pkg/store"
+	"github.com/millstonehq/crossplane-plan/pkg/workqueue"
+)
+
+// recordError counts an error by component and class
+func recordError(component string, err error) {
+	metrics.Errors.WithLabelValues(component, string(errclass.ClassOf(err))).Inc()
+}
+
+// anyRetryable reports whether retrying may fix at least one of errs
+func anyRetryable(errs []error) bool {
+	for _, err := range errs {
+		if errclass.Retryable(err) {
+			return true
+		}
+	}
+	return false
+}
+
+// planFailed records the outcome of a failed plan of a PR
+// Transient failures are retried on the next reconciliation; persistent ones (auth, config,
+// not found) only once the PR's resources change, so they aren't retried every resync
+func (w *XRWatcher) planFailed(pr workqueue.PR, versions map[string]string, errs []error) {
+	if anyRetryable(errs) {
+		w.tracker.markDirty(pr)
+		return
+	}
+	w.logger.Info("Plan failed with a persistent error, not retrying until the PR changes",
+		"pr", pr, "class", errclass.ClassOf(errs[0]))
+	w.tracker.markPlanned(pr, versions)
+}
+
+// SetFailureComments posts a minimal comment on PRs whose whole plan fails, e.g. because XRDs
+// can't be discovered, instead of leaving them without a comment
+func (w *XRWatcher) SetFailureComments(enabled bool) {
+	w.failureComments = enabled
+}
+
+// reportPlanFailure posts (or updates) the comment of a PR whose plan failed before any resource was
+// planned, naming the error's class and a failure ID logged with the error
+// The ID only changes with the error, so retries failing the same way don't edit the comment again
+func (w *XRWatcher) reportPlanFailure(ctx context.Context, repo string, prNumber int, err error) {
+	class := errclass.ClassOf(err)
+	failureID := store.HashComment(fmt.Sprintf("%s\n%d\n%s", w.repositoryName(repo), prNumber, err.Error()))[:12]
+	w.logger.Error(err, "plan failed", "prNumber", prNumber, "class", class, "failureID", failureID)
+	if !w.failureComments || w.vcsClient == nil || previewFrom(ctx) != nil {
+		return
+	}
+
+	comment, post := w.applyFreeze(prNumber, w.formatter.FormatPlanFailure(class, failureID))
+	if !post || w.commentUnchanged(ctx, repo, prNumber, "", comment) {
+		return
+	}
+	vcsClient, err := w.vcsClientFor(repo)
+	if err != nil {
+		w.logger.Error(err, "failed to post failure comment", "prNumber", prNumber)
+		return
+	}
+	if _, err := vcsClient.PostCommentWithFooter(ctx, prNumber, comment, ""); err != nil {
+		recordError("vcs", err)
+		w.logger.Error(err, "failed to post failure comment", "prNumber", prNumber, "repo", vcsClient.Repository())
+		return
+	}
+	w.recordComment(ctx, repo, prNumber, "", comment)
+	w.logger.Info("Posted failure comment", "prNumber", prNumber, "repo", vcsClient.Repository(), "failureID", failureID)
+}
@@ -0,0 +1,37 @@
+package watcher
+
+import (
+	"github.com/millstonehq/crossplane-plan/pkg/config"
+)
+
+// activeFreeze returns the freeze window in effect, or nil
+func (w *XRWatcher) activeFreeze() *config.FreezeWindow {
+	w.settingsMu.RLock()
+	defer w.settingsMu.RUnlock()
+	if w.appConfig == nil {
+		return nil
+	}
+	return w.appConfig.ActiveFreeze(w.clock.Now())
+}
+
+// applyFreeze adapts a PR comment to the active freeze window
+// Returns false if the comment must be held back until the window ends
+func (w *XRWatcher) applyFreeze(prNumber int, comment string) (string, bool) {
+	window := w.activeFreeze()
+	if window == nil {
+		return comment, true
+	}
+
+	if window.FreezeAction() == config.FreezeMark {
+		return w.formatter.FormatFreezeNotice(window.Name) + comment, true
+	}
+
+	w.logger.Info("Freeze window active, holding comment back", "prNumber", prNumber, "window", window.Name)
+	return comment, false
+}
+
+// holdingComments reports whether a freeze window currently holds comments back
+func (w *XRWatcher) holdingComments() bool {
+	window := w.activeFreeze()
+	return window != nil && window.FreezeAction() == config.FreezeHold
+}
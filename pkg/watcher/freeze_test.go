@@ -0,0 +1,79 @@
+package watcher
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/millstonehq/crossplane-plan/pkg/config"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestXRWatcher_applyFreeze(t *testing.T) {
+	release := config.FreezeWindow{Name: "release", Start: "2025-12-20T00:00:00Z", End: "2025-12-27T00:00:00Z"}
+	nights := config.FreezeWindow{Name: "nights", From: "22:00", To: "06:00", Action: config.FreezeMark}
+
+	tests := []struct {
+		name        string
+		windows     []config.FreezeWindow
+		now         string
+		wantPost    bool
+		wantNotice  string
+		wantHolding bool
+	}{
+		{name: "no freeze windows", now: "2025-12-22T12:00:00Z", wantPost: true},
+		{name: "before the window", windows: []config.FreezeWindow{release}, now: "2025-12-19T23:59:00Z", wantPost: true},
+		{name: "hold window", windows: []config.FreezeWindow{release}, now: "2025-12-22T12:00:00Z", wantHolding: true},
+		{name: "after the window", windows: []config.FreezeWindow{release}, now: "2025-12-27T00:00:00Z", wantPost: true},
+		{name: "mark window", windows: []config.FreezeWindow{nights}, now: "2025-12-22T23:00:00Z", wantPost: true, wantNotice: "nights"},
+		{name: "mark window the next morning", windows: []config.FreezeWindow{nights}, now: "2025-12-23T05:59:00Z", wantPost: true, wantNotice: "nights"},
+		{name: "outside the recurring window", windows: []config.FreezeWindow{nights}, now: "2025-12-23T06:00:00Z", wantPost: true},
+		{
+			name:        "first active window wins",
+			windows:     []config.FreezeWindow{release, nights},
+			now:         "2025-12-22T23:00:00Z",
+			wantHolding: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now, err := time.Parse(time.RFC3339, tt.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w := newTestWatcher(t, clocktesting.NewFakeClock(now), nil)
+			w.appConfig = &config.Config{Freeze: tt.windows}
+
+			comment, post := w.applyFreeze(1, "plan")
+			if post != tt.wantPost {
+				t.Errorf("applyFreeze() post = %v, want %v", post, tt.wantPost)
+			}
+			if !strings.HasSuffix(comment, "plan") {
+				t.Errorf("applyFreeze() = %q, want the plan", comment)
+			}
+			if notice := comment != "plan"; notice != (tt.wantNotice != "") || !strings.Contains(comment, tt.wantNotice) {
+				t.Errorf("applyFreeze() = %q, want notice of window %q", comment, tt.wantNotice)
+			}
+			if holding := w.holdingComments(); holding != tt.wantHolding {
+				t.Errorf("holdingComments() = %v, want %v", holding, tt.wantHolding)
+			}
+		})
+	}
+}
+
+func TestXRWatcher_applyFreezeOnClock(t *testing.T) {
+	start, _ := time.Parse(time.RFC3339, "2025-12-26T23:00:00Z")
+	clk := clocktesting.NewFakeClock(start)
+	w := newTestWatcher(t, clk, nil)
+	w.appConfig = &config.Config{Freeze: []config.FreezeWindow{{Name: "release", Start: "2025-12-20T00:00:00Z", End: "2025-12-27T00:00:00Z"}}}
+
+	if _, post := w.applyFreeze(1, "plan"); post {
+		t.Fatal("comment posted during the freeze window")
+	}
+	// Held comments are posted once the window ends, without reloading the configuration
+	clk.Step(time.Hour)
+	if _, post := w.applyFreeze(1, "plan"); !post || w.holdingComments() {
+		t.Error("comment still held after the freeze window ended")
+	}
+}
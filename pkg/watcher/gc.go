@@ -0,0 +1,130 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/millstonehq/crossplane-plan/pkg/metrics"
+	"github.com/millstonehq/crossplane-plan/pkg/workqueue"
+)
+
+// SetStateGC garbage-collects the state of PRs without preview XRs every interval (0 to disable)
+// A PR's state is dropped once the PR is closed or merged, or once its last plan is older than ttl
+// (0 to only drop the state of closed PRs)
+func (w *XRWatcher) SetStateGC(interval, ttl time.Duration) {
+	w.stateGCInterval = interval
+	w.stateTTL = ttl
+}
+
+// runStateGC collects garbage every stateGCInterval
+func (w *XRWatcher) runStateGC(ctx context.Context) {
+	ticker := w.clock.NewTicker(w.stateGCInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+			if err := w.collectGarbage(ctx); err != nil {
+				w.logger.Error(err, "failed to garbage-collect PR state")
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// collectGarbage drops the stored and in-memory state of PRs that no longer need it
+// PRs with preview XRs are kept whatever their state, since their XRs will be planned again
+func (w *XRWatcher) collectGarbage(ctx context.Context) error {
+	previews, err := w.prsWithXRs(ctx)
+	if err != nil {
+		return err
+	}
+
+	prs, err := w.state.StoredPRs(ctx)
+	if err != nil {
+		recordError("store", err)
+		return fmt.Errorf("failed to list stored PRs: %w", err)
+	}
+
+	open := make(map[string][]int)
+	kept := make(map[workqueue.PR]bool)
+	evicted := 0
+	for _, pr := range prs {
+		key := w.prKey(pr.Repository, pr.PRNumber)
+		if previews[key] {
+			kept[key] = true
+			continue
+		}
+
+		reason := w.evictionReason(ctx, pr.Repository, pr.PRNumber, open)
+		if reason == "" {
+			kept[key] = true
+			continue
+		}
+		if err := w.state.ForgetPR(ctx, pr.Repository, pr.PRNumber); err != nil {
+			recordError("store", err)
+			w.logger.Error(err, "failed to drop PR state", "repo", pr.Repository, "prNumber", pr.PRNumber)
+			continue
+		}
+		metrics.StateEvictions.WithLabelValues(reason).Inc()
+		w.logger.V(1).Info("Dropped PR state", "repo", pr.Repository, "prNumber", pr.PRNumber, "reason", reason)
+		evicted++
+	}
+
+	// Planned PRs whose XRs are gone and that have no state left were missed by preview removal
+	for _, pr := range w.tracker.settledPRs() {
+		if previews[pr] || kept[pr] {
+			continue
+		}
+		w.tracker.forgetPR(pr)
+		w.forgetPlannedVersions(ctx, pr)
+		metrics.StateEvictions.WithLabelValues("absent").Inc()
+		evicted++
+	}
+
+	if evicted > 0 {
+		w.logger.Info("Garbage-collected PR state", "evicted", evicted)
+	}
+	return nil
+}
+
+// evictionReason returns why the state of a PR without preview XRs can be dropped: "closed",
+// "expired", or empty to keep it
+// open caches the open PRs of each repository, nil for a repository whose PRs can't be listed;
+// their PRs are treated as open
+func (w *XRWatcher) evictionReason(ctx context.Context, repository string, prNumber int, open map[string][]int) string {
+	if w.vcsClient != nil {
+		prs, listed := open[repository]
+		if !listed {
+			client, err := w.dashboardClientFor(repository)
+			if err == nil {
+				prs, err = client.ListOpenPRs(ctx)
+				prs = append([]int{}, prs...) // Listed, even if there are none
+			}
+			if err != nil {
+				recordError("vcs", err)
+				w.logger.Error(err, "failed to list open PRs, keeping the state of their closed PRs", "repo", repository)
+				prs = nil
+			}
+			open[repository] = prs
+		}
+		if prs != nil && !slices.Contains(prs, prNumber) {
+			return "closed"
+		}
+	}
+
+	if w.stateTTL <= 0 {
+		return ""
+	}
+	records, err := w.state.Plans(ctx, repository, prNumber)
+	if err != nil || len(records) == 0 {
+		return ""
+	}
+	if w.clock.Since(records[len(records)-1].Time) > w.stateTTL {
+		return "expired"
+	}
+	return ""
+}
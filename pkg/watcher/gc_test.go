@@ -0,0 +1,122 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/millstonehq/crossplane-plan/pkg/store"
+	"github.com/millstonehq/crossplane-plan/pkg/vcs"
+	"github.com/millstonehq/crossplane-plan/pkg/workqueue"
+	"k8s.io/apimachinery/pkg/runtime"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+// openPRsClient lists the open PRs of repositories; repositories missing from open can't be listed
+// Other calls aren't implemented
+type openPRsClient struct {
+	vcs.Client
+
+	repo string
+	open map[string][]int
+}
+
+func (c *openPRsClient) Repository() string { return c.repo }
+
+func (c *openPRsClient) ForRepository(repository string) (vcs.Client, error) {
+	return &openPRsClient{repo: repository, open: c.open}, nil
+}
+
+func (c *openPRsClient) ListOpenPRs(ctx context.Context) ([]int, error) {
+	prs, ok := c.open[c.repo]
+	if !ok {
+		return nil, errors.New("forbidden")
+	}
+	return prs, nil
+}
+
+func TestXRWatcher_collectGarbage(t *testing.T) {
+	now := time.Now()
+	// PR 1 of acme/infra has preview XRs; every stored PR has a plan history
+	stored := []struct {
+		repo     string
+		prNumber int
+		planned  time.Time
+	}{
+		{"acme/infra", 1, now.Add(-48 * time.Hour)},
+		{"acme/infra", 2, now},
+		{"acme/infra", 3, now.Add(-time.Hour)},
+		{"acme/infra", 4, now.Add(-48 * time.Hour)},
+		{"acme/network", 1, now},
+		{"acme/data", 5, now.Add(-48 * time.Hour)},
+	}
+
+	tests := []struct {
+		name string
+		open map[string][]int
+		ttl  time.Duration
+		want []string // the stored PRs left
+	}{
+		{
+			name: "closed PRs",
+			open: map[string][]int{"acme/infra": {3, 4}, "acme/network": {1}, "acme/data": {5}},
+			want: []string{"acme/data#5", "acme/infra#1", "acme/infra#3", "acme/infra#4", "acme/network#1"},
+		},
+		{
+			name: "closed and expired PRs",
+			open: map[string][]int{"acme/infra": {3, 4}, "acme/network": {1}, "acme/data": {5}},
+			ttl:  24 * time.Hour,
+			want: []string{"acme/infra#1", "acme/infra#3", "acme/network#1"},
+		},
+		{
+			name: "same PR number closed in another repository",
+			open: map[string][]int{"acme/infra": {2, 3, 4}, "acme/network": {}, "acme/data": {5}},
+			want: []string{"acme/data#5", "acme/infra#1", "acme/infra#2", "acme/infra#3", "acme/infra#4"},
+		},
+		{
+			name: "open PRs can't be listed",
+			open: map[string][]int{"acme/infra": {}},
+			want: []string{"acme/data#5", "acme/infra#1", "acme/network#1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			clk := clocktesting.NewFakeClock(now)
+			w := newTestWatcher(t, clk, []runtime.Object{newXRD("XBucket"), newXR("XBucket", "team", "pr-1-data", nil)})
+			w.vcsClient = &openPRsClient{repo: "acme/infra", open: tt.open}
+			w.SetStateGC(time.Hour, tt.ttl)
+			for _, pr := range stored {
+				if err := w.state.RecordPlan(ctx, pr.repo, pr.prNumber, store.PlanRecord{Time: pr.planned}); err != nil {
+					t.Fatalf("RecordPlan() error = %v", err)
+				}
+			}
+			// PR 6 was planned but lost its XRs and state without a removal being noticed
+			w.tracker.markPlanned(workqueue.PR{Number: 1}, nil)
+			w.tracker.markPlanned(workqueue.PR{Number: 6}, nil)
+
+			if err := w.collectGarbage(ctx); err != nil {
+				t.Fatalf("collectGarbage() error = %v", err)
+			}
+
+			prs, err := w.state.StoredPRs(ctx)
+			if err != nil {
+				t.Fatalf("StoredPRs() error = %v", err)
+			}
+			var got []string
+			for _, pr := range prs {
+				got = append(got, fmt.Sprintf("%s#%d", pr.Repository, pr.PRNumber))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("stored PRs after collectGarbage() = %v, want %v", got, tt.want)
+			}
+			if settled := w.tracker.settledPRs(); !reflect.DeepEqual(settled, []workqueue.PR{{Number: 1}}) {
+				t.Errorf("settled PRs after collectGarbage() = %v, want [#1]", settled)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package watcher
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// PlanIgnoreAnnotation excludes an XR from plans and comments when set to "true"
+	PlanIgnoreAnnotation = "millstone.tech/plan-ignore"
+)
+
+// isPlanIgnored reports whether an XR has opted out of planning
+func isPlanIgnored(xr *unstructured.Unstructured) bool {
+	return strings.EqualFold(strings.TrimSpace(xr.GetAnnotations()[PlanIgnoreAnnotation]), "true")
+}
+
+// withoutIgnored returns the XRs that haven't opted out of planning
+// Ignored XRs still count for deletion detection, so their production counterparts aren't deletions
+func withoutIgnored(xrs []*unstructured.Unstructured) []*unstructured.Unstructured {
+	planned := make([]*unstructured.Unstructured, 0, len(xrs))
+	for _, xr := range xrs {
+		if !isPlanIgnored(xr) {
+			planned = append(planned, xr)
+		}
+	}
+	return planned
+}
@@ -0,0 +1,98 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+	"github.com/millstonehq/crossplane-plan/pkg/metrics"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// listPageSize is how many objects a list request returns at a time
+// Full-cluster lists are paged so only one page of XRs is held in memory at once
+const listPageSize = 500
+
+// SetLimits bounds the memory a single plan may use (0 disables a limit)
+// maxBatchXRs caps the XRs planned per PR and repository; the rest are listed as not planned
+// maxDiffs caps the diffs whose text is kept per plan; later diffs are reduced to their summary
+func (w *XRWatcher) SetLimits(maxBatchXRs, maxDiffs int) {
+	w.maxBatchXRs = maxBatchXRs
+	w.maxDiffs = maxDiffs
+}
+
+// listEach calls visit for every object of a GVR, one page at a time
+// Objects are only valid during visit; keep a DeepCopy of the ones that are needed later
+// Returns the resource version of the list
+func (w *XRWatcher) listEach(ctx context.Context, gvr schema.GroupVersionResource, visit func(*unstructured.Unstructured)) (string, error) {
+	opts := metav1.ListOptions{Limit: listPageSize}
+	for {
+		list, err := w.dynamicClient.Resource(gvr).List(ctx, opts)
+		if err != nil {
+			return "", err
+		}
+		for i := range list.Items {
+			visit(&list.Items[i])
+		}
+		if list.GetContinue() == "" {
+			return list.GetResourceVersion(), nil
+		}
+		opts.Continue = list.GetContinue()
+	}
+}
+
+// shedXRs keeps the first max XRs by namespace and name, so the same XRs are planned every time
+// Returns the XRs to plan and the number shed
+func shedXRs(xrs []*unstructured.Unstructured, max int) ([]*unstructured.Unstructured, int) {
+	if max <= 0 || len(xrs) <= max {
+		return xrs, 0
+	}
+
+	sorted := append([]*unstructured.Unstructured(nil), xrs...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].GetNamespace() != sorted[j].GetNamespace() {
+			return sorted[i].GetNamespace() < sorted[j].GetNamespace()
+		}
+		return sorted[i].GetName() < sorted[j].GetName()
+	})
+	return sorted[:max], len(xrs) - max
+}
+
+// diffBudget counts the diff texts a plan keeps
+type diffBudget struct {
+	max  int
+	kept int
+	shed int
+}
+
+// admit keeps a diff's text while the budget lasts and reduces the diff to its summary after
+func (b *diffBudget) admit(diff *differ.DiffResult) {
+	if diff.RawDiff == "" {
+		return
+	}
+	if b.max <= 0 || b.kept < b.max {
+		b.kept++
+		return
+	}
+
+	diff.RawDiff = fmt.Sprintf("(diff omitted: this plan has more than %d diffs)", b.max)
+	diff.ProductionDrift = ""
+	diff.DiffInput = nil
+	diff.Debug = false
+	b.shed++
+}
+
+// recordShedding logs and counts the work a plan dropped to stay within the limits
+func (w *XRWatcher) recordShedding(prNumber, shedXRs, shedDiffs int) {
+	if shedXRs > 0 {
+		metrics.Shed.WithLabelValues("xr").Add(float64(shedXRs))
+		w.logger.Info("PR exceeds the XR limit, planning only part of it", "prNumber", prNumber, "shed", shedXRs, "maxBatchXRs", w.maxBatchXRs)
+	}
+	if shedDiffs > 0 {
+		metrics.Shed.WithLabelValues("diff").Add(float64(shedDiffs))
+		w.logger.Info("Plan exceeds the diff limit, omitting diff text", "prNumber", prNumber, "shed", shedDiffs, "maxDiffs", w.maxDiffs)
+	}
+}
@@ -0,0 +1,141 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestXRWatcher_listEach(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "xbuckets"}
+
+	tests := []struct {
+		name  string
+		pages [][]string // the XRs of each page
+	}{
+		{name: "empty", pages: [][]string{nil}},
+		{name: "one page", pages: [][]string{{"data", "logs"}}},
+		{name: "several pages", pages: [][]string{{"a", "b"}, {"c", "d"}, {"e"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWatcher(t, clocktesting.NewFakeClock(time.Now()), nil)
+			requests := 0
+			w.dynamicClient.(*fake.FakeDynamicClient).PrependReactor("list", "xbuckets", func(clienttesting.Action) (bool, runtime.Object, error) {
+				// The fake client drops the limit and continue token of requests, so pages are served in order
+				page := requests
+				requests++
+				list := &unstructured.UnstructuredList{}
+				list.SetResourceVersion("42")
+				if page < len(tt.pages)-1 {
+					list.SetContinue(fmt.Sprintf("page-%d", page+1))
+				}
+				for _, name := range tt.pages[page] {
+					list.Items = append(list.Items, *newXR("XBucket", "team", name, nil))
+				}
+				return true, list, nil
+			})
+
+			var visited, want []string
+			resourceVersion, err := w.listEach(context.Background(), gvr, func(xr *unstructured.Unstructured) {
+				visited = append(visited, xr.GetName())
+			})
+			if err != nil {
+				t.Fatalf("listEach() error = %v", err)
+			}
+			for _, page := range tt.pages {
+				want = append(want, page...)
+			}
+			if !reflect.DeepEqual(visited, want) || resourceVersion != "42" {
+				t.Errorf("listEach() visited %v at %q, want %v at \"42\"", visited, resourceVersion, want)
+			}
+			if requests != len(tt.pages) {
+				t.Errorf("listEach() requested %d pages, want %d", requests, len(tt.pages))
+			}
+		})
+	}
+}
+
+func TestShedXRs(t *testing.T) {
+	xrs := []*unstructured.Unstructured{
+		newXR("XBucket", "team", "pr-1-logs", nil),
+		newXR("XBucket", "apps", "pr-1-web", nil),
+		newXR("XBucket", "team", "pr-1-data", nil),
+	}
+
+	tests := []struct {
+		name     string
+		max      int
+		want     []string
+		wantShed int
+	}{
+		{name: "no limit", want: []string{"pr-1-logs", "pr-1-web", "pr-1-data"}},
+		{name: "within the limit", max: 3, want: []string{"pr-1-logs", "pr-1-web", "pr-1-data"}},
+		{name: "over the limit", max: 2, want: []string{"pr-1-web", "pr-1-data"}, wantShed: 1},
+		{name: "one XR", max: 1, want: []string{"pr-1-web"}, wantShed: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, shed := shedXRs(xrs, tt.max)
+			var got []string
+			for _, xr := range kept {
+				got = append(got, xr.GetName())
+			}
+			if !reflect.DeepEqual(got, tt.want) || shed != tt.wantShed {
+				t.Errorf("shedXRs() = %v, %d, want %v, %d", got, shed, tt.want, tt.wantShed)
+			}
+		})
+	}
+	if xrs[0].GetName() != "pr-1-logs" {
+		t.Error("shedXRs() reordered its input")
+	}
+}
+
+func TestDiffBudget_admit(t *testing.T) {
+	tests := []struct {
+		name     string
+		max      int
+		diffs    []string // the raw diff of each admitted diff
+		wantKept int
+		wantShed int
+	}{
+		{name: "no limit", diffs: []string{"a", "b", "c"}, wantKept: 3},
+		{name: "within the limit", max: 3, diffs: []string{"a", "b", "c"}, wantKept: 3},
+		{name: "over the limit", max: 2, diffs: []string{"a", "b", "c", "d"}, wantKept: 2, wantShed: 2},
+		{name: "diffs without text don't count", max: 1, diffs: []string{"", "a", ""}, wantKept: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			budget := &diffBudget{max: tt.max}
+			var results []*differ.DiffResult
+			for _, raw := range tt.diffs {
+				result := &differ.DiffResult{RawDiff: raw, ProductionDrift: "drift", Debug: true}
+				budget.admit(result)
+				results = append(results, result)
+			}
+
+			if budget.kept != tt.wantKept || budget.shed != tt.wantShed {
+				t.Errorf("admit() kept %d, shed %d, want %d, %d", budget.kept, budget.shed, tt.wantKept, tt.wantShed)
+			}
+			for i, result := range results[len(results)-tt.wantShed:] {
+				want := fmt.Sprintf("(diff omitted: this plan has more than %d diffs)", tt.max)
+				if result.RawDiff != want || result.ProductionDrift != "" || result.Debug {
+					t.Errorf("shed diff %d = %+v, want its summary only", i, result)
+				}
+			}
+		})
+	}
+}
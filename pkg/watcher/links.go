@@ -0,0 +1,53 @@
+package watcher
+
+import (
+	"github.com/millstonehq/crossplane-plan/pkg/config"
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// linksFor renders the configured deep links of a PR XR
+// Links failing to render are logged and left out
+func (w *XRWatcher) linksFor(repo string, prNumber int, xr *unstructured.Unstructured, productionName string, scope *Scope) []differ.Link {
+	w.settingsMu.RLock()
+	var templates []config.LinkTemplate
+	if w.appConfig != nil {
+		templates = w.appConfig.Links
+	}
+	w.settingsMu.RUnlock()
+	if len(templates) == 0 {
+		return nil
+	}
+
+	gvk := xr.GroupVersionKind()
+	data := config.LinkData{
+		Repository:     w.repositoryName(repo),
+		PRNumber:       prNumber,
+		APIVersion:     xr.GetAPIVersion(),
+		Group:          gvk.Group,
+		Kind:           gvk.Kind,
+		Name:           xr.GetName(),
+		Namespace:      xr.GetNamespace(),
+		ProductionName: productionName,
+	}
+	if scope != nil {
+		data.App = scope.PRAppName
+		data.ProductionApp = scope.ProdAppName
+	}
+
+	var links []differ.Link
+	for _, tmpl := range templates {
+		if !tmpl.AppliesTo(gvk.Kind) {
+			continue
+		}
+		url, err := tmpl.Render(data)
+		if err != nil {
+			w.logger.Error(err, "failed to render link", "link", tmpl.Name, "xr", xr.GetName())
+			continue
+		}
+		if url != "" {
+			links = append(links, differ.Link{Name: tmpl.Name, URL: url})
+		}
+	}
+	return links
+}
@@ -0,0 +1,14 @@
+package watcher
+
+import (
+	"time"
+
+	"github.com/millstonehq/crossplane-plan/pkg/logsample"
+)
+
+// SetLogSampling logs each per-resource message of hot paths (XR events, XRs of a batch, detected
+// deletions) at most once per interval, with a count of the suppressed ones; 0 logs them all
+// With the logger at logsample.DetailLevel, every message is logged regardless
+func (w *XRWatcher) SetLogSampling(interval time.Duration) {
+	w.sampler = logsample.NewSampler(w.logger, interval, w.clock)
+}
@@ -0,0 +1,177 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+	"github.com/millstonehq/crossplane-plan/pkg/formatter"
+	"github.com/millstonehq/crossplane-plan/pkg/severity"
+	"github.com/millstonehq/crossplane-plan/pkg/workqueue"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Outcome summarizes the plans of a single reconciliation pass
+type Outcome struct {
+	PRs       int `json:"prs"`
+	Changed   int `json:"changed"`
+	Deletions int `json:"deletions"`
+	Protected int `json:"protected"`
+	// Blocked counts the deletions of kinds a deletion policy blocks
+	Blocked int `json:"blocked"`
+	// Failed counts the XRs that could not be planned
+	Failed   int            `json:"failed"`
+	Severity severity.Level `json:"severity"`
+	// Risk is the rating of the severity from before severities: high, medium or none
+	Risk string `json:"risk"`
+	// Plans has one entry per planned PR and repository
+	Plans  []PlanOutcome `json:"plans"`
+	Errors []string      `json:"errors,omitempty"`
+}
+
+// PlanOutcome summarizes the plan of a PR for one repository
+type PlanOutcome struct {
+	Repository string         `json:"repository"`
+	PRNumber   int            `json:"pr"`
+	Resources  int            `json:"resources"`
+	Changed    int            `json:"changed"`
+	Deletions  int            `json:"deletions"`
+	Protected  int            `json:"protected"`
+	Blocked    int            `json:"blocked"`
+	Failed     int            `json:"failed"`
+	Severity   severity.Level `json:"severity"`
+	Risk       string         `json:"risk"`
+	// Draft plans don't count toward the totals of the pass
+	Draft bool `json:"draft,omitempty"`
+}
+
+// RunOnce plans every PR with preview XRs once (or only prNumber, if set), posts the
+// comments and returns, without leader election or watches
+func (w *XRWatcher) RunOnce(ctx context.Context, prNumber int) (Outcome, error) {
+	w.outcome = &Outcome{Plans: []PlanOutcome{}}
+	defer func() { w.outcome = nil }()
+
+	if w.vcsClient != nil {
+		w.refreshOrgRepos(ctx)
+	}
+
+	prs, err := w.prsWithPreviews(ctx, prNumber)
+	if err != nil {
+		w.outcome.Errors = append(w.outcome.Errors, err.Error())
+		return w.finishOutcome(), err
+	}
+
+	var errs []error
+	for _, pr := range prs {
+		if err := w.ProcessPR(ctx, pr); err != nil {
+			err = fmt.Errorf("PR %s: %w", pr, err)
+			w.outcome.Errors = append(w.outcome.Errors, err.Error())
+			errs = append(errs, err)
+		}
+	}
+	w.outcome.PRs = len(prs)
+	return w.finishOutcome(), errors.Join(errs...)
+}
+
+// finishOutcome rates the severity of the pass, that of its most severe plan
+func (w *XRWatcher) finishOutcome() Outcome {
+	outcome := *w.outcome
+	outcome.Severity = formatter.DashboardEntry{
+		Changed:   outcome.Changed,
+		Deletions: outcome.Deletions,
+		Protected: outcome.Protected,
+		Failed:    outcome.Failed,
+	}.Severity()
+	outcome.Risk = riskOf(outcome.Severity)
+	return outcome
+}
+
+// riskOf maps a severity to the risk rating of summaries from before severities
+func riskOf(level severity.Level) string {
+	switch level {
+	case severity.Destructive, severity.Error:
+		return "high"
+	case severity.Change:
+		return "medium"
+	default:
+		return "none"
+	}
+}
+
+// prsWithPreviews returns the PRs with preview XRs, by repository and PR number
+// With prNumber set, only the PRs with that number are returned, or that of the default
+// repository if it has no preview XRs anywhere (e.g. an ArgoCD-only PR)
+func (w *XRWatcher) prsWithPreviews(ctx context.Context, prNumber int) ([]workqueue.PR, error) {
+	gvrs, err := w.discoverXRDGVRs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover XRDs: %w", err)
+	}
+
+	seen := make(map[workqueue.PR]bool)
+	for _, gvr := range gvrs {
+		_, err := w.listEach(ctx, gvr, func(xr *unstructured.Unstructured) {
+			pr, err := w.prOf(xr)
+			if pr.Number > 0 && err == nil && !isPlanIgnored(xr) && (prNumber == 0 || pr.Number == prNumber) {
+				seen[pr] = true
+			}
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", gvr.String(), err)
+		}
+	}
+
+	prs := make([]workqueue.PR, 0, len(seen))
+	for pr := range seen {
+		prs = append(prs, pr)
+	}
+	if prNumber != 0 && len(prs) == 0 {
+		prs = append(prs, workqueue.PR{Number: prNumber})
+	}
+	sort.Slice(prs, func(i, j int) bool {
+		return prs[i].Less(prs[j])
+	})
+	return prs, nil
+}
+
+// recordOutcome adds a plan to the outcome of a RunOnce pass
+// With DraftNoFail, the plans of draft PRs are listed but not counted
+func (w *XRWatcher) recordOutcome(repo string, prNumber int, draft bool, resources, changed, deletions, protected, blocked, failed int) {
+	if w.outcome == nil {
+		return
+	}
+	entry := formatter.DashboardEntry{Changed: changed, Deletions: deletions, Protected: protected, Failed: failed}
+	w.outcome.Plans = append(w.outcome.Plans, PlanOutcome{
+		Repository: w.repositoryName(repo),
+		PRNumber:   prNumber,
+		Resources:  resources,
+		Changed:    changed,
+		Deletions:  deletions,
+		Protected:  protected,
+		Blocked:    blocked,
+		Failed:     failed,
+		Severity:   entry.Severity(),
+		Risk:       riskOf(entry.Severity()),
+		Draft:      draft,
+	})
+	if draft {
+		return
+	}
+	w.outcome.Changed += changed
+	w.outcome.Deletions += deletions
+	w.outcome.Protected += protected
+	w.outcome.Blocked += blocked
+	w.outcome.Failed += failed
+}
+
+// countBlocked counts the deletions a deletion policy blocks
+func countBlocked(items []differ.PlanItem) int {
+	blocked := 0
+	for _, item := range items {
+		if item.Blocked {
+			blocked++
+		}
+	}
+	return blocked
+}
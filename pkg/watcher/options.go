@@ -0,0 +1,65 @@
+package watcher
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/millstonehq/crossplane-plan/pkg/argocd"
+	"github.com/millstonehq/crossplane-plan/pkg/vcs"
+	"github.com/millstonehq/crossplane-plan/pkg/workqueue"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/clock"
+)
+
+// Option configures an XRWatcher when it is created
+type Option func(*XRWatcher)
+
+// WorkQueue debounces the PRs the watcher enqueues and processes them
+// workqueue.PRWorkQueue is the default implementation
+type WorkQueue interface {
+	Enqueue(ctx context.Context, pr workqueue.PR)
+	EnqueueAfter(ctx context.Context, pr workqueue.PR, debounce time.Duration)
+	Drain(grace time.Duration) []workqueue.PR
+	InFlightCount() int
+	Snapshot() []workqueue.WorkItem
+}
+
+// WithLogger sets the logger (default discards logs)
+func WithLogger(logger logr.Logger) Option {
+	return func(w *XRWatcher) { w.logger = logger }
+}
+
+// WithClientset sets the clientset used for leader election, e.g. with the pod's identity while
+// the watcher's config impersonates a plan-only identity
+// Defaults to one created from the watcher's config
+func WithClientset(clientset kubernetes.Interface) Option {
+	return func(w *XRWatcher) { w.clientset = clientset }
+}
+
+// WithVCS posts plans through client; without it plans aren't posted (dry run)
+func WithVCS(client vcs.Client) Option {
+	return func(w *XRWatcher) { w.vcsClient = client }
+}
+
+// WithArgoCD maps XRs to PRs through ArgoCD preview applications
+func WithArgoCD(client *argocd.Client) Option {
+	return func(w *XRWatcher) { w.argocdClient = client }
+}
+
+// WithReconcileInterval re-plans open PRs every interval minutes (0 to disable)
+func WithReconcileInterval(minutes int) Option {
+	return func(w *XRWatcher) { w.reconciliationInterval = minutes }
+}
+
+// WithWorkQueue replaces the default work queue with the one newQueue creates
+// newQueue is given the watcher, which processes the queued PRs
+func WithWorkQueue(newQueue func(processor workqueue.PRProcessor) WorkQueue) Option {
+	return func(w *XRWatcher) { w.newWorkQueue = newQueue }
+}
+
+// WithClock sets the clock the watcher's tickers, timers and backoff run on (default the real clock)
+// The default work queue runs its debounce timers on it too
+func WithClock(c clock.WithTickerAndDelayedExecution) Option {
+	return func(w *XRWatcher) { w.clock = c }
+}
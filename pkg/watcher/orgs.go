@@ -0,0 +1,62 @@
+package watcher
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/millstonehq/crossplane-plan/pkg/config"
+)
+
+// orgRefreshInterval is how often the repositories of the configured orgs are listed again
+const orgRefreshInterval = 10 * time.Minute
+
+// runOrgDiscovery keeps the repositories of the configured orgs up to date
+func (w *XRWatcher) runOrgDiscovery(ctx context.Context) {
+	ticker := w.clock.NewTicker(orgRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+			w.refreshOrgRepos(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// refreshOrgRepos lists the repositories of the configured orgs, which XRs may then target
+// An org that fails to list keeps its previously discovered repositories
+func (w *XRWatcher) refreshOrgRepos(ctx context.Context) {
+	w.settingsMu.RLock()
+	var orgs []config.OrgSelector
+	if w.appConfig != nil {
+		orgs = w.appConfig.Orgs
+	}
+	previous := w.orgRepos
+	w.settingsMu.RUnlock()
+
+	repos := make(map[string]bool)
+	for _, org := range orgs {
+		listed, err := w.vcsClient.ListOrgRepositories(ctx, org.Org, org.Topic)
+		if err != nil {
+			recordError("vcs", err)
+			w.logger.Error(err, "failed to list org repositories, keeping the previous list", "org", org.Org)
+			for repo := range previous {
+				if strings.EqualFold(strings.SplitN(repo, "/", 2)[0], org.Org) {
+					repos[repo] = true
+				}
+			}
+			continue
+		}
+		for _, repo := range listed {
+			repos[repo] = true
+		}
+		w.logger.V(1).Info("Discovered org repositories", "org", org.Org, "topic", org.Topic, "count", len(listed))
+	}
+
+	w.settingsMu.Lock()
+	defer w.settingsMu.Unlock()
+	w.orgRepos = repos
+}
@@ -0,0 +1,54 @@
+package watcher
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/millstonehq/crossplane-plan/pkg/config"
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+	"github.com/millstonehq/crossplane-plan/pkg/severity"
+)
+
+// ownerRules returns the configured owner rules
+func (w *XRWatcher) ownerRules() []config.OwnerRule {
+	w.settingsMu.RLock()
+	defer w.settingsMu.RUnlock()
+	if w.appConfig == nil {
+		return nil
+	}
+	return w.appConfig.Owners
+}
+
+// owningTeams returns the teams owning the resources a plan modifies or deletes, sorted
+// Only changes and destructive results need their owners' attention, not expected deletions or failures
+func (w *XRWatcher) owningTeams(items []differ.PlanItem) []string {
+	rules := w.ownerRules()
+	if len(rules) == 0 {
+		return nil
+	}
+
+	var teams []string
+	for _, item := range items {
+		if item.Severity != severity.Change && item.Severity != severity.Destructive {
+			continue
+		}
+		var kind, name string
+		if item.Action == differ.ActionDelete {
+			kind, name = item.GroupKind.Kind, item.Name[strings.LastIndex(item.Name, "/")+1:]
+		} else {
+			if item.XR == nil {
+				continue
+			}
+			kind, name = item.XR.GetKind(), w.currentDetector().GetBaseName(item.XR)
+		}
+
+		for _, rule := range rules {
+			if rule.Owns(kind, name) && !slices.Contains(teams, rule.Team) {
+				teams = append(teams, rule.Team)
+			}
+		}
+	}
+	sort.Strings(teams)
+	return teams
+}
@@ -0,0 +1,179 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/millstonehq/crossplane-plan/pkg/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/utils/clock"
+)
+
+// planConfigRetryDelay is how long Run waits before watching again after a failed watch
+const planConfigRetryDelay = 5 * time.Second
+
+// PlanConfigWatcher hot-reloads configuration from a PlanConfig resource
+// Every replica watches the resource so a failover leader already runs the latest config
+type PlanConfigWatcher struct {
+	dynamicClient  dynamic.Interface
+	namespace      string
+	name           string
+	base           *config.Config
+	apply          func(*config.Config) error
+	logger         logr.Logger
+	clock          clock.WithDelayedExecution
+	lastGeneration int64 // generation of the last PlanConfig that was processed
+}
+
+// NewPlanConfigWatcher creates a new PlanConfigWatcher
+// base holds the CLI settings the PlanConfig is layered over; apply installs a new config
+func NewPlanConfigWatcher(
+	dynamicClient dynamic.Interface,
+	namespace string,
+	name string,
+	base *config.Config,
+	apply func(*config.Config) error,
+	logger logr.Logger,
+) *PlanConfigWatcher {
+	return &PlanConfigWatcher{
+		dynamicClient: dynamicClient,
+		namespace:     namespace,
+		name:          name,
+		base:          base,
+		apply:         apply,
+		logger:        logger.WithName("planconfig"),
+		clock:         clock.RealClock{},
+	}
+}
+
+// Load fetches the PlanConfig and applies it once
+func (w *PlanConfigWatcher) Load(ctx context.Context) error {
+	obj, err := w.dynamicClient.Resource(config.PlanConfigGVR).Namespace(w.namespace).Get(ctx, w.name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get PlanConfig %s/%s: %w", w.namespace, w.name, err)
+	}
+	return w.reconcile(ctx, obj)
+}
+
+// Run watches the PlanConfig and re-applies it on every spec change until the context is cancelled
+func (w *PlanConfigWatcher) Run(ctx context.Context) {
+	w.logger.Info("Watching PlanConfig", "namespace", w.namespace, "name", w.name)
+
+	for ctx.Err() == nil {
+		if err := w.watchOnce(ctx); err != nil {
+			w.logger.Error(err, "PlanConfig watch failed, retrying", "delay", planConfigRetryDelay.String())
+			select {
+			case <-ctx.Done():
+			case <-w.clock.After(planConfigRetryDelay):
+			}
+		}
+	}
+}
+
+// watchOnce performs a single watch operation
+func (w *PlanConfigWatcher) watchOnce(ctx context.Context) error {
+	watcher, err := w.dynamicClient.Resource(config.PlanConfigGVR).Namespace(w.namespace).Watch(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("metadata.name", w.name).String(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create watcher: %w", err)
+	}
+	defer watcher.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return fmt.Errorf("watch channel closed")
+			}
+
+			switch event.Type {
+			case watch.Added, watch.Modified:
+				obj, ok := event.Object.(*unstructured.Unstructured)
+				if !ok {
+					w.logger.Error(nil, "unexpected object type")
+					continue
+				}
+				if err := w.reconcile(ctx, obj); err != nil {
+					w.logger.Error(err, "failed to apply PlanConfig, keeping previous configuration")
+				}
+			case watch.Deleted:
+				w.logger.Info("PlanConfig deleted, keeping last applied configuration")
+				w.lastGeneration = 0
+			case watch.Error:
+				w.logger.Error(nil, "PlanConfig watch error event")
+			}
+		}
+	}
+}
+
+// reconcile applies a PlanConfig and reports the outcome in its status
+// Status-only updates don't bump the generation and are skipped, so writing
+// status (including for invalid configs) doesn't loop
+func (w *PlanConfigWatcher) reconcile(ctx context.Context, obj *unstructured.Unstructured) error {
+	generation := obj.GetGeneration()
+	if generation != 0 && generation == w.lastGeneration {
+		return nil
+	}
+	w.lastGeneration = generation
+
+	applyErr := w.load(obj)
+	if applyErr == nil {
+		w.logger.Info("Applied PlanConfig", "generation", generation)
+	}
+
+	if err := w.updateStatus(ctx, obj, applyErr); err != nil {
+		// Replicas race to write status; the next reconcile will retry
+		w.logger.Info("Failed to update PlanConfig status", "error", err.Error())
+	}
+
+	return applyErr
+}
+
+// load converts the PlanConfig to a Config and installs it
+func (w *PlanConfigWatcher) load(obj *unstructured.Unstructured) error {
+	cfg, err := config.FromPlanConfig(obj, w.base)
+	if err != nil {
+		return err
+	}
+	for _, warning := range cfg.Warnings() {
+		w.logger.Info("Ignoring PlanConfig rule", "problem", warning)
+	}
+	return w.apply(cfg)
+}
+
+// updateStatus records the result of applying the PlanConfig as a Ready condition
+func (w *PlanConfigWatcher) updateStatus(ctx context.Context, obj *unstructured.Unstructured, applyErr error) error {
+	condition := map[string]interface{}{
+		"type":               "Ready",
+		"status":             "True",
+		"reason":             "Applied",
+		"message":            "Configuration is valid and active",
+		"lastTransitionTime": w.clock.Now().UTC().Format(time.RFC3339),
+	}
+	if applyErr != nil {
+		condition["status"] = "False"
+		condition["reason"] = "InvalidConfig"
+		condition["message"] = applyErr.Error()
+	}
+
+	updated := obj.DeepCopy()
+	status := map[string]interface{}{
+		"observedGeneration": obj.GetGeneration(),
+		"conditions":         []interface{}{condition},
+	}
+	if err := unstructured.SetNestedField(updated.Object, status, "status"); err != nil {
+		return fmt.Errorf("failed to set status: %w", err)
+	}
+
+	_, err := w.dynamicClient.Resource(config.PlanConfigGVR).Namespace(w.namespace).UpdateStatus(ctx, updated, metav1.UpdateOptions{})
+	return err
+}
@@ -0,0 +1,74 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/millstonehq/crossplane-plan/pkg/config"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestPlanConfigWatcher_RunRetriesOnClock(t *testing.T) {
+	client := fake.NewSimpleDynamicClient(runtime.NewScheme())
+	watches := make(chan struct{}, 10)
+	client.PrependWatchReactor(config.PlanConfigGVR.Resource, func(clienttesting.Action) (bool, watch.Interface, error) {
+		watches <- struct{}{}
+		return true, nil, errors.New("connection refused")
+	})
+
+	clk := clocktesting.NewFakeClock(time.Now())
+	w := NewPlanConfigWatcher(client, "crossplane-system", "default", config.DefaultConfig(), func(*config.Config) error { return nil }, logr.Discard())
+	w.clock = clk
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.Run(ctx)
+	}()
+
+	expectWatch := func(want bool) {
+		t.Helper()
+		timeout := 5 * time.Second
+		if !want {
+			timeout = 50 * time.Millisecond
+		}
+		select {
+		case <-watches:
+			if !want {
+				t.Fatal("PlanConfig watched again before the retry delay elapsed")
+			}
+		case <-time.After(timeout):
+			if want {
+				t.Fatal("timed out waiting for a PlanConfig watch")
+			}
+		}
+	}
+
+	expectWatch(true)
+	for !clk.HasWaiters() {
+		time.Sleep(time.Millisecond)
+	}
+	clk.Step(planConfigRetryDelay - time.Millisecond)
+	expectWatch(false)
+	clk.Step(time.Millisecond)
+	expectWatch(true)
+
+	// Cancelling stops Run while it waits to retry
+	for !clk.HasWaiters() {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after its context was cancelled")
+	}
+}
@@ -0,0 +1,31 @@
+package watcher
+
+import (
+	"context"
+
+	"github.com/millstonehq/crossplane-plan/pkg/api"
+	"github.com/millstonehq/crossplane-plan/pkg/store"
+)
+
+// LatestPlan implements api.PlanSource
+func (w *XRWatcher) LatestPlan(ctx context.Context, repo string, prNumber int) (*api.Plan, error) {
+	plan := &api.Plan{}
+	ok, err := w.state.LatestPlan(ctx, repo, prNumber, plan)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// PlanHistory implements api.PlanSource
+func (w *XRWatcher) PlanHistory(ctx context.Context, repo string, prNumber int) ([]store.PlanRecord, error) {
+	return w.state.Plans(ctx, repo, prNumber)
+}
+
+// recordLatestPlan records a PR's plan for the plan API
+func (w *XRWatcher) recordLatestPlan(ctx context.Context, repo string, prNumber int, plan *api.Plan) {
+	if err := w.state.SetLatestPlan(ctx, w.repositoryName(repo), prNumber, plan); err != nil {
+		recordError("store", err)
+		w.logger.Error(err, "failed to record latest plan", "prNumber", prNumber)
+	}
+}
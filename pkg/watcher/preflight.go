@@ -0,0 +1,234 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// Permission is an access requirement: verbs on a resource, cluster-wide unless Namespace is set
+type Permission struct {
+	Group     string
+	Resource  string
+	Namespace string
+	Name      string // restricts the requirement to one object, e.g. the impersonated user
+	Verbs     []string
+	Purpose   string
+}
+
+// String formats the permission the way it appears in an RBAC rule
+func (p Permission) String() string {
+	resource := p.Resource
+	if p.Group != "" {
+		resource += "." + p.Group
+	}
+	if p.Name != "" {
+		resource += "/" + p.Name
+	}
+	scope := "cluster-wide"
+	if p.Namespace != "" {
+		scope = "in namespace " + p.Namespace
+	}
+	return fmt.Sprintf("%s %s (%s)", strings.Join(p.Verbs, ","), resource, scope)
+}
+
+// PreflightReport lists the permissions the service account is missing
+type PreflightReport struct {
+	Checked int
+	Missing []Permission
+}
+
+// OK reports whether every required permission is granted
+func (r *PreflightReport) OK() bool {
+	return len(r.Missing) == 0
+}
+
+// String summarizes the missing permissions, one per line
+func (r *PreflightReport) String() string {
+	if r.OK() {
+		return fmt.Sprintf("all %d permission checks passed", r.Checked)
+	}
+	lines := make([]string, 0, len(r.Missing)+1)
+	lines = append(lines, fmt.Sprintf("%d of %d permission checks failed:", len(r.Missing), r.Checked))
+	for _, p := range r.Missing {
+		lines = append(lines, fmt.Sprintf("  - %s: needed to %s", p, p.Purpose))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Preflight verifies with SelfSubjectAccessReviews that the service account has every
+// permission crossplane-plan needs, so missing RBAC is reported once at startup
+// rather than as scattered failures during reconciliation
+// With impersonation, reading and diffing permissions are checked for the impersonated identity
+func (w *XRWatcher) Preflight(ctx context.Context) (*PreflightReport, error) {
+	xrdPermission := Permission{
+		Group:    "apiextensions.crossplane.io",
+		Resource: "compositeresourcedefinitions",
+		Verbs:    []string{"list"},
+		Purpose:  "discover composite resource types",
+	}
+	required := []Permission{
+		xrdPermission,
+		{
+			Group:    "apiextensions.crossplane.io",
+			Resource: "compositions",
+			Verbs:    []string{"list"},
+			Purpose:  "render composite resources",
+		},
+	}
+	leaderRequired := append([]Permission{{
+		Group:     "coordination.k8s.io",
+		Resource:  "leases",
+		Namespace: podNamespace(),
+		Verbs:     []string{"get", "create", "update"},
+		Purpose:   "run leader election",
+	}}, impersonationPermissions(w.cfg.Impersonate)...)
+
+	if w.argocdClient != nil {
+		required = append(required, Permission{
+			Group:     "argoproj.io",
+			Resource:  "applications",
+			Namespace: w.argocdClient.Namespace(),
+			Verbs:     []string{"get", "list", "watch"},
+			Purpose:   "read ArgoCD Applications for scope discovery, deletion detection, and ArgoCD-only PRs",
+		})
+	}
+
+	// XR types can only be discovered if XRDs can be listed
+	xrdAllowed, err := w.allowed(ctx, w.planClientset, xrdPermission)
+	if err != nil {
+		return nil, err
+	}
+	if xrdAllowed {
+		gvrs, err := w.discoverXRDGVRs(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to discover XRDs: %w", err)
+		}
+		required = append(required, xrPermissions(gvrs)...)
+	}
+
+	report := &PreflightReport{}
+	if err := w.checkPermissions(ctx, w.clientset, leaderRequired, report); err != nil {
+		return nil, err
+	}
+	if err := w.checkPermissions(ctx, w.planClientset, required, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// checkPermissions checks every verb of the permissions as the identity of client,
+// adding the missing ones to report
+func (w *XRWatcher) checkPermissions(ctx context.Context, client kubernetes.Interface, required []Permission, report *PreflightReport) error {
+	for _, p := range required {
+		var missing []string
+		for _, verb := range p.Verbs {
+			report.Checked++
+			check := p
+			check.Verbs = []string{verb}
+			ok, err := w.allowed(ctx, client, check)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				missing = append(missing, verb)
+			}
+		}
+		if len(missing) > 0 {
+			p.Verbs = missing
+			report.Missing = append(report.Missing, p)
+		}
+	}
+	return nil
+}
+
+// impersonationPermissions returns the permissions needed to impersonate the plan-only identity
+func impersonationPermissions(impersonate rest.ImpersonationConfig) []Permission {
+	if impersonate.UserName == "" {
+		return nil
+	}
+
+	var permissions []Permission
+	if parts := strings.Split(impersonate.UserName, ":"); len(parts) == 4 && parts[0] == "system" && parts[1] == "serviceaccount" {
+		permissions = append(permissions, Permission{
+			Resource:  "serviceaccounts",
+			Namespace: parts[2],
+			Name:      parts[3],
+			Verbs:     []string{"impersonate"},
+			Purpose:   "read and diff resources as the plan-only service account",
+		})
+	} else {
+		permissions = append(permissions, Permission{
+			Resource: "users",
+			Name:     impersonate.UserName,
+			Verbs:    []string{"impersonate"},
+			Purpose:  "read and diff resources as the plan-only user",
+		})
+	}
+	for _, group := range impersonate.Groups {
+		permissions = append(permissions, Permission{
+			Resource: "groups",
+			Name:     group,
+			Verbs:    []string{"impersonate"},
+			Purpose:  "read and diff resources with the plan-only identity's groups",
+		})
+	}
+	return permissions
+}
+
+// xrPermissions returns the permissions needed to watch and diff each XR type
+func xrPermissions(gvrs []schema.GroupVersionResource) []Permission {
+	sorted := append([]schema.GroupVersionResource(nil), gvrs...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].String() < sorted[j].String()
+	})
+
+	permissions := make([]Permission, 0, len(sorted))
+	for _, gvr := range sorted {
+		permissions = append(permissions, Permission{
+			Group:    gvr.Group,
+			Resource: gvr.Resource,
+			// patch is needed for the server-side dry-run apply during diff calculation
+			Verbs:   []string{"get", "list", "watch", "patch"},
+			Purpose: "watch and diff composite resources",
+		})
+	}
+	return permissions
+}
+
+// allowed checks a single verb with a SelfSubjectAccessReview as the identity of client
+func (w *XRWatcher) allowed(ctx context.Context, client kubernetes.Interface, p Permission) (bool, error) {
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Group:     p.Group,
+				Resource:  p.Resource,
+				Namespace: p.Namespace,
+				Name:      p.Name,
+				Verb:      p.Verbs[0],
+			},
+		},
+	}
+
+	result, err := client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return false, fmt.Errorf("failed to review access to %s: %w", p.Resource, err)
+	}
+	return result.Status.Allowed, nil
+}
+
+// podNamespace returns the namespace the leader election lease lives in
+func podNamespace() string {
+	if ns := os.Getenv("POD_NAMESPACE"); ns != "" {
+		return ns
+	}
+	return "crossplane-system"
+}
@@ -0,0 +1,135 @@
+package watcher
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	clienttesting "k8s.io/client-go/testing"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+// reviewingClientset answers SelfSubjectAccessReviews, denying the "verb resource" pairs of denied
+func reviewingClientset(denied ...string) *fake.Clientset {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		review := action.(clienttesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		attributes := review.Spec.ResourceAttributes
+		review.Status.Allowed = true
+		for _, d := range denied {
+			if d == attributes.Verb+" "+attributes.Resource {
+				review.Status.Allowed = false
+			}
+		}
+		return true, review, nil
+	})
+	return client
+}
+
+func TestXRWatcher_Preflight(t *testing.T) {
+	t.Setenv("POD_NAMESPACE", "")
+
+	tests := []struct {
+		name        string
+		leaderDeny  []string
+		planDeny    []string
+		impersonate string
+		wantChecked int
+		wantMissing []string
+	}{
+		{name: "all granted", wantChecked: 9},
+		{
+			name:        "lease update missing",
+			leaderDeny:  []string{"update leases"},
+			wantChecked: 9,
+			wantMissing: []string{"update leases.coordination.k8s.io (in namespace crossplane-system)"},
+		},
+		{
+			name:        "XR patch missing",
+			planDeny:    []string{"patch xbuckets"},
+			wantChecked: 9,
+			wantMissing: []string{"patch xbuckets.example.com (cluster-wide)"},
+		},
+		{
+			name:        "XRDs can't be listed",
+			planDeny:    []string{"list compositeresourcedefinitions"},
+			wantChecked: 5,
+			wantMissing: []string{"list compositeresourcedefinitions.apiextensions.crossplane.io (cluster-wide)"},
+		},
+		{
+			name:        "impersonation checked for the leader identity",
+			leaderDeny:  []string{"impersonate serviceaccounts", "patch xbuckets"},
+			planDeny:    []string{"impersonate serviceaccounts"},
+			impersonate: "system:serviceaccount:crossplane-system:plan-only",
+			wantChecked: 10,
+			wantMissing: []string{"impersonate serviceaccounts/plan-only (in namespace crossplane-system)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWatcher(t, clocktesting.NewFakeClock(time.Now()), []runtime.Object{newXRD("XBucket")})
+			w.clientset = reviewingClientset(tt.leaderDeny...)
+			w.planClientset = reviewingClientset(tt.planDeny...)
+			w.cfg.Impersonate = rest.ImpersonationConfig{UserName: tt.impersonate}
+
+			report, err := w.Preflight(context.Background())
+			if err != nil {
+				t.Fatalf("Preflight() error = %v", err)
+			}
+
+			var missing []string
+			for _, p := range report.Missing {
+				missing = append(missing, p.String())
+			}
+			if report.Checked != tt.wantChecked || !reflect.DeepEqual(missing, tt.wantMissing) {
+				t.Errorf("Preflight() checked %d, missing %v, want %d, %v", report.Checked, missing, tt.wantChecked, tt.wantMissing)
+			}
+			if report.OK() != (len(tt.wantMissing) == 0) {
+				t.Errorf("OK() = %v with missing %v", report.OK(), missing)
+			}
+		})
+	}
+}
+
+func TestImpersonationPermissions(t *testing.T) {
+	tests := []struct {
+		name        string
+		impersonate rest.ImpersonationConfig
+		want        []string
+	}{
+		{name: "no impersonation"},
+		{
+			name:        "service account",
+			impersonate: rest.ImpersonationConfig{UserName: "system:serviceaccount:plan:reader"},
+			want:        []string{"impersonate serviceaccounts/reader (in namespace plan)"},
+		},
+		{
+			name:        "user with groups",
+			impersonate: rest.ImpersonationConfig{UserName: "plan-reader", Groups: []string{"readers"}},
+			want:        []string{"impersonate users/plan-reader (cluster-wide)", "impersonate groups/readers (cluster-wide)"},
+		},
+		{
+			name:        "user named like a service account",
+			impersonate: rest.ImpersonationConfig{UserName: "system:serviceaccount:plan"},
+			want:        []string{"impersonate users/system:serviceaccount:plan (cluster-wide)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, p := range impersonationPermissions(tt.impersonate) {
+				got = append(got, p.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("impersonationPermissions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
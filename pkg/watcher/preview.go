@@ -0,0 +1,94 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/millstonehq/crossplane-plan/pkg/admin"
+	"github.com/millstonehq/crossplane-plan/pkg/workqueue"
+)
+
+// previewKey is the context key of a preview run's collected comments
+type previewKey struct{}
+
+// commentPreview collects the comments a preview run would post
+type commentPreview struct {
+	comments []admin.PreviewComment
+}
+
+// previewFrom returns the preview collecting a run's comments, or nil when the run posts them
+func previewFrom(ctx context.Context) *commentPreview {
+	preview, _ := ctx.Value(previewKey{}).(*commentPreview)
+	return preview
+}
+
+// add records the comment that would be posted to a repository (empty means default)
+func (p *commentPreview) add(w *XRWatcher, repo, comment, footer string, held bool) error {
+	body := comment
+	if footer != "" {
+		body += "\n" + footer
+	}
+	if w.vcsClient != nil {
+		vcsClient, err := w.vcsClientFor(repo)
+		if err != nil {
+			return err
+		}
+		body = vcsClient.CommentBody(comment, footer)
+	}
+	p.comments = append(p.comments, admin.PreviewComment{Repository: w.repositoryName(repo), Body: body, Held: held})
+	return nil
+}
+
+// PreviewPR implements admin.CommentPreviewer: it runs the plan of a PR and returns the
+// comments it would post, without posting them, placeholders or recording any state
+func (w *XRWatcher) PreviewPR(ctx context.Context, prNumber int) ([]admin.PreviewComment, error) {
+	preview := &commentPreview{}
+	ctx = context.WithValue(ctx, previewKey{}, preview)
+
+	xrs, err := w.findAllPRResources(ctx, prNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find PR resources: %w", err)
+	}
+
+	if len(withoutIgnored(xrs)) == 0 {
+		if w.argocdClient == nil {
+			return nil, nil
+		}
+		apps, err := w.argocdClient.FindPRApplications(ctx, prNumber)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find PR applications: %w", err)
+		}
+		if len(apps) == 0 {
+			return nil, nil
+		}
+		combined, errs := w.combinedAppDiff(ctx, apps)
+		if len(errs) > 0 {
+			return nil, errors.Join(errs...)
+		}
+		if !hasAppChanges(combined) {
+			return nil, nil
+		}
+		comment, post := w.applyFreeze(prNumber, w.appOnlyComment(prNumber, combined))
+		if err := preview.add(w, "", comment, "", !post); err != nil {
+			return nil, err
+		}
+		return preview.comments, nil
+	}
+
+	// The PRs with this number in every repository their XRs target
+	var errs []error
+	for repo, repoXRs := range w.groupByTargetRepo(prNumber, xrs) {
+		if len(withoutIgnored(repoXRs)) == 0 {
+			continue
+		}
+		if err := w.planPR(ctx, workqueue.PR{Repo: repo, Number: prNumber}, repoXRs); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	sort.Slice(preview.comments, func(i, j int) bool {
+		return preview.comments[i].Repository < preview.comments[j].Repository
+	})
+	return preview.comments, errors.Join(errs...)
+}
@@ -0,0 +1,43 @@
+package watcher
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// PlanPriorityAnnotation set to "high" plans the XR's PR without waiting for the debounce
+	PlanPriorityAnnotation = "millstone.tech/plan-priority"
+
+	// PlanDebounceAnnotation overrides the debounce of the XR's PR (a duration, e.g. "1s")
+	PlanDebounceAnnotation = "millstone.tech/plan-debounce"
+)
+
+// debounceOverride returns the debounce an XR asks for with the priority or debounce annotation
+// ok is false when the XR has neither; a high priority wins over a debounce
+func debounceOverride(xr *unstructured.Unstructured) (debounce time.Duration, ok bool, err error) {
+	annotations := xr.GetAnnotations()
+
+	if priority, set := annotations[PlanPriorityAnnotation]; set {
+		switch strings.ToLower(strings.TrimSpace(priority)) {
+		case "high":
+			return 0, true, nil
+		case "normal", "":
+		default:
+			return 0, false, fmt.Errorf("invalid %s %q, expected high or normal", PlanPriorityAnnotation, priority)
+		}
+	}
+
+	if value, set := annotations[PlanDebounceAnnotation]; set {
+		debounce, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || debounce < 0 {
+			return 0, false, fmt.Errorf("invalid %s %q, expected a duration like 1s", PlanDebounceAnnotation, value)
+		}
+		return debounce, true, nil
+	}
+
+	return 0, false, nil
+}
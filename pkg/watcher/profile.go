@@ -0,0 +1,171 @@
+package watcher
+
+import (
+	"context"
+	"slices"
+
+	"github.com/millstonehq/crossplane-plan/pkg/config"
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+	"github.com/millstonehq/crossplane-plan/pkg/formatter"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// SetConfig enables per-repository profiles and the comment style from the application config
+// Repositories without a profile use the calculator's default sanitizer
+func (w *XRWatcher) SetConfig(cfg *config.Config) {
+	repoSanitizers := make(map[string]*differ.Sanitizer, len(cfg.Repos))
+	for repo := range cfg.Repos {
+		var sanitizer *differ.Sanitizer
+		if rules := cfg.StripRulesFor(repo); len(rules) > 0 {
+			sanitizer = differ.NewSanitizer(rules)
+		}
+		repoSanitizers[repo] = sanitizer
+	}
+
+	w.formatter.SetStyle(formatter.Style{
+		NoEmoji:  !cfg.Comments.EmojiEnabled(),
+		Title:    cfg.Comments.Title,
+		Headings: cfg.Comments.Headings,
+	})
+	w.formatter.SetPostProcessors(w.postProcessors(cfg.Comments.PostProcessors))
+	if err := w.setLayout(cfg.Comments); err != nil {
+		w.logger.Error(err, "invalid comment template, keeping the current layout")
+	}
+	if w.argocdClient != nil {
+		w.argocdClient.SetProjects(cfg.ArgoCD.Projects)
+	}
+
+	w.settingsMu.Lock()
+	defer w.settingsMu.Unlock()
+	w.appConfig = cfg
+	w.repoSanitizers = repoSanitizers
+}
+
+// postProcessors builds the configured comment post-processors
+// Invalid ones, which validation rejects at load, are skipped
+func (w *XRWatcher) postProcessors(configured []config.PostProcessor) []formatter.PostProcessor {
+	var processors []formatter.PostProcessor
+	for i, processor := range configured {
+		pattern, err := processor.Pattern()
+		if err != nil {
+			w.logger.Error(err, "skipping invalid comment post-processor", "index", i)
+			continue
+		}
+		switch {
+		case processor.Replace != "":
+			processors = append(processors, formatter.ReplaceText(pattern, processor.With))
+		case processor.RewriteLinks != "":
+			processors = append(processors, formatter.RewriteLinks(pattern, processor.With))
+		case processor.Append != "":
+			processors = append(processors, formatter.AppendText(processor.Append))
+		}
+	}
+	return processors
+}
+
+// setLayout installs the configured comment layout template
+func (w *XRWatcher) setLayout(style config.CommentStyle) error {
+	layout, err := style.Layout()
+	if err != nil {
+		return err
+	}
+	return w.formatter.SetLayout(layout)
+}
+
+// repositoryName resolves a target repository to "owner/repo" (empty means default)
+func (w *XRWatcher) repositoryName(repo string) string {
+	if repo != "" {
+		return repo
+	}
+	if w.vcsClient != nil {
+		return w.vcsClient.Repository()
+	}
+
+	w.settingsMu.RLock()
+	defer w.settingsMu.RUnlock()
+	if w.appConfig != nil {
+		return w.appConfig.GitHubRepo
+	}
+	return ""
+}
+
+// profileFor returns the profile for a target repository (empty means default)
+func (w *XRWatcher) profileFor(repo string) config.RepoProfile {
+	name := w.repositoryName(repo)
+
+	w.settingsMu.RLock()
+	defer w.settingsMu.RUnlock()
+	if w.appConfig == nil {
+		return config.RepoProfile{}
+	}
+	return w.appConfig.Profile(name)
+}
+
+// deletionPolicies returns the configured deletion policies
+func (w *XRWatcher) deletionPolicies() []config.DeletionPolicy {
+	w.settingsMu.RLock()
+	defer w.settingsMu.RUnlock()
+	if w.appConfig == nil {
+		return nil
+	}
+	return w.appConfig.Diff.DeletionPolicies
+}
+
+// calculateDiff calculates a diff using the target repository's strip rules
+// With a git baseline (non-nil declared), the XR is compared against its declared production version
+func (w *XRWatcher) calculateDiff(ctx context.Context, repo string, xr *unstructured.Unstructured, declared differ.Declared) (*differ.DiffResult, error) {
+	name := w.repositoryName(repo)
+
+	w.settingsMu.RLock()
+	sanitizer, ok := w.repoSanitizers[name]
+	w.settingsMu.RUnlock()
+
+	if declared != nil {
+		threeWay := false
+		if baseline := w.profileFor(repo).GitBaseline; baseline != nil {
+			threeWay = baseline.ThreeWay
+		}
+		if ok {
+			return w.differ.CalculateDeclaredDiffWithSanitizer(ctx, xr, declared.Lookup(xr), sanitizer, threeWay)
+		}
+		return w.differ.CalculateDeclaredDiff(ctx, xr, declared.Lookup(xr), threeWay)
+	}
+	if ok {
+		return w.differ.CalculateDiffWithSanitizer(ctx, xr, sanitizer)
+	}
+	return w.differ.CalculateDiff(ctx, xr)
+}
+
+// markProtectedKinds flags changes to protected XR kinds in their summaries
+func markProtectedKinds(items []differ.PlanItem, protectedKinds []string) {
+	if len(protectedKinds) == 0 {
+		return
+	}
+	for _, item := range items {
+		if item.Action != differ.ActionCreate && item.Action != differ.ActionUpdate {
+			continue
+		}
+		if slices.Contains(protectedKinds, item.GroupKind.Kind) {
+			item.Summary = "🔒 **Protected kind** - " + item.Summary
+		}
+	}
+}
+
+// applyProfileTemplate wraps a formatted comment with the profile's template (if any)
+func (w *XRWatcher) applyProfileTemplate(repo string, prNumber int, comment string) string {
+	profile := w.profileFor(repo)
+	if profile.Template == "" {
+		return comment
+	}
+
+	rendered, err := formatter.RenderTemplate(profile.Template, formatter.CommentData{
+		Body:       comment,
+		Repository: w.repositoryName(repo),
+		PRNumber:   prNumber,
+	})
+	if err != nil {
+		w.logger.Error(err, "failed to apply comment template, posting plain comment", "repo", w.repositoryName(repo))
+		return comment
+	}
+	return rendered
+}
@@ -0,0 +1,66 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// SetPlaceholderDelay posts a "computing preview" comment for plans still running after delay
+// The comment is then edited with the results; 0 disables placeholders
+func (w *XRWatcher) SetPlaceholderDelay(delay time.Duration) {
+	w.placeholderDelay = delay
+}
+
+// startPlaceholder posts a placeholder comment if the plan is still running after the placeholder delay
+// The returned stop func must be called before the results are posted; it waits for
+// a placeholder post in flight so the placeholder can't overwrite the results,
+// and reports whether a placeholder was posted
+func (w *XRWatcher) startPlaceholder(ctx context.Context, repo string, prNumber, resourceCount int) (stop func() bool) {
+	if w.vcsClient == nil || w.placeholderDelay <= 0 || w.holdingComments() || previewFrom(ctx) != nil || w.placement == PlacementCheck {
+		return func() bool { return false }
+	}
+
+	var mu sync.Mutex
+	stopped, posted := false, false
+	timer := w.clock.AfterFunc(w.placeholderDelay, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if stopped {
+			return
+		}
+
+		vcsClient, err := w.vcsClientFor(repo)
+		if err != nil {
+			return
+		}
+		if _, err := vcsClient.PostCommentIfChanged(ctx, prNumber, w.formatter.FormatPlaceholder(resourceCount)); err != nil {
+			w.logger.Error(err, "failed to post placeholder comment", "prNumber", prNumber)
+			return
+		}
+		posted = true
+		w.logger.Info("Posted placeholder comment for long-running plan", "prNumber", prNumber, "resourceCount", resourceCount)
+	})
+
+	return func() bool {
+		timer.Stop()
+		mu.Lock()
+		defer mu.Unlock()
+		stopped = true
+		return posted
+	}
+}
+
+// deletePlaceholder removes a placeholder comment for a plan that produced no results
+func (w *XRWatcher) deletePlaceholder(ctx context.Context, repo string, prNumber int) error {
+	vcsClient, err := w.vcsClientFor(repo)
+	if err != nil {
+		return err
+	}
+	if err := vcsClient.DeleteComment(ctx, prNumber); err != nil {
+		return fmt.Errorf("failed to delete placeholder comment: %w", err)
+	}
+	w.forgetComment(ctx, repo, prNumber)
+	return nil
+}
@@ -0,0 +1,94 @@
+package watcher
+
+import (
+	"context"
+	"time"
+
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+	"github.com/millstonehq/crossplane-plan/pkg/store"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// readinessWindow is how recently an XR must have become Ready for its time-to-Ready to be sampled
+// Older transitions are replayed by relists and restarts, and may already be sampled
+const readinessWindow = time.Hour
+
+// SetConvergenceEstimate adds the estimated time to converge after merge to PR comments,
+// from the time-to-Ready of past XRs of the kinds a plan changes
+func (w *XRWatcher) SetConvergenceEstimate(enabled bool) {
+	w.convergenceEstimate = enabled
+}
+
+// observeReadiness samples the time an XR took from creation to Ready, once per XR
+// Only XRs that were never updated (generation 1) are sampled, so the time is that of a fresh apply
+func (w *XRWatcher) observeReadiness(ctx context.Context, xr *unstructured.Unstructured) {
+	if !w.convergenceEstimate || xr.GetGeneration() != 1 {
+		return
+	}
+	readyAt, ok := readyTransition(xr)
+	created := xr.GetCreationTimestamp().Time
+	if !ok || !readyAt.After(created) || w.clock.Since(readyAt) > readinessWindow {
+		return
+	}
+
+	w.readyMu.Lock()
+	if _, seen := w.readySeen[xr.GetUID()]; seen {
+		w.readyMu.Unlock()
+		return
+	}
+	for uid, at := range w.readySeen {
+		if w.clock.Since(at) > readinessWindow {
+			delete(w.readySeen, uid)
+		}
+	}
+	w.readySeen[xr.GetUID()] = readyAt
+	w.readyMu.Unlock()
+
+	kind := xr.GroupVersionKind().GroupKind().String()
+	sample := store.ReadinessSample{UID: string(xr.GetUID()), Seconds: readyAt.Sub(created).Seconds()}
+	if _, err := w.state.RecordReadiness(ctx, kind, sample); err != nil {
+		recordError("store", err)
+		w.logger.Error(err, "failed to record time-to-Ready", "kind", kind, "name", xr.GetName())
+	}
+}
+
+// readyTransition returns when an XR's Ready condition last became True
+func readyTransition(xr *unstructured.Unstructured) (time.Time, bool) {
+	conditions, _, _ := unstructured.NestedSlice(xr.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, _ := c.(map[string]interface{})
+		if condition["type"] != "Ready" || condition["status"] != "True" {
+			continue
+		}
+		transition, _ := condition["lastTransitionTime"].(string)
+		at, err := time.Parse(time.RFC3339, transition)
+		return at, err == nil
+	}
+	return time.Time{}, false
+}
+
+// convergenceTime estimates how long the XRs a plan creates or changes take to become Ready
+// They converge in parallel, so the estimate is that of the slowest kind; ok is false if no kind has one
+func (w *XRWatcher) convergenceTime(ctx context.Context, items []differ.PlanItem) (estimate time.Duration, slowestKind string, ok bool) {
+	estimated := make(map[string]bool)
+	for _, item := range items {
+		if !item.Changed() || item.XR == nil {
+			continue
+		}
+		kind := item.XR.GroupVersionKind().GroupKind().String()
+		if estimated[kind] {
+			continue
+		}
+		estimated[kind] = true
+
+		d, found, err := w.state.TimeToReady(ctx, kind)
+		if err != nil {
+			w.logger.Error(err, "failed to read time-to-Ready", "kind", kind)
+			continue
+		}
+		if found && (d > estimate || (d == estimate && kind < slowestKind)) {
+			estimate, slowestKind, ok = d, kind, true
+		}
+	}
+	return estimate, slowestKind, ok
+}
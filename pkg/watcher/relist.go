@@ -0,0 +1,120 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/millstonehq/crossplane-plan/pkg/argocd"
+	"github.com/millstonehq/crossplane-plan/pkg/workqueue"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// errWatchExpired is returned by a watch whose resourceVersion is too old to resume from
+var errWatchExpired = errors.New("watch resourceVersion expired")
+
+// watchHealthyAfter is how long a watch must run before its backoff is reset
+const watchHealthyAfter = 2 * time.Minute
+
+// newWatchBackoff returns the backoff between failed watches: 1s doubling up to 5m, with jitter
+func newWatchBackoff() wait.Backoff {
+	return wait.Backoff{
+		Duration: time.Second,
+		Factor:   2,
+		Jitter:   0.5,
+		Steps:    32,
+		Cap:      5 * time.Minute,
+	}
+}
+
+// runWatch runs watchOnce until ctx is done, like a reflector
+// Failures back off exponentially with jitter; an expired resourceVersion re-lists with relist
+// (which records a fresh bookmark) before watching again, instead of replaying every object
+func (w *XRWatcher) runWatch(ctx context.Context, name string, watchOnce, relist func(context.Context) error) {
+	backoff := newWatchBackoff()
+	for ctx.Err() == nil {
+		started := w.clock.Now()
+		err := watchOnce(ctx)
+		if err == nil || ctx.Err() != nil {
+			continue
+		}
+
+		if w.clock.Since(started) >= watchHealthyAfter {
+			backoff = newWatchBackoff()
+		}
+
+		if errors.Is(err, errWatchExpired) {
+			w.logger.Info("Watch resourceVersion expired, re-listing", "watch", name)
+			if err = relist(ctx); err == nil {
+				continue
+			}
+		}
+
+		delay := backoff.Step()
+		w.logger.Error(err, "watch failed, retrying", "watch", name, "delay", delay.Round(time.Millisecond).String())
+		select {
+		case <-ctx.Done():
+		case <-w.clock.After(delay):
+		}
+	}
+}
+
+// isExpired reports whether a watch error event means its resourceVersion is too old
+func isExpired(event watch.Event) bool {
+	err := apierrors.FromObject(event.Object)
+	return apierrors.IsResourceExpired(err) || apierrors.IsGone(err)
+}
+
+// relistGVR lists a GVR to get a fresh bookmark after its watch expired
+// PRs whose XRs changed meanwhile are enqueued, since the events in between were missed
+func (w *XRWatcher) relistGVR(ctx context.Context, gvr schema.GroupVersionResource) error {
+	prXRs := make(map[workqueue.PR][]*unstructured.Unstructured)
+	resourceVersion, err := w.listEach(ctx, gvr, func(xr *unstructured.Unstructured) {
+		pr, err := w.prOf(xr)
+		if pr.Number == 0 || err != nil {
+			return
+		}
+		prXRs[pr] = append(prXRs[pr], xr.DeepCopy())
+	})
+	if err != nil {
+		return fmt.Errorf("failed to re-list %s: %w", gvr.String(), err)
+	}
+
+	// Other GVRs may hold more of a PR's XRs, so only the listed ones are compared with the last plan
+	for pr, xrs := range prXRs {
+		versions := resourceVersions(xrs)
+		if !w.tracker.unchanged(pr, versions) {
+			w.tracker.markDirty(pr)
+			w.workQueue.Enqueue(ctx, pr)
+			for _, previous := range w.movedFrom(pr, versions) {
+				w.workQueue.Enqueue(ctx, previous)
+			}
+		}
+	}
+
+	w.tracker.setBookmark(gvr, resourceVersion)
+	w.logger.Info("Re-listed GVR", "gvr", gvr.String(), "prCount", len(prXRs))
+	return nil
+}
+
+// relistApplications lists PR applications to get a fresh bookmark after their watch expired
+// Applications whose revision or resources changed meanwhile are handled as modified
+func (w *XRWatcher) relistApplications(ctx context.Context) error {
+	list, err := w.dynamicClient.Resource(argocd.ApplicationGVR).Namespace(w.argocdClient.Namespace()).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to re-list applications: %w", err)
+	}
+
+	for i := range list.Items {
+		w.handleApplicationEvent(ctx, watch.Modified, &list.Items[i])
+	}
+
+	w.tracker.setBookmark(argocd.ApplicationGVR, list.GetResourceVersion())
+	return nil
+}
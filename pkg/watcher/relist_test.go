@@ -0,0 +1,175 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+// watchRun runs runWatch on a fake clock; each watch attempt is reported on attempts
+// and returns the error its script gives it
+type watchRun struct {
+	clk      *clocktesting.FakeClock
+	attempts chan int
+	relists  chan struct{}
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// startWatch runs runWatch until the test ends; watchOnce(n) is the result of the nth attempt
+// (from 1) and relistErr the result of every relist
+func startWatch(t *testing.T, watchOnce func(clk *clocktesting.FakeClock, n int) error, relistErr error) *watchRun {
+	t.Helper()
+
+	clk := clocktesting.NewFakeClock(time.Now())
+	w := newTestWatcher(t, clk, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	run := &watchRun{
+		clk:      clk,
+		attempts: make(chan int, 10),
+		relists:  make(chan struct{}, 10),
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+
+	n := 0
+	go func() {
+		defer close(run.done)
+		w.runWatch(ctx, "test", func(ctx context.Context) error {
+			n++
+			run.attempts <- n
+			return watchOnce(clk, n)
+		}, func(ctx context.Context) error {
+			run.relists <- struct{}{}
+			return relistErr
+		})
+	}()
+
+	t.Cleanup(func() {
+		cancel()
+		select {
+		case <-run.done:
+		case <-time.After(5 * time.Second):
+			t.Error("runWatch did not return after its context was cancelled")
+		}
+	})
+	return run
+}
+
+// expectAttempt waits for the nth watch attempt
+func (r *watchRun) expectAttempt(t *testing.T, n int) {
+	t.Helper()
+	select {
+	case got := <-r.attempts:
+		if got != n {
+			t.Fatalf("watch attempt %d, want %d", got, n)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for watch attempt %d", n)
+	}
+}
+
+// expectNoAttempt checks that the watch isn't retried yet
+func (r *watchRun) expectNoAttempt(t *testing.T) {
+	t.Helper()
+	select {
+	case got := <-r.attempts:
+		t.Fatalf("watch attempt %d started before its backoff elapsed", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// waitBackoff waits until the watch is backing off on the clock
+func (r *watchRun) waitBackoff(t *testing.T) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !r.clk.HasWaiters() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the watch to back off")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRunWatch_BacksOffExponentially(t *testing.T) {
+	run := startWatch(t, func(*clocktesting.FakeClock, int) error {
+		return errors.New("connection refused")
+	}, nil)
+
+	// The first retry waits 1s plus up to 50% jitter, the second 2s plus jitter
+	run.expectAttempt(t, 1)
+	run.waitBackoff(t)
+	run.clk.Step(999 * time.Millisecond)
+	run.expectNoAttempt(t)
+	run.clk.Step(501 * time.Millisecond)
+	run.expectAttempt(t, 2)
+
+	run.waitBackoff(t)
+	run.clk.Step(1999 * time.Millisecond)
+	run.expectNoAttempt(t)
+	run.clk.Step(1001 * time.Millisecond)
+	run.expectAttempt(t, 3)
+}
+
+func TestRunWatch_ResetsBackoffAfterHealthyWatch(t *testing.T) {
+	run := startWatch(t, func(clk *clocktesting.FakeClock, n int) error {
+		if n == 3 {
+			// The third watch runs long enough to count as healthy before it fails
+			clk.Step(watchHealthyAfter)
+		}
+		return errors.New("connection reset")
+	}, nil)
+
+	run.expectAttempt(t, 1)
+	run.waitBackoff(t)
+	run.clk.Step(1500 * time.Millisecond)
+	run.expectAttempt(t, 2)
+	run.waitBackoff(t)
+	run.clk.Step(3 * time.Second)
+	run.expectAttempt(t, 3)
+
+	// Back to the initial 1s plus jitter instead of 4s
+	run.waitBackoff(t)
+	run.clk.Step(1500 * time.Millisecond)
+	run.expectAttempt(t, 4)
+}
+
+func TestRunWatch_RelistsExpiredWatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		relistErr error
+		backoff   bool
+	}{
+		{name: "relist succeeds", backoff: false},
+		{name: "relist fails", relistErr: errors.New("forbidden"), backoff: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := startWatch(t, func(_ *clocktesting.FakeClock, n int) error {
+				if n == 1 {
+					return errWatchExpired
+				}
+				return errors.New("connection refused")
+			}, tt.relistErr)
+
+			run.expectAttempt(t, 1)
+			select {
+			case <-run.relists:
+			case <-time.After(5 * time.Second):
+				t.Fatal("expired watch was not re-listed")
+			}
+
+			if tt.backoff {
+				run.waitBackoff(t)
+				run.expectNoAttempt(t)
+				run.clk.Step(1500 * time.Millisecond)
+			}
+			// A successful relist watches again from its bookmark right away
+			run.expectAttempt(t, 2)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/millstonehq/crossplane-plan/pkg/admin"
+	"github.com/millstonehq/crossplane-plan/pkg/workqueue"
+)
+
+// SetPreviewRemovedAction sets what happens to the comment of a PR whose preview resources were all deleted:
+// admin.CleanupStale (mark it stale), admin.CleanupDelete (delete it), or "" (leave it)
+func (w *XRWatcher) SetPreviewRemovedAction(action string) {
+	w.previewRemovedAction = action
+}
+
+// handlePreviewRemoved updates the comments of a PR whose preview resources no longer target its repository
+// PRs that never had any there (as far as this replica knows) are left alone; the cleanup subcommand covers those
+func (w *XRWatcher) handlePreviewRemoved(ctx context.Context, pr workqueue.PR) error {
+	if !w.tracker.forgetPR(pr) {
+		w.logger.Info("No resources found for PR", "pr", pr)
+		return nil
+	}
+	w.forgetPlannedVersions(ctx, pr)
+
+	if w.vcsClient == nil || w.previewRemovedAction == "" {
+		w.logger.Info("Preview removed, leaving comment", "pr", pr)
+		return nil
+	}
+	if err := w.removePreview(ctx, pr.Repo, pr.Number); err != nil {
+		recordError("vcs", err)
+		w.tracker.markRemoved(pr)
+		return fmt.Errorf("failed to update comment of removed preview: %w", err)
+	}
+	w.forgetComment(ctx, pr.Repo, pr.Number)
+	w.logger.Info("Preview removed, updated comment", "pr", pr, "repo", w.repositoryName(pr.Repo), "action", w.previewRemovedAction)
+	return nil
+}
+
+// removePreview updates the comment of a PR in one repository, then its component comments
+// (see SetComponentComments)
+func (w *XRWatcher) removePreview(ctx context.Context, repo string, prNumber int) error {
+	for _, component := range append([]string{""}, w.postedComponents(ctx, repo, prNumber)...) {
+		client, err := w.componentClientFor(repo, component)
+		if err != nil {
+			return err
+		}
+		switch w.previewRemovedAction {
+		case admin.CleanupDelete:
+			err = client.DeleteComment(ctx, prNumber)
+		case admin.CleanupStale:
+			_, err = client.MarkCommentStale(ctx, prNumber, "the preview resources of this PR were deleted")
+		default:
+			err = fmt.Errorf("unknown preview removed action %q", w.previewRemovedAction)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -3,7 +3,9 @@ package watcher
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"github.com/millstonehq/crossplane-plan/pkg/argocd"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -21,29 +23,62 @@ type Scope struct {
 	ProdAppName string // ArgoCD Application name for production (e.g., "myapp")
 }
 
-// DiscoverScope extracts ArgoCD application information from XR labels
-func (w *XRWatcher) DiscoverScope(xr *unstructured.Unstructured) (*Scope, error) {
-	labels := xr.GetLabels()
-	if labels == nil {
-		return nil, fmt.Errorf(
-			"XR %s has no labels. crossplane-plan requires ArgoCD to manage your resources. "+
-				"See: https://github.com/millstonehq/crossplane-plan#argocd-setup",
-			xr.GetName())
+// DiscoverScope finds the ArgoCD applications an XR belongs to
+// The instance label and tracking-id annotation are consulted in the order of ArgoCD's tracking
+// method; without either, Applications are searched for one listing the XR in status.resources
+func (w *XRWatcher) DiscoverScope(ctx context.Context, xr *unstructured.Unstructured) (*Scope, error) {
+	method := w.argocdClient.TrackingMethod()
+
+	if method == argocd.TrackingLabel {
+		if appName, ok := xr.GetLabels()[ArgoCDInstanceLabel]; ok {
+			return w.scopeForApp(ctx, appName)
+		}
+	}
+	if appName, ok := w.trackedApp(xr); ok {
+		return w.scopeForApp(ctx, appName)
+	}
+	// With annotation+label tracking the label is informational and may be truncated
+	if method == argocd.TrackingAnnotationAndLabel {
+		if appName, ok := xr.GetLabels()[ArgoCDInstanceLabel]; ok {
+			return w.scopeForApp(ctx, appName)
+		}
+	}
+
+	appName, err := w.findOwningApp(ctx, xr)
+	if err != nil {
+		return nil, err
 	}
+	w.logger.V(1).Info("Found owning application by search", "xr", xr.GetName(), "app", appName)
+	return w.scopeForApp(ctx, appName)
+}
 
-	// ArgoCD automatically adds this label
-	appName, ok := labels[ArgoCDInstanceLabel]
+// trackedApp returns the application named by an XR's tracking-id annotation
+// Invalid ids and ids copied from another resource are ignored
+func (w *XRWatcher) trackedApp(xr *unstructured.Unstructured) (string, bool) {
+	value, ok := xr.GetAnnotations()[argocd.TrackingIDAnnotation]
 	if !ok {
-		return nil, fmt.Errorf(
-			"XR %s is not managed by ArgoCD (missing %s label). "+
-				"crossplane-plan requires ArgoCD. "+
-				"See: https://github.com/millstonehq/crossplane-plan#argocd-setup",
-			xr.GetName(),
-			ArgoCDInstanceLabel)
+		return "", false
 	}
 
-	// Get production app name by stripping PR prefix
-	prodAppName := w.argocdClient.GetProductionAppName(appName)
+	id, err := argocd.ParseTrackingID(value)
+	if err != nil {
+		w.logger.Info("Ignoring invalid tracking id", "xr", xr.GetName(), "error", err.Error())
+		return "", false
+	}
+	if !id.Matches(xr) {
+		w.logger.Info("Ignoring tracking id of another resource", "xr", xr.GetName(), "trackingID", value)
+		return "", false
+	}
+	return id.AppName, true
+}
+
+// scopeForApp builds the scope of a PR application
+// The production app is named after it without the PR prefix, in the same AppProject
+func (w *XRWatcher) scopeForApp(ctx context.Context, appName string) (*Scope, error) {
+	prodAppName, err := w.argocdClient.ProductionApp(ctx, appName)
+	if err != nil {
+		return nil, err
+	}
 
 	return &Scope{
 		Type:        "argocd",
@@ -52,11 +87,53 @@ func (w *XRWatcher) DiscoverScope(xr *unstructured.Unstructured) (*Scope, error)
 	}, nil
 }
 
+// findOwningApp searches Applications for the one managing an XR
+// When several list it, the single PR application among them wins
+func (w *XRWatcher) findOwningApp(ctx context.Context, xr *unstructured.Unstructured) (string, error) {
+	apps, err := w.argocdClient.FindApplicationsForResource(ctx, xr)
+	if err != nil {
+		return "", fmt.Errorf("failed to search applications for XR %s: %w", xr.GetName(), err)
+	}
+
+	switch len(apps) {
+	case 0:
+		return "", fmt.Errorf(
+			"XR %s is not managed by ArgoCD (no %s label or %s annotation and no Application lists it; tracking method %s). "+
+				"crossplane-plan requires ArgoCD. "+
+				"See: https://github.com/millstonehq/crossplane-plan#argocd-setup",
+			xr.GetName(),
+			ArgoCDInstanceLabel,
+			argocd.TrackingIDAnnotation,
+			w.argocdClient.TrackingMethod())
+	case 1:
+		return apps[0], nil
+	}
+
+	var prApps []string
+	for _, app := range apps {
+		if w.argocdClient.GetProductionAppName(app) != app {
+			prApps = append(prApps, app)
+		}
+	}
+	if len(prApps) != 1 {
+		return "", fmt.Errorf("XR %s is listed by several applications: %s", xr.GetName(), strings.Join(apps, ", "))
+	}
+	return prApps[0], nil
+}
+
 // ListScopedProductionResources lists all XRs that belong to the production application
+// With annotation tracking the instance label isn't authoritative, so XRs are matched by tracking id
 func (w *XRWatcher) ListScopedProductionResources(ctx context.Context, scope *Scope, gvr schema.GroupVersionResource) ([]*unstructured.Unstructured, error) {
+	if scope.ProdAppName == "" {
+		// No production app is paired with the PR app
+		return nil, nil
+	}
+	byLabel := w.argocdClient.TrackingMethod() == argocd.TrackingLabel
+
 	// List all resources of this GVR with the production app label
-	listOptions := metav1.ListOptions{
-		LabelSelector: fmt.Sprintf("%s=%s", ArgoCDInstanceLabel, scope.ProdAppName),
+	listOptions := metav1.ListOptions{}
+	if byLabel {
+		listOptions.LabelSelector = fmt.Sprintf("%s=%s", ArgoCDInstanceLabel, scope.ProdAppName)
 	}
 
 	list, err := w.dynamicClient.Resource(gvr).List(ctx, listOptions)
@@ -64,9 +141,14 @@ func (w *XRWatcher) ListScopedProductionResources(ctx context.Context, scope *Sc
 		return nil, fmt.Errorf("failed to list scoped resources for app %s: %w", scope.ProdAppName, err)
 	}
 
-	result := make([]*unstructured.Unstructured, len(list.Items))
+	result := make([]*unstructured.Unstructured, 0, len(list.Items))
 	for i := range list.Items {
-		result[i] = &list.Items[i]
+		if !byLabel {
+			if appName, ok := w.trackedApp(&list.Items[i]); !ok || appName != scope.ProdAppName {
+				continue
+			}
+		}
+		result = append(result, &list.Items[i])
 	}
 
 	return result, nil
@@ -0,0 +1,188 @@
+package watcher
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"github.com/millstonehq/crossplane-plan/pkg/config"
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+	"github.com/millstonehq/crossplane-plan/pkg/vcs"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// changedFiles returns the files a PR changes, or nil without GitHub access
+// A failure is logged, and the plan goes on without source links
+func (w *XRWatcher) changedFiles(ctx context.Context, repo string, prNumber int) []vcs.ChangedFile {
+	if w.vcsClient == nil {
+		return nil
+	}
+	vcsClient, err := w.vcsClientFor(repo)
+	if err != nil {
+		w.logger.Error(err, "failed to list changed files", "prNumber", prNumber)
+		return nil
+	}
+	files, err := vcsClient.ListChangedFiles(ctx, prNumber)
+	if err != nil {
+		recordError("vcs", err)
+		w.logger.Error(err, "failed to list changed files, omitting source links", "prNumber", prNumber)
+		return nil
+	}
+	return files
+}
+
+// SourcePathAnnotation names the repository file declaring an XR's manifest
+// A changed file at that path is the XR's source, whatever the source mappings say
+const SourcePathAnnotation = "millstone.tech/source-path"
+
+// sourcesFor returns the changed files likely declaring a PR XR
+// The file named by the source path annotation is used if the PR changes it; otherwise configured
+// source mappings for the XR's kind decide alone, and without one, files whose patch touches a
+// manifest named like the XR are used
+func (w *XRWatcher) sourcesFor(xr *unstructured.Unstructured, baseName string, files []vcs.ChangedFile) []differ.Link {
+	if len(files) == 0 {
+		return nil
+	}
+
+	if sourcePath := strings.TrimPrefix(xr.GetAnnotations()[SourcePathAnnotation], "/"); sourcePath != "" {
+		for _, file := range files {
+			if file.Path == sourcePath && file.Status != "removed" {
+				return []differ.Link{sourceLink(file, xr, baseName)}
+			}
+		}
+	}
+
+	mappings := w.sourceMappingsFor(xr.GetKind())
+	data := config.SourceData{Kind: xr.GetKind(), Name: baseName, Namespace: xr.GetNamespace()}
+	var sources []differ.Link
+	for _, file := range files {
+		if file.Status == "removed" {
+			continue
+		}
+		if len(mappings) > 0 {
+			if !w.matchesSourceMappings(mappings, file.Path, data) {
+				continue
+			}
+		} else if !file.Mentions(xr.GetName()) && !file.Mentions(baseName) {
+			continue
+		}
+		sources = append(sources, sourceLink(file, xr, baseName))
+	}
+	return sources
+}
+
+// sourceLink links to a changed file declaring an XR, at the line of the patch naming it if any
+func sourceLink(file vcs.ChangedFile, xr *unstructured.Unstructured, baseName string) differ.Link {
+	line := file.Line(baseName)
+	if line == 0 {
+		line = file.Line(xr.GetName())
+	}
+	return differ.Link{Name: file.Path, URL: file.URL, Line: line}
+}
+
+// matchesSourceMappings reports whether a file is a source of an XR according to any mapping
+func (w *XRWatcher) matchesSourceMappings(mappings []config.SourceMapping, file string, data config.SourceData) bool {
+	for _, mapping := range mappings {
+		matched, err := mapping.Matches(file, data)
+		if err != nil {
+			w.logger.Error(err, "invalid source mapping", "kind", data.Kind)
+			continue
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// sourceMappingsFor returns the configured source mappings applying to a kind
+func (w *XRWatcher) sourceMappingsFor(kind string) []config.SourceMapping {
+	w.settingsMu.RLock()
+	defer w.settingsMu.RUnlock()
+
+	var mappings []config.SourceMapping
+	if w.appConfig != nil {
+		for _, mapping := range w.appConfig.Sources {
+			if mapping.AppliesTo(kind) {
+				mappings = append(mappings, mapping)
+			}
+		}
+	}
+	return mappings
+}
+
+// SetOnlyChangedSources skips planning the XRs whose source files a PR doesn't change
+// Sources come from the source mappings of the XR's kind, or else the paths of the PR's ArgoCD Application
+func (w *XRWatcher) SetOnlyChangedSources(enabled bool) {
+	w.onlyChangedSources = enabled
+}
+
+// filterUnchangedSources splits a PR's XRs into those whose sources the PR changes and the names of the others
+// XRs whose sources can't be told are kept, as are all XRs when the changed files are unknown
+func (w *XRWatcher) filterUnchangedSources(ctx context.Context, xrs []*unstructured.Unstructured, scope *Scope, files []vcs.ChangedFile) ([]*unstructured.Unstructured, []string) {
+	if !w.onlyChangedSources || len(files) == 0 {
+		return xrs, nil
+	}
+
+	var appPaths []string
+	if w.argocdClient != nil && scope != nil {
+		paths, err := w.argocdClient.SourcePaths(ctx, scope.PRAppName)
+		if err != nil {
+			w.logger.Error(err, "failed to read application source paths", "app", scope.PRAppName)
+		}
+		appPaths = paths
+	}
+
+	var changed []*unstructured.Unstructured
+	var unchanged []string
+	for _, xr := range xrs {
+		baseName := w.currentDetector().GetBaseName(xr)
+		mappings := w.sourceMappingsFor(xr.GetKind())
+		data := config.SourceData{Kind: xr.GetKind(), Name: baseName, Namespace: xr.GetNamespace()}
+
+		var touched bool
+		switch {
+		case len(mappings) > 0:
+			touched = anyFileMatches(files, func(file string) bool {
+				return w.matchesSourceMappings(mappings, file, data)
+			})
+		case len(appPaths) > 0:
+			touched = anyFileMatches(files, func(file string) bool {
+				return underAnyPath(file, appPaths)
+			})
+		default:
+			touched = true
+		}
+
+		if touched {
+			changed = append(changed, xr)
+		} else {
+			unchanged = append(unchanged, xr.GetName())
+		}
+	}
+	if len(unchanged) > 0 {
+		w.logger.V(1).Info("Skipping XRs whose sources the PR doesn't change", "count", len(unchanged))
+	}
+	return changed, unchanged
+}
+
+// anyFileMatches reports whether match accepts the current or previous path of any changed file
+func anyFileMatches(files []vcs.ChangedFile, match func(string) bool) bool {
+	for _, file := range files {
+		if match(file.Path) || (file.PreviousPath != "" && match(file.PreviousPath)) {
+			return true
+		}
+	}
+	return false
+}
+
+// underAnyPath reports whether a file lies in one of the directories
+func underAnyPath(file string, dirs []string) bool {
+	for _, dir := range dirs {
+		dir = strings.Trim(path.Clean(dir), "/")
+		if dir == "." || dir == "" || file == dir || strings.HasPrefix(file, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,198 @@
+package watcher
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/millstonehq/crossplane-plan/pkg/config"
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+	"github.com/millstonehq/crossplane-plan/pkg/vcs"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestXRWatcher_sourcesFor(t *testing.T) {
+	bucketPatch := "@@ -0,0 +4,3 @@\n+kind: XBucket\n+metadata:\n+  name: data\n"
+	files := []vcs.ChangedFile{
+		{Path: "buckets/data.yaml", Status: "modified", Patch: bucketPatch, URL: "https://example.com/1"},
+		{Path: "buckets/logs.yaml", Status: "added", Patch: "@@ -0,0 +1,2 @@\n+metadata:\n+  name: logs\n", URL: "https://example.com/2"},
+		{Path: "old/data.yaml", Status: "removed", Patch: "@@ -1,2 +0,0 @@\n-metadata:\n-  name: data\n", URL: "https://example.com/3"},
+		{Path: "teams/team/data.yaml", Status: "modified", URL: "https://example.com/4"},
+	}
+
+	tests := []struct {
+		name     string
+		xr       *unstructured.Unstructured
+		mappings []config.SourceMapping
+		files    []vcs.ChangedFile
+		want     []differ.Link
+	}{
+		{name: "no changed files", xr: newXR("XBucket", "team", "pr-1-data", nil)},
+		{
+			name:  "patch naming the XR",
+			xr:    newXR("XBucket", "team", "pr-1-data", nil),
+			files: files,
+			want:  []differ.Link{{Name: "buckets/data.yaml", URL: "https://example.com/1", Line: 6}},
+		},
+		{
+			name:  "source path annotation",
+			xr:    newXR("XBucket", "team", "pr-1-data", map[string]string{SourcePathAnnotation: "/teams/team/data.yaml"}),
+			files: files,
+			want:  []differ.Link{{Name: "teams/team/data.yaml", URL: "https://example.com/4"}},
+		},
+		{
+			name:  "source path the PR removes",
+			xr:    newXR("XBucket", "team", "pr-1-data", map[string]string{SourcePathAnnotation: "old/data.yaml"}),
+			files: files,
+			want:  []differ.Link{{Name: "buckets/data.yaml", URL: "https://example.com/1", Line: 6}},
+		},
+		{
+			name:     "source mapping",
+			xr:       newXR("XBucket", "team", "pr-1-data", nil),
+			mappings: []config.SourceMapping{{Kinds: []string{"XBucket"}, Paths: []string{"teams/{{.Namespace}}/{{.Name}}.yaml"}}},
+			files:    files,
+			want:     []differ.Link{{Name: "teams/team/data.yaml", URL: "https://example.com/4"}},
+		},
+		{
+			name:     "source mapping of another kind",
+			xr:       newXR("XBucket", "team", "pr-1-data", nil),
+			mappings: []config.SourceMapping{{Kinds: []string{"XQueue"}, Paths: []string{"teams/{{.Namespace}}/{{.Name}}.yaml"}}},
+			files:    files,
+			want:     []differ.Link{{Name: "buckets/data.yaml", URL: "https://example.com/1", Line: 6}},
+		},
+		{
+			name:  "unrelated files",
+			xr:    newXR("XBucket", "team", "pr-1-jobs", nil),
+			files: files,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWatcher(t, clocktesting.NewFakeClock(time.Now()), nil)
+			w.appConfig = &config.Config{Sources: tt.mappings}
+
+			got := w.sourcesFor(tt.xr, w.currentDetector().GetBaseName(tt.xr), tt.files)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sourcesFor() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestXRWatcher_filterUnchangedSources(t *testing.T) {
+	xrs := []*unstructured.Unstructured{
+		newXR("XBucket", "team", "pr-1-data", nil),
+		newXR("XQueue", "team", "pr-1-jobs", nil),
+	}
+	app := newApp("pr-1-web", "abc")
+	app.Object["spec"] = map[string]interface{}{"source": map[string]interface{}{"path": "queues/"}}
+
+	tests := []struct {
+		name          string
+		enabled       bool
+		mappings      []config.SourceMapping
+		scope         *Scope
+		files         []vcs.ChangedFile
+		wantChanged   []string
+		wantUnchanged []string
+	}{
+		{
+			name:        "disabled",
+			files:       []vcs.ChangedFile{{Path: "README.md"}},
+			wantChanged: []string{"pr-1-data", "pr-1-jobs"},
+		},
+		{
+			name:        "changed files unknown",
+			enabled:     true,
+			mappings:    []config.SourceMapping{{Paths: []string{"{{.Name}}.yaml"}}},
+			wantChanged: []string{"pr-1-data", "pr-1-jobs"},
+		},
+		{
+			name:          "source mappings",
+			enabled:       true,
+			mappings:      []config.SourceMapping{{Paths: []string{"{{.Name}}.yaml"}}},
+			files:         []vcs.ChangedFile{{Path: "data.yaml"}},
+			wantChanged:   []string{"pr-1-data"},
+			wantUnchanged: []string{"pr-1-jobs"},
+		},
+		{
+			name:          "renamed source",
+			enabled:       true,
+			mappings:      []config.SourceMapping{{Paths: []string{"{{.Name}}.yaml"}}},
+			files:         []vcs.ChangedFile{{Path: "archive/jobs.yaml", PreviousPath: "jobs.yaml", Status: "renamed"}},
+			wantChanged:   []string{"pr-1-jobs"},
+			wantUnchanged: []string{"pr-1-data"},
+		},
+		{
+			name:          "application paths",
+			enabled:       true,
+			scope:         &Scope{PRAppName: "pr-1-web"},
+			files:         []vcs.ChangedFile{{Path: "README.md"}},
+			wantUnchanged: []string{"pr-1-data", "pr-1-jobs"},
+		},
+		{
+			name:        "file under the application path",
+			enabled:     true,
+			scope:       &Scope{PRAppName: "pr-1-web"},
+			files:       []vcs.ChangedFile{{Path: "queues/jobs.yaml"}},
+			wantChanged: []string{"pr-1-data", "pr-1-jobs"},
+		},
+		{
+			name:          "source mappings before application paths",
+			enabled:       true,
+			mappings:      []config.SourceMapping{{Kinds: []string{"XBucket"}, Paths: []string{"buckets/{{.Name}}.yaml"}}},
+			scope:         &Scope{PRAppName: "pr-1-web"},
+			files:         []vcs.ChangedFile{{Path: "queues/jobs.yaml"}},
+			wantChanged:   []string{"pr-1-jobs"},
+			wantUnchanged: []string{"pr-1-data"},
+		},
+		{
+			name:        "sources can't be told",
+			enabled:     true,
+			files:       []vcs.ChangedFile{{Path: "README.md"}},
+			wantChanged: []string{"pr-1-data", "pr-1-jobs"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newAppWatcher(t, app)
+			w.appConfig = &config.Config{Sources: tt.mappings}
+			w.SetOnlyChangedSources(tt.enabled)
+
+			changed, unchanged := w.filterUnchangedSources(context.Background(), xrs, tt.scope, tt.files)
+			var changedNames []string
+			for _, xr := range changed {
+				changedNames = append(changedNames, xr.GetName())
+			}
+			if !reflect.DeepEqual(changedNames, tt.wantChanged) || !reflect.DeepEqual(unchanged, tt.wantUnchanged) {
+				t.Errorf("filterUnchangedSources() = %v, %v, want %v, %v", changedNames, unchanged, tt.wantChanged, tt.wantUnchanged)
+			}
+		})
+	}
+}
+
+func TestUnderAnyPath(t *testing.T) {
+	tests := []struct {
+		file string
+		dirs []string
+		want bool
+	}{
+		{file: "queues/jobs.yaml", dirs: []string{"queues"}, want: true},
+		{file: "queues/jobs.yaml", dirs: []string{"/queues/"}, want: true},
+		{file: "queues/team/jobs.yaml", dirs: []string{"buckets", "queues"}, want: true},
+		{file: "queues-old/jobs.yaml", dirs: []string{"queues"}},
+		{file: "jobs.yaml", dirs: []string{"."}, want: true},
+		{file: "queues", dirs: []string{"queues"}, want: true},
+		{file: "buckets/data.yaml"},
+	}
+
+	for _, tt := range tests {
+		if got := underAnyPath(tt.file, tt.dirs); got != tt.want {
+			t.Errorf("underAnyPath(%q, %v) = %v, want %v", tt.file, tt.dirs, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/millstonehq/crossplane-plan/pkg/differ"
+	"github.com/millstonehq/crossplane-plan/pkg/metrics"
+	"github.com/millstonehq/crossplane-plan/pkg/store"
+	"github.com/millstonehq/crossplane-plan/pkg/workqueue"
+)
+
+// stateSaveTimeout bounds saving the retry state after shutdown, when the watcher's context is cancelled
+const stateSaveTimeout = 5 * time.Second
+
+// SetStateStore keeps comment hashes, plan history and retry state in s instead of memory,
+// so they survive restarts and leader failover
+func (w *XRWatcher) SetStateStore(s store.Store) {
+	w.state = store.NewState(s)
+}
+
+// commentUnchanged reports whether comment is the last comment posted to a PR (or to one of its components)
+// Lets unchanged plans skip the GitHub API entirely; store errors count as changed
+func (w *XRWatcher) commentUnchanged(ctx context.Context, repo string, prNumber int, component, comment string) bool {
+	var hash string
+	var err error
+	if component != "" {
+		var hashes map[string]string
+		hashes, err = w.state.ComponentCommentHashes(ctx, w.repositoryName(repo), prNumber)
+		hash = hashes[component]
+	} else {
+		hash, err = w.state.CommentHash(ctx, w.repositoryName(repo), prNumber)
+	}
+	if err != nil {
+		recordError("store", err)
+		w.logger.Error(err, "failed to read comment hash", "prNumber", prNumber)
+		return false
+	}
+	return hash == store.HashComment(comment)
+}
+
+// recordComment records the comment posted to a PR (or to one of its components)
+func (w *XRWatcher) recordComment(ctx context.Context, repo string, prNumber int, component, comment string) {
+	var err error
+	if component != "" {
+		err = w.state.SetComponentCommentHash(ctx, w.repositoryName(repo), prNumber, component, store.HashComment(comment))
+	} else {
+		err = w.state.SetCommentHash(ctx, w.repositoryName(repo), prNumber, store.HashComment(comment))
+	}
+	if err != nil {
+		recordError("store", err)
+		w.logger.Error(err, "failed to record comment hash", "prNumber", prNumber)
+	}
+}
+
+// forgetComment drops the comment hash of a PR whose comment was replaced, marked stale or deleted
+func (w *XRWatcher) forgetComment(ctx context.Context, repo string, prNumber int) {
+	if err := w.state.ForgetComment(ctx, w.repositoryName(repo), prNumber); err != nil {
+		recordError("store", err)
+		w.logger.Error(err, "failed to forget comment hash", "prNumber", prNumber)
+	}
+}
+
+// recordPlan appends a plan to a PR's history
+func (w *XRWatcher) recordPlan(ctx context.Context, repo string, prNumber int, record store.PlanRecord) {
+	record.Time = w.clock.Now()
+	metrics.Plans.Inc()
+	if err := w.state.RecordPlan(ctx, w.repositoryName(repo), prNumber, record); err != nil {
+		recordError("store", err)
+		w.logger.Error(err, "failed to record plan history", "prNumber", prNumber)
+	}
+}
+
+// countChanged counts the items that create, update or delete resources
+func countChanged(items []differ.PlanItem) int {
+	changed := 0
+	for _, item := range items {
+		if item.Changed() {
+			changed++
+		}
+	}
+	return changed
+}
+
+// restorePlannedVersions loads the resourceVersions a previous leader planned PRs with, so PRs
+// whose XRs are unchanged since aren't replanned on startup
+func (w *XRWatcher) restorePlannedVersions(ctx context.Context) {
+	stored, err := w.state.PlannedVersions(ctx)
+	if err != nil {
+		recordError("store", err)
+		w.logger.Error(err, "failed to restore planned resourceVersions")
+		return
+	}
+	planned := make(map[workqueue.PR]map[string]string, len(stored))
+	for pr, versions := range stored {
+		planned[w.prKey(pr.Repository, pr.PRNumber)] = versions
+	}
+	w.tracker.restorePlanned(planned)
+	if len(planned) > 0 {
+		w.logger.Info("Restored planned resourceVersions", "prCount", len(planned))
+	}
+}
+
+// savePlannedVersions records the resourceVersions of the XRs a PR was planned with
+func (w *XRWatcher) savePlannedVersions(ctx context.Context, pr workqueue.PR, versions map[string]string) {
+	if err := w.state.SetPlannedVersions(ctx, w.repositoryName(pr.Repo), pr.Number, versions); err != nil {
+		recordError("store", err)
+		w.logger.Error(err, "failed to record planned resourceVersions", "pr", pr)
+	}
+}
+
+// forgetPlannedVersions drops the planned resourceVersions of a PR without XRs
+func (w *XRWatcher) forgetPlannedVersions(ctx context.Context, pr workqueue.PR) {
+	if err := w.state.ForgetPlannedVersions(ctx, w.repositoryName(pr.Repo), pr.Number); err != nil {
+		recordError("store", err)
+		w.logger.Error(err, "failed to forget planned resourceVersions", "pr", pr)
+	}
+}
+
+// restoreRetryState flags the PRs a previous leader was waiting to replan
+func (w *XRWatcher) restoreRetryState(ctx context.Context) {
+	prs, err := w.state.RetryPRs(ctx)
+	if err != nil {
+		recordError("store", err)
+		w.logger.Error(err, "failed to restore retry state")
+		return
+	}
+	for _, pr := range prs {
+		w.tracker.markDirty(w.prKey(pr.Repository, pr.PRNumber))
+	}
+	if len(prs) > 0 {
+		w.logger.Info("Restored PRs waiting to be replanned", "count", len(prs))
+	}
+	w.saveRetryState(ctx)
+}
+
+// saveRetryState records the PRs waiting to be replanned, if they changed since the last save
+func (w *XRWatcher) saveRetryState(ctx context.Context) {
+	dirty := w.tracker.dirtyPRs()
+	sort.Slice(dirty, func(i, j int) bool {
+		return dirty[i].Less(dirty[j])
+	})
+	prs := make([]store.PlannedPR, 0, len(dirty))
+	for _, pr := range dirty {
+		prs = append(prs, store.PlannedPR{Repository: w.repositoryName(pr.Repo), PRNumber: pr.Number})
+	}
+	snapshot := fmt.Sprint(prs)
+
+	w.stateMu.Lock()
+	defer w.stateMu.Unlock()
+	if snapshot == w.savedRetry {
+		return
+	}
+	if err := w.state.SetRetryPRs(ctx, prs); err != nil {
+		recordError("store", err)
+		w.logger.Error(err, "failed to save retry state")
+		return
+	}
+	w.savedRetry = snapshot
+}
@@ -0,0 +1,14 @@
+package watcher
+
+import "github.com/millstonehq/crossplane-plan/pkg/workqueue"
+
+// IsLeader reports whether this replica holds the leader lease and processes PRs
+func (w *XRWatcher) IsLeader() bool {
+	return w.leading.Load()
+}
+
+// QueueSnapshot returns the PRs waiting for, undergoing, or failing processing
+// Only the leader's queue has entries
+func (w *XRWatcher) QueueSnapshot() []workqueue.WorkItem {
+	return w.workQueue.Snapshot()
+}
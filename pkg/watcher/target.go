@@ -0,0 +1,125 @@
+package watcher
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/millstonehq/crossplane-plan/pkg/detector"
+	"github.com/millstonehq/crossplane-plan/pkg/workqueue"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// TargetRepoAnnotation overrides the repository a PR XR's plan is posted to
+	TargetRepoAnnotation = "millstone.tech/target-repo"
+)
+
+// SetAllowedTargetRepos configures which repositories XRs may target via TargetRepoAnnotation
+// Entries are "owner/repo" or glob patterns such as "owner/*"
+func (w *XRWatcher) SetAllowedTargetRepos(allowed []string) {
+	w.settingsMu.Lock()
+	defer w.settingsMu.Unlock()
+	w.allowedTargetRepos = allowed
+}
+
+// resolveTargetRepo returns the repository an XR's plan should be posted to
+// An empty string means the default repository
+func (w *XRWatcher) resolveTargetRepo(xr *unstructured.Unstructured) (string, error) {
+	repo, ok := xr.GetAnnotations()[TargetRepoAnnotation]
+	if !ok || repo == "" {
+		return w.routedRepo(xr), nil
+	}
+
+	if w.vcsClient != nil && repo == w.vcsClient.Repository() {
+		return "", nil
+	}
+
+	if !w.isTargetRepoAllowed(repo) {
+		return "", fmt.Errorf("XR %s targets repository %s which is not in the allowed target repositories", xr.GetName(), repo)
+	}
+
+	return repo, nil
+}
+
+// routedRepo returns the repository whose detection settings recognized an XR
+// Repositories are only routed when configured with detection settings, so they need no allowlist entry
+func (w *XRWatcher) routedRepo(xr *unstructured.Unstructured) string {
+	router, ok := w.currentDetector().(*detector.RoutingDetector)
+	if !ok {
+		return ""
+	}
+	repo := router.Repository(xr)
+	if w.vcsClient != nil && repo == w.vcsClient.Repository() {
+		return ""
+	}
+	return repo
+}
+
+// isTargetRepoAllowed checks a repository against the allowlist and the repositories of the configured orgs
+func (w *XRWatcher) isTargetRepoAllowed(repo string) bool {
+	w.settingsMu.RLock()
+	defer w.settingsMu.RUnlock()
+
+	if w.orgRepos[repo] {
+		return true
+	}
+
+	for _, pattern := range w.allowedTargetRepos {
+		if matched, err := path.Match(pattern, repo); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// groupByTargetRepo splits a PR's XRs by the repository their plan is posted to
+// XRs with a disallowed target are dropped and logged
+func (w *XRWatcher) groupByTargetRepo(prNumber int, xrs []*unstructured.Unstructured) map[string][]*unstructured.Unstructured {
+	groups := make(map[string][]*unstructured.Unstructured)
+	for _, xr := range xrs {
+		repo, err := w.resolveTargetRepo(xr)
+		if err != nil {
+			w.logger.Error(err, "skipping XR with disallowed target repository", "prNumber", prNumber)
+			continue
+		}
+		groups[repo] = append(groups[repo], xr)
+	}
+	return groups
+}
+
+// prOf returns the PR an XR belongs to, in the repository its plan is posted to
+// The number is 0 for XRs outside PRs; XRs with a disallowed target repository return an error
+func (w *XRWatcher) prOf(xr *unstructured.Unstructured) (workqueue.PR, error) {
+	prNumber := w.currentDetector().DetectPR(xr)
+	if prNumber == 0 {
+		return workqueue.PR{}, nil
+	}
+	repo, err := w.resolveTargetRepo(xr)
+	if err != nil {
+		return workqueue.PR{Number: prNumber}, err
+	}
+	return workqueue.PR{Repo: repo, Number: prNumber}, nil
+}
+
+// prKey returns the PR with a number in a repository given by its full name
+// The default repository is keyed as "", like the target repositories XRs resolve to
+func (w *XRWatcher) prKey(repo string, prNumber int) workqueue.PR {
+	if strings.EqualFold(repo, w.repositoryName("")) {
+		repo = ""
+	}
+	return workqueue.PR{Repo: repo, Number: prNumber}
+}
+
+// movedFrom flags for replanning the PRs with pr's number in other repositories that were last
+// planned with any of xrs (resourceVersions by XR), e.g. before the XRs' target repository changed,
+// so their comments stop showing the XRs
+func (w *XRWatcher) movedFrom(pr workqueue.PR, xrs map[string]string) []workqueue.PR {
+	previous := w.tracker.plannedElsewhere(pr, xrs)
+	for _, moved := range previous {
+		w.logger.Info("XRs changed target repository, replanning the PR in the previous one", "pr", pr, "previous", moved)
+		w.tracker.markRemoved(moved)
+		w.tracker.markDirty(moved)
+	}
+	return previous
+}
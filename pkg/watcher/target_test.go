@@ -0,0 +1,159 @@
+package watcher
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/millstonehq/crossplane-plan/pkg/config"
+	"github.com/millstonehq/crossplane-plan/pkg/workqueue"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestXRWatcher_groupByTargetRepo(t *testing.T) {
+	target := func(name, repo string) *unstructured.Unstructured {
+		return newXR("XBucket", "team", name, map[string]string{TargetRepoAnnotation: repo})
+	}
+
+	tests := []struct {
+		name    string
+		allowed []string
+		xrs     []*unstructured.Unstructured
+		want    map[string][]string
+	}{
+		{
+			name: "default repository",
+			xrs:  []*unstructured.Unstructured{newXR("XBucket", "team", "pr-1-data", nil), newXR("XBucket", "team", "pr-1-logs", nil)},
+			want: map[string][]string{"": {"pr-1-data", "pr-1-logs"}},
+		},
+		{
+			name:    "allowed target",
+			allowed: []string{"acme/network"},
+			xrs:     []*unstructured.Unstructured{newXR("XBucket", "team", "pr-1-data", nil), target("pr-1-vpc", "acme/network")},
+			want:    map[string][]string{"": {"pr-1-data"}, "acme/network": {"pr-1-vpc"}},
+		},
+		{
+			name:    "glob target",
+			allowed: []string{"acme/*"},
+			xrs:     []*unstructured.Unstructured{target("pr-1-vpc", "acme/network"), target("pr-1-db", "acme/data")},
+			want:    map[string][]string{"acme/network": {"pr-1-vpc"}, "acme/data": {"pr-1-db"}},
+		},
+		{
+			name:    "disallowed target is dropped",
+			allowed: []string{"acme/network"},
+			xrs:     []*unstructured.Unstructured{newXR("XBucket", "team", "pr-1-data", nil), target("pr-1-vpc", "other/network")},
+			want:    map[string][]string{"": {"pr-1-data"}},
+		},
+		{
+			name: "no allowlist",
+			xrs:  []*unstructured.Unstructured{target("pr-1-vpc", "acme/network")},
+			want: map[string][]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWatcher(t, clocktesting.NewFakeClock(time.Now()), nil)
+			w.SetAllowedTargetRepos(tt.allowed)
+
+			got := make(map[string][]string)
+			for repo, xrs := range w.groupByTargetRepo(1, tt.xrs) {
+				for _, xr := range xrs {
+					got[repo] = append(got[repo], xr.GetName())
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("groupByTargetRepo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestXRWatcher_prOf(t *testing.T) {
+	tests := []struct {
+		name    string
+		xr      *unstructured.Unstructured
+		want    workqueue.PR
+		wantErr bool
+	}{
+		{name: "not a PR XR", xr: newXR("XBucket", "team", "data", nil)},
+		{name: "default repository", xr: newXR("XBucket", "team", "pr-4-data", nil), want: workqueue.PR{Number: 4}},
+		{
+			name: "target repository",
+			xr:   newXR("XBucket", "team", "pr-4-vpc", map[string]string{TargetRepoAnnotation: "acme/network"}),
+			want: workqueue.PR{Repo: "acme/network", Number: 4},
+		},
+		{
+			name:    "disallowed target repository",
+			xr:      newXR("XBucket", "team", "pr-4-vpc", map[string]string{TargetRepoAnnotation: "other/network"}),
+			want:    workqueue.PR{Number: 4},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWatcher(t, clocktesting.NewFakeClock(time.Now()), nil)
+			w.SetAllowedTargetRepos([]string{"acme/network"})
+
+			got, err := w.prOf(tt.xr)
+			if got != tt.want || (err != nil) != tt.wantErr {
+				t.Errorf("prOf() = %v, %v, want %v (error %v)", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestXRWatcher_prKey(t *testing.T) {
+	w := newTestWatcher(t, clocktesting.NewFakeClock(time.Now()), nil)
+	w.appConfig = &config.Config{GitHubRepo: "acme/infra"}
+
+	tests := []struct {
+		repo string
+		want workqueue.PR
+	}{
+		{repo: "acme/infra", want: workqueue.PR{Number: 7}},
+		{repo: "Acme/Infra", want: workqueue.PR{Number: 7}},
+		{repo: "", want: workqueue.PR{Number: 7}},
+		{repo: "acme/network", want: workqueue.PR{Repo: "acme/network", Number: 7}},
+	}
+	for _, tt := range tests {
+		if got := w.prKey(tt.repo, 7); got != tt.want {
+			t.Errorf("prKey(%q, 7) = %v, want %v", tt.repo, got, tt.want)
+		}
+	}
+}
+
+func TestXRWatcher_handleXREventTargetRepoChanged(t *testing.T) {
+	clk := clocktesting.NewFakeClock(time.Now())
+	processor := &recordingProcessor{processed: make(chan workqueue.PR, 2)}
+	w := newTestWatcher(t, clk, nil, WithWorkQueue(func(workqueue.PRProcessor) WorkQueue {
+		return workqueue.NewPRWorkQueue(processor, logr.Discard(), 5*time.Second, workqueue.WithClock(clk))
+	}))
+	w.SetAllowedTargetRepos([]string{"acme/network"})
+
+	// PR 3 was planned in the default repository with both XRs, then one moved to acme/network
+	w.tracker.markPlanned(workqueue.PR{Number: 3}, map[string]string{"XBucket/team/pr-3-data": "1", "XBucket/team/pr-3-vpc": "1"})
+	moved := newXR("XBucket", "team", "pr-3-vpc", map[string]string{TargetRepoAnnotation: "acme/network"})
+	w.handleXREvent(context.Background(), watch.Modified, moved)
+	clk.Step(5 * time.Second)
+
+	var got []string
+	for len(got) < 2 {
+		select {
+		case pr := <-processor.processed:
+			got = append(got, pr.String())
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for both PRs, got %v", got)
+		}
+	}
+	sort.Strings(got)
+	if want := []string{"#3", "acme/network#3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("processed %v, want %v", got, want)
+	}
+}
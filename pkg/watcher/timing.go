@@ -0,0 +1,70 @@
+package watcher
+
+import (
+	"sync"
+	"time"
+
+	"github.com/millstonehq/crossplane-plan/pkg/formatter"
+)
+
+// SetCommentTiming adds a timing breakdown footer to PR comments
+func (w *XRWatcher) SetCommentTiming(enabled bool) {
+	w.commentTiming = enabled
+}
+
+// planTimer records how long each phase of a plan takes
+// Repeated phases (e.g., one diff per XR) accumulate in the order first seen
+type planTimer struct {
+	mu     sync.Mutex
+	start  time.Time
+	phases []formatter.TimingPhase
+}
+
+// newPlanTimer starts timing a plan
+func newPlanTimer() *planTimer {
+	return &planTimer{start: time.Now()}
+}
+
+// phase starts timing a phase; call the returned func when it ends
+func (t *planTimer) phase(name string) func() {
+	start := time.Now()
+	return func() {
+		t.add(name, time.Since(start))
+	}
+}
+
+// add accumulates time spent in a phase
+func (t *planTimer) add(name string, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i := range t.phases {
+		if t.phases[i].Name == name {
+			t.phases[i].Duration += d
+			return
+		}
+	}
+	t.phases = append(t.phases, formatter.TimingPhase{Name: name, Duration: d})
+}
+
+// timing returns the breakdown so far
+func (t *planTimer) timing(resources int) formatter.Timing {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return formatter.Timing{
+		Resources: resources,
+		Total:     time.Since(t.start),
+		Phases:    append([]formatter.TimingPhase(nil), t.phases...),
+	}
+}
+
+// logValues returns the breakdown as structured log key/values
+func (t *planTimer) logValues(resources int) []interface{} {
+	timing := t.timing(resources)
+	values := []interface{}{"resources", timing.Resources, "total", timing.Total.Round(time.Millisecond).String()}
+	for _, phase := range timing.Phases {
+		values = append(values, phase.Name, phase.Duration.Round(time.Millisecond).String())
+	}
+	return values
+}
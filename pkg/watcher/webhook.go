@@ -0,0 +1,43 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/millstonehq/crossplane-plan/pkg/webhook"
+)
+
+// Replan implements webhook.Replanner
+// It replans the PR of repo: the PR's XRs that target repo, not those of the same PR number elsewhere
+func (w *XRWatcher) Replan(ctx context.Context, repo string, prNumber int) error {
+	if !w.plansRepository(repo) {
+		return fmt.Errorf("%w: %s", webhook.ErrUnknownRepository, repo)
+	}
+	// Only the leader's queue is processed
+	if !w.IsLeader() {
+		return webhook.ErrNotLeader
+	}
+
+	pr := w.prKey(repo, prNumber)
+	w.logger.Info("Replanning PR on request", "repo", repo, "pr", pr)
+	w.tracker.markDirty(pr)
+	w.workQueue.Enqueue(ctx, pr)
+	return nil
+}
+
+// plansRepository reports whether plans may be posted to a repository: the default repository,
+// an allowed target repository, or one routed to by its profile's detection settings
+func (w *XRWatcher) plansRepository(repo string) bool {
+	if strings.EqualFold(repo, w.repositoryName("")) || w.isTargetRepoAllowed(repo) {
+		return true
+	}
+
+	w.settingsMu.RLock()
+	defer w.settingsMu.RUnlock()
+	if w.appConfig == nil {
+		return false
+	}
+	_, routed := w.appConfig.RepoDetections()[repo]
+	return routed
+}
@@ -0,0 +1,94 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/millstonehq/crossplane-plan/pkg/admin"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// WhoOwns implements admin.OwnerFinder: it runs the PR detectors over an XR and reports its PR,
+// base name, target repository and the PR's last plan, e.g. during incident response
+func (w *XRWatcher) WhoOwns(ctx context.Context, kind, name, namespace string) (*admin.OwnerResult, error) {
+	xr, err := w.findXR(ctx, kind, name, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	d := w.currentDetector()
+	result := &admin.OwnerResult{
+		Resource:   fmt.Sprintf("%s/%s", xr.GetKind(), xr.GetName()),
+		APIVersion: xr.GetAPIVersion(),
+		Namespace:  xr.GetNamespace(),
+		PRNumber:   d.DetectPR(xr),
+		BaseName:   d.GetBaseName(xr),
+		Ignored:    isPlanIgnored(xr),
+	}
+	if result.PRNumber == 0 {
+		return result, nil
+	}
+
+	repo, err := w.resolveTargetRepo(xr)
+	if err != nil {
+		result.RepositoryError = err.Error()
+	}
+	result.Repository = w.repositoryName(repo)
+
+	history, err := w.state.Plans(ctx, result.Repository, result.PRNumber)
+	if err != nil {
+		recordError("store", err)
+		return nil, fmt.Errorf("failed to load plans of PR #%d: %w", result.PRNumber, err)
+	}
+	if len(history) > 0 {
+		result.LastPlan = &history[len(history)-1]
+	}
+
+	for _, item := range w.QueueSnapshot() {
+		if item.PRNumber == result.PRNumber && item.Repo == repo {
+			queued := admin.NewQueueItem(item)
+			result.Queue = &queued
+			break
+		}
+	}
+	return result, nil
+}
+
+// findXR finds an XR by kind (or plural resource name, case-insensitive) and name
+// Without a namespace, the name must be unique across namespaces
+func (w *XRWatcher) findXR(ctx context.Context, kind, name, namespace string) (*unstructured.Unstructured, error) {
+	gvrs, err := w.discoverXRDGVRs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover XRDs: %w", err)
+	}
+
+	var matches []*unstructured.Unstructured
+	for _, gvr := range gvrs {
+		_, err := w.listEach(ctx, gvr, func(xr *unstructured.Unstructured) {
+			if !strings.EqualFold(xr.GetKind(), kind) && !strings.EqualFold(gvr.Resource, kind) {
+				return
+			}
+			if xr.GetName() != name || (namespace != "" && xr.GetNamespace() != namespace) {
+				return
+			}
+			matches = append(matches, xr.DeepCopy())
+		})
+		if err != nil {
+			w.logger.Error(err, "failed to list resources", "gvr", gvr.String())
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("%s/%s: %w", kind, name, admin.ErrResourceNotFound)
+	case 1:
+		return matches[0], nil
+	default:
+		namespaces := make([]string, 0, len(matches))
+		for _, xr := range matches {
+			namespaces = append(namespaces, xr.GetNamespace())
+		}
+		return nil, fmt.Errorf("%s/%s exists in namespaces %s; pass a namespace", kind, name, strings.Join(namespaces, ", "))
+	}
+}
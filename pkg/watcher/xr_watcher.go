@@ -5,83 +5,151 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/millstonehq/crossplane-plan/pkg/api"
 	"github.com/millstonehq/crossplane-plan/pkg/argocd"
+	"github.com/millstonehq/crossplane-plan/pkg/config"
 	"github.com/millstonehq/crossplane-plan/pkg/detector"
 	"github.com/millstonehq/crossplane-plan/pkg/differ"
 	"github.com/millstonehq/crossplane-plan/pkg/formatter"
-	"github.com/millstonehq/crossplane-plan/pkg/vcs/github"
+	"github.com/millstonehq/crossplane-plan/pkg/logsample"
+	"github.com/millstonehq/crossplane-plan/pkg/severity"
+	"github.com/millstonehq/crossplane-plan/pkg/store"
+	"github.com/millstonehq/crossplane-plan/pkg/vcs"
 	"github.com/millstonehq/crossplane-plan/pkg/workqueue"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/leaderelection"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/utils/clock"
 )
 
 // XRWatcher watches Crossplane Composite Resources and posts diffs to GitHub
 type XRWatcher struct {
-	clientset              *kubernetes.Clientset
+	clientset              kubernetes.Interface // leader election
+	planClientset          kubernetes.Interface // the identity of cfg, which reads and diffs resources
 	dynamicClient          dynamic.Interface
 	detector               detector.Detector
 	differ                 *differ.Calculator
 	formatter              *formatter.GitHubFormatter
-	vcsClient              *github.Client
+	vcsClient              vcs.Client
 	argocdClient           *argocd.Client
 	logger                 logr.Logger
-	processedXRs           map[string]string // name -> resource version
-	reconciliationInterval int               // minutes
-	workQueue              *workqueue.PRWorkQueue
+	reconciliationInterval int // minutes
+	workQueue              WorkQueue
+	newWorkQueue           func(workqueue.PRProcessor) WorkQueue // creates workQueue (nil for the default queue)
 	cfg                    *rest.Config
+	clock                  clock.WithTickerAndDelayedExecution
+	allowedTargetRepos     []string        // repositories XRs may target via annotation
+	orgRepos               map[string]bool // repositories of the configured orgs, refreshed periodically
+	appConfig              *config.Config
+	repoSanitizers         map[string]*differ.Sanitizer // per-repository strip rules
+	settingsMu             sync.RWMutex                 // guards detector and reloadable settings
+	debugDiffInput         bool                         // attach sanitized diff input for every XR
+	pipelineCaptureDir     string                       // where pipeline captures are written; logged if empty
+	tracker                *reconcileTracker
+	fullSweepInterval      int // minutes between full reconciliation sweeps
+	shutdownGracePeriod    time.Duration
+	runs                   prRuns // PR runs started by reconciliation rather than the work queue
+	commentTiming          bool // add a timing breakdown footer to comments
+	placeholderDelay       time.Duration
+	leading                atomic.Bool // whether this replica holds the leader lease
+	previewRemovedAction   string      // what to do with the comment of a PR whose preview was deleted
+	state                  *store.State
+	stateMu                sync.Mutex    // guards savedRetry
+	savedRetry             string        // retry state last saved to the store
+	maxBatchXRs            int           // XRs planned per PR and repository (0 for no limit)
+	maxDiffs               int           // diff texts kept per plan (0 for no limit)
+	onlyChangedSources     bool          // skip XRs whose source files the PR doesn't change
+	draftPRs               string        // what to do with draft PRs
+	stateGCInterval        time.Duration // how often PR state is garbage-collected (0 to disable)
+	stateTTL               time.Duration // age of the last plan after which a PR's state is dropped (0 to keep it)
+	dashboardIssue         int           // issue holding the dashboard comment (0 to disable)
+	dashboardUpdates       chan struct{}
+	outcome                *Outcome       // plans of the current RunOnce pass (nil outside of one)
+	convergenceEstimate    bool           // estimate the time to converge after merge in comments
+	placement              string         // where plans are published (comment, check run or both)
+	commitStatusFailOn     severity.Level // severity from which commit statuses fail (0 for no statuses)
+	checkAnnotations       bool           // annotate the source files of resources in check runs
+	failureComments        bool           // comment on PRs whose whole plan fails
+	componentComments      bool           // post a separate comment per component
+	readyMu                sync.Mutex
+	readySeen              map[types.UID]time.Time // XRs whose time-to-Ready was sampled, by Ready transition
+	sampler                *logsample.Sampler      // rate-limits per-resource logs of hot paths
+	eventCounts            *logsample.Counts       // XR events per GVR since the last periodic reconciliation
 }
 
-// NewXRWatcher creates a new XRWatcher
+// NewXRWatcher creates a new XRWatcher for the cluster of cfg (the in-cluster config if nil)
+// Optional collaborators and settings are given as options
 func NewXRWatcher(
-	clientset *kubernetes.Clientset,
+	cfg *rest.Config,
 	detector detector.Detector,
 	differ *differ.Calculator,
 	formatter *formatter.GitHubFormatter,
-	vcsClient *github.Client,
-	argocdClient *argocd.Client,
-	logger logr.Logger,
-	reconciliationInterval int,
-) *XRWatcher {
-	// Create dynamic client from the same config
-	cfg, err := rest.InClusterConfig()
-	if err != nil {
-		// Try building from kubeconfig if in-cluster fails
-		panic(fmt.Sprintf("failed to get kubernetes config: %v", err))
+	opts ...Option,
+) (*XRWatcher, error) {
+	if cfg == nil {
+		inCluster, err := rest.InClusterConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get kubernetes config: %w", err)
+		}
+		cfg = inCluster
 	}
 
 	dynamicClient, err := dynamic.NewForConfig(cfg)
 	if err != nil {
-		panic(fmt.Sprintf("failed to create dynamic client: %v", err))
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
 	}
 
 	watcher := &XRWatcher{
-		clientset:              clientset,
-		dynamicClient:          dynamicClient,
-		detector:               detector,
-		differ:                 differ,
-		formatter:              formatter,
-		vcsClient:              vcsClient,
-		argocdClient:           argocdClient,
-		logger:                 logger,
-		processedXRs:           make(map[string]string),
-		reconciliationInterval: reconciliationInterval,
-		cfg:                    cfg,
+		dynamicClient:       dynamicClient,
+		detector:            detector,
+		differ:              differ,
+		formatter:           formatter,
+		logger:              logr.Discard(),
+		cfg:                 cfg,
+		clock:               clock.RealClock{},
+		tracker:             newReconcileTracker(),
+		state:               store.NewState(store.NewMemory()),
+		fullSweepInterval:   60,
+		shutdownGracePeriod: 30 * time.Second,
+		placeholderDelay:    30 * time.Second,
+		dashboardUpdates:    make(chan struct{}, 1),
+		readySeen:           make(map[types.UID]time.Time),
+	}
+	for _, opt := range opts {
+		opt(watcher)
+	}
+	watcher.sampler = logsample.NewSampler(watcher.logger, 0, watcher.clock)
+	watcher.eventCounts = logsample.NewCounts()
+
+	// Permissions are reviewed as the identity of cfg, which may impersonate a plan-only identity
+	if watcher.planClientset, err = kubernetes.NewForConfig(cfg); err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes clientset: %w", err)
+	}
+	if watcher.clientset == nil {
+		watcher.clientset = watcher.planClientset
 	}
 
 	// Create work queue with 5-second debounce
-	watcher.workQueue = workqueue.NewPRWorkQueue(watcher, logger, 5*time.Second)
+	if watcher.newWorkQueue != nil {
+		watcher.workQueue = watcher.newWorkQueue(watcher)
+	} else {
+		watcher.workQueue = workqueue.NewPRWorkQueue(watcher, watcher.logger, 5*time.Second, workqueue.WithClock(watcher.clock))
+	}
 
-	return watcher
+	return watcher, nil
 }
 
 // Start begins watching Crossplane XRs with leader election
@@ -113,8 +181,8 @@ func (w *XRWatcher) Start(ctx context.Context) error {
 		},
 	}
 
-	// Run leader election
-	leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+	// Run leader election until ctx is done
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
 		Lock:            lock,
 		ReleaseOnCancel: true,
 		LeaseDuration:   15 * time.Second,
@@ -123,6 +191,8 @@ func (w *XRWatcher) Start(ctx context.Context) error {
 		Callbacks: leaderelection.LeaderCallbacks{
 			OnStartedLeading: func(ctx context.Context) {
 				w.logger.Info("Acquired leadership, starting watchers")
+				w.leading.Store(true)
+				defer w.leading.Store(false)
 				if err := w.run(ctx); err != nil {
 					w.logger.Error(err, "Failed to run watchers")
 				}
@@ -137,6 +207,10 @@ func (w *XRWatcher) Start(ctx context.Context) error {
 			},
 		},
 	})
+	if err != nil {
+		return fmt.Errorf("failed to configure leader election: %w", err)
+	}
+	elector.Run(ctx)
 
 	return nil
 }
@@ -151,7 +225,29 @@ func (w *XRWatcher) run(ctx context.Context) error {
 
 	w.logger.Info("Discovered XRDs", "count", len(gvrs))
 
+	// Summarize the plans of all open PRs on the dashboard issue
+	if w.dashboardIssue > 0 && w.vcsClient != nil {
+		go w.runDashboard(ctx)
+	}
+
+	// Discover the repositories of the configured orgs before planning, so their PRs aren't dropped
+	if w.vcsClient != nil {
+		w.refreshOrgRepos(ctx)
+		go w.runOrgDiscovery(ctx)
+	}
+
+	// Drop the state of closed PRs
+	if w.stateGCInterval > 0 {
+		go w.runStateGC(ctx)
+	}
+
+	// Replan the PRs a previous leader was waiting on (held or failed comments, abandoned processing),
+	// but not those it planned at the current resourceVersions of their XRs
+	w.restoreRetryState(ctx)
+	w.restorePlannedVersions(ctx)
+
 	// Initial reconciliation - process existing PR XRs
+	// Comments a previous leader already posted are only edited if their content changed
 	w.logger.Info("Starting initial reconciliation of existing PR XRs")
 	for _, gvr := range gvrs {
 		if err := w.reconcileExistingXRs(ctx, gvr); err != nil {
@@ -166,23 +262,33 @@ func (w *XRWatcher) run(ctx context.Context) error {
 		go w.watchGVR(ctx, gvr)
 	}
 
+	// Watch PR applications, which may change without any XR changing
+	if w.argocdClient != nil {
+		go w.watchApplications(ctx)
+	}
+
 	// Start periodic reconciliation if enabled
 	if w.reconciliationInterval > 0 {
-		ticker := time.NewTicker(time.Duration(w.reconciliationInterval) * time.Minute)
+		ticker := w.clock.NewTicker(time.Duration(w.reconciliationInterval) * time.Minute)
 		defer ticker.Stop()
 
-		w.logger.Info("Starting periodic reconciliation", "interval", fmt.Sprintf("%dm", w.reconciliationInterval))
+		w.logger.Info("Starting periodic reconciliation",
+			"interval", fmt.Sprintf("%dm", w.reconciliationInterval),
+			"fullInterval", fmt.Sprintf("%dm", w.fullSweepInterval))
 
 		go func() {
+			lastFullSweep := w.clock.Now()
 			for {
 				select {
-				case <-ticker.C:
-					w.logger.Info("Running periodic reconciliation")
-					for _, gvr := range gvrs {
-						if err := w.reconcileExistingXRs(ctx, gvr); err != nil {
-							w.logger.Error(err, "periodic reconciliation failed", "gvr", gvr.String())
-						}
+				case <-ticker.C():
+					// Only replan PRs that changed, with an occasional full sweep as a safety net
+					full := w.fullSweepInterval > 0 &&
+						w.clock.Since(lastFullSweep) >= time.Duration(w.fullSweepInterval)*time.Minute
+					if full {
+						lastFullSweep = w.clock.Now()
 					}
+					w.logger.Info("Running periodic reconciliation", "full", full)
+					w.reconcilePRs(ctx, gvrs, full)
 				case <-ctx.Done():
 					return
 				}
@@ -192,6 +298,9 @@ func (w *XRWatcher) run(ctx context.Context) error {
 
 	// Block until context is cancelled
 	<-ctx.Done()
+
+	// Shutdown or leadership loss: let in-flight PRs finish before the lease is released
+	w.Drain()
 	return nil
 }
 
@@ -210,6 +319,7 @@ func (w *XRWatcher) discoverXRDGVRs(ctx context.Context) ([]schema.GroupVersionR
 	}
 
 	var gvrs []schema.GroupVersionResource
+	hints := make(map[schema.GroupKind]formatter.RenderHints)
 	for _, xrd := range xrds.Items {
 		// Extract group from spec.group
 		group, found, err := unstructured.NestedString(xrd.Object, "spec", "group")
@@ -225,6 +335,12 @@ func (w *XRWatcher) discoverXRDGVRs(ctx context.Context) ([]schema.GroupVersionR
 			continue
 		}
 
+		// Collect rendering hints from the XRD's annotations
+		if xrdHints, ok := formatter.HintsFromAnnotations(xrd.GetAnnotations()); ok {
+			kind, _, _ := unstructured.NestedString(xrd.Object, "spec", "names", "kind")
+			hints[schema.GroupKind{Group: group, Kind: kind}] = xrdHints
+		}
+
 		// Get served versions from spec.versions
 		versions, found, err := unstructured.NestedSlice(xrd.Object, "spec", "versions")
 		if err != nil || !found {
@@ -254,43 +370,62 @@ func (w *XRWatcher) discoverXRDGVRs(ctx context.Context) ([]schema.GroupVersionR
 		}
 	}
 
+	w.formatter.SetRenderHints(hints)
 	return gvrs, nil
 }
 
 // reconcileExistingXRs performs initial reconciliation of existing XRs for a GVR
 func (w *XRWatcher) reconcileExistingXRs(ctx context.Context, gvr schema.GroupVersionResource) error {
-	list, err := w.dynamicClient.Resource(gvr).List(ctx, metav1.ListOptions{})
+	// Group XRs by PR and the repository they target
+	prXRs := make(map[workqueue.PR][]*unstructured.Unstructured)
+	total := 0
+	resourceVersion, err := w.listEach(ctx, gvr, func(xr *unstructured.Unstructured) {
+		total++
+
+		// Ignored PR XRs only count for deletion detection
+		pr, err := w.prOf(xr)
+		if pr.Number == 0 {
+			return
+		}
+		w.tracker.setIgnored(xrKey(xr), isPlanIgnored(xr))
+		if err != nil {
+			w.logger.Error(err, "skipping XR with disallowed target repository", "pr", pr)
+			return
+		}
+
+		prXRs[pr] = append(prXRs[pr], xr.DeepCopy())
+	})
 	if err != nil {
 		return fmt.Errorf("failed to list resources: %w", err)
 	}
 
-	w.logger.Info("Checking for existing PR XRs", "gvr", gvr.String(), "totalCount", len(list.Items))
+	w.logger.Info("Checking for existing PR XRs", "gvr", gvr.String(), "totalCount", total)
 
-	// Group XRs by PR number
-	prXRs := make(map[int][]*unstructured.Unstructured)
-	for _, item := range list.Items {
-		xr := item.DeepCopy()
+	// Resume watches from this list instead of replaying every object as ADDED
+	w.tracker.setBookmark(gvr, resourceVersion)
 
-		// Only process PR XRs
-		prNumber := w.detector.DetectPR(xr)
-		if prNumber == 0 {
+	// Process each PR's XRs as a batch, except those planned at the same resourceVersions before a restart
+	skipped := 0
+	for pr, xrs := range prXRs {
+		if ctx.Err() != nil {
+			return nil // Shutting down, don't start another PR
+		}
+		if len(withoutIgnored(xrs)) == 0 {
 			continue
 		}
-
-		prXRs[prNumber] = append(prXRs[prNumber], xr)
-	}
-
-	// Process each PR's XRs as a batch
-	for prNumber, xrs := range prXRs {
-		w.logger.Info("Reconciling PR XRs", "prNumber", prNumber, "count", len(xrs))
-		if err := w.handlePRBatch(ctx, prNumber, xrs); err != nil {
-			w.logger.Error(err, "failed to process PR batch", "prNumber", prNumber)
+		if w.tracker.unchanged(pr, resourceVersions(xrs)) {
+			skipped++
+			continue
+		}
+		w.logger.Info("Reconciling PR XRs", "pr", pr, "count", len(xrs))
+		if err := w.runPR(ctx, pr, xrs); err != nil {
+			w.logger.Error(err, "failed to process PR batch", "pr", pr)
 			// Continue with other PRs
 		}
 	}
 
 	if len(prXRs) > 0 {
-		w.logger.Info("Reconciled existing PR XRs", "gvr", gvr.String(), "prCount", len(prXRs))
+		w.logger.Info("Reconciled existing PR XRs", "gvr", gvr.String(), "prCount", len(prXRs), "unchanged", skipped)
 	}
 
 	return nil
@@ -300,23 +435,28 @@ func (w *XRWatcher) reconcileExistingXRs(ctx context.Context, gvr schema.GroupVe
 func (w *XRWatcher) watchGVR(ctx context.Context, gvr schema.GroupVersionResource) {
 	w.logger.Info("Watching GVR", "gvr", gvr.String())
 
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		default:
-			if err := w.watchGVROnce(ctx, gvr); err != nil {
-				w.logger.Error(err, "watch failed, retrying in 5s", "gvr", gvr.String())
-				time.Sleep(5 * time.Second)
-			}
-		}
-	}
+	w.runWatch(ctx, gvr.String(),
+		func(ctx context.Context) error { return w.watchGVROnce(ctx, gvr) },
+		func(ctx context.Context) error { return w.relistGVR(ctx, gvr) })
 }
 
-// watchGVROnce performs a single watch operation
+// watchGVROnce performs a single watch operation, resuming from the GVR's bookmark
 func (w *XRWatcher) watchGVROnce(ctx context.Context, gvr schema.GroupVersionResource) error {
-	watcher, err := w.dynamicClient.Resource(gvr).Watch(ctx, metav1.ListOptions{})
+	if w.tracker.bookmark(gvr) == "" {
+		// Without a bookmark the watch would replay every object as ADDED
+		if err := w.relistGVR(ctx, gvr); err != nil {
+			return err
+		}
+	}
+
+	watcher, err := w.dynamicClient.Resource(gvr).Watch(ctx, metav1.ListOptions{
+		ResourceVersion:     w.tracker.bookmark(gvr),
+		AllowWatchBookmarks: true,
+	})
 	if err != nil {
+		if apierrors.IsResourceExpired(err) || apierrors.IsGone(err) {
+			return errWatchExpired
+		}
 		return fmt.Errorf("failed to create watcher: %w", err)
 	}
 	defer watcher.Stop()
@@ -331,6 +471,11 @@ func (w *XRWatcher) watchGVROnce(ctx context.Context, gvr schema.GroupVersionRes
 			}
 
 			if event.Type == watch.Error {
+				if isExpired(event) {
+					// Bookmark too old: re-list to catch up on the changes in between
+					w.tracker.resetBookmark(gvr)
+					return errWatchExpired
+				}
 				w.logger.Error(nil, "watch error event", "gvr", gvr.String())
 				continue
 			}
@@ -341,49 +486,198 @@ func (w *XRWatcher) watchGVROnce(ctx context.Context, gvr schema.GroupVersionRes
 				continue
 			}
 
+			w.tracker.setBookmark(gvr, xr.GetResourceVersion())
+			if event.Type == watch.Bookmark {
+				continue
+			}
+
+			w.eventCounts.Add(gvr.String(), string(event.Type))
 			w.handleXREvent(ctx, event.Type, xr)
 		}
 	}
 }
 
-// handlePRBatch processes all XRs for a single PR and posts one combined comment
-func (w *XRWatcher) handlePRBatch(ctx context.Context, prNumber int, xrs []*unstructured.Unstructured) error {
-	if len(xrs) == 0 {
+// handlePRBatch processes the XRs of a PR that target one repository and posts its combined comment there
+// xrs include the PR's ignored XRs, which are not planned
+func (w *XRWatcher) handlePRBatch(ctx context.Context, pr workqueue.PR, xrs []*unstructured.Unstructured) error {
+	if len(withoutIgnored(xrs)) == 0 {
 		return nil
 	}
+	defer w.requestDashboardUpdate()
 
-	results := make(map[string]*differ.DiffResult)
-	var argocdDiff *argocd.AppDiff
-	var scope *Scope
+	if err := w.planPR(ctx, pr, xrs); err != nil {
+		// Retry transient failures on the next reconciliation even if the PR's XRs don't change
+		w.planFailed(pr, resourceVersions(xrs), []error{err})
+		return err
+	}
 
-	// 1. Discover scope from first PR XR (all should have same ArgoCD app label)
-	if w.argocdClient != nil {
-		discoveredScope, err := w.DiscoverScope(xrs[0])
+	versions := resourceVersions(xrs)
+	w.tracker.markPlanned(pr, versions)
+	w.savePlannedVersions(ctx, pr, versions)
+	if w.holdingComments() {
+		// Post the held comments on the first reconciliation after the freeze window
+		w.tracker.markDirty(pr)
+	}
+	return nil
+}
+
+// planPR plans the XRs of a PR that target one repository and posts its comment there
+// xrs include the PR's ignored XRs, which only count for deletion detection
+func (w *XRWatcher) planPR(ctx context.Context, pr workqueue.PR, xrs []*unstructured.Unstructured) error {
+	shared := w.planShared(ctx, pr, xrs)
+	return w.handleRepoBatch(ctx, pr.Repo, pr.Number, withoutIgnored(xrs), shared)
+}
+
+// sharedPlan is the part of a PR's plan that is found once for all of its XRs
+type sharedPlan struct {
+	scope     *Scope
+	appDiff   *argocd.AppDiff
+	deletions []differ.PlanItem
+	timer     *planTimer
+}
+
+// planShared discovers a PR's scope and finds its deletions, from its XRs that target one repository
+// Deletions found without ArgoCD are kept only if the deleted XR targets that repository too
+func (w *XRWatcher) planShared(ctx context.Context, pr workqueue.PR, xrs []*unstructured.Unstructured) *sharedPlan {
+	shared := &sharedPlan{timer: newPlanTimer()}
+	timer := shared.timer
+
+	// 1. Discover scope from the first planned PR XR (all should have same ArgoCD app label)
+	if planned := withoutIgnored(xrs); w.argocdClient != nil && len(planned) > 0 {
+		scopeXR := planned[0]
+		endDiscovery := timer.phase("discovery")
+		discoveredScope, err := w.DiscoverScope(ctx, scopeXR)
+		endDiscovery()
 		if err != nil {
 			w.logger.Error(err, "failed to discover scope, falling back to legacy detection",
-				"xr", xrs[0].GetName())
+				"xr", scopeXR.GetName())
 			// Continue without ArgoCD integration (degraded mode)
 		} else {
-			scope = discoveredScope
+			shared.scope = discoveredScope
 			w.logger.Info("Discovered scope",
+				"prApp", discoveredScope.PRAppName,
+				"prodApp", discoveredScope.ProdAppName)
+		}
+	}
+	scope := shared.scope
+
+	// 3. ArgoCD diff for deletions + bare resources
+	deletionPhase := "deletions"
+	if w.argocdClient != nil && scope != nil {
+		deletionPhase = "ArgoCD"
+	}
+	endDeletions := timer.phase(deletionPhase)
+	defer endDeletions()
+	if w.argocdClient != nil && scope != nil {
+		appDiff, err := w.argocdClient.GetAppDiff(ctx, scope.PRAppName, scope.ProdAppName)
+		if err == nil {
+			// Successfully got ArgoCD diff
+			shared.appDiff = appDiff
+			w.logger.Info("ArgoCD diff complete",
+				"additions", len(appDiff.Additions),
+				"modifications", len(appDiff.Modifications),
+				"deletions", len(appDiff.Deletions))
+
+			// Add ArgoCD deletions to the plan
+			for _, deletion := range appDiff.Deletions {
+				shared.deletions = differ.AddDeletion(shared.deletions, deletion.GVK, deletion.Namespace, deletion.Name, &differ.DiffResult{
+					HasChanges: true,
+					Summary:    fmt.Sprintf("⚠️ %s will be **DELETED** (ArgoCD)", deletion.GVK.Kind),
+					RawDiff:    deletion.RawDiff,
+				})
+			}
+			return shared
+		}
+
+		recordError("argocd", err)
+		if errors.Is(err, argocd.ErrNotFound) {
+			w.logger.Info("ArgoCD diff unavailable, using fallback deletion detection",
 				"prApp", scope.PRAppName,
 				"prodApp", scope.ProdAppName)
+		} else {
+			w.logger.Error(err, "ArgoCD diff failed, using fallback",
+				"prApp", scope.PRAppName,
+				"prodApp", scope.ProdAppName)
+		}
+	}
+
+	// No ArgoCD client, scope or diff - use legacy deletion detection
+	deletions, err := w.detectDeletions(ctx, pr.Number, scope, xrs)
+	if err != nil {
+		w.logger.Error(err, "failed to detect deletions", "pr", pr)
+	}
+	for _, deletion := range deletions {
+		repo, err := w.resolveTargetRepo(deletion.XR)
+		if err != nil {
+			w.logger.Error(err, "skipping deletion of XR with disallowed target repository", "pr", pr)
+			continue
 		}
+		if repo == pr.Repo {
+			shared.deletions = append(shared.deletions, deletion)
+		}
+	}
+	return shared
+}
+
+// handleRepoBatch processes the XRs of a PR that target a single repository, together with the
+// PR's deletions and ArgoCD diff
+// An empty repo means the default repository
+func (w *XRWatcher) handleRepoBatch(ctx context.Context, repo string, prNumber int, xrs []*unstructured.Unstructured, shared *sharedPlan) error {
+	if len(xrs) == 0 && len(shared.deletions) == 0 {
+		return nil
+	}
+	draft := w.isDraft(ctx, repo, prNumber)
+	if w.skipDraft(ctx, repo, prNumber, draft) {
+		return nil
+	}
+
+	var items []differ.PlanItem
+	argocdDiff := shared.appDiff
+	scope := shared.scope
+	timer := shared.timer
+
+	// Link each resource to the files of the PR that declare it
+	files := w.changedFiles(ctx, repo, prNumber)
+
+	// Skip XRs whose sources the PR doesn't change, then plan at most maxBatchXRs
+	// All XRs still count for deletion detection
+	changed, unchanged := w.filterUnchangedSources(ctx, xrs, scope, files)
+	planned, shed := shedXRs(changed, w.maxBatchXRs)
+	diffs := diffBudget{max: w.maxDiffs}
+
+	// Let developers know a slow plan is underway
+	stopPlaceholder := w.startPlaceholder(ctx, repo, prNumber, len(planned))
+	defer stopPlaceholder()
+
+	// Read production as declared in Git, if the repository compares against it
+	declared, err := w.loadGitBaseline(ctx, repo)
+	if err != nil {
+		recordError("vcs", err)
+		w.logger.Error(err, "failed to load git baseline, comparing against the cluster", "prNumber", prNumber)
 	}
 
+	// XRs mapping to the same production XR can't be told apart, so none of them is diffed
+	collisions := differ.ProductionNameCollisions(planned, w.currentDetector().GetBaseName)
+
 	// 2. Run crossplane-diff for composition preview (existing behavior)
-	for _, xr := range xrs {
+	for _, xr := range planned {
 		name := xr.GetName()
 		namespace := xr.GetNamespace()
 
-		w.logger.Info("Processing XR in batch",
+		if err := collisions[xr]; err != nil {
+			w.logger.Error(err, "skipping XR", "name", name, "prNumber", prNumber)
+			items = append(items, differ.NewPlanItem(name, differ.FailedResult(xr, err)))
+			continue
+		}
+
+		w.sampler.Info("Processing XR in batch",
 			"name", name,
 			"namespace", namespace,
 			"prNumber", prNumber,
 		)
 
 		// Clone the XR and rename it to the production name
-		baseName := w.detector.GetBaseName(xr)
+		baseName := w.currentDetector().GetBaseName(xr)
 		xrForDiff := xr.DeepCopy()
 		xrForDiff.SetName(baseName)
 
@@ -394,122 +688,284 @@ func (w *XRWatcher) handlePRBatch(ctx context.Context, prNumber int, xrs []*unst
 		xrForDiff.SetCreationTimestamp(metav1.Time{})
 		xrForDiff.SetManagedFields(nil)
 
-		w.logger.Info("Comparing PR XR against production",
+		w.logger.V(logsample.DetailLevel).Info("Comparing PR XR against production",
 			"prName", name,
 			"productionName", baseName,
 		)
 
 		// Calculate diff
-		diff, err := w.differ.CalculateDiff(ctx, xrForDiff)
+		endDiff := timer.phase("diff")
+		diffCtx := ctx
+		if isPipelineCaptureRequested(xr) {
+			diffCtx = differ.WithPipelineCapture(ctx)
+		}
+		diff, err := w.calculateDiff(diffCtx, repo, xrForDiff, declared)
+		endDiff()
 		if err != nil {
+			recordError("differ", err)
 			w.logger.Error(err, "failed to calculate diff", "name", name)
+			items = append(items, differ.NewPlanItem(name, differ.FailedResult(xr, err)))
 			continue
 		}
 
-		// Store result using original XR name as key
-		results[name] = diff
+		diffs.admit(diff)
+		w.recordDiffInput(prNumber, xr, diff)
+		w.recordPipeline(repo, prNumber, xr, diff)
+		diff.Links = w.linksFor(repo, prNumber, xr, baseName, scope)
+		diff.Sources = w.sourcesFor(xr, baseName, files)
+
+		// Items are named after the original XR
+		items = append(items, differ.NewPlanItem(name, diff))
 	}
 
-	// 3. NEW: ArgoCD diff for deletions + bare resources
-	if w.argocdClient != nil && scope != nil {
-		appDiff, err := w.argocdClient.GetAppDiff(ctx, scope.PRAppName, scope.ProdAppName)
-		if err != nil {
-			if errors.Is(err, argocd.ErrNotFound) {
-				w.logger.Info("ArgoCD diff unavailable, using fallback deletion detection",
-					"prApp", scope.PRAppName,
-					"prodApp", scope.ProdAppName)
-				// Fall back to legacy deletion detection
-				if err := w.detectDeletions(ctx, prNumber, xrs, results); err != nil {
-					w.logger.Error(err, "legacy deletion detection failed", "prNumber", prNumber)
-				}
-			} else {
-				w.logger.Error(err, "ArgoCD diff failed, using fallback",
-					"prApp", scope.PRAppName,
-					"prodApp", scope.ProdAppName)
-				// Continue with fallback
-				if err := w.detectDeletions(ctx, prNumber, xrs, results); err != nil {
-					w.logger.Error(err, "legacy deletion detection failed", "prNumber", prNumber)
-				}
-			}
-		} else {
-			// Successfully got ArgoCD diff
-			argocdDiff = appDiff
-			w.logger.Info("ArgoCD diff complete",
-				"additions", len(appDiff.Additions),
-				"modifications", len(appDiff.Modifications),
-				"deletions", len(appDiff.Deletions))
+	// 3. Deletions of the whole PR that belong to this repository
+	items = append(items, shared.deletions...)
 
-			// Add ArgoCD deletions to results
-			for _, deletion := range appDiff.Deletions {
-				key := fmt.Sprintf("DELETED-%s", deletion.Name)
-				results[key] = &differ.DiffResult{
-					HasChanges: true,
-					Summary:    fmt.Sprintf("⚠️ %s will be **DELETED** (ArgoCD)", deletion.GVK.Kind),
-					RawDiff:    deletion.RawDiff,
-				}
-			}
+	placeholderPosted := stopPlaceholder()
+	w.recordShedding(prNumber, shed, diffs.shed)
+
+	// If no items, nothing to post
+	if len(items) == 0 {
+		if placeholderPosted {
+			// Don't leave the placeholder promising results that won't come
+			return w.deletePlaceholder(ctx, repo, prNumber)
 		}
-	} else {
-		// No ArgoCD client or scope - use legacy deletion detection
-		if err := w.detectDeletions(ctx, prNumber, xrs, results); err != nil {
-			w.logger.Error(err, "failed to detect deletions", "prNumber", prNumber)
+		return nil
+	}
+
+	markProtectedKinds(items, w.profileFor(repo).ProtectedKinds)
+	differ.AssignSeverities(items, w.deletionPolicies(), w.profileFor(repo).ProtectedKinds)
+	preview := previewFrom(ctx)
+	if preview == nil {
+		w.recordLatestPlan(ctx, repo, prNumber, api.NewPlan(items, argocdDiff))
+		changed := countChanged(items)
+		if argocdDiff != nil {
+			changed += len(argocdDiff.Additions) + len(argocdDiff.Modifications)
 		}
+		deletions, protected := countRisks(items)
+		w.recordOutcome(repo, prNumber, draft, len(items), changed, deletions, protected, countBlocked(items), countFailed(items))
 	}
 
-	// If no results, nothing to post
-	if len(results) == 0 {
-		return nil
+	// Format combined comment, or one comment per component
+	endFormat := timer.phase("format")
+	plans := []componentPlan{{items: items}}
+	if w.componentComments && preview == nil && w.placement == PlacementComment {
+		plans = splitByComponent(items)
 	}
+	comments := make([]string, len(plans))
+	for i, plan := range plans {
+		// The ArgoCD diff and PR-wide notices go in the first comment
+		var appDiff *argocd.AppDiff
+		if i == 0 {
+			appDiff = argocdDiff
+		}
 
-	// Format combined comment
-	var comment string
-	if len(results) == 1 && argocdDiff == nil {
-		// Single XR with no ArgoCD diff - use simple format
-		for _, diff := range results {
-			comment = w.formatter.FormatDiff(xrs[0], diff)
+		var comment string
+		if xr := plannedXR(planned, plan.items); xr != nil && appDiff == nil {
+			// Single XR with no ArgoCD diff - use simple format
+			comment = w.formatter.FormatDiff(xr, plan.items[0].DiffResult)
+		} else {
+			// Multiple XRs or ArgoCD diff present - use combined format
+			comment = w.formatter.FormatMultipleDiffs(plan.items, appDiff)
 		}
-	} else {
-		// Multiple XRs or ArgoCD diff present - use combined format
-		comment = w.formatter.FormatMultipleDiffs(results, argocdDiff)
+		layout, err := w.formatter.FormatLayout(formatter.LayoutData{
+			Repository: w.repositoryName(repo),
+			PRNumber:   prNumber,
+			Resources:  plan.items,
+			ArgoCD:     appDiff,
+			Body:       comment,
+		})
+		if err != nil {
+			w.logger.Error(err, "failed to apply comment template, posting the default layout", "prNumber", prNumber)
+		} else {
+			comment = layout
+		}
+		comment = w.applyProfileTemplate(repo, prNumber, comment)
+		if shed > 0 && i == 0 {
+			comment = w.formatter.FormatShedNotice(len(planned), len(changed)) + comment
+		}
+		if len(unchanged) > 0 && i == 0 {
+			comment = w.formatter.FormatUnchangedNotice(unchanged) + comment
+		}
+		if teams := w.owningTeams(plan.items); len(teams) > 0 {
+			comment = w.formatter.FormatOwnersNotice(teams) + comment
+		}
+		if w.convergenceEstimate {
+			if estimate, kind, ok := w.convergenceTime(ctx, plan.items); ok {
+				comment = w.formatter.FormatConvergenceNotice(estimate, kind) + comment
+			}
+		}
+		comments[i] = w.formatter.PostProcess(comment)
+	}
+	endFormat()
+
+	var footer string
+	if w.commentTiming {
+		footer = w.formatter.ApplyStyle(formatter.FormatTiming(timer.timing(len(items))))
+	}
+
+	post := true
+	for i := range comments {
+		comments[i], post = w.applyFreeze(prNumber, comments[i])
+	}
+	comment := comments[0]
+	if preview != nil {
+		return preview.add(w, repo, comment, footer, !post)
+	}
+	if !post {
+		return nil
 	}
 
 	// Post to GitHub
 	if w.vcsClient != nil {
-		if err := w.vcsClient.PostComment(ctx, prNumber, comment); err != nil {
-			return fmt.Errorf("failed to post GitHub comment: %w", err)
+		record := store.PlanRecord{Resources: len(items), Changed: countChanged(items), Failed: countFailed(items)}
+		record.Deletions, record.Protected = countRisks(items)
+		entry := formatter.DashboardEntry{Resources: record.Resources, Changed: record.Changed, Deletions: record.Deletions, Protected: record.Protected, Failed: record.Failed}
+		if w.commitStatusFailOn != 0 {
+			// Once the comment is posted, so the status can link to it
+			defer func() {
+				if err := w.publishCommitStatus(ctx, repo, prNumber, entry); err != nil {
+					recordError("vcs", err)
+					w.logger.Error(err, "failed to publish commit status", "prNumber", prNumber, "repo", repo)
+				}
+			}()
+		}
+		switch w.placement {
+		case PlacementCheck:
+			if err := w.publishCheckRun(ctx, repo, prNumber, entry, items, comment); err != nil {
+				recordError("vcs", err)
+				return err
+			}
+			w.recordPlan(ctx, repo, prNumber, record)
+			return nil
+		case PlacementSplit:
+			// Once the comment is posted, so the summary can link to its sections
+			defer func() {
+				if err := w.publishCheckRun(ctx, repo, prNumber, entry, items, comment); err != nil {
+					recordError("vcs", err)
+					w.logger.Error(err, "failed to publish check run", "prNumber", prNumber, "repo", repo)
+				}
+			}()
+		}
+
+		if placeholderPosted && plans[0].component != "" {
+			// The placeholder went to the PR's comment, which no plan replaces
+			if err := w.deletePlaceholder(ctx, repo, prNumber); err != nil {
+				w.logger.Error(err, "failed to delete placeholder comment", "prNumber", prNumber, "repo", repo)
+			}
 		}
-		w.logger.Info("Posted GitHub comment", "prNumber", prNumber, "resourceCount", len(results))
+
+		endPost := timer.phase("post")
+		for i, plan := range plans {
+			// A placeholder replaced the last comment, so it must be edited even if the plan is unchanged
+			if !(placeholderPosted && plan.component == "") && w.commentUnchanged(ctx, repo, prNumber, plan.component, comments[i]) {
+				w.logger.Info("Plan unchanged since last comment, skipping update", "prNumber", prNumber, "repo", repo, "component", plan.component)
+				continue
+			}
+
+			vcsClient, err := w.componentClientFor(repo, plan.component)
+			if err != nil {
+				endPost()
+				return err
+			}
+			posted, err := vcsClient.PostCommentWithFooter(ctx, prNumber, comments[i], footer)
+			if err != nil {
+				endPost()
+				recordError("vcs", err)
+				return fmt.Errorf("failed to post GitHub comment: %w", err)
+			}
+			w.recordComment(ctx, repo, prNumber, plan.component, comments[i])
+			if !posted {
+				w.logger.Info("GitHub comment unchanged, skipping update", "prNumber", prNumber, "repo", vcsClient.Repository(), "component", plan.component)
+				continue
+			}
+			record.Posted = true
+			w.logger.Info("Posted GitHub comment", "prNumber", prNumber, "repo", vcsClient.Repository(), "component", plan.component, "resourceCount", len(plan.items))
+		}
+		endPost()
+		w.logger.Info("Plan timing", append([]interface{}{"prNumber", prNumber}, timer.logValues(len(items))...)...)
+		w.recordPlan(ctx, repo, prNumber, record)
 	} else {
 		// Dry-run mode
-		w.logger.Info("Dry-run: would post comment", "prNumber", prNumber, "resourceCount", len(results))
+		w.logger.Info("Dry-run: would post comment", "prNumber", prNumber, "repo", repo, "resourceCount", len(items), "comments", len(comments))
 	}
 
 	return nil
 }
 
+// plannedXR returns the planned XR of a plan made of that XR alone, or nil
+func plannedXR(planned []*unstructured.Unstructured, items []differ.PlanItem) *unstructured.Unstructured {
+	if len(items) != 1 {
+		return nil
+	}
+	for _, xr := range planned {
+		if xr.GetName() == items[0].Name {
+			return xr
+		}
+	}
+	return nil
+}
+
+// vcsClientFor returns the VCS client for a target repository (empty means default)
+func (w *XRWatcher) vcsClientFor(repo string) (vcs.Client, error) {
+	client := w.vcsClient
+	if repo != "" {
+		var err error
+		client, err = w.vcsClient.ForRepository(repo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create client for target repository %s: %w", repo, err)
+		}
+	}
+
+	if identifier := w.profileFor(repo).CommentIdentifier; identifier != "" {
+		client = client.WithCommentIdentifier(identifier)
+	}
+	return client, nil
+}
+
+// SetDetector replaces the PR detector (used when configuration is reloaded)
+func (w *XRWatcher) SetDetector(prDetector detector.Detector) {
+	w.settingsMu.Lock()
+	defer w.settingsMu.Unlock()
+	w.detector = prDetector
+}
+
+// currentDetector returns the active PR detector
+func (w *XRWatcher) currentDetector() detector.Detector {
+	w.settingsMu.RLock()
+	defer w.settingsMu.RUnlock()
+	return w.detector
+}
+
 // ProcessPR implements the workqueue.PRProcessor interface
 // This is called by the work queue after debouncing
-func (w *XRWatcher) ProcessPR(ctx context.Context, prNumber int) error {
-	w.logger.Info("Processing all resources for PR", "prNumber", prNumber)
+func (w *XRWatcher) ProcessPR(ctx context.Context, pr workqueue.PR) error {
+	w.logger.Info("Processing all resources for PR", "pr", pr)
 
-	// Query all XRs for this PR across all GVRs
-	xrs, err := w.findAllPRResources(ctx, prNumber)
+	// Query all XRs for this PR across all GVRs, then keep those targeting its repository
+	xrs, err := w.findAllPRResources(ctx, pr.Number)
 	if err != nil {
-		return fmt.Errorf("failed to find PR resources: %w", err)
+		err = fmt.Errorf("failed to find PR resources: %w", err)
+		w.reportPlanFailure(ctx, pr.Repo, pr.Number, err)
+		return err
 	}
+	xrs = w.groupByTargetRepo(pr.Number, xrs)[pr.Repo]
 
-	if len(xrs) == 0 {
-		w.logger.Info("No resources found for PR", "prNumber", prNumber)
-		return nil
+	if len(withoutIgnored(xrs)) == 0 {
+		if w.argocdClient != nil && pr.Repo == "" {
+			// The PR may only change resources deployed by its ArgoCD application
+			return w.handleAppOnlyPR(ctx, pr.Number)
+		}
+		return w.handlePreviewRemoved(ctx, pr)
 	}
 
-	w.logger.Info("Found resources for PR", "prNumber", prNumber, "count", len(xrs))
+	w.logger.Info("Found resources for PR", "pr", pr, "count", len(withoutIgnored(xrs)), "ignored", len(xrs)-len(withoutIgnored(xrs)))
 
 	// Process all XRs as a batch
-	return w.handlePRBatch(ctx, prNumber, xrs)
+	return w.handlePRBatch(ctx, pr, xrs)
 }
 
-// findAllPRResources queries all XRs matching the given PR number
+// findAllPRResources queries all XRs matching the given PR number, including ignored ones
 func (w *XRWatcher) findAllPRResources(ctx context.Context, prNumber int) ([]*unstructured.Unstructured, error) {
 	gvrs, err := w.discoverXRDGVRs(ctx)
 	if err != nil {
@@ -518,17 +974,17 @@ func (w *XRWatcher) findAllPRResources(ctx context.Context, prNumber int) ([]*un
 
 	var allXRs []*unstructured.Unstructured
 	for _, gvr := range gvrs {
-		list, err := w.dynamicClient.Resource(gvr).List(ctx, metav1.ListOptions{})
+		_, err := w.listEach(ctx, gvr, func(xr *unstructured.Unstructured) {
+			if xr.GetDeletionTimestamp() != nil {
+				// Being deleted: no longer part of the preview
+				return
+			}
+			if w.currentDetector().DetectPR(xr) == prNumber {
+				allXRs = append(allXRs, xr.DeepCopy())
+			}
+		})
 		if err != nil {
 			w.logger.Error(err, "failed to list resources", "gvr", gvr.String())
-			continue
-		}
-
-		for _, item := range list.Items {
-			xr := item.DeepCopy()
-			if w.detector.DetectPR(xr) == prNumber {
-				allXRs = append(allXRs, xr)
-			}
 		}
 	}
 
@@ -536,78 +992,103 @@ func (w *XRWatcher) findAllPRResources(ctx context.Context, prNumber int) ([]*un
 }
 
 // detectDeletions finds production resources that will be deleted (no PR equivalent exists)
-func (w *XRWatcher) detectDeletions(ctx context.Context, prNumber int, prResources []*unstructured.Unstructured, results map[string]*differ.DiffResult) error {
-	// Build a map of PR resource base names for quick lookup
+// With a scope, every XR of the production application is a candidate; without one, only production
+// XRs of the kinds the PR touches are, since unrelated XRs of other applications can't be told apart
+func (w *XRWatcher) detectDeletions(ctx context.Context, prNumber int, scope *Scope, prResources []*unstructured.Unstructured) ([]differ.PlanItem, error) {
+	var items []differ.PlanItem
+
+	// Build a map of the production resources the PR resources map to, keyed like deletions
+	// (kind, namespace and base name), for quick lookup
 	prBaseNames := make(map[string]bool)
 	prGVKs := make(map[schema.GroupVersionKind]bool)
 
 	for _, prXR := range prResources {
-		baseName := w.detector.GetBaseName(prXR)
-		prBaseNames[baseName] = true
+		baseName := w.currentDetector().GetBaseName(prXR)
+		prBaseNames[differ.DeletionName(prXR.GroupVersionKind(), prXR.GetNamespace(), baseName)] = true
 		prGVKs[prXR.GroupVersionKind()] = true
 	}
 
 	// If no PR resources, nothing to compare against
 	if len(prBaseNames) == 0 {
-		return nil
+		return nil, nil
 	}
 
-	// Get all GVRs we're watching
-	gvrs, err := w.discoverXRDGVRs(ctx)
+	var candidates []*unstructured.Unstructured
+	var err error
+	if scope != nil {
+		candidates, err = w.ListAllScopedProductionXRs(ctx, scope)
+	} else {
+		candidates, err = w.listProductionXRsOfKinds(ctx, prGVKs)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to discover XRDs: %w", err)
+		return nil, err
 	}
 
 	// Find all production resources (non-PR resources)
-	for _, gvr := range gvrs {
-		list, err := w.dynamicClient.Resource(gvr).List(ctx, metav1.ListOptions{})
-		if err != nil {
-			w.logger.Error(err, "failed to list production resources", "gvr", gvr.String())
+	for _, item := range candidates {
+		prodXR := item.DeepCopy()
+
+		// Skip if this is a PR resource
+		if w.currentDetector().DetectPR(prodXR) != 0 {
 			continue
 		}
 
-		for _, item := range list.Items {
-			prodXR := item.DeepCopy()
-
-			// Skip if this is a PR resource
-			if w.detector.DetectPR(prodXR) != 0 {
-				continue
-			}
+		// Skip production resources that opted out of planning
+		if isPlanIgnored(prodXR) {
+			continue
+		}
 
-			// Skip if this GVK is not in the PR (PR doesn't touch this resource type)
-			if !prGVKs[prodXR.GroupVersionKind()] {
-				continue
+		prodName := prodXR.GetName()
+
+		// Check if there's a corresponding PR resource of the same kind and namespace
+		if !prBaseNames[differ.DeletionName(prodXR.GroupVersionKind(), prodXR.GetNamespace(), prodName)] {
+			// This production resource will be deleted!
+			w.sampler.Info("Detected deletion",
+				"resource", prodName,
+				"gvk", prodXR.GroupVersionKind().String(),
+				"prNumber", prNumber,
+			)
+
+			// Create a deletion diff result
+			deletionDiff := &differ.DiffResult{
+				XR:               prodXR,
+				HasChanges:       true,
+				Summary:          "⚠️  Resource will be **DELETED**",
+				RawDiff:          fmt.Sprintf("Resource %s/%s will be deleted", prodXR.GetKind(), prodName),
+				ManagedResources: []differ.ManagedResourceState{},
+				StrippedFields:   []differ.StrippedField{},
 			}
 
-			prodName := prodXR.GetName()
+			// Deletions are keyed by resource so none is listed twice
+			items = differ.AddDeletion(items, prodXR.GroupVersionKind(), prodXR.GetNamespace(), prodName, deletionDiff)
+		}
+	}
 
-			// Check if there's a corresponding PR resource
-			if !prBaseNames[prodName] {
-				// This production resource will be deleted!
-				w.logger.Info("Detected deletion",
-					"resource", prodName,
-					"gvk", prodXR.GroupVersionKind().String(),
-					"prNumber", prNumber,
-				)
+	return items, nil
+}
 
-				// Create a deletion diff result
-				deletionDiff := &differ.DiffResult{
-					XR:         prodXR,
-					HasChanges: true,
-					Summary:    "⚠️  Resource will be **DELETED**",
-					RawDiff:    fmt.Sprintf("Resource %s/%s will be deleted", prodXR.GetKind(), prodName),
-					ManagedResources: []differ.ManagedResourceState{},
-					StrippedFields:   []differ.StrippedField{},
-				}
+// listProductionXRsOfKinds lists the XRs of the given kinds
+func (w *XRWatcher) listProductionXRsOfKinds(ctx context.Context, gvks map[schema.GroupVersionKind]bool) ([]*unstructured.Unstructured, error) {
+	// Get all GVRs we're watching
+	gvrs, err := w.discoverXRDGVRs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover XRDs: %w", err)
+	}
 
-				// Use a special key format for deletions to distinguish from modifications
-				deletionKey := fmt.Sprintf("DELETED-%s", prodName)
-				results[deletionKey] = deletionDiff
+	var xrs []*unstructured.Unstructured
+	for _, gvr := range gvrs {
+		_, err := w.listEach(ctx, gvr, func(xr *unstructured.Unstructured) {
+			// Skip if this GVK is not in the PR (PR doesn't touch this resource type)
+			if gvks[xr.GroupVersionKind()] {
+				xrs = append(xrs, xr.DeepCopy())
 			}
+		})
+		if err != nil {
+			w.logger.Error(err, "failed to list production resources", "gvr", gvr.String())
 		}
 	}
 
-	return nil
+	return xrs, nil
 }
 
 // handleXREvent processes an XR event by enqueueing it for batch processing
@@ -615,20 +1096,56 @@ func (w *XRWatcher) handleXREvent(ctx context.Context, eventType watch.EventType
 	name := xr.GetName()
 	namespace := xr.GetNamespace()
 
-	// Detect PR number
-	prNumber := w.detector.DetectPR(xr)
-	if prNumber == 0 {
+	// Production XRs converge after merges too, so all XRs are sampled
+	if eventType != watch.Deleted {
+		w.observeReadiness(ctx, xr)
+	}
+
+	// Detect the PR and the repository the XR's plan is posted to
+	pr, err := w.prOf(xr)
+	if pr.Number == 0 {
 		// Not a PR preview XR, skip
 		return
 	}
+	if err != nil {
+		w.logger.Error(err, "skipping XR with disallowed target repository", "name", name, "namespace", namespace, "pr", pr)
+		return
+	}
+
+	// Adding or removing the annotation replans the PR, since ignored XRs still count for deletion
+	// detection; other changes of ignored XRs don't
+	ignored := isPlanIgnored(xr) && eventType != watch.Deleted
+	if ignoredChanged := w.tracker.setIgnored(xrKey(xr), ignored); ignored && !ignoredChanged {
+		w.logger.V(1).Info("Skipping XR with plan-ignore annotation", "name", name, "namespace", namespace, "pr", pr)
+		return
+	}
 
-	w.logger.Info("Processing XR event",
+	w.sampler.Info("Processing XR event",
 		"type", eventType,
 		"name", name,
 		"namespace", namespace,
-		"prNumber", prNumber,
+		"pr", pr,
 	)
 
 	// Enqueue for batch processing (debounced)
-	w.workQueue.Enqueue(ctx, prNumber)
+	if eventType == watch.Deleted {
+		// If this was the PR's last XR, the plan finds nothing and updates the comment
+		w.tracker.markRemoved(pr)
+	}
+	w.tracker.markDirty(pr)
+	debounce, override, err := debounceOverride(xr)
+	if err != nil {
+		w.logger.Error(err, "ignoring debounce override", "name", name, "namespace", namespace)
+	}
+
+	// An XR whose target repository changed also leaves the PR's plan in the repository it targeted before
+	prs := append([]workqueue.PR{pr}, w.movedFrom(pr, resourceVersions([]*unstructured.Unstructured{xr}))...)
+	for _, pr := range prs {
+		if override {
+			w.logger.Info("Planning PR with debounce override", "pr", pr, "debounce", debounce)
+			w.workQueue.EnqueueAfter(ctx, pr, debounce)
+			continue
+		}
+		w.workQueue.Enqueue(ctx, pr)
+	}
 }
@@ -0,0 +1,238 @@
+package watcher
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/millstonehq/crossplane-plan/pkg/argocd"
+	"github.com/millstonehq/crossplane-plan/pkg/detector"
+	"github.com/millstonehq/crossplane-plan/pkg/formatter"
+	"github.com/millstonehq/crossplane-plan/pkg/workqueue"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+// xrdGVR is the resource of Crossplane's CompositeResourceDefinitions
+var xrdGVR = schema.GroupVersionResource{Group: "apiextensions.crossplane.io", Version: "v1", Resource: "compositeresourcedefinitions"}
+
+// newTestWatcher creates a watcher that detects PRs by name ("pr-{number}-*") and reads
+// objects from a fake cluster; its timers run on clk
+func newTestWatcher(t *testing.T, clk *clocktesting.FakeClock, objects []runtime.Object, opts ...Option) *XRWatcher {
+	t.Helper()
+
+	w, err := NewXRWatcher(
+		&rest.Config{Host: "https://127.0.0.1:6443"},
+		detector.NewNameDetector("pr-{number}-*"),
+		nil,
+		formatter.NewGitHubFormatter(),
+		append([]Option{WithClock(clk)}, opts...)...,
+	)
+	if err != nil {
+		t.Fatalf("NewXRWatcher() error = %v", err)
+	}
+
+	listKinds := map[schema.GroupVersionResource]string{
+		xrdGVR:                "CompositeResourceDefinitionList",
+		argocd.ApplicationGVR: "ApplicationList",
+		{Group: "example.com", Version: "v1", Resource: "xbuckets"}: "XBucketList",
+		{Group: "example.com", Version: "v1", Resource: "xqueues"}:  "XQueueList",
+	}
+	w.dynamicClient = fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, objects...)
+	return w
+}
+
+// plural returns the resource of a test kind, e.g. "xbuckets" for XBucket
+func plural(kind string) string {
+	return map[string]string{"XBucket": "xbuckets", "XQueue": "xqueues"}[kind]
+}
+
+// newXRD returns an XRD serving kind in the example.com group
+func newXRD(kind string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.crossplane.io/v1",
+		"kind":       "CompositeResourceDefinition",
+		"metadata":   map[string]interface{}{"name": plural(kind) + ".example.com"},
+		"spec": map[string]interface{}{
+			"group": "example.com",
+			"names": map[string]interface{}{"kind": kind, "plural": plural(kind)},
+			"versions": []interface{}{
+				map[string]interface{}{"name": "v1", "served": true, "referenceable": true},
+			},
+		},
+	}}
+}
+
+// newXR returns an example.com XR
+func newXR(kind, namespace, name string, annotations map[string]string) *unstructured.Unstructured {
+	xr := &unstructured.Unstructured{}
+	xr.SetAPIVersion("example.com/v1")
+	xr.SetKind(kind)
+	xr.SetNamespace(namespace)
+	xr.SetName(name)
+	xr.SetAnnotations(annotations)
+	return xr
+}
+
+func TestXRWatcher_detectDeletions(t *testing.T) {
+	ignored := map[string]string{PlanIgnoreAnnotation: "true"}
+
+	tests := []struct {
+		name       string
+		pr         []*unstructured.Unstructured
+		production []*unstructured.Unstructured
+		want       []string
+	}{
+		{
+			name:       "no PR resources",
+			production: []*unstructured.Unstructured{newXR("XBucket", "team", "data", nil)},
+		},
+		{
+			name:       "production XR with a PR equivalent",
+			pr:         []*unstructured.Unstructured{newXR("XBucket", "team", "pr-1-data", nil)},
+			production: []*unstructured.Unstructured{newXR("XBucket", "team", "data", nil)},
+		},
+		{
+			name: "production XR without a PR equivalent",
+			pr:   []*unstructured.Unstructured{newXR("XBucket", "team", "pr-1-data", nil)},
+			production: []*unstructured.Unstructured{
+				newXR("XBucket", "team", "data", nil),
+				newXR("XBucket", "team", "logs", nil),
+			},
+			want: []string{"XBucket.example.com/team/logs"},
+		},
+		{
+			name:       "same name in another namespace",
+			pr:         []*unstructured.Unstructured{newXR("XBucket", "team", "pr-1-data", nil)},
+			production: []*unstructured.Unstructured{newXR("XBucket", "other", "data", nil)},
+			want:       []string{"XBucket.example.com/other/data"},
+		},
+		{
+			name: "same name of another kind",
+			pr: []*unstructured.Unstructured{
+				newXR("XBucket", "team", "pr-1-data", nil),
+				newXR("XQueue", "team", "pr-1-jobs", nil),
+			},
+			production: []*unstructured.Unstructured{
+				newXR("XBucket", "team", "data", nil),
+				newXR("XQueue", "team", "data", nil),
+			},
+			want: []string{"XQueue.example.com/team/data"},
+		},
+		{
+			name:       "kind the PR doesn't touch",
+			pr:         []*unstructured.Unstructured{newXR("XBucket", "team", "pr-1-data", nil)},
+			production: []*unstructured.Unstructured{newXR("XQueue", "team", "jobs", nil)},
+		},
+		{
+			name:       "ignored PR XR keeps its production XR",
+			pr:         []*unstructured.Unstructured{newXR("XBucket", "team", "pr-1-data", ignored)},
+			production: []*unstructured.Unstructured{newXR("XBucket", "team", "data", nil)},
+		},
+		{
+			name: "ignored production XR",
+			pr:   []*unstructured.Unstructured{newXR("XBucket", "team", "pr-1-data", nil)},
+			production: []*unstructured.Unstructured{
+				newXR("XBucket", "team", "data", nil),
+				newXR("XBucket", "team", "logs", ignored),
+			},
+		},
+		{
+			name: "XRs of other PRs",
+			pr:   []*unstructured.Unstructured{newXR("XBucket", "team", "pr-1-data", nil)},
+			production: []*unstructured.Unstructured{
+				newXR("XBucket", "team", "data", nil),
+				newXR("XBucket", "team", "pr-2-logs", nil),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects := []runtime.Object{newXRD("XBucket"), newXRD("XQueue")}
+			for _, xr := range append(append([]*unstructured.Unstructured(nil), tt.pr...), tt.production...) {
+				objects = append(objects, xr)
+			}
+			w := newTestWatcher(t, clocktesting.NewFakeClock(time.Now()), objects)
+
+			items, err := w.detectDeletions(context.Background(), 1, nil, tt.pr)
+			if err != nil {
+				t.Fatalf("detectDeletions() error = %v", err)
+			}
+
+			var got []string
+			for _, item := range items {
+				got = append(got, item.Name)
+			}
+			sort.Strings(got)
+			if len(got) != len(tt.want) {
+				t.Fatalf("detectDeletions() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("detectDeletions() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// recordingProcessor records the PRs a work queue processes
+type recordingProcessor struct {
+	processed chan workqueue.PR
+}
+
+func (p *recordingProcessor) ProcessPR(ctx context.Context, pr workqueue.PR) error {
+	p.processed <- pr
+	return nil
+}
+
+func TestXRWatcher_handleXREventDebounce(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		wait        time.Duration
+	}{
+		{name: "default debounce", wait: 5 * time.Second},
+		{name: "debounce override", annotations: map[string]string{PlanDebounceAnnotation: "2s"}, wait: 2 * time.Second},
+		{name: "high priority", annotations: map[string]string{PlanPriorityAnnotation: "high"}, wait: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clocktesting.NewFakeClock(time.Now())
+			processor := &recordingProcessor{processed: make(chan workqueue.PR, 1)}
+			w := newTestWatcher(t, clk, nil, WithWorkQueue(func(workqueue.PRProcessor) WorkQueue {
+				return workqueue.NewPRWorkQueue(processor, logr.Discard(), 5*time.Second, workqueue.WithClock(clk))
+			}))
+
+			w.handleXREvent(context.Background(), watch.Modified, newXR("XBucket", "team", "pr-3-data", tt.annotations))
+
+			if tt.wait > 0 {
+				clk.Step(tt.wait - time.Millisecond)
+				select {
+				case pr := <-processor.processed:
+					t.Fatalf("PR %s processed before its debounce of %s", pr, tt.wait)
+				case <-time.After(50 * time.Millisecond):
+				}
+			}
+
+			clk.Step(time.Millisecond)
+			select {
+			case pr := <-processor.processed:
+				if pr != (workqueue.PR{Number: 3}) {
+					t.Errorf("processed PR %s, want #3", pr)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("PR 3 not processed after its debounce of %s", tt.wait)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+)
+
+// Path is where GitHub delivers webhook events
+const Path = "/webhook"
+
+// Command is the PR comment asking crossplane-plan to plan a PR again
+const Command = "/crossplane-plan"
+
+// maxPayload bounds the size of a webhook delivery
+const maxPayload = 5 << 20
+
+// ErrUnknownRepository is returned by a Replanner for repositories it doesn't post plans to
+var ErrUnknownRepository = errors.New("repository not planned by this instance")
+
+// ErrNotLeader is returned by a Replanner on replicas that don't process PRs
+var ErrNotLeader = errors.New("replica is not the leader")
+
+// trustedAssociations are the author associations whose comments may trigger a plan
+var trustedAssociations = []string{"OWNER", "MEMBER", "COLLABORATOR"}
+
+// Replanner plans a PR again on request
+type Replanner interface {
+	// Replan queues a plan of a PR of a repository ("owner/repo"), or returns ErrUnknownRepository
+	// or ErrNotLeader
+	Replan(ctx context.Context, repo string, prNumber int) error
+}
+
+// issueCommentEvent is the part of a GitHub issue_comment event the handler reads
+type issueCommentEvent struct {
+	Action string `json:"action"`
+	Issue  struct {
+		Number      int              `json:"number"`
+		PullRequest *json.RawMessage `json:"pull_request"`
+	} `json:"issue"`
+	Comment struct {
+		Body              string `json:"body"`
+		AuthorAssociation string `json:"author_association"`
+		User              struct {
+			Login string `json:"login"`
+			Type  string `json:"type"`
+		} `json:"user"`
+	} `json:"comment"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// ReadSecret reads the webhook secret from a file, ignoring surrounding whitespace
+func ReadSecret(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook secret: %w", err)
+	}
+	secret := strings.TrimSpace(string(data))
+	if secret == "" {
+		return nil, fmt.Errorf("webhook secret file %s is empty", path)
+	}
+	return []byte(secret), nil
+}
+
+// NewHandler returns the handler of GitHub webhook deliveries signed with secret
+// A new PR comment starting with Command, by an owner, member or collaborator of the repository,
+// plans the PR again; other events are acknowledged and ignored
+func NewHandler(secret []byte, replanner Replanner, logger logr.Logger) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+Path, func(w http.ResponseWriter, r *http.Request) {
+		payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPayload))
+		if err != nil {
+			http.Error(w, "failed to read payload", http.StatusBadRequest)
+			return
+		}
+		if !validSignature(secret, payload, r.Header.Get("X-Hub-Signature-256")) {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+
+		// Other events, such as the ping sent when the webhook is created, are acknowledged
+		if r.Header.Get("X-GitHub-Event") != "issue_comment" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		var event issueCommentEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			http.Error(w, "invalid issue_comment event", http.StatusBadRequest)
+			return
+		}
+		if !isCommand(event) {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if !slices.Contains(trustedAssociations, event.Comment.AuthorAssociation) {
+			logger.Info("Ignoring plan command of an untrusted user", "repo", event.Repository.FullName,
+				"prNumber", event.Issue.Number, "user", event.Comment.User.Login, "association", event.Comment.AuthorAssociation)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		err = replanner.Replan(r.Context(), event.Repository.FullName, event.Issue.Number)
+		switch {
+		case errors.Is(err, ErrUnknownRepository):
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		case errors.Is(err, ErrNotLeader):
+			// The failed delivery can be redelivered from the webhook's settings
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		logger.Info("Plan requested by PR comment", "repo", event.Repository.FullName,
+			"prNumber", event.Issue.Number, "user", event.Comment.User.Login)
+		w.WriteHeader(http.StatusAccepted)
+	})
+	return mux
+}
+
+// isCommand reports whether an event is a new PR comment by a user whose first line is Command
+func isCommand(event issueCommentEvent) bool {
+	if event.Action != "created" || event.Issue.PullRequest == nil || event.Comment.User.Type == "Bot" {
+		return false
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(event.Comment.Body), "\n")
+	fields := strings.Fields(line)
+	return len(fields) > 0 && fields[0] == Command
+}
+
+// validSignature checks the "sha256=<hex>" HMAC of a payload
+func validSignature(secret, payload []byte, signature string) bool {
+	hexSum, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+	sum, err := hex.DecodeString(hexSum)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return hmac.Equal(sum, mac.Sum(nil))
+}
@@ -0,0 +1,107 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+)
+
+type fakeReplanner struct {
+	repos    map[string]bool
+	follower bool
+	replans  []string
+}
+
+func (f *fakeReplanner) Replan(ctx context.Context, repo string, prNumber int) error {
+	if !f.repos[repo] {
+		return ErrUnknownRepository
+	}
+	if f.follower {
+		return ErrNotLeader
+	}
+	f.replans = append(f.replans, repo)
+	return nil
+}
+
+func sign(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func commentEvent(repo, body, association, userType string, pullRequest bool) string {
+	pr := ""
+	if pullRequest {
+		pr = `, "pull_request": {"url": "https://api.github.com/repos/` + repo + `/pulls/7"}`
+	}
+	return `{"action": "created", "issue": {"number": 7` + pr + `}, "comment": {"body": "` + body +
+		`", "author_association": "` + association + `", "user": {"login": "dev", "type": "` + userType +
+		`"}}, "repository": {"full_name": "` + repo + `"}}`
+}
+
+func TestHandler(t *testing.T) {
+	const secret = "s3cret"
+
+	tests := []struct {
+		name       string
+		event      string
+		payload    string
+		signature  string
+		follower   bool
+		want       int
+		wantReplan bool
+	}{
+		{name: "plan command", event: "issue_comment", payload: commentEvent("owner/repo", "/crossplane-plan", "MEMBER", "User", true), want: http.StatusAccepted, wantReplan: true},
+		{name: "command with trailing text", event: "issue_comment", payload: commentEvent("owner/repo", "  /crossplane-plan please\\nthanks", "COLLABORATOR", "User", true), want: http.StatusAccepted, wantReplan: true},
+		{name: "invalid signature", event: "issue_comment", payload: commentEvent("owner/repo", "/crossplane-plan", "MEMBER", "User", true), signature: sign("wrong", "{}"), want: http.StatusUnauthorized},
+		{name: "missing signature", event: "issue_comment", payload: commentEvent("owner/repo", "/crossplane-plan", "MEMBER", "User", true), signature: "none", want: http.StatusUnauthorized},
+		{name: "ping", event: "ping", payload: `{"zen": "Keep it simple"}`, want: http.StatusNoContent},
+		{name: "other comment", event: "issue_comment", payload: commentEvent("owner/repo", "LGTM /crossplane-plan", "MEMBER", "User", true), want: http.StatusNoContent},
+		{name: "issue comment", event: "issue_comment", payload: commentEvent("owner/repo", "/crossplane-plan", "MEMBER", "User", false), want: http.StatusNoContent},
+		{name: "bot comment", event: "issue_comment", payload: commentEvent("owner/repo", "/crossplane-plan", "MEMBER", "Bot", true), want: http.StatusNoContent},
+		{name: "untrusted user", event: "issue_comment", payload: commentEvent("owner/repo", "/crossplane-plan", "CONTRIBUTOR", "User", true), want: http.StatusNoContent},
+		{name: "unknown repository", event: "issue_comment", payload: commentEvent("owner/other", "/crossplane-plan", "OWNER", "User", true), want: http.StatusNotFound},
+		{name: "not the leader", event: "issue_comment", payload: commentEvent("owner/repo", "/crossplane-plan", "OWNER", "User", true), follower: true, want: http.StatusServiceUnavailable},
+		{name: "invalid event", event: "issue_comment", payload: `{"action":`, want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replanner := &fakeReplanner{repos: map[string]bool{"owner/repo": true}, follower: tt.follower}
+			server := httptest.NewServer(NewHandler([]byte(secret), replanner, logr.Discard()))
+			defer server.Close()
+
+			signature := tt.signature
+			switch signature {
+			case "":
+				signature = sign(secret, tt.payload)
+			case "none":
+				signature = ""
+			}
+			req, err := http.NewRequest(http.MethodPost, server.URL+Path, strings.NewReader(tt.payload))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("X-GitHub-Event", tt.event)
+			req.Header.Set("X-Hub-Signature-256", signature)
+			resp, err := server.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.want {
+				t.Errorf("POST %s = %d, want %d", Path, resp.StatusCode, tt.want)
+			}
+			if replanned := len(replanner.replans) == 1; replanned != tt.wantReplan {
+				t.Errorf("replans = %v, want replan %v", replanner.replans, tt.wantReplan)
+			}
+		})
+	}
+}
@@ -2,117 +2,327 @@ package workqueue
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
+	"k8s.io/utils/clock"
 )
 
+// PR identifies a pull request across the repositories a watcher comments on
+type PR struct {
+	// Repo is the full name of the PR's repository, empty for the default repository
+	Repo   string
+	Number int
+}
+
+// String formats the PR as "#42", or "owner/repo#42" outside the default repository
+func (p PR) String() string {
+	return fmt.Sprintf("%s#%d", p.Repo, p.Number)
+}
+
+// Less orders PRs by repository, then number
+func (p PR) Less(other PR) bool {
+	if p.Repo != other.Repo {
+		return p.Repo < other.Repo
+	}
+	return p.Number < other.Number
+}
+
 // PRWorkQueue manages debounced processing of PR preview resources
 type PRWorkQueue struct {
-	pending   map[int]*prWork
+	pending   map[PR]*prWork
 	mu        sync.Mutex
 	processor PRProcessor
 	logger    logr.Logger
 	debounce  time.Duration
+	clock     clock.WithDelayedExecution
+	draining  bool                 // new work is rejected while draining
+	inFlight  map[PR]*inFlightWork // PRs currently being processed
+	failures  map[PR]*prFailure    // PRs whose last processing failed
+	wg        sync.WaitGroup       // tracks in-flight processing
 }
 
 // prWork represents pending work for a PR
 type prWork struct {
-	prNumber    int
+	pr          PR
+	enqueuedAt  time.Time
 	lastEventAt time.Time
-	timer       *time.Timer
+	debounce    time.Duration
+	timer       clock.Timer
 	mu          sync.Mutex
+
+	// ready is set when the debounce elapsed while the PR was still processing; the
+	// work then runs as soon as that finishes. Guarded by the queue's mu
+	ready bool
+}
+
+// inFlightWork is a PR being processed
+type inFlightWork struct {
+	cancel     context.CancelFunc
+	enqueuedAt time.Time
+	startedAt  time.Time
+}
+
+// prFailure tracks consecutive processing failures of a PR until it succeeds
+type prFailure struct {
+	count     int
+	lastError string
+	failedAt  time.Time
+}
+
+// Work item states reported by Snapshot
+const (
+	StatePending    = "pending"
+	StateProcessing = "processing"
+	StateFailed     = "failed"
+)
+
+// WorkItem is a point-in-time view of a PR in the queue
+type WorkItem struct {
+	// Repo is the PR's repository, empty for the default repository
+	Repo     string
+	PRNumber int
+	State    string
+
+	// EnqueuedAt is when the PR was first queued (zero for failed PRs awaiting reconciliation)
+	EnqueuedAt time.Time
+
+	// DebounceRemaining is how long until a pending PR is processed
+	DebounceRemaining time.Duration
+
+	// StartedAt is when processing began (processing PRs only)
+	StartedAt time.Time
+
+	// Failures counts consecutive failed attempts; periodic reconciliation retries them
+	Failures  int
+	LastError string
 }
 
 // PRProcessor is the callback interface for processing a PR's resources
 type PRProcessor interface {
-	ProcessPR(ctx context.Context, prNumber int) error
+	ProcessPR(ctx context.Context, pr PR) error
+}
+
+// Option configures a PRWorkQueue when it is created
+type Option func(*PRWorkQueue)
+
+// WithClock sets the clock debounce timers run on (default the real clock)
+func WithClock(c clock.WithDelayedExecution) Option {
+	return func(q *PRWorkQueue) { q.clock = c }
 }
 
 // NewPRWorkQueue creates a new PR work queue with the specified debounce duration
-func NewPRWorkQueue(processor PRProcessor, logger logr.Logger, debounce time.Duration) *PRWorkQueue {
-	return &PRWorkQueue{
-		pending:   make(map[int]*prWork),
+func NewPRWorkQueue(processor PRProcessor, logger logr.Logger, debounce time.Duration, opts ...Option) *PRWorkQueue {
+	q := &PRWorkQueue{
+		pending:   make(map[PR]*prWork),
+		inFlight:  make(map[PR]*inFlightWork),
+		failures:  make(map[PR]*prFailure),
 		processor: processor,
 		logger:    logger,
 		debounce:  debounce,
+		clock:     clock.RealClock{},
 	}
+	for _, opt := range opts {
+		opt(q)
+	}
+	return q
 }
 
 // Enqueue adds or updates a PR in the work queue
 // If the PR is already queued, it resets the debounce timer
-func (q *PRWorkQueue) Enqueue(ctx context.Context, prNumber int) {
+func (q *PRWorkQueue) Enqueue(ctx context.Context, pr PR) {
+	q.EnqueueAfter(ctx, pr, q.debounce)
+}
+
+// EnqueueAfter is Enqueue with a debounce other than the queue's, e.g. 0 for urgent PRs
+// A pending PR keeps the shortest debounce it was enqueued with until it is processed
+func (q *PRWorkQueue) EnqueueAfter(ctx context.Context, pr PR, debounce time.Duration) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
-	work, exists := q.pending[prNumber]
+	if q.draining {
+		q.logger.V(1).Info("Queue draining, ignoring PR", "pr", pr)
+		return
+	}
+
+	work, exists := q.pending[pr]
 	if !exists {
 		// Create new work item
+		now := q.clock.Now()
 		work = &prWork{
-			prNumber:    prNumber,
-			lastEventAt: time.Now(),
+			pr:          pr,
+			enqueuedAt:  now,
+			lastEventAt: now,
+			debounce:    debounce,
 		}
-		q.pending[prNumber] = work
+		q.pending[pr] = work
 
-		q.logger.V(1).Info("Enqueued PR for processing", "prNumber", prNumber, "debounce", q.debounce)
+		q.logger.V(1).Info("Enqueued PR for processing", "pr", pr, "debounce", debounce)
 	} else {
 		// Reset existing timer
 		work.mu.Lock()
 		if work.timer != nil {
 			work.timer.Stop()
 		}
-		work.lastEventAt = time.Now()
+		work.lastEventAt = q.clock.Now()
+		work.ready = false
+		if debounce < work.debounce {
+			work.debounce = debounce
+		}
 		work.mu.Unlock()
 
-		q.logger.V(1).Info("Reset debounce timer for PR", "prNumber", prNumber)
+		q.logger.V(1).Info("Reset debounce timer for PR", "pr", pr)
 	}
 
-	// Start debounce timer
+	// Start debounce timer; fake clocks fire timers while holding their lock, so the PR is
+	// processed on its own goroutine
 	work.mu.Lock()
-	work.timer = time.AfterFunc(q.debounce, func() {
-		q.processPR(ctx, prNumber)
+	work.timer = q.clock.AfterFunc(work.debounce, func() {
+		go q.processPR(ctx, pr)
 	})
 	work.mu.Unlock()
 }
 
 // processPR executes the processor callback and removes the work item
-func (q *PRWorkQueue) processPR(ctx context.Context, prNumber int) {
+// Processing is detached from ctx cancellation so shutdown doesn't interrupt a PR
+// mid-update; Drain decides whether in-flight work finishes or is abandoned.
+// A PR is processed by one run at a time: work whose debounce elapses while the PR
+// is still processing stays pending and runs once the current run finishes
+func (q *PRWorkQueue) processPR(ctx context.Context, pr PR) {
 	q.mu.Lock()
-	work, exists := q.pending[prNumber]
-	if !exists {
+	work, exists := q.pending[pr]
+	if !exists || q.draining {
+		q.mu.Unlock()
+		return
+	}
+	if _, running := q.inFlight[pr]; running {
+		work.ready = true
 		q.mu.Unlock()
+		q.logger.V(1).Info("PR still processing, deferring next run", "pr", pr)
 		return
 	}
-	delete(q.pending, prNumber)
+	delete(q.pending, pr)
+
+	processCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancel()
+	q.inFlight[pr] = &inFlightWork{
+		cancel:     cancel,
+		enqueuedAt: work.enqueuedAt,
+		startedAt:  q.clock.Now(),
+	}
+	q.wg.Add(1)
 	q.mu.Unlock()
 
+	defer func() {
+		q.mu.Lock()
+		delete(q.inFlight, pr)
+		next, queued := q.pending[pr]
+		rerun := queued && next.ready && !q.draining
+		q.mu.Unlock()
+		if rerun {
+			go q.processPR(ctx, pr)
+		}
+		q.wg.Done()
+	}()
+
 	q.logger.Info("Processing PR after debounce",
-		"prNumber", prNumber,
-		"lastEventAge", time.Since(work.lastEventAt),
+		"pr", pr,
+		"lastEventAge", q.clock.Since(work.lastEventAt),
 	)
 
-	if err := q.processor.ProcessPR(ctx, prNumber); err != nil {
-		q.logger.Error(err, "Failed to process PR", "prNumber", prNumber)
+	err := q.processor.ProcessPR(processCtx, pr)
+	q.recordResult(pr, err)
+	if err != nil {
+		q.logger.Error(err, "Failed to process PR", "pr", pr)
 		// Note: We don't re-queue on error. Periodic reconciliation will catch it.
 	}
 }
 
+// recordResult tracks consecutive failures of a PR, clearing them on success
+func (q *PRWorkQueue) recordResult(pr PR, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err == nil {
+		delete(q.failures, pr)
+		return
+	}
+
+	failure, ok := q.failures[pr]
+	if !ok {
+		failure = &prFailure{}
+		q.failures[pr] = failure
+	}
+	failure.count++
+	failure.lastError = err.Error()
+	failure.failedAt = q.clock.Now()
+}
+
 // Shutdown stops all pending timers
 func (q *PRWorkQueue) Shutdown() {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
-	for prNumber, work := range q.pending {
+	for pr, work := range q.pending {
 		work.mu.Lock()
 		if work.timer != nil {
 			work.timer.Stop()
 		}
 		work.mu.Unlock()
-		q.logger.Info("Cancelled pending work", "prNumber", prNumber)
+		q.logger.Info("Cancelled pending work", "pr", pr)
 	}
 
-	q.pending = make(map[int]*prWork)
+	q.pending = make(map[PR]*prWork)
+}
+
+// Drain stops accepting work, cancels pending timers and waits up to grace for
+// in-flight PRs to finish. PRs still running after grace are abandoned: their
+// context is cancelled and they are returned in order. The queue accepts work
+// again once Drain returns
+func (q *PRWorkQueue) Drain(grace time.Duration) []PR {
+	q.mu.Lock()
+	q.draining = true
+	q.mu.Unlock()
+
+	q.Shutdown()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	var abandoned []PR
+	select {
+	case <-done:
+	case <-q.clock.After(grace):
+		q.mu.Lock()
+		for pr, work := range q.inFlight {
+			abandoned = append(abandoned, pr)
+			work.cancel()
+		}
+		q.mu.Unlock()
+		<-done
+	}
+	sort.Slice(abandoned, func(i, j int) bool {
+		return abandoned[i].Less(abandoned[j])
+	})
+
+	q.mu.Lock()
+	q.draining = false
+	q.mu.Unlock()
+
+	return abandoned
+}
+
+// InFlightCount returns the number of PRs currently being processed
+func (q *PRWorkQueue) InFlightCount() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.inFlight)
 }
 
 // PendingCount returns the number of PRs currently in the queue
@@ -121,3 +331,69 @@ func (q *PRWorkQueue) PendingCount() int {
 	defer q.mu.Unlock()
 	return len(q.pending)
 }
+
+// Snapshot returns the pending, processing and failed PRs, ordered by repository and PR number
+func (q *PRWorkQueue) Snapshot() []WorkItem {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.clock.Now()
+	items := make(map[PR]*WorkItem)
+
+	for pr, failure := range q.failures {
+		items[pr] = &WorkItem{
+			Repo:      pr.Repo,
+			PRNumber:  pr.Number,
+			State:     StateFailed,
+			Failures:  failure.count,
+			LastError: failure.lastError,
+		}
+	}
+
+	for pr, work := range q.inFlight {
+		item := itemFor(items, pr)
+		item.State = StateProcessing
+		item.EnqueuedAt = work.enqueuedAt
+		item.StartedAt = work.startedAt
+	}
+
+	// A PR can be pending again while it is processing; the pending entry is the next run
+	for pr, work := range q.pending {
+		work.mu.Lock()
+		remaining := work.debounce - now.Sub(work.lastEventAt)
+		enqueuedAt := work.enqueuedAt
+		work.mu.Unlock()
+		if remaining < 0 {
+			remaining = 0
+		}
+
+		item := itemFor(items, pr)
+		if item.State != StateProcessing {
+			item.State = StatePending
+			item.EnqueuedAt = enqueuedAt
+		}
+		item.DebounceRemaining = remaining
+	}
+
+	snapshot := make([]WorkItem, 0, len(items))
+	for _, item := range items {
+		snapshot = append(snapshot, *item)
+	}
+	sort.Slice(snapshot, func(i, j int) bool {
+		if snapshot[i].Repo != snapshot[j].Repo {
+			return snapshot[i].Repo < snapshot[j].Repo
+		}
+		return snapshot[i].PRNumber < snapshot[j].PRNumber
+	})
+	return snapshot
+}
+
+// itemFor returns the work item for a PR, creating it if needed
+func itemFor(items map[PR]*WorkItem, pr PR) *WorkItem {
+	item, ok := items[pr]
+	if !ok {
+		item = &WorkItem{Repo: pr.Repo, PRNumber: pr.Number}
+		items[pr] = item
+	}
+	return item
+}
@@ -2,56 +2,83 @@ package workqueue
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/go-logr/logr"
+	clocktesting "k8s.io/utils/clock/testing"
 )
 
 type mockProcessor struct {
 	mu        sync.Mutex
-	processed []int
+	processed []PR
 	err       error
+	calls     chan PR // receives every processed PR, if set
 }
 
-func (m *mockProcessor) ProcessPR(ctx context.Context, prNumber int) error {
+func (m *mockProcessor) ProcessPR(ctx context.Context, pr PR) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.processed = append(m.processed, prNumber)
+	m.processed = append(m.processed, pr)
+	if m.calls != nil {
+		m.calls <- pr
+	}
 	return m.err
 }
 
-func (m *mockProcessor) getProcessed() []int {
+func (m *mockProcessor) getProcessed() []PR {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	result := make([]int, len(m.processed))
+	result := make([]PR, len(m.processed))
 	copy(result, m.processed)
 	return result
 }
 
+// waitProcessed waits until n more PRs are processed
+func (m *mockProcessor) waitProcessed(t *testing.T, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-m.calls:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %d processed PRs, got %v", n, m.getProcessed())
+		}
+	}
+}
+
+// newFakeClockQueue creates a queue whose debounce timers run on a fake clock
+func newFakeClockQueue(processor PRProcessor, debounce time.Duration) (*PRWorkQueue, *clocktesting.FakeClock) {
+	clk := clocktesting.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	return NewPRWorkQueue(processor, logr.Discard(), debounce, WithClock(clk)), clk
+}
+
 func TestPRWorkQueue_Enqueue(t *testing.T) {
-	processor := &mockProcessor{}
-	logger := logr.Discard()
-	queue := NewPRWorkQueue(processor, logger, 50*time.Millisecond)
+	processor := &mockProcessor{calls: make(chan PR, 10)}
+	queue, clk := newFakeClockQueue(processor, 50*time.Millisecond)
 	defer queue.Shutdown()
 
 	ctx := context.Background()
 
 	// Enqueue PR #5
-	queue.Enqueue(ctx, 5)
+	queue.Enqueue(ctx, PR{Number: 5})
 
-	// Should be pending
+	// Should be pending until the debounce elapses
+	clk.Step(49 * time.Millisecond)
 	if queue.PendingCount() != 1 {
 		t.Errorf("expected 1 pending item, got %d", queue.PendingCount())
 	}
+	if processed := processor.getProcessed(); len(processed) != 0 {
+		t.Errorf("expected nothing processed before the debounce, got %v", processed)
+	}
 
-	// Wait for debounce
-	time.Sleep(100 * time.Millisecond)
+	clk.Step(time.Millisecond)
+	processor.waitProcessed(t, 1)
 
 	// Should be processed
 	processed := processor.getProcessed()
-	if len(processed) != 1 || processed[0] != 5 {
+	if len(processed) != 1 || processed[0] != (PR{Number: 5}) {
 		t.Errorf("expected [5], got %v", processed)
 	}
 
@@ -62,17 +89,16 @@ func TestPRWorkQueue_Enqueue(t *testing.T) {
 }
 
 func TestPRWorkQueue_Debounce(t *testing.T) {
-	processor := &mockProcessor{}
-	logger := logr.Discard()
-	queue := NewPRWorkQueue(processor, logger, 50*time.Millisecond)
+	processor := &mockProcessor{calls: make(chan PR, 10)}
+	queue, clk := newFakeClockQueue(processor, 50*time.Millisecond)
 	defer queue.Shutdown()
 
 	ctx := context.Background()
 
 	// Enqueue PR #5 multiple times rapidly
 	for i := 0; i < 5; i++ {
-		queue.Enqueue(ctx, 5)
-		time.Sleep(10 * time.Millisecond) // Less than debounce
+		queue.Enqueue(ctx, PR{Number: 5})
+		clk.Step(10 * time.Millisecond) // Less than debounce
 	}
 
 	// Should still be pending (timer keeps resetting)
@@ -81,27 +107,52 @@ func TestPRWorkQueue_Debounce(t *testing.T) {
 	}
 
 	// Wait for final debounce
-	time.Sleep(100 * time.Millisecond)
+	clk.Step(40 * time.Millisecond)
+	processor.waitProcessed(t, 1)
 
-	// Should be processed exactly once
+	// Should be processed exactly once, with no timer left to process it again
 	processed := processor.getProcessed()
 	if len(processed) != 1 {
 		t.Errorf("expected 1 processing call, got %d: %v", len(processed), processed)
 	}
+	if clk.HasWaiters() {
+		t.Error("expected no debounce timers left")
+	}
+}
+
+func TestPRWorkQueue_EnqueueAfter(t *testing.T) {
+	processor := &mockProcessor{calls: make(chan PR, 10)}
+	queue, clk := newFakeClockQueue(processor, time.Hour)
+	defer queue.Shutdown()
+
+	ctx := context.Background()
+
+	// An urgent event shortens the debounce of an already pending PR
+	queue.Enqueue(ctx, PR{Number: 5})
+	queue.EnqueueAfter(ctx, PR{Number: 5}, 0)
+	// Later normal events don't lengthen it again
+	queue.Enqueue(ctx, PR{Number: 5})
+
+	clk.Step(time.Millisecond)
+	processor.waitProcessed(t, 1)
+
+	processed := processor.getProcessed()
+	if len(processed) != 1 || processed[0] != (PR{Number: 5}) {
+		t.Errorf("expected [5] processed without waiting for the queue's debounce, got %v", processed)
+	}
 }
 
 func TestPRWorkQueue_MultiplePRs(t *testing.T) {
-	processor := &mockProcessor{}
-	logger := logr.Discard()
-	queue := NewPRWorkQueue(processor, logger, 50*time.Millisecond)
+	processor := &mockProcessor{calls: make(chan PR, 10)}
+	queue, clk := newFakeClockQueue(processor, 50*time.Millisecond)
 	defer queue.Shutdown()
 
 	ctx := context.Background()
 
 	// Enqueue multiple PRs
-	queue.Enqueue(ctx, 5)
-	queue.Enqueue(ctx, 10)
-	queue.Enqueue(ctx, 15)
+	queue.Enqueue(ctx, PR{Number: 5})
+	queue.Enqueue(ctx, PR{Number: 10})
+	queue.Enqueue(ctx, PR{Number: 15})
 
 	// All should be pending
 	if queue.PendingCount() != 3 {
@@ -109,7 +160,8 @@ func TestPRWorkQueue_MultiplePRs(t *testing.T) {
 	}
 
 	// Wait for debounce
-	time.Sleep(100 * time.Millisecond)
+	clk.Step(50 * time.Millisecond)
+	processor.waitProcessed(t, 3)
 
 	// All should be processed
 	processed := processor.getProcessed()
@@ -118,24 +170,50 @@ func TestPRWorkQueue_MultiplePRs(t *testing.T) {
 	}
 
 	// Verify all PRs were processed (order may vary)
-	seen := make(map[int]bool)
+	seen := make(map[PR]bool)
 	for _, pr := range processed {
 		seen[pr] = true
 	}
-	if !seen[5] || !seen[10] || !seen[15] {
+	if !seen[PR{Number: 5}] || !seen[PR{Number: 10}] || !seen[PR{Number: 15}] {
 		t.Errorf("not all PRs were processed: %v", processed)
 	}
 }
 
+func TestPRWorkQueue_SameNumberInOtherRepo(t *testing.T) {
+	processor := &mockProcessor{calls: make(chan PR, 10)}
+	queue, clk := newFakeClockQueue(processor, 50*time.Millisecond)
+	defer queue.Shutdown()
+
+	ctx := context.Background()
+
+	// PR 5 of another repository is separate work, not a repeat event for the default one
+	queue.Enqueue(ctx, PR{Number: 5})
+	queue.Enqueue(ctx, PR{Repo: "acme/network", Number: 5})
+	if queue.PendingCount() != 2 {
+		t.Errorf("expected 2 pending items, got %d", queue.PendingCount())
+	}
+
+	clk.Step(50 * time.Millisecond)
+	processor.waitProcessed(t, 2)
+
+	processed := processor.getProcessed()
+	seen := make(map[PR]bool)
+	for _, pr := range processed {
+		seen[pr] = true
+	}
+	if len(processed) != 2 || !seen[PR{Number: 5}] || !seen[PR{Repo: "acme/network", Number: 5}] {
+		t.Errorf("expected PR 5 of both repositories processed once, got %v", processed)
+	}
+}
+
 func TestPRWorkQueue_Shutdown(t *testing.T) {
 	processor := &mockProcessor{}
-	logger := logr.Discard()
-	queue := NewPRWorkQueue(processor, logger, 200*time.Millisecond)
+	queue, clk := newFakeClockQueue(processor, 200*time.Millisecond)
 
 	ctx := context.Background()
 
 	// Enqueue PR
-	queue.Enqueue(ctx, 5)
+	queue.Enqueue(ctx, PR{Number: 5})
 
 	// Shutdown before debounce completes
 	queue.Shutdown()
@@ -145,8 +223,11 @@ func TestPRWorkQueue_Shutdown(t *testing.T) {
 		t.Errorf("expected 0 pending items after shutdown, got %d", queue.PendingCount())
 	}
 
-	// Wait to ensure it doesn't process
-	time.Sleep(300 * time.Millisecond)
+	// The debounce timer is stopped, so nothing fires when the debounce elapses
+	if clk.HasWaiters() {
+		t.Error("expected the debounce timer to be stopped")
+	}
+	clk.Step(300 * time.Millisecond)
 
 	// Should not have processed
 	processed := processor.getProcessed()
@@ -154,3 +235,166 @@ func TestPRWorkQueue_Shutdown(t *testing.T) {
 		t.Errorf("expected no processing after shutdown, got %v", processed)
 	}
 }
+
+// blockingProcessor holds each PR until its context is cancelled or release is closed
+type blockingProcessor struct {
+	started chan PR
+	release chan struct{}
+	mu      sync.Mutex
+	errs    []error
+}
+
+func (b *blockingProcessor) ProcessPR(ctx context.Context, pr PR) error {
+	b.started <- pr
+	var err error
+	select {
+	case <-b.release:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	b.mu.Lock()
+	b.errs = append(b.errs, err)
+	b.mu.Unlock()
+	return err
+}
+
+func TestPRWorkQueue_SerializesPR(t *testing.T) {
+	processor := &blockingProcessor{started: make(chan PR, 2), release: make(chan struct{})}
+	queue, clk := newFakeClockQueue(processor, 10*time.Millisecond)
+
+	ctx := context.Background()
+	queue.Enqueue(ctx, PR{Number: 4})
+	clk.Step(10 * time.Millisecond)
+	<-processor.started
+
+	// The next run's debounce elapses while the first is still processing
+	queue.Enqueue(ctx, PR{Number: 4})
+	clk.Step(10 * time.Millisecond)
+
+	select {
+	case <-processor.started:
+		t.Fatal("PR 4 processed concurrently with its running update")
+	case <-time.After(100 * time.Millisecond):
+	}
+	if queue.InFlightCount() != 1 || queue.PendingCount() != 1 {
+		t.Errorf("in-flight = %d, pending = %d, want 1 and 1", queue.InFlightCount(), queue.PendingCount())
+	}
+
+	// The deferred run starts once the first finishes
+	close(processor.release)
+	select {
+	case <-processor.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the deferred run of PR 4")
+	}
+
+	if abandoned := queue.Drain(time.Second); len(abandoned) != 0 {
+		t.Errorf("Drain() abandoned %v, want none", abandoned)
+	}
+	if queue.PendingCount() != 0 {
+		t.Errorf("expected 0 pending items, got %d", queue.PendingCount())
+	}
+}
+
+func TestPRWorkQueue_DrainWaitsForInFlight(t *testing.T) {
+	processor := &blockingProcessor{started: make(chan PR, 1), release: make(chan struct{})}
+	queue := NewPRWorkQueue(processor, logr.Discard(), 10*time.Millisecond)
+
+	// Cancelling the enqueue context must not interrupt in-flight processing
+	ctx, cancel := context.WithCancel(context.Background())
+	queue.Enqueue(ctx, PR{Number: 5})
+	<-processor.started
+	cancel()
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(processor.release)
+	}()
+
+	if abandoned := queue.Drain(time.Second); len(abandoned) != 0 {
+		t.Errorf("Drain() abandoned %v, want none", abandoned)
+	}
+	if processor.errs[0] != nil {
+		t.Errorf("ProcessPR() error = %v, want nil", processor.errs[0])
+	}
+	if queue.InFlightCount() != 0 {
+		t.Errorf("expected 0 in-flight items, got %d", queue.InFlightCount())
+	}
+}
+
+func TestPRWorkQueue_DrainAbandonsAfterGrace(t *testing.T) {
+	processor := &blockingProcessor{started: make(chan PR, 1), release: make(chan struct{})}
+	queue := NewPRWorkQueue(processor, logr.Discard(), 10*time.Millisecond)
+
+	ctx := context.Background()
+	queue.Enqueue(ctx, PR{Number: 7})
+	<-processor.started
+
+	// Pending work is cancelled rather than started
+	queue.Enqueue(ctx, PR{Number: 8})
+
+	abandoned := queue.Drain(50 * time.Millisecond)
+	if len(abandoned) != 1 || abandoned[0] != (PR{Number: 7}) {
+		t.Errorf("Drain() abandoned %v, want [7]", abandoned)
+	}
+	if processor.errs[0] == nil {
+		t.Error("ProcessPR() error = nil, want context cancellation")
+	}
+	if queue.PendingCount() != 0 {
+		t.Errorf("expected 0 pending items after drain, got %d", queue.PendingCount())
+	}
+}
+
+func TestPRWorkQueue_Snapshot(t *testing.T) {
+	processor := &blockingProcessor{started: make(chan PR, 1), release: make(chan struct{})}
+	queue := NewPRWorkQueue(processor, logr.Discard(), 20*time.Millisecond)
+	defer queue.Shutdown()
+
+	ctx := context.Background()
+	queue.Enqueue(ctx, PR{Number: 3})
+	<-processor.started
+
+	queue.Enqueue(ctx, PR{Number: 9})
+	snapshot := queue.Snapshot()
+	if len(snapshot) != 2 {
+		t.Fatalf("Snapshot() = %+v, want 2 items", snapshot)
+	}
+
+	processing, pending := snapshot[0], snapshot[1]
+	if processing.PRNumber != 3 || processing.State != StateProcessing || processing.StartedAt.IsZero() {
+		t.Errorf("snapshot[0] = %+v, want PR 3 processing", processing)
+	}
+	if pending.PRNumber != 9 || pending.State != StatePending || pending.DebounceRemaining <= 0 || pending.EnqueuedAt.IsZero() {
+		t.Errorf("snapshot[1] = %+v, want PR 9 pending with debounce remaining", pending)
+	}
+
+	close(processor.release)
+}
+
+func TestPRWorkQueue_SnapshotFailures(t *testing.T) {
+	processor := &mockProcessor{err: errors.New("diff failed")}
+	queue := NewPRWorkQueue(processor, logr.Discard(), 10*time.Millisecond)
+	defer queue.Shutdown()
+
+	queue.Enqueue(context.Background(), PR{Number: 3})
+	time.Sleep(50 * time.Millisecond)
+
+	// Failed PRs stay visible with their error until they succeed
+	snapshot := queue.Snapshot()
+	if len(snapshot) != 1 {
+		t.Fatalf("Snapshot() = %+v, want only the failed PR", snapshot)
+	}
+	if failed := snapshot[0]; failed.PRNumber != 3 || failed.State != StateFailed || failed.Failures != 1 || failed.LastError != "diff failed" {
+		t.Errorf("snapshot[0] = %+v, want PR 3 failed once", failed)
+	}
+
+	processor.mu.Lock()
+	processor.err = nil
+	processor.mu.Unlock()
+	queue.Enqueue(context.Background(), PR{Number: 3})
+	time.Sleep(50 * time.Millisecond)
+
+	if snapshot := queue.Snapshot(); len(snapshot) != 0 {
+		t.Errorf("Snapshot() = %+v, want empty after success", snapshot)
+	}
+}